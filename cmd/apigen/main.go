@@ -0,0 +1,38 @@
+// Command apigen writes the typed API client from the Swagger spec and the
+// Go types it names. It is run by go generate in pkg/client:
+//
+//	go run ../../cmd/apigen -root ../.. -out client_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"controlling_furnace/internal/apigen"
+)
+
+func main() {
+	root := flag.String("root", ".", "repository root")
+	out := flag.String("out", "client_gen.go", "file to write")
+	pkg := flag.String("package", "client", "package of the generated file")
+	flag.Parse()
+
+	if err := run(*root, *out, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "apigen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(root, out, pkg string) error {
+	spec, err := os.ReadFile(filepath.Join(root, "docs", "swagger.json"))
+	if err != nil {
+		return err
+	}
+	src, err := apigen.Generate(apigen.Config{Spec: spec, Packages: apigen.Packages(root), Package: pkg})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
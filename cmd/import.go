@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"controlling_furnace/internal/logger"
+	"controlling_furnace/internal/repository"
+	"controlling_furnace/internal/service"
+
+	"github.com/spf13/viper"
+)
+
+// runImport implements the "import" subcommand:
+//
+//	furnace import -kind telemetry -mapping legacy -site plant-a export.csv [more.csv ...]
+//
+// Records are imported into the site given by -site, by default site.id.
+// Each file is imported in its own transaction and its report is printed as
+// JSON. The exit code is non-zero if any file failed.
+func runImport(args []string, log *logger.Logger) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	kind := fs.String("kind", "telemetry", "record kind: telemetry or events")
+	mapping := fs.String("mapping", "", "mapping name from import.mappings in config")
+	site := fs.String("site", viper.GetString("site.id"), "site the records belong to")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: import [-kind telemetry|events] [-mapping name] [-site id] file.csv ...")
+		return 2
+	}
+	if err := repository.ValidateSite(*site); err != nil {
+		log.Errorw("invalid site", "err", err)
+		return 2
+	}
+
+	cfg, err := loadImportConfig()
+	if err != nil {
+		log.Errorw("invalid import config", "err", err)
+		return 1
+	}
+	db, err := openDB(log)
+	if err != nil {
+		log.Errorw("failed to init sqlite", "err", err)
+		return 1
+	}
+	defer func() { _ = db.Close() }()
+	repoCfg := loadRepositoryConfig()
+	repoCfg.Site = *site
+	importer := service.NewImportService(repository.NewRepositoryWithConfig(db, repoCfg).Import, cfg)
+
+	var run func(context.Context, string, io.Reader) (service.ImportReport, error)
+	switch *kind {
+	case "telemetry":
+		run = importer.ImportTelemetry
+	case "events":
+		run = importer.ImportEvents
+	default:
+		fmt.Fprintf(os.Stderr, "unknown kind %q; use telemetry or events\n", *kind)
+		return 2
+	}
+
+	code := 0
+	out := json.NewEncoder(os.Stdout)
+	for _, path := range fs.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Errorw("import failed", "file", path, "err", err)
+			code = 1
+			continue
+		}
+		rep, err := run(context.Background(), *mapping, f)
+		_ = f.Close()
+		if err != nil {
+			log.Errorw("import failed", "file", path, "err", err)
+			code = 1
+			continue
+		}
+		_ = out.Encode(struct {
+			File string `json:"file"`
+			service.ImportReport
+		}{path, rep})
+	}
+	return code
+}
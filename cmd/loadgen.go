@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"os/signal"
+
+	"controlling_furnace/internal/loadgen"
+	"controlling_furnace/internal/logger"
+	"controlling_furnace/pkg/client"
+)
+
+// runLoadgen implements the "loadgen" subcommand:
+//
+//	furnace loadgen -target http://host:8080 -user qa -password secret -pollers 50 -subscribers 200 -duration 1m
+//
+// It loads a running instance and prints the report as JSON. The exit code
+// is non-zero if the run could not start or an error rate exceeds
+// -max-error-rate.
+func runLoadgen(args []string, log *logger.Logger) int {
+	var cfg loadgen.Config
+	fs := flag.NewFlagSet("loadgen", flag.ContinueOnError)
+	fs.StringVar(&cfg.Target, "target", "http://localhost:8080", "base URL of the instance under test")
+	fs.StringVar(&cfg.Token, "token", "", "JWT for the requests")
+	user := fs.String("user", "", "sign in as this user to obtain a token")
+	password := fs.String("password", "", "password for -user")
+	fs.IntVar(&cfg.Pollers, "pollers", 10, "concurrent REST pollers")
+	fs.StringVar(&cfg.Path, "path", loadgen.DefaultPath, "endpoint the pollers GET")
+	fs.DurationVar(&cfg.PollInterval, "poll-interval", 0, "pause between a poller's requests")
+	fs.IntVar(&cfg.Subscribers, "subscribers", 10, "concurrent WebSocket subscribers")
+	fs.DurationVar(&cfg.WSInterval, "ws-interval", 0, "stream interval requested by subscribers")
+	fs.DurationVar(&cfg.Duration, "duration", loadgen.DefaultDuration, "how long to run")
+	fs.DurationVar(&cfg.Timeout, "timeout", loadgen.DefaultTimeout, "per request and handshake timeout")
+	maxErrorRate := fs.Float64("max-error-rate", 1, "fail if the poll or WebSocket error rate is above this")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *user != "" {
+		token, err := signIn(cfg.Target, *user, *password)
+		if err != nil {
+			log.Errorw("loadgen sign-in failed", "user", *user, "err", err)
+			return 1
+		}
+		cfg.Token = token
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	log.Infow("loadgen started", "target", cfg.Target, "pollers", cfg.Pollers, "subscribers", cfg.Subscribers, "duration", cfg.Duration.String())
+	rep, err := loadgen.Run(ctx, cfg)
+	if err != nil {
+		log.Errorw("loadgen failed", "err", err)
+		return 1
+	}
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+	_ = out.Encode(rep)
+
+	if (rep.Poll != nil && rep.Poll.ErrorRate > *maxErrorRate) || (rep.WS != nil && rep.WS.ErrorRate > *maxErrorRate) {
+		log.Errorw("loadgen error rate above limit", "max", *maxErrorRate)
+		return 1
+	}
+	return 0
+}
+
+// signIn obtains a JWT from the instance under test.
+func signIn(target, user, password string) (string, error) {
+	tok, err := client.New(target).AuthSignIn(context.Background(), client.AuthCredentials{Username: user, Password: password})
+	return tok.Token, err
+}
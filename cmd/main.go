@@ -25,8 +25,13 @@ import (
 	"context"
 	"controlling_furnace/internal/repository/db"
 	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -35,51 +40,259 @@ import (
 	"controlling_furnace/internal/repository"
 	"controlling_furnace/internal/server"
 	"controlling_furnace/internal/service"
+	"controlling_furnace/internal/tracing"
 
 	_ "controlling_furnace/docs"
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
-const defaultSimTick = 1 * time.Second
+// version and commit are reported by GET /version; set them with
+// -ldflags "-X main.version=v1.2.3 -X main.commit=3f2c9a1". Empty falls
+// back to the VCS revision.
+var version, commit string
 
 func main() {
 	// init logger
 	log := logger.Get(logger.InfoLevel)
 
+	// loadgen targets another instance and needs no local config
+	if len(os.Args) > 1 && os.Args[1] == "loadgen" {
+		os.Exit(runLoadgen(os.Args[2:], log))
+	}
+
 	// load config.yml
 	if err := loadConfig(); err != nil {
 		log.Fatalw("error reading config", "err", err)
 	}
 
-	// open DB
-	db, err := openDB(log)
+	// subcommands run once and exit without serving
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			os.Exit(runImport(os.Args[2:], log))
+		case "scenario":
+			os.Exit(runScenario(os.Args[2:], log))
+		case "migrate":
+			os.Exit(runMigrate(os.Args[2:], log))
+		case "restore":
+			os.Exit(runRestore(os.Args[2:], log))
+		}
+	}
+
+	// export traces before the DB opens so its statements are instrumented
+	traceCfg, err := loadTracingConfig()
+	if err != nil {
+		log.Fatalw("invalid tracing config", "err", err)
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), traceCfg)
 	if err != nil {
-		log.Fatalw("failed to init sqlite", "err", err)
+		log.Fatalw("failed to set up tracing", "err", err)
 	}
 	defer func() {
-		if cerr := db.Close(); cerr != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Errorw("failed to flush traces", "err", err)
+		}
+	}()
+
+	// open DB, with the repositories of every site served
+	siteIDs, err := loadSiteIDs()
+	if err != nil {
+		log.Fatalw("invalid site config", "err", err)
+	}
+	siteRepos, closeDB, err := openRepositories(siteIDs, log)
+	if err != nil {
+		log.Fatalw("failed to init database", "err", err)
+	}
+	defer func() {
+		if cerr := closeDB(); cerr != nil {
 			log.Fatalw("failed to close sqlite", "err", cerr)
 		}
 	}()
 
 	// wire dependencies
-	repos := repository.NewRepository(db)
-	services := service.NewService(repos)
-	apiHandler := handlers.NewHandler(services, log)
+	if viper.GetBool("chaos.enabled") {
+		chaos, err := newChaos()
+		if err != nil {
+			log.Fatalw("invalid chaos config", "err", err)
+		}
+		for i := range siteRepos {
+			siteRepos[i] = chaos.Wrap(siteRepos[i])
+		}
+		log.Warnw("chaos mode enabled: repository calls may be delayed or failed", "settings", chaos.Settings())
+	}
+	svcCfg := loadServiceConfig()
+	svcCfg.Version, svcCfg.Commit = version, commit
+	if svcCfg.Import, err = loadImportConfig(); err != nil {
+		log.Fatalw("invalid import config", "err", err)
+	}
+	if svcCfg.Retention, err = loadRetentionConfig(); err != nil {
+		log.Fatalw("invalid retention config", "err", err)
+	}
+	if err := viper.UnmarshalKey("maintenance", &svcCfg.Maintenance); err != nil {
+		log.Fatalw("invalid maintenance config", "err", err)
+	}
+	if err := viper.UnmarshalKey("webhooks", &svcCfg.Webhooks); err != nil {
+		log.Fatalw("invalid webhooks config", "err", err)
+	}
+	if err := viper.UnmarshalKey("status", &svcCfg.Uptime); err != nil {
+		log.Fatalw("invalid status config", "err", err)
+	}
+	if svcCfg.Alerts, err = loadAlertConfig(); err != nil {
+		log.Fatalw("invalid alerts config", "err", err)
+	}
+	if svcCfg.Escalation, err = loadEscalationConfig(); err != nil {
+		log.Fatalw("invalid escalation config", "err", err)
+	}
+	if svcCfg.Audit, err = loadAuditConfig(); err != nil {
+		log.Fatalw("invalid audit config", "err", err)
+	}
+	if fp, _ := svcCfg.Audit.Fingerprint(); fp == "" {
+		log.Errorw("audit.signing_key is not set: audit packages will not be exported until it is configured")
+	} else {
+		// a copy of the key apart from the packages: verifiers check it
+		// against GET /api/v1/admin/audit/key
+		log.Infow("audit packages are signed", "key_fingerprint", fp)
+	}
+	if svcCfg.Usernames, err = loadUsernamePolicy(); err != nil {
+		log.Fatalw("invalid auth.usernames config", "err", err)
+	}
+	if svcCfg.Backup, err = loadBackupConfig(); err != nil {
+		log.Fatalw("invalid backup config", "err", err)
+	}
+	if svcCfg.History, err = loadHistoryConfig(); err != nil {
+		log.Fatalw("invalid history config", "err", err)
+	}
+	if svcCfg.Sequences, err = loadSequenceConfig(); err != nil {
+		log.Fatalw("invalid sequences config", "err", err)
+	}
+	handlerCfg, err := loadHandlerConfig()
+	if err != nil {
+		log.Fatalw("invalid api config", "err", err)
+	}
+	if traceCfg.Enabled {
+		handlerCfg.TraceService = traceCfg.ServiceName
+		if handlerCfg.TraceService == "" {
+			handlerCfg.TraceService = tracing.DefaultServiceName
+		}
+	}
+	tlsCfg, err := loadTLSConfig()
+	if err != nil {
+		log.Fatalw("invalid tls config", "err", err)
+	}
+	httpCfg, err := loadHTTPConfig()
+	if err != nil {
+		log.Fatalw("invalid server config", "err", err)
+	}
+	if handlerCfg.Demo {
+		log.Warnw("api.demo is on: state and logs are public and every change is refused")
+	}
+	// every site gets its own services, signing key, loops and routes
+	sites := make([]*siteServer, len(siteIDs))
+	apiRoutes, adminRoutes := handlers.NewSites(), handlers.NewSites()
+	for i, id := range siteIDs {
+		site, err := newSiteServer(id, siteRepos[i], svcCfg, handlerCfg, len(siteIDs) > 1, log)
+		if err != nil {
+			log.Fatalw("failed to set up site", "site", id, "err", err)
+		}
+		sites[i] = site
+		apiRoutes.Add(id, site.handler.InitRoutes())
+		adminRoutes.Add(id, site.handler.InitAdminRoutes())
+	}
+	watchConfig(sites, log)
 
 	// context for background goroutines
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-
-	// start simulator (via composed service)
-	go services.Simulator.Run(ctx, defaultSimTick)
+	for _, site := range sites {
+		site.startLoops(ctx)
+	}
 
 	// start HTTP server
-	srv := &server.Server{}
-	runHTTPServer(srv, viper.GetString("port"), apiHandler, log)
+	srv := &server.Server{TLS: tlsCfg, HTTP: httpCfg, OnCertReload: func(err error) {
+		if err != nil {
+			log.Errorw("tls reload failed, serving the previous certificate", "err", err)
+			return
+		}
+		log.Infow("tls certificate reloaded", "cert", tlsCfg.CertFile)
+	}}
+	runHTTPServer(srv, viper.GetString("port"), apiRoutes, log)
+	if port := viper.GetString("server.admin_port"); port != "" {
+		runAdminServer(srv, port, adminRoutes, log)
+	}
 
-	// graceful shutdown
-	waitForShutdown(cancel, srv, log)
+	// graceful shutdown; the DB stays open until the simulators' final saves
+	waitForShutdown(cancel, srv, sites, httpCfg.ShutdownTimeout, log)
+}
+
+// siteServer is what the process runs for one site: its services, with
+// their background loops, and its API.
+type siteServer struct {
+	id       string
+	cfg      service.Config
+	services *service.Service
+	handler  *handlers.Handler
+}
+
+// newSiteServer builds the services and API of site on repos. When the
+// process serves several sites, their logs name the site and each keeps
+// its backups in a directory of its own below backup.dir.
+func newSiteServer(site string, repos *repository.Repository, cfg service.Config, handlerCfg handlers.Config, several bool, log *logger.Logger) (*siteServer, error) {
+	if several {
+		log = &logger.Logger{SugaredLogger: log.With("site", site)}
+		if cfg.Backup.Dir != "" {
+			cfg.Backup.Dir = filepath.Join(cfg.Backup.Dir, site)
+		}
+	}
+	cfg.Site = site
+	cfg.LoopFailed = func(name string, err error, stack []byte) {
+		log.Errorw("loop_failed", "loop", name, "err", err, "stack", string(stack))
+	}
+	services := service.NewServiceWithConfig(repos, cfg)
+	// tokens are signed with the key chosen at the site's setup
+	if err := services.Setup.Restore(context.Background()); err != nil {
+		return nil, fmt.Errorf("restore setup: %w", err)
+	}
+	handlerCfg.FurnaceID = site
+	return &siteServer{
+		id:       site,
+		cfg:      cfg,
+		services: services,
+		handler:  handlers.NewHandlerWithConfig(services, log, handlerCfg),
+	}, nil
+}
+
+// startLoops starts the site's background loops; each is restarted if it
+// panics, and the simulator flushes its state when ctx is canceled.
+func (s *siteServer) startLoops(ctx context.Context) {
+	services := s.services
+	services.Loops.Go(ctx, "simulator", func(ctx context.Context) {
+		services.Simulator.Run(ctx, s.cfg.Sim.Tick)
+	})
+	// evaluate alert rules against the states the simulator publishes
+	services.Loops.Go(ctx, "alerts", services.Alerts.Run)
+	// move submitted mode sequences on step by step
+	services.Loops.Go(ctx, "sequences", services.Sequences.Run)
+	// compile incident reports for alarm episodes
+	services.Loops.Go(ctx, "incidents", services.Incidents.Run)
+	// page the escalation chain while critical incidents go unacknowledged
+	if len(s.cfg.Escalation.Tiers) > 0 {
+		services.Loops.Go(ctx, "escalation", services.Escalations.Run)
+	}
+	// purge expired events from the log
+	if s.cfg.Retention.Enabled() {
+		services.Loops.Go(ctx, "retention", services.Retention.Run)
+	}
+	// log maintenance tasks as they fall due
+	services.Loops.Go(ctx, "maintenance", services.Maintenance.Run)
+	// deliver new events to registered webhooks
+	services.Loops.Go(ctx, "webhooks", services.Webhooks.Run)
+	// record readiness for the uptime summary and badge
+	services.Loops.Go(ctx, "uptime", services.Uptime.Run)
+	// summarise finished hours and days of the temperature history
+	services.Loops.Go(ctx, "rollups", services.SampleRollups.Run)
 }
 
 // ... existing code ...
@@ -90,44 +303,512 @@ func loadConfig() error {
 	return viper.ReadInConfig()
 }
 
+// loadSiteIDs reads the sites the process serves: site.ids, or the one
+// site.id when the list is empty.
+func loadSiteIDs() ([]string, error) {
+	ids := viper.GetStringSlice("site.ids")
+	if len(ids) == 0 {
+		ids = []string{viper.GetString("site.id")}
+	}
+	seen := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		if err := repository.ValidateSite(id); err != nil {
+			return nil, err
+		}
+		if id == "" {
+			id = repository.DefaultSite
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("site %q is listed twice", id)
+		}
+		ids[i], seen[id] = id, true
+	}
+	return ids, nil
+}
+
+// openRepositories opens the repositories of each site on the storage
+// selected by db.driver: the SQLite file at db.path, which the sites
+// share, or process memory, which needs no file but keeps nothing across
+// restarts. The returned function closes the database.
+func openRepositories(sites []string, log *logger.Logger) ([]*repository.Repository, func() error, error) {
+	cfg := loadRepositoryConfig()
+	repos := make([]*repository.Repository, len(sites))
+	switch driver := viper.GetString("db.driver"); driver {
+	case "", "sqlite":
+		cipher, err := loadColumnCipher()
+		if err != nil {
+			return nil, nil, err
+		}
+		conn, err := openDB(log)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cipher != nil {
+			n, err := cipher.EncryptExisting(context.Background(), conn)
+			if err != nil {
+				_ = conn.Close()
+				return nil, nil, err
+			}
+			if n > 0 {
+				log.Infow("encrypted existing credentials", "values", n)
+			}
+		}
+		cfg.Cipher = cipher
+		for i, site := range sites {
+			cfg.Site = site
+			repos[i] = repository.NewRepositoryWithConfig(conn, cfg)
+		}
+		return repos, conn.Close, nil
+	case "memory":
+		log.Warnw("db.driver is memory: nothing is written to disk and all data is lost on exit")
+		for i, site := range sites {
+			cfg.Site = site
+			repos[i] = repository.NewInMemoryWithConfig(cfg)
+		}
+		return repos, func() error { return nil }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown db.driver %q; use sqlite or memory", driver)
+	}
+}
+
 // openDB initializes the SQLite database using configuration.
 func openDB(log *logger.Logger) (*sql.DB, error) {
-	dbPath := viper.GetString("db.path")
-	if dbPath == "" {
+	return db.InitDB(dbPath(log))
+}
+
+// dbPath returns the configured SQLite file.
+func dbPath(log *logger.Logger) string {
+	path := viper.GetString("db.path")
+	if path == "" {
 		log.Infow("db.path not set in config; using default file", "default", "app.db")
-		dbPath = "app.db"
+		path = "app.db"
+	}
+	return path
+}
+
+// loadServiceConfig maps the simulator.* config keys onto service tunables,
+// falling back to defaults for keys that are not set.
+func loadServiceConfig() service.Config {
+	cfg := service.DefaultConfig()
+	if viper.IsSet("simulator.tick") {
+		cfg.Sim.Tick = viper.GetDuration("simulator.tick")
+	}
+	if viper.IsSet("simulator.time_scale") {
+		cfg.Sim.TimeScale = viper.GetFloat64("simulator.time_scale")
+	}
+	if viper.IsSet("simulator.max_elapsed") {
+		cfg.Sim.MaxElapsed = viper.GetDuration("simulator.max_elapsed")
+	}
+	if viper.IsSet("simulator.jitter_tolerance") {
+		cfg.Sim.JitterTolerance = viper.GetDuration("simulator.jitter_tolerance")
+	}
+	phys := &cfg.Sim.Physics
+	if viper.IsSet("simulator.physics.ambient_c") {
+		phys.AmbientC = viper.GetFloat64("simulator.physics.ambient_c")
+	}
+	if viper.IsSet("simulator.physics.max_safe_c") {
+		phys.MaxSafeC = viper.GetFloat64("simulator.physics.max_safe_c")
+	}
+	if viper.IsSet("simulator.physics.ramp_up_c_per_sec") {
+		phys.RampUpCPerSec = viper.GetFloat64("simulator.physics.ramp_up_c_per_sec")
+	}
+	if viper.IsSet("simulator.physics.ramp_down_c_per_sec") {
+		phys.RampDownCPerSec = viper.GetFloat64("simulator.physics.ramp_down_c_per_sec")
+	}
+	if viper.IsSet("simulator.physics.standby_cool_c_per_sec") {
+		phys.StandbyCoolPerSec = viper.GetFloat64("simulator.physics.standby_cool_c_per_sec")
+	}
+	sensor := &cfg.Sim.Sensor
+	if viper.IsSet("simulator.sensor.noise_stddev_c") {
+		sensor.NoiseStdDevC = viper.GetFloat64("simulator.sensor.noise_stddev_c")
+	}
+	if viper.IsSet("simulator.sensor.drift_c_per_hour") {
+		sensor.DriftCPerHour = viper.GetFloat64("simulator.sensor.drift_c_per_hour")
+	}
+	if viper.IsSet("simulator.sensor.max_drift_c") {
+		sensor.MaxDriftC = viper.GetFloat64("simulator.sensor.max_drift_c")
+	}
+	if viper.IsSet("simulator.sensor.seed") {
+		sensor.Seed = viper.GetInt64("simulator.sensor.seed")
+	}
+	ambient := &cfg.Sim.Ambient
+	if viper.IsSet("simulator.ambient.warming_c") {
+		ambient.WarmingC = viper.GetFloat64("simulator.ambient.warming_c")
+	}
+	if viper.IsSet("simulator.ambient.time_constant_s") {
+		ambient.TimeConstantS = viper.GetFloat64("simulator.ambient.time_constant_s")
+	}
+	if viper.IsSet("simulator.ambient.noise_stddev_c") {
+		ambient.NoiseStdDevC = viper.GetFloat64("simulator.ambient.noise_stddev_c")
+	}
+	if viper.IsSet("simulator.ambient.daily_swing_c") {
+		ambient.DailySwingC = viper.GetFloat64("simulator.ambient.daily_swing_c")
+	}
+	if viper.IsSet("simulator.ambient.peak_hour") {
+		ambient.PeakHour = viper.GetFloat64("simulator.ambient.peak_hour")
+	}
+	soak := &cfg.Sim.Soak
+	if viper.IsSet("simulator.soak.min_stability") {
+		soak.MinStability = viper.GetFloat64("simulator.soak.min_stability")
+	}
+	if viper.IsSet("simulator.soak.min_seconds") {
+		soak.MinSeconds = viper.GetFloat64("simulator.soak.min_seconds")
 	}
-	return db.InitDB(dbPath)
+	power := &cfg.Sim.Power
+	if viper.IsSet("simulator.power.heater_kw") {
+		power.HeaterKW = viper.GetFloat64("simulator.power.heater_kw")
+	}
+	if viper.IsSet("simulator.power.hold_fraction") {
+		power.HoldFraction = viper.GetFloat64("simulator.power.hold_fraction")
+	}
+	if viper.IsSet("simulator.power.cooling_kw") {
+		power.CoolingKW = viper.GetFloat64("simulator.power.cooling_kw")
+	}
+	if viper.IsSet("simulator.power.idle_kw") {
+		power.IdleKW = viper.GetFloat64("simulator.power.idle_kw")
+	}
+	safety := &cfg.Sim.Safety
+	if viper.IsSet("simulator.safety.max_rise_c_per_min") {
+		safety.MaxRiseCPerMin = viper.GetFloat64("simulator.safety.max_rise_c_per_min")
+	}
+	if viper.IsSet("simulator.safety.trip_on_rate_of_rise") {
+		safety.TripOnRateOfRise = viper.GetBool("simulator.safety.trip_on_rate_of_rise")
+	}
+	wear := &cfg.Sim.Wear
+	if viper.IsSet("simulator.wear.ramp_loss_per_hour") {
+		wear.RampLossPerHour = viper.GetFloat64("simulator.wear.ramp_loss_per_hour")
+	}
+	if viper.IsSet("simulator.wear.ramp_loss_per_cycle") {
+		wear.RampLossPerCycle = viper.GetFloat64("simulator.wear.ramp_loss_per_cycle")
+	}
+	if viper.IsSet("simulator.wear.max_ramp_loss") {
+		wear.MaxRampLoss = viper.GetFloat64("simulator.wear.max_ramp_loss")
+	}
+	if viper.IsSet("simulator.wear.maintenance_hours") {
+		wear.MaintenanceHours = viper.GetFloat64("simulator.wear.maintenance_hours")
+	}
+	if viper.IsSet("simulator.wear.maintenance_cycles") {
+		wear.MaintenanceCycles = viper.GetInt("simulator.wear.maintenance_cycles")
+	}
+	charge := &cfg.Sim.Charge
+	if viper.IsSet("simulator.charge.chamber_kj_per_k") {
+		charge.ChamberKJPerK = viper.GetFloat64("simulator.charge.chamber_kj_per_k")
+	}
+	if viper.IsSet("simulator.charge.coupling_kw_per_k") {
+		charge.CouplingKWPerK = viper.GetFloat64("simulator.charge.coupling_kw_per_k")
+	}
+	atmosphere := &cfg.Sim.Atmosphere
+	if viper.IsSet("simulator.atmosphere.chamber_volume_m3") {
+		atmosphere.ChamberVolumeM3 = viper.GetFloat64("simulator.atmosphere.chamber_volume_m3")
+	}
+	if viper.IsSet("simulator.atmosphere.flow_time_constant_s") {
+		atmosphere.FlowTimeConstantS = viper.GetFloat64("simulator.atmosphere.flow_time_constant_s")
+	}
+	if viper.IsSet("simulator.atmosphere.gas_o2_ppm") {
+		atmosphere.GasO2PPM = viper.GetFloat64("simulator.atmosphere.gas_o2_ppm")
+	}
+	if viper.IsSet("simulator.atmosphere.leak_per_hour") {
+		atmosphere.LeakPerHour = viper.GetFloat64("simulator.atmosphere.leak_per_hour")
+	}
+	if viper.IsSet("simulator.atmosphere.idle_air_per_hour") {
+		atmosphere.IdleAirPerHour = viper.GetFloat64("simulator.atmosphere.idle_air_per_hour")
+	}
+	if viper.IsSet("simulator.atmosphere.default_max_o2_ppm") {
+		atmosphere.DefaultMaxO2PPM = viper.GetFloat64("simulator.atmosphere.default_max_o2_ppm")
+	}
+	if viper.IsSet("simulator.atmosphere.alarm_above_c") {
+		atmosphere.AlarmAboveC = viper.GetFloat64("simulator.atmosphere.alarm_above_c")
+	}
+	if viper.IsSet("simulator.atmosphere.door_air_share") {
+		atmosphere.DoorAirShare = viper.GetFloat64("simulator.atmosphere.door_air_share")
+	}
+	if viper.IsSet("simulator.keep_warm.setpoint_c") {
+		cfg.Sim.KeepWarm.SetpointC = viper.GetFloat64("simulator.keep_warm.setpoint_c")
+	}
+	if viper.IsSet("probes.max_tick_age") {
+		cfg.Probes.MaxTickAge = viper.GetDuration("probes.max_tick_age")
+	}
+	if viper.IsSet("probes.timeout") {
+		cfg.Probes.Timeout = viper.GetDuration("probes.timeout")
+	}
+	if viper.IsSet("events.node_id") {
+		cfg.NewID = service.NodeIDs(viper.GetString("events.node_id"))
+	}
+	return cfg
+}
+
+// loadRepositoryConfig reads the site and the events.* storage options.
+func loadRepositoryConfig() repository.Config {
+	cfg := repository.Config{Site: viper.GetString("site.id"), EventHashChain: viper.GetBool("events.hash_chain")}
+	if viper.IsSet("events.node_id") {
+		cfg.NewID = service.NodeIDs(viper.GetString("events.node_id"))
+	}
+	return cfg
+}
+
+// loadColumnCipher reads db.encryption_key, or FURNACE_DB_ENCRYPTION_KEY,
+// which keeps the key out of the config file. Nil when neither is set.
+func loadColumnCipher() (*repository.ColumnCipher, error) {
+	if err := viper.BindEnv("db.encryption_key", "FURNACE_DB_ENCRYPTION_KEY"); err != nil {
+		return nil, err
+	}
+	encoded := viper.GetString("db.encryption_key")
+	if encoded == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("db.encryption_key must be base64: %w", err)
+	}
+	return repository.NewColumnCipher(key)
+}
+
+// loadImportConfig reads and validates the import.* CSV mappings.
+func loadImportConfig() (service.ImportConfig, error) {
+	var cfg service.ImportConfig
+	if err := viper.UnmarshalKey("import", &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// loadRetentionConfig reads and validates the events.retention.* limits.
+func loadRetentionConfig() (service.RetentionConfig, error) {
+	var cfg service.RetentionConfig
+	if err := viper.UnmarshalKey("events.retention", &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// loadAlertConfig reads and validates the alerts.* notification settings.
+func loadAlertConfig() (service.AlertConfig, error) {
+	var cfg service.AlertConfig
+	if err := viper.UnmarshalKey("alerts", &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// loadEscalationConfig reads and validates the escalation.* chain.
+func loadEscalationConfig() (service.EscalationConfig, error) {
+	var cfg service.EscalationConfig
+	if err := viper.UnmarshalKey("escalation", &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// loadAuditConfig reads and validates the audit.* config keys.
+func loadAuditConfig() (service.AuditConfig, error) {
+	var cfg service.AuditConfig
+	if err := viper.UnmarshalKey("audit", &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// loadUsernamePolicy reads and validates the auth.usernames.* keys.
+func loadUsernamePolicy() (service.UsernamePolicy, error) {
+	var cfg service.UsernamePolicy
+	if err := viper.UnmarshalKey("auth.usernames", &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// loadBackupConfig reads and validates the backup.* config keys.
+func loadBackupConfig() (service.BackupConfig, error) {
+	var cfg service.BackupConfig
+	if err := viper.UnmarshalKey("backup", &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// loadHistoryConfig reads and validates the history.* config keys.
+func loadHistoryConfig() (service.HistoryConfig, error) {
+	var cfg service.HistoryConfig
+	if err := viper.UnmarshalKey("history", &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// loadSequenceConfig reads and validates the sequences.* config keys.
+func loadSequenceConfig() (service.SequenceConfig, error) {
+	var cfg service.SequenceConfig
+	if err := viper.UnmarshalKey("sequences", &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// loadTracingConfig reads and validates the tracing.* config keys.
+func loadTracingConfig() (tracing.Config, error) {
+	var cfg tracing.Config
+	if err := viper.UnmarshalKey("tracing", &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// loadHandlerConfig reads the api.*, debug.*, status.* and websocket.* config
+// keys, and whether server.admin_port takes the operational routes.
+func loadHandlerConfig() (handlers.Config, error) {
+	var cfg handlers.Config
+	if err := viper.UnmarshalKey("api.compat", &cfg.Compat); err != nil {
+		return cfg, err
+	}
+	if err := cfg.Compat.Validate(); err != nil {
+		return cfg, err
+	}
+	if err := viper.UnmarshalKey("api.permissions", &cfg.Permissions); err != nil {
+		return cfg, err
+	}
+	if err := cfg.Permissions.Validate(); err != nil {
+		return cfg, err
+	}
+	if err := viper.UnmarshalKey("api.rate_limit", &cfg.RateLimit); err != nil {
+		return cfg, err
+	}
+	if err := cfg.RateLimit.Validate(); err != nil {
+		return cfg, err
+	}
+	if err := viper.UnmarshalKey("api.access_log", &cfg.AccessLog); err != nil {
+		return cfg, err
+	}
+	cfg.Debug.Pprof = viper.GetBool("debug.pprof")
+	cfg.Demo = viper.GetBool("api.demo")
+	cfg.AdminListener = viper.GetString("server.admin_port") != ""
+	if err := viper.UnmarshalKey("status", &cfg.Status); err != nil {
+		return cfg, err
+	}
+	ws, err := loadWSConfig()
+	cfg.WS = ws
+	return cfg, err
+}
+
+// loadTLSConfig reads and validates the server.tls.* config keys.
+func loadTLSConfig() (server.TLSConfig, error) {
+	var cfg server.TLSConfig
+	if err := viper.UnmarshalKey("server.tls", &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// loadHTTPConfig reads and validates the server timeouts and limits.
+func loadHTTPConfig() (server.HTTPConfig, error) {
+	var cfg server.HTTPConfig
+	if err := viper.UnmarshalKey("server", &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// loadWSConfig reads and validates the websocket.* config keys.
+func loadWSConfig() (handlers.WSConfig, error) {
+	var ws handlers.WSConfig
+	if err := viper.UnmarshalKey("websocket", &ws); err != nil {
+		return ws, err
+	}
+	return ws, ws.Validate()
+}
+
+// watchConfig re-applies settings that support hot reload to every site
+// whenever config.yml changes. Invalid edits are logged and ignored.
+func watchConfig(sites []*siteServer, log *logger.Logger) {
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		ws, err := loadWSConfig()
+		for _, site := range sites {
+			if err == nil {
+				err = site.handler.SetWSConfig(ws)
+			}
+		}
+		if err != nil {
+			log.Errorw("config reload rejected", "file", e.Name, "err", err)
+			return
+		}
+		log.Infow("websocket config reloaded", "file", e.Name, "settings", sites[0].handler.WSConfig())
+	})
+	viper.WatchConfig()
+}
+
+// newChaos builds the repository fault injector from the chaos.* config keys.
+func newChaos() (*repository.Chaos, error) {
+	return repository.NewChaos(repository.ChaosSettings{
+		Enabled:            true,
+		Latency:            viper.GetDuration("chaos.latency"),
+		LatencyProbability: viper.GetFloat64("chaos.latency_probability"),
+		ErrorProbability:   viper.GetFloat64("chaos.error_probability"),
+	}, viper.GetInt64("chaos.seed"))
 }
 
 // runHTTPServer runs the HTTP server in a separate goroutine.
-func runHTTPServer(srv *server.Server, port string, handler *handlers.Handler, log *logger.Logger) {
+func runHTTPServer(srv *server.Server, port string, routes http.Handler, log *logger.Logger) {
 	go func() {
 		if port == "" {
 			port = "8080"
 		}
-		if err := srv.Run(port, handler.InitRoutes()); err != nil {
+		if err := srv.Run(port, routes); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalw("error starting server", "err", err)
 		}
 	}()
 }
 
-// waitForShutdown listens for termination signals and performs graceful shutdown.
-func waitForShutdown(cancel context.CancelFunc, srv *server.Server, log *logger.Logger) {
+// runAdminServer runs the admin listener (probes, metrics, profiling) in
+// a separate goroutine.
+func runAdminServer(srv *server.Server, port string, routes http.Handler, log *logger.Logger) {
+	go func() {
+		if err := srv.RunAdmin(port, routes); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalw("error starting admin server", "err", err)
+		}
+	}()
+}
+
+// waitForShutdown listens for termination signals and shuts down in order
+// within timeout: the WebSocket clients of every site are sent away, the
+// background loops stop (each simulator saving its state last), then the
+// listeners finish the requests in flight.
+func waitForShutdown(cancel context.CancelFunc, srv *server.Server, sites []*siteServer, timeout time.Duration, log *logger.Logger) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Infow("shutting down server...")
 
-	// stop background goroutines
-	cancel()
-
-	// allow in-flight requests to complete
-	ctx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer shutdownCancel()
-
-	if err := srv.Shutdown(ctx); err != nil {
+	err := server.GracefulShutdown(timeout,
+		// tell WebSocket clients when and where to reconnect; Shutdown
+		// does not close hijacked connections
+		server.ShutdownStep{Name: "websocket clients", Stop: func(ctx context.Context) error {
+			var errs []error
+			for _, site := range sites {
+				errs = append(errs, site.handler.DrainStreams(ctx))
+			}
+			return errors.Join(errs...)
+		}},
+		server.ShutdownStep{Name: "background loops", Stop: func(ctx context.Context) error {
+			cancel()
+			stopped := make(chan struct{})
+			go func() {
+				for _, site := range sites {
+					site.services.Loops.Wait()
+				}
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}},
+		// allow in-flight requests to complete
+		server.ShutdownStep{Name: "http server", Stop: srv.Shutdown},
+	)
+	if err != nil {
 		log.Fatalw("server forced to shutdown", "err", err)
 	}
+	log.Infow("server stopped")
 }
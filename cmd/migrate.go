@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"controlling_furnace/internal/logger"
+	"controlling_furnace/internal/repository/db"
+)
+
+// runMigrate implements the "migrate" subcommand:
+//
+//	furnace migrate status
+//	furnace migrate up
+//	furnace migrate down [n]
+//
+// The server applies pending migrations itself at startup; down rolls back
+// the newest n (default 1) so an older build can run against the file
+// again. The server must not be using the database meanwhile.
+func runMigrate(args []string, log *logger.Logger) int {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	usage := func() int {
+		fmt.Fprintln(os.Stderr, "usage: migrate status | up | down [n]")
+		return 2
+	}
+	if fs.NArg() == 0 || fs.NArg() > 2 || (fs.NArg() == 2 && fs.Arg(0) != "down") {
+		return usage()
+	}
+	steps := 1
+	if fs.NArg() == 2 {
+		n, err := strconv.Atoi(fs.Arg(1))
+		if err != nil || n <= 0 {
+			return usage()
+		}
+		steps = n
+	}
+
+	conn, err := db.OpenDB(dbPath(log))
+	if err != nil {
+		log.Errorw("failed to open sqlite", "err", err)
+		return 1
+	}
+	defer func() { _ = conn.Close() }()
+	ctx := context.Background()
+
+	var done []db.Migration
+	switch fs.Arg(0) {
+	case "status":
+		v, err := db.Version(ctx, conn)
+		if err != nil {
+			log.Errorw("failed to read schema version", "err", err)
+			return 1
+		}
+		for _, m := range db.Migrations() {
+			state := "pending"
+			if m.Version <= v {
+				state = "applied"
+			}
+			fmt.Printf("%04d_%s\t%s\n", m.Version, m.Name, state)
+		}
+		if v > db.LatestVersion() {
+			fmt.Printf("database at version %d is newer than this build (%d)\n", v, db.LatestVersion())
+			return 1
+		}
+		return 0
+	case "up":
+		done, err = db.Migrate(ctx, conn)
+	case "down":
+		done, err = db.MigrateDown(ctx, conn, steps)
+	default:
+		return usage()
+	}
+	for _, m := range done {
+		fmt.Printf("%s %04d_%s\n", fs.Arg(0), m.Version, m.Name)
+	}
+	if err != nil {
+		log.Errorw("migration failed", "err", err)
+		return 1
+	}
+	return 0
+}
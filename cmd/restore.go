@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"controlling_furnace/internal/logger"
+	"controlling_furnace/internal/repository/db"
+)
+
+// runRestore implements the "restore" subcommand:
+//
+//	furnace restore <snapshot>
+//
+// It replaces db.path with a snapshot from POST /api/v1/admin/backup after
+// checking its integrity and schema version. The current database is moved
+// aside, not deleted. The server must be stopped; an older snapshot is
+// migrated when it next starts.
+func runRestore(args []string, log *logger.Logger) int {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: restore <snapshot>")
+		return 2
+	}
+
+	path := dbPath(log)
+	aside, err := db.Restore(context.Background(), fs.Arg(0), path, time.Now())
+	if err != nil {
+		log.Errorw("restore failed", "snapshot", fs.Arg(0), "err", err)
+		return 1
+	}
+	if aside != "" {
+		fmt.Printf("previous database moved to %s\n", aside)
+	}
+	fmt.Printf("restored %s from %s\n", path, fs.Arg(0))
+	return 0
+}
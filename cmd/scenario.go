@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"controlling_furnace/internal/logger"
+	"controlling_furnace/internal/repository/db"
+	"controlling_furnace/internal/scenario"
+)
+
+// runScenario implements the "scenario" subcommand:
+//
+//	furnace scenario [-db path] script.yaml
+//
+// The script runs against an in-memory database unless -db names one to
+// fill with demo data; the server must not be using that file meanwhile.
+// The result, including all events, is printed as JSON.
+func runScenario(args []string, log *logger.Logger) int {
+	fs := flag.NewFlagSet("scenario", flag.ContinueOnError)
+	dbPath := fs.String("db", ":memory:", "SQLite database to write the scenario into")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: scenario [-db path] script.yaml")
+		return 2
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		log.Errorw("failed to read scenario", "err", err)
+		return 1
+	}
+	sc, err := scenario.Parse(data)
+	if err != nil {
+		log.Errorw("invalid scenario", "file", fs.Arg(0), "err", err)
+		return 1
+	}
+	conn, err := db.InitDB(*dbPath)
+	if err != nil {
+		log.Errorw("failed to init sqlite", "err", err)
+		return 1
+	}
+	defer func() { _ = conn.Close() }()
+
+	res, err := scenario.Run(context.Background(), conn, sc, loadServiceConfig())
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+	_ = out.Encode(res)
+	if err != nil {
+		log.Errorw("scenario failed", "name", sc.Name, "err", err)
+		return 1
+	}
+	return 0
+}
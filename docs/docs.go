@@ -1,4 +1,5 @@
-// Package docs Code generated by swaggo/swag. DO NOT EDIT
+// Code generated by swaggo/swag. DO NOT EDIT.
+
 package docs
 
 import "github.com/swaggo/swag"
@@ -21,286 +22,325 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/furnace/mode": {
-            "post": {
+        "/api/v1": {
+            "get": {
+                "description": "Lists the endpoints of the API version with the permission each requires, in path order. GET endpoints also answer HEAD. Public.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "API index",
+                "operationId": "getAPIIndex",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIIndex"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/audit/export": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "HEAT requires target_temp_c and duration_sec",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Streams a ZIP of the records of a period for quality and compliance reviews: events.ndjson (the events with their comments), audit/chain.json (hash chain verification), alarms/alerts.json and alarms/incidents.json, runs.json (the runs the events belong to) and the current configuration under config/.\nmanifest.json lists each file with its size, record count and SHA-256, and the PEM public key with its fingerprint; manifest.sig is the raw Ed25519 signature of manifest.json, made with audit.signing_key. The key in the manifest is not a trust anchor: verify against the key from GET /api/v1/admin/audit/key, kept apart from the packages. Without audit.signing_key nothing is exported (409). A package cut short by an error has no signature. Admin only.",
                 "produces": [
-                    "application/json"
+                    "application/zip"
                 ],
                 "tags": [
-                    "furnace"
+                    "admin"
                 ],
-                "summary": "Set mode",
+                "summary": "Export audit package",
                 "parameters": [
                     {
-                        "description": "Mode payload",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.SetModeRequest"
-                        }
+                        "type": "string",
+                        "example": "2025-09-01",
+                        "description": "Start of the period (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-09-30",
+                        "description": "End of the period; date-only means end of day",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "file"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/furnace/start": {
-            "post": {
+        "/api/v1/admin/audit/key": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Returns the Ed25519 public key audit packages are signed with and its fingerprint (hex SHA-256 of the DER key, as printed at startup). Keep a copy apart from the packages and verify manifest.sig against it rather than the key inside the manifest. 409 when audit.signing_key is not configured. Admin only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "furnace"
+                    "admin"
                 ],
-                "summary": "Start furnace",
+                "summary": "Get the audit signing key",
                 "responses": {
                     "200": {
-                        "description": "status, state",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/service.AuditKey"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/furnace/state": {
-            "get": {
+        "/api/v1/admin/backup": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Takes a consistent snapshot of the SQLite database while the server keeps running (VACUUM INTO), unlike a copy of the live WAL-mode file. By default it is stored in backup.dir, keeping the newest backup.keep snapshots, and described in the response; with download=true it is streamed as an attachment instead, with its SHA-256 in X-Backup-SHA256. Each backup logs a BACKUP event. Database calls wait while the snapshot is taken.\nTo restore, stop the server and run ` + "`" + `furnace restore \u003csnapshot\u003e` + "`" + `. 409 without backup.dir unless downloading; 501 with db.driver=memory. Admin only.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/vnd.sqlite3"
                 ],
                 "tags": [
-                    "furnace"
+                    "admin"
+                ],
+                "summary": "Back up the database",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Stream the snapshot instead of storing it",
+                        "name": "download",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get furnace state",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "file"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.BackupReport"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/furnace/stop": {
-            "post": {
+        "/api/v1/admin/chaos": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Returns the repository fault-injection settings. Admin only; 404 unless chaos mode is enabled in config.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "furnace"
+                    "admin"
                 ],
-                "summary": "Stop furnace",
+                "summary": "Get chaos settings",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handlers.ChaosSettingsDTO"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/logs": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Filter logs by date (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). If 'to' is date-only, it is treated as end-of-day inclusive (23:59:59.999999999Z).",
+                "description": "Arms, tunes, or disarms repository fault injection. Admin only; 404 unless chaos mode is enabled in config.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "logs"
+                    "admin"
                 ],
-                "summary": "List logs",
+                "summary": "Update chaos settings",
                 "parameters": [
                     {
-                        "type": "string",
-                        "example": "2025-08-01",
-                        "description": "Start of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')",
-                        "name": "from",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "example": "2025-08-31",
-                        "description": "End of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). Date-only treated as end of day.",
-                        "name": "to",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "START",
-                            "MODE_CHANGE",
-                            "STOP",
-                            "ERROR"
-                        ],
-                        "type": "string",
-                        "description": "Event type",
-                        "name": "type",
-                        "in": "query"
+                        "description": "Chaos settings",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ChaosSettingsDTO"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "count, events",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handlers.ChaosSettingsDTO"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     }
                 }
             }
         },
-        "/auth/sign-in": {
+        "/api/v1/admin/config/preview": {
             "post": {
-                "description": "Authenticate user and return a JWT token",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reports what the proposed settings would invalidate without applying them: an active HEAT target outside the new ambient_c..max_safe_c range, a chamber already above the new max_safe_c, a room override at or above it (all blocking), and enabled temp_above alert rules above it (warnings). With apply=true the settings are applied like PUT /sim/config if they are valid and nothing blocks them, checked and changed in one step so no command slips in between; otherwise the answer is 400 or 409 with the report.",
                 "consumes": [
                     "application/json"
                 ],
@@ -308,18 +348,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "simulator"
                 ],
-                "summary": "Sign in",
-                "operationId": "authSignIn",
+                "summary": "Preview simulator settings",
                 "parameters": [
                     {
-                        "description": "User credentials",
-                        "name": "input",
+                        "type": "boolean",
+                        "description": "Apply the settings when nothing blocks them",
+                        "name": "apply",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Proposed settings",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.AuthCredentials"
+                            "$ref": "#/definitions/models.SimSettings"
                         }
                     }
                 ],
@@ -327,207 +372,6550 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.TokenResponse"
+                            "$ref": "#/definitions/models.SettingsPreview"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "$ref": "#/definitions/models.SettingsPreview"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.SettingsPreview"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     }
                 }
             }
         },
-        "/auth/sign-up": {
+        "/api/v1/admin/import/{kind}": {
             "post": {
-                "description": "Register a new user",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Loads telemetry or events exported by a legacy controller. The request body is the CSV file, with a header row, read with the named mapping from the import.mappings config. Without a mapping, this system's own columns are expected (telemetry: at, channel, value; events: event_id, occurred_at, type, description). Invalid rows are skipped and reported; records already stored are counted as duplicates. Admin only.",
                 "consumes": [
-                    "application/json"
+                    "text/csv"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Sign up",
-                "operationId": "authSignUp",
+                "summary": "Import history from CSV",
                 "parameters": [
                     {
-                        "description": "User credentials",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.AuthCredentials"
-                        }
+                        "enum": [
+                            "telemetry",
+                            "events"
+                        ],
+                        "type": "string",
+                        "description": "Record kind",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mapping name from config",
+                        "name": "mapping",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.SignUpResponse"
+                            "$ref": "#/definitions/service.ImportReport"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "$ref": "#/definitions/handlers.Problem"
                         }
-                    }
-                }
-            }
-        },
-        "/health": {
-            "get": {
-                "produces": [
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/loops": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reports the supervised background loops (simulator, alerts, incidents): whether each is running or waiting to be restarted after a panic, how often it was restarted, and the last failure. Admin only.",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "system"
+                    "admin"
                 ],
-                "summary": "Health check",
+                "summary": "List background loops",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "count, loops ([]models.LoopStatus)",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     }
                 }
             }
         },
-        "/ws": {
+        "/api/v1/admin/loops/{name}/restart": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stops the named loop and starts it again, as after a panic but without the backoff. The simulator saves its state on the way down and recovers it on the way up. Admin only.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Restart a background loop",
+                "parameters": [
+                    {
+                        "enum": [
+                            "simulator",
+                            "alerts",
+                            "incidents"
+                        ],
+                        "type": "string",
+                        "description": "Loop name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets the role of a user: admin, operator or viewer. Users who sign up start as viewers; this is how an admin lets them control the furnace. Tokens carry the role, so the change applies from the user's next sign-in. The site's last admin cannot be demoted (409). Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Change a user's role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UserRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UserRoleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/alerts": {
             "get": {
-                "description": "Establish a WebSocket connection that streams current furnace state periodically.\nQuery params:\n- interval: Go duration string (e.g., 500ms, 2s). Range: 1ms..10s.\n- interval_ms: integer milliseconds. Range: 1..10000.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns fired alerts, newest first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "websockets"
+                    "alerts"
                 ],
-                "summary": "WebSocket: live furnace state stream",
+                "summary": "List alerts",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only alerts of this rule",
+                        "name": "rule_id",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Update interval as Go duration (e.g. 500ms, 2s). Max 10s.",
-                        "name": "interval",
+                        "description": "Start of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). Date-only treated as end of day.",
+                        "name": "to",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Update interval in milliseconds. Range: 1-10000.",
-                        "name": "interval_ms",
+                        "description": "Maximum alerts (default 100, max 1000)",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
                 "responses": {
-                    "101": {
-                        "description": "Switching Protocols (WebSocket upgrade)",
+                    "200": {
+                        "description": "count, alerts",
                         "schema": {
-                            "type": "string"
-                        },
-                        "headers": {
-                            "Connection": {
-                                "type": "string",
-                                "description": "Upgrade"
-                            },
-                            "Upgrade": {
-                                "type": "string",
-                                "description": "websocket"
-                            }
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Bad request (invalid parameters or upgrade failure)",
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal server error during upgrade",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.AuthCredentials": {
-            "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
-            "properties": {
-                "password": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
-                }
-            }
-        },
-        "handlers.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
-                }
-            }
-        },
-        "handlers.SetModeRequest": {
-            "type": "object",
-            "properties": {
-                "duration_sec": {
-                    "description": "Heating duration in seconds (required when mode=HEAT)",
-                    "type": "integer",
-                    "example": 600
-                },
-                "mode": {
-                    "description": "Mode to set. Allowed: HEAT, COOL, STANDBY",
-                    "type": "string",
-                    "example": "HEAT"
-                },
-                "target_temp_c": {
-                    "description": "Target temperature in Celsius (required when mode=HEAT)",
-                    "type": "number",
-                    "example": 850
-                }
-            }
         },
-        "handlers.SignUpResponse": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "integer"
+        "/api/v1/alerts/rules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "List alert rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AlertRule"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
                 }
-            }
-        },
-        "handlers.TokenResponse": {
-            "type": "object",
-            "properties": {
-                "token": {
-                    "type": "string"
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Kinds: temp_above (measured temperature above threshold °C for for_seconds), remaining_below (running with less than threshold seconds left), error_event (any new error code). A firing logs an ALERT event and is sent to the configured notification URL.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Create alert rule",
+                "parameters": [
+                    {
+                        "description": "Rule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AlertRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.AlertRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/alerts/rules/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Get alert rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AlertRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Replace alert rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AlertRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AlertRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Alerts the rule already fired stay in the history.",
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Delete alert rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/furnace/atmosphere": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets the protective gas and its flow without changing the mode. Gas only flows while the furnace runs; residual oxygen then falls toward the level the purge can hold. While the chamber is hot, oxygen above max_o2_ppm raises O2_HIGH. Logs ATMOSPHERE_CHANGE.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Set atmosphere",
+                "parameters": [
+                    {
+                        "description": "Atmosphere",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AtmosphereRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "status, state",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/furnace/charge": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reports the simulated load in the furnace and its current temperature.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Get furnace charge",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.ChargeStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Simulates loading a charge into the furnace. From the next tick it exchanges heat with the chamber: a cold load makes the chamber temperature dip and recover slowly while the heater brings both up. Logs CHARGE_INSERTED. Not persisted across restarts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Insert charge",
+                "parameters": [
+                    {
+                        "description": "Charge",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InsertChargeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/service.ChargeStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A charge is already in the furnace",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Takes the simulated load out of the furnace and returns it as removed. Logs CHARGE_REMOVED on the next tick.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Remove charge",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.ChargeStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "No charge in the furnace",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/furnace/health": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns cumulative heating hours and heat cycles with the resulting loss of ramp rate, and whether MAINTENANCE_DUE has been raised, for maintenance planning.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Heater health",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FurnaceHealth"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/furnace/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the chamber temperature reduced to min/max/avg per resolution interval, oldest first. Intervals without samples are omitted. Defaults to the last hour at about 500 buckets. At a resolution of whole hours or days, the periods already summarised are read from hourly and daily rollups and count whole, so long ranges stay fast after the per-tick samples are pruned (history.raw_retention). runtime_s is the time spent heating.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Temperature history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). Date-only treated as end of day.",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bucket width in whole seconds, as a number or a duration such as 5m",
+                        "name": "resolution",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TemperatureHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/furnace/mode": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "HEAT requires target_temp_c and duration_sec. An atmosphere object changes the protective gas in the same command.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Set mode",
+                "parameters": [
+                    {
+                        "description": "Mode payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetModeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/furnace/readiness": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Checks a prospective HEAT command against safety limits, furnace state and active alarms without changing anything. Returns every blocker so UIs can explain why Start is disabled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Pre-heat readiness",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "example": 850,
+                        "description": "Target temperature in Celsius",
+                        "name": "target_temp_c",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 600,
+                        "description": "Heating duration in seconds",
+                        "name": "duration_sec",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Readiness"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/furnace/sequence": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Runs the steps back to back as an ad-hoc recipe: the first is set now, each further one once the previous is done. HEAT ends when its soak of duration_sec has elapsed; COOL once the chamber is down to target_temp_c or after duration_sec, whichever comes first; STANDBY after duration_sec. Durations are simulated seconds. The state shows the running sequence and step. Stopping the furnace or another mode command aborts it. Saved with its progress: after a restart it carries on with the step it was on, not counting the time the process was down, unless that exceeded sequences.resume_within. Logs SEQUENCE_STARTED, SEQUENCE_RESUMED after a restart, then SEQUENCE_COMPLETED or SEQUENCE_ABORTED.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Start mode sequence",
+                "parameters": [
+                    {
+                        "description": "Steps",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.StartSequenceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.Sequence"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid steps, or the first could not be set, e.g. the furnace is stopped",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A sequence is already running",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/furnace/sequence/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reports a running or finished sequence with its status and step.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Get mode sequence",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sequence ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Sequence"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/furnace/start": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Start furnace",
+                "responses": {
+                    "200": {
+                        "description": "status, state",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/furnace/state": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Carries an ETag and Last-Modified; pollers sending the ETag back in If-None-Match get 304 without a body until the state changes.\nAccept: application/xml (or text/xml) renders the state as XML, text/csv as a header row and one row; field names are the canonical JSON ones, lists are joined with \";\" in CSV.",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Get furnace state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of the state the client holds",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "HTTP date; ignored when If-None-Match is sent",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "304": {
+                        "description": "State unchanged"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/furnace/state.prom": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the current state as OpenMetrics gauges for scrapers and scripts that do not parse JSON.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Get furnace state as OpenMetrics",
+                "responses": {
+                    "200": {
+                        "description": "OpenMetrics text exposition",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/furnace/stop": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Stop furnace",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/incidents": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns alarm episodes, newest first, without their events and telemetry. An incident opens when the first error code is raised and closes when none remain; open incidents have no ended_at. overheat_s is the time spent above the safe limit; resolution tells whether the alarms cleared with the furnace running (cleared) or off (stopped).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "List incidents",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). Date-only treated as end of day.",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only incidents that raised this alarm code, e.g. OVERHEAT",
+                        "name": "alarm",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum incidents (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "count, incidents",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/incidents/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the incident with the events logged during the episode and a temperature excerpt from a minute before to a minute after. Both are compiled when the incident closes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Get incident",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Incident ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Incident"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/incidents/{id}/ack": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Records the caller as the acknowledging operator. Open incidents can be acknowledged; only the first acknowledgement counts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Acknowledge incident",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Incident ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Incident"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/incidents/{id}/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Downloads the incident report as Markdown for post-mortems, or as JSON.",
+                "produces": [
+                    "text/markdown",
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Export incident",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Incident ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "markdown",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "Report format (default markdown)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/logs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Filter logs by date (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). If 'to' is date-only, it is treated as end-of-day inclusive (23:59:59.999999999Z).\nWith format=ndjson (or Accept: application/x-ndjson) events are streamed one JSON object per line as they are read, for ranges too large to buffer. A failure after streaming started ends the body with an {\"error\": ...} line.\nmeta.* parameters filter on the recorded metadata: meta.to=COOL keeps mode changes to COOL, meta.temp_c\u003e1000 events logged above 1000 °C. Numbers and booleans compare as such; up to 8 filters may be combined.\nPassing limit, cursor or order returns one page (default 100, max 1000 events) with a next_cursor to pass back for the following page; it is omitted on the last page. Without them every matching event is returned.\nAccept: application/xml (or text/xml) renders the events as XML, text/csv as a header row and a row per event with the metadata as JSON; field names are the canonical JSON ones. A page's next cursor is then sent in the X-Next-Cursor header.",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/xml",
+                    "text/csv"
+                ],
+                "tags": [
+                    "logs"
+                ],
+                "summary": "List logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "TELEMETRY",
+                        "description": "Comma-separated event types to leave out",
+                        "name": "exclude_type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Response format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-08-01",
+                        "description": "Start of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also list soft-deleted events, with deleted_at, deleted_by and delete_reason (admins only)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Events per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort by occurrence time",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events recorded during the given heat cycle",
+                        "name": "run_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "info",
+                            "warning",
+                            "critical"
+                        ],
+                        "type": "string",
+                        "description": "Only event types the catalog rates at least this severe (see GET /logs/schema)",
+                        "name": "severity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-08-31",
+                        "description": "End of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). Date-only treated as end of day.",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "START,STOP",
+                        "description": "Event type, or a comma-separated list of types to include",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Compare a metadata value, e.g. meta.to=COOL, meta.temp_c\u003e1000 or meta.limits.max_c\u003c=1200 (=, !=, \u003c, \u003c=, \u003e, \u003e=). Repeatable; all must match, and events without the key never do.",
+                        "name": "meta.{key}",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "count, events, next_cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/logs/purge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes the oldest events beyond an age or row limit, in insertion order; limits omitted from the body fall back to events.retention. With an archive directory configured the rows are first written there as gzipped NDJSON. The hash chain over the remaining events stays verifiable, and a LOG_PURGED event records the purge. dry_run reports the selection without deleting. The body may be omitted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "logs"
+                ],
+                "summary": "Purge old events",
+                "parameters": [
+                    {
+                        "description": "Limits and dry run",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PurgeLogsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PurgeReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/logs/schema": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the catalog of event types the service logs: each type's default severity (info, warning or critical) and the metadata fields it carries, with their JSON type and whether every event of the type has them. Fields in common may appear on any event. The field definitions are generated from the Go metadata structs, so clients can build decoders and validation from them. Events imported from CSV keep their source's types and are not listed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "logs"
+                ],
+                "summary": "Describe event types",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.EventCatalog"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/logs/tail": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns only the events appended after after_id, in the order they were appended, so clients can follow the log by polling without a WebSocket: pass next_after_id back as after_id each time. after_ts (RFC3339) starts from events that occurred after a time instead; with neither, the tail starts at the end of the log and only next_after_id is returned.\nWith wait (e.g. 30s, at most 1m) the request is held until an event arrives or the wait is over (long poll); it then answers with no events and the same next_after_id. type, exclude_type, run_id and meta.* filter as for GET /logs.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "logs"
+                ],
+                "summary": "Tail logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "next_after_id of the previous read",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events that occurred after this time (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')",
+                        "name": "after_ts",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "30s",
+                        "description": "Long-poll up to this long for a new event (Go duration, max 1m)",
+                        "name": "wait",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Maximum events (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event type, or a comma-separated list of types to include",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated event types to leave out",
+                        "name": "exclude_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events recorded during the given heat cycle",
+                        "name": "run_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Compare a metadata value, as for GET /logs",
+                        "name": "meta.{key}",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "count, events, next_after_id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/logs/verify": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Walks the event log hash chain and reports rows that were edited (tampered), removed or reordered (gap), or written without a hash (unhashed). Requires events.hash_chain; events logged before it was enabled are counted as unchained. Truncation at the end is only detectable by comparing head with a previously recorded value.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "logs"
+                ],
+                "summary": "Verify event log integrity",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChainReport"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/logs/{event_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Soft-deletes an event holding mistaken data: it is no longer listed by GET /logs (unless an admin passes include_deleted=true) or /logs/tail, but stays in the database with who deleted it, when and why. The hash chain still covers it, audit exports include it and retention purges it like any other event. It cannot be undone through the API.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "logs"
+                ],
+                "summary": "Delete an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DeleteEventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/logs/{event_id}/comments": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Attaches a note by the calling user to a logged event, e.g. the cause of an alarm for the next shift. Comments are returned with the event by GET /logs (except as NDJSON) and GET /logs/tail, oldest first, and are removed when the event is purged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "logs"
+                ],
+                "summary": "Comment on an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EventCommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.EventComment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/maintenance/records": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns who completed which task and when, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "List maintenance records",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only records of this task",
+                        "name": "task_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum records (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "count, records",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/maintenance/tasks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns every task with its due date (due_at), the heating hours it is due at (due_hours) and whether it is overdue.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "List maintenance tasks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MaintenanceTask"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The first interval starts now. When the task falls due, a MAINTENANCE_OVERDUE event is logged once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Create maintenance task",
+                "parameters": [
+                    {
+                        "description": "Task",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MaintenanceTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceTask"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/maintenance/tasks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Get maintenance task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceTask"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The interval still counts from the last completion.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Replace maintenance task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Task",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MaintenanceTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceTask"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Completion records of the task are kept.",
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Delete maintenance task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/maintenance/tasks/{id}/complete": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Records the calling user as having done the task now and starts its next interval. Completing an element_replacement task also restarts heater wear, so the ramp rate is nominal again. Logs MAINTENANCE_DONE.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Complete maintenance task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Note",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CompleteMaintenanceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/runs/{run_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the record of a heat cycle, including soak stability (share of soak time within tolerance and temperature standard deviation).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "runs"
+                ],
+                "summary": "Get run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "run_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Run"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/setup": {
+            "get": {
+                "description": "Reports whether the installation still needs first-run setup (no users exist yet) and the chosen display units.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "setup"
+                ],
+                "summary": "Setup status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.SetupStatus"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Configures an empty installation: creates the first admin, stores the token signing key, display units and safety limit, and returns a token for the admin. Only available while no users exist; sign-up is refused until then.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "setup"
+                ],
+                "summary": "Complete setup",
+                "parameters": [
+                    {
+                        "description": "First-run configuration",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Setup already completed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sim/ambient": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the room temperature the chamber converges to, and whether it follows the configured daily profile or an override.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "simulator"
+                ],
+                "summary": "Get ambient temperature",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.AmbientStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Holds the room at a fixed temperature from the next tick on, replacing the daily profile, e.g. to simulate an outdoor installation in winter. Not persisted across restarts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "simulator"
+                ],
+                "summary": "Set ambient temperature",
+                "parameters": [
+                    {
+                        "description": "Ambient",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetAmbientRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.AmbientStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the room temperature to the configured daily profile.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "simulator"
+                ],
+                "summary": "Clear ambient override",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.AmbientStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sim/config": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "simulator"
+                ],
+                "summary": "Get simulator settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SimSettings"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the simulator physics, sensor noise and tick interval. Send the full object as returned by GET. The tick changes immediately, the rest on the next simulation step; values are persisted across restarts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "simulator"
+                ],
+                "summary": "Update simulator settings",
+                "parameters": [
+                    {
+                        "description": "Settings",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SimSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SimSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sim/faults": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "simulator"
+                ],
+                "summary": "List simulator faults",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FaultsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Makes the simulator exhibit a hardware failure. It is reported on the next tick as an error code and an ERROR event, and lasts until cleared.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "simulator"
+                ],
+                "summary": "Inject simulator fault",
+                "parameters": [
+                    {
+                        "description": "Fault",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InjectFaultRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FaultsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "simulator"
+                ],
+                "summary": "Clear all simulator faults",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FaultsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sim/faults/{type}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "simulator"
+                ],
+                "summary": "Clear simulator fault",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Fault type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FaultsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sim/speed": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "simulator"
+                ],
+                "summary": "Get simulation speed",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SimSpeedDTO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes the simulator tick and time acceleration at runtime. With time_scale=60 a 2-hour heat cycle completes in 2 minutes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "simulator"
+                ],
+                "summary": "Set simulation speed",
+                "parameters": [
+                    {
+                        "description": "Speed",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SimSpeedDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SimSpeedDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/system/info": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Goroutine count, heap usage, database connection pool statistics, uptime and build version of the running instance. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Runtime diagnostics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.SystemInfo"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/telemetry": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns recorded sensor samples, oldest first. Channels: chamber (measured chamber temperature) and ambient (cold-junction temperature).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "telemetry"
+                ],
+                "summary": "List telemetry",
+                "parameters": [
+                    {
+                        "enum": [
+                            "chamber",
+                            "ambient",
+                            "o2",
+                            "gas_flow"
+                        ],
+                        "type": "string",
+                        "description": "Channel",
+                        "name": "channel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). Date-only treated as end of day.",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum samples (default 1000, max 10000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "count, samples",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Events of the listed types logged from now on are POSTed to the URL as JSON, with X-Furnace-Event and X-Furnace-Delivery headers. With a secret, X-Furnace-Signature carries \"sha256=\" and the hex HMAC-SHA256 of the body. Non-2xx answers are retried with exponential backoff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Register webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Omit secret to keep the stored one. Deliveries queued while the webhook is disabled fail without being sent.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Replace webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Also removes its deliveries, including pending ones.",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the deliveries of a webhook, newest first, with the attempts made, the last HTTP status or error and, while pending, the next attempt.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "delivered",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Only deliveries with this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum deliveries (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "count, deliveries",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}/deliveries/{delivery_id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queues a failed delivery again with its original payload and a fresh set of attempts; the dispatcher sends it on its next pass. The webhook must be enabled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Replay webhook delivery",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/furnaces": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the furnaces this instance controls: one, named after its site.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "List furnaces",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FurnaceList"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/furnaces/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Get furnace",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Furnace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FurnaceResource"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Starts or stops the furnace, sets its mode and target, and switches the protective gas, in that order; omitted fields stay as they are. Asking for the running state the furnace is already in changes nothing. HEAT requires target_temp_c and duration_sec. A step that fails leaves the ones before it done. Answers with the furnace as it is afterwards.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Change furnace",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Furnace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Changes",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PatchFurnaceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FurnaceResource"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/furnaces/{id}/charge": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "GET /api/v1/furnace/charge for a furnace.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Furnace charge",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Furnace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.ChargeStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "POST /api/v1/furnace/charge for a furnace.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Insert furnace charge",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Furnace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Charge",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InsertChargeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/service.ChargeStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A charge is already in the furnace",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "DELETE /api/v1/furnace/charge for a furnace.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Remove furnace charge",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Furnace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.ChargeStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "No such furnace, or no charge in it",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/furnaces/{id}/health": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "GET /api/v1/furnace/health for a furnace.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Furnace heater health",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Furnace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FurnaceHealth"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/furnaces/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "GET /api/v1/furnace/history for a furnace.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Furnace temperature history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Furnace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). Date-only treated as end of day.",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bucket width in whole seconds, as a number or a duration such as 5m",
+                        "name": "resolution",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TemperatureHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/furnaces/{id}/readiness": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "GET /api/v1/furnace/readiness for a furnace.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "furnace"
+                ],
+                "summary": "Furnace readiness",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Furnace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "example": 850,
+                        "description": "Target temperature in Celsius",
+                        "name": "target_temp_c",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 600,
+                        "description": "Heating duration in seconds",
+                        "name": "duration_sec",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Readiness"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sign-in": {
+            "post": {
+                "description": "Authenticate user and return a JWT token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Sign in",
+                "operationId": "authSignIn",
+                "parameters": [
+                    {
+                        "description": "User credentials",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AuthCredentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many attempts from this address",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sign-up": {
+            "post": {
+                "description": "Register a new user as a viewer; an admin grants more with PUT /api/v1/admin/users/{id}/role. An empty installation answers 409 until the first admin is created through POST /api/v1/setup. The username is trimmed, NFC-normalized and checked against the configured policy (auth.usernames); a rejected name answers 400 with \"reason\" set to empty, too_short, too_long, invalid_character or reserved. Names are unique regardless of case: a taken one answers 409.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Sign up",
+                "operationId": "authSignUp",
+                "parameters": [
+                    {
+                        "description": "User credentials",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AuthCredentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SignUpResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Setup required or username taken",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many attempts from this address",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Reports that the process is serving HTTP; it checks no dependencies. /health is kept as an alias.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Reports that the process is serving HTTP; it checks no dependencies. /health is kept as an alias.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics": {
+            "get": {
+                "description": "The furnace state gauges of /api/v1/furnace/state.prom plus build and runtime gauges and the panic count of the process, as OpenMetrics. Served on the admin listener only, without a token.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Metrics",
+                "responses": {
+                    "200": {
+                        "description": "OpenMetrics text exposition",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Checks that SQLite accepts writes, that schema migrations are applied and that the simulator ticked recently. Responds 503 with the failing components otherwise.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.ReadinessReport"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/service.ReadinessReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/status/badge.svg": {
+            "get": {
+                "description": "Public when status.public is enabled. An SVG badge with the current status and its availability, for embedding in wikis and dashboards. Shows \"unknown\" if the status cannot be loaded.",
+                "produces": [
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Status badge",
+                "parameters": [
+                    {
+                        "enum": [
+                            "24h",
+                            "7d"
+                        ],
+                        "type": "string",
+                        "description": "Availability window (default 24h)",
+                        "name": "window",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "SVG image",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/status/uptime": {
+            "get": {
+                "description": "Public when status.public is enabled. Reports whether the last readiness check passed and the availability over the last 24 hours and 7 days; checks missed while the service was stopped count as down.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Uptime summary",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UptimeSummary"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "Reports the build version and commit, injected at build time, and the state and event schema versions served, so fleet tooling can check what each controller runs. Public.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Build version",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.BuildInfo"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "description": "Establish a WebSocket connection that streams current furnace state periodically.\nQuery params:\n- interval: Go duration string (e.g., 500ms, 2s). Range: min_interval..max_interval (250ms..10s by default).\n- interval_ms: integer milliseconds. Same range in ms.\n- token: JWT, as an alternative to the Authorization header. Roles may have a higher minimum interval.\nThe stream requires the same permission as GET /api/v1/furnace/state (a valid token by default, or none if api.permissions makes that route public); otherwise the upgrade is refused with 401 or 403.\n- schema_version: render states in an older payload contract (same as the X-Schema-Version header on REST).\n- events: also stream the event log as \"event\" messages, filtered by type and exclude_type as on GET /api/v1/logs/tail. Requires the permission of that route as well.\nRequests below the caller's minimum are clamped and announced with a \"notice\" message, or, if the server is configured to reject them, answered with an \"error\" message and closed.\nWith events, every state and event message carries a resume token for the client's position in the log. Reconnecting with ?resume=\u003ctoken\u003e (which implies events) first replays the events appended since, then continues live; if that point was purged, a \"notice\" says events may be missing and the replay continues by time.\nWhen the server shuts down, or fails its readiness check at a keepalive ping, it sends a \"goaway\" message before closing with 1001 (going away); its data holds the reason, retry_after_ms (a jittered reconnect delay) and, if configured, an alternate endpoint to reconnect to.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "websockets"
+                ],
+                "summary": "WebSocket: live furnace state stream",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Update interval as Go duration (e.g. 500ms, 2s). 250ms-10s by default.",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Update interval in milliseconds. Range: 250-10000 by default.",
+                        "name": "interval_ms",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JWT authorizing the stream and picking the per-role minimum interval",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "State payload version; current when omitted",
+                        "name": "schema_version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also stream the event log",
+                        "name": "events",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated event types to stream",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated event types to leave out",
+                        "name": "exclude_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resume token from the last message received; replays missed events",
+                        "name": "resume",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols (WebSocket upgrade)",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "Connection": {
+                                "type": "string",
+                                "description": "Upgrade"
+                            },
+                            "Upgrade": {
+                                "type": "string",
+                                "description": "websocket"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request (invalid parameters or upgrade failure)",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Role may not read the furnace state or the event log",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error during upgrade",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.APIIndex": {
+            "type": "object",
+            "properties": {
+                "docs": {
+                    "type": "string",
+                    "example": "/swagger/index.html"
+                },
+                "endpoints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.Endpoint"
+                    }
+                },
+                "schema_version": {
+                    "type": "integer",
+                    "example": 9
+                },
+                "version": {
+                    "type": "string",
+                    "example": "v1"
+                }
+            }
+        },
+        "handlers.AlertRuleRequest": {
+            "type": "object",
+            "required": [
+                "kind",
+                "name"
+            ],
+            "properties": {
+                "enabled": {
+                    "description": "Defaults to true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "for_seconds": {
+                    "description": "How long the condition must hold before the alert fires",
+                    "type": "integer",
+                    "example": 30
+                },
+                "kind": {
+                    "description": "Allowed: temp_above, remaining_below, error_event",
+                    "type": "string",
+                    "example": "temp_above"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Too hot"
+                },
+                "threshold": {
+                    "description": "°C for temp_above, seconds for remaining_below; ignored for error_event",
+                    "type": "number",
+                    "example": 950
+                }
+            }
+        },
+        "handlers.AtmosphereRequest": {
+            "type": "object",
+            "properties": {
+                "flow_m3h": {
+                    "description": "Gas flow setpoint in m³/h",
+                    "type": "number",
+                    "example": 20
+                },
+                "gas": {
+                    "description": "Purge gas. Allowed: N2, AR",
+                    "type": "string",
+                    "example": "N2"
+                },
+                "max_o2_ppm": {
+                    "description": "Residual oxygen that raises O2_HIGH while hot; the configured default when omitted",
+                    "type": "number",
+                    "example": 50
+                }
+            }
+        },
+        "handlers.AuthCredentials": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ChaosSettingsDTO": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "description": "Whether faults are currently injected",
+                    "type": "boolean",
+                    "example": true
+                },
+                "error_probability": {
+                    "description": "Probability [0..1] that a call fails",
+                    "type": "number",
+                    "example": 0.05
+                },
+                "latency_ms": {
+                    "description": "Artificial latency added to affected repository calls",
+                    "type": "integer",
+                    "example": 250
+                },
+                "latency_probability": {
+                    "description": "Probability [0..1] that a call is delayed",
+                    "type": "number",
+                    "example": 0.2
+                }
+            }
+        },
+        "handlers.CompleteMaintenanceRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "example": "Replaced all six elements"
+                }
+            }
+        },
+        "handlers.DeleteEventRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Test entry logged on the production furnace"
+                }
+            }
+        },
+        "handlers.Endpoint": {
+            "type": "object",
+            "properties": {
+                "method": {
+                    "type": "string",
+                    "example": "GET"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/api/v1/furnace/sequence/{id}"
+                },
+                "permission": {
+                    "type": "string",
+                    "example": "read"
+                }
+            }
+        },
+        "handlers.EventCommentRequest": {
+            "type": "object",
+            "required": [
+                "text"
+            ],
+            "properties": {
+                "text": {
+                    "type": "string",
+                    "example": "Overheat was caused by the door left open"
+                }
+            }
+        },
+        "handlers.FaultsResponse": {
+            "type": "object",
+            "properties": {
+                "faults": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ActiveFault"
+                    }
+                }
+            }
+        },
+        "handlers.FurnaceList": {
+            "type": "object",
+            "properties": {
+                "furnaces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.FurnaceResource"
+                    }
+                }
+            }
+        },
+        "handlers.FurnaceResource": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "Furnace ID: the site the instance serves",
+                    "type": "string",
+                    "example": "default"
+                },
+                "state": {
+                    "$ref": "#/definitions/models.FurnaceState"
+                }
+            }
+        },
+        "handlers.InjectFaultRequest": {
+            "type": "object",
+            "required": [
+                "type"
+            ],
+            "properties": {
+                "type": {
+                    "description": "Fault to inject. Allowed: STUCK_SENSOR, HEATER_FAILURE, POWER_LOSS, THERMOCOUPLE_BREAK",
+                    "type": "string",
+                    "example": "HEATER_FAILURE"
+                }
+            }
+        },
+        "handlers.InsertChargeRequest": {
+            "type": "object",
+            "required": [
+                "mass_kg"
+            ],
+            "properties": {
+                "mass_kg": {
+                    "description": "Mass of the load",
+                    "type": "number",
+                    "example": 800
+                },
+                "specific_heat_j_per_kg_k": {
+                    "description": "Specific heat; carbon steel (490) when omitted",
+                    "type": "number",
+                    "example": 490
+                },
+                "temp_c": {
+                    "description": "Temperature of the load; room temperature when omitted",
+                    "type": "number",
+                    "example": 20
+                }
+            }
+        },
+        "handlers.MaintenanceTaskRequest": {
+            "type": "object",
+            "required": [
+                "kind",
+                "name"
+            ],
+            "properties": {
+                "interval_days": {
+                    "description": "Calendar days between completions",
+                    "type": "integer",
+                    "example": 365
+                },
+                "interval_hours": {
+                    "description": "Heating hours between completions",
+                    "type": "number",
+                    "example": 2000
+                },
+                "kind": {
+                    "description": "Allowed: calibration, element_replacement, inspection",
+                    "type": "string",
+                    "example": "element_replacement"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Replace heating elements"
+                },
+                "part": {
+                    "description": "Spare part consumed, copied to each completion record",
+                    "type": "string",
+                    "example": "KANTHAL-A1-8MM"
+                }
+            }
+        },
+        "handlers.PatchFurnaceRequest": {
+            "type": "object",
+            "properties": {
+                "atmosphere": {
+                    "description": "Protective gas to switch to",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.AtmosphereRequest"
+                        }
+                    ]
+                },
+                "duration_sec": {
+                    "description": "Heating duration in seconds (with mode HEAT)",
+                    "type": "integer",
+                    "example": 600
+                },
+                "mode": {
+                    "description": "Mode to set. Allowed: HEAT, COOL, STANDBY",
+                    "type": "string",
+                    "example": "HEAT"
+                },
+                "running": {
+                    "description": "Start (true) or stop (false) the furnace; stopping also switches to STANDBY",
+                    "type": "boolean",
+                    "example": true
+                },
+                "target_temp_c": {
+                    "description": "Target temperature in Celsius (with mode HEAT)",
+                    "type": "number",
+                    "example": 850
+                }
+            }
+        },
+        "handlers.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Machine-readable cause, e.g. invalid_body or event_not_found",
+                    "type": "string",
+                    "example": "event_not_found"
+                },
+                "detail": {
+                    "description": "What went wrong with this request",
+                    "type": "string",
+                    "example": "event not found"
+                },
+                "detail_en": {
+                    "description": "The English detail, when detail was translated after Accept-Language",
+                    "type": "string",
+                    "example": "event not found"
+                },
+                "error": {
+                    "description": "The same as detail, for clients of the earlier {\"error\": ...} body",
+                    "type": "string",
+                    "example": "event not found"
+                },
+                "instance": {
+                    "description": "Path of the request",
+                    "type": "string",
+                    "example": "/api/v1/logs/e42/comments"
+                },
+                "reason": {
+                    "description": "Rule a rejected username broke, e.g. too_long",
+                    "type": "string",
+                    "example": "too_long"
+                },
+                "request_id": {
+                    "description": "X-Request-ID of the request, to quote when reporting an internal error",
+                    "type": "string",
+                    "example": "7b0c6f2e-4b7c-4f8e-9a51-2d1f0f6c9e3a"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "description": "Text of the status code",
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "description": "URI naming the kind of problem: urn:furnace:problem:\u003ccode\u003e",
+                    "type": "string",
+                    "example": "urn:furnace:problem:event_not_found"
+                }
+            }
+        },
+        "handlers.PurgeLogsRequest": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "description": "Report what would be removed without deleting anything",
+                    "type": "boolean",
+                    "example": true
+                },
+                "max_age": {
+                    "description": "Remove events older than this Go duration; the configured max_age when omitted",
+                    "type": "string",
+                    "example": "720h"
+                },
+                "max_rows": {
+                    "description": "Keep only this many of the newest events; the configured max_rows when omitted",
+                    "type": "integer",
+                    "example": 100000
+                }
+            }
+        },
+        "handlers.SetAmbientRequest": {
+            "type": "object",
+            "required": [
+                "temp_c"
+            ],
+            "properties": {
+                "temp_c": {
+                    "description": "Room temperature in Celsius the chamber cools (or warms) toward",
+                    "type": "number",
+                    "example": -10
+                }
+            }
+        },
+        "handlers.SetModeRequest": {
+            "type": "object",
+            "properties": {
+                "atmosphere": {
+                    "description": "Protective gas to switch to with the mode; unchanged when omitted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.AtmosphereRequest"
+                        }
+                    ]
+                },
+                "duration_sec": {
+                    "description": "Heating duration in seconds (required when mode=HEAT)",
+                    "type": "integer",
+                    "example": 600
+                },
+                "mode": {
+                    "description": "Mode to set. Allowed: HEAT, COOL, STANDBY",
+                    "type": "string",
+                    "example": "HEAT"
+                },
+                "target_temp_c": {
+                    "description": "Target temperature in Celsius (required when mode=HEAT)",
+                    "type": "number",
+                    "example": 850
+                }
+            }
+        },
+        "handlers.SetupRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "max_safe_c": {
+                    "description": "OVERHEAT threshold in °C; the configured value is kept when omitted",
+                    "type": "number",
+                    "example": 1000
+                },
+                "password": {
+                    "type": "string"
+                },
+                "signing_key": {
+                    "description": "HMAC key for API tokens, at least 32 bytes; generated when omitted",
+                    "type": "string"
+                },
+                "units": {
+                    "description": "Display units for clients: C (default) or F; the API always reports °C",
+                    "type": "string",
+                    "example": "C"
+                },
+                "username": {
+                    "description": "First admin account",
+                    "type": "string",
+                    "example": "admin"
+                }
+            }
+        },
+        "handlers.SignUpResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.SimSpeedDTO": {
+            "type": "object",
+            "properties": {
+                "tick_ms": {
+                    "description": "Interval between simulation steps",
+                    "type": "integer",
+                    "example": 1000
+                },
+                "time_scale": {
+                    "description": "Simulated seconds per wall-clock second",
+                    "type": "number",
+                    "example": 60
+                }
+            }
+        },
+        "handlers.StartSequenceRequest": {
+            "type": "object",
+            "required": [
+                "steps"
+            ],
+            "properties": {
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SequenceStep"
+                    }
+                }
+            }
+        },
+        "handlers.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.UserRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "description": "New role of the user",
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "operator",
+                        "viewer"
+                    ],
+                    "example": "operator"
+                }
+            }
+        },
+        "handlers.UserRoleResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "role": {
+                    "type": "string",
+                    "example": "operator"
+                }
+            }
+        },
+        "handlers.WebhookRequest": {
+            "type": "object",
+            "required": [
+                "event_types",
+                "url"
+            ],
+            "properties": {
+                "enabled": {
+                    "description": "Defaults to true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "event_types": {
+                    "description": "Event types delivered to the URL",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "STOP",
+                        "ERROR"
+                    ]
+                },
+                "secret": {
+                    "description": "Keys the X-Furnace-Signature HMAC; on replace, omit to keep the stored secret",
+                    "type": "string",
+                    "example": "s3cret"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://mes.example.com/hooks/furnace"
+                }
+            }
+        },
+        "models.AlertRule": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "for_seconds": {
+                    "description": "ForSeconds is how long the condition must hold before the alert fires.",
+                    "type": "integer",
+                    "example": 30
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "temp_above"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Too hot"
+                },
+                "threshold": {
+                    "description": "°C or seconds, by kind; unused for error_event",
+                    "type": "number",
+                    "example": 950
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BackupReport": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer",
+                    "example": 1048576
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "file": {
+                    "type": "string",
+                    "example": "furnace-20250920T100000Z.db"
+                },
+                "path": {
+                    "description": "Path is where the snapshot was stored; omitted for a download.",
+                    "type": "string",
+                    "example": "backups/furnace-20250920T100000Z.db"
+                },
+                "sha256": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                }
+            }
+        },
+        "models.ChainProblem": {
+            "type": "object",
+            "properties": {
+                "event_id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "tampered"
+                },
+                "occurred_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChainReport": {
+            "type": "object",
+            "properties": {
+                "checked": {
+                    "description": "rows verified against the chain",
+                    "type": "integer"
+                },
+                "enabled": {
+                    "description": "new events are being chained",
+                    "type": "boolean"
+                },
+                "head": {
+                    "description": "Head is the hash of the newest row. Removing rows from the end of the\nlog leaves a valid shorter chain, so keep a copy elsewhere to detect it.",
+                    "type": "string"
+                },
+                "problems": {
+                    "description": "the first MaxChainProblems found",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ChainProblem"
+                    }
+                },
+                "total_problems": {
+                    "type": "integer"
+                },
+                "unchained": {
+                    "description": "rows written before the chain started",
+                    "type": "integer"
+                },
+                "valid": {
+                    "description": "no problems were found",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.EventCatalog": {
+            "type": "object",
+            "properties": {
+                "common": {
+                    "description": "Common are metadata fields any event may carry in addition to its\nown: the run it belongs to and the request and user that caused it.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EventField"
+                    }
+                },
+                "schema_version": {
+                    "description": "see SchemaVersion",
+                    "type": "integer",
+                    "example": 9
+                },
+                "types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EventSchema"
+                    }
+                }
+            }
+        },
+        "models.EventComment": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Overheat was caused by the door left open"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.EventField": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "°C, requested HEAT target"
+                },
+                "format": {
+                    "description": "for strings with a fixed format",
+                    "type": "string",
+                    "example": "date-time"
+                },
+                "items": {
+                    "description": "element type of arrays",
+                    "type": "string",
+                    "example": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "target_temp_c"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "description": "JSON type: string, number, integer, boolean, array or object",
+                    "type": "string",
+                    "example": "number"
+                }
+            }
+        },
+        "models.EventSchema": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "The mode was changed by a command or when a soak ended."
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EventField"
+                    }
+                },
+                "severity": {
+                    "description": "info | warning | critical",
+                    "type": "string",
+                    "example": "info"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "MODE_CHANGE"
+                }
+            }
+        },
+        "models.FurnaceEvent": {
+            "type": "object",
+            "properties": {
+                "comments": {
+                    "description": "Comments are the operators' notes on the event, oldest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EventComment"
+                    }
+                },
+                "delete_reason": {
+                    "description": "why it was deleted",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt is set on an event an admin soft-deleted, e.g. for holding\nmistaken data; such events are only listed on request.",
+                    "type": "string"
+                },
+                "deleted_by": {
+                    "description": "user ID of the admin",
+                    "type": "integer"
+                },
+                "description": {
+                    "description": "human-readable",
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "metadata": {},
+                "occurred_at": {
+                    "type": "string"
+                },
+                "schema_version": {
+                    "description": "see SchemaVersion; set when encoding",
+                    "type": "integer",
+                    "example": 9
+                },
+                "type": {
+                    "description": "START | STOP | MODE_CHANGE | ERROR | ...; see EventCatalog",
+                    "type": "string"
+                }
+            }
+        },
+        "models.FurnaceHealth": {
+            "type": "object",
+            "properties": {
+                "cycles": {
+                    "description": "heat cycles (runs) started",
+                    "type": "integer",
+                    "example": 12
+                },
+                "element_hours": {
+                    "description": "heating hours of the current elements",
+                    "type": "number",
+                    "example": 2
+                },
+                "heating_hours": {
+                    "type": "number",
+                    "example": 2
+                },
+                "heating_seconds": {
+                    "description": "time the elements were energized in HEAT",
+                    "type": "number",
+                    "example": 7200
+                },
+                "last_run_id": {
+                    "description": "last run counted as a cycle",
+                    "type": "string"
+                },
+                "maintenance_cycles": {
+                    "description": "cycles at which maintenance is due; 0 if unset",
+                    "type": "integer",
+                    "example": 5000
+                },
+                "maintenance_due": {
+                    "description": "MAINTENANCE_DUE has been raised",
+                    "type": "boolean"
+                },
+                "maintenance_hours": {
+                    "description": "heating hours at which maintenance is due; 0 if unset",
+                    "type": "number",
+                    "example": 2000
+                },
+                "ramp_degradation": {
+                    "description": "share of the nominal ramp rate lost to wear, 0..1",
+                    "type": "number",
+                    "example": 0.0034
+                },
+                "ramp_up_c_per_sec": {
+                    "description": "effective heating rate",
+                    "type": "number",
+                    "example": 2.99
+                },
+                "replaced_cycles": {
+                    "type": "integer"
+                },
+                "replaced_heating_seconds": {
+                    "description": "ReplacedHeatingSeconds and ReplacedCycles are the counters when the\nelements were last replaced; wear counts from there.",
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FurnaceState": {
+            "type": "object",
+            "properties": {
+                "ambient_temp_c": {
+                    "description": "°C, cold-junction sensor",
+                    "type": "number"
+                },
+                "current_temp_c": {
+                    "description": "°C, true (simulated) temperature",
+                    "type": "number"
+                },
+                "energy_kwh": {
+                    "description": "kWh consumed by the current or last run",
+                    "type": "number"
+                },
+                "error_codes": {
+                    "description": "e.g. [\"OVERHEAT\", \"SENSOR_FAULT\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "eta_seconds": {
+                    "description": "seconds until the target is reached while heating",
+                    "type": "integer"
+                },
+                "gas": {
+                    "description": "Protective atmosphere. Gas is empty while the chamber is not purged.",
+                    "type": "string"
+                },
+                "gas_flow_m3h": {
+                    "description": "m³/h, measured flow",
+                    "type": "number"
+                },
+                "gas_setpoint_m3h": {
+                    "description": "m³/h, requested purge flow",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_running": {
+                    "type": "boolean"
+                },
+                "keep_warm_c": {
+                    "description": "°C the heater holds while keep-warm is engaged in STANDBY; omitted\nwhile it is not.",
+                    "type": "number"
+                },
+                "max_o2_ppm": {
+                    "description": "ppm, O2_HIGH limit while hot",
+                    "type": "number"
+                },
+                "measured_temp_c": {
+                    "description": "°C, sensor reading incl. noise/drift",
+                    "type": "number"
+                },
+                "mode": {
+                    "description": "HEAT | COOL | STANDBY",
+                    "type": "string"
+                },
+                "o2_ppm": {
+                    "description": "ppm, residual oxygen in the chamber",
+                    "type": "number"
+                },
+                "power_kw": {
+                    "description": "kW, instantaneous draw",
+                    "type": "number"
+                },
+                "rate_c_per_s": {
+                    "description": "Derived from recent history when the state is read; not stored and\nomitted when unknown.",
+                    "type": "number"
+                },
+                "remaining_seconds": {
+                    "description": "seconds",
+                    "type": "integer"
+                },
+                "run_id": {
+                    "description": "active heat cycle, if any",
+                    "type": "string"
+                },
+                "schema_version": {
+                    "description": "see SchemaVersion; set when encoding",
+                    "type": "integer",
+                    "example": 9
+                },
+                "sequence": {
+                    "description": "Sequence running, if any. Not saved with the state: the sequence\ncoordinator shows it again when it resumes the sequence after a\nrestart.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SequenceProgress"
+                        }
+                    ]
+                },
+                "soak_ends_at": {
+                    "description": "UTC time the soak completes while it counts down at target; clients\ncan count down to it between polls.",
+                    "type": "string"
+                },
+                "soak_percent": {
+                    "description": "share of the requested soak completed, 0..100",
+                    "type": "number"
+                },
+                "target_temp_c": {
+                    "description": "°C",
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HistoryBucket": {
+            "type": "object",
+            "properties": {
+                "avg_c": {
+                    "type": "number"
+                },
+                "max_c": {
+                    "type": "number"
+                },
+                "min_c": {
+                    "type": "number"
+                },
+                "runtime_s": {
+                    "description": "seconds spent heating in the interval",
+                    "type": "number"
+                },
+                "samples": {
+                    "type": "integer"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "target_c": {
+                    "description": "highest target in the interval",
+                    "type": "number"
+                }
+            }
+        },
+        "models.Incident": {
+            "type": "object",
+            "properties": {
+                "acked_at": {
+                    "type": "string"
+                },
+                "acked_by": {
+                    "description": "user ID of the acknowledging operator",
+                    "type": "integer"
+                },
+                "acked_by_name": {
+                    "type": "string"
+                },
+                "alarm_codes": {
+                    "description": "AlarmCodes lists every code raised during the episode, in order.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "duration_s": {
+                    "description": "so far, for open incidents",
+                    "type": "number"
+                },
+                "ended_at": {
+                    "description": "nil while alarms are active",
+                    "type": "string"
+                },
+                "events": {
+                    "description": "Filled when the incident closes; omitted from listings.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FurnaceEvent"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "overheat_s": {
+                    "description": "OverheatS is how long the chamber stayed above MaxSafeC (OVERHEAT\nraised) during the episode.",
+                    "type": "number"
+                },
+                "peak_measured_c": {
+                    "description": "highest sensor reading",
+                    "type": "number"
+                },
+                "peak_temp_c": {
+                    "description": "highest chamber temperature",
+                    "type": "number"
+                },
+                "resolution": {
+                    "description": "how it ended; empty while open",
+                    "type": "string",
+                    "example": "cleared"
+                },
+                "run_id": {
+                    "description": "run active when the episode began",
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "telemetry": {
+                    "description": "temperature around the episode",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HistoryBucket"
+                    }
+                }
+            }
+        },
+        "models.MaintenanceRecord": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "completed_by": {
+                    "type": "integer"
+                },
+                "heating_hours": {
+                    "description": "heater hours when completed",
+                    "type": "number",
+                    "example": 2013.5
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Replaced all six elements"
+                },
+                "part": {
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "integer"
+                },
+                "task_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MaintenanceTask": {
+            "type": "object",
+            "properties": {
+                "baseline_at": {
+                    "description": "BaselineAt and BaselineHours are when, and at how many heating hours,\nthe task was last done, or created if never.",
+                    "type": "string"
+                },
+                "baseline_hours": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "due_at": {
+                    "description": "Derived when the task is read.",
+                    "type": "string"
+                },
+                "due_hours": {
+                    "description": "heating hours at which the task is due",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "interval_days": {
+                    "description": "days; 0 if heat-hours only",
+                    "type": "integer",
+                    "example": 365
+                },
+                "interval_hours": {
+                    "description": "heating hours; 0 if calendar only",
+                    "type": "number",
+                    "example": 2000
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "element_replacement"
+                },
+                "last_done_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Replace heating elements"
+                },
+                "overdue": {
+                    "type": "boolean"
+                },
+                "part": {
+                    "description": "spare part consumed, if any",
+                    "type": "string",
+                    "example": "KANTHAL-A1-8MM"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PurgeReport": {
+            "type": "object",
+            "properties": {
+                "archive": {
+                    "description": "Archive is the file the rows were copied to before deletion.",
+                    "type": "string",
+                    "example": "data/archive/events-20250920T100000Z.ndjson.gz"
+                },
+                "deleted": {
+                    "description": "rows removed, or selected in a dry run",
+                    "type": "integer",
+                    "example": 1200
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "newest": {
+                    "type": "string"
+                },
+                "oldest": {
+                    "description": "Oldest and Newest bound the occurred_at of the selected rows; both are\nomitted when nothing was selected.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Run": {
+            "type": "object",
+            "properties": {
+                "energy_kwh": {
+                    "description": "kWh consumed from HEAT until the run ended",
+                    "type": "number"
+                },
+                "run_id": {
+                    "type": "string"
+                },
+                "soak_mean_c": {
+                    "description": "°C, time-weighted mean",
+                    "type": "number"
+                },
+                "soak_seconds": {
+                    "description": "Soak statistics, measured on the sensor reading while holding at target.",
+                    "type": "number"
+                },
+                "soak_stddev_c": {
+                    "description": "°C, time-weighted standard deviation",
+                    "type": "number"
+                },
+                "soak_unstable": {
+                    "description": "SOAK_UNSTABLE was raised for this run",
+                    "type": "boolean"
+                },
+                "soak_within_seconds": {
+                    "description": "part of it within ±tolerance",
+                    "type": "number"
+                },
+                "stability_score": {
+                    "description": "SoakWithinSeconds / SoakSeconds, 0..1",
+                    "type": "number"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "target_temp_c": {
+                    "description": "°C",
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Sequence": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "why it was aborted",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "RUNNING | COMPLETED | ABORTED",
+                    "type": "string"
+                },
+                "step": {
+                    "description": "Step is the 1-based step running, or the last one reached once the\nsequence has ended.",
+                    "type": "integer"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SequenceStep"
+                    }
+                }
+            }
+        },
+        "models.SequenceProgress": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "step": {
+                    "description": "1-based",
+                    "type": "integer"
+                },
+                "steps": {
+                    "description": "total",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SequenceStep": {
+            "type": "object",
+            "properties": {
+                "duration_sec": {
+                    "type": "integer",
+                    "example": 600
+                },
+                "mode": {
+                    "description": "HEAT | COOL | STANDBY",
+                    "type": "string",
+                    "example": "HEAT"
+                },
+                "target_temp_c": {
+                    "description": "°C",
+                    "type": "number",
+                    "example": 850
+                }
+            }
+        },
+        "models.SettingsConflict": {
+            "type": "object",
+            "properties": {
+                "blocking": {
+                    "description": "Blocking conflicts keep the settings from being applied; the others\nare warnings.",
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "active HEAT target 950.0 °C exceeds max_safe_c 900.0 °C"
+                },
+                "rule_id": {
+                    "description": "for alert_rule",
+                    "type": "integer"
+                },
+                "subject": {
+                    "description": "What is affected: state.target_temp_c, state.current_temp_c,\nambient.override or alert_rule",
+                    "type": "string",
+                    "example": "state.target_temp_c"
+                },
+                "value": {
+                    "type": "number",
+                    "example": 950
+                }
+            }
+        },
+        "models.SettingsPreview": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "boolean"
+                },
+                "conflicts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SettingsConflict"
+                    }
+                },
+                "current": {
+                    "$ref": "#/definitions/models.SimSettings"
+                },
+                "error": {
+                    "description": "why the proposed settings are invalid",
+                    "type": "string"
+                },
+                "proposed": {
+                    "$ref": "#/definitions/models.SimSettings"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.SimSettings": {
+            "type": "object",
+            "properties": {
+                "ambient_c": {
+                    "description": "°C, room temperature the chamber cools to",
+                    "type": "number",
+                    "example": 25
+                },
+                "ambient_noise_stddev_c": {
+                    "description": "cold-junction sensor noise, °C",
+                    "type": "number",
+                    "example": 0
+                },
+                "drift_c_per_hour": {
+                    "description": "chamber sensor drift rate",
+                    "type": "number",
+                    "example": 0
+                },
+                "max_drift_c": {
+                    "description": "cap on accumulated drift, °C",
+                    "type": "number",
+                    "example": 5
+                },
+                "max_safe_c": {
+                    "description": "°C, OVERHEAT threshold and highest allowed target",
+                    "type": "number",
+                    "example": 1000
+                },
+                "noise_stddev_c": {
+                    "description": "chamber sensor noise, °C",
+                    "type": "number",
+                    "example": 0
+                },
+                "ramp_down_c_per_sec": {
+                    "description": "cooling rate in COOL",
+                    "type": "number",
+                    "example": 5
+                },
+                "ramp_up_c_per_sec": {
+                    "description": "heating rate in HEAT",
+                    "type": "number",
+                    "example": 3
+                },
+                "standby_cool_c_per_sec": {
+                    "description": "passive cooling rate",
+                    "type": "number",
+                    "example": 0.5
+                },
+                "tick_ms": {
+                    "description": "interval between simulation steps",
+                    "type": "integer",
+                    "example": 1000
+                }
+            }
+        },
+        "models.TemperatureHistory": {
+            "type": "object",
+            "properties": {
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HistoryBucket"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "resolution_s": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UptimeSummary": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "description": "last readiness check",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "up"
+                },
+                "windows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UptimeWindow"
+                    }
+                }
+            }
+        },
+        "models.UptimeWindow": {
+            "type": "object",
+            "properties": {
+                "availability_pct": {
+                    "description": "Omitted when no check was recorded in the window",
+                    "type": "number",
+                    "example": 99.93
+                },
+                "checks": {
+                    "type": "integer",
+                    "example": 1440
+                },
+                "missed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "window": {
+                    "type": "string",
+                    "example": "24h"
+                }
+            }
+        },
+        "models.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "STOP",
+                        "ERROR"
+                    ]
+                },
+                "has_secret": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://mes.example.com/hooks/furnace"
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "STOP"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "description": "duration of the last attempt",
+                    "type": "integer",
+                    "example": 120
+                },
+                "next_attempt_at": {
+                    "description": "while pending",
+                    "type": "string"
+                },
+                "response_snippet": {
+                    "description": "ResponseSnippet is the start of the last response body.",
+                    "type": "string",
+                    "example": "{\"error\":\"unknown furnace\"}"
+                },
+                "response_status": {
+                    "description": "HTTP status of the last attempt",
+                    "type": "integer",
+                    "example": 200
+                },
+                "status": {
+                    "type": "string",
+                    "example": "delivered"
+                },
+                "webhook_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ActiveFault": {
+            "type": "object",
+            "properties": {
+                "injected_at": {
+                    "type": "string",
+                    "example": "2025-09-20T10:00:00Z"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "HEATER_FAILURE"
+                }
+            }
+        },
+        "service.AmbientStatus": {
+            "type": "object",
+            "properties": {
+                "daily_swing_c": {
+                    "description": "amplitude of the daily profile",
+                    "type": "number",
+                    "example": 8
+                },
+                "mean_c": {
+                    "description": "centre of the daily profile",
+                    "type": "number",
+                    "example": 25
+                },
+                "peak_hour": {
+                    "description": "UTC hour of the daily maximum",
+                    "type": "number",
+                    "example": 15
+                },
+                "source": {
+                    "type": "string",
+                    "example": "profile"
+                },
+                "temp_c": {
+                    "type": "number",
+                    "example": 31.5
+                }
+            }
+        },
+        "service.AuditKey": {
+            "type": "object",
+            "properties": {
+                "fingerprint": {
+                    "description": "Hex SHA-256 of the DER-encoded public key",
+                    "type": "string",
+                    "example": "3b6a27bcceb6a42d62a3a8d02a6f0d73653215771de243a63ac048a18b59da29"
+                },
+                "public_key": {
+                    "description": "PEM-encoded Ed25519 public key",
+                    "type": "string"
+                }
+            }
+        },
+        "service.Blocker": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "FURNACE_NOT_RUNNING"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "furnace is stopped, start it first"
+                }
+            }
+        },
+        "service.BuildInfo": {
+            "type": "object",
+            "properties": {
+                "commit": {
+                    "type": "string",
+                    "example": "3f2c9a1b7e4d"
+                },
+                "go_version": {
+                    "type": "string",
+                    "example": "go1.24.4"
+                },
+                "min_schema_version": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "schema_version": {
+                    "description": "SchemaVersion is the state and event payload version served;\nclients may pin any from MinSchemaVersion on.",
+                    "type": "integer",
+                    "example": 9
+                },
+                "version": {
+                    "type": "string",
+                    "example": "v1.4.0"
+                }
+            }
+        },
+        "service.ChargeStatus": {
+            "type": "object",
+            "properties": {
+                "inserted_at": {
+                    "type": "string"
+                },
+                "loaded": {
+                    "type": "boolean"
+                },
+                "mass_kg": {
+                    "type": "number",
+                    "example": 800
+                },
+                "specific_heat_j_per_kg_k": {
+                    "type": "number",
+                    "example": 490
+                },
+                "temp_c": {
+                    "description": "°C, current core temperature of the load",
+                    "type": "number",
+                    "example": 412.5
+                }
+            }
+        },
+        "service.ComponentStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "service.DBStats": {
+            "type": "object",
+            "properties": {
+                "idle": {
+                    "type": "integer"
+                },
+                "in_use": {
+                    "type": "integer"
+                },
+                "max_idle_closed": {
+                    "type": "integer"
+                },
+                "max_idle_time_closed": {
+                    "type": "integer"
+                },
+                "max_lifetime_closed": {
+                    "type": "integer"
+                },
+                "max_open_connections": {
+                    "type": "integer"
+                },
+                "open_connections": {
+                    "type": "integer"
+                },
+                "wait_count": {
+                    "type": "integer"
+                },
+                "wait_ms": {
+                    "description": "total time spent waiting for a connection",
+                    "type": "number"
+                }
+            }
+        },
+        "service.ImportReport": {
+            "type": "object",
+            "properties": {
+                "duplicates": {
+                    "description": "valid records already stored",
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ImportRowError"
+                    }
+                },
+                "imported": {
+                    "description": "records written",
+                    "type": "integer"
+                },
+                "invalid": {
+                    "description": "rows skipped by validation",
+                    "type": "integer"
+                },
+                "rows": {
+                    "description": "data rows read",
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ImportRowError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "line": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.Readiness": {
+            "type": "object",
+            "properties": {
+                "blockers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Blocker"
+                    }
+                },
+                "ready": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "service.ReadinessReport": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/service.ComponentStatus"
+                    }
+                },
+                "ready": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "service.SetupStatus": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "units": {
+                    "type": "string",
+                    "example": "C"
+                }
+            }
+        },
+        "service.SystemInfo": {
+            "type": "object",
+            "properties": {
+                "db": {
+                    "$ref": "#/definitions/service.DBStats"
+                },
+                "go_version": {
+                    "type": "string",
+                    "example": "go1.24.4"
+                },
+                "goroutines": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "heap_alloc_bytes": {
+                    "type": "integer"
+                },
+                "num_gc": {
+                    "type": "integer"
+                },
+                "panics": {
+                    "description": "Panics counts the panics recovered while serving requests since the\nprocess started.",
+                    "type": "integer",
+                    "example": 0
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "state_subscribers": {
+                    "description": "StateSubscribers counts live state consumers: one per WebSocket\nclient plus the alert and incident evaluators.",
+                    "type": "integer",
+                    "example": 3
+                },
+                "uptime_s": {
+                    "type": "number",
+                    "example": 86400
+                },
+                "version": {
+                    "type": "string",
+                    "example": "v1.4.0"
                 }
             }
         }
@@ -0,0 +1,743 @@
+// Package apigen generates the typed API client in pkg/client. Operations
+// come from the Swagger spec that swag builds from the handler annotations;
+// request and response types are copied from the Go declarations the spec
+// names, so the client sends and receives exactly what the server's DTOs
+// and models marshal.
+package apigen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Config selects the inputs of Generate.
+type Config struct {
+	Spec []byte // Swagger 2.0 JSON, e.g. docs/swagger.json
+	// Packages maps the Go package names used in the spec's definitions
+	// (handlers.AuthCredentials, models.Run, ...) to their source
+	// directories. Types they reference in further listed packages are
+	// copied as well.
+	Packages map[string]string
+	Package  string // package clause of the generated file
+}
+
+// Generate returns the formatted source of the typed client.
+func Generate(cfg Config) ([]byte, error) {
+	var sp spec
+	if err := json.Unmarshal(cfg.Spec, &sp); err != nil {
+		return nil, fmt.Errorf("parse spec: %w", err)
+	}
+	g := &generator{
+		types:   make(map[string]map[string]*typeDecl),
+		wanted:  make(map[typeKey]bool),
+		imports: map[string]bool{"context": true, "net/url": true},
+	}
+	for name, dir := range cfg.Packages {
+		decls, err := parsePackage(dir)
+		if err != nil {
+			return nil, fmt.Errorf("parse package %s: %w", name, err)
+		}
+		g.types[name] = decls
+	}
+	ops, err := g.operations(sp)
+	if err != nil {
+		return nil, err
+	}
+	// first pass finds every type the operations reach, the second names
+	// them, avoiding clashes between packages
+	if _, err := g.renderTypes(); err != nil {
+		return nil, err
+	}
+	g.assignNames()
+	types, err := g.renderTypes()
+	if err != nil {
+		return nil, err
+	}
+	var methods bytes.Buffer
+	for _, op := range ops {
+		if err := g.renderOperation(&methods, op); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by apigen from docs/swagger.json and the Go types it names. DO NOT EDIT.\n\npackage %s\n\nimport (\n", cfg.Package)
+	paths := make([]string, 0, len(g.imports))
+	for p := range g.imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		fmt.Fprintf(&out, "\t%q\n", p)
+	}
+	out.WriteString(")\n\n")
+	out.Write(types)
+	out.Write(methods.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+// spec is the part of a Swagger 2.0 document the generator reads.
+type spec struct {
+	Paths map[string]map[string]operation `json:"paths"`
+}
+
+type operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Consumes    []string            `json:"consumes"`
+	Parameters  []parameter         `json:"parameters"`
+	Responses   map[string]response `json:"responses"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Type        string  `json:"type"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *schema `json:"schema"`
+}
+
+type response struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref                  string          `json:"$ref"`
+	Type                 string          `json:"type"`
+	Items                *schema         `json:"items"`
+	AdditionalProperties json.RawMessage `json:"additionalProperties"`
+}
+
+// typeKey identifies a Go type by package name and type name.
+type typeKey struct{ pkg, name string }
+
+// typeDecl is a parsed type declaration with the imports of its file.
+type typeDecl struct {
+	spec    *ast.TypeSpec
+	doc     string
+	imports map[string]string // local name → import path
+}
+
+type generator struct {
+	types   map[string]map[string]*typeDecl
+	wanted  map[typeKey]bool
+	names   map[typeKey]string // set by assignNames
+	imports map[string]bool
+}
+
+// op is an operation ready to render.
+type op struct {
+	name, method, path, summary string
+	pathParams                  []parameter
+	query                       []parameter
+	body                        string // Go type of the JSON body; "" for none
+	rawBody                     string // content type of a raw body; "" for none
+	result                      string // Go type of the response; "" for none
+}
+
+// operations lists the spec's operations sorted by path and method.
+// Operations without a success response, such as the WebSocket upgrade,
+// are left out.
+func (g *generator) operations(sp spec) ([]op, error) {
+	var ops []op
+	for path, methods := range sp.Paths {
+		for method, o := range methods {
+			code := successCode(o.Responses)
+			if code == "" {
+				continue
+			}
+			p := op{name: methodName(o.OperationID, method, path), method: strings.ToUpper(method), path: path, summary: o.Summary}
+			for _, par := range o.Parameters {
+				switch par.In {
+				case "path":
+					p.pathParams = append(p.pathParams, par)
+				case "query":
+					p.query = append(p.query, par)
+				case "body":
+					t, err := g.schemaType(par.Schema, false)
+					if err != nil {
+						return nil, fmt.Errorf("%s %s: body: %w", p.method, path, err)
+					}
+					p.body = t
+				}
+			}
+			if p.body == "" && len(o.Consumes) > 0 && o.Consumes[0] != "application/json" {
+				p.rawBody = o.Consumes[0]
+			}
+			if s := o.Responses[code].Schema; s != nil {
+				t, err := g.schemaType(s, true)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: response: %w", p.method, path, err)
+				}
+				p.result = t
+			}
+			ops = append(ops, p)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].path != ops[j].path {
+			return ops[i].path < ops[j].path
+		}
+		return ops[i].method < ops[j].method
+	})
+	for i := 1; i < len(ops); i++ {
+		if ops[i].name == ops[i-1].name {
+			return nil, fmt.Errorf("operations %s %s and %s %s are both named %s", ops[i-1].method, ops[i-1].path, ops[i].method, ops[i].path, ops[i].name)
+		}
+	}
+	return ops, nil
+}
+
+// successCode returns the lowest 2xx response code of an operation.
+func successCode(responses map[string]response) string {
+	best := ""
+	for code := range responses {
+		if strings.HasPrefix(code, "2") && (best == "" || code < best) {
+			best = code
+		}
+	}
+	return best
+}
+
+// schemaType returns the Go type of a spec schema, recording the types it
+// references. A text or file response (top) is returned as raw bytes.
+func (g *generator) schemaType(s *schema, top bool) (string, error) {
+	if s == nil {
+		return "", fmt.Errorf("missing schema")
+	}
+	if s.Ref != "" {
+		def := strings.TrimPrefix(s.Ref, "#/definitions/")
+		pkg, name, ok := strings.Cut(def, ".")
+		if !ok {
+			return "", fmt.Errorf("definition %q has no package", def)
+		}
+		return g.want(pkg, name)
+	}
+	switch s.Type {
+	case "array":
+		t, err := g.schemaType(s.Items, false)
+		return "[]" + t, err
+	case "object":
+		var elem schema
+		if len(s.AdditionalProperties) == 0 || json.Unmarshal(s.AdditionalProperties, &elem) != nil {
+			return "map[string]any", nil // additionalProperties: true
+		}
+		t, err := g.schemaType(&elem, false)
+		return "map[string]" + t, err
+	case "string", "file":
+		if top {
+			return "[]byte", nil
+		}
+		return "string", nil
+	case "integer":
+		return "int", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	}
+	return "", fmt.Errorf("unsupported schema type %q", s.Type)
+}
+
+// want records the type pkg.name and returns its name in the generated
+// code.
+func (g *generator) want(pkg, name string) (string, error) {
+	if g.types[pkg][name] == nil {
+		return "", fmt.Errorf("type %s.%s not found in the listed packages", pkg, name)
+	}
+	k := typeKey{pkg, name}
+	g.wanted[k] = true
+	if n, ok := g.names[k]; ok {
+		return n, nil
+	}
+	return name, nil
+}
+
+// assignNames keeps each type's own name unless types of several packages
+// share it; those are prefixed with their package name.
+func (g *generator) assignNames() {
+	count := make(map[string]int)
+	for k := range g.wanted {
+		count[k.name]++
+	}
+	g.names = make(map[typeKey]string, len(g.wanted))
+	for k := range g.wanted {
+		if count[k.name] > 1 {
+			g.names[k] = exported(k.pkg) + k.name
+		} else {
+			g.names[k] = k.name
+		}
+	}
+}
+
+// renderTypes renders every wanted type, following their references until
+// no new type is found. Types are ordered by generated name.
+func (g *generator) renderTypes() ([]byte, error) {
+	rendered := make(map[typeKey][]byte)
+	for {
+		var next []typeKey
+		for k := range g.wanted {
+			if rendered[k] == nil {
+				next = append(next, k)
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		for _, k := range next {
+			src, err := g.renderType(k)
+			if err != nil {
+				return nil, err
+			}
+			rendered[k] = src
+		}
+	}
+	keys := make([]typeKey, 0, len(rendered))
+	for k := range rendered {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ni, _ := g.want(keys[i].pkg, keys[i].name)
+		nj, _ := g.want(keys[j].pkg, keys[j].name)
+		return ni < nj
+	})
+	var out bytes.Buffer
+	for _, k := range keys {
+		out.Write(rendered[k])
+	}
+	return out.Bytes(), nil
+}
+
+// renderType renders the declaration of one type.
+func (g *generator) renderType(k typeKey) ([]byte, error) {
+	decl := g.types[k.pkg][k.name]
+	name, _ := g.want(k.pkg, k.name)
+	var out bytes.Buffer
+	writeComment(&out, "", decl.doc, k.name, name)
+	st, ok := decl.spec.Type.(*ast.StructType)
+	if !ok {
+		t, err := g.expr(k.pkg, decl, decl.spec.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", k.pkg, k.name, err)
+		}
+		assign := ""
+		if decl.spec.Assign.IsValid() {
+			assign = "= "
+		}
+		fmt.Fprintf(&out, "type %s %s%s\n\n", name, assign, t)
+		return out.Bytes(), nil
+	}
+	fmt.Fprintf(&out, "type %s struct {\n", name)
+	for _, f := range st.Fields.List {
+		var names []string
+		for _, n := range f.Names {
+			if n.IsExported() {
+				names = append(names, n.Name)
+			}
+		}
+		if len(f.Names) > 0 && len(names) == 0 {
+			continue
+		}
+		tag := ""
+		if f.Tag != nil {
+			raw, _ := strconv.Unquote(f.Tag.Value)
+			j, ok := reflect.StructTag(raw).Lookup("json")
+			if j == "-" {
+				continue
+			}
+			if ok {
+				tag = fmt.Sprintf(" `json:%q`", j)
+			}
+		}
+		t, err := g.expr(k.pkg, decl, f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", k.pkg, k.name, err)
+		}
+		writeComment(&out, "\t", commentText(f.Doc), "", "")
+		line := ""
+		if c := strings.TrimSpace(commentText(f.Comment)); c != "" && !strings.Contains(c, "\n") {
+			line = " // " + c
+		}
+		fmt.Fprintf(&out, "\t%s %s%s%s\n", strings.Join(names, ", "), t, tag, line)
+	}
+	out.WriteString("}\n\n")
+	return out.Bytes(), nil
+}
+
+// expr renders a type expression of a declaration in pkg, replacing
+// references to types of the listed packages with their generated names.
+func (g *generator) expr(pkg string, decl *typeDecl, e ast.Expr) (string, error) {
+	switch e := e.(type) {
+	case *ast.Ident:
+		if g.types[pkg][e.Name] != nil {
+			return g.want(pkg, e.Name)
+		}
+		if predeclared[e.Name] {
+			return e.Name, nil
+		}
+		return "", fmt.Errorf("unknown type %s", e.Name)
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		if !ok {
+			return "", fmt.Errorf("unsupported type %s", render(e))
+		}
+		path, ok := decl.imports[x.Name]
+		if !ok {
+			return "", fmt.Errorf("unknown package %s", x.Name)
+		}
+		if other := filepath.Base(path); g.types[other] != nil {
+			return g.want(other, e.Sel.Name)
+		}
+		if filepath.Base(path) != x.Name {
+			return "", fmt.Errorf("renamed import %s of %s", x.Name, path)
+		}
+		g.imports[path] = true
+		return x.Name + "." + e.Sel.Name, nil
+	case *ast.StarExpr:
+		t, err := g.expr(pkg, decl, e.X)
+		return "*" + t, err
+	case *ast.ArrayType:
+		t, err := g.expr(pkg, decl, e.Elt)
+		if e.Len != nil {
+			return "[" + render(e.Len) + "]" + t, err
+		}
+		return "[]" + t, err
+	case *ast.MapType:
+		key, err := g.expr(pkg, decl, e.Key)
+		if err != nil {
+			return "", err
+		}
+		val, err := g.expr(pkg, decl, e.Value)
+		return "map[" + key + "]" + val, err
+	case *ast.InterfaceType:
+		if len(e.Methods.List) == 0 {
+			return "any", nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type %s", render(e))
+}
+
+// renderOperation renders the client method of o and its query
+// parameters type.
+func (g *generator) renderOperation(out *bytes.Buffer, o op) error {
+	args := []string{"ctx context.Context"}
+	for _, p := range o.pathParams {
+		args = append(args, goIdent(p.Name)+" "+paramType(p.Type))
+	}
+	if o.body != "" {
+		args = append(args, "body "+o.body)
+	}
+	if o.rawBody != "" {
+		g.imports["io"] = true
+		args = append(args, "body io.Reader")
+	}
+	params := ""
+	if len(o.query) > 0 {
+		params = o.name + "Params"
+		args = append(args, "params "+params)
+		fmt.Fprintf(out, "// %s holds the query parameters of %s; zero values are left out.\ntype %s struct {\n", params, o.name, params)
+		for _, q := range o.query {
+			writeComment(out, "\t", q.Description, "", "")
+			fmt.Fprintf(out, "\t%s %s\n", queryField(q), queryType(q))
+		}
+		fmt.Fprintf(out, "}\n\nfunc (p %s) values() url.Values {\n\tq := url.Values{}\n", params)
+		for _, q := range o.query {
+			field := "p." + queryField(q)
+			if prefix, ok := queryPrefix(q); ok {
+				// the whole expression goes into the name, e.g. meta.temp_c>1000
+				fmt.Fprintf(out, "\tfor _, v := range %s {\n\t\tq.Add(%q+v, \"\")\n\t}\n", field, prefix)
+				continue
+			}
+			switch paramType(q.Type) {
+			case "string":
+				fmt.Fprintf(out, "\tif %s != \"\" {\n\t\tq.Set(%q, %s)\n\t}\n", field, q.Name, field)
+			case "bool":
+				fmt.Fprintf(out, "\tif %s {\n\t\tq.Set(%q, \"true\")\n\t}\n", field, q.Name)
+			case "int":
+				g.imports["strconv"] = true
+				fmt.Fprintf(out, "\tif %s != 0 {\n\t\tq.Set(%q, strconv.Itoa(%s))\n\t}\n", field, q.Name, field)
+			case "float64":
+				g.imports["strconv"] = true
+				fmt.Fprintf(out, "\tif %s != 0 {\n\t\tq.Set(%q, strconv.FormatFloat(%s, 'f', -1, 64))\n\t}\n", field, q.Name, field)
+			case "[]string":
+				g.imports["strings"] = true
+				fmt.Fprintf(out, "\tif len(%s) > 0 {\n\t\tq.Set(%q, strings.Join(%s, \",\"))\n\t}\n", field, q.Name, field)
+			}
+		}
+		out.WriteString("\treturn q\n}\n\n")
+	}
+
+	results := "error"
+	if o.result != "" {
+		results = "(" + o.result + ", error)"
+	}
+	summary := strings.TrimSuffix(strings.TrimSpace(o.summary), ".")
+	if summary != "" {
+		summary = ": " + summary
+	}
+	fmt.Fprintf(out, "// %s calls %s %s%s.\nfunc (c *Client) %s(%s) %s {\n", o.name, o.method, o.path, summary, o.name, strings.Join(args, ", "), results)
+
+	path, err := g.pathExpr(o)
+	if err != nil {
+		return err
+	}
+	query := "nil"
+	if params != "" {
+		query = "params.values()"
+	}
+	body := "nil"
+	switch {
+	case o.body != "":
+		body = "body"
+	case o.rawBody != "":
+		body = fmt.Sprintf("rawBody{%q, body}", o.rawBody)
+	}
+	if o.result == "" {
+		fmt.Fprintf(out, "\treturn c.do(ctx, %q, %s, %s, %s, nil)\n}\n\n", o.method, path, query, body)
+		return nil
+	}
+	fmt.Fprintf(out, "\tvar out %s\n\terr := c.do(ctx, %q, %s, %s, %s, &out)\n\treturn out, err\n}\n\n", o.result, o.method, path, query, body)
+	return nil
+}
+
+// pathExpr returns the Go expression building o's path from its path
+// parameters.
+func (g *generator) pathExpr(o op) (string, error) {
+	rest := o.path
+	var parts []string
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			parts = append(parts, strconv.Quote(rest))
+			break
+		}
+		end := strings.IndexByte(rest, '}')
+		if end < open {
+			return "", fmt.Errorf("malformed path %s", o.path)
+		}
+		if open > 0 {
+			parts = append(parts, strconv.Quote(rest[:open]))
+		}
+		name := rest[open+1 : end]
+		var p *parameter
+		for i := range o.pathParams {
+			if o.pathParams[i].Name == name {
+				p = &o.pathParams[i]
+			}
+		}
+		if p == nil {
+			return "", fmt.Errorf("%s %s: no parameter for {%s}", o.method, o.path, name)
+		}
+		arg := goIdent(name)
+		if paramType(p.Type) != "string" {
+			g.imports["fmt"] = true
+			arg = "fmt.Sprint(" + arg + ")"
+		}
+		parts = append(parts, "url.PathEscape("+arg+")")
+		rest = rest[end+1:]
+	}
+	return strings.Join(parts, " + "), nil
+}
+
+// parsePackage returns the type declarations of the non-test files in dir.
+func parsePackage(dir string) (map[string]*typeDecl, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	decls := make(map[string]*typeDecl)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") || strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, e.Name()), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		imports := make(map[string]string)
+		for _, imp := range f.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			name := filepath.Base(path)
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			imports[name] = path
+		}
+		for _, d := range f.Decls {
+			gd, ok := d.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range gd.Specs {
+				ts := s.(*ast.TypeSpec)
+				doc := commentText(ts.Doc)
+				if doc == "" && len(gd.Specs) == 1 {
+					doc = commentText(gd.Doc)
+				}
+				decls[ts.Name.Name] = &typeDecl{spec: ts, doc: doc, imports: imports}
+			}
+		}
+	}
+	return decls, nil
+}
+
+// methodName returns the client method of an operation: its operationId
+// if it has one, else the HTTP method followed by the path's segments
+// after /api/{version}, with "By" before each parameter, e.g.
+// PostIncidentsByIDAck.
+func methodName(operationID, method, path string) string {
+	if operationID != "" {
+		return exported(operationID)
+	}
+	name := exported(strings.ToLower(method))
+	if rest, ok := strings.CutPrefix(path, "/api/"); ok {
+		_, path, _ = strings.Cut(rest, "/")
+	}
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, "{") {
+			name += "By" + exported(strings.Trim(seg, "{}"))
+			continue
+		}
+		name += exported(seg)
+	}
+	return name
+}
+
+// initialisms are written in capitals in Go names.
+var initialisms = map[string]string{"id": "ID", "url": "URL", "ts": "TS", "csv": "CSV", "json": "JSON", "svg": "SVG", "o2": "O2", "ms": "Ms"}
+
+// exported turns a name such as run_id, sign-up or authSignIn into an
+// exported Go identifier.
+func exported(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if up, ok := initialisms[strings.ToLower(word)]; ok && up != "Ms" {
+			b.WriteString(up)
+			continue
+		}
+		r := []rune(word)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
+
+// goIdent turns a parameter name into an unexported Go identifier.
+func goIdent(s string) string {
+	e := exported(s)
+	for up, in := range map[string]string{"ID": "id", "URL": "url"} {
+		if strings.HasPrefix(e, up) {
+			return in + e[len(up):]
+		}
+	}
+	r := []rune(e)
+	r[0] = unicode.ToLower(r[0])
+	if name := string(r); name != "type" {
+		return name
+	}
+	return "typ"
+}
+
+// queryPrefix returns the fixed part of a parameter family such as
+// meta.{key}.
+func queryPrefix(q parameter) (string, bool) {
+	prefix, _, ok := strings.Cut(q.Name, "{")
+	return prefix, ok
+}
+
+// queryField returns the field of a query parameter in its params struct.
+func queryField(q parameter) string {
+	if prefix, ok := queryPrefix(q); ok {
+		return exported(prefix)
+	}
+	return exported(q.Name)
+}
+
+// queryType returns the Go type of a query parameter; a parameter family
+// takes one expression per filter.
+func queryType(q parameter) string {
+	if _, ok := queryPrefix(q); ok {
+		return "[]string"
+	}
+	return paramType(q.Type)
+}
+
+// paramType returns the Go type of a path or query parameter.
+func paramType(t string) string {
+	switch t {
+	case "integer":
+		return "int"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]string"
+	}
+	return "string"
+}
+
+var predeclared = map[string]bool{
+	"any": true, "bool": true, "byte": true, "error": true, "rune": true, "string": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true,
+}
+
+// writeComment writes text as a line comment block with the given indent,
+// renaming a leading declared name to its generated one.
+func writeComment(out *bytes.Buffer, indent, text, from, to string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if from != "" && from != to && strings.HasPrefix(text, from+" ") {
+		text = to + text[len(from):]
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(out, "%s// %s\n", indent, strings.TrimRight(line, " "))
+	}
+}
+
+func commentText(c *ast.CommentGroup) string {
+	if c == nil {
+		return ""
+	}
+	return c.Text()
+}
+
+func render(e ast.Node) string {
+	var b bytes.Buffer
+	_ = printer.Fprint(&b, token.NewFileSet(), e)
+	return b.String()
+}
+
+// Packages returns the packages declaring the API's wire types, relative
+// to the repository root.
+func Packages(root string) map[string]string {
+	return map[string]string{
+		"handlers":   filepath.Join(root, "internal", "handlers"),
+		"models":     filepath.Join(root, "internal", "models"),
+		"service":    filepath.Join(root, "internal", "service"),
+		"repository": filepath.Join(root, "internal", "repository"),
+	}
+}
@@ -0,0 +1,78 @@
+package apigen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The committed client must match the spec and DTOs it was generated from;
+// run `make gen` when this fails.
+func TestGenerate_ClientIsCurrent(t *testing.T) {
+	root := filepath.Join("..", "..")
+	spec, err := os.ReadFile(filepath.Join(root, "docs", "swagger.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Generate(Config{Spec: spec, Packages: Packages(root), Package: "client"})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(root, "pkg", "client", "client_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("pkg/client/client_gen.go is stale; run `make gen`")
+	}
+}
+
+func TestGenerate_CopiesGoTypes(t *testing.T) {
+	dir := t.TempDir()
+	src := `package models
+
+import "time"
+
+// Run is one heat cycle.
+type Run struct {
+	ID      string     ` + "`json:\"run_id\" example:\"r1\"`" + `
+	Ended   *time.Time ` + "`json:\"ended_at,omitempty\"`" + `
+	Modes   []Mode     ` + "`json:\"modes\"`" + `
+	Secret  string     ` + "`json:\"-\"`" + `
+	private int
+}
+
+type Mode string
+`
+	if err := os.WriteFile(filepath.Join(dir, "run.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	spec := `{"paths": {
+		"/api/v1/runs/{run_id}": {"get": {"summary": "Get a run", "parameters": [{"name": "run_id", "in": "path", "type": "string"}],
+			"responses": {"200": {"schema": {"$ref": "#/definitions/models.Run"}}}}},
+		"/ws": {"get": {"responses": {"101": {}}}}
+	}}`
+	out, err := Generate(Config{Spec: []byte(spec), Packages: map[string]string{"models": dir}, Package: "client"})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	for _, want := range []string{
+		"// Run is one heat cycle.",
+		"ID    string     `json:\"run_id\"`",
+		"Ended *time.Time `json:\"ended_at,omitempty\"`",
+		"type Mode string",
+		"func (c *Client) GetRunsByRunID(ctx context.Context, runID string) (Run, error)",
+		`"/api/v1/runs/"+url.PathEscape(runID)`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("missing %q in\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"Secret", "private", "/ws"} {
+		if strings.Contains(string(out), unwanted) {
+			t.Errorf("unexpected %q in\n%s", unwanted, out)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/handlers"
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+type stateEnvelope struct {
+	Type string              `json:"type"`
+	Data models.FurnaceState `json:"data"`
+}
+
+func TestAuthAndControlFlow(t *testing.T) {
+	t.Parallel()
+	for name, newStack := range stackBackends {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s := newStack(t)
+
+			s.mustCall(http.StatusUnauthorized, http.MethodGet, "/api/v1/furnace/state", "", nil, nil)
+			admin := s.signUp("admin", "s3cret-pass")
+			operator := s.signUp("op", "s3cret-pass")
+
+			s.mustCall(http.StatusOK, http.MethodPost, "/api/v1/furnace/start", operator, nil, nil)
+			s.mustCall(http.StatusOK, http.MethodPost, "/api/v1/furnace/mode", operator,
+				handlers.SetModeRequest{Mode: "HEAT", TargetTempC: 850, DurationSec: 600}, nil)
+
+			var st models.FurnaceState
+			s.mustCall(http.StatusOK, http.MethodGet, "/api/v1/furnace/state", operator, nil, &st)
+			if !st.IsRunning || st.Mode != "HEAT" || st.TargetTempC != 850 || st.RunID == "" {
+				t.Fatalf("unexpected state: %+v", st)
+			}
+
+			var logs struct {
+				Events []models.FurnaceEvent `json:"events"`
+			}
+			s.mustCall(http.StatusOK, http.MethodGet, "/api/v1/logs/?run_id="+st.RunID, operator, nil, &logs)
+			if len(logs.Events) == 0 {
+				t.Fatalf("expected events tagged with run %s", st.RunID)
+			}
+
+			// Admin-only routes reject operators.
+			s.mustCall(http.StatusForbidden, http.MethodGet, "/api/v1/admin/chaos", operator, nil, nil)
+			s.mustCall(http.StatusNotFound, http.MethodGet, "/api/v1/admin/chaos", admin, nil, nil)
+			s.mustCall(http.StatusForbidden, http.MethodPut, "/api/v1/admin/users/1/role", operator,
+				handlers.UserRoleRequest{Role: models.RoleAdmin}, nil)
+
+			// Anyone can sign up, but only to read until an admin says otherwise.
+			creds := handlers.AuthCredentials{Username: "walk-in", Password: "s3cret-pass"}
+			s.mustCall(http.StatusOK, http.MethodPost, "/auth/sign-up", "", creds, nil)
+			var viewer handlers.TokenResponse
+			s.mustCall(http.StatusOK, http.MethodPost, "/auth/sign-in", "", creds, &viewer)
+			s.mustCall(http.StatusOK, http.MethodGet, "/api/v1/furnace/state", viewer.Token, nil, nil)
+			s.mustCall(http.StatusForbidden, http.MethodPost, "/api/v1/furnace/stop", viewer.Token, nil, nil)
+		})
+	}
+}
+
+func TestSetupBootstrapsFirstAdmin(t *testing.T) {
+	t.Parallel()
+	s := newStack(t)
+
+	creds := handlers.AuthCredentials{Username: "mallory", Password: "s3cret-pass"}
+	s.mustCall(http.StatusConflict, http.MethodPost, "/auth/sign-up", "", creds, nil)
+	var st service.SetupStatus
+	s.mustCall(http.StatusOK, http.MethodGet, "/api/v1/setup", "", nil, &st)
+	if !st.Required {
+		t.Fatal("empty installation does not report setup as required")
+	}
+
+	var tok handlers.TokenResponse
+	s.mustCall(http.StatusCreated, http.MethodPost, "/api/v1/setup", "",
+		handlers.SetupRequest{Username: "admin", Password: "s3cret-pass", Units: "F", MaxSafeC: 950}, &tok)
+	s.mustCall(http.StatusNotFound, http.MethodGet, "/api/v1/admin/chaos", tok.Token, nil, nil)
+	s.mustCall(http.StatusConflict, http.MethodPost, "/api/v1/setup", "",
+		handlers.SetupRequest{Username: "mallory", Password: "s3cret-pass"}, nil)
+
+	var sim struct {
+		MaxSafeC float64 `json:"max_safe_c"`
+	}
+	s.mustCall(http.StatusOK, http.MethodGet, "/api/v1/sim/config", tok.Token, nil, &sim)
+	if sim.MaxSafeC != 950 {
+		t.Fatalf("max_safe_c = %v, want 950 from setup", sim.MaxSafeC)
+	}
+	s.mustCall(http.StatusOK, http.MethodPost, "/auth/sign-up", "", creds, nil)
+}
+
+func TestStateStreamFollowsSimulator(t *testing.T) {
+	t.Parallel()
+	s := newStack(t, withTimeScale(600))
+	token := s.signUp("admin", "s3cret-pass")
+
+	s.mustCall(http.StatusOK, http.MethodPost, "/api/v1/furnace/start", token, nil, nil)
+	s.mustCall(http.StatusOK, http.MethodPost, "/api/v1/furnace/mode", token,
+		handlers.SetModeRequest{Mode: "HEAT", TargetTempC: 400, DurationSec: 60}, nil)
+	s.runSimulator()
+
+	ws := s.dialWS("?interval_ms=20&token=" + token)
+	s.eventually(5*time.Second, "streamed temperature to rise", func() bool {
+		var msg stateEnvelope
+		_ = ws.SetReadDeadline(time.Now().Add(time.Second))
+		if err := ws.ReadJSON(&msg); err != nil {
+			t.Fatalf("read ws: %v", err)
+		}
+		return msg.Type == "state" && msg.Data.CurrentTempC > 100
+	})
+}
+
+func TestReadinessFollowsSimulator(t *testing.T) {
+	t.Parallel()
+	s := newStack(t, withTimeScale(100))
+
+	s.mustCall(http.StatusOK, http.MethodGet, "/healthz", "", nil, nil)
+	var rep service.ReadinessReport
+	s.mustCall(http.StatusServiceUnavailable, http.MethodGet, "/readyz", "", nil, &rep)
+	if !rep.Components[service.ComponentDatabase].OK || !rep.Components[service.ComponentSchema].OK ||
+		rep.Components[service.ComponentSimulator].OK {
+		t.Fatalf("expected only the simulator to fail before it runs: %+v", rep)
+	}
+
+	s.runSimulator()
+	s.eventually(5*time.Second, "instance to become ready", func() bool {
+		return s.call(http.MethodGet, "/readyz", "", nil, nil) == http.StatusOK
+	})
+}
+
+func TestAlertRuleFiresOnSimulatedState(t *testing.T) {
+	t.Parallel()
+	s := newStack(t, withTimeScale(600))
+	token := s.signUp("admin", "s3cret-pass")
+
+	var rule models.AlertRule
+	s.mustCall(http.StatusCreated, http.MethodPost, "/api/v1/alerts/rules", token,
+		handlers.AlertRuleRequest{Name: "Warm", Kind: models.AlertTempAbove, Threshold: 100}, &rule)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.services.Alerts.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	s.mustCall(http.StatusOK, http.MethodPost, "/api/v1/furnace/start", token, nil, nil)
+	s.mustCall(http.StatusOK, http.MethodPost, "/api/v1/furnace/mode", token,
+		handlers.SetModeRequest{Mode: "HEAT", TargetTempC: 400, DurationSec: 60}, nil)
+	s.runSimulator()
+
+	var hist struct {
+		Alerts []models.Alert `json:"alerts"`
+	}
+	s.eventually(5*time.Second, "the rule to fire", func() bool {
+		s.mustCall(http.StatusOK, http.MethodGet, "/api/v1/alerts", token, nil, &hist)
+		return len(hist.Alerts) > 0
+	})
+	if a := hist.Alerts[0]; a.RuleID != rule.ID || a.Value <= 100 || a.RunID == "" {
+		t.Fatalf("unexpected alert: %+v", a)
+	}
+	var logs struct {
+		Events []models.FurnaceEvent `json:"events"`
+	}
+	s.mustCall(http.StatusOK, http.MethodGet, "/api/v1/logs/?type=ALERT", token, nil, &logs)
+	if len(logs.Events) == 0 {
+		t.Fatal("expected an ALERT event in the log")
+	}
+}
+
+func TestIncidentCompiledWhenAlarmClears(t *testing.T) {
+	t.Parallel()
+	s := newStack(t, withTimeScale(600))
+	token := s.signUp("admin", "s3cret-pass")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.services.Incidents.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	s.mustCall(http.StatusOK, http.MethodPost, "/api/v1/furnace/start", token, nil, nil)
+	s.mustCall(http.StatusOK, http.MethodPost, "/api/v1/furnace/mode", token,
+		handlers.SetModeRequest{Mode: "HEAT", TargetTempC: 400, DurationSec: 600}, nil)
+	s.runSimulator()
+	s.mustCall(http.StatusAccepted, http.MethodPost, "/api/v1/sim/faults", token,
+		handlers.InjectFaultRequest{Type: service.FaultHeaterFailure}, nil)
+
+	var list struct {
+		Incidents []models.Incident `json:"incidents"`
+	}
+	s.eventually(5*time.Second, "an incident to open", func() bool {
+		s.mustCall(http.StatusOK, http.MethodGet, "/api/v1/incidents", token, nil, &list)
+		return len(list.Incidents) == 1
+	})
+	id := strconv.FormatInt(list.Incidents[0].ID, 10)
+	s.mustCall(http.StatusOK, http.MethodPost, "/api/v1/incidents/"+id+"/ack", token, nil, nil)
+	s.mustCall(http.StatusConflict, http.MethodPost, "/api/v1/incidents/"+id+"/ack", token, nil, nil)
+	s.mustCall(http.StatusOK, http.MethodDelete, "/api/v1/sim/faults", token, nil, nil)
+
+	var inc models.Incident
+	s.eventually(5*time.Second, "the incident to close", func() bool {
+		s.mustCall(http.StatusOK, http.MethodGet, "/api/v1/incidents/"+id, token, nil, &inc)
+		return !inc.Open()
+	})
+	if inc.AckedByName != "admin" || inc.AlarmCodes[0] != service.FaultHeaterFailure || inc.DurationS <= 0 {
+		t.Fatalf("unexpected incident: %+v", inc)
+	}
+	if len(inc.Events) == 0 || len(inc.Telemetry) == 0 {
+		t.Fatalf("expected events and telemetry in the report, got %d/%d", len(inc.Events), len(inc.Telemetry))
+	}
+}
+
+func TestLogPurgeKeepsNewestEvents(t *testing.T) {
+	t.Parallel()
+	for name, newStack := range stackBackends {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s := newStack(t)
+			admin := s.signUp("admin", "s3cret-pass")
+
+			for i := 0; i < 3; i++ {
+				s.mustCall(http.StatusOK, http.MethodPost, "/api/v1/furnace/start", admin, nil, nil)
+				s.mustCall(http.StatusOK, http.MethodPost, "/api/v1/furnace/stop", admin, nil, nil)
+			}
+			var logs struct {
+				Count int `json:"count"`
+			}
+			s.mustCall(http.StatusOK, http.MethodGet, "/api/v1/logs/", admin, nil, &logs)
+			total := logs.Count
+
+			var rep models.PurgeReport
+			s.mustCall(http.StatusOK, http.MethodPost, "/api/v1/logs/purge", admin, handlers.PurgeLogsRequest{MaxAge: "1h"}, &rep)
+			if rep.Deleted != 0 {
+				t.Fatalf("max_age 1h purged %d fresh events", rep.Deleted)
+			}
+			s.mustCall(http.StatusOK, http.MethodPost, "/api/v1/logs/purge", admin, handlers.PurgeLogsRequest{MaxRows: 2, DryRun: true}, &rep)
+			if !rep.DryRun || rep.Deleted != int64(total-2) || rep.Oldest == nil {
+				t.Fatalf("dry run report %+v for %d events", rep, total)
+			}
+			s.mustCall(http.StatusOK, http.MethodPost, "/api/v1/logs/purge", admin, handlers.PurgeLogsRequest{MaxRows: 2}, &rep)
+			s.mustCall(http.StatusOK, http.MethodGet, "/api/v1/logs/", admin, nil, &logs)
+			if rep.Deleted != int64(total-2) || logs.Count != 3 { // the two kept and LOG_PURGED
+				t.Fatalf("deleted %d of %d, %d left", rep.Deleted, total, logs.Count)
+			}
+
+			var chain models.ChainReport
+			s.mustCall(http.StatusOK, http.MethodGet, "/api/v1/logs/verify", admin, nil, &chain)
+			if !chain.Valid {
+				t.Fatalf("chain invalid after purge: %+v", chain)
+			}
+		})
+	}
+}
@@ -0,0 +1,5 @@
+// Package e2e holds black-box API tests. Each test boots the full stack —
+// real services, an in-memory SQLite database, the real router and the
+// WebSocket endpoint — on its own httptest server, so tests may run in
+// parallel. See newStack in stack_test.go.
+package e2e
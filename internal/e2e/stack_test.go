@@ -0,0 +1,194 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/handlers"
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+	"controlling_furnace/internal/repository/db"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+func TestMain(m *testing.M) {
+	// gin's mode is global; set it once before tests run in parallel.
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// stack is one running instance of the API with its own database.
+type stack struct {
+	t        *testing.T
+	srv      *httptest.Server
+	services *service.Service
+	cfg      service.Config
+	setUp    bool   // setup created the first admin
+	admin    string // token of that admin
+}
+
+// newStack boots the API on a fresh in-memory database. The simulator is
+// not started; call runSimulator when a test needs temperatures to move.
+func newStack(t *testing.T, opts ...func(*service.Config)) *stack {
+	t.Helper()
+	conn, err := db.InitDB(":memory:")
+	if err != nil {
+		t.Fatalf("init db: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return newStackOn(t, repository.NewRepository(conn), opts...)
+}
+
+// newMemoryStack boots the API on the in-memory repositories, as with
+// db.driver=memory.
+func newMemoryStack(t *testing.T, opts ...func(*service.Config)) *stack {
+	t.Helper()
+	return newStackOn(t, repository.NewInMemory(), opts...)
+}
+
+// stackBackends boots the API on each storage backend.
+var stackBackends = map[string]func(*testing.T, ...func(*service.Config)) *stack{
+	"sqlite": newStack,
+	"memory": newMemoryStack,
+}
+
+func newStackOn(t *testing.T, repos *repository.Repository, opts ...func(*service.Config)) *stack {
+	t.Helper()
+	cfg := service.DefaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	services := service.NewServiceWithConfig(repos, cfg)
+	srv := httptest.NewServer(handlers.NewHandler(services, nil).InitRoutes())
+	t.Cleanup(srv.Close)
+
+	return &stack{t: t, srv: srv, services: services, cfg: cfg}
+}
+
+// withTimeScale speeds the simulator up; with a 10ms tick, a scale of at
+// least 100 is needed for each tick to advance simulated time.
+func withTimeScale(scale float64) func(*service.Config) {
+	return func(cfg *service.Config) {
+		cfg.Sim.Tick = 10 * time.Millisecond
+		cfg.Sim.TimeScale = scale
+	}
+}
+
+// runSimulator starts the simulator loop until the test ends.
+func (s *stack) runSimulator() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.services.Simulator.Run(ctx, 0)
+	}()
+	// Registered after the server's cleanup, so it runs first: the loop
+	// flushes before the database is closed.
+	s.t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// call sends a JSON request and decodes a JSON response into out (if not
+// nil). It returns the status code.
+func (s *stack) call(method, path, token string, body, out any) int {
+	s.t.Helper()
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatalf("marshal body: %v", err)
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, s.srv.URL+path, r)
+	if err != nil {
+		s.t.Fatalf("new request: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := s.srv.Client().Do(req)
+	if err != nil {
+		s.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			s.t.Fatalf("%s %s: decode response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// mustCall is call that fails the test unless the status is want.
+func (s *stack) mustCall(want int, method, path, token string, body, out any) {
+	s.t.Helper()
+	if got := s.call(method, path, token, body, out); got != want {
+		s.t.Fatalf("%s %s: status %d, want %d", method, path, got, want)
+	}
+}
+
+// signUp registers a user and returns a token for it. The first user of a
+// stack becomes admin, later ones sign up as viewers and are made
+// operators by that admin.
+func (s *stack) signUp(username, password string) string {
+	s.t.Helper()
+	creds := handlers.AuthCredentials{Username: username, Password: password}
+	if !s.setUp {
+		// the first account is the admin created by setup
+		s.setUp = true
+		var tok handlers.TokenResponse
+		s.mustCall(http.StatusCreated, http.MethodPost, "/api/v1/setup", "",
+			handlers.SetupRequest{Username: username, Password: password}, &tok)
+		s.admin = tok.Token
+		return tok.Token
+	}
+	var user handlers.SignUpResponse
+	s.mustCall(http.StatusOK, http.MethodPost, "/auth/sign-up", "", creds, &user)
+	s.mustCall(http.StatusOK, http.MethodPut, fmt.Sprintf("/api/v1/admin/users/%d/role", user.ID), s.admin,
+		handlers.UserRoleRequest{Role: models.RoleOperator}, nil)
+	var tok handlers.TokenResponse
+	s.mustCall(http.StatusOK, http.MethodPost, "/auth/sign-in", "", creds, &tok)
+	return tok.Token
+}
+
+// dialWS opens the state stream; query is appended to /ws as-is.
+func (s *stack) dialWS(query string) *websocket.Conn {
+	s.t.Helper()
+	url := "ws" + strings.TrimPrefix(s.srv.URL, "http") + "/ws" + query
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		s.t.Fatalf("dial %s: %v", url, err)
+	}
+	s.t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// eventually polls cond until it holds or timeout expires.
+func (s *stack) eventually(timeout time.Duration, what string, cond func() bool) {
+	s.t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			s.t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLogConfig controls the one log line written per request.
+type AccessLogConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Exclude lists paths never logged; an entry ending in "/" excludes
+	// everything below it. Nil means DefaultAccessLogExclude.
+	Exclude []string `mapstructure:"exclude"`
+	// SampleEvery logs one in so many requests answered below 400; 0 or 1
+	// logs them all. Client and server errors are always logged.
+	SampleEvery int `mapstructure:"sample_every"`
+}
+
+// DefaultAccessLogExclude are the probe and scrape paths, which would
+// otherwise drown out the API calls.
+var DefaultAccessLogExclude = []string{"/health", "/healthz", "/readyz", "/metrics"}
+
+// excluded reports whether requests for path go unlogged.
+func (c AccessLogConfig) excluded(path string) bool {
+	exclude := c.Exclude
+	if exclude == nil {
+		exclude = DefaultAccessLogExclude
+	}
+	for _, e := range exclude {
+		if path == e || (strings.HasSuffix(e, "/") && strings.HasPrefix(path, e)) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessLog returns the middleware logging each request once it has been
+// answered. It must run after requestIDMiddleware; h.log must not be nil.
+func (h *Handler) accessLog(cfg AccessLogConfig) gin.HandlerFunc {
+	var n atomic.Uint64
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if cfg.excluded(path) {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < http.StatusBadRequest && cfg.SampleEvery > 1 && n.Add(1)%uint64(cfg.SampleEvery) != 1 {
+			return
+		}
+		fields := []interface{}{
+			"method", c.Request.Method,
+			"path", path,
+			"route", c.FullPath(),
+			"status", status,
+			"latencyMs", float64(time.Since(start).Microseconds()) / 1000,
+			"bytes", max(c.Writer.Size(), 0),
+			"clientIp", c.ClientIP(),
+		}
+		if id, ok := c.Get(ctxKeyUserID); ok {
+			fields = append(fields, "userId", id)
+		}
+		log := h.requestLog(c)
+		switch {
+		case status >= http.StatusInternalServerError:
+			log.Errorw("http_request", fields...)
+		case status >= http.StatusBadRequest:
+			log.Warnw("http_request", fields...)
+		default:
+			log.Infow("http_request", fields...)
+		}
+	}
+}
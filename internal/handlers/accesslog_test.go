@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"controlling_furnace/internal/logger"
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAccessLog_LogsAnsweredRequests(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := &logger.Logger{SugaredLogger: zap.New(core).Sugar()}
+	s := &service.Service{Authorization: &mockAuth{parseID: 7, parseRole: models.RoleViewer}, Monitoring: &mockMonitoring{}}
+	r := NewHandlerWithConfig(s, log, Config{AccessLog: AccessLogConfig{Enabled: true, SampleEvery: 2}}).InitRoutes()
+	get := func(path, token string) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(requestIDHeader, "req-1")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		r.ServeHTTP(w, req)
+	}
+
+	get("/healthz", "")
+	get("/api/v1/furnace/state", "valid")
+	get("/api/v1/furnace/state", "valid") // sampled out
+	get("/api/v1/furnace/state", "")      // 401, always logged
+
+	entries := logs.FilterMessage("http_request").All()
+	if len(entries) != 2 {
+		t.Fatalf("%d access log lines, want 2: %+v", len(entries), entries)
+	}
+	ok, denied := entries[0].ContextMap(), entries[1].ContextMap()
+	if ok["method"] != http.MethodGet || ok["path"] != "/api/v1/furnace/state" || ok["route"] != "/api/v1/furnace/state" ||
+		ok["status"] != int64(http.StatusOK) || ok["userId"] != int64(7) || ok["requestId"] != "req-1" || ok["bytes"].(int64) <= 0 {
+		t.Fatalf("logged %v", ok)
+	}
+	if _, timed := ok["latencyMs"]; !timed || entries[0].Level != zapcore.InfoLevel {
+		t.Fatalf("logged %v at %v", ok, entries[0].Level)
+	}
+	if denied["status"] != int64(http.StatusUnauthorized) || entries[1].Level != zapcore.WarnLevel {
+		t.Fatalf("logged %v at %v", denied, entries[1].Level)
+	}
+	if _, ok := denied["userId"]; ok {
+		t.Fatalf("user logged for a request without a token: %v", denied)
+	}
+}
+
+func TestAccessLogConfig_Excluded(t *testing.T) {
+	def := AccessLogConfig{}
+	if !def.excluded("/metrics") || def.excluded("/api/v1/logs/") {
+		t.Fatal("default exclusions")
+	}
+	cfg := AccessLogConfig{Exclude: []string{"/swagger/"}}
+	if !cfg.excluded("/swagger/index.html") || cfg.excluded("/healthz") {
+		t.Fatal("configured exclusions")
+	}
+}
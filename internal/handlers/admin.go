@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InitAdminRoutes builds the router of the admin listener: the probes,
+// /metrics and, when enabled, /debug/pprof. It is unauthenticated, for
+// scrapers and orchestrators, so its port is meant to be reachable from
+// the plant network only. Set Config.AdminListener so that InitRoutes
+// leaves these routes off the API port.
+func (h *Handler) InitAdminRoutes() *gin.Engine {
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.NoMethod(methodNotAllowed)
+	router.Use(requestIDMiddleware, h.recovery)
+
+	h.registerProbeRoutes(router)
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		router.Handle(method, "/metrics", h.getMetrics)
+	}
+	h.registerDebugRoutes(router)
+	return router
+}
+
+// registerProbeRoutes mounts the liveness and readiness probes.
+func (h *Handler) registerProbeRoutes(r *gin.Engine) {
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		r.Handle(method, "/healthz", h.health)
+		r.Handle(method, "/health", h.health)
+		r.Handle(method, "/readyz", h.ready)
+	}
+}
+
+// @Summary      Metrics
+// @Description  The furnace state gauges of /api/v1/furnace/state.prom plus build and runtime gauges and the panic count of the process, as OpenMetrics. Served on the admin listener only, without a token.
+// @Tags         system
+// @Produce      plain
+// @Success      200  {string}  string  "OpenMetrics text exposition"
+// @Failure      500  {object}  Problem
+// @Router       /metrics [get]
+func (h *Handler) getMetrics(c *gin.Context) {
+	st, err := h.services.Monitoring.GetState(c.Request.Context())
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, errGetState, "furnace_get_state_failed", err)
+		return
+	}
+	var w openMetricsWriter
+	w.state(st)
+	w.process(h.services.System.Info(), h.services.System.Build())
+	w.sb.WriteString("# EOF\n")
+	c.Data(http.StatusOK, openMetricsContentType, []byte(w.sb.String()))
+}
+
+// process writes the gauges of the running instance.
+func (w *openMetricsWriter) process(info service.SystemInfo, build service.BuildInfo) {
+	w.family("furnace_build_info", "", "1, labelled with the build version and commit.")
+	w.sample("furnace_build_info", 1, "version", build.Version, "commit", build.Commit, "go_version", build.GoVersion)
+	w.gauge("furnace_uptime_seconds", "seconds", "Time since the process started.", info.UptimeS)
+	w.gauge("furnace_goroutines", "", "Goroutines running.", float64(info.Goroutines))
+	w.gauge("furnace_heap_alloc_bytes", "bytes", "Bytes of allocated heap objects.", float64(info.HeapAllocBytes))
+	w.gauge("furnace_state_subscribers", "", "Live state consumers: WebSocket clients and evaluators.", float64(info.StateSubscribers))
+	w.counter("furnace_http_panics", "Panics recovered while serving requests.", float64(info.Panics))
+	if info.DB != nil {
+		w.gauge("furnace_db_open_connections", "", "Open database connections.", float64(info.DB.OpenConnections))
+		w.gauge("furnace_db_in_use_connections", "", "Database connections in use.", float64(info.DB.InUse))
+	}
+}
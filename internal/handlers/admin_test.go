@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminRoutes_TakeOperationalRoutesOffTheAPI(t *testing.T) {
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleAdmin},
+		Monitoring:    &mockMonitoring{state: models.FurnaceState{Mode: "HEAT", CurrentTempC: 612.5, IsRunning: true}},
+		Probes:        &mockProbes{report: service.ReadinessReport{Ready: true}},
+		System: &mockSystem{
+			info:  service.SystemInfo{Goroutines: 12, UptimeS: 30, Panics: 3, DB: &service.DBStats{OpenConnections: 2}},
+			build: service.BuildInfo{Version: "v1.4.0", Commit: "3f2c9a1b7e4d"},
+		},
+	}
+	h := NewHandlerWithConfig(s, nil, Config{Debug: DebugConfig{Pprof: true}, AdminListener: true})
+	api, admin := h.InitRoutes(), h.InitAdminRoutes()
+	get := func(r *gin.Engine, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, path := range []string{"/healthz", "/readyz", "/metrics", "/debug/pprof/"} {
+		if w := get(api, path); w.Code != http.StatusNotFound {
+			t.Errorf("API port %s: %d", path, w.Code)
+		}
+	}
+	if w := get(api, "/version"); w.Code != http.StatusOK {
+		t.Errorf("API port /version: %d", w.Code)
+	}
+
+	for _, path := range []string{"/healthz", "/health", "/readyz"} {
+		if w := get(admin, path); w.Code != http.StatusOK {
+			t.Errorf("admin port %s: %d", path, w.Code)
+		}
+	}
+	// no token on the admin port, the profiler included
+	w := httptest.NewRecorder()
+	admin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("admin port pprof: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	admin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != openMetricsContentType {
+		t.Fatalf("metrics: %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		"furnace_temperature_celsius 612.5\n",
+		`furnace_mode{mode="HEAT"} 1`,
+		`furnace_build_info{version="v1.4.0",commit="3f2c9a1b7e4d",go_version=""} 1`,
+		"furnace_goroutines 12\n",
+		"furnace_db_open_connections 2\n",
+		"# TYPE furnace_http_panics counter\n",
+		"furnace_http_panics_total 3\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics lack %q:\n%s", want, body)
+		}
+	}
+	if !strings.HasSuffix(body, "# EOF\n") || strings.Count(body, "# EOF") != 1 {
+		t.Errorf("metrics must end with a single # EOF:\n%s", body)
+	}
+}
+
+func TestAdminRoutes_ProbesStayOnTheAPIWithoutAdminListener(t *testing.T) {
+	r := newTestRouter(&service.Service{Probes: &mockProbes{report: service.ReadinessReport{Ready: true}}})
+	for _, path := range []string{"/healthz", "/readyz"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: %d", path, w.Code)
+		}
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("/metrics on the API port: %d", w.Code)
+	}
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AlertRuleRequest is the payload for creating or replacing an alert rule.
+type AlertRuleRequest struct {
+	Name string `json:"name" binding:"required" example:"Too hot"`
+	// Allowed: temp_above, remaining_below, error_event
+	Kind string `json:"kind" binding:"required" example:"temp_above"`
+	// °C for temp_above, seconds for remaining_below; ignored for error_event
+	Threshold float64 `json:"threshold" example:"950"`
+	// How long the condition must hold before the alert fires
+	ForSeconds int `json:"for_seconds" example:"30"`
+	// Defaults to true
+	Enabled *bool `json:"enabled" example:"true"`
+}
+
+func (r AlertRuleRequest) rule() models.AlertRule {
+	enabled := r.Enabled == nil || *r.Enabled
+	return models.AlertRule{Name: r.Name, Kind: r.Kind, Threshold: r.Threshold, ForSeconds: r.ForSeconds, Enabled: enabled}
+}
+
+// ruleID parses the :id path parameter, answering 400 if it is not a
+// positive integer.
+func ruleID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		problem(c, http.StatusBadRequest, codeInvalidID, "invalid rule id")
+		return 0, false
+	}
+	return id, true
+}
+
+// alertRuleError answers for errors returned by the alert rule service.
+func (h *Handler) alertRuleError(c *gin.Context, err error, msg, logKey string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAlertRule):
+		problemFor(c, http.StatusBadRequest, err)
+	case errors.Is(err, service.ErrAlertRuleNotFound):
+		problemFor(c, http.StatusNotFound, err)
+	default:
+		h.logAndJSONError(c, http.StatusInternalServerError, msg, logKey, err)
+	}
+}
+
+// @Summary      List alert rules
+// @Tags         alerts
+// @Produce      json
+// @Success      200  {array}   models.AlertRule
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/alerts/rules [get]
+// @Security     BearerAuth
+func (h *Handler) listAlertRules(c *gin.Context) {
+	rules, err := h.services.Alerts.ListRules(c.Request.Context())
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to list alert rules", "alert_rules_list_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// @Summary      Get alert rule
+// @Tags         alerts
+// @Produce      json
+// @Param        id   path      int  true  "Rule ID"
+// @Success      200  {object}  models.AlertRule
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/alerts/rules/{id} [get]
+// @Security     BearerAuth
+func (h *Handler) getAlertRule(c *gin.Context) {
+	id, ok := ruleID(c)
+	if !ok {
+		return
+	}
+	rule, err := h.services.Alerts.GetRule(c.Request.Context(), id)
+	if err != nil {
+		h.alertRuleError(c, err, "failed to load alert rule", "alert_rule_get_failed")
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// @Summary      Create alert rule
+// @Description  Kinds: temp_above (measured temperature above threshold °C for for_seconds), remaining_below (running with less than threshold seconds left), error_event (any new error code). A firing logs an ALERT event and is sent to the configured notification URL.
+// @Tags         alerts
+// @Accept       json
+// @Produce      json
+// @Param        body  body      AlertRuleRequest  true  "Rule"
+// @Success      201   {object}  models.AlertRule
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      500   {object}  Problem
+// @Router       /api/v1/alerts/rules [post]
+// @Security     BearerAuth
+func (h *Handler) createAlertRule(c *gin.Context) {
+	var req AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, "invalid request body")
+		return
+	}
+	rule, err := h.services.Alerts.CreateRule(c.Request.Context(), req.rule(), c.GetInt(ctxKeyUserID))
+	if err != nil {
+		h.alertRuleError(c, err, "failed to create alert rule", "alert_rule_create_failed")
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// @Summary      Replace alert rule
+// @Tags         alerts
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int               true  "Rule ID"
+// @Param        body  body      AlertRuleRequest  true  "Rule"
+// @Success      200   {object}  models.AlertRule
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      404   {object}  Problem
+// @Failure      500   {object}  Problem
+// @Router       /api/v1/alerts/rules/{id} [put]
+// @Security     BearerAuth
+func (h *Handler) updateAlertRule(c *gin.Context) {
+	id, ok := ruleID(c)
+	if !ok {
+		return
+	}
+	var req AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, "invalid request body")
+		return
+	}
+	rule := req.rule()
+	rule.ID = id
+	rule, err := h.services.Alerts.UpdateRule(c.Request.Context(), rule)
+	if err != nil {
+		h.alertRuleError(c, err, "failed to update alert rule", "alert_rule_update_failed")
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// @Summary      Delete alert rule
+// @Description  Alerts the rule already fired stay in the history.
+// @Tags         alerts
+// @Param        id   path  int  true  "Rule ID"
+// @Success      204
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/alerts/rules/{id} [delete]
+// @Security     BearerAuth
+func (h *Handler) deleteAlertRule(c *gin.Context) {
+	id, ok := ruleID(c)
+	if !ok {
+		return
+	}
+	if err := h.services.Alerts.DeleteRule(c.Request.Context(), id); err != nil {
+		h.alertRuleError(c, err, "failed to delete alert rule", "alert_rule_delete_failed")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary      List alerts
+// @Description  Returns fired alerts, newest first.
+// @Tags         alerts
+// @Produce      json
+// @Param        rule_id  query     integer  false  "Only alerts of this rule"
+// @Param        from     query     string   false  "Start of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')"
+// @Param        to       query     string   false  "End of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). Date-only treated as end of day."
+// @Param        limit    query     integer  false  "Maximum alerts (default 100, max 1000)"
+// @Success      200      {object}  map[string]interface{}  "count, alerts"
+// @Failure      400      {object}  Problem
+// @Failure      401      {object}  Problem
+// @Failure      500      {object}  Problem
+// @Router       /api/v1/alerts [get]
+// @Security     BearerAuth
+func (h *Handler) listAlerts(c *gin.Context) {
+	var (
+		f   service.AlertFilter
+		err error
+	)
+	if qs := c.Query("rule_id"); qs != "" {
+		if f.RuleID, err = strconv.Atoi(qs); err != nil || f.RuleID <= 0 {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, "invalid 'rule_id'")
+			return
+		}
+	}
+	if qs := c.Query("from"); qs != "" {
+		if f.From, err = parseQueryTime(qs); err != nil {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, errFromInvalid)
+			return
+		}
+	}
+	if qs := c.Query("to"); qs != "" {
+		if f.To, err = parseQueryTime(qs); err != nil {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, errToInvalid)
+			return
+		}
+		if isDateOnly(qs) {
+			f.To = f.To.Add(24*time.Hour - time.Nanosecond).UTC()
+		}
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.From.After(f.To) {
+		problem(c, http.StatusBadRequest, codeInvalidQuery, "'from' must be <= 'to'")
+		return
+	}
+	if qs := c.Query("limit"); qs != "" {
+		if f.Limit, err = strconv.Atoi(qs); err != nil || f.Limit < 0 {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, "invalid 'limit'; must be a non-negative integer")
+			return
+		}
+	}
+
+	alerts, err := h.services.Alerts.ListAlerts(c.Request.Context(), f)
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to list alerts", "alerts_list_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"count":  len(alerts),
+		"alerts": alerts,
+	})
+}
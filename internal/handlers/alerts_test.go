@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestAlertRules_CRUD(t *testing.T) {
+	alerts := &mockAlerts{rule: models.AlertRule{ID: 3, Name: "Too hot", Kind: models.AlertTempAbove, Threshold: 950, Enabled: true}}
+	auth := &mockAuth{parseID: 7, parseRole: models.RoleOperator}
+	r := newTestRouter(&service.Service{Authorization: auth, Alerts: alerts})
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer valid")
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/api/v1/alerts/rules", `{"name":"Too hot","kind":"temp_above","threshold":950,"for_seconds":30}`)
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"id":3`) {
+		t.Fatalf("create: status=%d body=%s", w.Code, w.Body.String())
+	}
+	if alerts.lastUserID != 7 || !alerts.lastRule.Enabled || alerts.lastRule.ForSeconds != 30 {
+		t.Fatalf("unexpected rule passed on: %+v by %d", alerts.lastRule, alerts.lastUserID)
+	}
+
+	w = do(http.MethodPut, "/api/v1/alerts/rules/3", `{"name":"Too hot","kind":"temp_above","threshold":900,"enabled":false}`)
+	if w.Code != http.StatusOK || alerts.lastRule.ID != 3 || alerts.lastRule.Enabled {
+		t.Fatalf("update: status=%d rule=%+v", w.Code, alerts.lastRule)
+	}
+	if w := do(http.MethodGet, "/api/v1/alerts/rules", ""); w.Code != http.StatusOK {
+		t.Fatalf("list: status=%d", w.Code)
+	}
+	if w := do(http.MethodDelete, "/api/v1/alerts/rules/3", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: status=%d", w.Code)
+	}
+
+	if w := do(http.MethodGet, "/api/v1/alerts/rules/abc", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad id, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/v1/alerts/rules", `{"kind":"temp_above"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without a name, got %d", w.Code)
+	}
+	alerts.err = fmt.Errorf("%w: kind must be one of ...", service.ErrInvalidAlertRule)
+	if w := do(http.MethodPost, "/api/v1/alerts/rules", `{"name":"x","kind":"bogus"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a rejected rule, got %d", w.Code)
+	}
+	alerts.err = service.ErrAlertRuleNotFound
+	if w := do(http.MethodGet, "/api/v1/alerts/rules/9", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+
+	auth.parseRole = models.RoleViewer
+	alerts.err = nil
+	if w := do(http.MethodDelete, "/api/v1/alerts/rules/3", ""); w.Code != http.StatusForbidden {
+		t.Fatalf("expected viewers to be refused, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v1/alerts/rules", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected viewers to list rules, got %d", w.Code)
+	}
+}
+
+func TestListAlerts(t *testing.T) {
+	alerts := &mockAlerts{alerts: []models.Alert{{ID: 1, RuleID: 3, Kind: models.AlertErrorEvent}}}
+	r := newTestRouter(&service.Service{Authorization: &mockAuth{parseID: 1}, Alerts: alerts})
+
+	get := func(q string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/alerts?"+q, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("rule_id=3&from=2025-09-20&to=2025-09-20&limit=5")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"count":1`) {
+		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
+	}
+	f := alerts.lastFilter
+	if f.RuleID != 3 || f.Limit != 5 || f.To.Sub(f.From).Hours() < 23 {
+		t.Fatalf("unexpected filter: %+v", f)
+	}
+	for _, q := range []string{"rule_id=x", "limit=-1", "from=yesterday", "from=2025-09-21&to=2025-09-20"} {
+		if w := get(q); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", q, w.Code)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AtmosphereRequest sets the protective gas. Omit gas and flow to turn the
+// purge off.
+type AtmosphereRequest struct {
+	// Purge gas. Allowed: N2, AR
+	Gas string `json:"gas,omitempty" example:"N2"`
+	// Gas flow setpoint in m³/h
+	FlowM3h float64 `json:"flow_m3h,omitempty" example:"20"`
+	// Residual oxygen that raises O2_HIGH while hot; the configured default when omitted
+	MaxO2PPM float64 `json:"max_o2_ppm,omitempty" example:"50"`
+}
+
+func (r AtmosphereRequest) params() service.AtmosphereParams {
+	return service.AtmosphereParams{Gas: r.Gas, FlowM3h: r.FlowM3h, MaxO2PPM: r.MaxO2PPM}
+}
+
+// @Summary      Set atmosphere
+// @Description  Sets the protective gas and its flow without changing the mode. Gas only flows while the furnace runs; residual oxygen then falls toward the level the purge can hold. While the chamber is hot, oxygen above max_o2_ppm raises O2_HIGH. Logs ATMOSPHERE_CHANGE.
+// @Tags         furnace
+// @Accept       json
+// @Produce      json
+// @Param        body  body      AtmosphereRequest  true  "Atmosphere"
+// @Success      200   {object}  map[string]interface{}  "status, state"
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      500   {object}  Problem
+// @Router       /api/v1/furnace/atmosphere [put]
+// @Security     BearerAuth
+func (h *Handler) setAtmosphere(c *gin.Context) {
+	var req AtmosphereRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, errInvalidBodyPref+err.Error())
+		return
+	}
+	err := h.services.Furnace.SetAtmosphere(c.Request.Context(), req.params())
+	if errors.Is(err, service.ErrInvalidAtmosphere) {
+		problemFor(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to set atmosphere", "furnace_set_atmosphere_failed", err)
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Infow("atmosphere_set", "gas", req.Gas, "flowM3h", req.FlowM3h, "userId", c.GetInt(ctxKeyUserID))
+	}
+	h.respondWithStatusAndState(c, statusAtmosphereSet, gin.H{})
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestSetAtmosphere(t *testing.T) {
+	fu := &mockFurnace{}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleOperator},
+		Furnace:       fu,
+		Monitoring:    &mockMonitoring{state: models.FurnaceState{ID: 1, Gas: "N2", O2PPM: 180000}},
+	}
+	r := newTestRouter(s)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPut, "/api/v1/furnace/atmosphere", `{"gas":"N2","flow_m3h":20,"max_o2_ppm":30}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"o2_ppm":180000`) {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if p := fu.lastAtmosphere; p == nil || p.Gas != "N2" || p.FlowM3h != 20 || p.MaxO2PPM != 30 {
+		t.Fatalf("params = %+v", fu.lastAtmosphere)
+	}
+
+	fu.atmosphereErr = fmt.Errorf("%w: bad gas", service.ErrInvalidAtmosphere)
+	if w := do(http.MethodPut, "/api/v1/furnace/atmosphere", `{"gas":"CO2","flow_m3h":5}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid gas: status=%d", w.Code)
+	}
+
+	w = do(http.MethodPost, "/api/v1/furnace/mode", `{"mode":"HEAT","target_temp_c":850,"duration_sec":600,"atmosphere":{"gas":"AR","flow_m3h":10}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("mode: status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if a := fu.lastSetMode.Atmosphere; a == nil || a.Gas != "AR" || a.FlowM3h != 10 {
+		t.Fatalf("mode atmosphere = %+v", fu.lastSetMode.Atmosphere)
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// attachmentWriter sends the download headers with the first bytes
+// written and extends the write deadline with each chunk, so large files
+// are not cut off by the server's write timeout. Errors before that still
+// answer JSON.
+type attachmentWriter struct {
+	c           *gin.Context
+	rc          *http.ResponseController
+	name        string
+	contentType string
+}
+
+func (a *attachmentWriter) Write(p []byte) (int, error) {
+	if !a.c.Writer.Written() {
+		a.c.Header("Content-Type", a.contentType)
+		a.c.Header("Content-Disposition", `attachment; filename="`+a.name+`"`)
+	}
+	_ = a.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	return a.c.Writer.Write(p)
+}
+
+// @Summary      Export audit package
+// @Description  Streams a ZIP of the records of a period for quality and compliance reviews: events.ndjson (the events with their comments), audit/chain.json (hash chain verification), alarms/alerts.json and alarms/incidents.json, runs.json (the runs the events belong to) and the current configuration under config/.
+// @Description  manifest.json lists each file with its size, record count and SHA-256, and the PEM public key with its fingerprint; manifest.sig is the raw Ed25519 signature of manifest.json, made with audit.signing_key. The key in the manifest is not a trust anchor: verify against the key from GET /api/v1/admin/audit/key, kept apart from the packages. Without audit.signing_key nothing is exported (409). A package cut short by an error has no signature. Admin only.
+// @Tags         admin
+// @Produce      application/zip
+// @Param        from  query     string  true  "Start of the period (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')"  example(2025-09-01)
+// @Param        to    query     string  true  "End of the period; date-only means end of day"  example(2025-09-30)
+// @Success      200   {file}    file
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      409   {object}  Problem
+// @Failure      500   {object}  Problem
+// @Router       /api/v1/admin/audit/export [get]
+// @Security     BearerAuth
+func (h *Handler) exportAudit(c *gin.Context) {
+	req := service.AuditExportRequest{UserID: c.GetInt(ctxKeyUserID)}
+	var err error
+	if req.From, err = parseQueryTime(c.Query("from")); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidQuery, errFromInvalid)
+		return
+	}
+	qs := c.Query("to")
+	if req.To, err = parseQueryTime(qs); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidQuery, errToInvalid)
+		return
+	}
+	if isDateOnly(qs) {
+		req.To = req.To.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	w := &attachmentWriter{
+		c:           c,
+		rc:          http.NewResponseController(c.Writer),
+		name:        "audit-" + req.From.Format("20060102T150405Z") + "-" + req.To.Format("20060102T150405Z") + ".zip",
+		contentType: "application/zip",
+	}
+	err = h.services.AuditExport.ExportAudit(c.Request.Context(), req, w)
+	switch {
+	case err == nil:
+		if h.log != nil {
+			h.requestLog(c).Infow("audit_exported", "from", req.From, "to", req.To, "bytes", c.Writer.Size())
+		}
+	case c.Writer.Written():
+		// the status is sent; the client is left with an unsigned package
+		if h.log != nil {
+			h.requestLog(c).Errorw("audit_export_failed", "err", err, "bytes", c.Writer.Size())
+		}
+	case errors.Is(err, service.ErrInvalidAuditExport):
+		problemFor(c, http.StatusBadRequest, err)
+	case errors.Is(err, service.ErrAuditKeyNotConfigured):
+		problemFor(c, http.StatusConflict, err)
+	default:
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to export audit package", "audit_export_failed", err)
+	}
+}
+
+// @Summary      Get the audit signing key
+// @Description  Returns the Ed25519 public key audit packages are signed with and its fingerprint (hex SHA-256 of the DER key, as printed at startup). Keep a copy apart from the packages and verify manifest.sig against it rather than the key inside the manifest. 409 when audit.signing_key is not configured. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  service.AuditKey
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Failure      409  {object}  Problem
+// @Router       /api/v1/admin/audit/key [get]
+// @Security     BearerAuth
+func (h *Handler) getAuditKey(c *gin.Context) {
+	key, err := h.services.AuditExport.AuditPublicKey()
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, key)
+	case errors.Is(err, service.ErrAuditKeyNotConfigured):
+		problemFor(c, http.StatusConflict, err)
+	default:
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to read audit key", "audit_key_failed", err)
+	}
+}
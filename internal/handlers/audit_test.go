@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestAuditHandler_Export(t *testing.T) {
+	audit := &mockAuditExport{body: "PK zip"}
+	auth := &mockAuth{parseID: 3, parseRole: models.RoleAdmin}
+	r := newTestRouter(&service.Service{Authorization: auth, AuditExport: audit})
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit/export"+query, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("?from=2025-09-01&to=2025-09-30")
+	if w.Code != http.StatusOK || w.Body.String() != "PK zip" || w.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("status=%d content-type=%q body=%q", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "audit-20250901T000000Z-20250930T235959Z.zip") {
+		t.Fatalf("Content-Disposition = %q", cd)
+	}
+	wantTo := time.Date(2025, 9, 30, 23, 59, 59, 999999999, time.UTC)
+	if audit.got.UserID != 3 || !audit.got.To.Equal(wantTo) {
+		t.Fatalf("passed %+v", audit.got)
+	}
+
+	for _, q := range []string{"", "?from=2025-09-01", "?from=yesterday&to=2025-09-30"} {
+		if w := get(q); w.Code != http.StatusBadRequest {
+			t.Fatalf("%q: expected 400, got %d", q, w.Code)
+		}
+	}
+	audit.body, audit.err = "", service.ErrInvalidAuditExport
+	if w := get("?from=2025-09-30&to=2025-09-01"); w.Code != http.StatusBadRequest || w.Header().Get("Content-Type") == "application/zip" {
+		t.Fatalf("expected a JSON 400, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	audit.err = errors.New("db down")
+	if w := get("?from=2025-09-01&to=2025-09-30"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+	audit.err = service.ErrAuditKeyNotConfigured
+	if p := decodeProblem(t, get("?from=2025-09-01&to=2025-09-30")); p.Status != http.StatusConflict || p.Code != "audit_key_not_configured" {
+		t.Fatalf("without a key: %+v", p)
+	}
+
+	auth.parseRole = models.RoleOperator
+	if w := get("?from=2025-09-01&to=2025-09-30"); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for an operator, got %d", w.Code)
+	}
+}
+
+func TestAuditHandler_Key(t *testing.T) {
+	audit := &mockAuditExport{key: service.AuditKey{PublicKey: "-----BEGIN PUBLIC KEY-----", Fingerprint: "3b6a"}}
+	auth := &mockAuth{parseID: 3, parseRole: models.RoleAdmin}
+	r := newTestRouter(&service.Service{Authorization: auth, AuditExport: audit})
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit/key", nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get(); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"fingerprint":"3b6a"`) {
+		t.Fatalf("key: %d %s", w.Code, w.Body.String())
+	}
+	audit.keyErr = service.ErrAuditKeyNotConfigured
+	if p := decodeProblem(t, get()); p.Status != http.StatusConflict || p.Code != "audit_key_not_configured" {
+		t.Fatalf("without a key: %+v", p)
+	}
+	auth.parseRole = models.RoleOperator
+	if w := get(); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for an operator, got %d", w.Code)
+	}
+}
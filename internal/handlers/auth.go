@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
+	"controlling_furnace/internal/service"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,34 +22,44 @@ type SignUpResponse struct {
 type TokenResponse struct {
 	Token string `json:"token"`
 }
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
 
-// bindJSONOrBadRequest tries to bind the request body into dst and writes a 400 JSON on failure.
+// bindJSONOrBadRequest tries to bind the request body into dst and writes a 400 problem on failure.
 // Returns false if the request was already handled (aborted), true otherwise.
 func (h *Handler) bindJSONOrBadRequest(c *gin.Context, dst any) bool {
 	if err := c.ShouldBindJSON(dst); err != nil {
 		// optional structured logging
 		if h.log != nil {
-			h.log.Infow("auth_bad_request_body", "err", err)
+			h.requestLog(c).Infow("auth_bad_request_body", "err", err)
 		}
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem(c, http.StatusBadRequest, codeInvalidBody, err.Error())
 		return false
 	}
 	return true
 }
 
+// invalidInput answers with err. A username the policy rejects also
+// carries the broken rule under "reason" (see service.Username*).
+func invalidInput(c *gin.Context, status int, err error) {
+	p := newProblem(c, status, problemCode(status, err), err.Error())
+	var uerr *service.UsernameError
+	if errors.As(err, &uerr) {
+		p.Reason = uerr.Reason
+	}
+	writeProblem(c, p)
+}
+
 // @Summary      Sign up
-// @Description  Register a new user
+// @Description  Register a new user as a viewer; an admin grants more with PUT /api/v1/admin/users/{id}/role. An empty installation answers 409 until the first admin is created through POST /api/v1/setup. The username is trimmed, NFC-normalized and checked against the configured policy (auth.usernames); a rejected name answers 400 with "reason" set to empty, too_short, too_long, invalid_character or reserved. Names are unique regardless of case: a taken one answers 409.
 // @Tags         auth
 // @ID           authSignUp
 // @Accept       json
 // @Produce      json
 // @Param        input  body   AuthCredentials  true  "User credentials"
 // @Success      200    {object}  SignUpResponse
-// @Failure      400    {object}  ErrorResponse  "Invalid request"
-// @Failure      500    {object}  ErrorResponse  "Internal server error"
+// @Failure      400    {object}  Problem  "Invalid request"
+// @Failure      409    {object}  Problem  "Setup required or username taken"
+// @Failure      429    {object}  Problem  "Too many attempts from this address"
+// @Failure      500    {object}  Problem  "Internal server error"
 // @Router       /auth/sign-up [post]
 func (h *Handler) signUp(c *gin.Context) {
 	var input AuthCredentials
@@ -54,12 +67,16 @@ func (h *Handler) signUp(c *gin.Context) {
 		return
 	}
 
-	id, err := h.services.SignUp(input.Username, input.Password)
+	id, err := h.services.SignUp(c.Request.Context(), input.Username, input.Password)
 	if err != nil {
 		if h.log != nil {
-			h.log.Infow("auth_sign_up_failed", "username", input.Username, "err", err)
+			h.requestLog(c).Infow("auth_sign_up_failed", "username", input.Username, "err", err)
+		}
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrSetupRequired) || errors.Is(err, service.ErrUsernameTaken) {
+			status = http.StatusConflict
 		}
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		invalidInput(c, status, err)
 		return
 	}
 
@@ -74,8 +91,9 @@ func (h *Handler) signUp(c *gin.Context) {
 // @Produce      json
 // @Param        input  body   AuthCredentials  true  "User credentials"
 // @Success      200    {object}  TokenResponse
-// @Failure      400    {object}  ErrorResponse  "Invalid request"
-// @Failure      401    {object}  ErrorResponse  "Unauthorized"
+// @Failure      400    {object}  Problem  "Invalid request"
+// @Failure      401    {object}  Problem  "Unauthorized"
+// @Failure      429    {object}  Problem  "Too many attempts from this address"
 // @Router       /auth/sign-in [post]
 func (h *Handler) signIn(c *gin.Context) {
 	var input AuthCredentials
@@ -83,12 +101,12 @@ func (h *Handler) signIn(c *gin.Context) {
 		return
 	}
 
-	token, err := h.services.GenerateToken(input.Username, input.Password)
+	token, err := h.services.GenerateToken(c.Request.Context(), input.Username, input.Password)
 	if err != nil {
 		if h.log != nil {
-			h.log.Infow("auth_sign_in_failed", "username", input.Username, "err", err)
+			h.requestLog(c).Infow("auth_sign_in_failed", "username", input.Username, "err", err)
 		}
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		problem(c, http.StatusUnauthorized, codeInvalidCredentials, "invalid credentials")
 		return
 	}
 
@@ -53,3 +53,28 @@ func TestAuthHandlers_SignUpAndSignIn(t *testing.T) {
 		t.Fatalf("expected 400 for bad body, got %d", w.Code)
 	}
 }
+
+func TestAuthHandlers_SignUpRejectedUsername(t *testing.T) {
+	auth := &mockAuth{}
+	r := newTestRouter(&service.Service{Authorization: auth})
+	post := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/auth/sign-up", bytes.NewBufferString(`{"username":"root","password":"p"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	auth.signUpErr = &service.UsernameError{Reason: service.UsernameReserved, Detail: `"root" is reserved`}
+	w := post()
+	var m map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &m)
+	if w.Code != http.StatusBadRequest || m["reason"] != service.UsernameReserved {
+		t.Fatalf("reserved: status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	auth.signUpErr = service.ErrUsernameTaken
+	if w := post(); w.Code != http.StatusConflict || bytes.Contains(w.Body.Bytes(), []byte("reason")) {
+		t.Fatalf("taken: status=%d body=%s", w.Code, w.Body.String())
+	}
+}
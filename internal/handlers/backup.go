@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"controlling_furnace/internal/repository"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Back up the database
+// @Description  Takes a consistent snapshot of the SQLite database while the server keeps running (VACUUM INTO), unlike a copy of the live WAL-mode file. By default it is stored in backup.dir, keeping the newest backup.keep snapshots, and described in the response; with download=true it is streamed as an attachment instead, with its SHA-256 in X-Backup-SHA256. Each backup logs a BACKUP event. Database calls wait while the snapshot is taken.
+// @Description  To restore, stop the server and run `furnace restore <snapshot>`. 409 without backup.dir unless downloading; 501 with db.driver=memory. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Produce      application/vnd.sqlite3
+// @Param        download  query     bool  false  "Stream the snapshot instead of storing it"
+// @Success      201       {object}  models.BackupReport
+// @Success      200       {file}    file
+// @Failure      401       {object}  Problem
+// @Failure      403       {object}  Problem
+// @Failure      409       {object}  Problem
+// @Failure      500       {object}  Problem
+// @Failure      501       {object}  Problem
+// @Router       /api/v1/admin/backup [post]
+// @Security     BearerAuth
+func (h *Handler) backupDatabase(c *gin.Context) {
+	if c.Query("download") == "true" {
+		h.downloadBackup(c)
+		return
+	}
+	rep, err := h.services.Backups.Backup(c.Request.Context())
+	if err != nil {
+		h.backupError(c, err)
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Infow("database_backed_up", "path", rep.Path, "bytes", rep.Bytes)
+	}
+	c.JSON(http.StatusCreated, rep)
+}
+
+func (h *Handler) downloadBackup(c *gin.Context) {
+	rep, snapshot, err := h.services.Backups.OpenBackup(c.Request.Context())
+	if err != nil {
+		h.backupError(c, err)
+		return
+	}
+	defer func() { _ = snapshot.Close() }()
+
+	c.Header("Content-Length", strconv.FormatInt(rep.Bytes, 10))
+	c.Header("X-Backup-SHA256", rep.SHA256)
+	c.Status(http.StatusOK)
+	w := &attachmentWriter{
+		c:           c,
+		rc:          http.NewResponseController(c.Writer),
+		name:        rep.File,
+		contentType: "application/vnd.sqlite3",
+	}
+	if _, err := io.Copy(w, snapshot); err != nil {
+		// the status is sent; the client is left with a truncated file
+		if h.log != nil {
+			h.requestLog(c).Errorw("backup_download_failed", "err", err, "bytes", c.Writer.Size())
+		}
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Infow("database_backup_downloaded", "bytes", rep.Bytes)
+	}
+}
+
+func (h *Handler) backupError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrBackupNotConfigured):
+		problemFor(c, http.StatusConflict, err)
+	case errors.Is(err, repository.ErrBackupUnsupported):
+		problemFor(c, http.StatusNotImplemented, err)
+	default:
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to back up database", "backup_failed", err)
+	}
+}
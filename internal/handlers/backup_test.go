@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+	"controlling_furnace/internal/service"
+)
+
+func TestBackupHandler(t *testing.T) {
+	backups := &mockBackups{
+		report:   models.BackupReport{File: "furnace-20250920T100000Z.db", Path: "backups/furnace-20250920T100000Z.db", Bytes: 6, SHA256: "abc"},
+		snapshot: "SQLite",
+	}
+	auth := &mockAuth{parseID: 1, parseRole: models.RoleAdmin}
+	r := newTestRouter(&service.Service{Authorization: auth, Backups: backups})
+
+	post := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/backup"+query, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post(""); w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"path":"backups/furnace-20250920T100000Z.db"`) {
+		t.Fatalf("stored backup: %d %s", w.Code, w.Body.String())
+	}
+	w := post("?download=true")
+	if w.Code != http.StatusOK || w.Body.String() != "SQLite" || w.Header().Get("X-Backup-SHA256") != "abc" {
+		t.Fatalf("download: %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "furnace-20250920T100000Z.db") {
+		t.Fatalf("Content-Disposition = %q", cd)
+	}
+
+	for err, code := range map[error]int{
+		service.ErrBackupNotConfigured:  http.StatusConflict,
+		repository.ErrBackupUnsupported: http.StatusNotImplemented,
+		errors.New("disk full"):         http.StatusInternalServerError,
+	} {
+		backups.err = err
+		if w := post(""); w.Code != code {
+			t.Fatalf("%v: expected %d, got %d", err, code, w.Code)
+		}
+	}
+
+	auth.parseRole = models.RoleOperator
+	if w := post(""); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for an operator, got %d", w.Code)
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"controlling_furnace/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const errChaosDisabled = "chaos mode is disabled; set chaos.enabled in config"
+
+// ChaosSettingsDTO is the wire form of repository fault-injection settings.
+type ChaosSettingsDTO struct {
+	// Whether faults are currently injected
+	Enabled bool `json:"enabled" example:"true"`
+	// Artificial latency added to affected repository calls
+	LatencyMs int `json:"latency_ms" example:"250"`
+	// Probability [0..1] that a call is delayed
+	LatencyProbability float64 `json:"latency_probability" example:"0.2"`
+	// Probability [0..1] that a call fails
+	ErrorProbability float64 `json:"error_probability" example:"0.05"`
+}
+
+func chaosToDTO(s repository.ChaosSettings) ChaosSettingsDTO {
+	return ChaosSettingsDTO{
+		Enabled:            s.Enabled,
+		LatencyMs:          int(s.Latency / time.Millisecond),
+		LatencyProbability: s.LatencyProbability,
+		ErrorProbability:   s.ErrorProbability,
+	}
+}
+
+func (d ChaosSettingsDTO) toSettings() repository.ChaosSettings {
+	return repository.ChaosSettings{
+		Enabled:            d.Enabled,
+		Latency:            time.Duration(d.LatencyMs) * time.Millisecond,
+		LatencyProbability: d.LatencyProbability,
+		ErrorProbability:   d.ErrorProbability,
+	}
+}
+
+// @Summary      Get chaos settings
+// @Description  Returns the repository fault-injection settings. Admin only; 404 unless chaos mode is enabled in config.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  ChaosSettingsDTO
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Router       /api/v1/admin/chaos [get]
+// @Security     BearerAuth
+func (h *Handler) getChaos(c *gin.Context) {
+	if h.services.Chaos == nil {
+		problem(c, http.StatusNotFound, codeFeatureDisabled, errChaosDisabled)
+		return
+	}
+	c.JSON(http.StatusOK, chaosToDTO(h.services.Chaos.ChaosSettings()))
+}
+
+// @Summary      Update chaos settings
+// @Description  Arms, tunes, or disarms repository fault injection. Admin only; 404 unless chaos mode is enabled in config.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ChaosSettingsDTO  true  "Chaos settings"
+// @Success      200   {object}  ChaosSettingsDTO
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      404   {object}  Problem
+// @Router       /api/v1/admin/chaos [put]
+// @Security     BearerAuth
+func (h *Handler) updateChaos(c *gin.Context) {
+	if h.services.Chaos == nil {
+		problem(c, http.StatusNotFound, codeFeatureDisabled, errChaosDisabled)
+		return
+	}
+	var req ChaosSettingsDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, errInvalidBodyPref+err.Error())
+		return
+	}
+	if err := h.services.Chaos.UpdateChaos(req.toSettings()); err != nil {
+		if errors.Is(err, repository.ErrInvalidChaosSettings) {
+			problemFor(c, http.StatusBadRequest, err)
+			return
+		}
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to update chaos settings", "chaos_update_failed", err)
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Warnw("chaos_settings_updated", "settings", req, "userId", c.GetInt(ctxKeyUserID))
+	}
+	c.JSON(http.StatusOK, chaosToDTO(h.services.Chaos.ChaosSettings()))
+}
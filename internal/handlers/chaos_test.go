@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+	"controlling_furnace/internal/service"
+)
+
+func TestChaosHandlers_AdminOnly(t *testing.T) {
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleOperator},
+		Chaos:         &mockChaos{},
+	}
+	r := newTestRouter(s)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/chaos", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for operator, got %d", w.Code)
+	}
+}
+
+func TestChaosHandlers_DisabledReturns404(t *testing.T) {
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleAdmin},
+	}
+	r := newTestRouter(s)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/chaos", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 when chaos is not configured, got %d", w.Code)
+	}
+}
+
+func TestChaosHandlers_GetAndUpdate(t *testing.T) {
+	chaos := &mockChaos{}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleAdmin},
+		Chaos:         chaos,
+	}
+	r := newTestRouter(s)
+
+	body := bytes.NewBufferString(`{"enabled":true,"latency_ms":250,"latency_probability":0.5,"error_probability":0.1}`)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/chaos", body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update status=%d, body=%s", w.Code, w.Body.String())
+	}
+	want := repository.ChaosSettings{Enabled: true, Latency: 250 * time.Millisecond, LatencyProbability: 0.5, ErrorProbability: 0.1}
+	if chaos.settings != want {
+		t.Fatalf("settings not applied: %+v", chaos.settings)
+	}
+
+	var out ChaosSettingsDTO
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !out.Enabled || out.LatencyMs != 250 {
+		t.Fatalf("unexpected response: %+v", out)
+	}
+
+	// Validation errors from the service map to 400.
+	chaos.updateErr = fmt.Errorf("%w: bad", repository.ErrInvalidChaosSettings)
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/admin/chaos", bytes.NewBufferString(`{"error_probability":2}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 on invalid settings, got %d", w.Code)
+	}
+}
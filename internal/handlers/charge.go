@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InsertChargeRequest describes a load placed in the furnace.
+type InsertChargeRequest struct {
+	// Mass of the load
+	MassKg float64 `json:"mass_kg" binding:"required" example:"800"`
+	// Specific heat; carbon steel (490) when omitted
+	SpecificHeat float64 `json:"specific_heat_j_per_kg_k,omitempty" example:"490"`
+	// Temperature of the load; room temperature when omitted
+	TempC *float64 `json:"temp_c,omitempty" example:"20"`
+}
+
+// @Summary      Get furnace charge
+// @Description  Reports the simulated load in the furnace and its current temperature.
+// @Tags         furnace
+// @Produce      json
+// @Success      200  {object}  service.ChargeStatus
+// @Failure      401  {object}  Problem
+// @Router       /api/v1/furnace/charge [get]
+// @Security     BearerAuth
+func (h *Handler) getCharge(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.Charges.Charge())
+}
+
+// @Summary      Insert charge
+// @Description  Simulates loading a charge into the furnace. From the next tick it exchanges heat with the chamber: a cold load makes the chamber temperature dip and recover slowly while the heater brings both up. Logs CHARGE_INSERTED. Not persisted across restarts.
+// @Tags         furnace
+// @Accept       json
+// @Produce      json
+// @Param        body  body      InsertChargeRequest  true  "Charge"
+// @Success      202   {object}  service.ChargeStatus
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      409   {object}  Problem  "A charge is already in the furnace"
+// @Router       /api/v1/furnace/charge [post]
+// @Security     BearerAuth
+func (h *Handler) insertCharge(c *gin.Context) {
+	var req InsertChargeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, errInvalidBodyPref+err.Error())
+		return
+	}
+	st, err := h.services.Charges.InsertCharge(service.ChargeRequest{
+		MassKg:       req.MassKg,
+		SpecificHeat: req.SpecificHeat,
+		TempC:        req.TempC,
+	})
+	switch {
+	case errors.Is(err, service.ErrInvalidCharge):
+		problemFor(c, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, service.ErrChargeLoaded):
+		problemFor(c, http.StatusConflict, err)
+		return
+	case err != nil:
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to insert charge", "charge_insert_failed", err)
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Infow("charge_inserted", "massKg", st.MassKg, "tempC", st.TempC, "userId", c.GetInt(ctxKeyUserID))
+	}
+	c.JSON(http.StatusAccepted, st)
+}
+
+// @Summary      Remove charge
+// @Description  Takes the simulated load out of the furnace and returns it as removed. Logs CHARGE_REMOVED on the next tick.
+// @Tags         furnace
+// @Produce      json
+// @Success      200  {object}  service.ChargeStatus
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Failure      404  {object}  Problem  "No charge in the furnace"
+// @Router       /api/v1/furnace/charge [delete]
+// @Security     BearerAuth
+func (h *Handler) removeCharge(c *gin.Context) {
+	st, err := h.services.Charges.RemoveCharge()
+	if errors.Is(err, service.ErrNoCharge) {
+		problemFor(c, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to remove charge", "charge_remove_failed", err)
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Infow("charge_removed", "massKg", st.MassKg, "tempC", st.TempC, "userId", c.GetInt(ctxKeyUserID))
+	}
+	c.JSON(http.StatusOK, st)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestChargeHandlers(t *testing.T) {
+	charges := &mockCharges{status: service.ChargeStatus{Loaded: true, MassKg: 800, SpecificHeat: 490}}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleOperator},
+		Charges:       charges,
+	}
+	r := newTestRouter(s)
+	do := func(method, body string) *httptest.ResponseRecorder {
+		var rd io.Reader
+		if body != "" {
+			rd = strings.NewReader(body)
+		}
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/api/v1/furnace/charge", rd)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodPost, `{"mass_kg":800,"temp_c":-5}`); w.Code != http.StatusAccepted {
+		t.Fatalf("insert: status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if charges.lastReq.MassKg != 800 || charges.lastReq.TempC == nil || *charges.lastReq.TempC != -5 {
+		t.Fatalf("request = %+v", charges.lastReq)
+	}
+	if w := do(http.MethodGet, ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"mass_kg":800`) {
+		t.Fatalf("get: status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing mass: status=%d, want 400", w.Code)
+	}
+
+	charges.err = service.ErrChargeLoaded
+	if w := do(http.MethodPost, `{"mass_kg":800}`); w.Code != http.StatusConflict {
+		t.Fatalf("second charge: status=%d, want 409", w.Code)
+	}
+	charges.err = service.ErrNoCharge
+	if w := do(http.MethodDelete, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("remove from empty furnace: status=%d, want 404", w.Code)
+	}
+	charges.err = nil
+	if w := do(http.MethodDelete, ""); w.Code != http.StatusOK {
+		t.Fatalf("remove: status=%d", w.Code)
+	}
+}
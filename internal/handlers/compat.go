@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// compatHeader lets a client pick a field naming profile per request.
+const compatHeader = "X-API-Compat"
+
+// ctxKeySchemaVersion holds the payload version the request is pinned to,
+// 0 for the current one, for renderers the JSON rewrite does not reach.
+const ctxKeySchemaVersion = "schemaVersion"
+
+// Field naming styles a profile can start from.
+const (
+	NamingSnake = "snake" // canonical, as documented in Swagger
+	NamingCamel = "camel"
+)
+
+// ErrInvalidCompat is returned by CompatConfig.Validate.
+var ErrInvalidCompat = errors.New("invalid compat config")
+
+// CompatProfile maps canonical JSON field names to the ones a legacy client
+// expects. Fields overrides Naming for the keys it lists.
+type CompatProfile struct {
+	Naming string            `mapstructure:"naming"` // "" or snake keeps canonical names
+	Fields map[string]string `mapstructure:"fields"` // canonical name -> client name
+	// SchemaVersion pins state and event payloads to an older contract
+	// version; 0 follows the current one. The X-Schema-Version header wins.
+	SchemaVersion int `mapstructure:"schema_version"`
+}
+
+// CompatConfig selects how JSON field names are presented to clients.
+// A request uses the profile named in the X-API-Compat header, else the one
+// bound to its API version, else Default. "camel" is always available.
+type CompatConfig struct {
+	Default  string                   `mapstructure:"default"`
+	Profiles map[string]CompatProfile `mapstructure:"profiles"`
+	// Versions mounts extra API versions (e.g. "v0") serving the v1 routes
+	// through the named profile.
+	Versions map[string]string `mapstructure:"versions"`
+}
+
+// Validate checks that every referenced profile exists and is well formed.
+func (c CompatConfig) Validate() error {
+	for name, p := range c.Profiles {
+		switch p.Naming {
+		case "", NamingSnake, NamingCamel:
+		default:
+			return fmt.Errorf("%w: profile %q: unknown naming %q", ErrInvalidCompat, name, p.Naming)
+		}
+		if p.SchemaVersion != 0 {
+			if err := checkSchemaVersion(p.SchemaVersion); err != nil {
+				return fmt.Errorf("%w: profile %q: %v", ErrInvalidCompat, name, err)
+			}
+		}
+	}
+	if _, ok := c.profile(c.Default); c.Default != "" && !ok {
+		return fmt.Errorf("%w: unknown default profile %q", ErrInvalidCompat, c.Default)
+	}
+	for v, name := range c.Versions {
+		if v == "" || v == "v1" || v == "v2" {
+			return fmt.Errorf("%w: version %q is reserved", ErrInvalidCompat, v)
+		}
+		if _, ok := c.profile(name); !ok {
+			return fmt.Errorf("%w: version %q: unknown profile %q", ErrInvalidCompat, v, name)
+		}
+	}
+	return nil
+}
+
+// profile looks up a profile by case-insensitive name. A nil *fieldMapper
+// with ok=true means canonical names.
+func (c CompatConfig) profile(name string) (*fieldMapper, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for n, p := range c.Profiles {
+		if strings.ToLower(n) == name {
+			return newFieldMapper(p), true
+		}
+	}
+	switch name {
+	case "", NamingSnake:
+		return nil, true
+	case NamingCamel:
+		return newFieldMapper(CompatProfile{Naming: NamingCamel}), true
+	}
+	return nil, false
+}
+
+// fieldMapper renames JSON object keys in both directions.
+type fieldMapper struct {
+	camel   bool
+	out, in map[string]string
+	schema  int // pinned payload version; 0 for current
+}
+
+func newFieldMapper(p CompatProfile) *fieldMapper {
+	m := &fieldMapper{
+		camel:  p.Naming == NamingCamel,
+		schema: p.SchemaVersion,
+		out:    make(map[string]string, len(p.Fields)),
+		in:     make(map[string]string, len(p.Fields)),
+	}
+	for canonical, client := range p.Fields {
+		m.out[canonical] = client
+		m.in[client] = canonical
+	}
+	return m
+}
+
+func (m *fieldMapper) toClient(key string) string {
+	if v, ok := m.out[key]; ok {
+		return v
+	}
+	if m.camel {
+		return snakeToCamel(key)
+	}
+	return key
+}
+
+func (m *fieldMapper) toCanonical(key string) string {
+	if v, ok := m.in[key]; ok {
+		return v
+	}
+	if m.camel {
+		return camelToSnake(key)
+	}
+	return key
+}
+
+// rewriteKeys re-encodes a JSON document with every object key passed through
+// rename. Numbers are kept verbatim.
+func rewriteKeys(data []byte, rename func(string) string) ([]byte, error) {
+	return rewriteJSON(data, 0, rename)
+}
+
+// rewriteJSON is rewriteKeys that first renders state and event payloads as
+// schema version; 0 keeps them current.
+func rewriteJSON(data []byte, version int, rename func(string) string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(renameKeys(downgradeAll(v, version), rename))
+}
+
+func renameKeys(v any, rename func(string) string) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, val := range t {
+			out[rename(k)] = renameKeys(val, rename)
+		}
+		return out
+	case []any:
+		for i := range t {
+			t[i] = renameKeys(t[i], rename)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+func snakeToCamel(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if p := parts[i]; p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func camelToSnake(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// compatWriter holds the response body back so its keys can be renamed.
+// Streaming handlers switch it to direct and rewrite each record
+// themselves (see streamThrough).
+type compatWriter struct {
+	gin.ResponseWriter
+	body    bytes.Buffer
+	rewrite func([]byte) []byte
+	direct  bool
+}
+
+func (w *compatWriter) Write(b []byte) (int, error) {
+	if w.direct {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+func (w *compatWriter) WriteString(s string) (int, error) {
+	if w.direct {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+func (w *compatWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// streamThrough lets a handler that writes its response incrementally
+// bypass the compat buffer. It returns the rewrite each JSON record must
+// then pass through, the identity when no profile applies.
+func streamThrough(c *gin.Context) func([]byte) []byte {
+	if w, ok := c.Writer.(*compatWriter); ok {
+		w.direct = true
+		return w.rewrite
+	}
+	return func(b []byte) []byte { return b }
+}
+
+func isJSON(contentType string) bool {
+	return strings.HasPrefix(strings.TrimSpace(contentType), "application/json")
+}
+
+// compatMiddleware translates JSON request and response bodies between the
+// canonical field names and the client's profile, and renders state and
+// event payloads at the schema version the client pinned. fixed, when
+// non-empty, pins the profile for a legacy API version; the header still wins.
+func (h *Handler) compatMiddleware(fixed string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.GetHeader(compatHeader)
+		if name == "" {
+			name = fixed
+		}
+		if name == "" {
+			name = h.compat.Default
+		}
+		m, ok := h.compat.profile(name)
+		if !ok {
+			abortProblem(c, http.StatusBadRequest, codeUnknownProfile, "unknown "+compatHeader+" profile")
+			return
+		}
+		version, err := parseSchemaVersion(c.GetHeader(schemaHeader))
+		if err != nil {
+			abortProblem(c, http.StatusBadRequest, codeInvalidSchemaVersion, err.Error())
+			return
+		}
+		rename := func(k string) string { return k }
+		if m != nil {
+			rename = m.toClient
+			if version == 0 {
+				version = m.schema
+			}
+		}
+		c.Set(ctxKeySchemaVersion, version)
+		if m == nil && version == 0 {
+			c.Next()
+			return
+		}
+
+		if m != nil && c.Request.Body != nil && isJSON(c.ContentType()) {
+			raw, err := io.ReadAll(c.Request.Body)
+			if err == nil {
+				// Malformed bodies pass through untouched so binding reports them.
+				if out, rerr := rewriteKeys(raw, m.toCanonical); rerr == nil {
+					raw = out
+				}
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+			c.Request.ContentLength = int64(len(raw))
+		}
+
+		w := &compatWriter{ResponseWriter: c.Writer, rewrite: func(b []byte) []byte {
+			if out, err := rewriteJSON(b, version, rename); err == nil {
+				return out
+			}
+			return b
+		}}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+		if w.direct {
+			return
+		}
+
+		body := w.body.Bytes()
+		if isJSON(w.Header().Get("Content-Type")) && len(body) > 0 {
+			body = w.rewrite(body)
+		}
+		_, _ = w.ResponseWriter.Write(body)
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newCompatRouter(s *service.Service, cfg CompatConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return NewHandlerWithConfig(s, nil, Config{Compat: cfg}).InitRoutes()
+}
+
+func TestCompat_CamelHeaderRenamesBothWays(t *testing.T) {
+	fu := &mockFurnace{}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleOperator},
+		Monitoring:    &mockMonitoring{state: models.FurnaceState{Mode: "HEAT", CurrentTempC: 500, IsRunning: true}},
+		Furnace:       fu,
+	}
+	r := newCompatRouter(s, CompatConfig{})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/furnace/mode", bytes.NewBufferString(`{"mode":"HEAT","targetTempC":850,"durationSec":600}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer valid")
+	req.Header.Set(compatHeader, "camel")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if fu.lastSetMode.TargetTempC != 850 || fu.lastSetMode.DurationSec != 600 {
+		t.Fatalf("camelCase body not mapped: %+v", fu.lastSetMode)
+	}
+
+	var out map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	st, _ := out["state"].(map[string]any)
+	if st["currentTempC"] != 500.0 || st["isRunning"] != true || st["current_temp_c"] != nil {
+		t.Fatalf("expected camelCase state, got %v", out)
+	}
+}
+
+func TestCompat_LegacyVersionUsesProfile(t *testing.T) {
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1},
+		Monitoring:    &mockMonitoring{state: models.FurnaceState{Mode: "HEAT", CurrentTempC: 500, TargetTempC: 800}},
+	}
+	cfg := CompatConfig{
+		Profiles: map[string]CompatProfile{
+			"legacy": {Fields: map[string]string{"current_temp_c": "temp", "target_temp_c": "setpoint"}},
+		},
+		Versions: map[string]string{"v0": "legacy"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	r := newCompatRouter(s, cfg)
+
+	get := func(path, profile string) map[string]any {
+		t.Helper()
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		if profile != "" {
+			req.Header.Set(compatHeader, profile)
+		}
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s status=%d, body=%s", path, w.Code, w.Body.String())
+		}
+		var out map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		return out
+	}
+
+	if got := get("/api/v0/furnace/state", ""); got["temp"] != 500.0 || got["setpoint"] != 800.0 || got["is_running"] != false {
+		t.Fatalf("unexpected legacy body: %v", got)
+	}
+	if got := get("/api/v1/furnace/state", ""); got["current_temp_c"] != 500.0 {
+		t.Fatalf("v1 must stay canonical: %v", got)
+	}
+	// The header overrides the version's profile.
+	if got := get("/api/v0/furnace/state", "snake"); got["current_temp_c"] != 500.0 {
+		t.Fatalf("expected canonical body with snake header: %v", got)
+	}
+}
+
+func TestCompat_UnknownProfileIs400(t *testing.T) {
+	r := newCompatRouter(&service.Service{Authorization: &mockAuth{parseID: 1}}, CompatConfig{})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/furnace/state", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	req.Header.Set(compatHeader, "cobol")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestCompatConfig_Validate(t *testing.T) {
+	bad := []CompatConfig{
+		{Default: "nope"},
+		{Profiles: map[string]CompatProfile{"x": {Naming: "kebab"}}},
+		{Versions: map[string]string{"v1": "camel"}},
+		{Versions: map[string]string{"v2": "camel"}},
+		{Versions: map[string]string{"v0": "missing"}},
+	}
+	for i, cfg := range bad {
+		if err := cfg.Validate(); !errors.Is(err, ErrInvalidCompat) {
+			t.Fatalf("case %d: expected ErrInvalidCompat, got %v", i, err)
+		}
+	}
+	if err := (CompatConfig{Default: "Camel"}).Validate(); err != nil {
+		t.Fatalf("built-in camel profile rejected: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+
+	"controlling_furnace/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stateETag tags the representation of st a request receives. The state
+// changes only with UpdatedAt, so the tag needs no serialisation; the
+// compat profile, pinned schema version and format select other renderings
+// of the same state and are folded in.
+func (h *Handler) stateETag(c *gin.Context, st models.FurnaceState) string {
+	tag := fmt.Sprintf("%x-%d", st.UpdatedAt.UnixNano(), models.SchemaVersion)
+	profile := c.GetHeader(compatHeader)
+	if profile == "" {
+		profile = h.compat.Default
+	}
+	format := renderFormat(c)
+	if format == gin.MIMEJSON {
+		format = ""
+	}
+	if schema := c.GetHeader(schemaHeader); profile != "" || schema != "" || format != "" {
+		f := fnv.New32a()
+		_, _ = f.Write([]byte(profile + "\n" + schema + "\n" + format))
+		tag += fmt.Sprintf("-%x", f.Sum32())
+	}
+	// weak: derived fields such as the rate are best effort
+	return `W/"` + tag + `"`
+}
+
+// notModified sets the validators of st on the response and, when the
+// request's If-None-Match (or, without one, If-Modified-Since) shows the
+// client already holds it, answers 304 and returns true.
+func (h *Handler) notModified(c *gin.Context, st models.FurnaceState) bool {
+	etag := h.stateETag(c, st)
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", st.UpdatedAt.UTC().Format(http.TimeFormat))
+	c.Header("Vary", "Accept, "+compatHeader+", "+schemaHeader)
+
+	fresh := false
+	if inm := c.GetHeader("If-None-Match"); inm != "" {
+		fresh = etagMatches(inm, etag)
+	} else if ims, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil {
+		// HTTP dates have whole seconds; two updates within one second are
+		// told apart only by the ETag
+		fresh = !st.UpdatedAt.Truncate(time.Second).After(ims)
+	}
+	if fresh {
+		c.Status(http.StatusNotModified)
+	}
+	return fresh
+}
+
+// etagMatches reports whether an If-None-Match list names etag, comparing
+// weakly as RFC 9110 requires for that header.
+func etagMatches(list, etag string) bool {
+	weak := func(t string) string { return strings.TrimPrefix(strings.TrimSpace(t), "W/") }
+	for _, t := range strings.Split(list, ",") {
+		if strings.TrimSpace(t) == "*" || weak(t) == weak(etag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestGetState_ConditionalGET(t *testing.T) {
+	updated := time.Date(2025, 9, 1, 10, 0, 0, 500_000_000, time.UTC)
+	mon := &mockMonitoring{state: models.FurnaceState{ID: 1, Mode: "HEAT", UpdatedAt: updated}}
+	r := newTestRouter(&service.Service{Authorization: &mockAuth{parseID: 1, parseRole: models.RoleViewer}, Monitoring: mon})
+	get := func(headers ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/furnace/state", nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		for i := 0; i < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get()
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" || w.Header().Get("Last-Modified") != "Mon, 01 Sep 2025 10:00:00 GMT" {
+		t.Fatalf("first poll: %d, ETag %q, Last-Modified %q", w.Code, etag, w.Header().Get("Last-Modified"))
+	}
+	if w := get("If-None-Match", etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+		t.Fatalf("unchanged: %d %q, ETag %q", w.Code, w.Body.String(), w.Header().Get("ETag"))
+	}
+	if w := get("If-None-Match", `"other", `+etag[2:]); w.Code != http.StatusNotModified {
+		t.Fatalf("listed strong form: %d", w.Code)
+	}
+	if w := get("If-Modified-Since", "Mon, 01 Sep 2025 10:00:00 GMT"); w.Code != http.StatusNotModified {
+		t.Fatalf("not modified since: %d", w.Code)
+	}
+	// If-None-Match wins over a matching If-Modified-Since
+	if w := get("If-None-Match", `W/"stale"`, "If-Modified-Since", "Mon, 01 Sep 2025 10:00:00 GMT"); w.Code != http.StatusOK {
+		t.Fatalf("stale ETag: %d", w.Code)
+	}
+	// another rendering of the same state is another representation
+	if w := get("If-None-Match", etag, compatHeader, "camel"); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Fatalf("camel profile: %d, ETag %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	mon.state.UpdatedAt = updated.Add(time.Second)
+	if w := get("If-None-Match", etag); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Fatalf("changed state: %d, ETag %q", w.Code, w.Header().Get("ETag"))
+	}
+	if w := get("If-Modified-Since", "Mon, 01 Sep 2025 10:00:00 GMT"); w.Code != http.StatusOK {
+		t.Fatalf("modified since: %d", w.Code)
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// demoRoutes are opened to anonymous callers in demo mode. The state
+// stream on /ws follows GET /furnace/state (see wsStateRoute).
+var demoRoutes = []string{
+	"GET /furnace/state",
+	"GET /furnaces",
+	"GET /furnaces/:id",
+	"GET /logs/",
+	"GET /logs/tail",
+	"GET /logs/verify",
+	"GET /logs/schema",
+}
+
+// demoPermissions opens demoRoutes in perms.
+func demoPermissions(perms map[string]Permission) {
+	for _, route := range demoRoutes {
+		perms[route] = PermPublic
+	}
+}
+
+// demoReadOnly rejects every request that could change something, before
+// routing and whatever token it carries, so a public demo cannot be
+// steered or filled with accounts. Signing in stays possible for accounts
+// created before the demo was opened.
+func demoReadOnly(c *gin.Context) {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		c.Next()
+		return
+	}
+	if c.Request.Method == http.MethodPost && c.Request.URL.Path == "/auth/sign-in" {
+		c.Next()
+		return
+	}
+	abortProblem(c, http.StatusForbidden, codeReadOnlyDemo, "read-only demo: changes are disabled")
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDemo_PublicReadsAndNoChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fu := &mockFurnace{}
+	logs := &mockEventLog{resp: []models.FurnaceEvent{{EventID: "e1"}}}
+	auth := &mockAuth{parseID: 1, parseRole: models.RoleAdmin, signUpID: 2, genTokenToken: "tok"}
+	s := &service.Service{
+		Authorization: auth,
+		Furnace:       fu,
+		Monitoring:    &mockMonitoring{state: models.FurnaceState{Mode: "HEAT"}},
+		EventLog:      logs,
+		EventPager:    logs,
+	}
+	r := NewHandlerWithConfig(s, nil, Config{Demo: true}).InitRoutes()
+	call := func(method, path, token, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, path := range []string{"/api/v1/furnace/state", "/api/v1/logs/"} {
+		if w := call(http.MethodGet, path, "", ""); w.Code != http.StatusOK {
+			t.Fatalf("anonymous GET %s: %d %s", path, w.Code, w.Body.String())
+		}
+	}
+	// the stream passes authentication and fails only for lack of an upgrade
+	if w := call(http.MethodGet, "/ws", "", ""); w.Code == http.StatusUnauthorized {
+		t.Fatalf("anonymous /ws: %d", w.Code)
+	}
+	// other reads keep their permission
+	if w := call(http.MethodGet, "/api/v1/telemetry", "", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous GET /telemetry: %d", w.Code)
+	}
+
+	for _, path := range []string{"/api/v1/furnace/start", "/api/v1/logs/purge", "/api/v1/setup", "/auth/sign-up"} {
+		w := call(http.MethodPost, path, "valid", `{"username":"u","password":"p"}`)
+		if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "read-only demo") {
+			t.Fatalf("admin POST %s: %d %s", path, w.Code, w.Body.String())
+		}
+	}
+	if w := call(http.MethodDelete, "/api/v1/alerts/rules/1", "valid", ""); w.Code != http.StatusForbidden {
+		t.Fatalf("admin DELETE: %d", w.Code)
+	}
+	if fu.startCalled != 0 || auth.lastSignUpUsername != "" {
+		t.Fatalf("a change reached the services: start=%d sign-up=%q", fu.startCalled, auth.lastSignUpUsername)
+	}
+
+	if w := call(http.MethodPost, "/auth/sign-in", "", `{"username":"u","password":"p"}`); w.Code != http.StatusOK {
+		t.Fatalf("sign-in: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -1,32 +1,49 @@
 package handlers
 
 import (
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
 	"controlling_furnace/internal/service"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Common response/status constants to avoid magic strings and typos.
 const (
-	statusOK      = "ok"
-	statusStarted = "started"
-	statusStopped = "stopped"
-	statusModeSet = "mode_set"
+	statusOK            = "ok"
+	statusStarted       = "started"
+	statusStopped       = "stopped"
+	statusModeSet       = "mode_set"
+	statusAtmosphereSet = "atmosphere_set"
 
 	errStartFurnace    = "failed to start furnace"
 	errStopFurnace     = "failed to stop furnace"
 	errGetState        = "failed to load state"
+	errCheckReadiness  = "failed to check readiness"
+	errGetHealth       = "failed to load furnace health"
 	errInvalidBodyPref = "invalid body: "
+	errDatabaseBusy    = "database is busy, try again"
 )
 
-// Centralized error logging and response.
+// busyRetryAfter is the Retry-After sent with a 503 for a locked database.
+const busyRetryAfter = "1"
+
+// Centralized error logging and response. A server error caused by the
+// database staying locked past its retries is reported as a 503, which
+// clients may retry, rather than a 500.
 func (h *Handler) logAndJSONError(c *gin.Context, httpCode int, userMsg, logKey string, err error, kv ...interface{}) {
 	if h.log != nil && err != nil {
 		fields := append([]interface{}{"err", err}, kv...)
-		h.log.Errorw(logKey, fields...)
+		h.requestLog(c).Errorw(logKey, fields...)
+	}
+	code := ""
+	if httpCode == http.StatusInternalServerError && repository.IsBusy(err) {
+		httpCode, code, userMsg = http.StatusServiceUnavailable, codeDatabaseBusy, errDatabaseBusy
+		c.Header("Retry-After", busyRetryAfter)
 	}
-	c.JSON(httpCode, gin.H{"error": userMsg})
+	problem(c, httpCode, code, userMsg)
 }
 
 // Respond with a status and include current state if available (best-effort).
@@ -45,9 +62,10 @@ func (h *Handler) respondWithStatusAndState(c *gin.Context, status string, extra
 
 // Request DTO for setting mode.
 type modeRequest struct {
-	Mode        string  `json:"mode" binding:"required"` // HEAT | COOL | STANDBY
-	TargetTempC float64 `json:"target_temp_c,omitempty"` // required if mode=HEAT
-	DurationSec int     `json:"duration_sec,omitempty"`  // required if mode=HEAT
+	Mode        string             `json:"mode" binding:"required"` // HEAT | COOL | STANDBY
+	TargetTempC float64            `json:"target_temp_c,omitempty"` // required if mode=HEAT
+	DurationSec int                `json:"duration_sec,omitempty"`  // required if mode=HEAT
+	Atmosphere  *AtmosphereRequest `json:"atmosphere,omitempty"`
 }
 
 // SetModeRequest is an exported model for Swagger docs of the setMode payload.
@@ -58,12 +76,16 @@ type SetModeRequest struct {
 	TargetTempC float64 `json:"target_temp_c,omitempty" example:"850"`
 	// Heating duration in seconds (required when mode=HEAT)
 	DurationSec int `json:"duration_sec,omitempty" example:"600"`
+	// Protective gas to switch to with the mode; unchanged when omitted
+	Atmosphere *AtmosphereRequest `json:"atmosphere,omitempty"`
 }
 
-// @Summary      Health check
+// @Summary      Liveness probe
+// @Description  Reports that the process is serving HTTP; it checks no dependencies. /health is kept as an alias.
 // @Tags         system
 // @Produce      json
 // @Success      200  {object}  map[string]string
+// @Router       /healthz [get]
 // @Router       /health [get]
 func (h *Handler) health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
@@ -71,12 +93,31 @@ func (h *Handler) health(c *gin.Context) {
 	})
 }
 
+// @Summary      Readiness probe
+// @Description  Checks that SQLite accepts writes, that schema migrations are applied and that the simulator ticked recently. Responds 503 with the failing components otherwise.
+// @Tags         system
+// @Produce      json
+// @Success      200  {object}  service.ReadinessReport
+// @Failure      503  {object}  service.ReadinessReport
+// @Router       /readyz [get]
+func (h *Handler) ready(c *gin.Context) {
+	rep := h.services.Probes.Ready(c.Request.Context())
+	if !rep.Ready {
+		if h.log != nil {
+			h.requestLog(c).Warnw("readiness_check_failed", "components", rep.Components)
+		}
+		c.JSON(http.StatusServiceUnavailable, rep)
+		return
+	}
+	c.JSON(http.StatusOK, rep)
+}
+
 // @Summary      Start furnace
 // @Tags         furnace
 // @Produce      json
 // @Success      200  {object}  map[string]interface{}  "status, state"
-// @Failure      401  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
 // @Router       /api/v1/furnace/start [post]
 // @Security     BearerAuth
 func (h *Handler) startFurnace(c *gin.Context) {
@@ -92,8 +133,8 @@ func (h *Handler) startFurnace(c *gin.Context) {
 // @Tags         furnace
 // @Produce      json
 // @Success      200  {object}  map[string]interface{}
-// @Failure      401  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
 // @Router       /api/v1/furnace/stop [post]
 // @Security     BearerAuth
 func (h *Handler) stopFurnace(c *gin.Context) {
@@ -106,21 +147,21 @@ func (h *Handler) stopFurnace(c *gin.Context) {
 }
 
 // @Summary      Set mode
-// @Description  HEAT requires target_temp_c and duration_sec
+// @Description  HEAT requires target_temp_c and duration_sec. An atmosphere object changes the protective gas in the same command.
 // @Tags         furnace
 // @Accept       json
 // @Produce      json
 // @Param        body  body   SetModeRequest  true  "Mode payload"
 // @Success      200   {object}  map[string]interface{}
-// @Failure      400   {object}  map[string]string
-// @Failure      401   {object}  map[string]string
-// @Failure      500   {object}  map[string]string
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      500   {object}  Problem
 // @Router       /api/v1/furnace/mode [post]
 // @Security     BearerAuth
 func (h *Handler) setMode(c *gin.Context) {
 	var req modeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidBodyPref + err.Error()})
+		problem(c, http.StatusBadRequest, codeInvalidBody, errInvalidBodyPref+err.Error())
 		return
 	}
 	ctx := c.Request.Context()
@@ -129,24 +170,35 @@ func (h *Handler) setMode(c *gin.Context) {
 		TargetTempC: req.TargetTempC,
 		DurationSec: req.DurationSec,
 	}
+	if req.Atmosphere != nil {
+		p := req.Atmosphere.params()
+		params.Atmosphere = &p
+	}
 	if err := h.services.Furnace.SetMode(ctx, params); err != nil {
 		// Treat as bad request if validation failed in service; otherwise internal error.
 		// (You can refine this by returning typed errors from service.)
 		if h.log != nil {
-			h.log.Errorw("furnace_set_mode_failed", "err", err, "mode", req.Mode)
+			h.requestLog(c).Errorw("furnace_set_mode_failed", "err", err, "mode", req.Mode)
 		}
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problemFor(c, http.StatusBadRequest, err)
 		return
 	}
 	h.respondWithStatusAndState(c, statusModeSet, gin.H{"mode": req.Mode})
 }
 
 // @Summary      Get furnace state
+// @Description  Carries an ETag and Last-Modified; pollers sending the ETag back in If-None-Match get 304 without a body until the state changes.
+// @Description  Accept: application/xml (or text/xml) renders the state as XML, text/csv as a header row and one row; field names are the canonical JSON ones, lists are joined with ";" in CSV.
 // @Tags         furnace
 // @Produce      json
-// @Success      200  {object}  map[string]interface{}
-// @Failure      401  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Produce      xml
+// @Produce      text/csv
+// @Param        If-None-Match      header    string  false  "ETag of the state the client holds"
+// @Param        If-Modified-Since  header    string  false  "HTTP date; ignored when If-None-Match is sent"
+// @Success      200                {object}  map[string]interface{}
+// @Success      304                "State unchanged"
+// @Failure      401                {object}  Problem
+// @Failure      500                {object}  Problem
 // @Router       /api/v1/furnace/state [get]
 // @Security     BearerAuth
 func (h *Handler) getState(c *gin.Context) {
@@ -156,5 +208,68 @@ func (h *Handler) getState(c *gin.Context) {
 		h.logAndJSONError(c, http.StatusInternalServerError, errGetState, "furnace_get_state_failed", err)
 		return
 	}
+	if h.notModified(c, st) {
+		return
+	}
+	if format := renderFormat(c); format != gin.MIMEJSON {
+		renderRecords(c, format, "state", "", []models.FurnaceState{st})
+		return
+	}
 	c.JSON(http.StatusOK, st)
 }
+
+// @Summary      Pre-heat readiness
+// @Description  Checks a prospective HEAT command against safety limits, furnace state and active alarms without changing anything. Returns every blocker so UIs can explain why Start is disabled.
+// @Tags         furnace
+// @Produce      json
+// @Param        target_temp_c  query     number   false  "Target temperature in Celsius"  example(850)
+// @Param        duration_sec   query     integer  false  "Heating duration in seconds"    example(600)
+// @Success      200            {object}  service.Readiness
+// @Failure      400            {object}  Problem
+// @Failure      401            {object}  Problem
+// @Failure      500            {object}  Problem
+// @Router       /api/v1/furnace/readiness [get]
+// @Security     BearerAuth
+func (h *Handler) getReadiness(c *gin.Context) {
+	var (
+		target   float64
+		duration int
+		err      error
+	)
+	if qs := c.Query("target_temp_c"); qs != "" {
+		if target, err = strconv.ParseFloat(qs, 64); err != nil {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, "invalid 'target_temp_c'; must be a number")
+			return
+		}
+	}
+	if qs := c.Query("duration_sec"); qs != "" {
+		if duration, err = strconv.Atoi(qs); err != nil {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, "invalid 'duration_sec'; must be an integer")
+			return
+		}
+	}
+	r, err := h.services.Furnace.CheckReadiness(c.Request.Context(), target, duration)
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, errCheckReadiness, "furnace_readiness_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// @Summary      Heater health
+// @Description  Returns cumulative heating hours and heat cycles with the resulting loss of ramp rate, and whether MAINTENANCE_DUE has been raised, for maintenance planning.
+// @Tags         furnace
+// @Produce      json
+// @Success      200  {object}  models.FurnaceHealth
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/furnace/health [get]
+// @Security     BearerAuth
+func (h *Handler) getFurnaceHealth(c *gin.Context) {
+	health, err := h.services.Health.FurnaceHealth(c.Request.Context())
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, errGetHealth, "furnace_health_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, health)
+}
@@ -3,8 +3,10 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"controlling_furnace/internal/models"
@@ -123,3 +125,92 @@ func TestFurnaceHandlers_StartStopSetMode_GetState(t *testing.T) {
 		t.Fatalf("expected Stop to be called once, got %d", fu.stopCalled)
 	}
 }
+
+func TestFurnaceHandlers_Readiness(t *testing.T) {
+	fu := &mockFurnace{readiness: service.Readiness{
+		Blockers: []service.Blocker{{Code: service.BlockerNotRunning, Message: "furnace is stopped, start it first"}},
+	}}
+	s := &service.Service{Authorization: &mockAuth{parseID: 7}, Furnace: fu}
+	r := newTestRouter(s)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/furnace/readiness?target_temp_c=850&duration_sec=600", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("readiness status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if fu.lastReadiness.TargetTempC != 850 || fu.lastReadiness.DurationSec != 600 {
+		t.Fatalf("params not forwarded: %+v", fu.lastReadiness)
+	}
+	var got service.Readiness
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.Ready || len(got.Blockers) != 1 || got.Blockers[0].Code != service.BlockerNotRunning {
+		t.Fatalf("unexpected readiness: %+v", got)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/furnace/readiness?target_temp_c=hot", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for non-numeric target, got %d", w.Code)
+	}
+}
+
+func TestFurnaceHandlers_Health(t *testing.T) {
+	hm := &mockHealth{health: models.FurnaceHealth{HeatingHours: 2001, Cycles: 40, MaintenanceDue: true, RampUpCPerSec: 2.4}}
+	s := &service.Service{Authorization: &mockAuth{parseID: 7}, Health: hm}
+	r := newTestRouter(s)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/furnace/health", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("health status=%d, body=%s", w.Code, w.Body.String())
+	}
+	var got models.FurnaceHealth
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got != hm.health {
+		t.Fatalf("got %+v, want %+v", got, hm.health)
+	}
+
+	hm.err = errors.New("db down")
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/furnace/health", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
+
+func TestProbes(t *testing.T) {
+	probes := &mockProbes{report: service.ReadinessReport{Ready: true, Components: map[string]service.ComponentStatus{
+		service.ComponentDatabase: {OK: true},
+	}}}
+	r := newTestRouter(&service.Service{Probes: probes})
+
+	for _, path := range []string{"/healthz", "/health", "/readyz"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status=%d, body=%s", path, w.Code, w.Body.String())
+		}
+	}
+
+	probes.report = service.ReadinessReport{Components: map[string]service.ComponentStatus{
+		service.ComponentDatabase:  {OK: true},
+		service.ComponentSimulator: {Error: "last tick 42s ago, limit 10s"},
+	}}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "last tick 42s ago") {
+		t.Fatalf("expected 503 with the failing component, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// API v2 addresses the furnace as a resource, /api/v2/furnaces/{id}, and
+// changes it with PATCH instead of one POST per command. An instance runs
+// one furnace, named after its site; the v1 /furnace routes keep working
+// against the same services.
+
+// FurnaceResource is a furnace of API v2 with its current state.
+type FurnaceResource struct {
+	// Furnace ID: the site the instance serves
+	ID    string              `json:"id" example:"default"`
+	State models.FurnaceState `json:"state"`
+}
+
+// FurnaceList is the answer of GET /api/v2/furnaces.
+type FurnaceList struct {
+	Furnaces []FurnaceResource `json:"furnaces"`
+}
+
+// PatchFurnaceRequest changes a furnace; omitted fields stay as they are.
+type PatchFurnaceRequest struct {
+	// Start (true) or stop (false) the furnace; stopping also switches to STANDBY
+	Running *bool `json:"running,omitempty" example:"true"`
+	// Mode to set. Allowed: HEAT, COOL, STANDBY
+	Mode string `json:"mode,omitempty" example:"HEAT"`
+	// Target temperature in Celsius (with mode HEAT)
+	TargetTempC float64 `json:"target_temp_c,omitempty" example:"850"`
+	// Heating duration in seconds (with mode HEAT)
+	DurationSec int `json:"duration_sec,omitempty" example:"600"`
+	// Protective gas to switch to
+	Atmosphere *AtmosphereRequest `json:"atmosphere,omitempty"`
+}
+
+// validate returns what is wrong with the patch, or "".
+func (r PatchFurnaceRequest) validate() string {
+	switch {
+	case r.Running == nil && r.Mode == "" && r.TargetTempC == 0 && r.DurationSec == 0 && r.Atmosphere == nil:
+		return "nothing to change: set running, mode, target_temp_c, duration_sec or atmosphere"
+	case r.Running != nil && !*r.Running && (r.Mode != "" || r.Atmosphere != nil):
+		return "a furnace being stopped takes no mode or atmosphere"
+	case r.Mode == "" && (r.TargetTempC != 0 || r.DurationSec != 0):
+		return "target_temp_c and duration_sec go with mode HEAT"
+	}
+	return ""
+}
+
+// knownFurnace answers 404 for any furnace but this instance's before fn
+// runs.
+func (h *Handler) knownFurnace(fn gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Param("id") != h.furnaceID {
+			problem(c, http.StatusNotFound, codeFurnaceNotFound, "furnace not found")
+			return
+		}
+		fn(c)
+	}
+}
+
+// furnace loads the resource of this instance's furnace.
+func (h *Handler) furnace(c *gin.Context) (FurnaceResource, bool) {
+	st, err := h.services.Monitoring.GetState(c.Request.Context())
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, errGetState, "furnace_get_state_failed", err)
+		return FurnaceResource{}, false
+	}
+	return FurnaceResource{ID: h.furnaceID, State: st}, true
+}
+
+// @Summary      List furnaces
+// @Description  Lists the furnaces this instance controls: one, named after its site.
+// @Tags         furnace
+// @Produce      json
+// @Success      200  {object}  FurnaceList
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v2/furnaces [get]
+// @Security     BearerAuth
+func (h *Handler) listFurnaces(c *gin.Context) {
+	f, ok := h.furnace(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, FurnaceList{Furnaces: []FurnaceResource{f}})
+}
+
+// @Summary      Get furnace
+// @Tags         furnace
+// @Produce      json
+// @Param        id   path      string  true  "Furnace ID"
+// @Success      200  {object}  FurnaceResource
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v2/furnaces/{id} [get]
+// @Security     BearerAuth
+func (h *Handler) getFurnace(c *gin.Context) {
+	f, ok := h.furnace(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, f)
+}
+
+// @Summary      Change furnace
+// @Description  Starts or stops the furnace, sets its mode and target, and switches the protective gas, in that order; omitted fields stay as they are. Asking for the running state the furnace is already in changes nothing. HEAT requires target_temp_c and duration_sec. A step that fails leaves the ones before it done. Answers with the furnace as it is afterwards.
+// @Tags         furnace
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string               true  "Furnace ID"
+// @Param        body  body      PatchFurnaceRequest  true  "Changes"
+// @Success      200   {object}  FurnaceResource
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      404   {object}  Problem
+// @Failure      500   {object}  Problem
+// @Router       /api/v2/furnaces/{id} [patch]
+// @Security     BearerAuth
+func (h *Handler) patchFurnace(c *gin.Context) {
+	var req PatchFurnaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, errInvalidBodyPref+err.Error())
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		problem(c, http.StatusBadRequest, codeInvalidBody, msg)
+		return
+	}
+	f, ok := h.furnace(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	if req.Running != nil && *req.Running != f.State.IsRunning {
+		if *req.Running {
+			if err := h.services.Furnace.Start(ctx); err != nil {
+				h.logAndJSONError(c, http.StatusInternalServerError, errStartFurnace, "furnace_start_failed", err)
+				return
+			}
+		} else if err := h.services.Furnace.Stop(ctx); err != nil {
+			h.logAndJSONError(c, http.StatusInternalServerError, errStopFurnace, "furnace_stop_failed", err)
+			return
+		}
+	}
+	switch {
+	case req.Mode != "":
+		params := service.ModeParams{Mode: req.Mode, TargetTempC: req.TargetTempC, DurationSec: req.DurationSec}
+		if req.Atmosphere != nil {
+			p := req.Atmosphere.params()
+			params.Atmosphere = &p
+		}
+		// as POST /api/v1/furnace/mode: the service rejects bad modes
+		// and a stopped furnace alike
+		if err := h.services.Furnace.SetMode(ctx, params); err != nil {
+			if h.log != nil {
+				h.requestLog(c).Errorw("furnace_set_mode_failed", "err", err, "mode", req.Mode)
+			}
+			problemFor(c, http.StatusBadRequest, err)
+			return
+		}
+	case req.Atmosphere != nil:
+		err := h.services.Furnace.SetAtmosphere(ctx, req.Atmosphere.params())
+		if errors.Is(err, service.ErrInvalidAtmosphere) {
+			problemFor(c, http.StatusBadRequest, err)
+			return
+		}
+		if err != nil {
+			h.logAndJSONError(c, http.StatusInternalServerError, "failed to set atmosphere", "furnace_set_atmosphere_failed", err)
+			return
+		}
+	}
+	h.getFurnace(c)
+}
+
+// @Summary      Furnace readiness
+// @Description  GET /api/v1/furnace/readiness for a furnace.
+// @Tags         furnace
+// @Produce      json
+// @Param        id             path      string   true   "Furnace ID"
+// @Param        target_temp_c  query     number   false  "Target temperature in Celsius"  example(850)
+// @Param        duration_sec   query     integer  false  "Heating duration in seconds"    example(600)
+// @Success      200            {object}  service.Readiness
+// @Failure      400            {object}  Problem
+// @Failure      401            {object}  Problem
+// @Failure      404            {object}  Problem
+// @Failure      500            {object}  Problem
+// @Router       /api/v2/furnaces/{id}/readiness [get]
+// @Security     BearerAuth
+func (h *Handler) getReadinessV2(c *gin.Context) { h.getReadiness(c) }
+
+// @Summary      Furnace heater health
+// @Description  GET /api/v1/furnace/health for a furnace.
+// @Tags         furnace
+// @Produce      json
+// @Param        id   path      string  true  "Furnace ID"
+// @Success      200  {object}  models.FurnaceHealth
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v2/furnaces/{id}/health [get]
+// @Security     BearerAuth
+func (h *Handler) getFurnaceHealthV2(c *gin.Context) { h.getFurnaceHealth(c) }
+
+// @Summary      Furnace temperature history
+// @Description  GET /api/v1/furnace/history for a furnace.
+// @Tags         furnace
+// @Produce      json
+// @Param        id          path      string  true   "Furnace ID"
+// @Param        from        query     string  false  "Start of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')"
+// @Param        to          query     string  false  "End of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). Date-only treated as end of day."
+// @Param        resolution  query     string  false  "Bucket width in whole seconds, as a number or a duration such as 5m"
+// @Success      200         {object}  models.TemperatureHistory
+// @Failure      400         {object}  Problem
+// @Failure      401         {object}  Problem
+// @Failure      404         {object}  Problem
+// @Failure      500         {object}  Problem
+// @Router       /api/v2/furnaces/{id}/history [get]
+// @Security     BearerAuth
+func (h *Handler) getHistoryV2(c *gin.Context) { h.getHistory(c) }
+
+// @Summary      Furnace charge
+// @Description  GET /api/v1/furnace/charge for a furnace.
+// @Tags         furnace
+// @Produce      json
+// @Param        id   path      string  true  "Furnace ID"
+// @Success      200  {object}  service.ChargeStatus
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Router       /api/v2/furnaces/{id}/charge [get]
+// @Security     BearerAuth
+func (h *Handler) getChargeV2(c *gin.Context) { h.getCharge(c) }
+
+// @Summary      Insert furnace charge
+// @Description  POST /api/v1/furnace/charge for a furnace.
+// @Tags         furnace
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string               true  "Furnace ID"
+// @Param        body  body      InsertChargeRequest  true  "Charge"
+// @Success      202   {object}  service.ChargeStatus
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      404   {object}  Problem
+// @Failure      409   {object}  Problem  "A charge is already in the furnace"
+// @Router       /api/v2/furnaces/{id}/charge [post]
+// @Security     BearerAuth
+func (h *Handler) insertChargeV2(c *gin.Context) { h.insertCharge(c) }
+
+// @Summary      Remove furnace charge
+// @Description  DELETE /api/v1/furnace/charge for a furnace.
+// @Tags         furnace
+// @Produce      json
+// @Param        id   path      string  true  "Furnace ID"
+// @Success      200  {object}  service.ChargeStatus
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Failure      404  {object}  Problem  "No such furnace, or no charge in it"
+// @Router       /api/v2/furnaces/{id}/charge [delete]
+// @Security     BearerAuth
+func (h *Handler) removeChargeV2(c *gin.Context) { h.removeCharge(c) }
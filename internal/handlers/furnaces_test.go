@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestFurnacesV2_Read(t *testing.T) {
+	auth := &mockAuth{parseID: 1, parseRole: models.RoleViewer}
+	mon := &mockMonitoring{state: models.FurnaceState{ID: 1, Mode: "HEAT", IsRunning: true}}
+	r := NewHandlerWithConfig(&service.Service{Authorization: auth, Monitoring: mon, Furnace: &mockFurnace{}}, nil, Config{FurnaceID: "plant-a"}).InitRoutes()
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/api/v2/furnaces")
+	var list FurnaceList
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || w.Code != http.StatusOK || len(list.Furnaces) != 1 || list.Furnaces[0].ID != "plant-a" {
+		t.Fatalf("list: %d %s", w.Code, w.Body.String())
+	}
+	w = get("/api/v2/furnaces/plant-a")
+	var f FurnaceResource
+	if err := json.Unmarshal(w.Body.Bytes(), &f); err != nil || w.Code != http.StatusOK || f.ID != "plant-a" || f.State.Mode != "HEAT" || !f.State.IsRunning {
+		t.Fatalf("get: %d %s", w.Code, w.Body.String())
+	}
+	for _, path := range []string{"/api/v2/furnaces/plant-b", "/api/v2/furnaces/plant-b/readiness", "/api/v2/furnaces/plant-b/charge"} {
+		w := get(path)
+		if p := decodeProblem(t, w); w.Code != http.StatusNotFound || p.Code != codeFurnaceNotFound {
+			t.Fatalf("%s: %d %+v", path, w.Code, p)
+		}
+	}
+	if w := get("/api/v2/furnaces/plant-a/readiness?target_temp_c=850&duration_sec=600"); w.Code != http.StatusOK {
+		t.Fatalf("readiness: %d %s", w.Code, w.Body.String())
+	}
+	// v1 and the routes v2 shares with it stay served
+	if w := get("/api/v1/furnace/state"); w.Code != http.StatusOK {
+		t.Fatalf("v1 state: %d", w.Code)
+	}
+	if w := get("/api/v2/furnace/state"); w.Code != http.StatusNotFound {
+		t.Fatalf("v1 furnace route under v2: %d", w.Code)
+	}
+}
+
+func TestFurnacesV2_Patch(t *testing.T) {
+	auth := &mockAuth{parseID: 1, parseRole: models.RoleOperator}
+	furnace := &mockFurnace{}
+	mon := &mockMonitoring{state: models.FurnaceState{ID: 1, Mode: "STANDBY"}}
+	r := newTestRouter(&service.Service{Authorization: auth, Monitoring: mon, Furnace: furnace})
+	patch := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPatch, "/api/v2/furnaces/default", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer valid")
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, body := range []string{`{}`, `{"running":false,"mode":"HEAT"}`, `{"target_temp_c":850}`, `{"running":"yes"}`} {
+		if w := patch(body); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+	if furnace.startCalled+furnace.stopCalled+furnace.setModeCalls != 0 {
+		t.Fatalf("invalid patches reached the service: %+v", furnace)
+	}
+
+	w := patch(`{"running":true,"mode":"HEAT","target_temp_c":850,"duration_sec":600,"atmosphere":{"gas":"N2","flow_m3h":20}}`)
+	if w.Code != http.StatusOK || furnace.startCalled != 1 || furnace.setModeCalls != 1 {
+		t.Fatalf("start and heat: %d %s, %+v", w.Code, w.Body.String(), furnace)
+	}
+	if p := furnace.lastSetMode; p.Mode != "HEAT" || p.TargetTempC != 850 || p.DurationSec != 600 || p.Atmosphere == nil || p.Atmosphere.Gas != "N2" {
+		t.Fatalf("mode params %+v", p)
+	}
+	if furnace.lastAtmosphere != nil {
+		t.Fatal("atmosphere set apart from the mode")
+	}
+
+	// asking for the running state it is in changes nothing
+	mon.state.IsRunning = true
+	if w := patch(`{"running":true}`); w.Code != http.StatusOK || furnace.startCalled != 1 {
+		t.Fatalf("running again: %d, %d starts", w.Code, furnace.startCalled)
+	}
+	if w := patch(`{"atmosphere":{"gas":"AR","flow_m3h":10}}`); w.Code != http.StatusOK || furnace.lastAtmosphere == nil || furnace.lastAtmosphere.Gas != "AR" {
+		t.Fatalf("atmosphere: %d %+v", w.Code, furnace.lastAtmosphere)
+	}
+	if w := patch(`{"running":false}`); w.Code != http.StatusOK || furnace.stopCalled != 1 {
+		t.Fatalf("stop: %d, %d stops", w.Code, furnace.stopCalled)
+	}
+
+	auth.parseRole = models.RoleViewer
+	if w := patch(`{"running":false}`); w.Code != http.StatusForbidden {
+		t.Fatalf("viewer: expected 403, got %d", w.Code)
+	}
+}
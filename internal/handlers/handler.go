@@ -1,10 +1,18 @@
 package handlers
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
 	"controlling_furnace/internal/logger"
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
 	"controlling_furnace/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -14,37 +22,171 @@ import (
 type Handler struct {
 	services *service.Service
 	log      *logger.Logger
+	compat   CompatConfig
+	perms    map[string]Permission
+	debug    DebugConfig
+	status   StatusConfig
+	trace    string // service name on request spans; empty disables tracing
+	demo     bool
+	away     goAway
+	streams  streamCount // open WebSocket streams, see DrainStreams
+	probe    readiness   // cached for the streams, see degraded
+	limits   rateLimits
+	proxies  []string
+	access   AccessLogConfig
+	// admin moves the probes and profiling to InitAdminRoutes
+	admin bool
+	// furnaceID names the furnace in API v2
+	furnaceID string
+	// routes are the registered routes, for the API index
+	routes gin.RoutesInfo
+
+	wsMu sync.RWMutex
+	ws   WSConfig
+}
+
+// Config holds HTTP-layer options.
+type Config struct {
+	Compat      CompatConfig
+	WS          WSConfig
+	Permissions Permissions // overrides of the default route permissions
+	Debug       DebugConfig
+	Status      StatusConfig
+	// TraceService names this server on OpenTelemetry request spans;
+	// empty leaves requests untraced.
+	TraceService string
+	// Demo serves state, logs and the state stream without a token and
+	// refuses every change, whatever the token (see demo.go).
+	Demo bool
+	// RateLimit limits requests per user or client IP; zero rates leave
+	// routes unlimited.
+	RateLimit RateLimitConfig
+	// AccessLog writes a line per request (method, path, status, latency,
+	// user, request ID, bytes).
+	AccessLog AccessLogConfig
+	// FurnaceID names the furnace at /api/v2/furnaces/{id}: the site the
+	// instance serves, repository.DefaultSite when empty.
+	FurnaceID string
+	// AdminListener serves the probes, /metrics and /debug/pprof on the
+	// separate router of InitAdminRoutes instead of the API's.
+	AdminListener bool
 }
 
 // NewHandler constructs a new HTTP handler with dependencies.
 func NewHandler(services *service.Service, log *logger.Logger) *Handler {
-	return &Handler{services: services, log: log}
+	return NewHandlerWithConfig(services, log, Config{})
+}
+
+// NewHandlerWithConfig is NewHandler with explicit HTTP-layer options.
+func NewHandlerWithConfig(services *service.Service, log *logger.Logger, cfg Config) *Handler {
+	perms := cfg.Permissions.table()
+	if cfg.FurnaceID == "" {
+		cfg.FurnaceID = repository.DefaultSite
+	}
+	if cfg.Demo {
+		demoPermissions(perms)
+	}
+	return &Handler{
+		services: services,
+		log:      log,
+		compat:   cfg.Compat,
+		perms:    perms,
+		debug:    cfg.Debug,
+		status:   cfg.Status,
+		trace:    cfg.TraceService,
+		demo:     cfg.Demo,
+		ws:       cfg.WS.withDefaults(),
+		limits:   newRateLimits(cfg.RateLimit),
+		proxies:  cfg.RateLimit.TrustedProxies,
+		access:   cfg.AccessLog,
+		admin:    cfg.AdminListener,
+
+		furnaceID: cfg.FurnaceID,
+		away:      goAway{ch: make(chan struct{})},
+	}
 }
 
 // InitRoutes builds and returns the Gin router with all routes registered.
 func (h *Handler) InitRoutes() *gin.Engine {
 	router := gin.New()
-	router.Use(gin.Recovery())
+	// a known path asked with another method answers 405 and its Allow
+	// list rather than 404, and OPTIONS with the list alone
+	router.HandleMethodNotAllowed = true
+	router.NoMethod(methodNotAllowed)
+	// validated with the config: the peer is the client unless it is one
+	_ = router.SetTrustedProxies(h.proxies)
+	router.Use(requestIDMiddleware, h.recovery)
+	if h.log != nil && h.access.Enabled {
+		router.Use(h.accessLog(h.access))
+	}
+	if h.trace != "" {
+		router.Use(otelgin.Middleware(h.trace, otelgin.WithFilter(traced)))
+	}
+	if h.demo {
+		router.Use(demoReadOnly)
+	}
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Health endpoint
-	router.GET("/health", h.health)
+	// Liveness and readiness probes, unless on the admin listener
+	if !h.admin {
+		h.registerProbeRoutes(router)
+	}
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		router.Handle(method, "/version", h.getVersion)
+	}
+
+	// Status page and badge, when public
+	h.registerStatusRoutes(router)
 
 	// Auth endpoints
 	h.registerAuthRoutes(router)
 
-	// Versioned API endpoints (protected)
+	// Versioned API endpoints, guarded per route (see permissions.go)
 	h.registerAPIRoutes(router)
 
+	// Profiling, when enabled and not on the admin listener
+	if !h.admin {
+		h.registerDebugRoutes(router, h.userIdMiddleware, h.requireRole(models.RoleAdmin))
+	}
+
 	// Minimal WebSocket connection (HTTP upgrade) — same port
 	router.GET("/ws", h.wsConnect)
 
+	h.routes = router.Routes()
 	return router
 }
 
+// methodNotAllowed answers a request whose path is routed for other
+// methods only; gin has set the Allow header to those methods by then.
+// OPTIONS is answered for every such path, so clients can discover what
+// a route accepts.
+func methodNotAllowed(c *gin.Context) {
+	allow := c.Writer.Header().Get("Allow") + ", " + http.MethodOptions
+	c.Header("Allow", allow)
+	if c.Request.Method == http.MethodOptions {
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+	abortProblem(c, http.StatusMethodNotAllowed, codeMethodNotAllowed,
+		fmt.Sprintf("%s is not supported here; allowed: %s", c.Request.Method, allow))
+}
+
+// traced leaves probes, the status badge, the Swagger UI and WebSocket
+// sessions out of the request traces: they are frequent or long-lived and
+// would drown out the API calls worth tracing.
+func traced(r *http.Request) bool {
+	switch p := r.URL.Path; {
+	case p == "/healthz", p == "/health", p == "/readyz", p == "/ws":
+		return false
+	case strings.HasPrefix(p, "/swagger/"), strings.HasPrefix(p, "/status/"):
+		return false
+	}
+	return true
+}
+
 func (h *Handler) registerAuthRoutes(r *gin.Engine) {
-	auth := r.Group("/auth")
+	auth := r.Group("/auth", h.limits.auth.middleware()...)
 	{
 		auth.POST("/sign-up", h.signUp)
 		auth.POST("/sign-in", h.signIn)
@@ -52,27 +194,201 @@ func (h *Handler) registerAuthRoutes(r *gin.Engine) {
 }
 
 func (h *Handler) registerAPIRoutes(r *gin.Engine) {
-	api := r.Group("/api/v1", h.userIdMiddleware)
-	{
-		h.registerFurnaceRoutes(api)
-		h.registerLogRoutes(api)
+	h.registerAPIVersion(r, "v1", "")
+	// Legacy versions serve the same routes with renamed JSON fields.
+	for version, profile := range h.compat.Versions {
+		h.registerAPIVersion(r, version, profile)
 	}
+	// v2 addresses the furnace as a resource; the other routes are v1's.
+	v2 := r.Group("/api/v2", h.compatMiddleware(""))
+	h.registerIndexRoute(v2, "v2")
+	h.registerFurnaceResourceRoutes(v2)
+	h.registerSharedRoutes(v2)
+}
+
+func (h *Handler) registerAPIVersion(r *gin.Engine, version, compatProfile string) {
+	api := r.Group("/api/"+version, h.compatMiddleware(compatProfile))
+	h.registerIndexRoute(api, version)
+	h.registerFurnaceRoutes(api)
+	h.registerSharedRoutes(api)
+}
+
+// registerSharedRoutes registers the routes API v1 and v2 have in common.
+func (h *Handler) registerSharedRoutes(api *gin.RouterGroup) {
+	h.registerLogRoutes(api)
+	h.registerRunRoutes(api)
+	h.registerTelemetryRoutes(api)
+	h.registerAlertRoutes(api)
+	h.registerIncidentRoutes(api)
+	h.registerMaintenanceRoutes(api)
+	h.registerWebhookRoutes(api)
+	h.registerSimRoutes(api)
+	h.registerAdminRoutes(api)
+	h.registerSystemRoutes(api)
+	h.registerSetupRoutes(api)
 }
 
 func (h *Handler) registerFurnaceRoutes(api *gin.RouterGroup) {
 	furnace := api.Group("/furnace")
 	{
-		furnace.POST("/start", h.startFurnace)
-		furnace.POST("/stop", h.stopFurnace)
+		h.handle(furnace, http.MethodPost, "/start", h.startFurnace)
+		h.handle(furnace, http.MethodPost, "/stop", h.stopFurnace)
 		// Body example: {"mode":"HEAT","target_c":850,"duration_s":600}
-		furnace.POST("/mode", h.setMode)
-		furnace.GET("/state", h.getState)
+		h.handle(furnace, http.MethodPost, "/mode", h.setMode)
+		h.handle(furnace, http.MethodPut, "/atmosphere", h.setAtmosphere)
+		h.handle(furnace, http.MethodGet, "/state", h.getState)
+		h.handle(furnace, http.MethodGet, "/state.prom", h.getStateOpenMetrics)
+		h.handle(furnace, http.MethodGet, "/readiness", h.getReadiness)
+		h.handle(furnace, http.MethodGet, "/health", h.getFurnaceHealth)
+		h.handle(furnace, http.MethodGet, "/history", h.getHistory)
+		// Body example: {"mass_kg":800,"specific_heat_j_per_kg_k":490}
+		h.handle(furnace, http.MethodGet, "/charge", h.getCharge)
+		h.handle(furnace, http.MethodPost, "/charge", h.insertCharge)
+		h.handle(furnace, http.MethodDelete, "/charge", h.removeCharge)
+		// Body example: {"steps":[{"mode":"HEAT","target_temp_c":850,"duration_sec":600},{"mode":"COOL","target_temp_c":200}]}
+		h.handle(furnace, http.MethodPost, "/sequence", h.startSequence)
+		h.handle(furnace, http.MethodGet, "/sequence/:id", h.getSequence)
+	}
+}
+
+func (h *Handler) registerFurnaceResourceRoutes(api *gin.RouterGroup) {
+	furnaces := api.Group("/furnaces")
+	{
+		h.handle(furnaces, http.MethodGet, "", h.listFurnaces)
+		h.handle(furnaces, http.MethodGet, "/:id", h.knownFurnace(h.getFurnace))
+		// Body example: {"running":true,"mode":"HEAT","target_temp_c":850,"duration_sec":600}
+		h.handle(furnaces, http.MethodPatch, "/:id", h.knownFurnace(h.patchFurnace))
+		h.handle(furnaces, http.MethodGet, "/:id/readiness", h.knownFurnace(h.getReadinessV2))
+		h.handle(furnaces, http.MethodGet, "/:id/health", h.knownFurnace(h.getFurnaceHealthV2))
+		h.handle(furnaces, http.MethodGet, "/:id/history", h.knownFurnace(h.getHistoryV2))
+		h.handle(furnaces, http.MethodGet, "/:id/charge", h.knownFurnace(h.getChargeV2))
+		h.handle(furnaces, http.MethodPost, "/:id/charge", h.knownFurnace(h.insertChargeV2))
+		h.handle(furnaces, http.MethodDelete, "/:id/charge", h.knownFurnace(h.removeChargeV2))
 	}
 }
 
 func (h *Handler) registerLogRoutes(api *gin.RouterGroup) {
 	logs := api.Group("/logs")
 	{
-		logs.GET("/", h.getLogs)
+		h.handle(logs, http.MethodGet, "/", h.getLogs)
+		h.handle(logs, http.MethodGet, "/verify", h.verifyLogs)
+		h.handle(logs, http.MethodGet, "/schema", h.getLogSchema)
+		h.handle(logs, http.MethodGet, "/tail", h.tailLogs)
+		h.handle(logs, http.MethodPost, "/purge", h.purgeLogs)
+		h.handle(logs, http.MethodPost, "/:event_id/comments", h.commentEvent)
+		h.handle(logs, http.MethodDelete, "/:event_id", h.deleteEvent)
+	}
+}
+
+func (h *Handler) registerRunRoutes(api *gin.RouterGroup) {
+	runs := api.Group("/runs")
+	{
+		h.handle(runs, http.MethodGet, "/:run_id", h.getRun)
+	}
+}
+
+func (h *Handler) registerTelemetryRoutes(api *gin.RouterGroup) {
+	h.handle(api, http.MethodGet, "/telemetry", h.getTelemetry)
+}
+
+func (h *Handler) registerAlertRoutes(api *gin.RouterGroup) {
+	alerts := api.Group("/alerts")
+	{
+		h.handle(alerts, http.MethodGet, "", h.listAlerts)
+		h.handle(alerts, http.MethodGet, "/rules", h.listAlertRules)
+		h.handle(alerts, http.MethodGet, "/rules/:id", h.getAlertRule)
+		// Body example: {"name":"Too hot","kind":"temp_above","threshold":950,"for_seconds":30}
+		h.handle(alerts, http.MethodPost, "/rules", h.createAlertRule)
+		h.handle(alerts, http.MethodPut, "/rules/:id", h.updateAlertRule)
+		h.handle(alerts, http.MethodDelete, "/rules/:id", h.deleteAlertRule)
+	}
+}
+
+func (h *Handler) registerMaintenanceRoutes(api *gin.RouterGroup) {
+	maintenance := api.Group("/maintenance")
+	{
+		h.handle(maintenance, http.MethodGet, "/tasks", h.listMaintenanceTasks)
+		h.handle(maintenance, http.MethodGet, "/tasks/:id", h.getMaintenanceTask)
+		// Body example: {"name":"Calibrate thermocouple","kind":"calibration","interval_days":90}
+		h.handle(maintenance, http.MethodPost, "/tasks", h.createMaintenanceTask)
+		h.handle(maintenance, http.MethodPut, "/tasks/:id", h.updateMaintenanceTask)
+		h.handle(maintenance, http.MethodDelete, "/tasks/:id", h.deleteMaintenanceTask)
+		h.handle(maintenance, http.MethodPost, "/tasks/:id/complete", h.completeMaintenanceTask)
+		h.handle(maintenance, http.MethodGet, "/records", h.listMaintenanceRecords)
+	}
+}
+
+func (h *Handler) registerWebhookRoutes(api *gin.RouterGroup) {
+	webhooks := api.Group("/webhooks")
+	{
+		h.handle(webhooks, http.MethodGet, "", h.listWebhooks)
+		h.handle(webhooks, http.MethodGet, "/:id", h.getWebhook)
+		// Body example: {"url":"https://mes.example.com/hook","event_types":["STOP","ERROR"],"secret":"s3cret"}
+		h.handle(webhooks, http.MethodPost, "", h.createWebhook)
+		h.handle(webhooks, http.MethodPut, "/:id", h.updateWebhook)
+		h.handle(webhooks, http.MethodDelete, "/:id", h.deleteWebhook)
+		h.handle(webhooks, http.MethodGet, "/:id/deliveries", h.listWebhookDeliveries)
+		h.handle(webhooks, http.MethodPost, "/:id/deliveries/:delivery_id/replay", h.replayWebhookDelivery)
+	}
+}
+
+func (h *Handler) registerIncidentRoutes(api *gin.RouterGroup) {
+	incidents := api.Group("/incidents")
+	{
+		h.handle(incidents, http.MethodGet, "", h.listIncidents)
+		h.handle(incidents, http.MethodGet, "/:id", h.getIncident)
+		h.handle(incidents, http.MethodGet, "/:id/export", h.exportIncident)
+		h.handle(incidents, http.MethodPost, "/:id/ack", h.acknowledgeIncident)
+	}
+}
+
+func (h *Handler) registerSimRoutes(api *gin.RouterGroup) {
+	sim := api.Group("/sim")
+	{
+		// Body example: {"type":"HEATER_FAILURE"}
+		h.handle(sim, http.MethodPost, "/faults", h.injectFault)
+		h.handle(sim, http.MethodGet, "/faults", h.listFaults)
+		h.handle(sim, http.MethodDelete, "/faults", h.clearAllFaults)
+		h.handle(sim, http.MethodDelete, "/faults/:type", h.clearFault)
+		// Body example: {"time_scale":60}
+		h.handle(sim, http.MethodGet, "/speed", h.getSimSpeed)
+		h.handle(sim, http.MethodPut, "/speed", h.setSimSpeed)
+		h.handle(sim, http.MethodGet, "/config", h.getSimConfig)
+		h.handle(sim, http.MethodPut, "/config", h.updateSimConfig)
+		// Body example: {"temp_c":-10}
+		h.handle(sim, http.MethodGet, "/ambient", h.getSimAmbient)
+		h.handle(sim, http.MethodPut, "/ambient", h.setSimAmbient)
+		h.handle(sim, http.MethodDelete, "/ambient", h.clearSimAmbient)
+	}
+}
+
+func (h *Handler) registerAdminRoutes(api *gin.RouterGroup) {
+	admin := api.Group("/admin")
+	{
+		h.handle(admin, http.MethodGet, "/chaos", h.getChaos)
+		h.handle(admin, http.MethodPut, "/chaos", h.updateChaos)
+		h.handle(admin, http.MethodPost, "/import/:kind", h.importHistory)
+		h.handle(admin, http.MethodGet, "/loops", h.listLoops)
+		h.handle(admin, http.MethodPost, "/loops/:name/restart", h.restartLoop)
+		// Body: the full simulator settings, as for PUT /sim/config
+		h.handle(admin, http.MethodPost, "/config/preview", h.previewSimConfig)
+		h.handle(admin, http.MethodGet, "/audit/export", h.exportAudit)
+		h.handle(admin, http.MethodGet, "/audit/key", h.getAuditKey)
+		h.handle(admin, http.MethodPost, "/backup", h.backupDatabase)
+		// Body example: {"role":"operator"}
+		h.handle(admin, http.MethodPut, "/users/:id/role", h.setUserRole)
+	}
+}
+
+func (h *Handler) registerSetupRoutes(api *gin.RouterGroup) {
+	// Body example: {"username":"admin","password":"...","units":"C","max_safe_c":1000}
+	h.handle(api, http.MethodGet, "/setup", h.getSetup)
+	h.handle(api, http.MethodPost, "/setup", h.completeSetup)
+}
+
+func (h *Handler) registerSystemRoutes(api *gin.RouterGroup) {
+	system := api.Group("/system")
+	{
+		h.handle(system, http.MethodGet, "/info", h.getSystemInfo)
 	}
 }
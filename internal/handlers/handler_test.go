@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestRoutes_MethodNotAllowedListsAllow(t *testing.T) {
+	r := newTestRouter(&service.Service{Authorization: &mockAuth{parseID: 1}})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/v1/furnace/state", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, HEAD, OPTIONS" {
+		t.Fatalf("DELETE state: %d, Allow %q", w.Code, w.Header().Get("Allow"))
+	}
+	if p := decodeProblem(t, w); p.Code != codeMethodNotAllowed {
+		t.Fatalf("problem %+v", p)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/api/v1/furnace/sequence", nil))
+	if w.Code != http.StatusNoContent || w.Header().Get("Allow") != "POST, OPTIONS" || w.Body.Len() != 0 {
+		t.Fatalf("OPTIONS sequence: %d, Allow %q, %s", w.Code, w.Header().Get("Allow"), w.Body.String())
+	}
+
+	// unknown paths stay 404
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/furnace/nope", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unknown path: %d", w.Code)
+	}
+}
+
+func TestRoutes_HeadOnReadRoutes(t *testing.T) {
+	st := models.FurnaceState{Mode: "HEAT", UpdatedAt: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)}
+	r := newTestRouter(&service.Service{Authorization: &mockAuth{parseID: 1}, Monitoring: &mockMonitoring{state: st}})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodHead, "/api/v1/furnace/state", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == "" {
+		t.Fatalf("HEAD state: %d, ETag %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	// HEAD is guarded as GET is
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/api/v1/furnace/state", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous HEAD state: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("HEAD healthz: %d", w.Code)
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Temperature history
+// @Description  Returns the chamber temperature reduced to min/max/avg per resolution interval, oldest first. Intervals without samples are omitted. Defaults to the last hour at about 500 buckets. At a resolution of whole hours or days, the periods already summarised are read from hourly and daily rollups and count whole, so long ranges stay fast after the per-tick samples are pruned (history.raw_retention). runtime_s is the time spent heating.
+// @Tags         furnace
+// @Produce      json
+// @Param        from        query     string  false  "Start of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')"
+// @Param        to          query     string  false  "End of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). Date-only treated as end of day."
+// @Param        resolution  query     string  false  "Bucket width in whole seconds, as a number or a duration such as 5m"
+// @Success      200         {object}  models.TemperatureHistory
+// @Failure      400         {object}  Problem
+// @Failure      401         {object}  Problem
+// @Failure      500         {object}  Problem
+// @Router       /api/v1/furnace/history [get]
+// @Security     BearerAuth
+func (h *Handler) getHistory(c *gin.Context) {
+	var (
+		f   service.HistoryFilter
+		err error
+	)
+	if qs := c.Query("from"); qs != "" {
+		if f.From, err = parseQueryTime(qs); err != nil {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, errFromInvalid)
+			return
+		}
+	}
+	if qs := c.Query("to"); qs != "" {
+		if f.To, err = parseQueryTime(qs); err != nil {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, errToInvalid)
+			return
+		}
+		if isDateOnly(qs) {
+			f.To = f.To.Add(24*time.Hour - time.Nanosecond).UTC()
+		}
+	}
+	if qs := c.Query("resolution"); qs != "" {
+		if f.Resolution, err = parseResolution(qs); err != nil {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, "invalid 'resolution'; use seconds or a duration such as 5m")
+			return
+		}
+	}
+
+	hist, err := h.services.TempHistory.History(c.Request.Context(), f)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidHistoryQuery) {
+			problemFor(c, http.StatusBadRequest, err)
+			return
+		}
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to load temperature history", "history_failed", err)
+		return
+	}
+	if hist.Buckets == nil {
+		hist.Buckets = []models.HistoryBucket{}
+	}
+	c.JSON(http.StatusOK, hist)
+}
+
+// parseResolution accepts a positive number of seconds or a Go duration.
+func parseResolution(s string) (time.Duration, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n <= 0 {
+			return 0, errors.New("resolution must be positive")
+		}
+		return time.Duration(n) * time.Second, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, errors.New("resolution must be positive")
+	}
+	return d, nil
+}
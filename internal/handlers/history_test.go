@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestGetHistory(t *testing.T) {
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	hist := &mockHistory{hist: models.TemperatureHistory{From: at, To: at.Add(time.Hour), ResolutionS: 300}}
+	s := &service.Service{Authorization: &mockAuth{parseID: 1}, TempHistory: hist}
+	r := newTestRouter(s)
+
+	get := func(q string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/furnace/history?"+q, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("from=2025-09-20T10:00:00Z&resolution=5m")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	var out models.TemperatureHistory
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out.ResolutionS != 300 || out.Buckets == nil {
+		t.Fatalf("unexpected response: %s", w.Body.String())
+	}
+	if !hist.lastFilter.From.Equal(at) || hist.lastFilter.Resolution != 5*time.Minute {
+		t.Fatalf("unexpected filter: %+v", hist.lastFilter)
+	}
+	if get("resolution=30"); hist.lastFilter.Resolution != 30*time.Second {
+		t.Fatalf("expected bare numbers to be seconds, got %s", hist.lastFilter.Resolution)
+	}
+
+	for _, q := range []string{"resolution=0", "resolution=fast", "from=yesterday"} {
+		if w := get(q); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", q, w.Code)
+		}
+	}
+
+	hist.err = fmt.Errorf("%w: too many buckets", service.ErrInvalidHistoryQuery)
+	if w := get("resolution=1"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a rejected query, got %d", w.Code)
+	}
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// problemLanguages are the languages problem details are offered in.
+// English, the first, is the language of the service's own messages and
+// the fallback.
+var problemLanguages = []language.Tag{language.English, language.Russian, language.Uzbek}
+
+var problemLanguageMatcher = language.NewMatcher(problemLanguages)
+
+// problemLanguage picks the language of the request's Accept-Language
+// among problemLanguages: "en", "ru" or "uz". Regional variants such as
+// ru-RU or uz-Latn-UZ match their language.
+func problemLanguage(c *gin.Context) string {
+	tags, _, err := language.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	if err != nil || len(tags) == 0 {
+		return "en"
+	}
+	_, i, conf := problemLanguageMatcher.Match(tags...)
+	if conf == language.No {
+		return "en"
+	}
+	base, _ := problemLanguages[i].Base()
+	return base.String()
+}
+
+// localize puts the detail of p in the request's language when code has
+// a translation there, keeping the English detail for support. Programs
+// keep branching on the code, which is never translated.
+func localize(c *gin.Context, p *Problem) {
+	lang := problemLanguage(c)
+	msg, ok := problemMessages[lang][p.Code]
+	if !ok {
+		return
+	}
+	c.Header("Content-Language", lang)
+	p.DetailEN = p.Detail
+	p.Detail, p.Error = msg, msg
+}
+
+// problemMessages are the translated details, by language and code. A
+// code missing here keeps its English detail.
+var problemMessages = map[string]map[string]string{
+	"ru": {
+		codeInvalidRequest:          "Некорректный запрос.",
+		codeInvalidBody:             "Некорректное тело запроса.",
+		codeInvalidQuery:            "Некорректные параметры запроса.",
+		codeInvalidID:               "Некорректный идентификатор.",
+		codeUnauthorized:            "Требуется вход в систему.",
+		codeMissingToken:            "Не передан токен доступа.",
+		codeInvalidToken:            "Токен доступа недействителен или истёк.",
+		codeInvalidCredentials:      "Неверное имя пользователя или пароль.",
+		codeForbidden:               "Доступ запрещён.",
+		codeInsufficientPermissions: "Недостаточно прав для этого действия.",
+		codeReadOnlyDemo:            "Демо-режим: изменения отключены.",
+		codeNotFound:                "Не найдено.",
+		codeMethodNotAllowed:        "Этот метод здесь не поддерживается.",
+		codeFurnaceNotFound:         "Печь не найдена.",
+		codeSiteNotFound:            "Этот сервер не обслуживает указанную площадку.",
+		codeConflict:                "Запрос противоречит текущему состоянию.",
+		codeTooLarge:                "Слишком большой объём данных.",
+		codeRateLimited:             "Слишком много запросов, повторите позже.",
+		codeInternal:                "Внутренняя ошибка сервера.",
+		codeNotImplemented:          "Не поддерживается.",
+		codeUnavailable:             "Сервис временно недоступен.",
+		codeDatabaseBusy:            "База данных занята, повторите попытку.",
+		codeFeatureDisabled:         "Эта функция отключена.",
+		codeUnknownProfile:          "Неизвестный профиль совместимости.",
+		codeInvalidSchemaVersion:    "Неподдерживаемая версия схемы.",
+
+		"invalid_alert_rule":         "Некорректное правило оповещения.",
+		"alert_rule_not_found":       "Правило оповещения не найдено.",
+		"invalid_atmosphere":         "Некорректные параметры атмосферы.",
+		"invalid_audit_export":       "Некорректный запрос выгрузки аудита.",
+		"audit_key_not_configured":   "Не задан audit.signing_key: пакеты аудита без ключа не выгружаются.",
+		"setup_required":             "Сначала выполните первоначальную настройку.",
+		"invalid_username":           "Недопустимое имя пользователя.",
+		"username_taken":             "Это имя пользователя уже занято.",
+		"invalid_role":               "Недопустимая роль.",
+		"user_not_found":             "Пользователь не найден.",
+		"last_admin":                 "Нельзя лишить роли администратора последнего администратора площадки.",
+		"backup_not_configured":      "Резервное копирование не настроено.",
+		"backup_unsupported":         "Это хранилище не поддерживает резервное копирование.",
+		"invalid_chaos_settings":     "Некорректные настройки режима сбоев.",
+		"invalid_charge":             "Некорректные параметры загрузки.",
+		"charge_loaded":              "Печь уже загружена.",
+		"no_charge":                  "Печь не загружена.",
+		"invalid_sequence":           "Некорректная последовательность шагов.",
+		"sequence_running":           "Последовательность уже выполняется.",
+		"sequence_not_found":         "Последовательность не найдена.",
+		"invalid_comment":            "Некорректный комментарий.",
+		"invalid_deletion":           "Некорректный запрос на удаление.",
+		"event_deleted":              "Событие удалено.",
+		"event_not_found":            "Событие не найдено.",
+		"invalid_cursor":             "Некорректный курсор страницы.",
+		"invalid_meta_filter":        "Некорректный фильтр метаданных.",
+		"invalid_severity":           "Недопустимый уровень важности.",
+		"tail_event_not_found":       "Событие, с которого нужно продолжить, не найдено в журнале.",
+		"invalid_purge":              "Некорректный запрос очистки журнала.",
+		"unknown_fault":              "Неизвестный тип неисправности.",
+		"invalid_history_query":      "Некорректный запрос истории.",
+		"invalid_import":             "Некорректные данные импорта.",
+		"unknown_mapping":            "Неизвестное сопоставление импорта.",
+		"incident_not_found":         "Инцидент не найден.",
+		"incident_acknowledged":      "Инцидент уже подтверждён.",
+		"invalid_maintenance_task":   "Некорректная задача обслуживания.",
+		"maintenance_task_not_found": "Задача обслуживания не найдена.",
+		"invalid_ambient":            "Некорректная температура окружающей среды.",
+		"run_not_found":              "Цикл нагрева не найден.",
+		"setup_done":                 "Первоначальная настройка уже выполнена.",
+		"invalid_setup":              "Некорректные данные настройки.",
+		"invalid_sim_settings":       "Некорректные настройки симулятора.",
+		"invalid_speed":              "Некорректная скорость симуляции.",
+		"unknown_loop":               "Неизвестный фоновый процесс.",
+		"unknown_channel":            "Неизвестный канал.",
+		"invalid_webhook":            "Некорректный вебхук.",
+		"webhook_not_found":          "Вебхук не найден.",
+		"webhook_delivery_not_found": "Доставка вебхука не найдена.",
+		"delivery_not_replayable":    "Повторить можно только неудачную доставку.",
+		"invalid_resume":             "Невозможно возобновить поток.",
+	},
+	"uz": {
+		codeInvalidRequest:          "So'rov noto'g'ri.",
+		codeInvalidBody:             "So'rov tanasi noto'g'ri.",
+		codeInvalidQuery:            "So'rov parametrlari noto'g'ri.",
+		codeInvalidID:               "Identifikator noto'g'ri.",
+		codeUnauthorized:            "Tizimga kirish talab qilinadi.",
+		codeMissingToken:            "Kirish tokeni yuborilmagan.",
+		codeInvalidToken:            "Kirish tokeni yaroqsiz yoki muddati o'tgan.",
+		codeInvalidCredentials:      "Foydalanuvchi nomi yoki parol noto'g'ri.",
+		codeForbidden:               "Kirish taqiqlangan.",
+		codeInsufficientPermissions: "Bu amal uchun huquqlaringiz yetarli emas.",
+		codeReadOnlyDemo:            "Demo rejimi: o'zgartirishlar o'chirilgan.",
+		codeNotFound:                "Topilmadi.",
+		codeMethodNotAllowed:        "Bu usul bu yerda qo'llab-quvvatlanmaydi.",
+		codeFurnaceNotFound:         "Pech topilmadi.",
+		codeSiteNotFound:            "Bu server ko'rsatilgan obyektga xizmat ko'rsatmaydi.",
+		codeConflict:                "So'rov joriy holatga zid.",
+		codeTooLarge:                "Ma'lumotlar hajmi juda katta.",
+		codeRateLimited:             "So'rovlar juda ko'p, keyinroq qayta urinib ko'ring.",
+		codeInternal:                "Serverning ichki xatosi.",
+		codeNotImplemented:          "Qo'llab-quvvatlanmaydi.",
+		codeUnavailable:             "Xizmat vaqtincha ishlamayapti.",
+		codeDatabaseBusy:            "Ma'lumotlar bazasi band, qayta urinib ko'ring.",
+		codeFeatureDisabled:         "Bu funksiya o'chirilgan.",
+		codeUnknownProfile:          "Moslik profili noma'lum.",
+		codeInvalidSchemaVersion:    "Sxema versiyasi qo'llab-quvvatlanmaydi.",
+
+		"invalid_alert_rule":         "Ogohlantirish qoidasi noto'g'ri.",
+		"alert_rule_not_found":       "Ogohlantirish qoidasi topilmadi.",
+		"invalid_atmosphere":         "Atmosfera parametrlari noto'g'ri.",
+		"invalid_audit_export":       "Audit eksporti so'rovi noto'g'ri.",
+		"audit_key_not_configured":   "audit.signing_key sozlanmagan: audit paketlari kalitsiz eksport qilinmaydi.",
+		"setup_required":             "Avval dastlabki sozlashni bajaring.",
+		"invalid_username":           "Foydalanuvchi nomi yaroqsiz.",
+		"username_taken":             "Bu foydalanuvchi nomi band.",
+		"invalid_role":               "Rol noto'g'ri.",
+		"user_not_found":             "Foydalanuvchi topilmadi.",
+		"last_admin":                 "Obyektning oxirgi administratorini administrator rolidan mahrum qilib bo'lmaydi.",
+		"backup_not_configured":      "Zaxira nusxalash sozlanmagan.",
+		"backup_unsupported":         "Bu ombor zaxira nusxalashni qo'llab-quvvatlamaydi.",
+		"invalid_chaos_settings":     "Nosozlik rejimi sozlamalari noto'g'ri.",
+		"invalid_charge":             "Yuk parametrlari noto'g'ri.",
+		"charge_loaded":              "Pech allaqachon yuklangan.",
+		"no_charge":                  "Pech yuklanmagan.",
+		"invalid_sequence":           "Qadamlar ketma-ketligi noto'g'ri.",
+		"sequence_running":           "Ketma-ketlik allaqachon bajarilmoqda.",
+		"sequence_not_found":         "Ketma-ketlik topilmadi.",
+		"invalid_comment":            "Izoh noto'g'ri.",
+		"invalid_deletion":           "O'chirish so'rovi noto'g'ri.",
+		"event_deleted":              "Hodisa o'chirilgan.",
+		"event_not_found":            "Hodisa topilmadi.",
+		"invalid_cursor":             "Sahifa kursori noto'g'ri.",
+		"invalid_meta_filter":        "Metama'lumotlar filtri noto'g'ri.",
+		"invalid_severity":           "Muhimlik darajasi noto'g'ri.",
+		"tail_event_not_found":       "Davom ettiriladigan hodisa jurnalda topilmadi.",
+		"invalid_purge":              "Jurnalni tozalash so'rovi noto'g'ri.",
+		"unknown_fault":              "Nosozlik turi noma'lum.",
+		"invalid_history_query":      "Tarix so'rovi noto'g'ri.",
+		"invalid_import":             "Import ma'lumotlari noto'g'ri.",
+		"unknown_mapping":            "Import moslamasi noma'lum.",
+		"incident_not_found":         "Intsident topilmadi.",
+		"incident_acknowledged":      "Intsident allaqachon tasdiqlangan.",
+		"invalid_maintenance_task":   "Texnik xizmat vazifasi noto'g'ri.",
+		"maintenance_task_not_found": "Texnik xizmat vazifasi topilmadi.",
+		"invalid_ambient":            "Atrof-muhit harorati noto'g'ri.",
+		"run_not_found":              "Qizdirish sikli topilmadi.",
+		"setup_done":                 "Dastlabki sozlash allaqachon bajarilgan.",
+		"invalid_setup":              "Sozlash ma'lumotlari noto'g'ri.",
+		"invalid_sim_settings":       "Simulyator sozlamalari noto'g'ri.",
+		"invalid_speed":              "Simulyatsiya tezligi noto'g'ri.",
+		"unknown_loop":               "Fon jarayoni noma'lum.",
+		"unknown_channel":            "Kanal noma'lum.",
+		"invalid_webhook":            "Vebxuk noto'g'ri.",
+		"webhook_not_found":          "Vebxuk topilmadi.",
+		"webhook_delivery_not_found": "Vebxuk yetkazmasi topilmadi.",
+		"delivery_not_replayable":    "Faqat muvaffaqiyatsiz yetkazmani qayta yuborish mumkin.",
+		"invalid_resume":             "Oqimni davom ettirib bo'lmaydi.",
+	},
+}
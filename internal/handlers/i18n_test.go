@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"controlling_furnace/internal/service"
+)
+
+func TestProblemMessages_CoverEveryCode(t *testing.T) {
+	codes := []string{codeInvalidBody, codeInvalidQuery, codeInvalidID, codeMissingToken, codeInvalidToken,
+		codeInvalidCredentials, codeInsufficientPermissions, codeReadOnlyDemo, codeFurnaceNotFound, codeSiteNotFound,
+		codeDatabaseBusy, codeFeatureDisabled, codeUnknownProfile, codeInvalidSchemaVersion}
+	for _, code := range statusCodes {
+		codes = append(codes, code)
+	}
+	for _, pc := range problemCodes {
+		codes = append(codes, pc.code)
+	}
+	for lang, msgs := range problemMessages {
+		for _, code := range codes {
+			if msgs[code] == "" {
+				t.Errorf("%s: no message for %s", lang, code)
+			}
+		}
+		if len(msgs) != len(problemMessages["ru"]) {
+			t.Errorf("%s has %d messages, ru %d", lang, len(msgs), len(problemMessages["ru"]))
+		}
+	}
+}
+
+func TestProblem_TranslatedAfterAcceptLanguage(t *testing.T) {
+	r := newTestRouter(&service.Service{Authorization: &mockAuth{parseID: 1}, Sequences: &mockSequences{}})
+	get := func(accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/furnace/sequence/nope", nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		if accept != "" {
+			req.Header.Set("Accept-Language", accept)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for accept, want := range map[string]string{
+		"ru-RU,ru;q=0.9,en;q=0.8": "Последовательность не найдена.",
+		"uz-Latn-UZ":              "Ketma-ketlik topilmadi.",
+		"de, uz;q=0.5":            "Ketma-ketlik topilmadi.",
+	} {
+		w := get(accept)
+		p := decodeProblem(t, w)
+		if p.Code != "sequence_not_found" || p.Detail != want || p.DetailEN != service.ErrSequenceNotFound.Error() {
+			t.Fatalf("%s: %+v", accept, p)
+		}
+		if w.Header().Get("Content-Language") == "" {
+			t.Fatalf("%s: no Content-Language", accept)
+		}
+	}
+
+	for _, accept := range []string{"", "en-GB", "de", "not a header"} {
+		w := get(accept)
+		if p := decodeProblem(t, w); p.Detail != service.ErrSequenceNotFound.Error() || p.DetailEN != "" || w.Header().Get("Content-Language") != "" {
+			t.Fatalf("%q: %+v", accept, p)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportBytes bounds an uploaded CSV; larger histories go through the
+// import CLI subcommand or are split into several files.
+const maxImportBytes = 32 << 20
+
+// Record kinds accepted by the import endpoint.
+const (
+	importKindTelemetry = "telemetry"
+	importKindEvents    = "events"
+)
+
+// @Summary      Import history from CSV
+// @Description  Loads telemetry or events exported by a legacy controller. The request body is the CSV file, with a header row, read with the named mapping from the import.mappings config. Without a mapping, this system's own columns are expected (telemetry: at, channel, value; events: event_id, occurred_at, type, description). Invalid rows are skipped and reported; records already stored are counted as duplicates. Admin only.
+// @Tags         admin
+// @Accept       text/csv
+// @Produce      json
+// @Param        kind     path      string  true   "Record kind"  Enums(telemetry, events)
+// @Param        mapping  query     string  false  "Mapping name from config"
+// @Success      200      {object}  service.ImportReport
+// @Failure      400      {object}  Problem
+// @Failure      401      {object}  Problem
+// @Failure      403      {object}  Problem
+// @Failure      404      {object}  Problem
+// @Failure      413      {object}  Problem
+// @Failure      500      {object}  Problem
+// @Router       /api/v1/admin/import/{kind} [post]
+// @Security     BearerAuth
+func (h *Handler) importHistory(c *gin.Context) {
+	kind, mapping := c.Param("kind"), c.Query("mapping")
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	ctx := c.Request.Context()
+
+	var (
+		rep service.ImportReport
+		err error
+	)
+	switch kind {
+	case importKindTelemetry:
+		rep, err = h.services.Importer.ImportTelemetry(ctx, mapping, body)
+	case importKindEvents:
+		rep, err = h.services.Importer.ImportEvents(ctx, mapping, body)
+	default:
+		problem(c, http.StatusNotFound, codeNotFound, "unknown import kind; use telemetry or events")
+		return
+	}
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		problem(c, http.StatusRequestEntityTooLarge, codeTooLarge, "CSV exceeds 32 MB; use the import command for larger files")
+		return
+	case errors.Is(err, service.ErrInvalidImport), errors.Is(err, service.ErrUnknownMapping):
+		problemFor(c, http.StatusBadRequest, err)
+		return
+	case err != nil:
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to import history", "import_failed", err)
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Infow("history_imported", "kind", kind, "mapping", mapping, "rows", rep.Rows,
+			"imported", rep.Imported, "duplicates", rep.Duplicates, "invalid", rep.Invalid, "userId", c.GetInt(ctxKeyUserID))
+	}
+	c.JSON(http.StatusOK, rep)
+}
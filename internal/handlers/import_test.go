@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestImportHistory_PassesCSVAndReturnsReport(t *testing.T) {
+	imp := &mockImporter{report: service.ImportReport{Rows: 2, Imported: 1, Duplicates: 1}}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleAdmin},
+		Importer:      imp,
+	}
+	r := newTestRouter(s)
+
+	csv := "at,channel,value\n2023-05-01T08:00:00Z,chamber,640\n"
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/import/telemetry?mapping=legacy", bytes.NewBufferString(csv))
+	req.Header.Set("Content-Type", "text/csv")
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if imp.lastKind != "telemetry" || imp.lastMapping != "legacy" || imp.lastBody != csv {
+		t.Fatalf("importer got kind=%q mapping=%q body=%q", imp.lastKind, imp.lastMapping, imp.lastBody)
+	}
+	var rep service.ImportReport
+	if err := json.Unmarshal(w.Body.Bytes(), &rep); err != nil || rep.Imported != 1 || rep.Duplicates != 1 {
+		t.Fatalf("unexpected report %s (%v)", w.Body.String(), err)
+	}
+}
+
+func TestImportHistory_Errors(t *testing.T) {
+	imp := &mockImporter{}
+	cases := []struct {
+		name, role, path string
+		err              error
+		body             string
+		want             int
+	}{
+		{"operator forbidden", models.RoleOperator, "/api/v1/admin/import/events", nil, "x", http.StatusForbidden},
+		{"unknown kind", models.RoleAdmin, "/api/v1/admin/import/runs", nil, "x", http.StatusNotFound},
+		{"bad csv", models.RoleAdmin, "/api/v1/admin/import/events", fmt.Errorf("%w: missing column", service.ErrInvalidImport), "x", http.StatusBadRequest},
+		{"unknown mapping", models.RoleAdmin, "/api/v1/admin/import/events?mapping=x", service.ErrUnknownMapping, "x", http.StatusBadRequest},
+		{"too large", models.RoleAdmin, "/api/v1/admin/import/events", nil, strings.Repeat("x", maxImportBytes+1), http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			imp.err = tc.err
+			s := &service.Service{
+				Authorization: &mockAuth{parseID: 1, parseRole: tc.role},
+				Importer:      imp,
+			}
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "text/csv")
+			req.Header.Set("Authorization", "Bearer valid")
+			newTestRouter(s).ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Fatalf("status=%d, want %d, body=%s", w.Code, tc.want, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// incidentID parses the :id path parameter, answering 400 if it is not a
+// positive integer.
+func incidentID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		problem(c, http.StatusBadRequest, codeInvalidID, "invalid incident id")
+		return 0, false
+	}
+	return id, true
+}
+
+// incidentError answers for errors returned by the incident service.
+func (h *Handler) incidentError(c *gin.Context, err error, msg, logKey string) {
+	switch {
+	case errors.Is(err, service.ErrIncidentNotFound):
+		problemFor(c, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrIncidentAcknowledged):
+		problemFor(c, http.StatusConflict, err)
+	default:
+		h.logAndJSONError(c, http.StatusInternalServerError, msg, logKey, err)
+	}
+}
+
+// @Summary      List incidents
+// @Description  Returns alarm episodes, newest first, without their events and telemetry. An incident opens when the first error code is raised and closes when none remain; open incidents have no ended_at. overheat_s is the time spent above the safe limit; resolution tells whether the alarms cleared with the furnace running (cleared) or off (stopped).
+// @Tags         incidents
+// @Produce      json
+// @Param        from   query     string   false  "Start of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')"
+// @Param        to     query     string   false  "End of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). Date-only treated as end of day."
+// @Param        alarm  query     string   false  "Only incidents that raised this alarm code, e.g. OVERHEAT"
+// @Param        limit  query     integer  false  "Maximum incidents (default 50, max 500)"
+// @Success      200    {object}  map[string]interface{}  "count, incidents"
+// @Failure      400    {object}  Problem
+// @Failure      401    {object}  Problem
+// @Failure      500    {object}  Problem
+// @Router       /api/v1/incidents [get]
+// @Security     BearerAuth
+func (h *Handler) listIncidents(c *gin.Context) {
+	var (
+		f   service.IncidentFilter
+		err error
+	)
+	if qs := c.Query("from"); qs != "" {
+		if f.From, err = parseQueryTime(qs); err != nil {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, errFromInvalid)
+			return
+		}
+	}
+	if qs := c.Query("to"); qs != "" {
+		if f.To, err = parseQueryTime(qs); err != nil {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, errToInvalid)
+			return
+		}
+		if isDateOnly(qs) {
+			f.To = f.To.Add(24*time.Hour - time.Nanosecond).UTC()
+		}
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.From.After(f.To) {
+		problem(c, http.StatusBadRequest, codeInvalidQuery, "'from' must be <= 'to'")
+		return
+	}
+	if qs := c.Query("limit"); qs != "" {
+		if f.Limit, err = strconv.Atoi(qs); err != nil || f.Limit < 0 {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, "invalid 'limit'; must be a non-negative integer")
+			return
+		}
+	}
+	f.Alarm = c.Query("alarm")
+
+	incidents, err := h.services.Incidents.ListIncidents(c.Request.Context(), f)
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to list incidents", "incidents_list_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"count":     len(incidents),
+		"incidents": incidents,
+	})
+}
+
+// @Summary      Get incident
+// @Description  Returns the incident with the events logged during the episode and a temperature excerpt from a minute before to a minute after. Both are compiled when the incident closes.
+// @Tags         incidents
+// @Produce      json
+// @Param        id   path      int  true  "Incident ID"
+// @Success      200  {object}  models.Incident
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/incidents/{id} [get]
+// @Security     BearerAuth
+func (h *Handler) getIncident(c *gin.Context) {
+	id, ok := incidentID(c)
+	if !ok {
+		return
+	}
+	inc, err := h.services.Incidents.GetIncident(c.Request.Context(), id)
+	if err != nil {
+		h.incidentError(c, err, "failed to load incident", "incident_get_failed")
+		return
+	}
+	c.JSON(http.StatusOK, inc)
+}
+
+// @Summary      Export incident
+// @Description  Downloads the incident report as Markdown for post-mortems, or as JSON.
+// @Tags         incidents
+// @Produce      text/markdown
+// @Produce      json
+// @Param        id      path      int     true   "Incident ID"
+// @Param        format  query     string  false  "Report format (default markdown)"  Enums(markdown,json)
+// @Success      200     {string}  string
+// @Failure      400     {object}  Problem
+// @Failure      401     {object}  Problem
+// @Failure      404     {object}  Problem
+// @Failure      500     {object}  Problem
+// @Router       /api/v1/incidents/{id}/export [get]
+// @Security     BearerAuth
+func (h *Handler) exportIncident(c *gin.Context) {
+	id, ok := incidentID(c)
+	if !ok {
+		return
+	}
+	format := strings.ToLower(c.DefaultQuery("format", "markdown"))
+	if format != "markdown" && format != "json" {
+		problem(c, http.StatusBadRequest, codeInvalidQuery, "invalid 'format'; use markdown or json")
+		return
+	}
+	inc, err := h.services.Incidents.GetIncident(c.Request.Context(), id)
+	if err != nil {
+		h.incidentError(c, err, "failed to load incident", "incident_export_failed")
+		return
+	}
+	name := fmt.Sprintf("incident-%d", inc.ID)
+	if format == "json" {
+		c.Header("Content-Disposition", `attachment; filename="`+name+`.json"`)
+		c.IndentedJSON(http.StatusOK, inc)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+name+`.md"`)
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(incidentMarkdown(inc)))
+}
+
+// @Summary      Acknowledge incident
+// @Description  Records the caller as the acknowledging operator. Open incidents can be acknowledged; only the first acknowledgement counts.
+// @Tags         incidents
+// @Produce      json
+// @Param        id   path      int  true  "Incident ID"
+// @Success      200  {object}  models.Incident
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      409  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/incidents/{id}/ack [post]
+// @Security     BearerAuth
+func (h *Handler) acknowledgeIncident(c *gin.Context) {
+	id, ok := incidentID(c)
+	if !ok {
+		return
+	}
+	inc, err := h.services.Incidents.AcknowledgeIncident(c.Request.Context(), id, c.GetInt(ctxKeyUserID))
+	if err != nil {
+		h.incidentError(c, err, "failed to acknowledge incident", "incident_ack_failed")
+		return
+	}
+	c.JSON(http.StatusOK, inc)
+}
+
+// incidentMarkdown renders inc as a post-mortem starting point.
+func incidentMarkdown(inc models.Incident) string {
+	const stamp = "2006-01-02 15:04:05Z"
+	cell := func(s string) string { return strings.ReplaceAll(s, "|", `\|`) }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Incident %d\n\n", inc.ID)
+	b.WriteString("| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Started | %s |\n", inc.StartedAt.Format(stamp))
+	if inc.EndedAt != nil {
+		fmt.Fprintf(&b, "| Ended | %s |\n", inc.EndedAt.Format(stamp))
+	} else {
+		b.WriteString("| Ended | ongoing |\n")
+	}
+	fmt.Fprintf(&b, "| Duration | %s |\n", time.Duration(inc.DurationS*float64(time.Second)).Round(time.Second))
+	if inc.RunID != "" {
+		fmt.Fprintf(&b, "| Run | %s |\n", inc.RunID)
+	}
+	fmt.Fprintf(&b, "| Alarms | %s |\n", strings.Join(inc.AlarmCodes, ", "))
+	fmt.Fprintf(&b, "| Peak temperature | %.1f °C (sensor %.1f °C) |\n", inc.PeakTempC, inc.PeakMeasuredC)
+	if inc.OverheatS > 0 {
+		fmt.Fprintf(&b, "| Above safe limit | %s |\n", time.Duration(inc.OverheatS*float64(time.Second)).Round(time.Second))
+	}
+	if inc.Resolution != "" {
+		fmt.Fprintf(&b, "| Resolution | %s |\n", inc.Resolution)
+	}
+	switch {
+	case inc.AckedAt == nil:
+		b.WriteString("| Acknowledged | no |\n")
+	case inc.AckedByName != "":
+		fmt.Fprintf(&b, "| Acknowledged | %s at %s |\n", cell(inc.AckedByName), inc.AckedAt.Format(stamp))
+	default:
+		fmt.Fprintf(&b, "| Acknowledged | user %d at %s |\n", inc.AckedBy, inc.AckedAt.Format(stamp))
+	}
+
+	if len(inc.Events) > 0 {
+		b.WriteString("\n## Events\n\n| Time | Type | Description |\n|---|---|---|\n")
+		for _, ev := range inc.Events {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", ev.OccurredAt.UTC().Format(stamp), ev.Type, cell(ev.Description))
+		}
+	}
+	if len(inc.Telemetry) > 0 {
+		b.WriteString("\n## Temperature\n\n| From | Min °C | Avg °C | Max °C | Target °C |\n|---|---|---|---|---|\n")
+		for _, t := range inc.Telemetry {
+			fmt.Fprintf(&b, "| %s | %.1f | %.1f | %.1f | %.0f |\n", t.Start.UTC().Format(stamp), t.MinC, t.AvgC, t.MaxC, t.TargetC)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestIncidents(t *testing.T) {
+	start := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Second)
+	incidents := &mockIncidents{incident: models.Incident{
+		ID: 7, StartedAt: start, EndedAt: &end, DurationS: 90, RunID: "run-1",
+		AlarmCodes: []string{"OVERHEAT"}, PeakTempC: 1030, PeakMeasuredC: 1032, OverheatS: 75, Resolution: models.IncidentStopped,
+		Events: []models.FurnaceEvent{{OccurredAt: start, Type: "ERROR", Description: "Overheat | cut power"}},
+	}}
+	auth := &mockAuth{parseID: 3, parseRole: models.RoleOperator}
+	r := newTestRouter(&service.Service{Authorization: auth, Incidents: incidents})
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodGet, "/api/v1/incidents?from=2025-09-20&limit=5"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"count":1`) {
+		t.Fatalf("list: status=%d body=%s", w.Code, w.Body.String())
+	}
+	if !incidents.lastFilter.From.Equal(start.Truncate(24*time.Hour)) || incidents.lastFilter.Limit != 5 {
+		t.Fatalf("unexpected filter: %+v", incidents.lastFilter)
+	}
+	if w := do(http.MethodGet, "/api/v1/incidents?alarm=OVERHEAT"); w.Code != http.StatusOK || incidents.lastFilter.Alarm != "OVERHEAT" {
+		t.Fatalf("alarm filter: status=%d filter=%+v", w.Code, incidents.lastFilter)
+	}
+	if w := do(http.MethodGet, "/api/v1/incidents?limit=x"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad limit, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v1/incidents/7"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"peak_temp_c":1030`) {
+		t.Fatalf("get: status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	w := do(http.MethodGet, "/api/v1/incidents/7/export")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/markdown") {
+		t.Fatalf("export: status=%d type=%s", w.Code, w.Header().Get("Content-Type"))
+	}
+	md := w.Body.String()
+	for _, want := range []string{"# Incident 7", "| Duration | 1m30s |", "| Alarms | OVERHEAT |", `Overheat \| cut power`, "| Above safe limit | 1m15s |", "| Resolution | stopped |", "| Acknowledged | no |"} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("report lacks %q:\n%s", want, md)
+		}
+	}
+	if w := do(http.MethodGet, "/api/v1/incidents/7/export?format=json"); w.Code != http.StatusOK ||
+		!strings.Contains(w.Header().Get("Content-Disposition"), "incident-7.json") {
+		t.Fatalf("json export: status=%d disposition=%s", w.Code, w.Header().Get("Content-Disposition"))
+	}
+	if w := do(http.MethodGet, "/api/v1/incidents/7/export?format=pdf"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown format, got %d", w.Code)
+	}
+
+	if w := do(http.MethodPost, "/api/v1/incidents/7/ack"); w.Code != http.StatusOK || incidents.lastUserID != 3 {
+		t.Fatalf("ack: status=%d user=%d", w.Code, incidents.lastUserID)
+	}
+	incidents.err = service.ErrIncidentAcknowledged
+	if w := do(http.MethodPost, "/api/v1/incidents/7/ack"); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a second ack, got %d", w.Code)
+	}
+	incidents.err = service.ErrIncidentNotFound
+	if w := do(http.MethodGet, "/api/v1/incidents/8"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v1/incidents/x"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad id, got %d", w.Code)
+	}
+	auth.parseRole = models.RoleViewer
+	if w := do(http.MethodPost, "/api/v1/incidents/7/ack"); w.Code != http.StatusForbidden {
+		t.Fatalf("expected viewers to be refused, got %d", w.Code)
+	}
+}
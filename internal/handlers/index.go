@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"controlling_furnace/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIIndex lists the endpoints of an API version, for clients and fleet
+// tooling to discover what an instance serves without the Swagger UI.
+type APIIndex struct {
+	Version       string     `json:"version" example:"v1"`
+	SchemaVersion int        `json:"schema_version" example:"9"`
+	Docs          string     `json:"docs" example:"/swagger/index.html"`
+	Endpoints     []Endpoint `json:"endpoints"`
+}
+
+// Endpoint is a route of the index, with the permission it requires as
+// configured on this instance. GET routes also answer HEAD.
+type Endpoint struct {
+	Method     string     `json:"method" example:"GET"`
+	Path       string     `json:"path" example:"/api/v1/furnace/sequence/{id}"`
+	Permission Permission `json:"permission" swaggertype:"string" example:"read"`
+}
+
+// registerIndexRoute serves the index of the API version api is the root
+// of. It is public, as the Swagger UI is, and outside the route table.
+func (h *Handler) registerIndexRoute(api *gin.RouterGroup, version string) {
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		api.Handle(method, "", func(c *gin.Context) { h.getAPIIndex(c, version) })
+	}
+}
+
+// @Summary      API index
+// @Description  Lists the endpoints of the API version with the permission each requires, in path order. GET endpoints also answer HEAD. Public.
+// @Tags         system
+// @Produce      json
+// @Success      200  {object}  APIIndex
+// @ID           getAPIIndex
+// @Router       /api/v1 [get]
+func (h *Handler) getAPIIndex(c *gin.Context, version string) {
+	base := c.FullPath()
+	idx := APIIndex{Version: version, SchemaVersion: models.SchemaVersion, Docs: "/swagger/index.html", Endpoints: []Endpoint{}}
+	for _, rt := range h.routes {
+		rel, ok := strings.CutPrefix(rt.Path, base+"/")
+		if !ok || rt.Method == http.MethodHead {
+			continue
+		}
+		idx.Endpoints = append(idx.Endpoints, Endpoint{
+			Method:     rt.Method,
+			Path:       openAPIPath(rt.Path),
+			Permission: h.perms[rt.Method+" /"+rel],
+		})
+	}
+	sort.SliceStable(idx.Endpoints, func(i, j int) bool {
+		a, b := idx.Endpoints[i], idx.Endpoints[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+	c.JSON(http.StatusOK, idx)
+}
+
+// openAPIPath writes the parameters of a gin route path as the Swagger
+// document does: /logs/:event_id becomes /logs/{event_id}.
+func openAPIPath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			parts[i] = "{" + p[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// @Summary      Build version
+// @Description  Reports the build version and commit, injected at build time, and the state and event schema versions served, so fleet tooling can check what each controller runs. Public.
+// @Tags         system
+// @Produce      json
+// @Success      200  {object}  service.BuildInfo
+// @Router       /version [get]
+func (h *Handler) getVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.System.Build())
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"controlling_furnace/internal/service"
+)
+
+func TestAPIIndex_ListsRoutesWithPermissions(t *testing.T) {
+	r := newTestRouter(&service.Service{})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1", nil))
+	var idx APIIndex
+	if err := json.Unmarshal(w.Body.Bytes(), &idx); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("index: %d %s (%v)", w.Code, w.Body.String(), err)
+	}
+	perm := map[string]Permission{}
+	for _, ep := range idx.Endpoints {
+		if ep.Method == http.MethodHead {
+			t.Fatalf("HEAD listed: %+v", ep)
+		}
+		perm[ep.Method+" "+ep.Path] = ep.Permission
+	}
+	if idx.Version != "v1" || len(idx.Endpoints) == 0 ||
+		perm["GET /api/v1/furnace/state"] != PermRead ||
+		perm["POST /api/v1/furnace/sequence"] != PermOperate ||
+		perm["GET /api/v1/furnace/sequence/{id}"] != PermRead ||
+		perm["GET /api/v1/logs/"] != PermRead {
+		t.Fatalf("index %+v", idx)
+	}
+	if _, ok := perm["GET /api/v1/furnaces"]; ok {
+		t.Fatal("v1 index lists a v2 route")
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v2", nil))
+	idx = APIIndex{}
+	if err := json.Unmarshal(w.Body.Bytes(), &idx); err != nil || idx.Version != "v2" {
+		t.Fatalf("v2 index: %s (%v)", w.Body.String(), err)
+	}
+	for _, ep := range idx.Endpoints {
+		if ep.Path == "/api/v2/furnaces/{id}" && ep.Method == http.MethodPatch && ep.Permission == PermOperate {
+			return
+		}
+	}
+	t.Fatalf("v2 index lacks PATCH /furnaces/{id}: %+v", idx.Endpoints)
+}
+
+func TestVersion_Public(t *testing.T) {
+	build := service.BuildInfo{Version: "v1.2.3", Commit: "3f2c9a1b7e4d", SchemaVersion: 9, MinSchemaVersion: 1}
+	r := newTestRouter(&service.Service{System: &mockSystem{build: build}})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+	var got service.BuildInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || w.Code != http.StatusOK || got != build {
+		t.Fatalf("version: %d %s (%v)", w.Code, w.Body.String(), err)
+	}
+}
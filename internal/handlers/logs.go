@@ -1,9 +1,16 @@
 package handlers
 
 import (
+	"context"
+	"controlling_furnace/internal/models"
 	"controlling_furnace/internal/service"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,6 +23,15 @@ const (
 
 	layoutDateTime = "2006-01-02 15:04:05"
 	layoutDate     = "2006-01-02"
+
+	contentTypeNDJSON = "application/x-ndjson"
+)
+
+// Streamed log responses are flushed every streamFlushEvery events; each
+// flush allows another streamWriteTimeout for the client to keep up.
+const (
+	streamFlushEvery   = 100
+	streamWriteTimeout = 30 * time.Second
 )
 
 // isDateOnly reports whether the query string represents a date without time component.
@@ -23,69 +39,379 @@ func isDateOnly(s string) bool {
 	return !strings.ContainsAny(s, "T ")
 }
 
+// LogQuery is the query of GET /logs. Adding a filter here documents it in
+// the Swagger spec and validates it alongside the others; toFilter passes
+// it on to the service. The meta.* filters, whose names are free, are read
+// by metaFilters.
+type LogQuery struct {
+	// Start of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')
+	From string `form:"from" example:"2025-08-01"`
+	// End of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). Date-only treated as end of day.
+	To string `form:"to" example:"2025-08-31"`
+	// Event type, or a comma-separated list of types to include
+	Type string `form:"type" example:"START,STOP"`
+	// Comma-separated event types to leave out
+	ExcludeType string `form:"exclude_type" example:"TELEMETRY"`
+	// Only event types the catalog rates at least this severe (see GET /logs/schema)
+	Severity string `form:"severity" binding:"omitempty,oneof=info warning critical" enums:"info,warning,critical"`
+	// Only events recorded during the given heat cycle
+	RunID string `form:"run_id"`
+	// Response format
+	Format string `form:"format" binding:"omitempty,oneof=json ndjson" enums:"json,ndjson"`
+	// Events per page
+	Limit *int `form:"limit" binding:"omitempty,min=1,max=1000" minimum:"1" maximum:"1000"`
+	// next_cursor of the previous page
+	Cursor *string `form:"cursor"`
+	// Sort by occurrence time
+	Order *string `form:"order" enums:"asc,desc"`
+	// Also list soft-deleted events, with deleted_at, deleted_by and delete_reason (admins only)
+	IncludeDeleted bool `form:"include_deleted"`
+}
+
+// toFilter parses the range and type lists of q into a service filter.
+func (q LogQuery) toFilter() (service.LogFilter, string) {
+	var (
+		f   service.LogFilter
+		err error
+	)
+	// Parse 'from' (optional)
+	if q.From != "" {
+		if f.From, err = parseQueryTime(q.From); err != nil {
+			return f, errFromInvalid
+		}
+	}
+	// Parse 'to' (optional). If only a date is provided, make it end-of-day inclusive.
+	if q.To != "" {
+		if f.To, err = parseQueryTime(q.To); err != nil {
+			return f, errToInvalid
+		}
+		// If the user didn't include a time component, treat "to" as the end of that day.
+		if isDateOnly(q.To) {
+			f.To = f.To.Add(24*time.Hour - time.Nanosecond).UTC()
+		}
+	}
+	// Validate range if both provided
+	if !f.From.IsZero() && !f.To.IsZero() && f.From.After(f.To) {
+		return f, "'from' must be <= 'to'"
+	}
+	// Normalize event types: trim spaces and uppercase to match expected values.
+	if f.Types = splitTypes(q.Type); len(f.Types) == 1 {
+		f.Type, f.Types = f.Types[0], nil
+	}
+	f.ExcludeTypes = splitTypes(q.ExcludeType)
+	f.Severity = q.Severity
+	f.RunID = strings.TrimSpace(q.RunID)
+	f.IncludeDeleted = q.IncludeDeleted
+	return f, ""
+}
+
+// page reads limit, cursor and order. paged reports whether any was
+// given; errMsg is set for an invalid order.
+func (q LogQuery) page() (paged bool, p service.LogPageParams, errMsg string) {
+	paged = q.Limit != nil || q.Cursor != nil || q.Order != nil
+	if q.Limit != nil {
+		p.Limit = *q.Limit
+	}
+	if q.Cursor != nil {
+		p.Cursor = *q.Cursor
+	}
+	if q.Order != nil {
+		switch strings.ToLower(strings.TrimSpace(*q.Order)) {
+		case "", "asc":
+		case "desc":
+			p.Desc = true
+		default:
+			return paged, p, "order must be asc or desc"
+		}
+	}
+	return paged, p, ""
+}
+
 // @Summary      List logs
 // @Description  Filter logs by date (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). If 'to' is date-only, it is treated as end-of-day inclusive (23:59:59.999999999Z).
+// @Description  With format=ndjson (or Accept: application/x-ndjson) events are streamed one JSON object per line as they are read, for ranges too large to buffer. A failure after streaming started ends the body with an {"error": ...} line.
+// @Description  meta.* parameters filter on the recorded metadata: meta.to=COOL keeps mode changes to COOL, meta.temp_c>1000 events logged above 1000 °C. Numbers and booleans compare as such; up to 8 filters may be combined.
+// @Description  Passing limit, cursor or order returns one page (default 100, max 1000 events) with a next_cursor to pass back for the following page; it is omitted on the last page. Without them every matching event is returned.
+// @Description  Accept: application/xml (or text/xml) renders the events as XML, text/csv as a header row and a row per event with the metadata as JSON; field names are the canonical JSON ones. A page's next cursor is then sent in the X-Next-Cursor header.
 // @Tags         logs
 // @Produce      json
-// @Param        from  query   string  false  "Start of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')"  example(2025-08-01)
-// @Param        to    query   string  false  "End of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). Date-only treated as end of day."  example(2025-08-31)
-// @Param        type  query   string  false  "Event type"  Enums(START,MODE_CHANGE,STOP,ERROR)
-// @Success      200   {object}  map[string]interface{}  "count, events"
-// @Failure      400   {object}  map[string]string
-// @Failure      401   {object}  map[string]string
-// @Failure      500   {object}  map[string]string
+// @Produce      application/x-ndjson
+// @Produce      xml
+// @Produce      text/csv
+// @Param        query       query  LogQuery  false  "Filters and paging"
+// @Param        meta.{key}  query  string    false  "Compare a metadata value, e.g. meta.to=COOL, meta.temp_c>1000 or meta.limits.max_c<=1200 (=, !=, <, <=, >, >=). Repeatable; all must match, and events without the key never do."
+// @Success      200   {object}  map[string]interface{}  "count, events, next_cursor"
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      500   {object}  Problem
 // @Router       /api/v1/logs [get]
 // @Security     BearerAuth
 func (h *Handler) getLogs(c *gin.Context) {
 	ctx := c.Request.Context()
+	var q LogQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidQuery, "invalid query: "+err.Error())
+		return
+	}
+	filter, errMsg := q.toFilter()
+	if errMsg != "" {
+		problem(c, http.StatusBadRequest, codeInvalidQuery, errMsg)
+		return
+	}
+	meta, err := metaFilters(c)
+	if err != nil {
+		problemFor(c, http.StatusBadRequest, err)
+		return
+	}
+	filter.Meta = meta
+	if filter.IncludeDeleted && c.GetString(ctxKeyRole) != models.RoleAdmin {
+		problem(c, http.StatusForbidden, codeInsufficientPermissions, "only admins may list deleted events")
+		return
+	}
+	paged, page, errMsg := q.page()
+	if errMsg != "" {
+		problem(c, http.StatusBadRequest, codeInvalidQuery, errMsg)
+		return
+	}
+	if q.Format == "ndjson" || (q.Format == "" && strings.Contains(c.GetHeader("Accept"), contentTypeNDJSON)) {
+		if paged {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, "limit, cursor and order are not supported with ndjson")
+			return
+		}
+		h.streamLogs(c, filter)
+		return
+	}
+	// format=json asks for JSON whatever the Accept header says
+	format := gin.MIMEJSON
+	if q.Format == "" {
+		format = renderFormat(c)
+	}
+	if paged {
+		h.pageLogs(c, filter, page, format)
+		return
+	}
+	events, err := h.services.EventLog.List(ctx, filter)
+	if err != nil {
+		if h.log != nil {
+			h.requestLog(c).Errorw("logs_list_failed", "err", err, "from", filter.From, "to", filter.To, "type", filter.Type, "types", filter.Types, "exclude_types", filter.ExcludeTypes, "severity", filter.Severity, "run_id", filter.RunID)
+		}
+		problem(c, http.StatusInternalServerError, codeInternal, "failed to load logs")
+		return
+	}
+	if format != gin.MIMEJSON {
+		renderRecords(c, format, "logs", "event", events)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"count":  len(events),
+		"events": events,
+	})
+}
+
+// @Summary      Tail logs
+// @Description  Returns only the events appended after after_id, in the order they were appended, so clients can follow the log by polling without a WebSocket: pass next_after_id back as after_id each time. after_ts (RFC3339) starts from events that occurred after a time instead; with neither, the tail starts at the end of the log and only next_after_id is returned.
+// @Description  With wait (e.g. 30s, at most 1m) the request is held until an event arrives or the wait is over (long poll); it then answers with no events and the same next_after_id. type, exclude_type, run_id and meta.* filter as for GET /logs.
+// @Tags         logs
+// @Produce      json
+// @Param        after_id      query  string  false  "next_after_id of the previous read"
+// @Param        after_ts      query  string  false  "Only events that occurred after this time (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')"
+// @Param        wait          query  string  false  "Long-poll up to this long for a new event (Go duration, max 1m)"  example(30s)
+// @Param        limit         query  int     false  "Maximum events (default 100)"  minimum(1)  maximum(1000)
+// @Param        type          query  string  false  "Event type, or a comma-separated list of types to include"
+// @Param        exclude_type  query  string  false  "Comma-separated event types to leave out"
+// @Param        run_id        query  string  false  "Only events recorded during the given heat cycle"
+// @Param        meta.{key}    query  string  false  "Compare a metadata value, as for GET /logs"
+// @Success      200  {object}  map[string]interface{}  "count, events, next_after_id"
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/logs/tail [get]
+// @Security     BearerAuth
+func (h *Handler) tailLogs(c *gin.Context) {
 	var (
-		from time.Time
-		to   time.Time
-		// Normalize event type: trim spaces and uppercase to match expected values.
-		eventType = strings.ToUpper(strings.TrimSpace(c.Query("type")))
-		err       error
+		p   = service.TailParams{AfterID: strings.TrimSpace(c.Query("after_id"))}
+		err error
 	)
-	// Parse 'from' (optional)
-	if qs := c.Query("from"); qs != "" {
-		from, err = parseQueryTime(qs)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": errFromInvalid})
+	if qs := c.Query("after_ts"); qs != "" {
+		if p.AfterAt, err = parseQueryTime(qs); err != nil {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, "invalid 'after_ts' time; use RFC3339 or YYYY-MM-DD")
 			return
 		}
 	}
-	// Parse 'to' (optional). If only a date is provided, make it end-of-day inclusive.
-	if qs := c.Query("to"); qs != "" {
-		to, err = parseQueryTime(qs)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": errToInvalid})
+	if qs := c.Query("wait"); qs != "" {
+		if p.Wait, err = time.ParseDuration(qs); err != nil || p.Wait < 0 || p.Wait > service.MaxTailWait {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, fmt.Sprintf("invalid 'wait'; use a duration such as 30s, at most %s", service.MaxTailWait))
 			return
 		}
-		// If the user didn't include a time component, treat "to" as the end of that day.
-		if isDateOnly(qs) {
-			to = to.Add(24*time.Hour - time.Nanosecond).UTC()
+	}
+	if qs := c.Query("limit"); qs != "" {
+		if p.Limit, err = strconv.Atoi(qs); err != nil || p.Limit < 1 || p.Limit > service.MaxLogLimit {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, fmt.Sprintf("invalid 'limit'; must be between 1 and %d", service.MaxLogLimit))
+			return
 		}
 	}
-	// Validate range if both provided
-	if !from.IsZero() && !to.IsZero() && from.After(to) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "'from' must be <= 'to'"})
+	meta, err := metaFilters(c)
+	if err != nil {
+		problemFor(c, http.StatusBadRequest, err)
+		return
+	}
+	filter := service.LogFilter{
+		RunID:        strings.TrimSpace(c.Query("run_id")),
+		Types:        splitTypes(c.Query("type")),
+		ExcludeTypes: splitTypes(c.Query("exclude_type")),
+		Meta:         meta,
+	}
+	ctx := c.Request.Context()
+	if p.Wait > 0 {
+		// the server's write timeout would cut the long poll short
+		_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(p.Wait + streamWriteTimeout))
+		// and a shutdown would wait for it
+		var cancel context.CancelFunc
+		ctx, cancel = h.untilAway(ctx)
+		defer cancel()
+	}
+
+	tail, err := h.services.EventTail.Tail(ctx, filter, p)
+	if errors.Is(err, service.ErrTailEventNotFound) {
+		problem(c, http.StatusNotFound, problemCode(http.StatusNotFound, err), "'after_id' is not in the log (it may have been purged); continue with after_ts")
 		return
 	}
-	events, err := h.services.EventLog.List(ctx, service.LogFilter{
-		From: from,
-		To:   to,
-		Type: eventType,
-	})
 	if err != nil {
-		if h.log != nil {
-			h.log.Errorw("logs_list_failed", "err", err, "from", from, "to", to, "type", eventType)
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to load logs", "logs_tail_failed", err)
+		return
+	}
+	out := gin.H{
+		"count":  len(tail.Events),
+		"events": tail.Events,
+	}
+	if tail.NextAfterID != "" {
+		out["next_after_id"] = tail.NextAfterID
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// splitTypes parses a comma-separated list of event types, uppercased and
+// without blanks.
+func splitTypes(qs string) []string {
+	var types []string
+	for _, t := range strings.Split(qs, ",") {
+		if t = strings.ToUpper(strings.TrimSpace(t)); t != "" {
+			types = append(types, t)
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load logs"})
+	}
+	return types
+}
+
+// metaFilters reads the meta.* query parameters. The query string splits
+// meta.temp_c>=1000 at its "=" and leaves meta.temp_c>1000 without a
+// value, so each parameter is joined back into one comparison.
+func metaFilters(c *gin.Context) ([]service.MetaFilter, error) {
+	query := c.Request.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		if strings.HasPrefix(k, "meta.") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var out []service.MetaFilter
+	for _, k := range keys {
+		name := strings.TrimPrefix(k, "meta.")
+		for _, v := range query[k] {
+			expr := name + "=" + v
+			if v == "" && strings.ContainsAny(name, "=!<>") {
+				expr = name
+			}
+			f, err := service.ParseMetaFilter(expr)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, f)
+		}
+	}
+	if len(out) > service.MaxMetaFilters {
+		return nil, fmt.Errorf("%w: at most %d filters", service.ErrInvalidMetaFilter, service.MaxMetaFilters)
+	}
+	return out, nil
+}
+
+// pageLogs writes one page of the events matching f.
+func (h *Handler) pageLogs(c *gin.Context, f service.LogFilter, p service.LogPageParams, format string) {
+	page, err := h.services.EventPager.Page(c.Request.Context(), f, p)
+	if errors.Is(err, service.ErrInvalidCursor) {
+		problem(c, http.StatusBadRequest, problemCode(http.StatusBadRequest, err), "invalid 'cursor'; pass next_cursor from a previous page with the same order")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{
-		"count":  len(events),
-		"events": events,
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to load logs", "logs_page_failed", err)
+		return
+	}
+	if format != gin.MIMEJSON {
+		if page.NextCursor != "" {
+			c.Header(nextCursorHeader, page.NextCursor)
+		}
+		renderRecords(c, format, "logs", "event", page.Events)
+		return
+	}
+	out := gin.H{
+		"count":  len(page.Events),
+		"events": page.Events,
+	}
+	if page.NextCursor != "" {
+		out["next_cursor"] = page.NextCursor
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// streamLogs writes the events matching f as NDJSON, flushing as it goes.
+// The status is sent with the first event, so errors before it still get a
+// regular 500 response.
+func (h *Handler) streamLogs(c *gin.Context, f service.LogFilter) {
+	rewrite := streamThrough(c)
+	rc := http.NewResponseController(c.Writer)
+	started, n := false, 0
+	start := func() {
+		started = true
+		c.Header("Content-Type", contentTypeNDJSON)
+		c.Status(http.StatusOK)
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	}
+	writeLine := func(v any) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = c.Writer.Write(append(rewrite(b), '\n'))
+		return err
+	}
+
+	err := h.services.EventStream.Stream(c.Request.Context(), f, func(ev models.FurnaceEvent) error {
+		if !started {
+			start()
+		}
+		if err := writeLine(ev); err != nil {
+			return err
+		}
+		if n++; n%streamFlushEvery == 0 {
+			_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			return rc.Flush()
+		}
+		return nil
 	})
+	switch {
+	case err != nil && !started:
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to load logs", "logs_stream_failed", err)
+	case err != nil:
+		if h.log != nil {
+			h.requestLog(c).Errorw("logs_stream_failed", "err", err, "sent", n)
+		}
+		_ = writeLine(gin.H{"error": "failed to load logs"})
+	case !started:
+		start()
+	}
 }
 
 // ... existing code ...
@@ -106,3 +432,177 @@ func parseQueryTime(s string) (time.Time, error) {
 }
 
 // ... existing code ...
+
+// @Summary      Verify event log integrity
+// @Description  Walks the event log hash chain and reports rows that were edited (tampered), removed or reordered (gap), or written without a hash (unhashed). Requires events.hash_chain; events logged before it was enabled are counted as unchained. Truncation at the end is only detectable by comparing head with a previously recorded value.
+// @Tags         logs
+// @Produce      json
+// @Success      200  {object}  models.ChainReport
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/logs/verify [get]
+// @Security     BearerAuth
+func (h *Handler) verifyLogs(c *gin.Context) {
+	rep, err := h.services.EventAudit.VerifyChain(c.Request.Context())
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to verify event log", "logs_verify_failed", err)
+		return
+	}
+	if !rep.Valid && h.log != nil {
+		h.requestLog(c).Warnw("event_chain_invalid", "problems", rep.Total, "head", rep.Head)
+	}
+	c.JSON(http.StatusOK, rep)
+}
+
+// @Summary      Describe event types
+// @Description  Returns the catalog of event types the service logs: each type's default severity (info, warning or critical) and the metadata fields it carries, with their JSON type and whether every event of the type has them. Fields in common may appear on any event. The field definitions are generated from the Go metadata structs, so clients can build decoders and validation from them. Events imported from CSV keep their source's types and are not listed.
+// @Tags         logs
+// @Produce      json
+// @Success      200  {object}  models.EventCatalog
+// @Failure      401  {object}  Problem
+// @Router       /api/v1/logs/schema [get]
+// @Security     BearerAuth
+func (h *Handler) getLogSchema(c *gin.Context) {
+	c.JSON(http.StatusOK, service.EventCatalog())
+}
+
+// PurgeLogsRequest overrides the configured event retention for one purge.
+type PurgeLogsRequest struct {
+	// Remove events older than this Go duration; the configured max_age when omitted
+	MaxAge string `json:"max_age,omitempty" example:"720h"`
+	// Keep only this many of the newest events; the configured max_rows when omitted
+	MaxRows int `json:"max_rows,omitempty" example:"100000"`
+	// Report what would be removed without deleting anything
+	DryRun bool `json:"dry_run" example:"true"`
+}
+
+// @Summary      Purge old events
+// @Description  Removes the oldest events beyond an age or row limit, in insertion order; limits omitted from the body fall back to events.retention. With an archive directory configured the rows are first written there as gzipped NDJSON. The hash chain over the remaining events stays verifiable, and a LOG_PURGED event records the purge. dry_run reports the selection without deleting. The body may be omitted.
+// @Tags         logs
+// @Accept       json
+// @Produce      json
+// @Param        body  body      PurgeLogsRequest  false  "Limits and dry run"
+// @Success      200   {object}  models.PurgeReport
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      500   {object}  Problem
+// @Router       /api/v1/logs/purge [post]
+// @Security     BearerAuth
+func (h *Handler) purgeLogs(c *gin.Context) {
+	var req PurgeLogsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		problem(c, http.StatusBadRequest, codeInvalidBody, errInvalidBodyPref+err.Error())
+		return
+	}
+	purge := service.PurgeRequest{MaxRows: req.MaxRows, DryRun: req.DryRun}
+	if req.MaxAge != "" {
+		d, err := time.ParseDuration(req.MaxAge)
+		if err != nil {
+			problem(c, http.StatusBadRequest, codeInvalidBody, "invalid max_age: "+err.Error())
+			return
+		}
+		purge.MaxAge = d
+	}
+	rep, err := h.services.Retention.PurgeEvents(c.Request.Context(), purge)
+	switch {
+	case errors.Is(err, service.ErrInvalidPurge):
+		problemFor(c, http.StatusBadRequest, err)
+		return
+	case err != nil:
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to purge event log", "logs_purge_failed", err)
+		return
+	}
+	if h.log != nil && !rep.DryRun {
+		h.requestLog(c).Infow("logs_purged", "deleted", rep.Deleted, "archive", rep.Archive)
+	}
+	c.JSON(http.StatusOK, rep)
+}
+
+// EventCommentRequest is the payload for commenting on an event.
+type EventCommentRequest struct {
+	Text string `json:"text" binding:"required" example:"Overheat was caused by the door left open"`
+}
+
+// @Summary      Comment on an event
+// @Description  Attaches a note by the calling user to a logged event, e.g. the cause of an alarm for the next shift. Comments are returned with the event by GET /logs (except as NDJSON) and GET /logs/tail, oldest first, and are removed when the event is purged.
+// @Tags         logs
+// @Accept       json
+// @Produce      json
+// @Param        event_id  path      string               true  "Event ID"
+// @Param        body      body      EventCommentRequest  true  "Comment"
+// @Success      201       {object}  models.EventComment
+// @Failure      400       {object}  Problem
+// @Failure      401       {object}  Problem
+// @Failure      403       {object}  Problem
+// @Failure      404       {object}  Problem
+// @Failure      500       {object}  Problem
+// @Router       /api/v1/logs/{event_id}/comments [post]
+// @Security     BearerAuth
+func (h *Handler) commentEvent(c *gin.Context) {
+	var req EventCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, "invalid request body")
+		return
+	}
+	comment, err := h.services.EventComments.AddComment(c.Request.Context(), c.Param("event_id"), c.GetInt(ctxKeyUserID), req.Text)
+	switch {
+	case errors.Is(err, service.ErrInvalidComment):
+		problemFor(c, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, service.ErrEventNotFound):
+		problemFor(c, http.StatusNotFound, err)
+		return
+	case err != nil:
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to comment on event", "event_comment_failed", err)
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+// DeleteEventRequest is the payload for soft-deleting an event.
+type DeleteEventRequest struct {
+	Reason string `json:"reason" binding:"required" example:"Test entry logged on the production furnace"`
+}
+
+// @Summary      Delete an event
+// @Description  Soft-deletes an event holding mistaken data: it is no longer listed by GET /logs (unless an admin passes include_deleted=true) or /logs/tail, but stays in the database with who deleted it, when and why. The hash chain still covers it, audit exports include it and retention purges it like any other event. It cannot be undone through the API.
+// @Tags         logs
+// @Accept       json
+// @Param        event_id  path  string              true  "Event ID"
+// @Param        body      body  DeleteEventRequest  true  "Reason"
+// @Success      204
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      409  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/logs/{event_id} [delete]
+// @Security     BearerAuth
+func (h *Handler) deleteEvent(c *gin.Context) {
+	var req DeleteEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, "invalid request body")
+		return
+	}
+	err := h.services.EventDeletions.DeleteEvent(c.Request.Context(), c.Param("event_id"), c.GetInt(ctxKeyUserID), req.Reason)
+	switch {
+	case errors.Is(err, service.ErrInvalidDeletion):
+		problemFor(c, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, service.ErrEventNotFound):
+		problemFor(c, http.StatusNotFound, err)
+		return
+	case errors.Is(err, service.ErrEventDeleted):
+		problemFor(c, http.StatusConflict, err)
+		return
+	case err != nil:
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to delete event", "event_delete_failed", err)
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Infow("event_deleted", "event_id", c.Param("event_id"), "reason", req.Reason)
+	}
+	c.Status(http.StatusNoContent)
+}
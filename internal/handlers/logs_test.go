@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -63,3 +66,480 @@ func TestLogsHandler_ListAndValidation(t *testing.T) {
 		t.Fatalf("expected lastType MODE_CHANGE, got %q", logs.lastType)
 	}
 }
+
+func TestLogsHandler_RunIDFilter(t *testing.T) {
+	logs := &mockEventLog{}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1},
+		EventLog:      logs,
+	}
+	r := newTestRouter(s)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/logs/?run_id=run-123", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("logs status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if logs.lastRunID != "run-123" {
+		t.Fatalf("expected run_id passed to service, got %q", logs.lastRunID)
+	}
+}
+
+func TestLogsHandler_Paginates(t *testing.T) {
+	logs := &mockEventLog{resp: []models.FurnaceEvent{{EventID: "e1"}}, next: "abc"}
+	r := newTestRouter(&service.Service{
+		Authorization: &mockAuth{parseID: 1},
+		EventLog:      logs,
+		EventPager:    logs,
+	})
+	get := func(q string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/logs/"+q, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("?limit=20&order=desc&type=error")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	var out struct {
+		Count      int    `json:"count"`
+		NextCursor string `json:"next_cursor"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &out)
+	if out.Count != 1 || out.NextCursor != "abc" {
+		t.Fatalf("unexpected page: %s", w.Body.String())
+	}
+	if logs.lastPage != (service.LogPageParams{Limit: 20, Desc: true}) || logs.lastType != "ERROR" {
+		t.Fatalf("page params = %+v, type %q", logs.lastPage, logs.lastType)
+	}
+
+	logs.next = ""
+	w = get("?cursor=abc")
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "next_cursor") {
+		t.Fatalf("last page: status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if logs.lastPage.Cursor != "abc" || logs.lastPage.Desc {
+		t.Fatalf("page params = %+v", logs.lastPage)
+	}
+
+	for _, q := range []string{"?limit=0", "?limit=1001", "?limit=x", "?order=up", "?cursor=bad", "?limit=5&format=ndjson"} {
+		if w := get(q); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status=%d, want 400", q, w.Code)
+		}
+	}
+}
+
+func TestLogsHandler_TypeLists(t *testing.T) {
+	logs := &mockEventLog{}
+	r := newTestRouter(&service.Service{Authorization: &mockAuth{parseID: 1}, EventLog: logs})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/logs/?type=start,%20stop,&exclude_type=telemetry", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("logs status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if logs.lastType != "" || strings.Join(logs.lastTypes, ",") != "START,STOP" {
+		t.Fatalf("types = %q %v; want START,STOP as a list", logs.lastType, logs.lastTypes)
+	}
+	if strings.Join(logs.lastExcl, ",") != "TELEMETRY" {
+		t.Fatalf("exclude_types = %v; want TELEMETRY", logs.lastExcl)
+	}
+}
+
+func TestLogsHandler_Severity(t *testing.T) {
+	logs := &mockEventLog{}
+	r := newTestRouter(&service.Service{Authorization: &mockAuth{parseID: 1}, EventLog: logs})
+	get := func(q string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/logs/"+q, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("?severity=warning&type=alert"); w.Code != http.StatusOK || logs.lastSev != "warning" || logs.lastType != "ALERT" {
+		t.Fatalf("severity: status=%d, passed %q %q", w.Code, logs.lastSev, logs.lastType)
+	}
+	w := get("?severity=fatal")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("severity=fatal: status=%d", w.Code)
+	}
+	if p := decodeProblem(t, w); p.Code != codeInvalidQuery || !strings.Contains(p.Detail, "Severity") {
+		t.Fatalf("problem %+v", p)
+	}
+}
+
+func TestLogsHandler_MetaFilters(t *testing.T) {
+	logs := &mockEventLog{}
+	r := newTestRouter(&service.Service{Authorization: &mockAuth{parseID: 1}, EventLog: logs})
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/logs/?"+query, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("meta.to=COOL&meta.temp_c>1000&meta.limits.max_c<=1200&meta.note!=a=b"); w.Code != http.StatusOK {
+		t.Fatalf("logs status=%d, body=%s", w.Code, w.Body.String())
+	}
+	want := []service.MetaFilter{
+		{Key: "limits.max_c", Op: "<=", Value: "1200"},
+		{Key: "note", Op: "!=", Value: "a=b"},
+		{Key: "temp_c", Op: ">", Value: "1000"},
+		{Key: "to", Op: "=", Value: "COOL"},
+	}
+	if !reflect.DeepEqual(logs.lastMeta, want) {
+		t.Fatalf("meta filters = %+v; want %+v", logs.lastMeta, want)
+	}
+
+	for _, bad := range []string{"meta.temp_c>", "meta.bad%20key=1", "meta.x=", "meta.a=1&meta.b=1&meta.c=1&meta.d=1&meta.e=1&meta.f=1&meta.g=1&meta.h=1&meta.i=1"} {
+		if w := get(bad); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", bad, w.Code)
+		}
+	}
+}
+
+func TestLogsHandler_Verify(t *testing.T) {
+	audit := &mockEventAudit{report: models.ChainReport{
+		Enabled:  true,
+		Checked:  3,
+		Problems: []models.ChainProblem{{EventID: "e2", Kind: models.ChainTampered}},
+		Total:    1,
+	}}
+	r := newTestRouter(&service.Service{Authorization: &mockAuth{parseID: 1}, EventAudit: audit})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/logs/verify", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	var rep models.ChainReport
+	if err := json.Unmarshal(w.Body.Bytes(), &rep); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if rep.Valid || len(rep.Problems) != 1 || rep.Problems[0].Kind != models.ChainTampered {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+}
+
+func TestLogsHandler_Schema(t *testing.T) {
+	r := newTestRouter(&service.Service{Authorization: &mockAuth{parseID: 1, parseRole: models.RoleViewer}})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/logs/schema", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	var cat models.EventCatalog
+	if err := json.Unmarshal(w.Body.Bytes(), &cat); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	for _, s := range cat.Types {
+		if s.Type == "ERROR" {
+			if s.Severity != models.SeverityCritical || len(s.Fields) == 0 {
+				t.Fatalf("ERROR = %+v", s)
+			}
+			return
+		}
+	}
+	t.Fatalf("no ERROR in %+v", cat.Types)
+}
+
+func TestLogsHandler_Purge(t *testing.T) {
+	ret := &mockRetention{report: models.PurgeReport{DryRun: true, Deleted: 42}}
+	r := newTestRouter(&service.Service{Authorization: &mockAuth{parseID: 1, parseRole: models.RoleAdmin}, Retention: ret})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/logs/purge", strings.NewReader(`{"max_age":"720h","dry_run":true}`))
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if ret.lastReq != (service.PurgeRequest{MaxAge: 720 * time.Hour, DryRun: true}) {
+		t.Fatalf("request = %+v", ret.lastReq)
+	}
+	var rep models.PurgeReport
+	if err := json.Unmarshal(w.Body.Bytes(), &rep); err != nil || rep.Deleted != 42 || !rep.DryRun {
+		t.Fatalf("report = %+v, err %v", rep, err)
+	}
+
+	// no body purges by the configured retention
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/logs/purge", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || ret.lastReq != (service.PurgeRequest{}) {
+		t.Fatalf("empty body: status=%d request=%+v", w.Code, ret.lastReq)
+	}
+
+	for _, tc := range []struct {
+		body string
+		err  error
+	}{
+		{body: `{"max_age":"soon"}`},
+		{body: `{}`, err: service.ErrInvalidPurge}, // nothing configured either
+	} {
+		ret.err = tc.err
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/logs/purge", strings.NewReader(tc.body))
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status=%d, want 400", tc.body, w.Code)
+		}
+	}
+}
+
+func TestLogsHandler_PurgeRequiresAdmin(t *testing.T) {
+	ret := &mockRetention{}
+	r := newTestRouter(&service.Service{Authorization: &mockAuth{parseID: 1, parseRole: models.RoleOperator}, Retention: ret})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/logs/purge", strings.NewReader(`{"dry_run":true}`))
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status=%d, want 403", w.Code)
+	}
+}
+
+func TestLogsHandler_StreamsNDJSON(t *testing.T) {
+	logs := &mockEventLog{resp: []models.FurnaceEvent{
+		{EventID: "1", Type: "START", Metadata: map[string]any{"run_id": "run-1"}},
+		{EventID: "2", Type: "STOP"},
+	}}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1},
+		EventStream:   logs,
+	}
+	r := newCompatRouter(s, CompatConfig{})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/logs/?type=stop", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	req.Header.Set("Accept", contentTypeNDJSON)
+	req.Header.Set(compatHeader, "camel")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != contentTypeNDJSON {
+		t.Fatalf("status=%d content-type=%q", w.Code, w.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", w.Body.String())
+	}
+	var first map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("line 1: %v", err)
+	}
+	if first["eventId"] != "1" || first["metadata"].(map[string]any)["runId"] != "run-1" {
+		t.Fatalf("expected camelCase event, got %v", first)
+	}
+	if logs.lastType != "STOP" {
+		t.Fatalf("expected type filter passed, got %q", logs.lastType)
+	}
+}
+
+func TestLogsHandler_StreamErrors(t *testing.T) {
+	logs := &mockEventLog{err: errors.New("db down")}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1},
+		EventStream:   logs,
+	}
+	r := newTestRouter(s)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/logs/"+query, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// nothing sent yet → regular error response
+	if w := get("?format=ndjson"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 before streaming, got %d", w.Code)
+	}
+
+	// failure mid-stream → trailing error line
+	logs.err, logs.streamErr = nil, errors.New("db down")
+	logs.resp = []models.FurnaceEvent{{EventID: "1", Type: "START"}}
+	w := get("?format=ndjson")
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if w.Code != http.StatusOK || len(lines) != 2 || !strings.Contains(lines[1], `"error"`) {
+		t.Fatalf("expected event then error line, got %d %q", w.Code, w.Body.String())
+	}
+
+	if w := get("?format=xml"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown format, got %d", w.Code)
+	}
+}
+
+func TestLogsHandler_Tail(t *testing.T) {
+	logs := &mockEventLog{resp: []models.FurnaceEvent{{EventID: "e8", Type: "ERROR"}}, next: "e8"}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1},
+		EventTail:     logs,
+	}
+	r := newTestRouter(s)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/logs/tail"+query, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("?after_id=e7&wait=30s&limit=10&type=error")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	var out struct {
+		Count       int                   `json:"count"`
+		Events      []models.FurnaceEvent `json:"events"`
+		NextAfterID string                `json:"next_after_id"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &out)
+	if out.Count != 1 || out.NextAfterID != "e8" {
+		t.Fatalf("unexpected response: %s", w.Body.String())
+	}
+	want := service.TailParams{AfterID: "e7", Limit: 10, Wait: 30 * time.Second}
+	if logs.lastTail != want || !reflect.DeepEqual(logs.lastTypes, []string{"ERROR"}) {
+		t.Fatalf("passed %+v types %v, want %+v [ERROR]", logs.lastTail, logs.lastTypes, want)
+	}
+
+	if w := get("?after_id=purged"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a purged after_id, got %d", w.Code)
+	}
+	for _, q := range []string{"?wait=2m", "?wait=soon", "?after_ts=yesterday", "?limit=0"} {
+		if w := get(q); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", q, w.Code)
+		}
+	}
+	logs.err = errors.New("db down")
+	if w := get(""); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
+
+func TestLogsHandler_CommentEvent(t *testing.T) {
+	comments := &mockEventComments{}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 7, parseRole: models.RoleOperator},
+		EventComments: comments,
+	}
+	r := newTestRouter(s)
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/logs/"+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer valid")
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("e1/comments", `{"text":"door left open"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if comments.got.EventID != "e1" || comments.got.UserID != 7 || comments.got.Text != "door left open" {
+		t.Fatalf("passed %+v", comments.got)
+	}
+	if w := post("e1/comments", `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without text, got %d", w.Code)
+	}
+	comments.err = service.ErrInvalidComment
+	if w := post("e1/comments", `{"text":" "}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid comment, got %d", w.Code)
+	}
+	comments.err = service.ErrEventNotFound
+	if w := post("gone/comments", `{"text":"note"}`); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a missing event, got %d", w.Code)
+	}
+}
+
+func TestLogsHandler_DeleteEvent(t *testing.T) {
+	auth := &mockAuth{parseID: 7, parseRole: models.RoleAdmin}
+	deletions := &mockEventDeletions{}
+	s := &service.Service{
+		Authorization:  auth,
+		EventDeletions: deletions,
+	}
+	r := newTestRouter(s)
+
+	del := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/logs/"+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer valid")
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := del("e1", `{"reason":"test entry"}`); w.Code != http.StatusNoContent {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if deletions.gotID != "e1" || deletions.gotUserID != 7 || deletions.gotReason != "test entry" {
+		t.Fatalf("passed %+v", deletions)
+	}
+	if w := del("e1", `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without a reason, got %d", w.Code)
+	}
+	for err, code := range map[error]int{
+		service.ErrInvalidDeletion: http.StatusBadRequest,
+		service.ErrEventNotFound:   http.StatusNotFound,
+		service.ErrEventDeleted:    http.StatusConflict,
+	} {
+		deletions.err = err
+		if w := del("e1", `{"reason":"test entry"}`); w.Code != code {
+			t.Fatalf("%v: expected %d, got %d", err, code, w.Code)
+		}
+	}
+
+	auth.parseRole = models.RoleOperator
+	if w := del("e1", `{"reason":"test entry"}`); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for an operator, got %d", w.Code)
+	}
+}
+
+func TestLogsHandler_IncludeDeletedIsForAdmins(t *testing.T) {
+	auth := &mockAuth{parseID: 7, parseRole: models.RoleOperator}
+	logs := &mockEventLog{}
+	r := newTestRouter(&service.Service{Authorization: auth, EventLog: logs})
+	get := func(q string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/logs/"+q, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := get("?include_deleted=true"); code != http.StatusForbidden {
+		t.Fatalf("operator: expected 403, got %d", code)
+	}
+	if code := get("?include_deleted=false"); code != http.StatusOK || logs.lastDel {
+		t.Fatalf("include_deleted=false: status %d, passed %v", code, logs.lastDel)
+	}
+	if code := get("?include_deleted=maybe"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid value, got %d", code)
+	}
+	auth.parseRole = models.RoleAdmin
+	if code := get("?include_deleted=true"); code != http.StatusOK || !logs.lastDel {
+		t.Fatalf("admin: status %d, passed %v", code, logs.lastDel)
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      List background loops
+// @Description  Reports the supervised background loops (simulator, alerts, incidents): whether each is running or waiting to be restarted after a panic, how often it was restarted, and the last failure. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "count, loops ([]models.LoopStatus)"
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Router       /api/v1/admin/loops [get]
+// @Security     BearerAuth
+func (h *Handler) listLoops(c *gin.Context) {
+	if h.services.Loops == nil {
+		c.JSON(http.StatusOK, gin.H{"count": 0, "loops": []any{}})
+		return
+	}
+	loops := h.services.Loops.LoopStatus()
+	c.JSON(http.StatusOK, gin.H{
+		"count": len(loops),
+		"loops": loops,
+	})
+}
+
+// @Summary      Restart a background loop
+// @Description  Stops the named loop and starts it again, as after a panic but without the backoff. The simulator saves its state on the way down and recovers it on the way up. Admin only.
+// @Tags         admin
+// @Param        name  path  string  true  "Loop name"  Enums(simulator,alerts,incidents)
+// @Success      202
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Router       /api/v1/admin/loops/{name}/restart [post]
+// @Security     BearerAuth
+func (h *Handler) restartLoop(c *gin.Context) {
+	name := c.Param("name")
+	if h.services.Loops == nil {
+		problem(c, http.StatusNotFound, codeNotFound, "loop not found")
+		return
+	}
+	if err := h.services.Loops.Restart(name); err != nil {
+		if errors.Is(err, service.ErrUnknownLoop) {
+			problem(c, http.StatusNotFound, codeNotFound, "loop not found")
+			return
+		}
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to restart loop", "loop_restart_failed", err)
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Warnw("loop_restart_requested", "loop", name, "userId", c.GetInt(ctxKeyUserID))
+	}
+	c.Status(http.StatusAccepted)
+}
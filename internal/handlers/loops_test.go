@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestLoopsHandlers(t *testing.T) {
+	loops := &mockLoops{status: []models.LoopStatus{
+		{Name: "alerts", State: models.LoopRunning},
+		{Name: "simulator", State: models.LoopBackoff, Restarts: 2, LastError: "panic: boom"},
+	}}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleAdmin},
+		Loops:         loops,
+	}
+	r := newTestRouter(s)
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "/api/v1/admin/loops")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	var out struct {
+		Count int                 `json:"count"`
+		Loops []models.LoopStatus `json:"loops"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out.Count != 2 || out.Loops[1].State != models.LoopBackoff || out.Loops[1].LastError != "panic: boom" {
+		t.Fatalf("unexpected loops: %+v", out)
+	}
+
+	if w := do(http.MethodPost, "/api/v1/admin/loops/simulator/restart"); w.Code != http.StatusAccepted || loops.restarted != "simulator" {
+		t.Fatalf("restart: status=%d restarted=%q", w.Code, loops.restarted)
+	}
+	if w := do(http.MethodPost, "/api/v1/admin/loops/nope/restart"); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown loop: status=%d, want 404", w.Code)
+	}
+}
+
+func TestLoopsHandlers_AdminOnly(t *testing.T) {
+	r := newTestRouter(&service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleOperator},
+		Loops:         &mockLoops{},
+	})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/loops/simulator/restart", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for operator, got %d", w.Code)
+	}
+}
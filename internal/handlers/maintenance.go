@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceTaskRequest is the payload for creating or replacing a
+// maintenance task. Set interval_hours, interval_days or both; the task
+// falls due at whichever comes first.
+type MaintenanceTaskRequest struct {
+	Name string `json:"name" binding:"required" example:"Replace heating elements"`
+	// Allowed: calibration, element_replacement, inspection
+	Kind string `json:"kind" binding:"required" example:"element_replacement"`
+	// Spare part consumed, copied to each completion record
+	Part string `json:"part" example:"KANTHAL-A1-8MM"`
+	// Heating hours between completions
+	IntervalHours float64 `json:"interval_hours" example:"2000"`
+	// Calendar days between completions
+	IntervalDays int `json:"interval_days" example:"365"`
+}
+
+func (r MaintenanceTaskRequest) task() models.MaintenanceTask {
+	return models.MaintenanceTask{Name: r.Name, Kind: r.Kind, Part: r.Part, IntervalHours: r.IntervalHours, IntervalDays: r.IntervalDays}
+}
+
+// CompleteMaintenanceRequest is the optional payload for completing a task.
+type CompleteMaintenanceRequest struct {
+	Note string `json:"note" example:"Replaced all six elements"`
+}
+
+// taskID parses the :id path parameter, answering 400 if it is not a
+// positive integer.
+func taskID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		problem(c, http.StatusBadRequest, codeInvalidID, "invalid task id")
+		return 0, false
+	}
+	return id, true
+}
+
+// maintenanceError answers for errors returned by the maintenance service.
+func (h *Handler) maintenanceError(c *gin.Context, err error, msg, logKey string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidMaintenanceTask):
+		problemFor(c, http.StatusBadRequest, err)
+	case errors.Is(err, service.ErrMaintenanceTaskNotFound):
+		problemFor(c, http.StatusNotFound, err)
+	default:
+		h.logAndJSONError(c, http.StatusInternalServerError, msg, logKey, err)
+	}
+}
+
+// @Summary      List maintenance tasks
+// @Description  Returns every task with its due date (due_at), the heating hours it is due at (due_hours) and whether it is overdue.
+// @Tags         maintenance
+// @Produce      json
+// @Success      200  {array}   models.MaintenanceTask
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/maintenance/tasks [get]
+// @Security     BearerAuth
+func (h *Handler) listMaintenanceTasks(c *gin.Context) {
+	tasks, err := h.services.Maintenance.ListTasks(c.Request.Context())
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to list maintenance tasks", "maintenance_tasks_list_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+// @Summary      Get maintenance task
+// @Tags         maintenance
+// @Produce      json
+// @Param        id   path      int  true  "Task ID"
+// @Success      200  {object}  models.MaintenanceTask
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/maintenance/tasks/{id} [get]
+// @Security     BearerAuth
+func (h *Handler) getMaintenanceTask(c *gin.Context) {
+	id, ok := taskID(c)
+	if !ok {
+		return
+	}
+	task, err := h.services.Maintenance.GetTask(c.Request.Context(), id)
+	if err != nil {
+		h.maintenanceError(c, err, "failed to load maintenance task", "maintenance_task_get_failed")
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// @Summary      Create maintenance task
+// @Description  The first interval starts now. When the task falls due, a MAINTENANCE_OVERDUE event is logged once.
+// @Tags         maintenance
+// @Accept       json
+// @Produce      json
+// @Param        body  body      MaintenanceTaskRequest  true  "Task"
+// @Success      201   {object}  models.MaintenanceTask
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      500   {object}  Problem
+// @Router       /api/v1/maintenance/tasks [post]
+// @Security     BearerAuth
+func (h *Handler) createMaintenanceTask(c *gin.Context) {
+	var req MaintenanceTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, "invalid request body")
+		return
+	}
+	task, err := h.services.Maintenance.CreateTask(c.Request.Context(), req.task(), c.GetInt(ctxKeyUserID))
+	if err != nil {
+		h.maintenanceError(c, err, "failed to create maintenance task", "maintenance_task_create_failed")
+		return
+	}
+	c.JSON(http.StatusCreated, task)
+}
+
+// @Summary      Replace maintenance task
+// @Description  The interval still counts from the last completion.
+// @Tags         maintenance
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                     true  "Task ID"
+// @Param        body  body      MaintenanceTaskRequest  true  "Task"
+// @Success      200   {object}  models.MaintenanceTask
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      404   {object}  Problem
+// @Failure      500   {object}  Problem
+// @Router       /api/v1/maintenance/tasks/{id} [put]
+// @Security     BearerAuth
+func (h *Handler) updateMaintenanceTask(c *gin.Context) {
+	id, ok := taskID(c)
+	if !ok {
+		return
+	}
+	var req MaintenanceTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, "invalid request body")
+		return
+	}
+	task := req.task()
+	task.ID = id
+	task, err := h.services.Maintenance.UpdateTask(c.Request.Context(), task)
+	if err != nil {
+		h.maintenanceError(c, err, "failed to update maintenance task", "maintenance_task_update_failed")
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// @Summary      Delete maintenance task
+// @Description  Completion records of the task are kept.
+// @Tags         maintenance
+// @Param        id   path  int  true  "Task ID"
+// @Success      204
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/maintenance/tasks/{id} [delete]
+// @Security     BearerAuth
+func (h *Handler) deleteMaintenanceTask(c *gin.Context) {
+	id, ok := taskID(c)
+	if !ok {
+		return
+	}
+	if err := h.services.Maintenance.DeleteTask(c.Request.Context(), id); err != nil {
+		h.maintenanceError(c, err, "failed to delete maintenance task", "maintenance_task_delete_failed")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary      Complete maintenance task
+// @Description  Records the calling user as having done the task now and starts its next interval. Completing an element_replacement task also restarts heater wear, so the ramp rate is nominal again. Logs MAINTENANCE_DONE.
+// @Tags         maintenance
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                         true   "Task ID"
+// @Param        body  body      CompleteMaintenanceRequest  false  "Note"
+// @Success      201   {object}  models.MaintenanceRecord
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      404   {object}  Problem
+// @Failure      500   {object}  Problem
+// @Router       /api/v1/maintenance/tasks/{id}/complete [post]
+// @Security     BearerAuth
+func (h *Handler) completeMaintenanceTask(c *gin.Context) {
+	id, ok := taskID(c)
+	if !ok {
+		return
+	}
+	var req CompleteMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		problem(c, http.StatusBadRequest, codeInvalidBody, "invalid request body")
+		return
+	}
+	rec, err := h.services.Maintenance.CompleteTask(c.Request.Context(), id, c.GetInt(ctxKeyUserID), req.Note)
+	if err != nil {
+		h.maintenanceError(c, err, "failed to complete maintenance task", "maintenance_task_complete_failed")
+		return
+	}
+	c.JSON(http.StatusCreated, rec)
+}
+
+// @Summary      List maintenance records
+// @Description  Returns who completed which task and when, newest first.
+// @Tags         maintenance
+// @Produce      json
+// @Param        task_id  query     integer  false  "Only records of this task"
+// @Param        limit    query     integer  false  "Maximum records (default 100, max 1000)"
+// @Success      200      {object}  map[string]interface{}  "count, records"
+// @Failure      400      {object}  Problem
+// @Failure      401      {object}  Problem
+// @Failure      500      {object}  Problem
+// @Router       /api/v1/maintenance/records [get]
+// @Security     BearerAuth
+func (h *Handler) listMaintenanceRecords(c *gin.Context) {
+	var (
+		task, limit int
+		err         error
+	)
+	if qs := c.Query("task_id"); qs != "" {
+		if task, err = strconv.Atoi(qs); err != nil || task <= 0 {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, "invalid 'task_id'")
+			return
+		}
+	}
+	if qs := c.Query("limit"); qs != "" {
+		if limit, err = strconv.Atoi(qs); err != nil || limit < 0 {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, "invalid 'limit'; must be a non-negative integer")
+			return
+		}
+	}
+	records, err := h.services.Maintenance.ListRecords(c.Request.Context(), task, limit)
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to list maintenance records", "maintenance_records_list_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"count":   len(records),
+		"records": records,
+	})
+}
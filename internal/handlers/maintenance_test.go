@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestMaintenance(t *testing.T) {
+	due := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	maintenance := &mockMaintenance{
+		task:   models.MaintenanceTask{ID: 4, Name: "Replace heating elements", Kind: models.MaintenanceElements, IntervalHours: 2000, DueAt: &due, Overdue: true},
+		record: models.MaintenanceRecord{ID: 9, TaskID: 4, CompletedBy: 3, HeatingHours: 2013.5},
+	}
+	auth := &mockAuth{parseID: 3, parseRole: models.RoleOperator}
+	r := newTestRouter(&service.Service{Authorization: auth, Maintenance: maintenance})
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodGet, "/api/v1/maintenance/tasks", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"overdue":true`) {
+		t.Fatalf("list: status=%d body=%s", w.Code, w.Body.String())
+	}
+	w := do(http.MethodPost, "/api/v1/maintenance/tasks", `{"name":"Replace heating elements","kind":"element_replacement","interval_hours":2000}`)
+	if w.Code != http.StatusCreated || maintenance.lastUserID != 3 || maintenance.lastTask.IntervalHours != 2000 {
+		t.Fatalf("create: status=%d task=%+v user=%d", w.Code, maintenance.lastTask, maintenance.lastUserID)
+	}
+	if w := do(http.MethodPost, "/api/v1/maintenance/tasks", `{"kind":"inspection"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without a name, got %d", w.Code)
+	}
+	if w := do(http.MethodPut, "/api/v1/maintenance/tasks/4", `{"name":"Inspect door seal","kind":"inspection","interval_days":30}`); w.Code != http.StatusOK || maintenance.lastTask.ID != 4 {
+		t.Fatalf("update: status=%d task=%+v", w.Code, maintenance.lastTask)
+	}
+
+	if w := do(http.MethodPost, "/api/v1/maintenance/tasks/4/complete", ""); w.Code != http.StatusCreated || maintenance.lastNote != "" {
+		t.Fatalf("complete without body: status=%d body=%s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, "/api/v1/maintenance/tasks/4/complete", `{"note":"six new elements"}`); w.Code != http.StatusCreated || maintenance.lastNote != "six new elements" {
+		t.Fatalf("complete: status=%d note=%q", w.Code, maintenance.lastNote)
+	}
+
+	if w := do(http.MethodGet, "/api/v1/maintenance/records?task_id=4&limit=5", ""); w.Code != http.StatusOK || maintenance.lastQuery != [2]int{4, 5} {
+		t.Fatalf("records: status=%d query=%v", w.Code, maintenance.lastQuery)
+	}
+	if w := do(http.MethodGet, "/api/v1/maintenance/records?task_id=x", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad task_id, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v1/maintenance/tasks/x", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad id, got %d", w.Code)
+	}
+
+	maintenance.err = service.ErrInvalidMaintenanceTask
+	if w := do(http.MethodPost, "/api/v1/maintenance/tasks", `{"name":"x","kind":"polish"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid task, got %d", w.Code)
+	}
+	maintenance.err = service.ErrMaintenanceTaskNotFound
+	if w := do(http.MethodDelete, "/api/v1/maintenance/tasks/8", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+	maintenance.err = nil
+	if w := do(http.MethodDelete, "/api/v1/maintenance/tasks/4", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: status=%d", w.Code)
+	}
+}
+
+func TestMaintenance_ViewerCannotComplete(t *testing.T) {
+	auth := &mockAuth{parseID: 5, parseRole: models.RoleViewer}
+	r := newTestRouter(&service.Service{Authorization: auth, Maintenance: &mockMaintenance{}})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/maintenance/tasks/4/complete", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a viewer, got %d", w.Code)
+	}
+}
@@ -4,35 +4,104 @@ import (
 	"net/http"
 	"strings"
 
+	"controlling_furnace/internal/service"
+
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Keys under which the request ID and the authenticated identity are
+// stored in the Gin context.
+const (
+	ctxKeyRequestID = "requestId"
+	ctxKeyUserID    = "userId"
+	ctxKeyRole      = "role"
 )
 
+// requestIDHeader carries the request ID in both directions.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestID bounds request IDs accepted from clients.
+const maxRequestID = 64
+
+// requestIDMiddleware gives every request an ID, echoed in the
+// X-Request-ID response header, attached to the handler logs and passed
+// to the services, which record it on the events the request causes. A
+// well-formed X-Request-ID sent by the client (e.g. set by a proxy) is
+// kept; otherwise a random one is generated.
+func requestIDMiddleware(c *gin.Context) {
+	id := c.GetHeader(requestIDHeader)
+	if !validRequestID(id) {
+		id = uuid.NewString()
+	}
+	c.Set(ctxKeyRequestID, id)
+	c.Header(requestIDHeader, id)
+	c.Request = c.Request.WithContext(service.WithRequestID(c.Request.Context(), id))
+	c.Next()
+}
+
+// validRequestID accepts 1..maxRequestID letters, digits, '-', '_', '.'
+// and ':', so a client cannot inject log or header content.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestID {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requestLog returns the handler logger annotated with the ID of the
+// request being served. h.log must not be nil.
+func (h *Handler) requestLog(c *gin.Context) *zap.SugaredLogger {
+	return h.log.With("requestId", c.GetString(ctxKeyRequestID))
+}
+
 func (h *Handler) userIdMiddleware(c *gin.Context) {
 	header := c.GetHeader("Authorization")
 	if header == "" {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-			"error": "missing Authorization header",
-		})
+		abortProblem(c, http.StatusUnauthorized, codeMissingToken, "missing Authorization header")
 		return
 	}
 
 	parts := strings.SplitN(header, " ", 2)
 	if len(parts) != 2 || parts[0] != "Bearer" {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-			"error": "invalid Authorization header format",
-		})
+		abortProblem(c, http.StatusUnauthorized, codeInvalidToken, "invalid Authorization header format")
 		return
 	}
 
-	userId, err := h.services.ParseToken(parts[1])
+	claims, err := h.services.ParseClaims(parts[1])
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-			"error": "invalid or expired token",
-		})
+		abortProblem(c, http.StatusUnauthorized, codeInvalidToken, "invalid or expired token")
 		return
 	}
 
-	// store in Gin context
-	c.Set("userId", userId)
+	// store in Gin context, and the user in the request context for the
+	// services
+	c.Set(ctxKeyUserID, claims.UserID)
+	c.Set(ctxKeyRole, claims.EffectiveRole())
+	c.Request = c.Request.WithContext(service.WithUserID(c.Request.Context(), claims.UserID))
 	c.Next()
 }
+
+// requireRole only lets through requests whose token carries one of roles.
+// It must run after userIdMiddleware.
+func (h *Handler) requireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString(ctxKeyRole)
+		for _, r := range roles {
+			if role == r {
+				c.Next()
+				return
+			}
+		}
+		abortProblem(c, http.StatusForbidden, codeInsufficientPermissions, "insufficient permissions")
+	}
+}
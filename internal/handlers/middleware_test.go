@@ -1,15 +1,21 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"controlling_furnace/internal/models"
 	"controlling_furnace/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // minimal router wiring only the middleware + a protected endpoint
@@ -116,3 +122,76 @@ func TestUserIDMiddleware_SuccessSetsUserIDAndProceeds(t *testing.T) {
 		t.Fatalf("ParseToken got %q, want %q", auth.lastParseToken, "good-token")
 	}
 }
+
+func TestTracing_SpansAPIRequestsButNotProbes(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	otel.SetTracerProvider(tp)
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	gin.SetMode(gin.TestMode)
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleAdmin},
+		System:        &mockSystem{},
+	}
+	r := NewHandlerWithConfig(s, nil, Config{TraceService: "furnace-test"}).InitRoutes()
+
+	for _, path := range []string{"/healthz", "/api/v1/system/info"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("ended spans = %d, want 1 (probe excluded)", len(spans))
+	}
+	if got := spans[0].Name(); got != "/api/v1/system/info" {
+		t.Fatalf("span name = %q, want the route", got)
+	}
+}
+
+func TestRequestID_EchoedAndPassedToServices(t *testing.T) {
+	fu := &mockFurnace{}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 7, parseRole: models.RoleOperator},
+		Monitoring:    &mockMonitoring{},
+		Furnace:       fu,
+	}
+	r := newTestRouter(s)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/furnace/start", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	req.Header.Set("X-Request-ID", "proxy-7f3a")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("X-Request-ID"); got != "proxy-7f3a" {
+		t.Fatalf("X-Request-ID = %q, want the client's ID echoed", got)
+	}
+	if got := service.RequestID(fu.lastCtx); got != "proxy-7f3a" {
+		t.Fatalf("service saw request ID %q", got)
+	}
+	if got := service.UserID(fu.lastCtx); got != 7 {
+		t.Fatalf("service saw user %d, want 7", got)
+	}
+}
+
+func TestRequestID_ReplacesMalformedIDs(t *testing.T) {
+	r := newTestRouter(&service.Service{})
+	for _, sent := range []string{"", "two words", strings.Repeat("a", 65)} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		req.Header.Set("X-Request-ID", sent)
+		r.ServeHTTP(w, req)
+
+		got := w.Header().Get("X-Request-ID")
+		if got == "" || got == sent || !validRequestID(got) {
+			t.Fatalf("sent %q, got request ID %q; want a generated one", sent, got)
+		}
+	}
+}
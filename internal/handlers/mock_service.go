@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"context"
+	"io"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
 	"controlling_furnace/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -19,6 +23,7 @@ type mockAuth struct {
 	genTokenToken string
 	genTokenErr   error
 	parseID       int
+	parseRole     string
 	parseErr      error
 
 	lastSignUpUsername string
@@ -26,14 +31,16 @@ type mockAuth struct {
 	lastGenUsername    string
 	lastGenPassword    string
 	lastParseToken     string
+	setRoleErr         error
+	lastSetRole        string
 }
 
-func (m *mockAuth) SignUp(username, password string) (int, error) {
+func (m *mockAuth) SignUp(ctx context.Context, username, password string) (int, error) {
 	m.lastSignUpUsername = username
 	m.lastSignUpPassword = password
 	return m.signUpID, m.signUpErr
 }
-func (m *mockAuth) GenerateToken(username, password string) (string, error) {
+func (m *mockAuth) GenerateToken(ctx context.Context, username, password string) (string, error) {
 	m.lastGenUsername = username
 	m.lastGenPassword = password
 	return m.genTokenToken, m.genTokenErr
@@ -42,6 +49,17 @@ func (m *mockAuth) ParseToken(token string) (int, error) {
 	m.lastParseToken = token
 	return m.parseID, m.parseErr
 }
+func (m *mockAuth) SetRole(ctx context.Context, userID int, role string) error {
+	m.lastSetRole = role
+	return m.setRoleErr
+}
+func (m *mockAuth) ParseClaims(token string) (*service.Claims, error) {
+	m.lastParseToken = token
+	if m.parseErr != nil {
+		return nil, m.parseErr
+	}
+	return &service.Claims{UserID: m.parseID, Role: m.parseRole}, nil
+}
 
 type mockFurnace struct {
 	startErr     error
@@ -51,10 +69,19 @@ type mockFurnace struct {
 	startCalled  int
 	stopCalled   int
 	setModeCalls int
+	lastCtx      context.Context
+
+	atmosphereErr  error
+	lastAtmosphere *service.AtmosphereParams
+
+	readiness     service.Readiness
+	readinessErr  error
+	lastReadiness service.ModeParams
 }
 
 func (m *mockFurnace) Start(ctx context.Context) error {
 	m.startCalled++
+	m.lastCtx = ctx
 	return m.startErr
 }
 func (m *mockFurnace) Stop(ctx context.Context) error {
@@ -67,6 +94,16 @@ func (m *mockFurnace) SetMode(ctx context.Context, p service.ModeParams) error {
 	return m.setModeErr
 }
 
+func (m *mockFurnace) SetAtmosphere(ctx context.Context, p service.AtmosphereParams) error {
+	m.lastAtmosphere = &p
+	return m.atmosphereErr
+}
+
+func (m *mockFurnace) CheckReadiness(ctx context.Context, targetTempC float64, durationSec int) (service.Readiness, error) {
+	m.lastReadiness = service.ModeParams{Mode: "HEAT", TargetTempC: targetTempC, DurationSec: durationSec}
+	return m.readiness, m.readinessErr
+}
+
 type mockMonitoring struct {
 	state models.FurnaceState
 	err   error
@@ -77,20 +114,313 @@ func (m *mockMonitoring) GetState(ctx context.Context) (models.FurnaceState, err
 }
 
 type mockEventLog struct {
-	resp     []models.FurnaceEvent
-	err      error
-	lastFrom time.Time
-	lastTo   time.Time
-	lastType string
+	resp      []models.FurnaceEvent
+	err       error
+	lastFrom  time.Time
+	lastTo    time.Time
+	lastType  string
+	lastRunID string
+	lastTypes []string
+	lastExcl  []string
+	lastMeta  []service.MetaFilter
+	streamErr error // returned by Stream after resp was streamed
+	lastPage  service.LogPageParams
+	next      string // NextCursor returned by Page
+	lastTail  service.TailParams
+	lastDel   bool   // IncludeDeleted of the last List
+	lastSev   string // Severity of the last List
+	tailWait  bool   // Tail finds nothing and waits until ctx is done
 }
 
 func (m *mockEventLog) List(ctx context.Context, f service.LogFilter) ([]models.FurnaceEvent, error) {
 	m.lastFrom = f.From
 	m.lastTo = f.To
 	m.lastType = f.Type
+	m.lastRunID = f.RunID
+	m.lastTypes = f.Types
+	m.lastExcl = f.ExcludeTypes
+	m.lastMeta = f.Meta
+	m.lastDel = f.IncludeDeleted
+	m.lastSev = f.Severity
 	return m.resp, m.err
 }
 
+func (m *mockEventLog) Stream(ctx context.Context, f service.LogFilter, fn func(models.FurnaceEvent) error) error {
+	if _, err := m.List(ctx, f); err != nil {
+		return err
+	}
+	for _, ev := range m.resp {
+		if err := fn(ev); err != nil {
+			return err
+		}
+	}
+	return m.streamErr
+}
+
+func (m *mockEventLog) Page(ctx context.Context, f service.LogFilter, p service.LogPageParams) (service.LogPage, error) {
+	m.lastPage = p
+	if p.Cursor == "bad" {
+		return service.LogPage{}, service.ErrInvalidCursor
+	}
+	events, err := m.List(ctx, f)
+	return service.LogPage{Events: events, NextCursor: m.next}, err
+}
+
+func (m *mockEventLog) Tail(ctx context.Context, f service.LogFilter, p service.TailParams) (service.LogTail, error) {
+	m.lastTail = p
+	if m.tailWait {
+		<-ctx.Done()
+		return service.LogTail{}, nil
+	}
+	if p.AfterID == "purged" {
+		return service.LogTail{}, service.ErrTailEventNotFound
+	}
+	events, err := m.List(ctx, f)
+	return service.LogTail{Events: events, NextAfterID: m.next}, err
+}
+
+// mockEventFeed is an append-only event log followed through Tail, with
+// the given events gone as if purged.
+type mockEventFeed struct {
+	mu     sync.Mutex
+	events []models.FurnaceEvent
+	purged map[string]bool
+}
+
+func (m *mockEventFeed) append(evs ...models.FurnaceEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, evs...)
+}
+
+func (m *mockEventFeed) Tail(ctx context.Context, f service.LogFilter, p service.TailParams) (service.LogTail, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	start := 0
+	switch {
+	case p.AfterID != "":
+		start = -1
+		for i, ev := range m.events {
+			if ev.EventID == p.AfterID && !m.purged[ev.EventID] {
+				start = i + 1
+			}
+		}
+		if start < 0 {
+			return service.LogTail{}, service.ErrTailEventNotFound
+		}
+	case p.AfterAt.IsZero():
+		var last string
+		if n := len(m.events); n > 0 {
+			last = m.events[n-1].EventID
+		}
+		return service.LogTail{Events: []models.FurnaceEvent{}, NextAfterID: last}, nil
+	}
+	tail := service.LogTail{Events: []models.FurnaceEvent{}, NextAfterID: p.AfterID}
+	for _, ev := range m.events[start:] {
+		if m.purged[ev.EventID] || !ev.OccurredAt.After(p.AfterAt) {
+			continue
+		}
+		tail.Events = append(tail.Events, ev)
+		tail.NextAfterID = ev.EventID
+	}
+	return tail, nil
+}
+
+type mockEventComments struct {
+	got models.EventComment
+	err error
+}
+
+func (m *mockEventComments) AddComment(ctx context.Context, eventID string, userID int, text string) (models.EventComment, error) {
+	m.got = models.EventComment{ID: 1, EventID: eventID, UserID: userID, Text: text}
+	if m.err != nil {
+		return models.EventComment{}, m.err
+	}
+	return m.got, nil
+}
+
+type mockEventDeletions struct {
+	gotID     string
+	gotUserID int
+	gotReason string
+	err       error
+}
+
+func (m *mockEventDeletions) DeleteEvent(ctx context.Context, eventID string, userID int, reason string) error {
+	m.gotID, m.gotUserID, m.gotReason = eventID, userID, reason
+	return m.err
+}
+
+type mockAuditExport struct {
+	got    service.AuditExportRequest
+	body   string // written before err is returned
+	err    error
+	key    service.AuditKey
+	keyErr error
+}
+
+func (m *mockAuditExport) AuditPublicKey() (service.AuditKey, error) {
+	return m.key, m.keyErr
+}
+
+func (m *mockAuditExport) ExportAudit(ctx context.Context, req service.AuditExportRequest, w io.Writer) error {
+	m.got = req
+	if m.body != "" {
+		if _, err := io.WriteString(w, m.body); err != nil {
+			return err
+		}
+	}
+	return m.err
+}
+
+type mockHealth struct {
+	health models.FurnaceHealth
+	err    error
+}
+
+func (m *mockHealth) FurnaceHealth(ctx context.Context) (models.FurnaceHealth, error) {
+	return m.health, m.err
+}
+
+type mockRuns struct {
+	run models.Run
+	err error
+}
+
+func (m *mockRuns) GetRun(ctx context.Context, runID string) (models.Run, error) {
+	if m.err != nil {
+		return models.Run{}, m.err
+	}
+	return m.run, nil
+}
+
+type mockTelemetry struct {
+	resp       []models.TelemetrySample
+	err        error
+	lastFilter service.TelemetryFilter
+}
+
+func (m *mockTelemetry) Samples(ctx context.Context, f service.TelemetryFilter) ([]models.TelemetrySample, error) {
+	m.lastFilter = f
+	return m.resp, m.err
+}
+
+type mockImporter struct {
+	report      service.ImportReport
+	err         error
+	lastKind    string
+	lastMapping string
+	lastBody    string
+}
+
+func (m *mockImporter) read(kind, mapping string, r io.Reader) (service.ImportReport, error) {
+	m.lastKind, m.lastMapping = kind, mapping
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return service.ImportReport{}, err
+	}
+	m.lastBody = string(b)
+	return m.report, m.err
+}
+
+func (m *mockImporter) ImportTelemetry(ctx context.Context, mapping string, r io.Reader) (service.ImportReport, error) {
+	return m.read("telemetry", mapping, r)
+}
+func (m *mockImporter) ImportEvents(ctx context.Context, mapping string, r io.Reader) (service.ImportReport, error) {
+	return m.read("events", mapping, r)
+}
+
+type mockSimTuning struct {
+	settings  models.SimSettings
+	updateErr error
+
+	preview      models.SettingsPreview
+	previewErr   error
+	lastProposed models.SimSettings
+	lastApply    bool
+}
+
+func (m *mockSimTuning) SimSettings() models.SimSettings { return m.settings }
+func (m *mockSimTuning) UpdateSimSettings(ctx context.Context, set models.SimSettings) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	m.settings = set
+	return nil
+}
+func (m *mockSimTuning) PreviewSimSettings(ctx context.Context, set models.SimSettings, apply bool) (models.SettingsPreview, error) {
+	m.lastProposed, m.lastApply = set, apply
+	return m.preview, m.previewErr
+}
+
+type mockChaos struct {
+	settings  repository.ChaosSettings
+	updateErr error
+}
+
+func (m *mockChaos) ChaosSettings() repository.ChaosSettings {
+	return m.settings
+}
+func (m *mockChaos) UpdateChaos(s repository.ChaosSettings) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	m.settings = s
+	return nil
+}
+
+type mockSimClock struct {
+	speed service.Speed
+}
+
+func (m *mockSimClock) Speed() service.Speed { return m.speed }
+func (m *mockSimClock) SetSpeed(sp service.Speed) error {
+	if err := sp.Validate(); err != nil {
+		return err
+	}
+	m.speed = sp
+	return nil
+}
+
+type mockFaults struct {
+	active []service.ActiveFault
+}
+
+func (m *mockFaults) InjectFault(kind string) error {
+	if kind != service.FaultHeaterFailure && kind != service.FaultPowerLoss {
+		return service.ErrUnknownFault
+	}
+	m.active = append(m.active, service.ActiveFault{Type: kind})
+	return nil
+}
+func (m *mockFaults) ClearFault(kind string) error {
+	for i, f := range m.active {
+		if f.Type == kind {
+			m.active = append(m.active[:i], m.active[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+func (m *mockFaults) ClearAllFaults()                     { m.active = nil }
+func (m *mockFaults) ActiveFaults() []service.ActiveFault { return m.active }
+
+type mockSimAmbient struct {
+	status service.AmbientStatus
+}
+
+func (m *mockSimAmbient) Ambient() service.AmbientStatus { return m.status }
+func (m *mockSimAmbient) SetAmbient(tempC float64) error {
+	if tempC < service.MinAmbientC {
+		return service.ErrInvalidAmbient
+	}
+	m.status.TempC, m.status.Source = tempC, service.AmbientSourceOverride
+	return nil
+}
+func (m *mockSimAmbient) ClearAmbient() {
+	m.status.TempC, m.status.Source = m.status.MeanC, service.AmbientSourceProfile
+}
+
 // ---- Shared Test Helpers ----
 
 func newTestRouter(s *service.Service) *gin.Engine {
@@ -106,3 +436,283 @@ func authHeader(token string) http.Header {
 	}
 	return h
 }
+
+type mockHistory struct {
+	hist       models.TemperatureHistory
+	err        error
+	lastFilter service.HistoryFilter
+}
+
+func (m *mockHistory) History(ctx context.Context, f service.HistoryFilter) (models.TemperatureHistory, error) {
+	m.lastFilter = f
+	return m.hist, m.err
+}
+
+type mockProbes struct {
+	report service.ReadinessReport
+}
+
+func (m *mockProbes) Ready(ctx context.Context) service.ReadinessReport { return m.report }
+
+type mockEventAudit struct {
+	report models.ChainReport
+	err    error
+}
+
+func (m *mockEventAudit) VerifyChain(ctx context.Context) (models.ChainReport, error) {
+	return m.report, m.err
+}
+
+type mockRetention struct {
+	report  models.PurgeReport
+	err     error
+	lastReq service.PurgeRequest
+}
+
+func (m *mockRetention) PurgeEvents(ctx context.Context, req service.PurgeRequest) (models.PurgeReport, error) {
+	m.lastReq = req
+	return m.report, m.err
+}
+
+func (m *mockRetention) Run(ctx context.Context) {}
+
+type mockBackups struct {
+	report   models.BackupReport
+	snapshot string
+	err      error
+}
+
+func (m *mockBackups) Backup(ctx context.Context) (models.BackupReport, error) {
+	return m.report, m.err
+}
+
+func (m *mockBackups) OpenBackup(ctx context.Context) (models.BackupReport, io.ReadCloser, error) {
+	if m.err != nil {
+		return models.BackupReport{}, nil, m.err
+	}
+	return m.report, io.NopCloser(strings.NewReader(m.snapshot)), nil
+}
+
+type mockCharges struct {
+	status  service.ChargeStatus
+	err     error
+	lastReq service.ChargeRequest
+}
+
+func (m *mockCharges) Charge() service.ChargeStatus { return m.status }
+
+func (m *mockCharges) InsertCharge(req service.ChargeRequest) (service.ChargeStatus, error) {
+	m.lastReq = req
+	return m.status, m.err
+}
+
+func (m *mockCharges) RemoveCharge() (service.ChargeStatus, error) { return m.status, m.err }
+
+type mockSequences struct {
+	seq       models.Sequence
+	err       error
+	lastSteps []models.SequenceStep
+	lastUser  int
+}
+
+func (m *mockSequences) StartSequence(ctx context.Context, steps []models.SequenceStep, userID int) (models.Sequence, error) {
+	m.lastSteps, m.lastUser = steps, userID
+	return m.seq, m.err
+}
+
+func (m *mockSequences) GetSequence(ctx context.Context, id string) (models.Sequence, error) {
+	if m.err != nil || id != m.seq.ID {
+		return models.Sequence{}, service.ErrSequenceNotFound
+	}
+	return m.seq, nil
+}
+
+func (m *mockSequences) Run(ctx context.Context) {}
+
+type mockAlerts struct {
+	rule       models.AlertRule
+	alerts     []models.Alert
+	err        error
+	lastRule   models.AlertRule
+	lastUserID int
+	lastFilter service.AlertFilter
+}
+
+func (m *mockAlerts) ListRules(ctx context.Context) ([]models.AlertRule, error) {
+	return []models.AlertRule{m.rule}, m.err
+}
+func (m *mockAlerts) GetRule(ctx context.Context, id int) (models.AlertRule, error) {
+	return m.rule, m.err
+}
+func (m *mockAlerts) CreateRule(ctx context.Context, rule models.AlertRule, userID int) (models.AlertRule, error) {
+	m.lastRule, m.lastUserID = rule, userID
+	return m.rule, m.err
+}
+func (m *mockAlerts) UpdateRule(ctx context.Context, rule models.AlertRule) (models.AlertRule, error) {
+	m.lastRule = rule
+	return m.rule, m.err
+}
+func (m *mockAlerts) DeleteRule(ctx context.Context, id int) error { return m.err }
+func (m *mockAlerts) ListAlerts(ctx context.Context, f service.AlertFilter) ([]models.Alert, error) {
+	m.lastFilter = f
+	return m.alerts, m.err
+}
+func (m *mockAlerts) Run(ctx context.Context) {}
+
+type mockIncidents struct {
+	incident   models.Incident
+	err        error
+	lastFilter service.IncidentFilter
+	lastUserID int
+}
+
+func (m *mockIncidents) ListIncidents(ctx context.Context, f service.IncidentFilter) ([]models.Incident, error) {
+	m.lastFilter = f
+	return []models.Incident{m.incident}, m.err
+}
+func (m *mockIncidents) GetIncident(ctx context.Context, id int64) (models.Incident, error) {
+	return m.incident, m.err
+}
+func (m *mockIncidents) AcknowledgeIncident(ctx context.Context, id int64, userID int) (models.Incident, error) {
+	m.lastUserID = userID
+	return m.incident, m.err
+}
+func (m *mockIncidents) Run(ctx context.Context) {}
+
+type mockMaintenance struct {
+	task       models.MaintenanceTask
+	record     models.MaintenanceRecord
+	err        error
+	lastTask   models.MaintenanceTask
+	lastUserID int
+	lastNote   string
+	lastQuery  [2]int // task ID, limit
+}
+
+func (m *mockMaintenance) ListTasks(ctx context.Context) ([]models.MaintenanceTask, error) {
+	return []models.MaintenanceTask{m.task}, m.err
+}
+func (m *mockMaintenance) GetTask(ctx context.Context, id int) (models.MaintenanceTask, error) {
+	return m.task, m.err
+}
+func (m *mockMaintenance) CreateTask(ctx context.Context, t models.MaintenanceTask, userID int) (models.MaintenanceTask, error) {
+	m.lastTask, m.lastUserID = t, userID
+	return m.task, m.err
+}
+func (m *mockMaintenance) UpdateTask(ctx context.Context, t models.MaintenanceTask) (models.MaintenanceTask, error) {
+	m.lastTask = t
+	return m.task, m.err
+}
+func (m *mockMaintenance) DeleteTask(ctx context.Context, id int) error { return m.err }
+func (m *mockMaintenance) CompleteTask(ctx context.Context, id, userID int, note string) (models.MaintenanceRecord, error) {
+	m.lastUserID, m.lastNote = userID, note
+	return m.record, m.err
+}
+func (m *mockMaintenance) ListRecords(ctx context.Context, taskID, limit int) ([]models.MaintenanceRecord, error) {
+	m.lastQuery = [2]int{taskID, limit}
+	return []models.MaintenanceRecord{m.record}, m.err
+}
+func (m *mockMaintenance) Run(ctx context.Context) {}
+
+type mockWebhooks struct {
+	webhook    models.Webhook
+	delivery   models.WebhookDelivery
+	err        error
+	lastHook   models.Webhook
+	lastUserID int
+	lastStatus string
+	lastLimit  int
+	lastReplay [2]int64
+}
+
+func (m *mockWebhooks) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	return []models.Webhook{m.webhook}, m.err
+}
+func (m *mockWebhooks) GetWebhook(ctx context.Context, id int) (models.Webhook, error) {
+	return m.webhook, m.err
+}
+func (m *mockWebhooks) CreateWebhook(ctx context.Context, w models.Webhook, userID int) (models.Webhook, error) {
+	m.lastHook, m.lastUserID = w, userID
+	return m.webhook, m.err
+}
+func (m *mockWebhooks) UpdateWebhook(ctx context.Context, w models.Webhook) (models.Webhook, error) {
+	m.lastHook = w
+	return m.webhook, m.err
+}
+func (m *mockWebhooks) DeleteWebhook(ctx context.Context, id int) error { return m.err }
+func (m *mockWebhooks) ListDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]models.WebhookDelivery, error) {
+	m.lastStatus, m.lastLimit = status, limit
+	return []models.WebhookDelivery{m.delivery}, m.err
+}
+func (m *mockWebhooks) ReplayDelivery(ctx context.Context, webhookID int, deliveryID int64) (models.WebhookDelivery, error) {
+	m.lastReplay = [2]int64{int64(webhookID), deliveryID}
+	return m.delivery, m.err
+}
+func (m *mockWebhooks) Run(ctx context.Context) {}
+
+type mockUptime struct {
+	sum models.UptimeSummary
+	err error
+}
+
+func (m *mockUptime) UptimeSummary(ctx context.Context) (models.UptimeSummary, error) {
+	return m.sum, m.err
+}
+func (m *mockUptime) Run(ctx context.Context) {}
+
+type mockSystem struct {
+	info   service.SystemInfo
+	build  service.BuildInfo
+	panics []service.PanicReport
+	reqID  string // request ID of the context of the last panic
+}
+
+func (m *mockSystem) Info() service.SystemInfo { return m.info }
+func (m *mockSystem) Build() service.BuildInfo { return m.build }
+
+func (m *mockSystem) RecordPanic(ctx context.Context, p service.PanicReport) error {
+	m.panics = append(m.panics, p)
+	m.reqID = service.RequestID(ctx)
+	return nil
+}
+
+type mockLoops struct {
+	status    []models.LoopStatus
+	restarted string
+}
+
+func (m *mockLoops) Go(context.Context, string, func(context.Context)) {}
+
+func (m *mockLoops) Restart(name string) error {
+	for _, l := range m.status {
+		if l.Name == name {
+			m.restarted = name
+			return nil
+		}
+	}
+	return service.ErrUnknownLoop
+}
+
+func (m *mockLoops) LoopStatus() []models.LoopStatus { return m.status }
+
+func (m *mockLoops) Wait() {}
+
+type mockSetup struct {
+	status  service.SetupStatus
+	lastReq service.SetupRequest
+	err     error
+}
+
+func (m *mockSetup) Restore(context.Context) error { return nil }
+
+func (m *mockSetup) SetupStatus(context.Context) (service.SetupStatus, error) {
+	return m.status, nil
+}
+
+func (m *mockSetup) CompleteSetup(_ context.Context, req service.SetupRequest) (string, error) {
+	m.lastReq = req
+	if m.err != nil {
+		return "", m.err
+	}
+	return "admin-token", nil
+}
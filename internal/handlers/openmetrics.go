@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// openMetricsWriter builds an OpenMetrics text exposition.
+type openMetricsWriter struct {
+	sb strings.Builder
+}
+
+// family writes the metadata lines of a gauge. unit must be the suffix of
+// name, or empty.
+func (w *openMetricsWriter) family(name, unit, help string) {
+	fmt.Fprintf(&w.sb, "# TYPE %s gauge\n", name)
+	if unit != "" {
+		fmt.Fprintf(&w.sb, "# UNIT %s %s\n", name, unit)
+	}
+	fmt.Fprintf(&w.sb, "# HELP %s %s\n", name, help)
+}
+
+// sample writes one value; labels alternate names and values.
+func (w *openMetricsWriter) sample(name string, v float64, labels ...string) {
+	w.sb.WriteString(name)
+	if len(labels) > 0 {
+		w.sb.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				w.sb.WriteByte(',')
+			}
+			fmt.Fprintf(&w.sb, "%s=%q", labels[i], labels[i+1])
+		}
+		w.sb.WriteByte('}')
+	}
+	w.sb.WriteByte(' ')
+	w.sb.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	w.sb.WriteByte('\n')
+}
+
+// counter writes a counter family and its one sample; name is without
+// the _total suffix of the sample.
+func (w *openMetricsWriter) counter(name, help string, v float64) {
+	fmt.Fprintf(&w.sb, "# TYPE %s counter\n", name)
+	fmt.Fprintf(&w.sb, "# HELP %s %s\n", name, help)
+	w.sample(name+"_total", v)
+}
+
+func (w *openMetricsWriter) gauge(name, unit, help string, v float64) {
+	w.family(name, unit, help)
+	w.sample(name, v)
+}
+
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// stateOpenMetrics renders st as gauges.
+func stateOpenMetrics(st models.FurnaceState) string {
+	var w openMetricsWriter
+	w.state(st)
+	w.sb.WriteString("# EOF\n")
+	return w.sb.String()
+}
+
+// state writes the gauges of st. Mode is a state set with one series per
+// mode; error codes are one series each, present while active.
+func (w *openMetricsWriter) state(st models.FurnaceState) {
+	w.gauge("furnace_temperature_celsius", "celsius", "True chamber temperature.", st.CurrentTempC)
+	w.gauge("furnace_measured_temperature_celsius", "celsius", "Chamber temperature as reported by the sensor.", st.MeasuredTempC)
+	w.gauge("furnace_ambient_temperature_celsius", "celsius", "Cold-junction temperature.", st.AmbientTempC)
+	w.gauge("furnace_target_temperature_celsius", "celsius", "Target temperature; 0 when none is set.", st.TargetTempC)
+	w.gauge("furnace_remaining_seconds", "seconds", "Time left in the current mode.", float64(st.RemainingSeconds))
+	w.gauge("furnace_running", "", "1 while the furnace is running.", boolGauge(st.IsRunning))
+	w.gauge("furnace_power_kilowatts", "kilowatts", "Instantaneous power draw.", st.PowerKW)
+	w.gauge("furnace_energy_kilowatt_hours", "kilowatt_hours", "Energy consumed by the current or last run.", st.EnergyKWh)
+	w.gauge("furnace_gas_flow_cubic_meters_per_hour", "cubic_meters_per_hour", "Protective gas flow.", st.GasFlowM3h)
+	w.gauge("furnace_oxygen_ppm", "ppm", "Residual oxygen in the chamber.", st.O2PPM)
+
+	w.family("furnace_mode", "", "1 for the current operating mode.")
+	for _, m := range []string{service.ModeHeat, service.ModeCool, service.ModeStandby} {
+		w.sample("furnace_mode", boolGauge(st.Mode == m), "mode", m)
+	}
+	w.family("furnace_error", "", "1 for each active error code.")
+	codes := append([]string(nil), st.ErrorCodes...)
+	sort.Strings(codes)
+	for _, code := range codes {
+		w.sample("furnace_error", 1, "code", code)
+	}
+	if !st.UpdatedAt.IsZero() {
+		w.gauge("furnace_state_updated_timestamp_seconds", "seconds", "When the state was last saved, Unix time.",
+			float64(st.UpdatedAt.UnixMilli())/1000)
+	}
+}
+
+// @Summary      Get furnace state as OpenMetrics
+// @Description  Returns the current state as OpenMetrics gauges for scrapers and scripts that do not parse JSON.
+// @Tags         furnace
+// @Produce      plain
+// @Success      200  {string}  string  "OpenMetrics text exposition"
+// @Failure      401  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/furnace/state.prom [get]
+// @Security     BearerAuth
+func (h *Handler) getStateOpenMetrics(c *gin.Context) {
+	st, err := h.services.Monitoring.GetState(c.Request.Context())
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, errGetState, "furnace_get_state_failed", err)
+		return
+	}
+	c.Data(http.StatusOK, openMetricsContentType, []byte(stateOpenMetrics(st)))
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestGetStateOpenMetrics(t *testing.T) {
+	st := models.FurnaceState{
+		Mode:          "HEAT",
+		CurrentTempC:  612.5,
+		MeasuredTempC: 613,
+		TargetTempC:   800,
+		ErrorCodes:    []string{"SENSOR_FAULT", "OVERHEAT"},
+		IsRunning:     true,
+		UpdatedAt:     time.Date(2025, 9, 20, 10, 0, 0, 250e6, time.UTC),
+	}
+	s := &service.Service{Authorization: &mockAuth{parseID: 1}, Monitoring: &mockMonitoring{state: st}}
+	r := newTestRouter(s)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/furnace/state.prom", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/openmetrics-text") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	body := w.Body.String()
+	for _, line := range []string{
+		"# TYPE furnace_temperature_celsius gauge\n# UNIT furnace_temperature_celsius celsius\n",
+		"furnace_temperature_celsius 612.5\n",
+		"furnace_running 1\n",
+		`furnace_mode{mode="HEAT"} 1` + "\n",
+		`furnace_mode{mode="COOL"} 0` + "\n",
+		`furnace_error{code="OVERHEAT"} 1` + "\n" + `furnace_error{code="SENSOR_FAULT"} 1` + "\n",
+		"furnace_state_updated_timestamp_seconds 1758362400.25\n",
+	} {
+		if !strings.Contains(body, line) {
+			t.Fatalf("missing %q in:\n%s", line, body)
+		}
+	}
+	if !strings.HasSuffix(body, "# EOF\n") {
+		t.Fatalf("exposition must end with # EOF:\n%s", body)
+	}
+}
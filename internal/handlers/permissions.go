@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"controlling_furnace/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Permission is what a caller needs to use an API route.
+type Permission string
+
+const (
+	PermPublic  Permission = "public"  // anyone, without a token
+	PermRead    Permission = "read"    // any valid token
+	PermOperate Permission = "operate" // operator or admin token
+	PermAdmin   Permission = "admin"   // admin token
+)
+
+// ErrInvalidPermissions is returned by Permissions.Validate.
+var ErrInvalidPermissions = errors.New("invalid route permissions")
+
+// defaultPermissions declares the permission of every route below
+// /api/{version}, keyed by method and path relative to the version prefix.
+// Routes of both v1 and v2 share their entry.
+// Registering a route that is missing here panics at router build time.
+var defaultPermissions = map[string]Permission{
+	"POST /furnace/start":       PermOperate,
+	"POST /furnace/stop":        PermOperate,
+	"POST /furnace/mode":        PermOperate,
+	"PUT /furnace/atmosphere":   PermOperate,
+	"GET /furnace/state":        PermRead,
+	"GET /furnace/state.prom":   PermRead,
+	"GET /furnace/readiness":    PermRead,
+	"GET /furnace/health":       PermRead,
+	"GET /furnace/history":      PermRead,
+	"GET /furnace/charge":       PermRead,
+	"POST /furnace/charge":      PermOperate,
+	"DELETE /furnace/charge":    PermOperate,
+	"POST /furnace/sequence":    PermOperate,
+	"GET /furnace/sequence/:id": PermRead,
+
+	// API v2
+	"GET /furnaces":               PermRead,
+	"GET /furnaces/:id":           PermRead,
+	"PATCH /furnaces/:id":         PermOperate,
+	"GET /furnaces/:id/readiness": PermRead,
+	"GET /furnaces/:id/health":    PermRead,
+	"GET /furnaces/:id/history":   PermRead,
+	"GET /furnaces/:id/charge":    PermRead,
+	"POST /furnaces/:id/charge":   PermOperate,
+	"DELETE /furnaces/:id/charge": PermOperate,
+
+	"GET /logs/":                    PermRead,
+	"GET /logs/verify":              PermRead,
+	"GET /logs/schema":              PermRead,
+	"GET /logs/tail":                PermRead,
+	"POST /logs/purge":              PermAdmin,
+	"POST /logs/:event_id/comments": PermOperate,
+	"DELETE /logs/:event_id":        PermAdmin,
+	"GET /runs/:run_id":             PermRead,
+	"GET /telemetry":                PermRead,
+
+	"GET /alerts":              PermRead,
+	"GET /alerts/rules":        PermRead,
+	"GET /alerts/rules/:id":    PermRead,
+	"POST /alerts/rules":       PermOperate,
+	"PUT /alerts/rules/:id":    PermOperate,
+	"DELETE /alerts/rules/:id": PermOperate,
+
+	"GET /incidents":            PermRead,
+	"GET /incidents/:id":        PermRead,
+	"GET /incidents/:id/export": PermRead,
+	"POST /incidents/:id/ack":   PermOperate,
+
+	"GET /maintenance/tasks":               PermRead,
+	"GET /maintenance/tasks/:id":           PermRead,
+	"POST /maintenance/tasks":              PermOperate,
+	"PUT /maintenance/tasks/:id":           PermOperate,
+	"DELETE /maintenance/tasks/:id":        PermOperate,
+	"POST /maintenance/tasks/:id/complete": PermOperate,
+	"GET /maintenance/records":             PermRead,
+
+	// webhooks send the event log off-site
+	"GET /webhooks":                PermAdmin,
+	"GET /webhooks/:id":            PermAdmin,
+	"POST /webhooks":               PermAdmin,
+	"PUT /webhooks/:id":            PermAdmin,
+	"DELETE /webhooks/:id":         PermAdmin,
+	"GET /webhooks/:id/deliveries": PermAdmin,
+	"POST /webhooks/:id/deliveries/:delivery_id/replay": PermAdmin,
+
+	"POST /sim/faults":         PermOperate,
+	"GET /sim/faults":          PermOperate,
+	"DELETE /sim/faults":       PermOperate,
+	"DELETE /sim/faults/:type": PermOperate,
+	"GET /sim/speed":           PermOperate,
+	"PUT /sim/speed":           PermOperate,
+	"GET /sim/config":          PermOperate,
+	"PUT /sim/config":          PermOperate,
+	"GET /sim/ambient":         PermOperate,
+	"PUT /sim/ambient":         PermOperate,
+	"DELETE /sim/ambient":      PermOperate,
+
+	"GET /admin/chaos":                PermAdmin,
+	"PUT /admin/chaos":                PermAdmin,
+	"POST /admin/import/:kind":        PermAdmin,
+	"GET /admin/loops":                PermAdmin,
+	"POST /admin/loops/:name/restart": PermAdmin,
+	"POST /admin/config/preview":      PermAdmin,
+	"GET /admin/audit/export":         PermAdmin,
+	"GET /admin/audit/key":            PermAdmin,
+	"POST /admin/backup":              PermAdmin,
+	"PUT /admin/users/:id/role":       PermAdmin,
+
+	"GET /system/info": PermAdmin,
+
+	// Setup is open only while no users exist; the service enforces that.
+	"GET /setup":  PermPublic,
+	"POST /setup": PermPublic,
+}
+
+// RoutePermission overrides the permission of one route.
+type RoutePermission struct {
+	// Route is the method and path below /api/{version} as declared in
+	// the routes table, e.g. "GET /furnace/state".
+	Route   string     `mapstructure:"route"`
+	Require Permission `mapstructure:"require"`
+}
+
+// Permissions overrides the default route permissions.
+type Permissions []RoutePermission
+
+// Validate checks that every override names a known route and permission.
+// Only GET routes may be opened to anonymous callers.
+func (p Permissions) Validate() error {
+	for _, rp := range p {
+		key := normalizeRoute(rp.Route)
+		if _, ok := defaultPermissions[key]; !ok {
+			return fmt.Errorf("%w: unknown route %q", ErrInvalidPermissions, rp.Route)
+		}
+		switch rp.Require {
+		case PermRead, PermOperate, PermAdmin:
+		case PermPublic:
+			if !strings.HasPrefix(key, http.MethodGet+" ") {
+				return fmt.Errorf("%w: %q: only GET routes can be public", ErrInvalidPermissions, rp.Route)
+			}
+		default:
+			return fmt.Errorf("%w: %q: unknown permission %q", ErrInvalidPermissions, rp.Route, rp.Require)
+		}
+	}
+	return nil
+}
+
+// table returns the defaults with the overrides applied.
+func (p Permissions) table() map[string]Permission {
+	out := make(map[string]Permission, len(defaultPermissions))
+	for k, v := range defaultPermissions {
+		out[k] = v
+	}
+	for _, rp := range p {
+		out[normalizeRoute(rp.Route)] = rp.Require
+	}
+	return out
+}
+
+// normalizeRoute upper-cases the method and collapses the blanks of a
+// "METHOD /path" key.
+func normalizeRoute(route string) string {
+	method, path, _ := strings.Cut(strings.TrimSpace(route), " ")
+	return strings.ToUpper(method) + " " + strings.TrimSpace(path)
+}
+
+// handle registers an API route behind the middleware its permission
+// requires. A GET route answers HEAD as well, under the same permission.
+func (h *Handler) handle(g *gin.RouterGroup, method, path string, fn gin.HandlerFunc) {
+	// strip /api/{version}
+	full := g.BasePath() + path
+	rel := "/" + strings.SplitN(strings.TrimPrefix(full, "/api/"), "/", 2)[1]
+	key := method + " " + rel
+	perm, ok := h.perms[key]
+	if !ok {
+		panic("handlers: no permission declared for " + key)
+	}
+	chain := append(h.guard(perm), h.limits.forRoute(method, perm).middleware()...)
+	handlers := append(chain, fn)
+	g.Handle(method, path, handlers...)
+	if method == http.MethodGet {
+		g.Handle(http.MethodHead, path, handlers...)
+	}
+}
+
+// allows reports whether a token with role may use a route requiring p,
+// matching guard.
+func (p Permission) allows(role string) bool {
+	switch p {
+	case PermPublic, PermRead:
+		return true
+	case PermOperate:
+		return role == models.RoleAdmin || role == models.RoleOperator
+	default:
+		return role == models.RoleAdmin
+	}
+}
+
+// guard returns the middleware enforcing perm.
+func (h *Handler) guard(perm Permission) []gin.HandlerFunc {
+	switch perm {
+	case PermPublic:
+		return nil
+	case PermRead:
+		return []gin.HandlerFunc{h.userIdMiddleware}
+	case PermOperate:
+		return []gin.HandlerFunc{h.userIdMiddleware, h.requireRole(models.RoleAdmin, models.RoleOperator)}
+	default:
+		return []gin.HandlerFunc{h.userIdMiddleware, h.requireRole(models.RoleAdmin)}
+	}
+}
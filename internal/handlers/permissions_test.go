@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPermissions_ViewerCannotControlFurnace(t *testing.T) {
+	fu := &mockFurnace{}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleViewer},
+		Monitoring:    &mockMonitoring{state: models.FurnaceState{Mode: "IDLE"}},
+		Furnace:       fu,
+	}
+	r := newTestRouter(s)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/furnace/start", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden || fu.startCalled != 0 {
+		t.Fatalf("expected 403 without starting, got %d (calls=%d)", w.Code, fu.startCalled)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/furnace/state", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for viewer reading state, got %d", w.Code)
+	}
+}
+
+func TestPermissions_PublicStateKeepsControlLocked(t *testing.T) {
+	fu := &mockFurnace{}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1},
+		Monitoring:    &mockMonitoring{state: models.FurnaceState{Mode: "HEAT", CurrentTempC: 500}},
+		Furnace:       fu,
+	}
+	gin.SetMode(gin.TestMode)
+	r := NewHandlerWithConfig(s, nil, Config{
+		Permissions: Permissions{{Route: "get /furnace/state", Require: PermPublic}},
+	}).InitRoutes()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/furnace/state", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"current_temp_c":500`) {
+		t.Fatalf("expected anonymous state, got %d: %s", w.Code, w.Body.String())
+	}
+
+	for _, path := range []string{"/api/v1/furnace/start", "/api/v1/furnace/stop"} {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("%s: expected 401 without token, got %d", path, w.Code)
+		}
+	}
+	if fu.startCalled != 0 || fu.stopCalled != 0 {
+		t.Fatalf("control routes reached the service anonymously")
+	}
+}
+
+func TestPermissions_Validate(t *testing.T) {
+	if err := (Permissions{{Route: "GET /furnace/state", Require: PermPublic}}).Validate(); err != nil {
+		t.Fatalf("expected valid override, got %v", err)
+	}
+	for _, p := range []RoutePermission{
+		{Route: "GET /furnace/nope", Require: PermRead},
+		{Route: "GET /furnace/state", Require: "root"},
+		{Route: "POST /furnace/start", Require: PermPublic},
+	} {
+		if err := (Permissions{p}).Validate(); !errors.Is(err, ErrInvalidPermissions) {
+			t.Fatalf("%+v: expected ErrInvalidPermissions, got %v", p, err)
+		}
+	}
+}
+
+func TestPermissions_TableMatchesRoutes(t *testing.T) {
+	r := newTestRouter(&service.Service{})
+	seen := make(map[string]bool)
+	for _, rt := range r.Routes() {
+		for _, version := range []string{"/api/v1", "/api/v2"} {
+			if rest, ok := strings.CutPrefix(rt.Path, version); ok {
+				seen[rt.Method+" "+rest] = true
+			}
+		}
+	}
+	for key := range defaultPermissions {
+		if !seen[key] {
+			t.Errorf("permission declared for unregistered route %q", key)
+		}
+	}
+}
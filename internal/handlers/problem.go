@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"controlling_furnace/internal/repository"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contentTypeProblem is the media type of error answers (RFC 7807).
+const contentTypeProblem = "application/problem+json"
+
+// problemTypePrefix makes a problem's type URI from its code.
+const problemTypePrefix = "urn:furnace:problem:"
+
+// Problem is the body of every error answer, an RFC 7807 problem detail.
+// Code is stable and meant for programs; Title and Detail are for people
+// and may be reworded.
+type Problem struct {
+	// URI naming the kind of problem: urn:furnace:problem:<code>
+	Type string `json:"type" example:"urn:furnace:problem:event_not_found"`
+	// Text of the status code
+	Title  string `json:"title" example:"Not Found"`
+	Status int    `json:"status" example:"404"`
+	// What went wrong with this request
+	Detail string `json:"detail,omitempty" example:"event not found"`
+	// Path of the request
+	Instance string `json:"instance,omitempty" example:"/api/v1/logs/e42/comments"`
+	// Machine-readable cause, e.g. invalid_body or event_not_found
+	Code string `json:"code" example:"event_not_found"`
+	// Rule a rejected username broke, e.g. too_long
+	Reason string `json:"reason,omitempty" example:"too_long"`
+	// The same as detail, for clients of the earlier {"error": ...} body
+	Error string `json:"error" example:"event not found"`
+	// The English detail, when detail was translated after Accept-Language
+	DetailEN string `json:"detail_en,omitempty" example:"event not found"`
+	// X-Request-ID of the request, to quote when reporting an internal error
+	RequestID string `json:"request_id,omitempty" example:"7b0c6f2e-4b7c-4f8e-9a51-2d1f0f6c9e3a"`
+}
+
+// Codes of problems the handlers find themselves; those of service errors
+// are in problemCodes.
+const (
+	codeInvalidRequest          = "invalid_request"
+	codeInvalidBody             = "invalid_body"
+	codeInvalidQuery            = "invalid_query"
+	codeInvalidID               = "invalid_id"
+	codeUnauthorized            = "unauthorized"
+	codeMissingToken            = "missing_token"
+	codeInvalidToken            = "invalid_token"
+	codeInvalidCredentials      = "invalid_credentials"
+	codeForbidden               = "forbidden"
+	codeInsufficientPermissions = "insufficient_permissions"
+	codeReadOnlyDemo            = "read_only_demo"
+	codeNotFound                = "not_found"
+	codeMethodNotAllowed        = "method_not_allowed"
+	codeFurnaceNotFound         = "furnace_not_found"
+	codeSiteNotFound            = "site_not_found"
+	codeConflict                = "conflict"
+	codeTooLarge                = "payload_too_large"
+	codeRateLimited             = "rate_limited"
+	codeInternal                = "internal"
+	codeNotImplemented          = "not_implemented"
+	codeUnavailable             = "unavailable"
+	codeDatabaseBusy            = "database_busy"
+	codeFeatureDisabled         = "feature_disabled"
+	codeUnknownProfile          = "unknown_compat_profile"
+	codeInvalidSchemaVersion    = "invalid_schema_version"
+)
+
+// statusCodes are the codes of problems nothing more specific is known of.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            codeInvalidRequest,
+	http.StatusUnauthorized:          codeUnauthorized,
+	http.StatusForbidden:             codeForbidden,
+	http.StatusNotFound:              codeNotFound,
+	http.StatusMethodNotAllowed:      codeMethodNotAllowed,
+	http.StatusConflict:              codeConflict,
+	http.StatusRequestEntityTooLarge: codeTooLarge,
+	http.StatusTooManyRequests:       codeRateLimited,
+	http.StatusInternalServerError:   codeInternal,
+	http.StatusNotImplemented:        codeNotImplemented,
+	http.StatusServiceUnavailable:    codeUnavailable,
+}
+
+// problemCodes name the service and repository errors clients tell apart.
+// The first one err wraps gives the code.
+var problemCodes = []struct {
+	err  error
+	code string
+}{
+	{service.ErrInvalidAlertRule, "invalid_alert_rule"},
+	{service.ErrAlertRuleNotFound, "alert_rule_not_found"},
+	{service.ErrInvalidAtmosphere, "invalid_atmosphere"},
+	{service.ErrInvalidAuditExport, "invalid_audit_export"},
+	{service.ErrAuditKeyNotConfigured, "audit_key_not_configured"},
+	{service.ErrSetupRequired, "setup_required"},
+	{service.ErrInvalidUsername, "invalid_username"},
+	{service.ErrUsernameTaken, "username_taken"},
+	{service.ErrInvalidRole, "invalid_role"},
+	{service.ErrUserNotFound, "user_not_found"},
+	{service.ErrLastAdmin, "last_admin"},
+	{service.ErrBackupNotConfigured, "backup_not_configured"},
+	{repository.ErrBackupUnsupported, "backup_unsupported"},
+	{repository.ErrInvalidChaosSettings, "invalid_chaos_settings"},
+	{service.ErrInvalidCharge, "invalid_charge"},
+	{service.ErrChargeLoaded, "charge_loaded"},
+	{service.ErrNoCharge, "no_charge"},
+	{service.ErrInvalidSequence, "invalid_sequence"},
+	{service.ErrSequenceRunning, "sequence_running"},
+	{service.ErrSequenceNotFound, "sequence_not_found"},
+	{service.ErrInvalidComment, "invalid_comment"},
+	{service.ErrInvalidDeletion, "invalid_deletion"},
+	{service.ErrEventDeleted, "event_deleted"},
+	{service.ErrEventNotFound, "event_not_found"},
+	{service.ErrInvalidCursor, "invalid_cursor"},
+	{service.ErrInvalidMetaFilter, "invalid_meta_filter"},
+	{service.ErrInvalidSeverity, "invalid_severity"},
+	{service.ErrTailEventNotFound, "tail_event_not_found"},
+	{service.ErrInvalidPurge, "invalid_purge"},
+	{service.ErrUnknownFault, "unknown_fault"},
+	{service.ErrInvalidHistoryQuery, "invalid_history_query"},
+	{service.ErrInvalidImport, "invalid_import"},
+	{service.ErrUnknownMapping, "unknown_mapping"},
+	{service.ErrIncidentNotFound, "incident_not_found"},
+	{service.ErrIncidentAcknowledged, "incident_acknowledged"},
+	{service.ErrInvalidMaintenanceTask, "invalid_maintenance_task"},
+	{service.ErrMaintenanceTaskNotFound, "maintenance_task_not_found"},
+	{service.ErrInvalidAmbient, "invalid_ambient"},
+	{service.ErrRunNotFound, "run_not_found"},
+	{service.ErrSetupDone, "setup_done"},
+	{service.ErrInvalidSetup, "invalid_setup"},
+	{service.ErrInvalidSimSettings, "invalid_sim_settings"},
+	{service.ErrInvalidSpeed, "invalid_speed"},
+	{service.ErrUnknownLoop, "unknown_loop"},
+	{service.ErrUnknownChannel, "unknown_channel"},
+	{service.ErrInvalidWebhook, "invalid_webhook"},
+	{service.ErrWebhookNotFound, "webhook_not_found"},
+	{service.ErrDeliveryNotFound, "webhook_delivery_not_found"},
+	{service.ErrDeliveryNotFailed, "delivery_not_replayable"},
+	{errInvalidResume, "invalid_resume"},
+}
+
+// problemCode is the code of err, or the generic one of status.
+func problemCode(status int, err error) string {
+	for _, pc := range problemCodes {
+		if errors.Is(err, pc.err) {
+			return pc.code
+		}
+	}
+	return statusCodes[status]
+}
+
+// newProblem is the problem detail of an answer with status; an empty
+// code is the generic one of status. The detail is in the request's
+// language where a translation exists (see i18n.go).
+func newProblem(c *gin.Context, status int, code, detail string) Problem {
+	if code == "" {
+		code = statusCodes[status]
+	}
+	p := Problem{
+		Type:     problemTypePrefix + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		Code:     code,
+		Error:    detail,
+	}
+	localize(c, &p)
+	return p
+}
+
+// writeProblem answers with p as application/problem+json.
+func writeProblem(c *gin.Context, p Problem) {
+	c.Header("Content-Type", contentTypeProblem)
+	c.JSON(p.Status, p)
+}
+
+// problem answers with status and a problem detail of code.
+func problem(c *gin.Context, status int, code, detail string) {
+	writeProblem(c, newProblem(c, status, code, detail))
+}
+
+// problemFor answers with status and err as the detail, coded after the
+// error err wraps.
+func problemFor(c *gin.Context, status int, err error) {
+	problem(c, status, problemCode(status, err), err.Error())
+}
+
+// abortProblem is problem for middleware: the handlers after it don't run.
+func abortProblem(c *gin.Context, status int, code, detail string) {
+	problem(c, status, code, detail)
+	c.Abort()
+}
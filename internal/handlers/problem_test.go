@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) Problem {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, contentTypeProblem) {
+		t.Fatalf("Content-Type = %q, want %s", ct, contentTypeProblem)
+	}
+	var p Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("decode %s: %v", w.Body.String(), err)
+	}
+	if p.Status != w.Code || p.Title != http.StatusText(w.Code) || p.Type != problemTypePrefix+p.Code || p.Error != p.Detail {
+		t.Fatalf("problem %+v for status %d", p, w.Code)
+	}
+	return p
+}
+
+func TestProblem_ErrorsAnswerWithACode(t *testing.T) {
+	auth := &mockAuth{parseID: 7, parseRole: models.RoleAdmin}
+	deletions := &mockEventDeletions{}
+	r := newTestRouter(&service.Service{Authorization: auth, EventDeletions: deletions})
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", token)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodDelete, "/api/v1/logs/e1", "", "")
+	if p := decodeProblem(t, w); w.Code != http.StatusUnauthorized || p.Code != codeMissingToken || p.Instance != "/api/v1/logs/e1" {
+		t.Fatalf("without a token: %d %+v", w.Code, p)
+	}
+	w = do(http.MethodDelete, "/api/v1/logs/e1", "Bearer valid", "{")
+	if p := decodeProblem(t, w); w.Code != http.StatusBadRequest || p.Code != codeInvalidBody {
+		t.Fatalf("broken body: %d %+v", w.Code, p)
+	}
+
+	// a wrapped service error keeps the code of its sentinel
+	deletions.err = fmt.Errorf("%w: e1", service.ErrEventDeleted)
+	w = do(http.MethodDelete, "/api/v1/logs/e1", "Bearer valid", `{"reason":"test entry"}`)
+	if p := decodeProblem(t, w); w.Code != http.StatusConflict || p.Code != "event_deleted" || p.Detail != "event already deleted: e1" {
+		t.Fatalf("deleted event: %d %+v", w.Code, p)
+	}
+
+	auth.parseRole = models.RoleOperator
+	w = do(http.MethodDelete, "/api/v1/logs/e1", "Bearer valid", `{"reason":"test entry"}`)
+	if p := decodeProblem(t, w); w.Code != http.StatusForbidden || p.Code != codeInsufficientPermissions {
+		t.Fatalf("operator: %d %+v", w.Code, p)
+	}
+}
+
+func TestProblem_RejectedUsernameCarriesTheReason(t *testing.T) {
+	auth := &mockAuth{signUpErr: &service.UsernameError{Reason: service.UsernameTooLong, Detail: "at most 32 characters"}}
+	r := newTestRouter(&service.Service{Authorization: auth})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/auth/sign-up", strings.NewReader(`{"username":"ann","password":"secret"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if p := decodeProblem(t, w); w.Code != http.StatusBadRequest || p.Code != "invalid_username" || p.Reason != service.UsernameTooLong {
+		t.Fatalf("sign-up: %d %+v", w.Code, p)
+	}
+}
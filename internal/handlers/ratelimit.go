@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit is a token bucket: Rate requests per second on average, with
+// bursts of up to Burst. A zero Rate leaves the routes unlimited.
+type RateLimit struct {
+	Rate  float64 `mapstructure:"rate"`
+	Burst int     `mapstructure:"burst"`
+}
+
+// RateLimitConfig limits how often one caller may use the API. Signed-in
+// callers are counted per user, others per client IP.
+type RateLimitConfig struct {
+	// Auth limits /auth/* (sign-up and sign-in) per client IP, against
+	// password guessing.
+	Auth RateLimit `mapstructure:"auth"`
+	// Control limits the API routes that change something (every method
+	// but GET needing operate or admin, and setup).
+	Control RateLimit `mapstructure:"control"`
+	// Read limits the other API routes, against runaway polling.
+	Read RateLimit `mapstructure:"read"`
+	// TrustedProxies are the addresses or CIDRs of reverse proxies whose
+	// X-Forwarded-For names the client. Empty: the peer address is the
+	// client.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
+// ErrInvalidRateLimit is returned by RateLimitConfig.Validate.
+var ErrInvalidRateLimit = errors.New("invalid rate limit")
+
+// Validate checks the limits and the proxy addresses.
+func (c RateLimitConfig) Validate() error {
+	for name, l := range map[string]RateLimit{"auth": c.Auth, "control": c.Control, "read": c.Read} {
+		if l.Rate < 0 || math.IsInf(l.Rate, 0) || math.IsNaN(l.Rate) {
+			return fmt.Errorf("%w: %s.rate must be a non-negative number", ErrInvalidRateLimit, name)
+		}
+		if l.Rate > 0 && l.Burst < 1 {
+			return fmt.Errorf("%w: %s.burst must be at least 1", ErrInvalidRateLimit, name)
+		}
+	}
+	for _, p := range c.TrustedProxies {
+		if net.ParseIP(p) == nil {
+			if _, _, err := net.ParseCIDR(p); err != nil {
+				return fmt.Errorf("%w: trusted proxy %q is neither an address nor a CIDR", ErrInvalidRateLimit, p)
+			}
+		}
+	}
+	return nil
+}
+
+// idleSweep is how often buckets that have filled up again are dropped.
+const idleSweep = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter holds one token bucket per caller.
+type limiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+// newLimiter returns the limiter of l, or nil for no limit.
+func newLimiter(l RateLimit) *limiter {
+	if l.Rate <= 0 {
+		return nil
+	}
+	return &limiter{rate: l.Rate, burst: float64(l.Burst), now: time.Now, buckets: map[string]*bucket{}}
+}
+
+// allow takes a token from key's bucket. Without one it reports how long
+// until the next is due.
+func (l *limiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if now.Sub(l.swept) >= idleSweep {
+		l.sweep(now)
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+// sweep drops the buckets that are full by now: a new one is the same.
+func (l *limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.swept = now
+}
+
+// rateLimits are the limiters of the route classes; nil ones don't limit.
+type rateLimits struct {
+	auth, control, read *limiter
+}
+
+func newRateLimits(c RateLimitConfig) rateLimits {
+	return rateLimits{auth: newLimiter(c.Auth), control: newLimiter(c.Control), read: newLimiter(c.Read)}
+}
+
+// forRoute is the limiter of an API route with method and permission.
+func (r rateLimits) forRoute(method string, perm Permission) *limiter {
+	if method != http.MethodGet && perm != PermRead {
+		return r.control
+	}
+	return r.read
+}
+
+// middleware returns the middleware counting requests against l, none
+// for a nil l. Behind userIdMiddleware it counts per user.
+func (l *limiter) middleware() []gin.HandlerFunc {
+	if l == nil {
+		return nil
+	}
+	return []gin.HandlerFunc{func(c *gin.Context) {
+		key := "ip:" + c.ClientIP()
+		if id, ok := c.Get(ctxKeyUserID); ok {
+			key = fmt.Sprintf("user:%v", id)
+		}
+		ok, wait := l.allow(key)
+		if ok {
+			c.Next()
+			return
+		}
+		secs := int(math.Ceil(wait.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(max(secs, 1)))
+		abortProblem(c, http.StatusTooManyRequests, codeRateLimited, "too many requests, try again later")
+	}}
+}
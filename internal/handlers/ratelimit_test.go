@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestLimiter_RefillsAtTheRate(t *testing.T) {
+	now := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	l := newLimiter(RateLimit{Rate: 0.5, Burst: 2})
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.allow("a"); !ok {
+			t.Fatalf("request %d of the burst refused", i+1)
+		}
+	}
+	if ok, wait := l.allow("a"); ok || wait != 2*time.Second {
+		t.Fatalf("over the burst: allowed %v, wait %v; want a 2s wait", ok, wait)
+	}
+	if ok, _ := l.allow("b"); !ok {
+		t.Fatal("another caller shares the bucket")
+	}
+	now = now.Add(time.Second)
+	if ok, wait := l.allow("a"); ok || wait != time.Second {
+		t.Fatalf("half refilled: allowed %v, wait %v", ok, wait)
+	}
+	now = now.Add(time.Second)
+	if ok, _ := l.allow("a"); !ok {
+		t.Fatal("refilled token refused")
+	}
+
+	// full buckets are dropped once idle
+	now = now.Add(idleSweep)
+	l.allow("c")
+	if len(l.buckets) != 1 {
+		t.Fatalf("%d buckets after the sweep, want only c's", len(l.buckets))
+	}
+	if newLimiter(RateLimit{}) != nil {
+		t.Fatal("a zero rate should not limit")
+	}
+}
+
+func TestRateLimitConfig_Validate(t *testing.T) {
+	for _, cfg := range []RateLimitConfig{
+		{Auth: RateLimit{Rate: -1}},
+		{Read: RateLimit{Rate: 1}},
+		{TrustedProxies: []string{"proxy.local"}},
+	} {
+		if err := cfg.Validate(); !errors.Is(err, ErrInvalidRateLimit) {
+			t.Fatalf("%+v: err %v, want ErrInvalidRateLimit", cfg, err)
+		}
+	}
+	ok := RateLimitConfig{Control: RateLimit{Rate: 2, Burst: 10}, TrustedProxies: []string{"10.0.0.1", "192.168.0.0/16"}}
+	if err := ok.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateLimit_AnswersTooManyRequests(t *testing.T) {
+	auth := &mockAuth{parseID: 7, parseRole: models.RoleOperator, genTokenErr: errors.New("wrong password")}
+	s := &service.Service{Authorization: auth, Furnace: &mockFurnace{}, Monitoring: &mockMonitoring{}}
+	r := NewHandlerWithConfig(s, nil, Config{RateLimit: RateLimitConfig{
+		Auth:    RateLimit{Rate: 0.1, Burst: 2},
+		Control: RateLimit{Rate: 0.1, Burst: 1},
+	}}).InitRoutes()
+	do := func(method, path, ip string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(`{"username":"ann","password":"guess"}`))
+		req.RemoteAddr = ip + ":40000"
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := do(http.MethodPost, "/auth/sign-in", "10.0.0.1"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("sign-in %d: %d", i+1, w.Code)
+		}
+	}
+	w := do(http.MethodPost, "/auth/sign-in", "10.0.0.1")
+	if p := decodeProblem(t, w); w.Code != http.StatusTooManyRequests || p.Code != codeRateLimited || w.Header().Get("Retry-After") != "10" {
+		t.Fatalf("third sign-in: %d %+v, Retry-After %q", w.Code, p, w.Header().Get("Retry-After"))
+	}
+	// forwarded addresses count only from a trusted proxy
+	req := httptest.NewRequest(http.MethodPost, "/auth/sign-in", strings.NewReader(`{"username":"ann","password":"guess"}`))
+	req.RemoteAddr = "10.0.0.1:40000"
+	req.Header.Set("X-Forwarded-For", "10.9.9.9")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("spoofed X-Forwarded-For: %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/auth/sign-in", "10.0.0.2"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("another address: %d", w.Code)
+	}
+
+	// control routes count per user, whatever the address; reads are unlimited
+	if w := do(http.MethodPost, "/api/v1/furnace/stop", "10.0.0.3"); w.Code != http.StatusOK {
+		t.Fatalf("first stop: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, "/api/v1/furnace/stop", "10.0.0.4"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second stop from another address: %d", w.Code)
+	}
+	auth.parseID = 8
+	if w := do(http.MethodPost, "/api/v1/furnace/stop", "10.0.0.3"); w.Code != http.StatusOK {
+		t.Fatalf("another user's stop: %d", w.Code)
+	}
+	for i := 0; i < 5; i++ {
+		if w := do(http.MethodGet, "/api/v1/furnace/state", "10.0.0.3"); w.Code != http.StatusOK {
+			t.Fatalf("read %d: %d", i+1, w.Code)
+		}
+	}
+}
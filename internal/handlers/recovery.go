@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"syscall"
+
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recovery answers a panic in the handlers after it with a 500 problem
+// carrying the request ID, logs it with its stack, and has the system
+// service count it and log an INTERNAL_ERROR event. A panic over a
+// connection the client dropped is only logged: there is nobody to answer.
+func (h *Handler) recovery(c *gin.Context) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+		if v == http.ErrAbortHandler {
+			// net/http's way to abort a response; it logs nothing
+			panic(v)
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		err, _ := v.(error)
+		if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+			if h.log != nil {
+				h.requestLog(c).Warnw("handler_connection_lost", "err", err, "method", c.Request.Method, "route", route)
+			}
+			c.Abort()
+			return
+		}
+
+		if h.log != nil {
+			h.requestLog(c).Errorw("handler_panic", "panic", v, "method", c.Request.Method, "route", route,
+				"stack", string(debug.Stack()))
+		}
+		if h.services.System != nil {
+			// the event outlives the request, and keeps its request ID
+			ctx := context.WithoutCancel(c.Request.Context())
+			rep := service.PanicReport{Value: fmt.Sprint(v), Method: c.Request.Method, Route: route}
+			if err := h.services.System.RecordPanic(ctx, rep); err != nil && h.log != nil {
+				h.requestLog(c).Errorw("handler_panic_event_failed", "err", err)
+			}
+		}
+		if c.Writer.Written() {
+			// too late for a problem; the client sees a cut response
+			c.Abort()
+			return
+		}
+		p := newProblem(c, http.StatusInternalServerError, codeInternal, "internal server error")
+		p.RequestID = c.GetString(ctxKeyRequestID)
+		writeProblem(c, p)
+		c.Abort()
+	}()
+	c.Next()
+}
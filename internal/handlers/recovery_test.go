@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"controlling_furnace/internal/logger"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRecovery_AnswersProblemAndReportsPanic(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	sys := &mockSystem{}
+	h := NewHandler(&service.Service{System: sys}, &logger.Logger{SugaredLogger: zap.New(core).Sugar()})
+	r := h.InitRoutes()
+	r.GET("/boom/:id", func(*gin.Context) { panic("nil map write") })
+	r.GET("/half", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("after the header")
+	})
+	r.GET("/gone", func(*gin.Context) {
+		panic(&net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.EPIPE)})
+	})
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(requestIDHeader, "req-42")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/boom/7")
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d", w.Code)
+	}
+	if p := decodeProblem(t, w); p.Code != codeInternal || p.RequestID != "req-42" || strings.Contains(p.Detail, "nil map") {
+		t.Fatalf("problem %+v", p)
+	}
+	want := service.PanicReport{Value: "nil map write", Method: http.MethodGet, Route: "/boom/:id"}
+	if len(sys.panics) != 1 || sys.panics[0] != want || sys.reqID != "req-42" {
+		t.Fatalf("reported %+v (request %q)", sys.panics, sys.reqID)
+	}
+	entries := logs.FilterMessage("handler_panic").All()
+	if len(entries) != 1 || entries[0].Level != zapcore.ErrorLevel {
+		t.Fatalf("logged %+v", logs.All())
+	}
+	fields := entries[0].ContextMap()
+	if fields["requestId"] != "req-42" || !strings.Contains(fields["stack"].(string), "recovery_test.go") {
+		t.Fatalf("log fields %v", fields)
+	}
+
+	// once the response has started it can only be cut short
+	if w := get("/half"); w.Code != http.StatusOK || w.Body.String() != "partial" {
+		t.Fatalf("half-written: %d %q", w.Code, w.Body.String())
+	}
+	if len(sys.panics) != 2 {
+		t.Fatalf("reported %+v", sys.panics)
+	}
+
+	// a client that hung up is not an internal error
+	get("/gone")
+	if len(sys.panics) != 2 || logs.FilterMessage("handler_connection_lost").Len() != 1 {
+		t.Fatalf("connection loss reported as a panic: %+v", sys.panics)
+	}
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"controlling_furnace/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Representations of states and events offered besides JSON, for
+// integrations such as legacy MES that cannot read it.
+const (
+	contentTypeXML = "application/xml"
+	contentTypeCSV = "text/csv"
+)
+
+// nextCursorHeader carries the next page's cursor of a log page rendered
+// as XML or CSV, which have no place for it in the body.
+const nextCursorHeader = "X-Next-Cursor"
+
+// renderFormat picks the representation of a state or log answer from the
+// Accept header. Clients accepting none of the offered types get JSON, as
+// they did before XML and CSV were offered.
+func renderFormat(c *gin.Context) string {
+	switch c.NegotiateFormat(gin.MIMEJSON, contentTypeXML, gin.MIMEXML2, contentTypeCSV) {
+	case contentTypeXML, gin.MIMEXML2:
+		return contentTypeXML
+	case contentTypeCSV:
+		return contentTypeCSV
+	}
+	return gin.MIMEJSON
+}
+
+// table is a list of states or events laid out for XML and CSV: one
+// element or row per record, with the fields in struct order.
+type table struct {
+	// XML element names of the list and of a record; without a record
+	// element the one record's fields go directly in the root
+	root, element string
+	rows          []map[string]any
+	columns       []string // JSON names, in struct order
+}
+
+// newTable decodes records, states or events, as rendered at the schema
+// version the request is pinned to. Fields that version lacks have no
+// column.
+func newTable(c *gin.Context, root, element string, records any) (table, error) {
+	t := table{root: root, element: element}
+	v := c.GetInt(ctxKeySchemaVersion)
+	doc, err := versioned(records, v)
+	if err != nil {
+		return t, err
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return t, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&t.rows); err != nil {
+		return t, err
+	}
+
+	typ := reflect.TypeOf(records).Elem()
+	event := typ == reflect.TypeOf(models.FurnaceEvent{})
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || (v != 0 && !models.InSchemaVersion(name, event, v)) {
+			continue
+		}
+		t.columns = append(t.columns, name)
+	}
+	return t, nil
+}
+
+// renderRecords answers with records, a slice of states or events, as XML
+// or CSV.
+func renderRecords(c *gin.Context, format, root, element string, records any) {
+	t, err := newTable(c, root, element, records)
+	var buf bytes.Buffer
+	if err == nil && format == contentTypeCSV {
+		err = t.writeCSV(&buf)
+	} else if err == nil {
+		buf.WriteString(xml.Header)
+		err = t.writeXML(&buf)
+	}
+	if err != nil {
+		problem(c, http.StatusInternalServerError, codeInternal, "failed to render "+format)
+		return
+	}
+	c.Data(http.StatusOK, format+"; charset=utf-8", buf.Bytes())
+}
+
+// writeCSV writes a header row of the columns, then a row per record.
+func (t table) writeCSV(buf *bytes.Buffer) error {
+	w := csv.NewWriter(buf)
+	if err := w.Write(t.columns); err != nil {
+		return err
+	}
+	row := make([]string, len(t.columns))
+	for _, r := range t.rows {
+		for i, col := range t.columns {
+			row[i] = csvCell(r[col])
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// csvCell flattens a decoded JSON value into one cell: lists of scalars
+// such as error_codes are joined with ";", objects such as metadata are
+// written as JSON.
+func csvCell(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case []any:
+		parts := make([]string, 0, len(t))
+		for _, e := range t {
+			switch e.(type) {
+			case map[string]any, []any:
+				b, _ := json.Marshal(t)
+				return string(b)
+			}
+			parts = append(parts, csvCell(e))
+		}
+		return strings.Join(parts, ";")
+	case map[string]any:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// writeXML writes the records as child elements of the root, each field
+// as an element named after its JSON name. Omitted fields have no
+// element.
+func (t table) writeXML(buf *bytes.Buffer) error {
+	enc := xml.NewEncoder(buf)
+	root := xml.StartElement{Name: xml.Name{Local: t.root}}
+	if err := enc.EncodeToken(root); err != nil {
+		return err
+	}
+	for _, r := range t.rows {
+		el := xml.StartElement{Name: xml.Name{Local: t.element}}
+		if t.element != "" {
+			if err := enc.EncodeToken(el); err != nil {
+				return err
+			}
+		}
+		for _, col := range t.columns {
+			if v, ok := r[col]; ok {
+				if err := writeXMLValue(enc, col, v); err != nil {
+					return err
+				}
+			}
+		}
+		if t.element != "" {
+			if err := enc.EncodeToken(el.End()); err != nil {
+				return err
+			}
+		}
+	}
+	if err := enc.EncodeToken(root.End()); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+// writeXMLValue writes v as the element name. Objects nest their fields
+// in key order and lists repeat an item element; keys that are no valid
+// element names, as metadata keys may be, become an entry with a key
+// attribute.
+func writeXMLValue(enc *xml.Encoder, name string, v any) error {
+	el := xml.StartElement{Name: xml.Name{Local: name}}
+	if !xmlName(name) {
+		el = xml.StartElement{Name: xml.Name{Local: "entry"}, Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}}}
+	}
+	if err := enc.EncodeToken(el); err != nil {
+		return err
+	}
+	switch t := v.(type) {
+	case nil:
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := writeXMLValue(enc, k, t[k]); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, e := range t {
+			if err := writeXMLValue(enc, "item", e); err != nil {
+				return err
+			}
+		}
+	default:
+		if err := enc.EncodeToken(xml.CharData(fmt.Sprint(t))); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(el.End())
+}
+
+// xmlName reports whether s can be used as an element name as it is.
+func xmlName(s string) bool {
+	if s == "" || strings.HasPrefix(strings.ToLower(s), "xml") {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+		case i > 0 && (r == '-' || r == '.' || (r >= '0' && r <= '9')):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestRender_StateAsXMLAndCSV(t *testing.T) {
+	st := models.FurnaceState{ID: 1, Mode: "HEAT", CurrentTempC: 812.5, MeasuredTempC: 811, IsRunning: true,
+		ErrorCodes: []string{"OVERHEAT", "SENSOR_FAULT"}, UpdatedAt: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)}
+	r := newTestRouter(&service.Service{Authorization: &mockAuth{parseID: 1}, Monitoring: &mockMonitoring{state: st}})
+	get := func(headers ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/furnace/state", nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		for i := 0; i < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("Accept", "text/xml")
+	var doc struct {
+		XMLName    xml.Name `xml:"state"`
+		Mode       string   `xml:"mode"`
+		Temp       float64  `xml:"current_temp_c"`
+		ErrorCodes []string `xml:"error_codes>item"`
+		Schema     int      `xml:"schema_version"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &doc); err != nil || w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), contentTypeXML) {
+		t.Fatalf("xml: %d %q %s (%v)", w.Code, w.Header().Get("Content-Type"), w.Body.String(), err)
+	}
+	if doc.Mode != "HEAT" || doc.Temp != 812.5 || len(doc.ErrorCodes) != 2 || doc.Schema != models.SchemaVersion {
+		t.Fatalf("xml state %+v", doc)
+	}
+
+	w = get("Accept", "text/csv", schemaHeader, "1")
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil || w.Code != http.StatusOK || len(rows) != 2 {
+		t.Fatalf("csv: %d %s (%v)", w.Code, w.Body.String(), err)
+	}
+	cell := map[string]string{}
+	for i, col := range rows[0] {
+		cell[col] = rows[1][i]
+	}
+	if rows[0][0] != "schema_version" || cell["schema_version"] != "1" || cell["mode"] != "HEAT" || cell["error_codes"] != "OVERHEAT;SENSOR_FAULT" {
+		t.Fatalf("csv state %v", cell)
+	}
+	if _, ok := cell["measured_temp_c"]; ok {
+		t.Fatalf("a version 1 row has measured_temp_c: %v", rows[0])
+	}
+
+	// JSON stays the default, also for Accept headers offering nothing else
+	for _, accept := range []string{"", "*/*", "application/json", "text/html"} {
+		if w := get("Accept", accept); !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			t.Fatalf("Accept %q: %q", accept, w.Header().Get("Content-Type"))
+		}
+	}
+	if get("Accept", "text/csv").Header().Get("ETag") == get().Header().Get("ETag") {
+		t.Fatal("CSV and JSON share an ETag")
+	}
+}
+
+func TestRender_LogsAsCSVAndXML(t *testing.T) {
+	at := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	logs := &mockEventLog{resp: []models.FurnaceEvent{
+		{EventID: "e1", OccurredAt: at, Type: "START", Description: "Furnace started"},
+		{EventID: "e2", OccurredAt: at.Add(time.Minute), Type: "MODE_CHANGE", Description: "Mode changed to HEAT, \"fast\"",
+			Metadata: map[string]any{"to": "HEAT", "target_temp_c": 850, "limits/max_c": 1200}},
+	}, next: "c2"}
+	r := newTestRouter(&service.Service{Authorization: &mockAuth{parseID: 1}, EventLog: logs, EventPager: logs})
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		req.Header.Set("Accept", accept)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/api/v1/logs/", "text/csv")
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil || w.Code != http.StatusOK || len(rows) != 3 {
+		t.Fatalf("csv: %d %s (%v)", w.Code, w.Body.String(), err)
+	}
+	col := map[string]int{}
+	for i, name := range rows[0] {
+		col[name] = i
+	}
+	if rows[1][col["type"]] != "START" || rows[2][col["description"]] != `Mode changed to HEAT, "fast"` ||
+		rows[2][col["metadata"]] != `{"limits/max_c":1200,"target_temp_c":850,"to":"HEAT"}` || rows[1][col["metadata"]] != "" {
+		t.Fatalf("csv rows %v", rows)
+	}
+
+	w = get("/api/v1/logs/?limit=2", "application/xml")
+	var doc struct {
+		Events []struct {
+			ID    string `xml:"event_id"`
+			To    string `xml:"metadata>to"`
+			Entry []struct {
+				Key   string `xml:"key,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"metadata>entry"`
+		} `xml:"event"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &doc); err != nil || w.Code != http.StatusOK || len(doc.Events) != 2 {
+		t.Fatalf("xml: %d %s (%v)", w.Code, w.Body.String(), err)
+	}
+	if doc.Events[1].To != "HEAT" || len(doc.Events[1].Entry) != 1 || doc.Events[1].Entry[0].Key != "limits/max_c" || doc.Events[1].Entry[0].Value != "1200" {
+		t.Fatalf("xml events %+v", doc.Events)
+	}
+	if w.Header().Get(nextCursorHeader) != "c2" {
+		t.Fatalf("next cursor %q", w.Header().Get(nextCursorHeader))
+	}
+
+	if w := get("/api/v1/logs/?format=json", "text/csv"); !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("format=json: %q", w.Header().Get("Content-Type"))
+	}
+}
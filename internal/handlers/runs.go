@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Get run
+// @Description  Returns the record of a heat cycle, including soak stability (share of soak time within tolerance and temperature standard deviation).
+// @Tags         runs
+// @Produce      json
+// @Param        run_id  path      string  true  "Run ID"
+// @Success      200     {object}  models.Run
+// @Failure      401     {object}  Problem
+// @Failure      404     {object}  Problem
+// @Failure      500     {object}  Problem
+// @Router       /api/v1/runs/{run_id} [get]
+// @Security     BearerAuth
+func (h *Handler) getRun(c *gin.Context) {
+	runID := c.Param("run_id")
+	run, err := h.services.Runs.GetRun(c.Request.Context(), runID)
+	if err != nil {
+		if errors.Is(err, service.ErrRunNotFound) {
+			problemFor(c, http.StatusNotFound, err)
+			return
+		}
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to load run", "get_run_failed", err, "runId", runID)
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"controlling_furnace/internal/models"
+)
+
+// schemaHeader pins the state/event payload version for a request;
+// WebSocket clients, which cannot set headers, use ?schema_version=.
+const schemaHeader = "X-Schema-Version"
+
+// parseSchemaVersion validates a requested payload version. An empty string
+// means the current version and yields 0.
+func parseSchemaVersion(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid schema version %q", s)
+	}
+	if err := checkSchemaVersion(v); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+// checkSchemaVersion reports whether payloads can be rendered as version v.
+func checkSchemaVersion(v int) error {
+	if v < models.MinSchemaVersion || v > models.SchemaVersion {
+		return fmt.Errorf("unsupported schema version %d; use %d..%d", v, models.MinSchemaVersion, models.SchemaVersion)
+	}
+	return nil
+}
+
+// downgradeAll renders every state and event payload inside a decoded JSON
+// document as schema version v. v of 0 or the current version is a no-op.
+func downgradeAll(doc any, v int) any {
+	if v == 0 || v >= models.SchemaVersion {
+		return doc
+	}
+	switch t := doc.(type) {
+	case map[string]any:
+		models.Downgrade(t, v)
+		for k, val := range t {
+			t[k] = downgradeAll(val, v)
+		}
+	case []any:
+		for i := range t {
+			t[i] = downgradeAll(t[i], v)
+		}
+	}
+	return doc
+}
+
+// versioned returns payload as seen by a client pinned to schema version v,
+// re-encoding it only when an older version was requested.
+func versioned(payload any, v int) (any, error) {
+	if v == 0 || v >= models.SchemaVersion {
+		return payload, nil
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return downgradeAll(doc, v), nil
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+var schemaTestState = models.FurnaceState{Mode: "HEAT", CurrentTempC: 500, MeasuredTempC: 501, RunID: "run-1", PowerKW: 150, IsRunning: true}
+
+func getStateJSON(t *testing.T, r *gin.Engine, path string, header map[string]string) (int, map[string]any) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	r.ServeHTTP(w, req)
+	var out map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &out)
+	return w.Code, out
+}
+
+func TestSchema_CurrentVersionIsStamped(t *testing.T) {
+	s := &service.Service{Authorization: &mockAuth{parseID: 1}, Monitoring: &mockMonitoring{state: schemaTestState}}
+	code, out := getStateJSON(t, newCompatRouter(s, CompatConfig{}), "/api/v1/furnace/state", nil)
+	if code != http.StatusOK || out["schema_version"] != float64(models.SchemaVersion) || out["measured_temp_c"] != 501.0 {
+		t.Fatalf("status=%d, body=%v", code, out)
+	}
+}
+
+func TestSchema_HeaderPinsOlderState(t *testing.T) {
+	s := &service.Service{Authorization: &mockAuth{parseID: 1}, Monitoring: &mockMonitoring{state: schemaTestState}}
+	r := newCompatRouter(s, CompatConfig{})
+
+	code, out := getStateJSON(t, r, "/api/v1/furnace/state", map[string]string{schemaHeader: "1"})
+	if code != http.StatusOK {
+		t.Fatalf("status=%d, body=%v", code, out)
+	}
+	if out["schema_version"] != 1.0 || out["current_temp_c"] != 500.0 {
+		t.Fatalf("expected a version 1 state, got %v", out)
+	}
+	for _, f := range []string{"measured_temp_c", "ambient_temp_c", "run_id", "power_kw", "energy_kwh"} {
+		if _, ok := out[f]; ok {
+			t.Fatalf("field %s is not part of version 1: %v", f, out)
+		}
+	}
+
+	if code, _ := getStateJSON(t, r, "/api/v1/furnace/state", map[string]string{schemaHeader: strconv.Itoa(models.SchemaVersion + 1)}); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown version, got %d", code)
+	}
+}
+
+func TestSchema_ProfilePinAppliesBeforeRenaming(t *testing.T) {
+	s := &service.Service{Authorization: &mockAuth{parseID: 1}, Monitoring: &mockMonitoring{state: schemaTestState}}
+	cfg := CompatConfig{
+		Profiles: map[string]CompatProfile{"legacy": {Naming: NamingCamel, SchemaVersion: 1}},
+		Versions: map[string]string{"v0": "legacy"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	r := newCompatRouter(s, cfg)
+
+	_, out := getStateJSON(t, r, "/api/v0/furnace/state", nil)
+	if out["schemaVersion"] != 1.0 || out["currentTempC"] != 500.0 || out["measuredTempC"] != nil {
+		t.Fatalf("expected a camelCase version 1 state, got %v", out)
+	}
+	_, out = getStateJSON(t, r, "/api/v0/furnace/state", map[string]string{schemaHeader: "2"})
+	if out["measuredTempC"] != 501.0 {
+		t.Fatalf("header should override the profile pin, got %v", out)
+	}
+
+	bad := CompatConfig{Profiles: map[string]CompatProfile{"x": {SchemaVersion: models.SchemaVersion + 1}}}
+	if err := bad.Validate(); err == nil {
+		t.Fatalf("expected an unsupported pinned version to fail validation")
+	}
+}
+
+func TestWebSocket_SchemaVersionQuery(t *testing.T) {
+	r := gin.New()
+	h := NewHandler(&service.Service{Monitoring: &mockMonitoring{state: schemaTestState}, Authorization: &mockAuth{parseID: 1}}, nil)
+	r.GET("/ws", h.wsConnect)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	u.Scheme, u.Path, u.RawQuery = "ws", "/ws", "schema_version=1&token=valid"
+	conn, _, err := (&websocket.Dialer{HandshakeTimeout: 2 * time.Second}).Dial(u.String(), nil)
+	if err != nil {
+		t.Fatalf("dial error: %v", err)
+	}
+	defer conn.Close()
+
+	var env struct {
+		Type string         `json:"type"`
+		Data map[string]any `json:"data"`
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if err := conn.ReadJSON(&env); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if env.Type != "state" || env.Data["schema_version"] != 1.0 || env.Data["run_id"] != nil {
+		t.Fatalf("expected a version 1 state, got %+v", env)
+	}
+
+	u.RawQuery = "schema_version=0"
+	if _, resp, err := (&websocket.Dialer{}).Dial(u.String(), nil); err == nil || resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unsupported version, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartSequenceRequest lists the mode steps to run back to back.
+type StartSequenceRequest struct {
+	Steps []models.SequenceStep `json:"steps" binding:"required"`
+}
+
+// @Summary      Start mode sequence
+// @Description  Runs the steps back to back as an ad-hoc recipe: the first is set now, each further one once the previous is done. HEAT ends when its soak of duration_sec has elapsed; COOL once the chamber is down to target_temp_c or after duration_sec, whichever comes first; STANDBY after duration_sec. Durations are simulated seconds. The state shows the running sequence and step. Stopping the furnace or another mode command aborts it. Saved with its progress: after a restart it carries on with the step it was on, not counting the time the process was down, unless that exceeded sequences.resume_within. Logs SEQUENCE_STARTED, SEQUENCE_RESUMED after a restart, then SEQUENCE_COMPLETED or SEQUENCE_ABORTED.
+// @Tags         furnace
+// @Accept       json
+// @Produce      json
+// @Param        body  body      StartSequenceRequest  true  "Steps"
+// @Success      202   {object}  models.Sequence
+// @Failure      400   {object}  Problem  "Invalid steps, or the first could not be set, e.g. the furnace is stopped"
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      409   {object}  Problem  "A sequence is already running"
+// @Router       /api/v1/furnace/sequence [post]
+// @Security     BearerAuth
+func (h *Handler) startSequence(c *gin.Context) {
+	var req StartSequenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, errInvalidBodyPref+err.Error())
+		return
+	}
+	seq, err := h.services.Sequences.StartSequence(c.Request.Context(), req.Steps, c.GetInt(ctxKeyUserID))
+	switch {
+	case errors.Is(err, service.ErrSequenceRunning):
+		problemFor(c, http.StatusConflict, err)
+		return
+	case err != nil:
+		// as POST /furnace/mode: the first step is set the same way
+		if h.log != nil {
+			h.requestLog(c).Errorw("furnace_sequence_failed", "err", err, "steps", len(req.Steps))
+		}
+		problemFor(c, http.StatusBadRequest, err)
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Infow("furnace_sequence_started", "sequenceId", seq.ID, "steps", len(seq.Steps), "userId", c.GetInt(ctxKeyUserID))
+	}
+	c.JSON(http.StatusAccepted, seq)
+}
+
+// @Summary      Get mode sequence
+// @Description  Reports a running or finished sequence with its status and step.
+// @Tags         furnace
+// @Produce      json
+// @Param        id   path      string  true  "Sequence ID"
+// @Success      200  {object}  models.Sequence
+// @Failure      401  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Router       /api/v1/furnace/sequence/{id} [get]
+// @Security     BearerAuth
+func (h *Handler) getSequence(c *gin.Context) {
+	seq, err := h.services.Sequences.GetSequence(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		problemFor(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, seq)
+}
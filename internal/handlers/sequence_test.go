@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestSequenceHandlers(t *testing.T) {
+	auth := &mockAuth{parseID: 3, parseRole: models.RoleOperator}
+	seqs := &mockSequences{seq: models.Sequence{ID: "s1", Status: models.SequenceRunning, Step: 1}}
+	r := newTestRouter(&service.Service{Authorization: auth, Sequences: seqs})
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer valid")
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/api/v1/furnace/sequence", `{"steps":[{"mode":"HEAT","target_temp_c":850,"duration_sec":600},{"mode":"COOL","target_temp_c":200}]}`)
+	var seq models.Sequence
+	if err := json.Unmarshal(w.Body.Bytes(), &seq); err != nil || w.Code != http.StatusAccepted || seq.ID != "s1" {
+		t.Fatalf("start: %d %s", w.Code, w.Body.String())
+	}
+	if len(seqs.lastSteps) != 2 || seqs.lastSteps[1] != (models.SequenceStep{Mode: "COOL", TargetTempC: 200}) || seqs.lastUser != 3 {
+		t.Fatalf("passed %+v by user %d", seqs.lastSteps, seqs.lastUser)
+	}
+	if w := do(http.MethodGet, "/api/v1/furnace/sequence/s1", ""); w.Code != http.StatusOK {
+		t.Fatalf("get: %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v1/furnace/sequence/s2", ""); w.Code != http.StatusNotFound || decodeProblem(t, w).Code != "sequence_not_found" {
+		t.Fatalf("unknown: %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/v1/furnace/sequence", `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("no steps: %d", w.Code)
+	}
+
+	seqs.err = service.ErrSequenceRunning
+	if w := do(http.MethodPost, "/api/v1/furnace/sequence", `{"steps":[{"mode":"COOL","duration_sec":60}]}`); w.Code != http.StatusConflict || decodeProblem(t, w).Code != "sequence_running" {
+		t.Fatalf("running: %d", w.Code)
+	}
+	seqs.err = service.ErrInvalidSequence
+	if w := do(http.MethodPost, "/api/v1/furnace/sequence", `{"steps":[{"mode":"COOL"}]}`); w.Code != http.StatusBadRequest || decodeProblem(t, w).Code != "invalid_sequence" {
+		t.Fatalf("invalid: %d", w.Code)
+	}
+
+	auth.parseRole = models.RoleViewer
+	if w := do(http.MethodPost, "/api/v1/furnace/sequence", `{"steps":[{"mode":"COOL","duration_sec":60}]}`); w.Code != http.StatusForbidden {
+		t.Fatalf("viewer: %d", w.Code)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRequest is the first-run configuration.
+type SetupRequest struct {
+	// First admin account
+	Username string `json:"username" binding:"required" example:"admin"`
+	Password string `json:"password" binding:"required"`
+	// HMAC key for API tokens, at least 32 bytes; generated when omitted
+	SigningKey string `json:"signing_key,omitempty"`
+	// Display units for clients: C (default) or F; the API always reports °C
+	Units string `json:"units,omitempty" example:"C"`
+	// OVERHEAT threshold in °C; the configured value is kept when omitted
+	MaxSafeC float64 `json:"max_safe_c,omitempty" example:"1000"`
+}
+
+// @Summary      Setup status
+// @Description  Reports whether the installation still needs first-run setup (no users exist yet) and the chosen display units.
+// @Tags         setup
+// @Produce      json
+// @Success      200  {object}  service.SetupStatus
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/setup [get]
+func (h *Handler) getSetup(c *gin.Context) {
+	st, err := h.services.Setup.SetupStatus(c.Request.Context())
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to read setup status", "setup_status_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// @Summary      Complete setup
+// @Description  Configures an empty installation: creates the first admin, stores the token signing key, display units and safety limit, and returns a token for the admin. Only available while no users exist; sign-up is refused until then.
+// @Tags         setup
+// @Accept       json
+// @Produce      json
+// @Param        body  body      SetupRequest  true  "First-run configuration"
+// @Success      201   {object}  TokenResponse
+// @Failure      400   {object}  Problem
+// @Failure      409   {object}  Problem  "Setup already completed"
+// @Failure      500   {object}  Problem
+// @Router       /api/v1/setup [post]
+func (h *Handler) completeSetup(c *gin.Context) {
+	var req SetupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, errInvalidBodyPref+err.Error())
+		return
+	}
+	token, err := h.services.Setup.CompleteSetup(c.Request.Context(), service.SetupRequest{
+		Username:   req.Username,
+		Password:   req.Password,
+		SigningKey: req.SigningKey,
+		Units:      req.Units,
+		MaxSafeC:   req.MaxSafeC,
+	})
+	switch {
+	case errors.Is(err, service.ErrSetupDone):
+		problemFor(c, http.StatusConflict, err)
+		return
+	case errors.Is(err, service.ErrInvalidSetup):
+		invalidInput(c, http.StatusBadRequest, err)
+		return
+	case err != nil:
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to complete setup", "setup_failed", err)
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Infow("setup_completed", "admin", req.Username, "units", req.Units)
+	}
+	c.JSON(http.StatusCreated, TokenResponse{Token: token})
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"controlling_furnace/internal/service"
+)
+
+func TestSetupHandlers(t *testing.T) {
+	setup := &mockSetup{status: service.SetupStatus{Required: true, Units: "C"}}
+	r := newTestRouter(&service.Service{Authorization: &mockAuth{}, Setup: setup})
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/setup", bytes.NewBufferString(body)))
+		return w
+	}
+
+	// Both routes are open without a token.
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/setup", nil))
+	if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(`"required":true`)) {
+		t.Fatalf("status: %d %s", w.Code, w.Body.String())
+	}
+
+	w = post(`{"username":"admin","password":"pw","units":"F","max_safe_c":950}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("setup: %d %s", w.Code, w.Body.String())
+	}
+	var tok TokenResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &tok); err != nil || tok.Token != "admin-token" {
+		t.Fatalf("token response = %s", w.Body.String())
+	}
+	if setup.lastReq.Username != "admin" || setup.lastReq.Units != "F" || setup.lastReq.MaxSafeC != 950 {
+		t.Fatalf("request = %+v", setup.lastReq)
+	}
+
+	for err, want := range map[error]int{
+		service.ErrSetupDone: http.StatusConflict,
+		fmt.Errorf("%w: units must be C or F", service.ErrInvalidSetup): http.StatusBadRequest,
+	} {
+		setup.err = err
+		if w := post(`{"username":"admin","password":"pw"}`); w.Code != want {
+			t.Errorf("%v: status %d, want %d", err, w.Code, want)
+		}
+	}
+	if w := post(`{"username":"admin"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("missing password: status %d, want 400", w.Code)
+	}
+}
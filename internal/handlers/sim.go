@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InjectFaultRequest is the payload for injecting a simulator fault.
+type InjectFaultRequest struct {
+	// Fault to inject. Allowed: STUCK_SENSOR, HEATER_FAILURE, POWER_LOSS, THERMOCOUPLE_BREAK
+	Type string `json:"type" binding:"required" example:"HEATER_FAILURE"`
+}
+
+// FaultsResponse lists the faults currently injected into the simulator.
+type FaultsResponse struct {
+	Faults []service.ActiveFault `json:"faults"`
+}
+
+// SimSpeedDTO is the wire form of the simulator speed. On update, omitted
+// or zero fields keep their current value.
+type SimSpeedDTO struct {
+	// Interval between simulation steps
+	TickMs int `json:"tick_ms" example:"1000"`
+	// Simulated seconds per wall-clock second
+	TimeScale float64 `json:"time_scale" example:"60"`
+}
+
+func speedToDTO(sp service.Speed) SimSpeedDTO {
+	return SimSpeedDTO{TickMs: int(sp.Tick / time.Millisecond), TimeScale: sp.TimeScale}
+}
+
+// @Summary      Get simulation speed
+// @Tags         simulator
+// @Produce      json
+// @Success      200  {object}  SimSpeedDTO
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Router       /api/v1/sim/speed [get]
+// @Security     BearerAuth
+func (h *Handler) getSimSpeed(c *gin.Context) {
+	c.JSON(http.StatusOK, speedToDTO(h.services.SimClock.Speed()))
+}
+
+// @Summary      Set simulation speed
+// @Description  Changes the simulator tick and time acceleration at runtime. With time_scale=60 a 2-hour heat cycle completes in 2 minutes.
+// @Tags         simulator
+// @Accept       json
+// @Produce      json
+// @Param        body  body      SimSpeedDTO  true  "Speed"
+// @Success      200   {object}  SimSpeedDTO
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Router       /api/v1/sim/speed [put]
+// @Security     BearerAuth
+func (h *Handler) setSimSpeed(c *gin.Context) {
+	var req SimSpeedDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, errInvalidBodyPref+err.Error())
+		return
+	}
+	sp := h.services.SimClock.Speed()
+	if req.TickMs != 0 {
+		sp.Tick = time.Duration(req.TickMs) * time.Millisecond
+	}
+	if req.TimeScale != 0 {
+		sp.TimeScale = req.TimeScale
+	}
+	if err := h.services.SimClock.SetSpeed(sp); err != nil {
+		if errors.Is(err, service.ErrInvalidSpeed) {
+			problemFor(c, http.StatusBadRequest, err)
+			return
+		}
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to set simulation speed", "sim_speed_failed", err)
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Infow("sim_speed_updated", "tick", sp.Tick, "timeScale", sp.TimeScale, "userId", c.GetInt(ctxKeyUserID))
+	}
+	c.JSON(http.StatusOK, speedToDTO(h.services.SimClock.Speed()))
+}
+
+// @Summary      Get simulator settings
+// @Tags         simulator
+// @Produce      json
+// @Success      200  {object}  models.SimSettings
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Router       /api/v1/sim/config [get]
+// @Security     BearerAuth
+func (h *Handler) getSimConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.SimTuning.SimSettings())
+}
+
+// @Summary      Update simulator settings
+// @Description  Replaces the simulator physics, sensor noise and tick interval. Send the full object as returned by GET. The tick changes immediately, the rest on the next simulation step; values are persisted across restarts.
+// @Tags         simulator
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.SimSettings  true  "Settings"
+// @Success      200   {object}  models.SimSettings
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      500   {object}  Problem
+// @Router       /api/v1/sim/config [put]
+// @Security     BearerAuth
+func (h *Handler) updateSimConfig(c *gin.Context) {
+	var req models.SimSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, errInvalidBodyPref+err.Error())
+		return
+	}
+	if err := h.services.SimTuning.UpdateSimSettings(c.Request.Context(), req); err != nil {
+		if errors.Is(err, service.ErrInvalidSimSettings) {
+			problemFor(c, http.StatusBadRequest, err)
+			return
+		}
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to update simulator settings", "sim_config_failed", err)
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Infow("sim_config_updated", "settings", req, "userId", c.GetInt(ctxKeyUserID))
+	}
+	c.JSON(http.StatusOK, h.services.SimTuning.SimSettings())
+}
+
+// @Summary      Preview simulator settings
+// @Description  Reports what the proposed settings would invalidate without applying them: an active HEAT target outside the new ambient_c..max_safe_c range, a chamber already above the new max_safe_c, a room override at or above it (all blocking), and enabled temp_above alert rules above it (warnings). With apply=true the settings are applied like PUT /sim/config if they are valid and nothing blocks them, checked and changed in one step so no command slips in between; otherwise the answer is 400 or 409 with the report.
+// @Tags         simulator
+// @Accept       json
+// @Produce      json
+// @Param        apply  query     bool                false  "Apply the settings when nothing blocks them"
+// @Param        body   body      models.SimSettings  true  "Proposed settings"
+// @Success      200    {object}  models.SettingsPreview
+// @Failure      400    {object}  models.SettingsPreview
+// @Failure      401    {object}  Problem
+// @Failure      403    {object}  Problem
+// @Failure      409    {object}  models.SettingsPreview
+// @Failure      500    {object}  Problem
+// @Router       /api/v1/admin/config/preview [post]
+// @Security     BearerAuth
+func (h *Handler) previewSimConfig(c *gin.Context) {
+	var req models.SimSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, errInvalidBodyPref+err.Error())
+		return
+	}
+	apply := c.Query("apply") == "true"
+	p, err := h.services.SimTuning.PreviewSimSettings(c.Request.Context(), req, apply)
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to preview simulator settings", "sim_config_preview_failed", err)
+		return
+	}
+	switch {
+	case !apply:
+		c.JSON(http.StatusOK, p)
+	case !p.Valid:
+		c.JSON(http.StatusBadRequest, p)
+	case !p.Applied:
+		c.JSON(http.StatusConflict, p)
+	default:
+		if h.log != nil {
+			h.requestLog(c).Infow("sim_config_updated", "settings", req, "userId", c.GetInt(ctxKeyUserID))
+		}
+		c.JSON(http.StatusOK, p)
+	}
+}
+
+// SetAmbientRequest is the payload for overriding the room temperature.
+type SetAmbientRequest struct {
+	// Room temperature in Celsius the chamber cools (or warms) toward
+	TempC *float64 `json:"temp_c" binding:"required" example:"-10"`
+}
+
+// @Summary      Get ambient temperature
+// @Description  Returns the room temperature the chamber converges to, and whether it follows the configured daily profile or an override.
+// @Tags         simulator
+// @Produce      json
+// @Success      200  {object}  service.AmbientStatus
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Router       /api/v1/sim/ambient [get]
+// @Security     BearerAuth
+func (h *Handler) getSimAmbient(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.SimAmbient.Ambient())
+}
+
+// @Summary      Set ambient temperature
+// @Description  Holds the room at a fixed temperature from the next tick on, replacing the daily profile, e.g. to simulate an outdoor installation in winter. Not persisted across restarts.
+// @Tags         simulator
+// @Accept       json
+// @Produce      json
+// @Param        body  body      SetAmbientRequest  true  "Ambient"
+// @Success      200   {object}  service.AmbientStatus
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Router       /api/v1/sim/ambient [put]
+// @Security     BearerAuth
+func (h *Handler) setSimAmbient(c *gin.Context) {
+	var req SetAmbientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, errInvalidBodyPref+err.Error())
+		return
+	}
+	if err := h.services.SimAmbient.SetAmbient(*req.TempC); err != nil {
+		if errors.Is(err, service.ErrInvalidAmbient) {
+			problemFor(c, http.StatusBadRequest, err)
+			return
+		}
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to set ambient temperature", "sim_ambient_failed", err)
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Infow("sim_ambient_set", "tempC", *req.TempC, "userId", c.GetInt(ctxKeyUserID))
+	}
+	c.JSON(http.StatusOK, h.services.SimAmbient.Ambient())
+}
+
+// @Summary      Clear ambient override
+// @Description  Returns the room temperature to the configured daily profile.
+// @Tags         simulator
+// @Produce      json
+// @Success      200  {object}  service.AmbientStatus
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Router       /api/v1/sim/ambient [delete]
+// @Security     BearerAuth
+func (h *Handler) clearSimAmbient(c *gin.Context) {
+	h.services.SimAmbient.ClearAmbient()
+	if h.log != nil {
+		h.requestLog(c).Infow("sim_ambient_cleared", "userId", c.GetInt(ctxKeyUserID))
+	}
+	c.JSON(http.StatusOK, h.services.SimAmbient.Ambient())
+}
+
+// @Summary      Inject simulator fault
+// @Description  Makes the simulator exhibit a hardware failure. It is reported on the next tick as an error code and an ERROR event, and lasts until cleared.
+// @Tags         simulator
+// @Accept       json
+// @Produce      json
+// @Param        body  body      InjectFaultRequest  true  "Fault"
+// @Success      202   {object}  FaultsResponse
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Router       /api/v1/sim/faults [post]
+// @Security     BearerAuth
+func (h *Handler) injectFault(c *gin.Context) {
+	var req InjectFaultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, errInvalidBodyPref+err.Error())
+		return
+	}
+	if err := h.services.Faults.InjectFault(req.Type); err != nil {
+		if errors.Is(err, service.ErrUnknownFault) {
+			problemFor(c, http.StatusBadRequest, err)
+			return
+		}
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to inject fault", "fault_inject_failed", err)
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Warnw("sim_fault_injected", "type", req.Type, "userId", c.GetInt(ctxKeyUserID))
+	}
+	c.JSON(http.StatusAccepted, FaultsResponse{Faults: h.services.Faults.ActiveFaults()})
+}
+
+// @Summary      List simulator faults
+// @Tags         simulator
+// @Produce      json
+// @Success      200  {object}  FaultsResponse
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Router       /api/v1/sim/faults [get]
+// @Security     BearerAuth
+func (h *Handler) listFaults(c *gin.Context) {
+	c.JSON(http.StatusOK, FaultsResponse{Faults: h.services.Faults.ActiveFaults()})
+}
+
+// @Summary      Clear simulator fault
+// @Tags         simulator
+// @Produce      json
+// @Param        type  path      string  true  "Fault type"
+// @Success      200   {object}  FaultsResponse
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Router       /api/v1/sim/faults/{type} [delete]
+// @Security     BearerAuth
+func (h *Handler) clearFault(c *gin.Context) {
+	kind := c.Param("type")
+	if err := h.services.Faults.ClearFault(kind); err != nil {
+		problemFor(c, http.StatusBadRequest, err)
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Infow("sim_fault_cleared", "type", kind, "userId", c.GetInt(ctxKeyUserID))
+	}
+	c.JSON(http.StatusOK, FaultsResponse{Faults: h.services.Faults.ActiveFaults()})
+}
+
+// @Summary      Clear all simulator faults
+// @Tags         simulator
+// @Produce      json
+// @Success      200  {object}  FaultsResponse
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Router       /api/v1/sim/faults [delete]
+// @Security     BearerAuth
+func (h *Handler) clearAllFaults(c *gin.Context) {
+	h.services.Faults.ClearAllFaults()
+	if h.log != nil {
+		h.requestLog(c).Infow("sim_faults_cleared", "userId", c.GetInt(ctxKeyUserID))
+	}
+	c.JSON(http.StatusOK, FaultsResponse{Faults: h.services.Faults.ActiveFaults()})
+}
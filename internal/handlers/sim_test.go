@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestSimFaults_InjectListClear(t *testing.T) {
+	faults := &mockFaults{}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleOperator},
+		Faults:        faults,
+	}
+	r := newTestRouter(s)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sim/faults", bytes.NewBufferString(`{"type":"HEATER_FAILURE"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("inject status=%d, body=%s", w.Code, w.Body.String())
+	}
+	var out FaultsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(out.Faults) != 1 || out.Faults[0].Type != service.FaultHeaterFailure {
+		t.Fatalf("unexpected faults: %+v", out.Faults)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/sim/faults/HEATER_FAILURE", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || len(faults.active) != 0 {
+		t.Fatalf("clear status=%d, active=%+v", w.Code, faults.active)
+	}
+}
+
+func TestSimFaults_UnknownTypeIs400(t *testing.T) {
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleAdmin},
+		Faults:        &mockFaults{},
+	}
+	r := newTestRouter(s)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sim/faults", bytes.NewBufferString(`{"type":"MELTDOWN"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown fault, got %d", w.Code)
+	}
+}
+
+func TestSimFaults_ViewerForbidden(t *testing.T) {
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleViewer},
+		Faults:        &mockFaults{},
+	}
+	r := newTestRouter(s)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sim/faults", bytes.NewBufferString(`{"type":"POWER_LOSS"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for viewer, got %d", w.Code)
+	}
+}
+
+func TestSimSpeed_PartialUpdate(t *testing.T) {
+	clock := &mockSimClock{speed: service.Speed{Tick: time.Second, TimeScale: 1}}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleOperator},
+		SimClock:      clock,
+	}
+	r := newTestRouter(s)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/sim/speed", bytes.NewBufferString(`{"time_scale":60}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if clock.speed != (service.Speed{Tick: time.Second, TimeScale: 60}) {
+		t.Fatalf("unexpected speed: %+v", clock.speed)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/sim/speed", bytes.NewBufferString(`{"tick_ms":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for too-short tick, got %d", w.Code)
+	}
+}
+
+func TestSimConfig_GetAndUpdate(t *testing.T) {
+	tuning := &mockSimTuning{settings: models.SimSettings{TickMs: 1000, AmbientC: 25, MaxSafeC: 1000}}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleOperator},
+		SimTuning:     tuning,
+	}
+	r := newTestRouter(s)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sim/config", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	var got models.SimSettings
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got.MaxSafeC != 1000 {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+
+	body := `{"tick_ms":500,"ambient_c":20,"max_safe_c":1100,"ramp_up_c_per_sec":6,"ramp_down_c_per_sec":5,"standby_cool_c_per_sec":0.5}`
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/sim/config", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if tuning.settings.RampUpCPerSec != 6 || tuning.settings.TickMs != 500 {
+		t.Fatalf("settings not applied: %+v", tuning.settings)
+	}
+
+	tuning.updateErr = fmt.Errorf("%w: bad", service.ErrInvalidSimSettings)
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/sim/config", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 on invalid settings, got %d", w.Code)
+	}
+}
+
+func TestSimConfigPreview_StatusFollowsTheReport(t *testing.T) {
+	tuning := &mockSimTuning{}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleAdmin},
+		SimTuning:     tuning,
+	}
+	r := newTestRouter(s)
+	do := func(query string) *httptest.ResponseRecorder {
+		body := `{"tick_ms":1000,"ambient_c":25,"max_safe_c":700,"ramp_up_c_per_sec":3,"ramp_down_c_per_sec":5,"standby_cool_c_per_sec":0.5}`
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/config/preview"+query, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	blocked := models.SettingsPreview{Valid: true, Conflicts: []models.SettingsConflict{{Subject: "state.target_temp_c", Value: 800, Blocking: true}}}
+	tuning.preview = blocked
+	if w := do(""); w.Code != http.StatusOK || tuning.lastApply || tuning.lastProposed.MaxSafeC != 700 {
+		t.Fatalf("preview: status=%d apply=%v body=%s", w.Code, tuning.lastApply, w.Body.String())
+	}
+	w := do("?apply=true")
+	var out models.SettingsPreview
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil || w.Code != http.StatusConflict || !tuning.lastApply || len(out.Conflicts) != 1 {
+		t.Fatalf("blocked apply: status=%d body=%s", w.Code, w.Body.String())
+	}
+	tuning.preview = models.SettingsPreview{Valid: false, Error: "invalid simulator settings"}
+	if w := do("?apply=true"); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid apply: status=%d", w.Code)
+	}
+	tuning.preview = models.SettingsPreview{Valid: true, Applied: true}
+	if w := do("?apply=true"); w.Code != http.StatusOK {
+		t.Fatalf("apply: status=%d", w.Code)
+	}
+
+	s.Authorization = &mockAuth{parseID: 2, parseRole: models.RoleOperator}
+	if w := do(""); w.Code != http.StatusForbidden {
+		t.Fatalf("operator: status=%d, want 403", w.Code)
+	}
+}
+
+func TestSimAmbient_SetAndClear(t *testing.T) {
+	amb := &mockSimAmbient{status: service.AmbientStatus{TempC: 25, MeanC: 25, Source: service.AmbientSourceProfile}}
+	s := &service.Service{
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleOperator},
+		SimAmbient:    amb,
+	}
+	r := newTestRouter(s)
+
+	do := func(method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/api/v1/sim/ambient", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPut, `{"temp_c":-10}`)
+	var out service.AmbientStatus
+	_ = json.Unmarshal(w.Body.Bytes(), &out)
+	if w.Code != http.StatusOK || out.TempC != -10 || out.Source != service.AmbientSourceOverride {
+		t.Fatalf("set status=%d, body=%s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPut, `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without temp_c, got %d", w.Code)
+	}
+	if w := do(http.MethodPut, `{"temp_c":-100}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 below the minimum, got %d", w.Code)
+	}
+
+	w = do(http.MethodDelete, "")
+	_ = json.Unmarshal(w.Body.Bytes(), &out)
+	if w.Code != http.StatusOK || out.Source != service.AmbientSourceProfile || out.TempC != 25 {
+		t.Fatalf("clear status=%d, body=%s", w.Code, w.Body.String())
+	}
+}
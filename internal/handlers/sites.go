@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SiteHeader names the site of a request without a token, such as
+// sign-in and setup; ?site= does the same for clients that cannot set
+// headers.
+const SiteHeader = "X-Site-ID"
+
+// Sites serves several sites from one listener, each through the routes of
+// its own Handler, so every site keeps its own repositories, signing key
+// and background loops. A request goes to the site its bearer token names
+// (the Authorization header, or ?token= for WebSockets); that site's
+// routes then verify the token with the site's key. A request without a
+// token goes to the site in X-Site-ID or ?site=, and otherwise to the
+// first site added.
+type Sites struct {
+	order   []string
+	routes  map[string]http.Handler
+	unknown *gin.Engine
+}
+
+// NewSites returns a Sites serving no site yet; requests for a site not
+// added answer 404 site_not_found.
+func NewSites() *Sites {
+	s := &Sites{routes: make(map[string]http.Handler)}
+	s.unknown = gin.New()
+	s.unknown.NoRoute(func(c *gin.Context) {
+		problem(c, http.StatusNotFound, codeSiteNotFound, fmt.Sprintf("site %q is not served here", requestedSite(c.Request)))
+	})
+	return s
+}
+
+// Add serves site through routes, typically InitRoutes or InitAdminRoutes
+// of the site's Handler.
+func (s *Sites) Add(site string, routes http.Handler) {
+	if _, ok := s.routes[site]; !ok {
+		s.order = append(s.order, site)
+	}
+	s.routes[site] = routes
+}
+
+func (s *Sites) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.route(r).ServeHTTP(w, r)
+}
+
+// route picks the routes of the request's site. A token naming a site not
+// served here goes to the first site, which refuses it as it would any
+// token of another site.
+func (s *Sites) route(r *http.Request) http.Handler {
+	if site, ok := tokenSite(r); ok {
+		if routes, ok := s.routes[site]; ok {
+			return routes
+		}
+	} else if site := requestedSite(r); site != "" {
+		if routes, ok := s.routes[site]; ok {
+			return routes
+		}
+		return s.unknown
+	}
+	if len(s.order) == 0 {
+		return s.unknown
+	}
+	return s.routes[s.order[0]]
+}
+
+// tokenSite reads the site claim of the request's bearer token without
+// verifying it; the site's own routes do that.
+func tokenSite(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = r.URL.Query().Get("token")
+	}
+	if token == "" {
+		return "", false
+	}
+	var claims service.Claims
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err != nil {
+		return "", false
+	}
+	return claims.EffectiveSite(), true
+}
+
+func requestedSite(r *http.Request) string {
+	if site := r.Header.Get(SiteHeader); site != "" {
+		return site
+	}
+	return r.URL.Query().Get("site")
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"controlling_furnace/internal/service"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestSites_RouteByTokenThenHeader(t *testing.T) {
+	sites := NewSites()
+	for _, site := range []string{"plant-a", "plant-b"} {
+		sites.Add(site, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(site))
+		}))
+	}
+	token := func(site string) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, service.Claims{UserID: 1, Site: site}).SignedString([]byte("any key"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	serve := func(target string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, vs := range header {
+			for _, v := range vs {
+				req.Header.Add(k, v)
+			}
+		}
+		w := httptest.NewRecorder()
+		sites.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, tc := range []struct {
+		name   string
+		target string
+		header http.Header
+		want   string
+	}{
+		{"no site", "/api/v1/furnace/state", nil, "plant-a"},
+		{"token", "/api/v1/furnace/state", http.Header{"Authorization": {"Bearer " + token("plant-b")}}, "plant-b"},
+		{"websocket token", "/ws?token=" + token("plant-b"), nil, "plant-b"},
+		{"the token wins over the header", "/api/v1/furnace/state", http.Header{"Authorization": {"Bearer " + token("plant-b")}, SiteHeader: {"plant-a"}}, "plant-b"},
+		{"header", "/auth/sign-in", http.Header{SiteHeader: {"plant-b"}}, "plant-b"},
+		{"query", "/api/v1/setup?site=plant-b", nil, "plant-b"},
+		// the first site refuses a token of a site not served here
+		{"token of another site", "/api/v1/furnace/state", http.Header{"Authorization": {"Bearer " + token("plant-c")}}, "plant-a"},
+		{"malformed token", "/api/v1/furnace/state", http.Header{"Authorization": {"Bearer nope"}, SiteHeader: {"plant-b"}}, "plant-b"},
+	} {
+		if w := serve(tc.target, tc.header); w.Code != http.StatusOK || w.Body.String() != tc.want {
+			t.Errorf("%s: %d %q, want %q", tc.name, w.Code, w.Body.String(), tc.want)
+		}
+	}
+
+	w := serve("/auth/sign-in", http.Header{SiteHeader: {"plant-c"}})
+	if p := decodeProblem(t, w); w.Code != http.StatusNotFound || p.Code != codeSiteNotFound {
+		t.Fatalf("unknown site: %d %+v", w.Code, p)
+	}
+}
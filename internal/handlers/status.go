@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+
+	"controlling_furnace/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusConfig gates the unauthenticated status page endpoints.
+type StatusConfig struct {
+	// Public serves /status/uptime and /status/badge.svg without a token.
+	Public bool `mapstructure:"public"`
+	// BadgeLabel is the left-hand text of the badge; "furnace" when empty.
+	BadgeLabel string `mapstructure:"badge_label"`
+}
+
+// Badge colours, as used by shields.io.
+const (
+	badgeGreen  = "#4c1"
+	badgeYellow = "#dfb317"
+	badgeRed    = "#e05d44"
+	badgeGrey   = "#9f9f9f"
+)
+
+// registerStatusRoutes mounts /status when enabled. Like the probes, it
+// lives outside the versioned API and needs no token.
+func (h *Handler) registerStatusRoutes(r *gin.Engine) {
+	if !h.status.Public {
+		return
+	}
+	status := r.Group("/status")
+	status.GET("/uptime", h.getUptime)
+	status.GET("/badge.svg", h.getStatusBadge)
+}
+
+// @Summary      Uptime summary
+// @Description  Public when status.public is enabled. Reports whether the last readiness check passed and the availability over the last 24 hours and 7 days; checks missed while the service was stopped count as down.
+// @Tags         system
+// @Produce      json
+// @Success      200  {object}  models.UptimeSummary
+// @Failure      500  {object}  Problem
+// @Router       /status/uptime [get]
+func (h *Handler) getUptime(c *gin.Context) {
+	sum, err := h.services.Uptime.UptimeSummary(c.Request.Context())
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to load uptime", "uptime_failed", err)
+		return
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.JSON(http.StatusOK, sum)
+}
+
+// @Summary      Status badge
+// @Description  Public when status.public is enabled. An SVG badge with the current status and its availability, for embedding in wikis and dashboards. Shows "unknown" if the status cannot be loaded.
+// @Tags         system
+// @Produce      image/svg+xml
+// @Param        window  query     string  false  "Availability window (default 24h)"  Enums(24h,7d)
+// @Success      200     {string}  string  "SVG image"
+// @Failure      400     {object}  Problem
+// @Router       /status/badge.svg [get]
+func (h *Handler) getStatusBadge(c *gin.Context) {
+	window := c.DefaultQuery("window", "24h")
+	if window != "24h" && window != "7d" {
+		problem(c, http.StatusBadRequest, codeInvalidQuery, "invalid 'window'; must be 24h or 7d")
+		return
+	}
+	sum, err := h.services.Uptime.UptimeSummary(c.Request.Context())
+	if err != nil {
+		if h.log != nil {
+			h.requestLog(c).Errorw("uptime_failed", "err", err)
+		}
+		sum = models.UptimeSummary{Status: models.UptimeUnknown}
+	}
+	label := h.status.BadgeLabel
+	if label == "" {
+		label = "furnace"
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "image/svg+xml; charset=utf-8", []byte(statusBadge(label, sum, window)))
+}
+
+// statusBadge renders a flat two-part badge: the label, then the status
+// with the availability over window, coloured by both.
+func statusBadge(label string, sum models.UptimeSummary, window string) string {
+	value, colour := sum.Status, badgeGrey
+	var pct *float64
+	for _, w := range sum.Windows {
+		if w.Window == window {
+			pct = w.AvailabilityPct
+		}
+	}
+	if pct != nil {
+		value += " " + strconv.FormatFloat(*pct, 'f', -1, 64) + "% (" + window + ")"
+	}
+	switch {
+	case sum.Status == models.UptimeDown:
+		colour = badgeRed
+	case sum.Status != models.UptimeUp:
+	case pct == nil || *pct >= 99:
+		colour = badgeGreen
+	default:
+		colour = badgeYellow
+	}
+	// Verdana 11px averages about 7px per character
+	lw, vw := 7*len(label)+10, 7*len(value)+10
+	label, value = html.EscapeString(label), html.EscapeString(value)
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[3]s: %[4]s">`+
+		`<title>%[3]s: %[4]s</title>`+
+		`<rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[5]d" height="20" fill="%[6]s"/>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%[7]d" y="14">%[3]s</text><text x="%[8]d" y="14">%[4]s</text></g></svg>`,
+		lw+vw, lw, label, value, vw, colour, lw/2, lw+vw/2)
+}
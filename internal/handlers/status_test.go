@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStatus_PublicUptimeAndBadge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	pct24, pct7 := 99.5, 97.25
+	up := &mockUptime{sum: models.UptimeSummary{Status: models.UptimeUp, Windows: []models.UptimeWindow{
+		{Window: "24h", AvailabilityPct: &pct24, Checks: 1433, Missed: 7},
+		{Window: "7d", AvailabilityPct: &pct7, Checks: 9800, Missed: 280},
+	}}}
+	s := &service.Service{Uptime: up}
+
+	// no token: the routes must be public
+	get := func(r http.Handler, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	if w := get(newTestRouter(s), "/status/uptime"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 while disabled, got %d", w.Code)
+	}
+
+	r := NewHandlerWithConfig(s, nil, Config{Status: StatusConfig{Public: true}}).InitRoutes()
+	w := get(r, "/status/uptime")
+	var sum models.UptimeSummary
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &sum) != nil || sum.Status != models.UptimeUp || len(sum.Windows) != 2 {
+		t.Fatalf("unexpected uptime: %d %s", w.Code, w.Body.String())
+	}
+
+	w = get(r, "/status/badge.svg")
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "image/svg+xml") {
+		t.Fatalf("expected an SVG, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(body, ">furnace<") || !strings.Contains(body, "up 99.5% (24h)") || !strings.Contains(body, badgeGreen) {
+		t.Fatalf("unexpected badge: %s", body)
+	}
+	if body := get(r, "/status/badge.svg?window=7d").Body.String(); !strings.Contains(body, "up 97.25% (7d)") || !strings.Contains(body, badgeYellow) {
+		t.Fatalf("unexpected 7d badge: %s", body)
+	}
+	if w := get(r, "/status/badge.svg?window=30d"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown window, got %d", w.Code)
+	}
+
+	up.sum.Status = models.UptimeDown
+	if body := get(r, "/status/badge.svg").Body.String(); !strings.Contains(body, "down 99.5% (24h)") || !strings.Contains(body, badgeRed) {
+		t.Fatalf("unexpected badge while down: %s", body)
+	}
+
+	// the badge stays embeddable when the record cannot be read
+	up.err = errors.New("db down")
+	if w := get(r, "/status/uptime"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+	w = get(r, "/status/badge.svg")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), ">unknown<") || !strings.Contains(w.Body.String(), badgeGrey) {
+		t.Fatalf("expected an unknown badge, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestStatusBadge_EscapesTheLabel(t *testing.T) {
+	svg := statusBadge(`<kiln & "co">`, models.UptimeSummary{Status: models.UptimeUnknown}, "24h")
+	if strings.Contains(svg, "<kiln") || !strings.Contains(svg, "&lt;kiln &amp; &#34;co&#34;&gt;") {
+		t.Fatalf("label not escaped: %s", svg)
+	}
+}
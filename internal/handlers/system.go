@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DebugConfig gates the runtime profiling endpoints.
+type DebugConfig struct {
+	// Pprof serves net/http/pprof under /debug/pprof/ to admins.
+	Pprof bool `mapstructure:"pprof"`
+}
+
+// registerDebugRoutes mounts /debug/pprof/ when enabled. It lives outside
+// the versioned API, so it is guarded by the given handlers rather than
+// by the route table.
+func (h *Handler) registerDebugRoutes(r *gin.Engine, guard ...gin.HandlerFunc) {
+	if !h.debug.Pprof {
+		return
+	}
+	dbg := r.Group("/debug/pprof", guard...)
+	dbg.GET("/*name", h.pprof)
+	dbg.POST("/symbol", gin.WrapF(pprof.Symbol))
+}
+
+// pprof dispatches to the net/http/pprof handler named by the path;
+// Index serves the listing and the named runtime profiles.
+func (h *Handler) pprof(c *gin.Context) {
+	switch strings.TrimPrefix(c.Param("name"), "/") {
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Index(c.Writer, c.Request)
+	}
+}
+
+// @Summary      Runtime diagnostics
+// @Description  Goroutine count, heap usage, database connection pool statistics, uptime and build version of the running instance. Admin only.
+// @Tags         system
+// @Produce      json
+// @Success      200  {object}  service.SystemInfo
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Router       /api/v1/system/info [get]
+// @Security     BearerAuth
+func (h *Handler) getSystemInfo(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.System.Info())
+}
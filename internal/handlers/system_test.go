@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSystemInfo_AdminOnly(t *testing.T) {
+	auth := &mockAuth{parseID: 1, parseRole: models.RoleAdmin}
+	s := &service.Service{
+		Authorization: auth,
+		System:        &mockSystem{info: service.SystemInfo{Version: "v1.2.3", Goroutines: 42}},
+	}
+	r := newTestRouter(s)
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/system/info", nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get()
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	var info service.SystemInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil || info.Version != "v1.2.3" || info.Goroutines != 42 {
+		t.Fatalf("unexpected info %+v (%v)", info, err)
+	}
+
+	auth.parseRole = models.RoleOperator
+	if w := get(); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for operator, got %d", w.Code)
+	}
+}
+
+func TestPprof_GatedByConfigAndRole(t *testing.T) {
+	auth := &mockAuth{parseID: 1, parseRole: models.RoleAdmin}
+	s := &service.Service{Authorization: auth}
+	gin.SetMode(gin.TestMode)
+
+	get := func(r http.Handler, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get(newTestRouter(s), "/debug/pprof/"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 while disabled, got %d", w.Code)
+	}
+
+	r := NewHandlerWithConfig(s, nil, Config{Debug: DebugConfig{Pprof: true}}).InitRoutes()
+	if w := get(r, "/debug/pprof/"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine") {
+		t.Fatalf("expected the profile index, got %d", w.Code)
+	}
+	if w := get(r, "/debug/pprof/goroutine?debug=1"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine profile") {
+		t.Fatalf("expected a goroutine dump, got %d", w.Code)
+	}
+
+	auth.parseRole = models.RoleOperator
+	if w := get(r, "/debug/pprof/heap"); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for operator, got %d", w.Code)
+	}
+}
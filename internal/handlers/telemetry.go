@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      List telemetry
+// @Description  Returns recorded sensor samples, oldest first. Channels: chamber (measured chamber temperature) and ambient (cold-junction temperature).
+// @Tags         telemetry
+// @Produce      json
+// @Param        channel  query     string   false  "Channel"  Enums(chamber,ambient,o2,gas_flow)
+// @Param        from     query     string   false  "Start of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD')"
+// @Param        to       query     string   false  "End of range (RFC3339, 'YYYY-MM-DD HH:MM:SS', or 'YYYY-MM-DD'). Date-only treated as end of day."
+// @Param        limit    query     integer  false  "Maximum samples (default 1000, max 10000)"
+// @Success      200      {object}  map[string]interface{}  "count, samples"
+// @Failure      400      {object}  Problem
+// @Failure      401      {object}  Problem
+// @Failure      500      {object}  Problem
+// @Router       /api/v1/telemetry [get]
+// @Security     BearerAuth
+func (h *Handler) getTelemetry(c *gin.Context) {
+	f := service.TelemetryFilter{Channel: c.Query("channel")}
+	var err error
+	if qs := c.Query("from"); qs != "" {
+		if f.From, err = parseQueryTime(qs); err != nil {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, errFromInvalid)
+			return
+		}
+	}
+	if qs := c.Query("to"); qs != "" {
+		if f.To, err = parseQueryTime(qs); err != nil {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, errToInvalid)
+			return
+		}
+		if isDateOnly(qs) {
+			f.To = f.To.Add(24*time.Hour - time.Nanosecond).UTC()
+		}
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.From.After(f.To) {
+		problem(c, http.StatusBadRequest, codeInvalidQuery, "'from' must be <= 'to'")
+		return
+	}
+	if qs := c.Query("limit"); qs != "" {
+		if f.Limit, err = strconv.Atoi(qs); err != nil || f.Limit < 0 {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, "invalid 'limit'; must be a non-negative integer")
+			return
+		}
+	}
+
+	samples, err := h.services.Telemetry.Samples(c.Request.Context(), f)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownChannel) {
+			problemFor(c, http.StatusBadRequest, err)
+			return
+		}
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to load telemetry", "telemetry_list_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"count":   len(samples),
+		"samples": samples,
+	})
+}
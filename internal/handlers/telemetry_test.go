@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestGetTelemetry(t *testing.T) {
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	tel := &mockTelemetry{resp: []models.TelemetrySample{{At: at, Channel: models.ChannelAmbient, Value: 26.4}}}
+	s := &service.Service{Authorization: &mockAuth{parseID: 1}, Telemetry: tel}
+	r := newTestRouter(s)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/telemetry?channel=ambient&to=2025-09-20&limit=50", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, body=%s", w.Code, w.Body.String())
+	}
+	var out struct {
+		Count   int                      `json:"count"`
+		Samples []models.TelemetrySample `json:"samples"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out.Count != 1 || out.Samples[0].Value != 26.4 {
+		t.Fatalf("unexpected response: %+v", out)
+	}
+	wantTo := at.Add(14*time.Hour - time.Nanosecond)
+	if tel.lastFilter.Channel != "ambient" || tel.lastFilter.Limit != 50 || !tel.lastFilter.To.Equal(wantTo) {
+		t.Fatalf("unexpected filter: %+v", tel.lastFilter)
+	}
+
+	for _, q := range []string{"limit=-1", "from=yesterday", "from=2025-09-21&to=2025-09-20"} {
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry?"+q, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", q, w.Code)
+		}
+	}
+
+	tel.err = service.ErrUnknownChannel
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry?channel=pressure", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown channel, got %d", w.Code)
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserRoleRequest is the body of PUT /admin/users/{id}/role.
+type UserRoleRequest struct {
+	// New role of the user
+	Role string `json:"role" binding:"required" enums:"admin,operator,viewer" example:"operator"`
+}
+
+// UserRoleResponse confirms a role change.
+type UserRoleResponse struct {
+	ID   int    `json:"id" example:"7"`
+	Role string `json:"role" example:"operator"`
+}
+
+// @Summary      Change a user's role
+// @Description  Sets the role of a user: admin, operator or viewer. Users who sign up start as viewers; this is how an admin lets them control the furnace. Tokens carry the role, so the change applies from the user's next sign-in. The site's last admin cannot be demoted (409). Admin only.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int              true  "User ID"
+// @Param        body  body      UserRoleRequest  true  "New role"
+// @Success      200   {object}  UserRoleResponse
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      404   {object}  Problem
+// @Failure      409   {object}  Problem
+// @Router       /api/v1/admin/users/{id}/role [put]
+// @Security     BearerAuth
+func (h *Handler) setUserRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		problem(c, http.StatusBadRequest, codeInvalidID, "invalid user id")
+		return
+	}
+	var req UserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, errInvalidBodyPref+err.Error())
+		return
+	}
+	if err := h.services.SetRole(c.Request.Context(), id, req.Role); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRole):
+			problemFor(c, http.StatusBadRequest, err)
+		case errors.Is(err, service.ErrUserNotFound):
+			problemFor(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrLastAdmin):
+			problemFor(c, http.StatusConflict, err)
+		default:
+			h.logAndJSONError(c, http.StatusInternalServerError, "failed to change role", "user_role_failed", err)
+		}
+		return
+	}
+	if h.log != nil {
+		h.requestLog(c).Warnw("user_role_changed", "user", id, "role", req.Role, "userId", c.GetInt(ctxKeyUserID))
+	}
+	c.JSON(http.StatusOK, UserRoleResponse{ID: id, Role: req.Role})
+}
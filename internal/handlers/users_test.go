@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestSetUserRole(t *testing.T) {
+	auth := &mockAuth{parseID: 1, parseRole: models.RoleOperator}
+	r := newTestRouter(&service.Service{Authorization: auth})
+	put := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := put("/api/v1/admin/users/7/role", `{"role":"admin"}`); w.Code != http.StatusForbidden {
+		t.Fatalf("operator promoting a user: %d, want 403", w.Code)
+	}
+
+	auth.parseRole = models.RoleAdmin
+	if w := put("/api/v1/admin/users/7/role", `{"role":"operator"}`); w.Code != http.StatusOK || auth.lastSetRole != "operator" {
+		t.Fatalf("admin: %d %s, role %q", w.Code, w.Body.String(), auth.lastSetRole)
+	}
+	if p := decodeProblem(t, put("/api/v1/admin/users/x/role", `{"role":"operator"}`)); p.Status != http.StatusBadRequest || p.Code != codeInvalidID {
+		t.Fatalf("bad id: %+v", p)
+	}
+	auth.setRoleErr = service.ErrInvalidRole
+	if p := decodeProblem(t, put("/api/v1/admin/users/7/role", `{"role":"root"}`)); p.Status != http.StatusBadRequest || p.Code != "invalid_role" {
+		t.Fatalf("unknown role: %+v", p)
+	}
+	auth.setRoleErr = service.ErrUserNotFound
+	if p := decodeProblem(t, put("/api/v1/admin/users/99/role", `{"role":"viewer"}`)); p.Status != http.StatusNotFound || p.Code != "user_not_found" {
+		t.Fatalf("missing user: %+v", p)
+	}
+	auth.setRoleErr = service.ErrLastAdmin
+	if p := decodeProblem(t, put("/api/v1/admin/users/1/role", `{"role":"viewer"}`)); p.Status != http.StatusConflict || p.Code != "last_admin" {
+		t.Fatalf("last admin: %+v", p)
+	}
+}
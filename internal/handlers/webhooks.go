@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookRequest is the payload for registering or replacing a webhook.
+type WebhookRequest struct {
+	URL string `json:"url" binding:"required" example:"https://mes.example.com/hooks/furnace"`
+	// Event types delivered to the URL
+	EventTypes []string `json:"event_types" binding:"required" example:"STOP,ERROR"`
+	// Keys the X-Furnace-Signature HMAC; on replace, omit to keep the stored secret
+	Secret string `json:"secret" example:"s3cret"`
+	// Defaults to true
+	Enabled *bool `json:"enabled" example:"true"`
+}
+
+func (r WebhookRequest) webhook() models.Webhook {
+	enabled := r.Enabled == nil || *r.Enabled
+	return models.Webhook{URL: r.URL, EventTypes: r.EventTypes, Secret: r.Secret, Enabled: enabled}
+}
+
+// webhookID parses the :id path parameter, answering 400 if it is not a
+// positive integer.
+func webhookID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		problem(c, http.StatusBadRequest, codeInvalidID, "invalid webhook id")
+		return 0, false
+	}
+	return id, true
+}
+
+// webhookError answers for errors returned by the webhook service.
+func (h *Handler) webhookError(c *gin.Context, err error, msg, logKey string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidWebhook):
+		problemFor(c, http.StatusBadRequest, err)
+	case errors.Is(err, service.ErrWebhookNotFound), errors.Is(err, service.ErrDeliveryNotFound):
+		problemFor(c, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrDeliveryNotFailed):
+		problemFor(c, http.StatusConflict, err)
+	default:
+		h.logAndJSONError(c, http.StatusInternalServerError, msg, logKey, err)
+	}
+}
+
+// @Summary      List webhooks
+// @Tags         webhooks
+// @Produce      json
+// @Success      200  {array}   models.Webhook
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/webhooks [get]
+// @Security     BearerAuth
+func (h *Handler) listWebhooks(c *gin.Context) {
+	hooks, err := h.services.Webhooks.ListWebhooks(c.Request.Context())
+	if err != nil {
+		h.logAndJSONError(c, http.StatusInternalServerError, "failed to list webhooks", "webhooks_list_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, hooks)
+}
+
+// @Summary      Get webhook
+// @Tags         webhooks
+// @Produce      json
+// @Param        id   path      int  true  "Webhook ID"
+// @Success      200  {object}  models.Webhook
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/webhooks/{id} [get]
+// @Security     BearerAuth
+func (h *Handler) getWebhook(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	w, err := h.services.Webhooks.GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		h.webhookError(c, err, "failed to load webhook", "webhook_get_failed")
+		return
+	}
+	c.JSON(http.StatusOK, w)
+}
+
+// @Summary      Register webhook
+// @Description  Events of the listed types logged from now on are POSTed to the URL as JSON, with X-Furnace-Event and X-Furnace-Delivery headers. With a secret, X-Furnace-Signature carries "sha256=" and the hex HMAC-SHA256 of the body. Non-2xx answers are retried with exponential backoff.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        body  body      WebhookRequest  true  "Webhook"
+// @Success      201   {object}  models.Webhook
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      500   {object}  Problem
+// @Router       /api/v1/webhooks [post]
+// @Security     BearerAuth
+func (h *Handler) createWebhook(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, "invalid request body")
+		return
+	}
+	w, err := h.services.Webhooks.CreateWebhook(c.Request.Context(), req.webhook(), c.GetInt(ctxKeyUserID))
+	if err != nil {
+		h.webhookError(c, err, "failed to create webhook", "webhook_create_failed")
+		return
+	}
+	c.JSON(http.StatusCreated, w)
+}
+
+// @Summary      Replace webhook
+// @Description  Omit secret to keep the stored one. Deliveries queued while the webhook is disabled fail without being sent.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int             true  "Webhook ID"
+// @Param        body  body      WebhookRequest  true  "Webhook"
+// @Success      200   {object}  models.Webhook
+// @Failure      400   {object}  Problem
+// @Failure      401   {object}  Problem
+// @Failure      403   {object}  Problem
+// @Failure      404   {object}  Problem
+// @Failure      500   {object}  Problem
+// @Router       /api/v1/webhooks/{id} [put]
+// @Security     BearerAuth
+func (h *Handler) updateWebhook(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidBody, "invalid request body")
+		return
+	}
+	w := req.webhook()
+	w.ID = id
+	w, err := h.services.Webhooks.UpdateWebhook(c.Request.Context(), w)
+	if err != nil {
+		h.webhookError(c, err, "failed to update webhook", "webhook_update_failed")
+		return
+	}
+	c.JSON(http.StatusOK, w)
+}
+
+// @Summary      Delete webhook
+// @Description  Also removes its deliveries, including pending ones.
+// @Tags         webhooks
+// @Param        id   path  int  true  "Webhook ID"
+// @Success      204
+// @Failure      400  {object}  Problem
+// @Failure      401  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      500  {object}  Problem
+// @Router       /api/v1/webhooks/{id} [delete]
+// @Security     BearerAuth
+func (h *Handler) deleteWebhook(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	if err := h.services.Webhooks.DeleteWebhook(c.Request.Context(), id); err != nil {
+		h.webhookError(c, err, "failed to delete webhook", "webhook_delete_failed")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary      List webhook deliveries
+// @Description  Returns the deliveries of a webhook, newest first, with the attempts made, the last HTTP status or error and, while pending, the next attempt.
+// @Tags         webhooks
+// @Produce      json
+// @Param        id      path      int     true   "Webhook ID"
+// @Param        status  query     string  false  "Only deliveries with this status"  Enums(pending,delivered,failed)
+// @Param        limit   query     int     false  "Maximum deliveries (default 100, max 1000)"
+// @Success      200     {object}  map[string]interface{}  "count, deliveries"
+// @Failure      400     {object}  Problem
+// @Failure      401     {object}  Problem
+// @Failure      403     {object}  Problem
+// @Failure      404     {object}  Problem
+// @Failure      500     {object}  Problem
+// @Router       /api/v1/webhooks/{id}/deliveries [get]
+// @Security     BearerAuth
+func (h *Handler) listWebhookDeliveries(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	var limit int
+	if qs := c.Query("limit"); qs != "" {
+		n, err := strconv.Atoi(qs)
+		if err != nil || n < 0 {
+			problem(c, http.StatusBadRequest, codeInvalidQuery, "invalid 'limit'; must be a non-negative integer")
+			return
+		}
+		limit = n
+	}
+	deliveries, err := h.services.Webhooks.ListDeliveries(c.Request.Context(), id, c.Query("status"), limit)
+	if err != nil {
+		h.webhookError(c, err, "failed to list webhook deliveries", "webhook_deliveries_list_failed")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"count":      len(deliveries),
+		"deliveries": deliveries,
+	})
+}
+
+// @Summary      Replay webhook delivery
+// @Description  Queues a failed delivery again with its original payload and a fresh set of attempts; the dispatcher sends it on its next pass. The webhook must be enabled.
+// @Tags         webhooks
+// @Produce      json
+// @Param        id           path      int  true  "Webhook ID"
+// @Param        delivery_id  path      int  true  "Delivery ID"
+// @Success      202          {object}  models.WebhookDelivery
+// @Failure      400          {object}  Problem
+// @Failure      401          {object}  Problem
+// @Failure      403          {object}  Problem
+// @Failure      404          {object}  Problem
+// @Failure      409          {object}  Problem
+// @Failure      500          {object}  Problem
+// @Router       /api/v1/webhooks/{id}/deliveries/{delivery_id}/replay [post]
+// @Security     BearerAuth
+func (h *Handler) replayWebhookDelivery(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+	if err != nil || deliveryID <= 0 {
+		problem(c, http.StatusBadRequest, codeInvalidID, "invalid delivery id")
+		return
+	}
+	d, err := h.services.Webhooks.ReplayDelivery(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		h.webhookError(c, err, "failed to replay webhook delivery", "webhook_delivery_replay_failed")
+		return
+	}
+	c.JSON(http.StatusAccepted, d)
+}
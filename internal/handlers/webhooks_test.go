@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+)
+
+func TestWebhooks(t *testing.T) {
+	webhooks := &mockWebhooks{
+		webhook:  models.Webhook{ID: 2, URL: "https://mes.example.com/hook", EventTypes: []string{"STOP", "ERROR"}, Secret: "s3cret", HasSecret: true, Enabled: true},
+		delivery: models.WebhookDelivery{ID: 5, WebhookID: 2, EventType: "STOP", Status: models.DeliveryFailed, Attempts: 8, ResponseStatus: 503},
+	}
+	auth := &mockAuth{parseID: 1, parseRole: models.RoleAdmin}
+	r := newTestRouter(&service.Service{Authorization: auth, Webhooks: webhooks})
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "/api/v1/webhooks", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"has_secret":true`) || strings.Contains(w.Body.String(), "s3cret") {
+		t.Fatalf("list must not reveal the secret: status=%d body=%s", w.Code, w.Body.String())
+	}
+	w = do(http.MethodPost, "/api/v1/webhooks", `{"url":"https://mes.example.com/hook","event_types":["stop","error"],"secret":"s3cret"}`)
+	if w.Code != http.StatusCreated || webhooks.lastUserID != 1 || !webhooks.lastHook.Enabled || webhooks.lastHook.Secret != "s3cret" {
+		t.Fatalf("create: status=%d hook=%+v", w.Code, webhooks.lastHook)
+	}
+	if w := do(http.MethodPost, "/api/v1/webhooks", `{"url":"https://mes.example.com/hook"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without event types, got %d", w.Code)
+	}
+	if w := do(http.MethodPut, "/api/v1/webhooks/2", `{"url":"https://mes.example.com/hook","event_types":["STOP"],"enabled":false}`); w.Code != http.StatusOK ||
+		webhooks.lastHook.ID != 2 || webhooks.lastHook.Enabled {
+		t.Fatalf("update: status=%d hook=%+v", w.Code, webhooks.lastHook)
+	}
+
+	w = do(http.MethodGet, "/api/v1/webhooks/2/deliveries?status=failed&limit=20", "")
+	if w.Code != http.StatusOK || webhooks.lastStatus != "failed" || webhooks.lastLimit != 20 || !strings.Contains(w.Body.String(), `"response_status":503`) {
+		t.Fatalf("deliveries: status=%d body=%s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "/api/v1/webhooks/2/deliveries?limit=x", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad limit, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v1/webhooks/x", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad id, got %d", w.Code)
+	}
+
+	webhooks.err = service.ErrInvalidWebhook
+	if w := do(http.MethodPost, "/api/v1/webhooks", `{"url":"ftp://x","event_types":["STOP"]}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid webhook, got %d", w.Code)
+	}
+	webhooks.err = service.ErrWebhookNotFound
+	if w := do(http.MethodGet, "/api/v1/webhooks/9/deliveries", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+	webhooks.err = service.ErrDeliveryNotFailed
+	if w := do(http.MethodPost, "/api/v1/webhooks/2/deliveries/5/replay", ""); w.Code != http.StatusConflict ||
+		decodeProblem(t, w).Code != "delivery_not_replayable" {
+		t.Fatalf("expected 409 replaying a settled delivery, got %d: %s", w.Code, w.Body.String())
+	}
+	webhooks.err = service.ErrDeliveryNotFound
+	if w := do(http.MethodPost, "/api/v1/webhooks/2/deliveries/6/replay", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown delivery, got %d", w.Code)
+	}
+	webhooks.err = nil
+	if w := do(http.MethodPost, "/api/v1/webhooks/2/deliveries/x/replay", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad delivery id, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/v1/webhooks/2/deliveries/5/replay", ""); w.Code != http.StatusAccepted || webhooks.lastReplay != [2]int64{2, 5} {
+		t.Fatalf("replay: status=%d args=%v", w.Code, webhooks.lastReplay)
+	}
+	if w := do(http.MethodDelete, "/api/v1/webhooks/2", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: status=%d", w.Code)
+	}
+}
+
+func TestWebhooks_RequireAdmin(t *testing.T) {
+	auth := &mockAuth{parseID: 3, parseRole: models.RoleOperator}
+	r := newTestRouter(&service.Service{Authorization: auth, Webhooks: &mockWebhooks{}})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for an operator, got %d", w.Code)
+	}
+}
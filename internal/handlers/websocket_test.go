@@ -2,8 +2,10 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -11,6 +13,7 @@ import (
 	"time"
 
 	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
 	"controlling_furnace/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -63,11 +66,12 @@ func TestWebSocket_StateStream_InitialAndPeriodic(t *testing.T) {
 		RemainingSeconds: 60,
 		IsRunning:        true,
 	}}
-	s := &service.Service{Monitoring: mon}
+	s := &service.Service{Monitoring: mon, Authorization: &mockAuth{parseID: 1}}
 
 	// Build router with /ws
 	r := gin.New()
 	h := NewHandler(s, nil)
+	_ = h.SetWSConfig(WSConfig{MinInterval: 10 * time.Millisecond})
 	r.GET("/ws", h.wsConnect)
 
 	srv := httptest.NewServer(r)
@@ -79,6 +83,7 @@ func TestWebSocket_StateStream_InitialAndPeriodic(t *testing.T) {
 	u.Path = "/ws"
 	q := u.Query()
 	q.Set("interval_ms", "20") // fast ticks for the test
+	q.Set("token", "valid")
 	u.RawQuery = q.Encode()
 
 	dialer := websocket.Dialer{HandshakeTimeout: 2 * time.Second}
@@ -124,7 +129,7 @@ func TestWebSocket_StateStream_InitialAndPeriodic(t *testing.T) {
 
 func TestWebSocket_InitialGetStateError_Closes(t *testing.T) {
 	mon := &mockMonitoring{err: errors.New("boom")}
-	s := &service.Service{Monitoring: mon}
+	s := &service.Service{Monitoring: mon, Authorization: &mockAuth{parseID: 1}}
 
 	r := gin.New()
 	h := NewHandler(s, nil)
@@ -136,6 +141,7 @@ func TestWebSocket_InitialGetStateError_Closes(t *testing.T) {
 	u, _ := url.Parse(srv.URL)
 	u.Scheme = "ws"
 	u.Path = "/ws"
+	u.RawQuery = "token=valid"
 	dialer := websocket.Dialer{HandshakeTimeout: 2 * time.Second}
 	conn, _, err := dialer.Dial(u.String(), nil)
 	if err != nil {
@@ -150,3 +156,492 @@ func TestWebSocket_InitialGetStateError_Closes(t *testing.T) {
 		t.Fatalf("expected read error (closed), got message: %s", string(raw))
 	}
 }
+
+func TestStreamInterval_Floors(t *testing.T) {
+	cfg := WSConfig{RoleMinInterval: map[string]time.Duration{wsAnonymousRole: 2 * time.Second}}.withDefaults()
+
+	if d, note, ok := cfg.streamInterval(500*time.Millisecond, models.RoleOperator); d != 500*time.Millisecond || note != "" || !ok {
+		t.Fatalf("operator above the global floor should pass, got %s %q %v", d, note, ok)
+	}
+	if d, note, ok := cfg.streamInterval(time.Millisecond, models.RoleOperator); d != 250*time.Millisecond || note == "" || !ok {
+		t.Fatalf("expected clamp to 250ms with a note, got %s %q %v", d, note, ok)
+	}
+	if d, _, _ := cfg.streamInterval(time.Second, wsAnonymousRole); d != 2*time.Second {
+		t.Fatalf("expected anonymous floor of 2s, got %s", d)
+	}
+
+	cfg.RejectTooFast = true
+	if _, note, ok := cfg.streamInterval(100*time.Millisecond, models.RoleViewer); ok || note == "" {
+		t.Fatalf("expected rejection with an explanation, got %q %v", note, ok)
+	}
+	if err := (WSConfig{RoleMinInterval: map[string]time.Duration{"viewer": time.Minute}}).Validate(); !errors.Is(err, ErrInvalidWSConfig) {
+		t.Fatalf("role floor above max_interval must be invalid, got %v", err)
+	}
+}
+
+func TestWebSocket_IntervalBelowFloor(t *testing.T) {
+	s := &service.Service{
+		Monitoring:    &mockMonitoring{state: models.FurnaceState{Mode: "STANDBY"}},
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleViewer},
+	}
+	r := gin.New()
+	h := NewHandler(s, nil)
+	r.GET("/ws", h.wsConnect)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	dial := func(query string) *websocket.Conn {
+		t.Helper()
+		u, _ := url.Parse(srv.URL)
+		u.Scheme = "ws"
+		u.Path = "/ws"
+		u.RawQuery = query
+		conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+		if err != nil {
+			t.Fatalf("dial error: %v", err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		return conn
+	}
+	var env wsEnvelope
+
+	conn := dial("interval_ms=1&token=valid")
+	if err := conn.ReadJSON(&env); err != nil || env.Type != "notice" || env.Message == "" {
+		t.Fatalf("expected clamp notice, got %+v (err %v)", env, err)
+	}
+	if err := conn.ReadJSON(&env); err != nil || env.Type != "state" {
+		t.Fatalf("expected state after notice, got %+v (err %v)", env, err)
+	}
+	_ = conn.Close()
+
+	_ = h.SetWSConfig(WSConfig{RoleMinInterval: map[string]time.Duration{models.RoleViewer: 5 * time.Second}, RejectTooFast: true})
+	conn = dial("interval=1s&token=valid")
+	defer conn.Close()
+	env = wsEnvelope{}
+	if err := conn.ReadJSON(&env); err != nil || env.Type != "error" || env.Error == "" {
+		t.Fatalf("expected error envelope for viewer, got %+v (err %v)", env, err)
+	}
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+		t.Fatalf("expected policy-violation close, got %v", err)
+	}
+}
+
+func TestWebSocket_FollowsStateBus(t *testing.T) {
+	bus := service.NewStateBroker()
+	mon := &mockMonitoring{state: models.FurnaceState{Mode: "STANDBY", CurrentTempC: 25}}
+	s := &service.Service{Monitoring: mon, StateBus: bus, Authorization: &mockAuth{parseID: 1}}
+
+	r := gin.New()
+	h := NewHandler(s, nil)
+	_ = h.SetWSConfig(WSConfig{MinInterval: 10 * time.Millisecond})
+	r.GET("/ws", h.wsConnect)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	u.Scheme = "ws"
+	u.Path = "/ws"
+	u.RawQuery = "interval_ms=20&token=valid"
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		t.Fatalf("dial error: %v", err)
+	}
+	defer conn.Close()
+
+	var env struct {
+		Type string              `json:"type"`
+		Data models.FurnaceState `json:"data"`
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if err := conn.ReadJSON(&env); err != nil || env.Data.CurrentTempC != 25 {
+		t.Fatalf("expected initial state from monitoring, got %+v (err %v)", env, err)
+	}
+
+	// The handler subscribed before sending the initial state. The database
+	// is not read again; only the bus moves the stream.
+	mon.err = errors.New("db gone")
+	deadline := time.Now().Add(time.Second)
+	bus.Publish(models.FurnaceState{Mode: "HEAT", CurrentTempC: 410})
+	for time.Now().Before(deadline) {
+		_ = conn.SetReadDeadline(deadline)
+		if err := conn.ReadJSON(&env); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if env.Data.CurrentTempC == 410 {
+			return
+		}
+	}
+	t.Fatal("published state never reached the stream")
+}
+
+func TestWebSocket_BusFramesCarryDerivedFields(t *testing.T) {
+	ctx := context.Background()
+	s := service.NewServiceWithConfig(repository.NewInMemory(), service.DefaultConfig())
+	s.Authorization = &mockAuth{parseID: 1}
+	if err := s.Furnace.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Furnace.SetMode(ctx, service.ModeParams{Mode: service.ModeHeat, TargetTempC: 60, DurationSec: 600}); err != nil {
+		t.Fatal(err)
+	}
+	// reach the target and soak for a while
+	for i := 0; i < 10; i++ {
+		if err := s.Simulator.Step(ctx, 10*time.Second); err != nil {
+			t.Fatalf("Step: %v", err)
+		}
+	}
+
+	r := gin.New()
+	h := NewHandler(s, nil)
+	_ = h.SetWSConfig(WSConfig{MinInterval: 10 * time.Millisecond})
+	r.GET("/ws", h.wsConnect)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	u.Scheme = "ws"
+	u.Path = "/ws"
+	u.RawQuery = "interval_ms=20&token=valid"
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		t.Fatalf("dial error: %v", err)
+	}
+	defer conn.Close()
+
+	var env struct {
+		Type string              `json:"type"`
+		Data models.FurnaceState `json:"data"`
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	for env.Type != "state" {
+		if err := conn.ReadJSON(&env); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+	}
+	first := env.Data.UpdatedAt
+
+	if err := s.Simulator.Step(ctx, 10*time.Second); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		_ = conn.SetReadDeadline(deadline)
+		env.Data = models.FurnaceState{} // omitted fields keep their old values
+		if err := conn.ReadJSON(&env); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if env.Type != "state" || !env.Data.UpdatedAt.After(first) {
+			continue
+		}
+		st := env.Data
+		if st.RateCPerSec == nil || st.ETASeconds == nil || st.SoakPercent == nil || st.SoakEndsAt == nil {
+			t.Fatalf("published frame lost its derived fields: rate=%v eta=%v soak=%v ends=%v",
+				st.RateCPerSec, st.ETASeconds, st.SoakPercent, st.SoakEndsAt)
+		}
+		return
+	}
+	t.Fatal("published state never reached the stream")
+}
+
+func TestWebSocket_GoAway(t *testing.T) {
+	probes := &mockProbes{report: service.ReadinessReport{Ready: true}}
+	s := &service.Service{Monitoring: &mockMonitoring{state: models.FurnaceState{Mode: "STANDBY"}}, Probes: probes, Authorization: &mockAuth{parseID: 1}}
+	r := gin.New()
+	h := NewHandler(s, nil)
+	_ = h.SetWSConfig(WSConfig{PongWait: time.Second, GoAwayBackoff: 2 * time.Second, GoAwayJitter: time.Second, Alternate: "wss://b.example.com/ws"})
+	r.GET("/ws", h.wsConnect)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	dial := func() *websocket.Conn {
+		t.Helper()
+		u, _ := url.Parse(srv.URL)
+		u.Scheme, u.Path, u.RawQuery = "ws", "/ws", "token=valid"
+		conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+		if err != nil {
+			t.Fatalf("dial error: %v", err)
+		}
+		return conn
+	}
+	expectGoAway := func(conn *websocket.Conn, reason string) {
+		t.Helper()
+		var env struct {
+			Type string   `json:"type"`
+			Data wsGoAway `json:"data"`
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+		for env.Type != "goaway" {
+			if err := conn.ReadJSON(&env); err != nil {
+				t.Fatalf("no goaway before %v", err)
+			}
+		}
+		if env.Data.Reason != reason || env.Data.Alternate != "wss://b.example.com/ws" ||
+			env.Data.RetryAfterMs < 2000 || env.Data.RetryAfterMs >= 3000 {
+			t.Fatalf("unexpected goaway: %+v", env.Data)
+		}
+		if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+			t.Fatalf("expected going-away close, got %v", err)
+		}
+	}
+
+	// the readiness check runs at the keepalive ping (900ms here)
+	probes.report.Ready = false
+	conn := dial()
+	expectGoAway(conn, GoAwayDegraded)
+	_ = conn.Close()
+
+	probes.report.Ready = true
+	h.probe.checked = time.Time{}
+	conn = dial()
+	defer conn.Close()
+	h.GoAway(GoAwayShutdown)
+	expectGoAway(conn, GoAwayShutdown)
+
+	late := dial()
+	defer late.Close()
+	expectGoAway(late, GoAwayShutdown)
+}
+
+func TestWSConfig_ValidateAlternate(t *testing.T) {
+	for _, alt := range []string{"", "ws://b:8080/ws", "wss://b.example.com/ws"} {
+		if err := (WSConfig{Alternate: alt}).Validate(); err != nil {
+			t.Errorf("alternate %q: %v", alt, err)
+		}
+	}
+	for _, alt := range []string{"http://b/ws", "b:8080", "wss://"} {
+		if err := (WSConfig{Alternate: alt}).Validate(); !errors.Is(err, ErrInvalidWSConfig) {
+			t.Errorf("alternate %q: err = %v, want ErrInvalidWSConfig", alt, err)
+		}
+	}
+}
+
+func TestWebSocket_FollowsStatePermission(t *testing.T) {
+	auth := &mockAuth{parseID: 1, parseRole: models.RoleViewer}
+	s := &service.Service{Monitoring: &mockMonitoring{state: models.FurnaceState{Mode: "STANDBY"}}, Authorization: auth}
+	dial := func(cfg Config, query string) (*websocket.Conn, int) {
+		t.Helper()
+		r := gin.New()
+		h := NewHandlerWithConfig(s, nil, cfg)
+		r.GET("/ws", h.wsConnect)
+		srv := httptest.NewServer(r)
+		t.Cleanup(srv.Close)
+		u, _ := url.Parse(srv.URL)
+		u.Scheme, u.Path, u.RawQuery = "ws", "/ws", query
+		conn, resp, err := websocket.DefaultDialer.Dial(u.String(), nil)
+		if err != nil {
+			return nil, resp.StatusCode
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		return conn, http.StatusSwitchingProtocols
+	}
+
+	if _, code := dial(Config{}, ""); code != http.StatusUnauthorized {
+		t.Fatalf("anonymous: status %d, want 401", code)
+	}
+	auth.parseErr = errors.New("expired")
+	if _, code := dial(Config{}, "token=old"); code != http.StatusUnauthorized {
+		t.Fatalf("invalid token: status %d, want 401", code)
+	}
+	auth.parseErr = nil
+	if _, code := dial(Config{}, "token=valid"); code != http.StatusSwitchingProtocols {
+		t.Fatalf("viewer: status %d, want upgrade", code)
+	}
+
+	operators := Config{Permissions: Permissions{{Route: "GET /furnace/state", Require: PermOperate}}}
+	if _, code := dial(operators, "token=valid"); code != http.StatusForbidden {
+		t.Fatalf("viewer on an operator-only state: status %d, want 403", code)
+	}
+	public := Config{Permissions: Permissions{{Route: "GET /furnace/state", Require: PermPublic}}}
+	if _, code := dial(public, ""); code != http.StatusSwitchingProtocols {
+		t.Fatalf("anonymous on a public state: status %d, want upgrade", code)
+	}
+}
+
+func TestWebSocket_ResumeReplaysMissedEvents(t *testing.T) {
+	base := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	event := func(n int) models.FurnaceEvent {
+		return models.FurnaceEvent{EventID: fmt.Sprintf("e%d", n), Type: "START", OccurredAt: base.Add(time.Duration(n) * time.Minute)}
+	}
+	feed := &mockEventFeed{events: []models.FurnaceEvent{event(1), event(2)}}
+	s := &service.Service{
+		Monitoring:    &mockMonitoring{state: models.FurnaceState{Mode: "STANDBY"}},
+		EventTail:     feed,
+		Authorization: &mockAuth{parseID: 1, parseRole: models.RoleViewer},
+	}
+	dial := func(cfg Config, query string) (*websocket.Conn, int) {
+		t.Helper()
+		r := gin.New()
+		h := NewHandlerWithConfig(s, nil, cfg)
+		r.GET("/ws", h.wsConnect)
+		srv := httptest.NewServer(r)
+		t.Cleanup(srv.Close)
+		u, _ := url.Parse(srv.URL)
+		u.Scheme, u.Path, u.RawQuery = "ws", "/ws", "interval=10s&token=valid&"+query
+		conn, resp, err := websocket.DefaultDialer.Dial(u.String(), nil)
+		if err != nil {
+			return nil, resp.StatusCode
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		return conn, http.StatusSwitchingProtocols
+	}
+	type envelope struct {
+		Type    string              `json:"type"`
+		Data    models.FurnaceEvent `json:"data"`
+		Message string              `json:"message"`
+		Resume  string              `json:"resume"`
+	}
+	read := func(conn *websocket.Conn, want string) envelope {
+		t.Helper()
+		var env envelope
+		if err := conn.ReadJSON(&env); err != nil || env.Type != want || env.Resume == "" {
+			t.Fatalf("expected %s with a resume token, got %+v (err %v)", want, env, err)
+		}
+		return env
+	}
+
+	// a live stream starts at the end of the log
+	conn, _ := dial(Config{}, "events=true")
+	read(conn, "state")
+	feed.append(event(3))
+	resume := read(conn, "event")
+	if resume.Data.EventID != "e3" {
+		t.Fatalf("expected the appended event, got %+v", resume.Data)
+	}
+	_ = conn.Close()
+
+	// events appended while disconnected are replayed after the state
+	feed.append(event(4), event(5))
+	conn, _ = dial(Config{}, "resume="+resume.Resume)
+	read(conn, "state")
+	if env := read(conn, "event"); env.Data.EventID != "e4" {
+		t.Fatalf("expected replay from e4, got %+v", env.Data)
+	}
+	if env := read(conn, "event"); env.Data.EventID != "e5" {
+		t.Fatalf("expected replay of e5, got %+v", env.Data)
+	}
+	_ = conn.Close()
+
+	// a purged resume point continues by time, with a warning
+	feed.mu.Lock()
+	feed.purged = map[string]bool{"e3": true}
+	feed.mu.Unlock()
+	conn, _ = dial(Config{}, "resume="+resume.Resume)
+	read(conn, "state")
+	if env := read(conn, "notice"); env.Message == "" {
+		t.Fatalf("expected a notice about missing events, got %+v", env)
+	}
+	if env := read(conn, "event"); env.Data.EventID != "e4" {
+		t.Fatalf("expected replay by time from e4, got %+v", env.Data)
+	}
+
+	if _, code := dial(Config{}, "resume=bogus"); code != http.StatusBadRequest {
+		t.Fatalf("invalid token: status %d, want 400", code)
+	}
+	admins := Config{Permissions: Permissions{{Route: "GET /logs/tail", Require: PermAdmin}}}
+	if _, code := dial(admins, "events=true"); code != http.StatusForbidden {
+		t.Fatalf("viewer on admin-only logs: status %d, want 403", code)
+	}
+	if _, code := dial(admins, ""); code != http.StatusSwitchingProtocols {
+		t.Fatalf("viewer without events: status %d, want upgrade", code)
+	}
+}
+
+func TestWebSocket_OutlivesServerWriteTimeout(t *testing.T) {
+	s := &service.Service{Monitoring: &mockMonitoring{state: models.FurnaceState{Mode: "HEAT"}}, Authorization: &mockAuth{parseID: 1}}
+	r := gin.New()
+	h := NewHandler(s, nil)
+	_ = h.SetWSConfig(WSConfig{MinInterval: 10 * time.Millisecond})
+	r.GET("/ws", h.wsConnect)
+
+	srv := httptest.NewUnstartedServer(r)
+	srv.Config.WriteTimeout = 100 * time.Millisecond
+	srv.Config.ReadTimeout = 100 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	u.Scheme = "ws"
+	u.RawQuery = url.Values{"interval_ms": {"20"}, "token": {"valid"}}.Encode()
+	u.Path = "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	// states keep arriving well past the timeouts of the request
+	until := time.Now().Add(400 * time.Millisecond)
+	for time.Now().Before(until) {
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		var env wsEnvelope
+		if err := conn.ReadJSON(&env); err != nil {
+			t.Fatalf("stream cut after the write timeout: %v", err)
+		}
+	}
+}
+
+func TestHandler_DrainStreams(t *testing.T) {
+	s := &service.Service{
+		Monitoring:    &mockMonitoring{state: models.FurnaceState{Mode: "STANDBY"}},
+		EventTail:     &mockEventLog{tailWait: true},
+		Authorization: &mockAuth{parseID: 1},
+	}
+	h := NewHandler(s, nil)
+	if err := h.DrainStreams(context.Background()); err != nil {
+		t.Fatalf("nothing to drain: %v", err)
+	}
+
+	h = NewHandler(s, nil)
+	r := h.InitRoutes()
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	u.Scheme, u.Path, u.RawQuery = "ws", "/ws", "token=valid"
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	var env wsEnvelope
+	if err := conn.ReadJSON(&env); err != nil || env.Type != "state" {
+		t.Fatalf("initial state: %+v, %v", env, err)
+	}
+
+	polled := make(chan int, 1)
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/v1/logs/tail?wait=60s", nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			polled <- 0
+			return
+		}
+		resp.Body.Close()
+		polled <- resp.StatusCode
+	}()
+	time.Sleep(50 * time.Millisecond) // the poll is waiting
+
+	// the client reads its goaway and close frame while the drain waits
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := h.DrainStreams(ctx); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	select {
+	case code := <-polled:
+		if code != http.StatusOK {
+			t.Fatalf("long poll answered %d", code)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("the long poll still waits after the drain")
+	}
+}
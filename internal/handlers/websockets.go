@@ -2,31 +2,30 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 )
 
-// Send/receive timing configuration and message size limits.
-const (
-	writeWait        = 10 * time.Second
-	pongWait         = 60 * time.Second
-	pingPeriod       = (pongWait * 9) / 10
-	maxMsgSize       = 1 << 12 // 4 KB
-	defaultInterval  = 1 * time.Second
-	maxInterval      = 10 * time.Second
-	maxIntervalMilli = 10_000 // 10s in ms
-)
+// Message size limit; timings live in WSConfig.
+const maxMsgSize = 1 << 12 // 4 KB
 
 // Envelope used for WebSocket messages.
 // If this type already exists in this package, keep a single definition.
 type wsEnvelope struct {
-	Type  string      `json:"type"`
-	Data  interface{} `json:"data,omitempty"`
-	Error string      `json:"error,omitempty"`
+	Type    string      `json:"type"`
+	Data    interface{} `json:"data,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Resume  string      `json:"resume,omitempty"` // resume token, when the stream carries events
 }
 
 // Upgrader for HTTP -> WebSocket. Consider tightening CheckOrigin in production.
@@ -38,21 +37,71 @@ var upgrader = websocket.Upgrader{
 // @Summary WebSocket: live furnace state stream
 // @Description Establish a WebSocket connection that streams current furnace state periodically.
 // @Description Query params:
-// @Description - interval: Go duration string (e.g., 500ms, 2s). Range: 1ms..10s.
-// @Description - interval_ms: integer milliseconds. Range: 1..10000.
+// @Description - interval: Go duration string (e.g., 500ms, 2s). Range: min_interval..max_interval (250ms..10s by default).
+// @Description - interval_ms: integer milliseconds. Same range in ms.
+// @Description - token: JWT, as an alternative to the Authorization header. Roles may have a higher minimum interval.
+// @Description The stream requires the same permission as GET /api/v1/furnace/state (a valid token by default, or none if api.permissions makes that route public); otherwise the upgrade is refused with 401 or 403.
+// @Description - schema_version: render states in an older payload contract (same as the X-Schema-Version header on REST).
+// @Description - events: also stream the event log as "event" messages, filtered by type and exclude_type as on GET /api/v1/logs/tail. Requires the permission of that route as well.
+// @Description Requests below the caller's minimum are clamped and announced with a "notice" message, or, if the server is configured to reject them, answered with an "error" message and closed.
+// @Description With events, every state and event message carries a resume token for the client's position in the log. Reconnecting with ?resume=<token> (which implies events) first replays the events appended since, then continues live; if that point was purged, a "notice" says events may be missing and the replay continues by time.
+// @Description When the server shuts down, or fails its readiness check at a keepalive ping, it sends a "goaway" message before closing with 1001 (going away); its data holds the reason, retry_after_ms (a jittered reconnect delay) and, if configured, an alternate endpoint to reconnect to.
 // @Tags websockets
 // @Produce json
-// @Param interval query string false "Update interval as Go duration (e.g. 500ms, 2s). Max 10s."
-// @Param interval_ms query int false "Update interval in milliseconds. Range: 1-10000."
+// @Param interval query string false "Update interval as Go duration (e.g. 500ms, 2s). 250ms-10s by default."
+// @Param interval_ms query int false "Update interval in milliseconds. Range: 250-10000 by default."
+// @Param token query string false "JWT authorizing the stream and picking the per-role minimum interval"
+// @Param schema_version query int false "State payload version; current when omitted"
+// @Param events query bool false "Also stream the event log"
+// @Param type query string false "Comma-separated event types to stream"
+// @Param exclude_type query string false "Comma-separated event types to leave out"
+// @Param resume query string false "Resume token from the last message received; replays missed events"
 // @Success 101 {string} string "Switching Protocols (WebSocket upgrade)"
 // @Header 101 {string} Upgrade "websocket"
 // @Header 101 {string} Connection "Upgrade"
 // @Failure 400 {string} string "Bad request (invalid parameters or upgrade failure)"
+// @Failure 401 {object} Problem "Missing or invalid token"
+// @Failure 403 {object} Problem "Role may not read the furnace state or the event log"
 // @Failure 500 {string} string "Internal server error during upgrade"
 // @Router /ws [get]
 func (h *Handler) wsConnect(c *gin.Context) {
-	interval := h.parseInterval(c)
+	version, err := parseSchemaVersion(c.DefaultQuery("schema_version", c.GetHeader(schemaHeader)))
+	if err != nil {
+		problem(c, http.StatusBadRequest, codeInvalidSchemaVersion, err.Error())
+		return
+	}
+	resume := c.Query("resume")
+	routes := []string{wsStateRoute}
+	withEvents := resume != "" || c.Query("events") == "true"
+	if withEvents {
+		routes = append(routes, wsEventsRoute)
+	}
+	role, ok := h.streamRole(c, routes...)
+	if !ok {
+		return
+	}
+	var feed *eventFeed
+	if withEvents {
+		filter := service.LogFilter{
+			Types:        splitTypes(c.Query("type")),
+			ExcludeTypes: splitTypes(c.Query("exclude_type")),
+		}
+		if feed, err = h.startFeed(c.Request.Context(), filter, resume); errors.Is(err, errInvalidResume) {
+			problemFor(c, http.StatusBadRequest, err)
+			return
+		} else if err != nil {
+			h.logAndJSONError(c, http.StatusInternalServerError, "failed to load logs", "ws_events_failed", err)
+			return
+		}
+	}
+	cfg := h.WSConfig()
+	interval, note, allowed := cfg.streamInterval(h.parseInterval(c), role)
 
+	// the connection outlives the server's read and write timeouts; each
+	// read and write below sets its own deadline
+	rc := http.NewResponseController(c.Writer)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		if h.log != nil {
@@ -60,29 +109,78 @@ func (h *Handler) wsConnect(c *gin.Context) {
 		}
 		return
 	}
+	h.streams.add()
+	defer h.streams.done()
 	defer func() { _ = conn.Close() }()
 
+	select {
+	case <-h.away.ch:
+		h.sendGoAway(conn, cfg, h.away.reason)
+		return
+	default:
+	}
+
+	if !allowed {
+		if h.log != nil {
+			h.log.Infow("ws_interval_rejected", "role", role, "reason", note)
+		}
+		_ = conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
+		_ = conn.WriteJSON(wsEnvelope{Type: "error", Error: note})
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "interval below minimum"))
+		return
+	}
+	if note != "" {
+		_ = conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
+		if err := conn.WriteJSON(wsEnvelope{Type: "notice", Message: note}); err != nil {
+			return
+		}
+	}
+
 	// Configure read limits and pong handler to extend read deadline.
 	conn.SetReadLimit(maxMsgSize)
-	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	_ = conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
 	conn.SetPongHandler(func(string) error {
-		return conn.SetReadDeadline(time.Now().Add(pongWait))
+		return conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
 	})
 
 	// Reader goroutine to handle control frames and detect disconnects.
 	done := make(chan struct{})
 	go h.startReader(conn, done)
 
-	// Prepare periodic writers: state updates and pings.
+	// Prepare periodic writers: state updates, event polls and pings.
 	ticker := time.NewTicker(interval)
-	ping := time.NewTicker(pingPeriod)
+	ping := time.NewTicker(cfg.pingPeriod())
 	defer func() {
 		ticker.Stop()
 		ping.Stop()
 	}()
+	var poll <-chan time.Time
+	if feed != nil {
+		t := time.NewTicker(service.TailPollInterval)
+		defer t.Stop()
+		poll = t.C
+	}
 
-	// Send initial state immediately.
-	if err := h.sendState(c.Request.Context(), conn); err != nil {
+	// Follow the simulator's saves when a state bus is wired; subscribe
+	// before the initial read so no update falls in between. Without one,
+	// every tick reads the state from the database.
+	var updates <-chan models.FurnaceState
+	if h.services.StateBus != nil {
+		ch, unsubscribe := h.services.StateBus.Subscribe(1)
+		defer unsubscribe()
+		updates = ch
+	}
+
+	// Send initial state immediately, then replay what a resuming client
+	// missed.
+	latest, err := h.currentState(c.Request.Context())
+	if err == nil {
+		err = writeState(conn, latest, version, feed.token(), cfg.WriteWait)
+	}
+	if err == nil && feed != nil {
+		err = feed.send(c.Request.Context(), conn, cfg.WriteWait)
+	}
+	if err != nil {
 		// If initial send fails, log and close the connection.
 		if h.log != nil {
 			h.log.Infow("ws_write_failed_initial", "err", err)
@@ -97,16 +195,40 @@ func (h *Handler) wsConnect(c *gin.Context) {
 			return
 		case <-c.Request.Context().Done():
 			return
+		case <-h.away.ch:
+			h.sendGoAway(conn, cfg, h.away.reason)
+			return
 		case <-ping.C:
-			_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if h.degraded(c.Request.Context()) {
+				h.sendGoAway(conn, cfg, GoAwayDegraded)
+				return
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
 			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				if h.log != nil {
 					h.log.Infow("ws_ping_failed", "err", err)
 				}
 				return
 			}
+		case <-poll:
+			if err := feed.send(c.Request.Context(), conn, cfg.WriteWait); err != nil {
+				if h.log != nil {
+					h.log.Infow("ws_events_failed", "err", err)
+				}
+				return
+			}
+		case st, ok := <-updates:
+			if !ok {
+				return
+			}
+			latest = st
 		case <-ticker.C:
-			if err := h.sendState(c.Request.Context(), conn); err != nil {
+			if updates == nil {
+				if latest, err = h.currentState(c.Request.Context()); err != nil {
+					return
+				}
+			}
+			if err := writeState(conn, latest, version, feed.token(), cfg.WriteWait); err != nil {
 				// Log and keep the loop only for transient write errors; close on hard errors.
 				if h.log != nil {
 					h.log.Infow("ws_write_failed", "err", err)
@@ -120,21 +242,64 @@ func (h *Handler) wsConnect(c *gin.Context) {
 // ... existing code ...
 // Helper: parseInterval reads ?interval=2s or ?interval_ms=2000 with bounds.
 func (h *Handler) parseInterval(c *gin.Context) time.Duration {
-	interval := defaultInterval
+	cfg := h.WSConfig()
 
 	if s := c.Query("interval"); s != "" {
-		if d, err := time.ParseDuration(s); err == nil && d > 0 && d <= maxInterval {
+		if d, err := time.ParseDuration(s); err == nil && d > 0 && d <= cfg.MaxInterval {
 			return d
 		}
 	}
 
 	if ms := c.Query("interval_ms"); ms != "" {
-		if v, err := strconv.Atoi(ms); err == nil && v > 0 && v <= maxIntervalMilli {
+		if v, err := strconv.Atoi(ms); err == nil && v > 0 && int64(v) <= cfg.MaxInterval.Milliseconds() {
 			return time.Duration(v) * time.Millisecond
 		}
 	}
 
-	return interval
+	return cfg.DefaultInterval
+}
+
+// wsStateRoute is the REST route whose permission the state stream
+// follows, so a stream never shows a caller more than REST would.
+const wsStateRoute = http.MethodGet + " /furnace/state"
+
+// Helper: streamRole authorizes the stream like the REST guards of routes
+// and returns the caller's role for interval floors. Browsers cannot set
+// headers on a WebSocket, so ?token= is accepted as well. Only when every
+// route is public may a caller without a valid token stream, as anonymous.
+// On refusal it writes the REST error response and returns false.
+func (h *Handler) streamRole(c *gin.Context, routes ...string) (string, bool) {
+	public := true
+	for _, route := range routes {
+		public = public && h.perms[route] == PermPublic
+	}
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if token == "" {
+		token = c.Query("token")
+	}
+	var claims *service.Claims
+	err := errors.New("missing token")
+	if token != "" {
+		claims, err = h.services.ParseClaims(token)
+	}
+	switch {
+	case err != nil && public:
+		return wsAnonymousRole, true
+	case token == "":
+		problem(c, http.StatusUnauthorized, codeMissingToken, "missing token; send an Authorization header or ?token=")
+		return "", false
+	case err != nil:
+		problem(c, http.StatusUnauthorized, codeInvalidToken, "invalid or expired token")
+		return "", false
+	}
+	role := claims.EffectiveRole()
+	for _, route := range routes {
+		if !h.perms[route].allows(role) {
+			problem(c, http.StatusForbidden, codeInsufficientPermissions, "insufficient permissions")
+			return "", false
+		}
+	}
+	return role, true
 }
 
 // Helper: startReader drains incoming messages to handle control frames and detect closure.
@@ -150,15 +315,23 @@ func (h *Handler) startReader(conn *websocket.Conn, done chan<- struct{}) {
 	}
 }
 
-// Helper: sendState fetches and writes the current state with a write deadline.
-func (h *Handler) sendState(ctx context.Context, conn *websocket.Conn) error {
+// Helper: currentState reads the persisted state for the stream.
+func (h *Handler) currentState(ctx context.Context) (models.FurnaceState, error) {
 	st, err := h.services.Monitoring.GetState(ctx)
+	if err != nil && h.log != nil {
+		h.log.Errorw("ws_get_state_failed", "err", err)
+	}
+	return st, err
+}
+
+// Helper: writeState writes a state envelope, rendered as schema version
+// (0 for current) and carrying the resume token if any, with a write
+// deadline.
+func writeState(conn *websocket.Conn, st models.FurnaceState, version int, resume string, writeWait time.Duration) error {
+	data, err := versioned(st, version)
 	if err != nil {
-		if h.log != nil {
-			h.log.Errorw("ws_get_state_failed", "err", err)
-		}
 		return err
 	}
 	_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
-	return conn.WriteJSON(wsEnvelope{Type: "state", Data: st})
+	return conn.WriteJSON(wsEnvelope{Type: "state", Data: data, Resume: resume})
 }
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ErrInvalidWSConfig is returned for WebSocket timings that cannot work.
+var ErrInvalidWSConfig = errors.New("invalid websocket config")
+
+// wsAnonymousRole keys RoleMinInterval for stream clients without a valid token.
+const wsAnonymousRole = "anonymous"
+
+// WSConfig holds the WebSocket keepalive and stream interval limits.
+// Changes apply to connections opened afterwards.
+type WSConfig struct {
+	WriteWait       time.Duration `mapstructure:"write_wait"`       // deadline for a single write
+	PongWait        time.Duration `mapstructure:"pong_wait"`        // how long a silent peer is kept; pings go out at 90% of it
+	DefaultInterval time.Duration `mapstructure:"default_interval"` // state push interval when the client sets none
+	MaxInterval     time.Duration `mapstructure:"max_interval"`     // longest interval a client may request
+	MinInterval     time.Duration `mapstructure:"min_interval"`     // shortest interval any client gets
+
+	// RoleMinInterval raises the floor for particular roles, including
+	// "anonymous" for clients that connect without a token.
+	RoleMinInterval map[string]time.Duration `mapstructure:"role_min_interval"`
+	// RejectTooFast closes streams that ask for less than their floor
+	// instead of clamping them to it.
+	RejectTooFast bool `mapstructure:"reject_too_fast"`
+
+	// Reconnect guidance sent in "goaway" messages when the server shuts
+	// down or turns unready: clients are told to wait GoAwayBackoff plus a
+	// random share of GoAwayJitter, and to try Alternate first if set.
+	GoAwayBackoff time.Duration `mapstructure:"goaway_backoff"`
+	GoAwayJitter  time.Duration `mapstructure:"goaway_jitter"`
+	Alternate     string        `mapstructure:"alternate"` // ws(s):// URL of another instance
+}
+
+// DefaultWSConfig returns the limits used when none are configured.
+func DefaultWSConfig() WSConfig {
+	return WSConfig{
+		WriteWait:       10 * time.Second,
+		PongWait:        60 * time.Second,
+		DefaultInterval: time.Second,
+		MaxInterval:     10 * time.Second,
+		MinInterval:     250 * time.Millisecond,
+		GoAwayBackoff:   time.Second,
+		GoAwayJitter:    5 * time.Second,
+	}
+}
+
+// withDefaults fills unset fields from DefaultWSConfig.
+func (c WSConfig) withDefaults() WSConfig {
+	def := DefaultWSConfig()
+	if c.WriteWait == 0 {
+		c.WriteWait = def.WriteWait
+	}
+	if c.PongWait == 0 {
+		c.PongWait = def.PongWait
+	}
+	if c.DefaultInterval == 0 {
+		c.DefaultInterval = def.DefaultInterval
+	}
+	if c.MaxInterval == 0 {
+		c.MaxInterval = def.MaxInterval
+	}
+	if c.MinInterval == 0 {
+		c.MinInterval = def.MinInterval
+	}
+	if c.GoAwayBackoff == 0 {
+		c.GoAwayBackoff = def.GoAwayBackoff
+	}
+	if c.GoAwayJitter == 0 {
+		c.GoAwayJitter = def.GoAwayJitter
+	}
+	return c
+}
+
+// Validate checks the limits after defaults are applied.
+func (c WSConfig) Validate() error {
+	c = c.withDefaults()
+	switch {
+	case c.WriteWait < 0 || c.PongWait < 0 || c.DefaultInterval < 0 || c.MaxInterval < 0 || c.MinInterval < 0 ||
+		c.GoAwayBackoff < 0 || c.GoAwayJitter < 0:
+		return fmt.Errorf("%w: durations must be positive", ErrInvalidWSConfig)
+	case c.PongWait < time.Second:
+		return fmt.Errorf("%w: pong_wait must be at least 1s", ErrInvalidWSConfig)
+	case c.DefaultInterval > c.MaxInterval:
+		return fmt.Errorf("%w: default_interval must not exceed max_interval", ErrInvalidWSConfig)
+	case c.MinInterval > c.DefaultInterval:
+		return fmt.Errorf("%w: min_interval must not exceed default_interval", ErrInvalidWSConfig)
+	}
+	if c.Alternate != "" {
+		if u, err := url.Parse(c.Alternate); err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+			return fmt.Errorf("%w: alternate must be a ws:// or wss:// URL", ErrInvalidWSConfig)
+		}
+	}
+	for role, d := range c.RoleMinInterval {
+		if d < 0 || d > c.MaxInterval {
+			return fmt.Errorf("%w: role_min_interval %q must be within 0..max_interval", ErrInvalidWSConfig, role)
+		}
+	}
+	return nil
+}
+
+// pingPeriod leaves the peer a tenth of PongWait to answer.
+func (c WSConfig) pingPeriod() time.Duration {
+	return c.PongWait * 9 / 10
+}
+
+// floor is the shortest interval a client with role may stream at. A role
+// floor can only raise MinInterval, never lower it.
+func (c WSConfig) floor(role string) time.Duration {
+	f := c.MinInterval
+	if r := c.RoleMinInterval[role]; r > f {
+		f = r
+	}
+	return f
+}
+
+// streamInterval applies the floor for role to the requested interval. It
+// returns the interval to use, an explanation for the client when the request
+// was below the floor, and ok=false when such requests are refused.
+func (c WSConfig) streamInterval(requested time.Duration, role string) (d time.Duration, note string, ok bool) {
+	floor := c.floor(role)
+	if requested >= floor {
+		return requested, "", true
+	}
+	note = fmt.Sprintf("interval %s is below the %s minimum for role %s", requested, floor, role)
+	if c.RejectTooFast {
+		return 0, note, false
+	}
+	return floor, note + "; using " + floor.String(), true
+}
+
+// WSConfig returns the limits applied to new WebSocket connections.
+func (h *Handler) WSConfig() WSConfig {
+	h.wsMu.RLock()
+	defer h.wsMu.RUnlock()
+	return h.ws
+}
+
+// SetWSConfig replaces the WebSocket limits for new connections; open
+// connections keep the values they started with.
+func (h *Handler) SetWSConfig(c WSConfig) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	h.wsMu.Lock()
+	h.ws = c.withDefaults()
+	h.wsMu.Unlock()
+	return nil
+}
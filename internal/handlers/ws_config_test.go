@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWSConfig_Validate(t *testing.T) {
+	bad := []WSConfig{
+		{PongWait: 500 * time.Millisecond},
+		{DefaultInterval: 20 * time.Second},
+		{WriteWait: -time.Second},
+	}
+	for i, cfg := range bad {
+		if err := cfg.Validate(); !errors.Is(err, ErrInvalidWSConfig) {
+			t.Fatalf("case %d: expected ErrInvalidWSConfig, got %v", i, err)
+		}
+	}
+	if err := (WSConfig{PongWait: 5 * time.Minute}).Validate(); err != nil {
+		t.Fatalf("partial config should validate with defaults: %v", err)
+	}
+}
+
+func TestSetWSConfig_AppliesToNewRequests(t *testing.T) {
+	h := NewHandler(&service.Service{}, nil)
+	interval := func(query string) time.Duration {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/ws"+query, nil)
+		return h.parseInterval(c)
+	}
+
+	if got := interval("?interval=30s"); got != time.Second {
+		t.Fatalf("30s should exceed the default max, got %s", got)
+	}
+	if err := h.SetWSConfig(WSConfig{PongWait: 3 * time.Minute, MaxInterval: time.Minute, DefaultInterval: 5 * time.Second}); err != nil {
+		t.Fatalf("SetWSConfig: %v", err)
+	}
+	if got := interval("?interval=30s"); got != 30*time.Second {
+		t.Fatalf("expected raised max to allow 30s, got %s", got)
+	}
+	if got := interval("?interval_ms=45000"); got != 45*time.Second {
+		t.Fatalf("expected interval_ms to follow max_interval, got %s", got)
+	}
+	if got := interval(""); got != 5*time.Second {
+		t.Fatalf("expected new default interval, got %s", got)
+	}
+	if got := h.WSConfig(); got.WriteWait != DefaultWSConfig().WriteWait || got.pingPeriod() != 162*time.Second {
+		t.Fatalf("unexpected effective config: %+v", got)
+	}
+
+	if err := h.SetWSConfig(WSConfig{PongWait: time.Millisecond}); err == nil {
+		t.Fatal("expected invalid config to be rejected")
+	}
+	if h.WSConfig().MaxInterval != time.Minute {
+		t.Fatal("rejected config must not replace the current one")
+	}
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Reasons sent in a goaway envelope.
+const (
+	GoAwayShutdown = "shutdown" // the instance is stopping
+	GoAwayDegraded = "degraded" // the instance failed its readiness check
+)
+
+// readinessTTL is how long a readiness result is reused by the streams, so
+// many clients do not each probe the database.
+const readinessTTL = 5 * time.Second
+
+// wsGoAway tells a client why its stream is closing and when and where to
+// reconnect.
+type wsGoAway struct {
+	Reason       string `json:"reason" example:"shutdown"`
+	RetryAfterMs int64  `json:"retry_after_ms" example:"3250"`
+	Alternate    string `json:"alternate,omitempty" example:"wss://furnace-b.example.com/ws"`
+}
+
+// goAway is closed once to send every stream away.
+type goAway struct {
+	once   sync.Once
+	ch     chan struct{}
+	reason string
+}
+
+// streamCount counts the open WebSocket streams, so shutdown can wait for
+// them to be sent away.
+type streamCount struct {
+	mu   sync.Mutex
+	n    int
+	idle chan struct{} // closed when n drops to zero, if someone waits
+}
+
+func (s *streamCount) add() {
+	s.mu.Lock()
+	s.n++
+	s.mu.Unlock()
+}
+
+func (s *streamCount) done() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.n--; s.n == 0 && s.idle != nil {
+		close(s.idle)
+		s.idle = nil
+	}
+}
+
+// wait returns once no stream is open, or with ctx's error.
+func (s *streamCount) wait(ctx context.Context) error {
+	s.mu.Lock()
+	if s.n == 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	if s.idle == nil {
+		s.idle = make(chan struct{})
+	}
+	idle := s.idle
+	s.mu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// readiness caches the last readiness probe for the streams.
+type readiness struct {
+	mu      sync.Mutex
+	checked time.Time
+	ready   bool
+}
+
+// GoAway sends a goaway envelope with the configured reconnect guidance to
+// every open WebSocket stream and closes it; streams opened afterwards are
+// sent away at once. Only the first call's reason is used.
+func (h *Handler) GoAway(reason string) {
+	h.away.once.Do(func() {
+		h.away.reason = reason
+		close(h.away.ch)
+	})
+}
+
+// DrainStreams sends every WebSocket stream away for a shutdown and waits
+// until they have all closed, or returns ctx's error with some still open.
+// Long polls on the log answer at once with what they have.
+func (h *Handler) DrainStreams(ctx context.Context) error {
+	h.GoAway(GoAwayShutdown)
+	return h.streams.wait(ctx)
+}
+
+// untilAway derives a context from ctx that is also done once the streams
+// are sent away.
+func (h *Handler) untilAway(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-h.away.ch:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// degraded reports whether the readiness check fails, probing at most once
+// per readinessTTL. Without a probe service the instance counts as healthy.
+func (h *Handler) degraded(ctx context.Context) bool {
+	if h.services.Probes == nil {
+		return false
+	}
+	h.probe.mu.Lock()
+	defer h.probe.mu.Unlock()
+	if now := time.Now(); now.Sub(h.probe.checked) >= readinessTTL {
+		h.probe.ready = h.services.Probes.Ready(ctx).Ready
+		h.probe.checked = now
+	}
+	return !h.probe.ready
+}
+
+// sendGoAway writes the goaway envelope and a going-away close frame. The
+// suggested delay is spread over GoAwayJitter so a fleet of dashboards does
+// not reconnect at the same instant.
+func (h *Handler) sendGoAway(conn *websocket.Conn, cfg WSConfig, reason string) {
+	retry := cfg.GoAwayBackoff
+	if cfg.GoAwayJitter > 0 {
+		retry += rand.N(cfg.GoAwayJitter)
+	}
+	if h.log != nil {
+		h.log.Infow("ws_goaway", "reason", reason, "retry_after", retry)
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
+	msg := "server " + reason + "; reconnect later"
+	if cfg.Alternate != "" {
+		msg = "server " + reason + "; reconnect to the alternate endpoint"
+	}
+	if err := conn.WriteJSON(wsEnvelope{Type: "goaway", Message: msg, Data: wsGoAway{
+		Reason:       reason,
+		RetryAfterMs: retry.Milliseconds(),
+		Alternate:    cfg.Alternate,
+	}}); err != nil {
+		return
+	}
+	_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, reason))
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/service"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsEventsRoute is the REST route whose permission the event feed of the
+// stream follows.
+const wsEventsRoute = http.MethodGet + " /logs/tail"
+
+// errInvalidResume is returned for a resume token the server did not issue.
+var errInvalidResume = errors.New("invalid 'resume' token; reconnect without it")
+
+// wsResume is the decoded form of a stream's resume token: the last event
+// the client was sent and when it occurred, or, before any, the end of the
+// log and the time of connecting. The time is where a replay continues if
+// the event was purged. Clients treat the encoded string as opaque.
+type wsResume struct {
+	ID string    `json:"id,omitempty"`
+	At time.Time `json:"at"`
+}
+
+func (r wsResume) encode() string {
+	b, _ := json.Marshal(r)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeResume(s string) (wsResume, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return wsResume{}, errInvalidResume
+	}
+	var r wsResume
+	if err := json.Unmarshal(b, &r); err != nil || r.At.IsZero() {
+		return wsResume{}, errInvalidResume
+	}
+	return r, nil
+}
+
+// eventFeed follows the event log for one stream from a resume point.
+type eventFeed struct {
+	tail   service.EventTail
+	filter service.LogFilter
+	pos    wsResume
+}
+
+// startFeed returns a feed continuing after token, or at the end of the
+// log when token is empty.
+func (h *Handler) startFeed(ctx context.Context, f service.LogFilter, token string) (*eventFeed, error) {
+	feed := &eventFeed{tail: h.services.EventTail, filter: f}
+	if token != "" {
+		pos, err := decodeResume(token)
+		if err != nil {
+			return nil, err
+		}
+		feed.pos = pos
+		return feed, nil
+	}
+	tail, err := feed.tail.Tail(ctx, f, service.TailParams{})
+	if err != nil {
+		return nil, err
+	}
+	feed.pos = wsResume{ID: tail.NextAfterID, At: time.Now().UTC()}
+	return feed, nil
+}
+
+// send writes every event appended since the feed's position, each with
+// the token to resume after it, page by page. If the event to continue
+// after was purged, it continues by time instead and first sends a notice
+// that events may be missing.
+func (f *eventFeed) send(ctx context.Context, conn *websocket.Conn, writeWait time.Duration) error {
+	for {
+		p := service.TailParams{AfterID: f.pos.ID, Limit: service.MaxLogLimit}
+		if p.AfterID == "" {
+			p.AfterAt = f.pos.At
+		}
+		tail, err := f.tail.Tail(ctx, f.filter, p)
+		if errors.Is(err, service.ErrTailEventNotFound) {
+			f.pos.ID = ""
+			_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteJSON(wsEnvelope{Type: "notice", Message: "the resume point was purged from the log; events may be missing", Resume: f.pos.encode()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, ev := range tail.Events {
+			f.advance(ev)
+			_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteJSON(wsEnvelope{Type: "event", Data: ev, Resume: f.pos.encode()}); err != nil {
+				return err
+			}
+		}
+		if len(tail.Events) < service.MaxLogLimit {
+			return nil
+		}
+	}
+}
+
+// advance moves the feed's position past ev.
+func (f *eventFeed) advance(ev models.FurnaceEvent) {
+	f.pos = wsResume{ID: ev.EventID, At: ev.OccurredAt.UTC()}
+}
+
+// token returns the token resuming at the feed's position, or "" without
+// a feed.
+func (f *eventFeed) token() string {
+	if f == nil {
+		return ""
+	}
+	return f.pos.encode()
+}
@@ -0,0 +1,326 @@
+// Package loadgen drives a running instance with concurrent REST pollers and
+// WebSocket subscribers and reports latency percentiles and error rates, for
+// sizing hardware and the SQLite and WebSocket settings before deployment.
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrInvalidConfig is returned for load profiles that cannot be run.
+var ErrInvalidConfig = errors.New("invalid load config")
+
+// Defaults applied by Config.withDefaults.
+const (
+	DefaultPath     = "/api/v1/furnace/state"
+	DefaultDuration = 30 * time.Second
+	DefaultTimeout  = 5 * time.Second
+)
+
+// Config describes the load to generate.
+type Config struct {
+	Target string // base URL of the instance, e.g. http://localhost:8080
+	Token  string // JWT sent by pollers and subscribers; empty for anonymous
+
+	Pollers      int           // concurrent REST clients
+	Path         string        // endpoint the pollers GET; DefaultPath when empty
+	PollInterval time.Duration // pause between a poller's requests; 0 polls back to back
+
+	Subscribers int           // concurrent WebSocket clients on /ws
+	WSInterval  time.Duration // stream interval the subscribers request; server default when 0
+
+	Duration time.Duration // how long to run; DefaultDuration when 0
+	Timeout  time.Duration // per request and per handshake; DefaultTimeout when 0
+}
+
+func (c Config) withDefaults() Config {
+	if c.Path == "" {
+		c.Path = DefaultPath
+	}
+	if c.Duration == 0 {
+		c.Duration = DefaultDuration
+	}
+	if c.Timeout == 0 {
+		c.Timeout = DefaultTimeout
+	}
+	return c
+}
+
+// Validate checks that the profile generates some load against a valid URL.
+func (c Config) Validate() error {
+	u, err := url.Parse(c.Target)
+	switch {
+	case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+		return fmt.Errorf("%w: target must be an http(s) URL", ErrInvalidConfig)
+	case c.Pollers < 0 || c.Subscribers < 0:
+		return fmt.Errorf("%w: client counts must not be negative", ErrInvalidConfig)
+	case c.Pollers == 0 && c.Subscribers == 0:
+		return fmt.Errorf("%w: no pollers or subscribers", ErrInvalidConfig)
+	case c.Duration < 0 || c.Timeout < 0 || c.PollInterval < 0 || c.WSInterval < 0:
+		return fmt.Errorf("%w: durations must not be negative", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// Percentiles summarises a latency distribution in milliseconds.
+type Percentiles struct {
+	P50 float64 `json:"p50_ms"`
+	P90 float64 `json:"p90_ms"`
+	P99 float64 `json:"p99_ms"`
+	Max float64 `json:"max_ms"`
+}
+
+// PollReport covers the REST pollers.
+type PollReport struct {
+	Requests  int         `json:"requests"`
+	Errors    int         `json:"errors"` // transport failures and non-2xx responses
+	ErrorRate float64     `json:"error_rate"`
+	PerSecond float64     `json:"per_second"`
+	Latency   Percentiles `json:"latency"`
+	// Status counts responses by HTTP status code; 0 stands for requests
+	// that got no response.
+	Status map[int]int `json:"status"`
+}
+
+// WSReport covers the WebSocket subscribers.
+type WSReport struct {
+	Connects  int         `json:"connects"`
+	Errors    int         `json:"errors"` // failed handshakes and connections dropped before the end
+	ErrorRate float64     `json:"error_rate"`
+	Handshake Percentiles `json:"handshake"`
+	Messages  int         `json:"messages"`
+	// Gap is the time between consecutive messages on a connection; a p99
+	// far above the requested interval means the hub is falling behind.
+	Gap Percentiles `json:"gap"`
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	Target      string      `json:"target"`
+	Seconds     float64     `json:"duration_s"` // actual run time
+	Pollers     int         `json:"pollers"`
+	Subscribers int         `json:"subscribers"`
+	Poll        *PollReport `json:"poll,omitempty"`
+	WS          *WSReport   `json:"ws,omitempty"`
+}
+
+// recorder collects samples from concurrent clients.
+type recorder struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	gaps      []time.Duration
+	errors    int
+	messages  int
+	status    map[int]int
+}
+
+func (r *recorder) add(fn func(r *recorder)) {
+	r.mu.Lock()
+	fn(r)
+	r.mu.Unlock()
+}
+
+// Run generates load until cfg.Duration has passed or ctx is canceled.
+func Run(ctx context.Context, cfg Config) (Report, error) {
+	if err := cfg.Validate(); err != nil {
+		return Report{}, err
+	}
+	cfg = cfg.withDefaults()
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	polls := &recorder{status: make(map[int]int)}
+	subs := &recorder{}
+	client := &http.Client{Timeout: cfg.Timeout}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.Pollers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			poll(ctx, client, cfg, polls)
+		}()
+	}
+	for i := 0; i < cfg.Subscribers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			subscribe(ctx, cfg, subs)
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	rep := Report{Target: cfg.Target, Seconds: elapsed.Seconds(), Pollers: cfg.Pollers, Subscribers: cfg.Subscribers}
+	if cfg.Pollers > 0 {
+		n := len(polls.latencies)
+		rep.Poll = &PollReport{
+			Requests:  n,
+			Errors:    polls.errors,
+			ErrorRate: ratio(polls.errors, n),
+			PerSecond: float64(n) / elapsed.Seconds(),
+			Latency:   percentiles(polls.latencies),
+			Status:    polls.status,
+		}
+	}
+	if cfg.Subscribers > 0 {
+		n := len(subs.latencies)
+		rep.WS = &WSReport{
+			Connects:  n,
+			Errors:    subs.errors,
+			ErrorRate: ratio(subs.errors, n),
+			Handshake: percentiles(subs.latencies),
+			Messages:  subs.messages,
+			Gap:       percentiles(subs.gaps),
+		}
+	}
+	return rep, nil
+}
+
+// poll issues GET requests until ctx is done. Requests cut short by the end
+// of the run are not counted.
+func poll(ctx context.Context, client *http.Client, cfg Config, rec *recorder) {
+	target := strings.TrimRight(cfg.Target, "/") + cfg.Path
+	for ctx.Err() == nil {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			rec.add(func(r *recorder) { r.errors++ })
+			return
+		}
+		if cfg.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+cfg.Token)
+		}
+		began := time.Now()
+		resp, err := client.Do(req)
+		took := time.Since(began)
+		if ctx.Err() != nil {
+			if resp != nil {
+				_ = resp.Body.Close()
+			}
+			return
+		}
+		code := 0
+		if err == nil {
+			code = resp.StatusCode
+			_ = resp.Body.Close()
+		}
+		rec.add(func(r *recorder) {
+			r.latencies = append(r.latencies, took)
+			r.status[code]++
+			if code < 200 || code > 299 {
+				r.errors++
+			}
+		})
+		if cfg.PollInterval > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(cfg.PollInterval):
+			}
+		}
+	}
+}
+
+// subscribe holds one WebSocket connection open for the run, reconnecting
+// after failures, and records the gaps between state messages.
+func subscribe(ctx context.Context, cfg Config, rec *recorder) {
+	u, _ := url.Parse(strings.TrimRight(cfg.Target, "/") + "/ws")
+	u.Scheme = strings.Replace(u.Scheme, "http", "ws", 1)
+	q := u.Query()
+	if cfg.WSInterval > 0 {
+		q.Set("interval", cfg.WSInterval.String())
+	}
+	if cfg.Token != "" {
+		q.Set("token", cfg.Token)
+	}
+	u.RawQuery = q.Encode()
+	dialer := websocket.Dialer{HandshakeTimeout: cfg.Timeout}
+
+	for ctx.Err() == nil {
+		began := time.Now()
+		conn, _, err := dialer.DialContext(ctx, u.String(), nil)
+		took := time.Since(began)
+		if ctx.Err() != nil {
+			if conn != nil {
+				_ = conn.Close()
+			}
+			return
+		}
+		rec.add(func(r *recorder) {
+			r.latencies = append(r.latencies, took)
+			if err != nil {
+				r.errors++
+			}
+		})
+		if err != nil {
+			// back off briefly so a refusing server is not hammered
+			select {
+			case <-ctx.Done():
+			case <-time.After(cfg.Timeout / 10):
+			}
+			continue
+		}
+		if !read(ctx, conn, rec) {
+			rec.add(func(r *recorder) { r.errors++ })
+		}
+	}
+}
+
+// read consumes messages until ctx is done (true) or the connection fails
+// (false).
+func read(ctx context.Context, conn *websocket.Conn, rec *recorder) bool {
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	defer func() { _ = conn.Close() }()
+
+	var last time.Time
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return ctx.Err() != nil
+		}
+		now := time.Now()
+		rec.add(func(r *recorder) {
+			r.messages++
+			if !last.IsZero() {
+				r.gaps = append(r.gaps, now.Sub(last))
+			}
+		})
+		last = now
+	}
+}
+
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+// percentiles uses the nearest-rank method.
+func percentiles(ds []time.Duration) Percentiles {
+	if len(ds) == 0 {
+		return Percentiles{}
+	}
+	sorted := append([]time.Duration(nil), ds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	at := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(sorted)))) - 1
+		return ms(sorted[max(i, 0)])
+	}
+	return Percentiles{P50: at(0.50), P90: at(0.90), P99: at(0.99), Max: ms(sorted[len(sorted)-1])}
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
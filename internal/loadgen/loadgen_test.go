@@ -0,0 +1,101 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeInstance serves the state endpoint and streams a message every 10ms.
+func fakeInstance(t *testing.T, stateStatus int) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc(DefaultPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tkn" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(stateStatus)
+	})
+	up := websocket.Upgrader{}
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") != "tkn" || r.URL.Query().Get("interval") != "10ms" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		conn, err := up.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"state"}`)); err != nil {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRun_ReportsPollsAndStreams(t *testing.T) {
+	srv := fakeInstance(t, http.StatusOK)
+	rep, err := Run(context.Background(), Config{
+		Target: srv.URL, Token: "tkn",
+		Pollers: 3, PollInterval: 5 * time.Millisecond,
+		Subscribers: 2, WSInterval: 10 * time.Millisecond,
+		Duration: 300 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if rep.Poll == nil || rep.Poll.Requests == 0 || rep.Poll.Errors != 0 || rep.Poll.Status[http.StatusOK] != rep.Poll.Requests {
+		t.Fatalf("unexpected poll report %+v", rep.Poll)
+	}
+	if rep.Poll.Latency.P50 > rep.Poll.Latency.P99 || rep.Poll.Latency.P99 > rep.Poll.Latency.Max {
+		t.Fatalf("percentiles out of order: %+v", rep.Poll.Latency)
+	}
+	if rep.WS == nil || rep.WS.Connects != 2 || rep.WS.Errors != 0 || rep.WS.Messages < 10 || rep.WS.Gap.P50 == 0 {
+		t.Fatalf("unexpected ws report %+v", rep.WS)
+	}
+}
+
+func TestRun_CountsErrorResponses(t *testing.T) {
+	srv := fakeInstance(t, http.StatusServiceUnavailable)
+	rep, err := Run(context.Background(), Config{Target: srv.URL, Token: "tkn", Pollers: 1, Duration: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if rep.WS != nil || rep.Poll.Requests == 0 || rep.Poll.ErrorRate != 1 || rep.Poll.Status[http.StatusServiceUnavailable] != rep.Poll.Requests {
+		t.Fatalf("expected every request to fail, got %+v", rep.Poll)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	for _, cfg := range []Config{
+		{Target: "localhost:8080", Pollers: 1},
+		{Target: "http://localhost:8080"},
+		{Target: "http://localhost:8080", Pollers: -1, Subscribers: 2},
+	} {
+		if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("%+v: expected ErrInvalidConfig, got %v", cfg, err)
+		}
+	}
+}
+
+func TestPercentiles_NearestRank(t *testing.T) {
+	var ds []time.Duration
+	for i := 1; i <= 100; i++ {
+		ds = append(ds, time.Duration(i)*time.Millisecond)
+	}
+	if got := percentiles(ds); got != (Percentiles{P50: 50, P90: 90, P99: 99, Max: 100}) {
+		t.Fatalf("got %+v", got)
+	}
+}
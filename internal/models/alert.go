@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Alert rule kinds.
+const (
+	AlertTempAbove      = "temp_above"      // measured temperature above Threshold °C
+	AlertRemainingBelow = "remaining_below" // running with less than Threshold seconds left
+	AlertErrorEvent     = "error_event"     // any new error code (each one also logs an ERROR event)
+)
+
+// AlertRule is a user-defined condition on the furnace state.
+type AlertRule struct {
+	ID        int     `json:"id"`
+	Name      string  `json:"name" example:"Too hot"`
+	Kind      string  `json:"kind" example:"temp_above"`
+	Threshold float64 `json:"threshold" example:"950"` // °C or seconds, by kind; unused for error_event
+	// ForSeconds is how long the condition must hold before the alert fires.
+	ForSeconds int       `json:"for_seconds" example:"30"`
+	Enabled    bool      `json:"enabled"`
+	CreatedBy  int       `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Alert is one firing of a rule.
+type Alert struct {
+	ID       int64     `json:"id"`
+	RuleID   int       `json:"rule_id"`
+	RuleName string    `json:"rule_name"`
+	Kind     string    `json:"kind"`
+	FiredAt  time.Time `json:"fired_at"`
+	Value    float64   `json:"value"` // temperature or remaining seconds that fired the rule
+	Message  string    `json:"message"`
+	RunID    string    `json:"run_id,omitempty"`
+	// Notified is set once the outbound notification was delivered, or held
+	// for a channel's digest.
+	Notified    bool   `json:"notified"`
+	NotifyError string `json:"notify_error,omitempty"`
+}
+
+// AlertDigest summarizes the non-critical alerts a notification channel held
+// during its quiet hours or digest interval.
+type AlertDigest struct {
+	Channel string    `json:"channel" example:"night-shift"`
+	From    time.Time `json:"from"` // when the first alert was held
+	To      time.Time `json:"to"`
+	Count   int       `json:"count"`             // alerts held, including dropped ones
+	Dropped int       `json:"dropped,omitempty"` // held beyond the limit and left out of alerts
+	Alerts  []Alert   `json:"alerts"`
+}
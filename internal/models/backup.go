@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// BackupReport describes a database snapshot taken by POST /admin/backup.
+type BackupReport struct {
+	File string `json:"file" example:"furnace-20250920T100000Z.db"`
+	// Path is where the snapshot was stored; omitted for a download.
+	Path      string    `json:"path,omitempty" example:"backups/furnace-20250920T100000Z.db"`
+	Bytes     int64     `json:"bytes" example:"1048576"`
+	SHA256    string    `json:"sha256" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Kinds of problem found when verifying the event hash chain.
+const (
+	ChainTampered = "tampered" // the row no longer matches its hash
+	ChainGap      = "gap"      // prev_hash does not match the row before: rows were removed or reordered
+	ChainUnhashed = "unhashed" // the row was written after the chain started but carries no hash
+)
+
+// ChainProblem is one row that failed verification.
+type ChainProblem struct {
+	EventID    string    `json:"event_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Kind       string    `json:"kind" example:"tampered"`
+}
+
+// ChainReport is the outcome of verifying the event hash chain.
+type ChainReport struct {
+	Enabled   bool `json:"enabled"`   // new events are being chained
+	Valid     bool `json:"valid"`     // no problems were found
+	Checked   int  `json:"checked"`   // rows verified against the chain
+	Unchained int  `json:"unchained"` // rows written before the chain started
+	// Head is the hash of the newest row. Removing rows from the end of the
+	// log leaves a valid shorter chain, so keep a copy elsewhere to detect it.
+	Head     string         `json:"head,omitempty"`
+	Problems []ChainProblem `json:"problems"` // the first MaxChainProblems found
+	Total    int            `json:"total_problems"`
+}
+
+// MaxChainProblems caps ChainReport.Problems.
+const MaxChainProblems = 100
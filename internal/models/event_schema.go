@@ -0,0 +1,233 @@
+package models
+
+import "time"
+
+// Default severities of event types.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// EventCatalog describes every event type the service logs and the
+// metadata each carries, for clients that decode or validate the log.
+type EventCatalog struct {
+	SchemaVersion int `json:"schema_version" example:"9"` // see SchemaVersion
+	// Common are metadata fields any event may carry in addition to its
+	// own: the run it belongs to and the request and user that caused it.
+	Common []EventField  `json:"common"`
+	Types  []EventSchema `json:"types"`
+}
+
+// EventSchema describes one event type.
+type EventSchema struct {
+	Type        string       `json:"type" example:"MODE_CHANGE"`
+	Severity    string       `json:"severity" example:"info"` // info | warning | critical
+	Description string       `json:"description" example:"The mode was changed by a command or when a soak ended."`
+	Fields      []EventField `json:"fields"`
+}
+
+// EventField describes a metadata field.
+type EventField struct {
+	Name string `json:"name" example:"target_temp_c"`
+	// JSON type: string, number, integer, boolean, array or object
+	Type        string `json:"type" example:"number"`
+	Format      string `json:"format,omitempty" example:"date-time"` // for strings with a fixed format
+	Items       string `json:"items,omitempty" example:"string"`     // element type of arrays
+	Required    bool   `json:"required"`
+	Description string `json:"description" example:"°C, requested HEAT target"`
+}
+
+// The structs below are the metadata of each event type. Fields tagged
+// omitempty are only present in some events of the type; doc describes the
+// field in the catalog.
+
+// CommonEventMeta holds the fields shared by all event types.
+type CommonEventMeta struct {
+	RunID     string `json:"run_id,omitempty" doc:"heat cycle the event belongs to"`
+	RequestID string `json:"request_id,omitempty" doc:"X-Request-ID of the API call that caused the event"`
+	UserID    int    `json:"user_id,omitempty" doc:"user whose API call caused the event"`
+}
+
+// ModeChangeMeta is the metadata of MODE_CHANGE.
+type ModeChangeMeta struct {
+	From           string  `json:"from,omitempty" doc:"previous mode, when the simulator changed it"`
+	To             string  `json:"to,omitempty" doc:"new mode, when the simulator changed it"`
+	TargetTempC    float64 `json:"target_temp_c,omitempty" doc:"°C, HEAT target set by the command"`
+	DurationSec    int     `json:"duration_sec,omitempty" doc:"seconds of soak set by the command"`
+	IsRunning      bool    `json:"is_running,omitempty" doc:"whether the furnace was running"`
+	Gas            string  `json:"gas,omitempty" doc:"protective gas set with the mode: N2 or AR"`
+	GasSetpointM3h float64 `json:"gas_setpoint_m3h,omitempty" doc:"m³/h, purge flow set with the mode"`
+	MaxO2PPM       float64 `json:"max_o2_ppm,omitempty" doc:"ppm, O2_HIGH limit set with the mode"`
+}
+
+// AtmosphereChangeMeta is the metadata of ATMOSPHERE_CHANGE.
+type AtmosphereChangeMeta struct {
+	Gas            string  `json:"gas" doc:"protective gas, N2 or AR; empty when the purge is off"`
+	GasSetpointM3h float64 `json:"gas_setpoint_m3h" doc:"m³/h, requested purge flow"`
+	MaxO2PPM       float64 `json:"max_o2_ppm" doc:"ppm, O2_HIGH limit; 0 uses the configured default"`
+}
+
+// ErrorMeta is the metadata of ERROR. Which fields are present depends on
+// the alarm or fault raised.
+type ErrorMeta struct {
+	Alarm         string  `json:"alarm,omitempty" doc:"alarm raised: RATE_OF_RISE or O2_HIGH; absent for an overheat"`
+	Fault         string  `json:"fault,omitempty" doc:"injected fault that became active"`
+	TempC         float64 `json:"temp_c,omitempty" doc:"°C, chamber temperature"`
+	MaxSafe       float64 `json:"max_safe,omitempty" doc:"°C, overheat threshold"`
+	Mode          string  `json:"mode,omitempty" doc:"mode at the time"`
+	IsRunning     bool    `json:"isRunning,omitempty" doc:"whether the furnace was running"`
+	RateCPerMin   float64 `json:"rate_c_per_min,omitempty" doc:"°C per minute, measured rate of rise"`
+	LimitCPerMin  float64 `json:"limit_c_per_min,omitempty" doc:"°C per minute, allowed rate of rise"`
+	MeasuredTempC float64 `json:"measured_temp_c,omitempty" doc:"°C, sensor reading"`
+	O2PPM         float64 `json:"o2_ppm,omitempty" doc:"ppm, residual oxygen"`
+	MaxO2PPM      float64 `json:"max_o2_ppm,omitempty" doc:"ppm, O2_HIGH limit"`
+	Gas           string  `json:"gas,omitempty" doc:"protective gas requested"`
+	GasFlowM3h    float64 `json:"gas_flow_m3h,omitempty" doc:"m³/h, measured purge flow"`
+}
+
+// AlarmClearedMeta is the metadata of ALARM_CLEARED.
+type AlarmClearedMeta struct {
+	Alarm       string  `json:"alarm" doc:"alarm cleared: OVERHEAT, RATE_OF_RISE or O2_HIGH"`
+	TempC       float64 `json:"temp_c,omitempty" doc:"°C, chamber temperature (OVERHEAT)"`
+	MaxSafe     float64 `json:"max_safe,omitempty" doc:"°C, overheat threshold (OVERHEAT)"`
+	RateCPerMin float64 `json:"rate_c_per_min,omitempty" doc:"°C per minute, measured rate of rise (RATE_OF_RISE)"`
+	O2PPM       float64 `json:"o2_ppm,omitempty" doc:"ppm, residual oxygen (O2_HIGH)"`
+}
+
+// FaultClearedMeta is the metadata of FAULT_CLEARED.
+type FaultClearedMeta struct {
+	Fault string `json:"fault" doc:"injected fault that was cleared"`
+}
+
+// SafetyTripMeta is the metadata of SAFETY_TRIP.
+type SafetyTripMeta struct {
+	Reason string `json:"reason" doc:"alarm that tripped the shutdown"`
+}
+
+// BootMeta is the metadata of BOOT.
+type BootMeta struct {
+	DowntimeS        float64   `json:"downtime_s" doc:"seconds since the state was last saved"`
+	LastUpdate       time.Time `json:"last_update" doc:"when the state was last saved"`
+	Mode             string    `json:"mode" doc:"mode found at start-up"`
+	IsRunning        bool      `json:"isRunning" doc:"whether the furnace was running"`
+	RemainingSeconds int       `json:"remaining_seconds" doc:"soak seconds left"`
+}
+
+// ShutdownMeta is the metadata of SHUTDOWN.
+type ShutdownMeta struct {
+	TempC            float64 `json:"temp_c" doc:"°C, chamber temperature"`
+	Mode             string  `json:"mode" doc:"mode at shutdown"`
+	IsRunning        bool    `json:"isRunning" doc:"whether the furnace was running"`
+	RemainingSeconds int     `json:"remaining_seconds" doc:"soak seconds left"`
+}
+
+// SoakUnstableMeta is the metadata of SOAK_UNSTABLE.
+type SoakUnstableMeta struct {
+	Stability    float64 `json:"stability" doc:"share of soak time within the tolerance band"`
+	MinStability float64 `json:"min_stability" doc:"required share"`
+	StdDevC      float64 `json:"stddev_c" doc:"°C, standard deviation during the soak"`
+	SoakS        float64 `json:"soak_s" doc:"seconds of soak judged"`
+	TargetTempC  float64 `json:"target_temp_c" doc:"°C, soak target"`
+}
+
+// MaintenanceDueMeta is the metadata of MAINTENANCE_DUE.
+type MaintenanceDueMeta struct {
+	HeatingHours      float64 `json:"heating_hours" doc:"heating hours of the elements"`
+	Cycles            int     `json:"cycles" doc:"heat cycles of the elements"`
+	RampDegradation   float64 `json:"ramp_degradation" doc:"share of the ramp rate lost to wear"`
+	MaintenanceHours  float64 `json:"maintenance_hours" doc:"heating hours at which maintenance is due"`
+	MaintenanceCycles int     `json:"maintenance_cycles" doc:"cycles at which maintenance is due"`
+}
+
+// MaintenanceDoneMeta is the metadata of MAINTENANCE_DONE.
+type MaintenanceDoneMeta struct {
+	TaskID       int     `json:"task_id" doc:"maintenance task"`
+	Kind         string  `json:"kind" doc:"task kind"`
+	Part         string  `json:"part,omitempty" doc:"part the task concerns"`
+	UserID       int     `json:"user_id" doc:"user who did the task"`
+	HeatingHours float64 `json:"heating_hours" doc:"heating hours at completion"`
+	WasOverdue   bool    `json:"was_overdue" doc:"whether the task was overdue"`
+}
+
+// MaintenanceOverdueMeta is the metadata of MAINTENANCE_OVERDUE.
+type MaintenanceOverdueMeta struct {
+	TaskID   int       `json:"task_id" doc:"maintenance task"`
+	Kind     string    `json:"kind" doc:"task kind"`
+	Part     string    `json:"part,omitempty" doc:"part the task concerns"`
+	DueAt    time.Time `json:"due_at,omitempty" doc:"when the task fell due by days"`
+	DueHours float64   `json:"due_hours,omitempty" doc:"heating hours at which the task fell due"`
+}
+
+// ChargeMeta is the metadata of CHARGE_INSERTED and CHARGE_REMOVED.
+type ChargeMeta struct {
+	MassKg       float64 `json:"mass_kg" doc:"kg, mass of the load"`
+	SpecificHeat float64 `json:"specific_heat" doc:"J/(kg·K), specific heat of the load"`
+	ChargeTempC  float64 `json:"charge_temp_c" doc:"°C, core temperature of the load"`
+	ChamberTempC float64 `json:"chamber_temp_c" doc:"°C, chamber temperature"`
+}
+
+// AlertMeta is the metadata of ALERT.
+type AlertMeta struct {
+	RuleID    int     `json:"rule_id" doc:"alert rule that fired"`
+	Kind      string  `json:"kind" doc:"rule kind"`
+	Value     float64 `json:"value" doc:"value that fired the rule"`
+	Threshold float64 `json:"threshold" doc:"rule threshold"`
+	Notified  bool    `json:"notified" doc:"whether alerts.notify_url was notified"`
+}
+
+// EscalationMeta is the metadata of ESCALATION.
+type EscalationMeta struct {
+	IncidentID  int64    `json:"incident_id" doc:"unacknowledged incident"`
+	Level       int      `json:"level" doc:"escalation level, 1 for the first tier"`
+	Tier        string   `json:"tier" doc:"tier paged"`
+	AlarmCodes  []string `json:"alarm_codes" doc:"alarms of the incident"`
+	Users       []string `json:"users,omitempty" doc:"who the tier pages"`
+	Notified    bool     `json:"notified" doc:"whether the tier's gateway accepted the page"`
+	NotifyError string   `json:"notify_error,omitempty" doc:"why the page failed"`
+}
+
+// LogPurgedMeta is the metadata of LOG_PURGED.
+type LogPurgedMeta struct {
+	Deleted int64  `json:"deleted" doc:"events removed"`
+	Archive string `json:"archive,omitempty" doc:"file the removed events were archived to"`
+}
+
+// BackupMeta is the metadata of BACKUP.
+type BackupMeta struct {
+	File     string `json:"file" doc:"snapshot file name"`
+	Bytes    int64  `json:"bytes" doc:"snapshot size"`
+	SHA256   string `json:"sha256" doc:"hex SHA-256 of the snapshot"`
+	Download bool   `json:"download,omitempty" doc:"set when the snapshot was downloaded instead of stored"`
+}
+
+// InternalErrorMeta is the metadata of INTERNAL_ERROR.
+type InternalErrorMeta struct {
+	Panic  string `json:"panic" doc:"value the handler panicked with"`
+	Method string `json:"method" doc:"HTTP method of the request"`
+	Route  string `json:"route" doc:"route of the request, or its path when none matched"`
+}
+
+// SequenceMeta is the metadata of SEQUENCE_STARTED, SEQUENCE_RESUMED,
+// SEQUENCE_COMPLETED and SEQUENCE_ABORTED.
+type SequenceMeta struct {
+	SequenceID string  `json:"sequence_id" doc:"sequence ID"`
+	Step       int     `json:"step" doc:"1-based step reached"`
+	Steps      int     `json:"steps" doc:"number of steps"`
+	Reason     string  `json:"reason,omitempty" doc:"why the sequence was aborted"`
+	DowntimeS  float64 `json:"downtime_s,omitempty" doc:"seconds the process was down before SEQUENCE_RESUMED"`
+}
+
+// KeepWarmEngagedMeta is the metadata of KEEP_WARM_ENGAGED.
+type KeepWarmEngagedMeta struct {
+	SetpointC float64 `json:"setpoint_c" doc:"°C held in STANDBY"`
+	TempC     float64 `json:"temp_c" doc:"°C, chamber temperature"`
+}
+
+// KeepWarmDisengagedMeta is the metadata of KEEP_WARM_DISENGAGED.
+type KeepWarmDisengagedMeta struct {
+	SetpointC float64 `json:"setpoint_c" doc:"°C that was held"`
+	TempC     float64 `json:"temp_c" doc:"°C, chamber temperature"`
+	Mode      string  `json:"mode" doc:"mode that ended keep-warm"`
+	IsRunning bool    `json:"isRunning" doc:"whether the furnace was running"`
+}
@@ -4,9 +4,27 @@ import "time"
 
 // FurnaceEvent is a single log entry.
 type FurnaceEvent struct {
-	EventID     string    `json:"event_id"`
-	OccurredAt  time.Time `json:"occurred_at"`
-	Type        string    `json:"type"`        // START | STOP | MODE_CHANGE | ERROR | TELEMETRY
-	Description string    `json:"description"` // human-readable
-	Metadata    any       `json:"metadata,omitempty"`
+	SchemaVersion int       `json:"schema_version" example:"9"` // see SchemaVersion; set when encoding
+	EventID       string    `json:"event_id"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	Type          string    `json:"type"`        // START | STOP | MODE_CHANGE | ERROR | ...; see EventCatalog
+	Description   string    `json:"description"` // human-readable
+	Metadata      any       `json:"metadata,omitempty"`
+	// Comments are the operators' notes on the event, oldest first.
+	Comments []EventComment `json:"comments,omitempty"`
+	// DeletedAt is set on an event an admin soft-deleted, e.g. for holding
+	// mistaken data; such events are only listed on request.
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy    int        `json:"deleted_by,omitempty"`    // user ID of the admin
+	DeleteReason string     `json:"delete_reason,omitempty"` // why it was deleted
+}
+
+// EventComment is an operator's note on a logged event, such as the cause
+// of an alarm left for the next shift.
+type EventComment struct {
+	ID        int64     `json:"id"`
+	EventID   string    `json:"event_id"`
+	UserID    int       `json:"user_id"`
+	Text      string    `json:"text" example:"Overheat was caused by the door left open"`
+	CreatedAt time.Time `json:"created_at"`
 }
@@ -3,12 +3,43 @@ package models
 import "time"
 
 type FurnaceState struct {
+	SchemaVersion    int       `json:"schema_version" example:"9"` // see SchemaVersion; set when encoding
 	ID               int       `json:"id"`
 	Mode             string    `json:"mode"`                        // HEAT | COOL | STANDBY
-	CurrentTempC     float64   `json:"current_temp_c"`              // °C
+	CurrentTempC     float64   `json:"current_temp_c"`              // °C, true (simulated) temperature
+	MeasuredTempC    float64   `json:"measured_temp_c"`             // °C, sensor reading incl. noise/drift
+	AmbientTempC     float64   `json:"ambient_temp_c"`              // °C, cold-junction sensor
 	TargetTempC      float64   `json:"target_temp_c,omitempty"`     // °C
 	RemainingSeconds int       `json:"remaining_seconds,omitempty"` // seconds
 	ErrorCodes       []string  `json:"error_codes,omitempty"`       // e.g. ["OVERHEAT", "SENSOR_FAULT"]
 	IsRunning        bool      `json:"is_running"`
 	UpdatedAt        time.Time `json:"updated_at"`
+	RunID            string    `json:"run_id,omitempty"` // active heat cycle, if any
+	PowerKW          float64   `json:"power_kw"`         // kW, instantaneous draw
+	EnergyKWh        float64   `json:"energy_kwh"`       // kWh consumed by the current or last run
+
+	// Protective atmosphere. Gas is empty while the chamber is not purged.
+	Gas            string  `json:"gas,omitempty"`              // N2 | AR
+	GasSetpointM3h float64 `json:"gas_setpoint_m3h,omitempty"` // m³/h, requested purge flow
+	GasFlowM3h     float64 `json:"gas_flow_m3h"`               // m³/h, measured flow
+	O2PPM          float64 `json:"o2_ppm"`                     // ppm, residual oxygen in the chamber
+	MaxO2PPM       float64 `json:"max_o2_ppm,omitempty"`       // ppm, O2_HIGH limit while hot
+
+	// °C the heater holds while keep-warm is engaged in STANDBY; omitted
+	// while it is not.
+	KeepWarmC float64 `json:"keep_warm_c,omitempty"`
+
+	// Derived from recent history when the state is read; not stored and
+	// omitted when unknown.
+	RateCPerSec *float64 `json:"rate_c_per_s,omitempty"` // °C per second over the last minute, negative when cooling
+	ETASeconds  *int     `json:"eta_seconds,omitempty"`  // seconds until the target is reached while heating
+	SoakPercent *float64 `json:"soak_percent,omitempty"` // share of the requested soak completed, 0..100
+	// UTC time the soak completes while it counts down at target; clients
+	// can count down to it between polls.
+	SoakEndsAt *time.Time `json:"soak_ends_at,omitempty"`
+
+	// Sequence running, if any. Not saved with the state: the sequence
+	// coordinator shows it again when it resumes the sequence after a
+	// restart.
+	Sequence *SequenceProgress `json:"sequence,omitempty"`
 }
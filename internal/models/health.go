@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// FurnaceHealth tracks heater wear for maintenance planning. Heating time and
+// cycle counts are stored; the remaining fields are derived from the wear
+// configuration when the record is read.
+type FurnaceHealth struct {
+	HeatingSeconds float64   `json:"heating_seconds" example:"7200"` // time the elements were energized in HEAT
+	Cycles         int       `json:"cycles" example:"12"`            // heat cycles (runs) started
+	LastRunID      string    `json:"last_run_id,omitempty"`          // last run counted as a cycle
+	MaintenanceDue bool      `json:"maintenance_due"`                // MAINTENANCE_DUE has been raised
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	// ReplacedHeatingSeconds and ReplacedCycles are the counters when the
+	// elements were last replaced; wear counts from there.
+	ReplacedHeatingSeconds float64 `json:"replaced_heating_seconds,omitempty"`
+	ReplacedCycles         int     `json:"replaced_cycles,omitempty"`
+
+	HeatingHours      float64 `json:"heating_hours" example:"2"`
+	ElementHours      float64 `json:"element_hours" example:"2"`         // heating hours of the current elements
+	RampDegradation   float64 `json:"ramp_degradation" example:"0.0034"` // share of the nominal ramp rate lost to wear, 0..1
+	RampUpCPerSec     float64 `json:"ramp_up_c_per_sec" example:"2.99"`  // effective heating rate
+	MaintenanceHours  float64 `json:"maintenance_hours" example:"2000"`  // heating hours at which maintenance is due; 0 if unset
+	MaintenanceCycles int     `json:"maintenance_cycles" example:"5000"` // cycles at which maintenance is due; 0 if unset
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// FurnaceSample is one point of the temperature history, written by the
+// simulator every tick.
+type FurnaceSample struct {
+	At      time.Time `json:"at"`
+	TempC   float64   `json:"temp_c"`             // °C, true chamber temperature
+	TargetC float64   `json:"target_c,omitempty"` // °C, 0 when no target is set
+	Mode    string    `json:"mode"`
+}
+
+// HistoryBucket summarises the samples of one resolution interval.
+type HistoryBucket struct {
+	Start    time.Time `json:"start"`
+	Samples  int       `json:"samples"`
+	MinC     float64   `json:"min_c"`
+	MaxC     float64   `json:"max_c"`
+	AvgC     float64   `json:"avg_c"`
+	TargetC  float64   `json:"target_c,omitempty"` // highest target in the interval
+	RuntimeS float64   `json:"runtime_s"`          // seconds spent heating in the interval
+}
+
+// TemperatureHistory is a downsampled temperature series. Intervals without
+// samples are omitted.
+type TemperatureHistory struct {
+	From        time.Time       `json:"from"`
+	To          time.Time       `json:"to"`
+	ResolutionS int             `json:"resolution_s"`
+	Count       int             `json:"count"`
+	Buckets     []HistoryBucket `json:"buckets"`
+}
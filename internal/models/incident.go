@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Incident is the record of an alarm episode: it opens when the first error
+// code is raised and is compiled when the last one clears.
+type Incident struct {
+	ID        int64      `json:"id"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"` // nil while alarms are active
+	DurationS float64    `json:"duration_s"`         // so far, for open incidents
+	RunID     string     `json:"run_id,omitempty"`   // run active when the episode began
+	// AlarmCodes lists every code raised during the episode, in order.
+	AlarmCodes    []string `json:"alarm_codes"`
+	PeakTempC     float64  `json:"peak_temp_c"`     // highest chamber temperature
+	PeakMeasuredC float64  `json:"peak_measured_c"` // highest sensor reading
+	// OverheatS is how long the chamber stayed above MaxSafeC (OVERHEAT
+	// raised) during the episode.
+	OverheatS  float64 `json:"overheat_s"`
+	Resolution string  `json:"resolution,omitempty" example:"cleared"` // how it ended; empty while open
+
+	AckedBy     int        `json:"acked_by,omitempty"` // user ID of the acknowledging operator
+	AckedByName string     `json:"acked_by_name,omitempty"`
+	AckedAt     *time.Time `json:"acked_at,omitempty"`
+
+	// Filled when the incident closes; omitted from listings.
+	Events    []FurnaceEvent  `json:"events,omitempty"`    // events logged during the episode
+	Telemetry []HistoryBucket `json:"telemetry,omitempty"` // temperature around the episode
+}
+
+// How an incident ended.
+const (
+	IncidentCleared = "cleared" // alarms cleared with the furnace still running
+	IncidentStopped = "stopped" // the furnace was off when the alarms cleared
+)
+
+// Open reports whether the episode's alarms are still active.
+func (i Incident) Open() bool { return i.EndedAt == nil }
+
+// Escalation is the notification sent to a tier of the escalation chain
+// while a critical incident stays unacknowledged.
+type Escalation struct {
+	IncidentID int64     `json:"incident_id"`
+	Level      int       `json:"level" example:"1"` // 1 for the first tier
+	Tier       string    `json:"tier" example:"shift-lead"`
+	Users      []string  `json:"users,omitempty"` // who the tier pages
+	AlarmCodes []string  `json:"alarm_codes"`
+	StartedAt  time.Time `json:"started_at"`
+	UnackedS   float64   `json:"unacked_s"` // since the incident started
+	RunID      string    `json:"run_id,omitempty"`
+	At         time.Time `json:"at"`
+}
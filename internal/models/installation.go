@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Display units chosen at setup. The API always reports °C; clients use
+// the preference to convert for display.
+const (
+	UnitsCelsius    = "C"
+	UnitsFahrenheit = "F"
+)
+
+// Installation holds the settings chosen at first-run setup. It is stored
+// as a single row; the zero value means setup has not run.
+type Installation struct {
+	SigningKey  string     `json:"signing_key"` // JWT HMAC key; never sent to clients
+	Units       string     `json:"units"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
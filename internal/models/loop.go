@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// States of a supervised background loop.
+const (
+	LoopRunning = "running"
+	LoopBackoff = "backoff" // failed; waiting to be restarted
+	LoopStopped = "stopped"
+)
+
+// LoopStatus reports one supervised background loop, such as the
+// simulator.
+type LoopStatus struct {
+	Name      string    `json:"name" example:"simulator"`
+	State     string    `json:"state" example:"running"`
+	StartedAt time.Time `json:"started_at"` // start of the current attempt
+	Restarts  int       `json:"restarts" example:"1"`
+	// LastError describes the last panic or unexpected exit.
+	LastError     string     `json:"last_error,omitempty" example:"panic: runtime error: index out of range [3] with length 3"`
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty"`
+}
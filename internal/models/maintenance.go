@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Maintenance task kinds.
+const (
+	MaintenanceCalibration = "calibration"         // thermocouple or controller calibration
+	MaintenanceElements    = "element_replacement" // heating elements; completing it resets heater wear
+	MaintenanceInspection  = "inspection"          // any other recurring check
+)
+
+// MaintenanceTask is recurring upkeep that falls due after IntervalHours
+// heating hours or IntervalDays calendar days since it was last done,
+// whichever comes first.
+type MaintenanceTask struct {
+	ID            int     `json:"id"`
+	Name          string  `json:"name" example:"Replace heating elements"`
+	Kind          string  `json:"kind" example:"element_replacement"`
+	Part          string  `json:"part,omitempty" example:"KANTHAL-A1-8MM"` // spare part consumed, if any
+	IntervalHours float64 `json:"interval_hours,omitempty" example:"2000"` // heating hours; 0 if calendar only
+	IntervalDays  int     `json:"interval_days,omitempty" example:"365"`   // days; 0 if heat-hours only
+	// BaselineAt and BaselineHours are when, and at how many heating hours,
+	// the task was last done, or created if never.
+	BaselineAt    time.Time  `json:"baseline_at"`
+	BaselineHours float64    `json:"baseline_hours"`
+	LastDoneAt    *time.Time `json:"last_done_at,omitempty"`
+	CreatedBy     int        `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	// OverdueLogged is set once MAINTENANCE_OVERDUE was logged for the
+	// current interval.
+	OverdueLogged bool `json:"-"`
+
+	// Derived when the task is read.
+	DueAt    *time.Time `json:"due_at,omitempty"`    // calendar due date
+	DueHours *float64   `json:"due_hours,omitempty"` // heating hours at which the task is due
+	Overdue  bool       `json:"overdue"`
+}
+
+// MaintenanceRecord documents one completion of a task.
+type MaintenanceRecord struct {
+	ID           int64     `json:"id"`
+	TaskID       int       `json:"task_id"`
+	TaskName     string    `json:"task_name"`
+	Kind         string    `json:"kind"`
+	Part         string    `json:"part,omitempty"`
+	CompletedAt  time.Time `json:"completed_at"`
+	CompletedBy  int       `json:"completed_by"`
+	HeatingHours float64   `json:"heating_hours" example:"2013.5"` // heater hours when completed
+	Note         string    `json:"note,omitempty" example:"Replaced all six elements"`
+}
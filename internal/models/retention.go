@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PurgeReport describes the events removed, or that would be removed, by
+// an event log purge.
+type PurgeReport struct {
+	DryRun  bool  `json:"dry_run"`
+	Deleted int64 `json:"deleted" example:"1200"` // rows removed, or selected in a dry run
+	// Oldest and Newest bound the occurred_at of the selected rows; both are
+	// omitted when nothing was selected.
+	Oldest *time.Time `json:"oldest,omitempty"`
+	Newest *time.Time `json:"newest,omitempty"`
+	// Archive is the file the rows were copied to before deletion.
+	Archive string `json:"archive,omitempty" example:"data/archive/events-20250920T100000Z.ndjson.gz"`
+}
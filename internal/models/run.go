@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Run is the record of a single heat cycle, including its soak quality.
+type Run struct {
+	RunID       string    `json:"run_id"`
+	TargetTempC float64   `json:"target_temp_c"` // °C
+	StartedAt   time.Time `json:"started_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Soak statistics, measured on the sensor reading while holding at target.
+	SoakSeconds       float64 `json:"soak_seconds"`        // time spent in soak so far
+	SoakWithinSeconds float64 `json:"soak_within_seconds"` // part of it within ±tolerance
+	SoakMeanC         float64 `json:"soak_mean_c"`         // °C, time-weighted mean
+	SoakStdDevC       float64 `json:"soak_stddev_c"`       // °C, time-weighted standard deviation
+	StabilityScore    float64 `json:"stability_score"`     // SoakWithinSeconds / SoakSeconds, 0..1
+	SoakUnstable      bool    `json:"soak_unstable"`       // SOAK_UNSTABLE was raised for this run
+
+	EnergyKWh float64 `json:"energy_kwh"` // kWh consumed from HEAT until the run ended
+}
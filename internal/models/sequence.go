@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Sequence statuses.
+const (
+	SequenceRunning   = "RUNNING"
+	SequenceCompleted = "COMPLETED"
+	SequenceAborted   = "ABORTED"
+)
+
+// SequenceStep is one mode a sequence holds before moving on. HEAT ends
+// when its soak of DurationSec has elapsed; COOL once the chamber is down
+// to TargetTempC or after DurationSec, whichever comes first; STANDBY
+// after DurationSec. Durations are simulated seconds.
+type SequenceStep struct {
+	Mode        string  `json:"mode" example:"HEAT"`                   // HEAT | COOL | STANDBY
+	TargetTempC float64 `json:"target_temp_c,omitempty" example:"850"` // °C
+	DurationSec int     `json:"duration_sec,omitempty" example:"600"`
+}
+
+// Sequence is an ad-hoc list of mode steps run back to back.
+type Sequence struct {
+	ID     string         `json:"id"`
+	Steps  []SequenceStep `json:"steps"`
+	Status string         `json:"status"` // RUNNING | COMPLETED | ABORTED
+	// Step is the 1-based step running, or the last one reached once the
+	// sequence has ended.
+	Step       int        `json:"step"`
+	Reason     string     `json:"reason,omitempty"` // why it was aborted
+	CreatedBy  int        `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// SequenceProgress is the running sequence as shown in the state.
+type SequenceProgress struct {
+	ID    string `json:"id"`
+	Step  int    `json:"step"`  // 1-based
+	Steps int    `json:"steps"` // total
+}
@@ -0,0 +1,43 @@
+package models
+
+// SimSettings are the simulator parameters QA can tune at runtime. They are
+// persisted so a restart keeps the last applied values.
+type SimSettings struct {
+	TickMs int `json:"tick_ms" example:"1000"` // interval between simulation steps
+
+	AmbientC           float64 `json:"ambient_c" example:"25"`               // °C, room temperature the chamber cools to
+	MaxSafeC           float64 `json:"max_safe_c" example:"1000"`            // °C, OVERHEAT threshold and highest allowed target
+	RampUpCPerSec      float64 `json:"ramp_up_c_per_sec" example:"3"`        // heating rate in HEAT
+	RampDownCPerSec    float64 `json:"ramp_down_c_per_sec" example:"5"`      // cooling rate in COOL
+	StandbyCoolCPerSec float64 `json:"standby_cool_c_per_sec" example:"0.5"` // passive cooling rate
+
+	NoiseStdDevC        float64 `json:"noise_stddev_c" example:"0"`         // chamber sensor noise, °C
+	DriftCPerHour       float64 `json:"drift_c_per_hour" example:"0"`       // chamber sensor drift rate
+	MaxDriftC           float64 `json:"max_drift_c" example:"5"`            // cap on accumulated drift, °C
+	AmbientNoiseStdDevC float64 `json:"ambient_noise_stddev_c" example:"0"` // cold-junction sensor noise, °C
+}
+
+// SettingsConflict is something proposed simulator settings would make
+// invalid.
+type SettingsConflict struct {
+	// What is affected: state.target_temp_c, state.current_temp_c,
+	// ambient.override or alert_rule
+	Subject string  `json:"subject" example:"state.target_temp_c"`
+	RuleID  int     `json:"rule_id,omitempty"` // for alert_rule
+	Value   float64 `json:"value" example:"950"`
+	Message string  `json:"message" example:"active HEAT target 950.0 °C exceeds max_safe_c 900.0 °C"`
+	// Blocking conflicts keep the settings from being applied; the others
+	// are warnings.
+	Blocking bool `json:"blocking"`
+}
+
+// SettingsPreview reports what proposed simulator settings would do to the
+// active state, the room override and the alert rules.
+type SettingsPreview struct {
+	Current   SimSettings        `json:"current"`
+	Proposed  SimSettings        `json:"proposed"`
+	Valid     bool               `json:"valid"`
+	Error     string             `json:"error,omitempty"` // why the proposed settings are invalid
+	Conflicts []SettingsConflict `json:"conflicts"`
+	Applied   bool               `json:"applied"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Telemetry channels recorded by the simulator.
+const (
+	ChannelChamber = "chamber"  // measured chamber temperature, °C
+	ChannelAmbient = "ambient"  // cold-junction (ambient) temperature, °C
+	ChannelO2      = "o2"       // residual oxygen in the chamber, ppm
+	ChannelGasFlow = "gas_flow" // protective gas flow, m³/h
+)
+
+// TelemetrySample is one reading of a telemetry channel.
+type TelemetrySample struct {
+	At      time.Time `json:"at"`
+	Channel string    `json:"channel"`
+	Value   float64   `json:"value"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Service status reported by the uptime summary and badge.
+const (
+	UptimeUp      = "up"
+	UptimeDown    = "down"
+	UptimeUnknown = "unknown" // no readiness check recorded yet
+)
+
+// UptimeCheck is the outcome of one periodic readiness check.
+type UptimeCheck struct {
+	At time.Time `json:"at"`
+	Up bool      `json:"up"`
+}
+
+// UptimeCounts tallies the readiness checks recorded in a window.
+type UptimeCounts struct {
+	Checks int
+	Up     int
+	First  time.Time // oldest check in the window; zero without checks
+}
+
+// UptimeWindow is the availability over a rolling window. Checks that
+// should have run but were not recorded, e.g. while the service was
+// stopped, count as down.
+type UptimeWindow struct {
+	Window string `json:"window" example:"24h"`
+	// Omitted when no check was recorded in the window
+	AvailabilityPct *float64 `json:"availability_pct,omitempty" example:"99.93"`
+	Checks          int      `json:"checks" example:"1440"`
+	Missed          int      `json:"missed" example:"1"`
+}
+
+// UptimeSummary is the public status of the service.
+type UptimeSummary struct {
+	Status    string         `json:"status" example:"up"`
+	CheckedAt *time.Time     `json:"checked_at,omitempty"` // last readiness check
+	Windows   []UptimeWindow `json:"windows"`
+}
@@ -1,7 +1,15 @@
 package models
 
+// User roles. Every account has exactly one role.
+const (
+	RoleAdmin    = "admin"    // full control plus administrative endpoints
+	RoleOperator = "operator" // furnace control and monitoring
+	RoleViewer   = "viewer"   // read-only access
+)
+
 type User struct {
 	ID           int    `json:"id"`
 	Username     string `json:"username"`
 	PasswordHash string `json:"-"` // don’t expose hash
+	Role         string `json:"role"`
 }
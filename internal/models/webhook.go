@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"   // waiting for its first or next attempt
+	DeliveryDelivered = "delivered" // the endpoint answered 2xx
+	DeliveryFailed    = "failed"    // gave up after the last attempt
+)
+
+// Webhook is an external endpoint that receives events of the listed
+// types as signed JSON POSTs.
+type Webhook struct {
+	ID         int      `json:"id"`
+	URL        string   `json:"url" example:"https://mes.example.com/hooks/furnace"`
+	EventTypes []string `json:"event_types" example:"STOP,ERROR"`
+	// Secret keys the HMAC-SHA256 signature of each body; never returned.
+	Secret    string    `json:"-"`
+	HasSecret bool      `json:"has_secret"`
+	Enabled   bool      `json:"enabled"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one event queued for one webhook, with the outcome
+// of its latest attempt.
+type WebhookDelivery struct {
+	ID        int64  `json:"id"`
+	WebhookID int    `json:"webhook_id"`
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type" example:"STOP"`
+	// Payload is the JSON body, fixed when the delivery is queued.
+	Payload        string     `json:"-"`
+	Status         string     `json:"status" example:"delivered"`
+	Attempts       int        `json:"attempts" example:"1"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"` // while pending
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	ResponseStatus int        `json:"response_status,omitempty" example:"200"` // HTTP status of the last attempt
+	LatencyMs      int64      `json:"latency_ms,omitempty" example:"120"`      // duration of the last attempt
+	// ResponseSnippet is the start of the last response body.
+	ResponseSnippet string     `json:"response_snippet,omitempty" example:"{\"error\":\"unknown furnace\"}"`
+	LastError       string     `json:"last_error,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	DeliveredAt     *time.Time `json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+type AlertSQLite struct {
+	db   *sql.DB
+	site string // rules and alerts of other sites are invisible
+}
+
+func NewAlertSQLite(db *sql.DB) *AlertSQLite { return &AlertSQLite{db: db, site: DefaultSite} }
+
+// Ensure implementation of AlertRepo interface at compile time.
+var _ AlertRepo = (*AlertSQLite)(nil)
+
+const (
+	alertRuleColumns = `id, name, kind, threshold, for_s, enabled, created_by, created_at, updated_at`
+
+	insertAlertRuleSQL = `
+		INSERT INTO alert_rules (name, kind, threshold, for_s, enabled, created_by, created_at, updated_at, site_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	updateAlertRuleSQL = `
+		UPDATE alert_rules SET name=?, kind=?, threshold=?, for_s=?, enabled=?, updated_at=?
+		WHERE id=? AND site_id=?
+	`
+	deleteAlertRuleSQL = `DELETE FROM alert_rules WHERE id=? AND site_id=?`
+	selectAlertRuleSQL = `SELECT ` + alertRuleColumns + ` FROM alert_rules WHERE id=? AND site_id=?`
+	listAlertRulesSQL  = `SELECT ` + alertRuleColumns + ` FROM alert_rules WHERE site_id=? ORDER BY id ASC`
+
+	insertAlertSQL = `
+		INSERT INTO alerts (rule_id, rule_name, kind, fired_at, value, message, run_id, notified, notify_error, site_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+)
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAlertRule(s rowScanner) (models.AlertRule, error) {
+	var r models.AlertRule
+	err := s.Scan(&r.ID, &r.Name, &r.Kind, &r.Threshold, &r.ForSeconds, &r.Enabled, &r.CreatedBy, &r.CreatedAt, &r.UpdatedAt)
+	r.CreatedAt, r.UpdatedAt = r.CreatedAt.UTC(), r.UpdatedAt.UTC()
+	return r, err
+}
+
+// CreateRule stores a new rule and returns its ID.
+func (r *AlertSQLite) CreateRule(ctx context.Context, rule models.AlertRule) (int, error) {
+	now := time.Now().UTC()
+	res, err := r.db.ExecContext(ctx, insertAlertRuleSQL,
+		rule.Name, rule.Kind, rule.Threshold, rule.ForSeconds, rule.Enabled, rule.CreatedBy, now, now, r.site)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// UpdateRule replaces the editable fields of a rule. It reports false if
+// the rule does not exist.
+func (r *AlertSQLite) UpdateRule(ctx context.Context, rule models.AlertRule) (bool, error) {
+	res, err := r.db.ExecContext(ctx, updateAlertRuleSQL,
+		rule.Name, rule.Kind, rule.Threshold, rule.ForSeconds, rule.Enabled, time.Now().UTC(), rule.ID, r.site)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteRule removes a rule; its alerts are kept. It reports false if the
+// rule does not exist.
+func (r *AlertSQLite) DeleteRule(ctx context.Context, id int) (bool, error) {
+	res, err := r.db.ExecContext(ctx, deleteAlertRuleSQL, id, r.site)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetRule fetches a rule by ID; a missing rule yields a zero value and nil error.
+func (r *AlertSQLite) GetRule(ctx context.Context, id int) (models.AlertRule, error) {
+	rule, err := scanAlertRule(r.db.QueryRowContext(ctx, selectAlertRuleSQL, id, r.site))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.AlertRule{}, nil
+	}
+	return rule, err
+}
+
+func (r *AlertSQLite) ListRules(ctx context.Context) ([]models.AlertRule, error) {
+	rows, err := r.db.QueryContext(ctx, listAlertRulesSQL, r.site)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.AlertRule, 0, 8)
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rule)
+	}
+	return out, rows.Err()
+}
+
+// AppendAlert records a fired alert and returns its ID.
+func (r *AlertSQLite) AppendAlert(ctx context.Context, a models.Alert) (int64, error) {
+	res, err := r.db.ExecContext(ctx, insertAlertSQL,
+		a.RuleID, a.RuleName, a.Kind, a.FiredAt.UTC(), a.Value, a.Message, a.RunID, a.Notified, a.NotifyError, r.site)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// ListAlerts returns alerts matching q, newest first.
+func (r *AlertSQLite) ListAlerts(ctx context.Context, q AlertQuery) ([]models.Alert, error) {
+	conds := []string{"site_id = ?"}
+	args := []any{r.site}
+	if q.RuleID != 0 {
+		conds = append(conds, "rule_id = ?")
+		args = append(args, q.RuleID)
+	}
+	if !q.From.IsZero() {
+		conds = append(conds, "fired_at >= ?")
+		args = append(args, q.From.UTC())
+	}
+	if !q.To.IsZero() {
+		conds = append(conds, "fired_at <= ?")
+		args = append(args, q.To.UTC())
+	}
+
+	stmt := `SELECT id, rule_id, rule_name, kind, fired_at, value, message, run_id, notified, notify_error FROM alerts WHERE ` + strings.Join(conds, " AND ")
+	stmt += " ORDER BY fired_at DESC, id DESC"
+	if q.Limit > 0 {
+		stmt += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.Alert, 0, 16)
+	for rows.Next() {
+		var a models.Alert
+		if err := rows.Scan(&a.ID, &a.RuleID, &a.RuleName, &a.Kind, &a.FiredAt, &a.Value, &a.Message, &a.RunID, &a.Notified, &a.NotifyError); err != nil {
+			return nil, err
+		}
+		a.FiredAt = a.FiredAt.UTC()
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,104 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestAlertSQLite_CreateAndGetRule(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO alert_rules")).
+		WithArgs("Too hot", models.AlertTempAbove, 950.0, 30, true, 7, sqlmock.AnyArg(), sqlmock.AnyArg(), repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM alert_rules WHERE id=? AND site_id=?")).
+		WithArgs(4, repository.DefaultSite).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "kind", "threshold", "for_s", "enabled", "created_by", "created_at", "updated_at"}).
+			AddRow(4, "Too hot", models.AlertTempAbove, 950.0, 30, true, 7, at, at))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM alert_rules WHERE id=? AND site_id=?")).
+		WithArgs(5, repository.DefaultSite).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	repo := repository.NewAlertSQLite(db)
+	id, err := repo.CreateRule(context.Background(), models.AlertRule{
+		Name: "Too hot", Kind: models.AlertTempAbove, Threshold: 950, ForSeconds: 30, Enabled: true, CreatedBy: 7,
+	})
+	if err != nil || id != 4 {
+		t.Fatalf("CreateRule() = %d, %v", id, err)
+	}
+	rule, err := repo.GetRule(context.Background(), 4)
+	if err != nil || rule.ID != 4 || rule.ForSeconds != 30 || !rule.CreatedAt.Equal(at) {
+		t.Fatalf("GetRule() = %+v, %v", rule, err)
+	}
+	if rule, err := repo.GetRule(context.Background(), 5); err != nil || rule.ID != 0 {
+		t.Fatalf("expected a zero rule for a missing ID, got %+v, %v", rule, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestAlertSQLite_UpdateAndDeleteReportMissingRules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE alert_rules")).
+		WithArgs("x", models.AlertErrorEvent, 0.0, 0, false, sqlmock.AnyArg(), 9, repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM alert_rules WHERE id=? AND site_id=?")).
+		WithArgs(4, repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := repository.NewAlertSQLite(db)
+	found, err := repo.UpdateRule(context.Background(), models.AlertRule{ID: 9, Name: "x", Kind: models.AlertErrorEvent})
+	if err != nil || found {
+		t.Fatalf("UpdateRule() = %v, %v; want false", found, err)
+	}
+	if found, err := repo.DeleteRule(context.Background(), 4); err != nil || !found {
+		t.Fatalf("DeleteRule() = %v, %v; want true", found, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestAlertSQLite_ListAlertsFilters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	from := time.Date(2025, 9, 20, 0, 0, 0, 0, time.UTC)
+	fired := from.Add(time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM alerts WHERE site_id = ? AND rule_id = ? AND fired_at >= ? ORDER BY fired_at DESC, id DESC LIMIT ?")).
+		WithArgs(repository.DefaultSite, 3, from, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "rule_id", "rule_name", "kind", "fired_at", "value", "message", "run_id", "notified", "notify_error"}).
+			AddRow(int64(12), 3, "Too hot", models.AlertTempAbove, fired, 951.5, "temperature 951.5 °C above 950.0 °C", "run-1", false, "status 502"))
+
+	got, err := repository.NewAlertSQLite(db).ListAlerts(context.Background(), repository.AlertQuery{RuleID: 3, From: from, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListAlerts() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 12 || got[0].Value != 951.5 || got[0].NotifyError != "status 502" || !got[0].FiredAt.Equal(fired) {
+		t.Fatalf("unexpected alerts: %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
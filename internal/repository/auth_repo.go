@@ -1,32 +1,68 @@
 package repository
 
 import (
+	"context"
 	cf "controlling_furnace/internal/models"
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
 )
 
+// ErrUsernameTaken is returned by Create when another user has the same
+// name regardless of case.
+var ErrUsernameTaken = errors.New("username already taken")
+
+// ErrLastAdmin is returned by SetRole for a change that would leave the
+// site without an admin. Setup is closed once the site has users, so no
+// one could become admin again.
+var ErrLastAdmin = errors.New("the site's last admin cannot lose the admin role")
+
 type UserRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	site   string        // users of other sites are invisible
+	cipher *ColumnCipher // encrypts password hashes; nil stores them as they are
 }
 
 func NewUserRepository(db *sql.DB) *UserRepository {
-	return &UserRepository{db: db}
+	return &UserRepository{db: db, site: DefaultSite}
 }
 
 // Ensure implementation of Authorization interface at compile time.
 var _ Authorization = (*UserRepository)(nil)
 
 const (
-	insertUserSQL           = `INSERT INTO users (username, password_hash) VALUES (?, ?)`
-	selectUserByUsernameSQL = `SELECT id, username, password_hash FROM users WHERE username = ?`
+	insertUserSQL = `INSERT INTO users (site_id, username, username_key, password_hash) VALUES (?, ?, ?, ?)`
+	// an exact match wins over a case-insensitive one; see migration 0002
+	// for users without a key
+	selectUserByUsernameSQL = `SELECT id, username, password_hash, role FROM users
+		WHERE site_id = ? AND (username_key = ? OR username = ?) ORDER BY username = ? DESC LIMIT 1`
+	countUsersSQL     = `SELECT COUNT(*) FROM users WHERE site_id = ?`
+	selectUserRoleSQL = `SELECT role FROM users WHERE id = ? AND site_id = ?`
+	updateUserRoleSQL = `UPDATE users SET role = ? WHERE id = ? AND site_id = ?`
+	countAdminsSQL    = `SELECT COUNT(*) FROM users WHERE site_id = ? AND role = 'admin'`
 )
 
-// Create inserts a new user and returns its ID.
-func (r *UserRepository) Create(username, passwordHash string) (int, error) {
-	res, err := r.db.Exec(insertUserSQL, username, passwordHash)
+// usernameKey is the form usernames are compared in.
+func usernameKey(username string) string {
+	return strings.ToLower(norm.NFC.String(username))
+}
+
+// Create inserts a new user of the site and returns its ID. It fails with
+// ErrUsernameTaken if the name differs only in case from another user's
+// there.
+func (r *UserRepository) Create(ctx context.Context, username, passwordHash string) (int, error) {
+	stored, err := r.cipher.seal(passwordHash)
 	if err != nil {
+		return 0, fmt.Errorf("encrypt password hash for user %q: %w", username, err)
+	}
+	res, err := r.db.ExecContext(ctx, insertUserSQL, r.site, username, usernameKey(username), stored)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return 0, fmt.Errorf("insert user %q: %w", username, ErrUsernameTaken)
+		}
 		return 0, fmt.Errorf("insert user %q: %w", username, err)
 	}
 	lastID, err := res.LastInsertId()
@@ -36,15 +72,59 @@ func (r *UserRepository) Create(username, passwordHash string) (int, error) {
 	return int(lastID), nil
 }
 
-// GetByUsername fetches a user by username. Returns (nil, nil) if not found.
-func (r *UserRepository) GetByUsername(username string) (*cf.User, error) {
+// GetByUsername fetches a user by username regardless of case. Returns
+// (nil, nil) if not found.
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*cf.User, error) {
 	var u cf.User
-	err := r.db.QueryRow(selectUserByUsernameSQL, username).Scan(&u.ID, &u.Username, &u.PasswordHash)
+	err := r.db.QueryRowContext(ctx, selectUserByUsernameSQL, r.site, usernameKey(username), username, username).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Role)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("select user %q: %w", username, err)
 	}
+	if u.PasswordHash, err = r.cipher.open(u.PasswordHash); err != nil {
+		return nil, fmt.Errorf("decrypt password hash for user %q: %w", username, err)
+	}
 	return &u, nil
 }
+
+// Count returns the number of users registered at the site.
+func (r *UserRepository) Count(ctx context.Context) (int, error) {
+	var n int
+	if err := r.db.QueryRowContext(ctx, countUsersSQL, r.site).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count users: %w", err)
+	}
+	return n, nil
+}
+
+// SetRole changes the role of the user with the given ID. It fails with
+// ErrLastAdmin, changing nothing, if that would leave the site without an
+// admin; the admins are counted in the same transaction as the change.
+func (r *UserRepository) SetRole(ctx context.Context, id int, role string) error {
+	return retryBusy(ctx, func() error {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback() }()
+
+		var old string
+		if err := tx.QueryRowContext(ctx, selectUserRoleSQL, id, r.site).Scan(&old); err != nil {
+			return fmt.Errorf("select role of user %d: %w", id, err)
+		}
+		if _, err := tx.ExecContext(ctx, updateUserRoleSQL, role, id, r.site); err != nil {
+			return fmt.Errorf("update role for user %d: %w", id, err)
+		}
+		if old == cf.RoleAdmin && role != cf.RoleAdmin {
+			var admins int
+			if err := tx.QueryRowContext(ctx, countAdminsSQL, r.site).Scan(&admins); err != nil {
+				return fmt.Errorf("count admins: %w", err)
+			}
+			if admins == 0 {
+				return fmt.Errorf("update role for user %d: %w", id, ErrLastAdmin)
+			}
+		}
+		return tx.Commit()
+	})
+}
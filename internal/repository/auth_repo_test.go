@@ -2,11 +2,13 @@
 package repository
 
 import (
+	"context"
 	cf "controlling_furnace/internal/models"
 	"database/sql"
 	"errors"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 )
@@ -45,7 +47,7 @@ func TestUserRepository_Create(t *testing.T) {
 			passwordHash: "h123",
 			mockExpect: func(m sqlmock.Sqlmock) {
 				m.ExpectExec(regexp.QuoteMeta(insertUserSQL)).
-					WithArgs("alice", "h123").
+					WithArgs(DefaultSite, "alice", "alice", "h123").
 					WillReturnResult(sqlmock.NewResult(42, 1))
 			},
 			wantID:  42,
@@ -57,7 +59,7 @@ func TestUserRepository_Create(t *testing.T) {
 			passwordHash: "h456",
 			mockExpect: func(m sqlmock.Sqlmock) {
 				m.ExpectExec(regexp.QuoteMeta(insertUserSQL)).
-					WithArgs("bob", "h456").
+					WithArgs(DefaultSite, "bob", "bob", "h456").
 					WillReturnError(errors.New("db exec failed"))
 			},
 			wantID:         0,
@@ -70,13 +72,26 @@ func TestUserRepository_Create(t *testing.T) {
 			passwordHash: "h789",
 			mockExpect: func(m sqlmock.Sqlmock) {
 				m.ExpectExec(regexp.QuoteMeta(insertUserSQL)).
-					WithArgs("carol", "h789").
+					WithArgs(DefaultSite, "carol", "carol", "h789").
 					WillReturnResult(sqlmock.NewErrorResult(errors.New("no last id")))
 			},
 			wantID:         0,
 			wantErr:        true,
 			errContainsStr: "get last insert id",
 		},
+		{
+			name:         "name taken in another case",
+			username:     "Dave",
+			passwordHash: "h000",
+			mockExpect: func(m sqlmock.Sqlmock) {
+				m.ExpectExec(regexp.QuoteMeta(insertUserSQL)).
+					WithArgs(DefaultSite, "Dave", "dave", "h000").
+					WillReturnError(errors.New("constraint failed: UNIQUE constraint failed: users.username_key (2067)"))
+			},
+			wantID:         0,
+			wantErr:        true,
+			errContainsStr: ErrUsernameTaken.Error(),
+		},
 	}
 
 	for _, tt := range tests {
@@ -87,7 +102,7 @@ func TestUserRepository_Create(t *testing.T) {
 
 			tt.mockExpect(mock)
 
-			id, err := repo.Create(tt.username, tt.passwordHash)
+			id, err := repo.Create(context.Background(), tt.username, tt.passwordHash)
 
 			if tt.wantErr {
 				if err == nil {
@@ -125,16 +140,17 @@ func TestUserRepository_GetByUsername(t *testing.T) {
 			name:     "found",
 			username: "alice",
 			mockExpect: func(m sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "username", "password_hash"}).
-					AddRow(7, "alice", "h123")
+				rows := sqlmock.NewRows([]string{"id", "username", "password_hash", "role"}).
+					AddRow(7, "alice", "h123", "admin")
 				m.ExpectQuery(regexp.QuoteMeta(selectUserByUsernameSQL)).
-					WithArgs("alice").
+					WithArgs(DefaultSite, "alice", "alice", "alice").
 					WillReturnRows(rows)
 			},
 			wantUser: &cf.User{
 				ID:           7,
 				Username:     "alice",
 				PasswordHash: "h123",
+				Role:         "admin",
 			},
 			wantErr: false,
 		},
@@ -143,7 +159,7 @@ func TestUserRepository_GetByUsername(t *testing.T) {
 			username: "missing",
 			mockExpect: func(m sqlmock.Sqlmock) {
 				m.ExpectQuery(regexp.QuoteMeta(selectUserByUsernameSQL)).
-					WithArgs("missing").
+					WithArgs(DefaultSite, "missing", "missing", "missing").
 					WillReturnError(sql.ErrNoRows)
 			},
 			wantUser: nil,
@@ -154,7 +170,7 @@ func TestUserRepository_GetByUsername(t *testing.T) {
 			username: "bob",
 			mockExpect: func(m sqlmock.Sqlmock) {
 				m.ExpectQuery(regexp.QuoteMeta(selectUserByUsernameSQL)).
-					WithArgs("bob").
+					WithArgs(DefaultSite, "bob", "bob", "bob").
 					WillReturnError(errors.New("db query failed"))
 			},
 			wantUser:       nil,
@@ -171,7 +187,7 @@ func TestUserRepository_GetByUsername(t *testing.T) {
 
 			tt.mockExpect(mock)
 
-			u, err := repo.GetByUsername(tt.username)
+			u, err := repo.GetByUsername(context.Background(), tt.username)
 
 			if tt.wantErr {
 				if err == nil {
@@ -198,13 +214,104 @@ func TestUserRepository_GetByUsername(t *testing.T) {
 			if u == nil {
 				t.Fatalf("expected user, got nil")
 			}
-			if u.ID != tt.wantUser.ID || u.Username != tt.wantUser.Username || u.PasswordHash != tt.wantUser.PasswordHash {
+			if u.ID != tt.wantUser.ID || u.Username != tt.wantUser.Username || u.PasswordHash != tt.wantUser.PasswordHash || u.Role != tt.wantUser.Role {
 				t.Fatalf("unexpected user: want %+v, got %+v", tt.wantUser, u)
 			}
 		})
 	}
 }
 
+func TestUserRepository_Count(t *testing.T) {
+	repo, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta(countUsersSQL)).
+		WithArgs(DefaultSite).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	n, err := repo.Count(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("unexpected count: want 3, got %d", n)
+	}
+}
+
+func TestUserRepository_SetRole(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo, mock, cleanup := newMockRepo(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(selectUserRoleSQL)).
+			WithArgs(7, DefaultSite).
+			WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("viewer"))
+		mock.ExpectExec(regexp.QuoteMeta(updateUserRoleSQL)).
+			WithArgs("admin", 7, DefaultSite).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		if err := repo.SetRole(context.Background(), 7, "admin"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		repo, mock, cleanup := newMockRepo(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(selectUserRoleSQL)).
+			WithArgs(99, DefaultSite).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err := repo.SetRole(context.Background(), 99, "admin")
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected sql.ErrNoRows, got %v", err)
+		}
+	})
+
+	t.Run("last admin", func(t *testing.T) {
+		repo, mock, cleanup := newMockRepo(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(selectUserRoleSQL)).
+			WithArgs(1, DefaultSite).
+			WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("admin"))
+		mock.ExpectExec(regexp.QuoteMeta(updateUserRoleSQL)).
+			WithArgs("viewer", 1, DefaultSite).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(countAdminsSQL)).
+			WithArgs(DefaultSite).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectRollback()
+
+		err := repo.SetRole(context.Background(), 1, "viewer")
+		if !errors.Is(err, ErrLastAdmin) {
+			t.Fatalf("expected ErrLastAdmin, got %v", err)
+		}
+	})
+}
+
+func TestUserRepository_CanceledContext(t *testing.T) {
+	repo, mock, cleanup := newMockRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta(selectUserByUsernameSQL)).
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password_hash", "role"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := repo.GetByUsername(ctx, "alice"); err == nil || time.Since(start) >= time.Second {
+		t.Fatalf("expected the deadline to cut the query short, got %v after %s", err, time.Since(start))
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(substr) == 0 || (len(s) >= len(substr) && regexp.MustCompile(regexp.QuoteMeta(substr)).FindStringIndex(s) != nil)
 }
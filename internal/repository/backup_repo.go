@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrBackupUnsupported is returned by backends without a database file to
+// copy, such as the in-memory repositories.
+var ErrBackupUnsupported = errors.New("backups need the sqlite driver")
+
+type BackupSQLite struct {
+	db   *sql.DB
+	site string // the only site whose rows the snapshot keeps
+}
+
+func NewBackupSQLite(db *sql.DB) *BackupSQLite {
+	return &BackupSQLite{db: db, site: DefaultSite}
+}
+
+const (
+	// siteTablesSQL lists the tables with a site_id column.
+	siteTablesSQL = `
+		SELECT m.name FROM sqlite_master m
+		WHERE m.type = 'table' AND EXISTS (SELECT 1 FROM pragma_table_info(m.name) p WHERE p.name = 'site_id')
+		ORDER BY m.name
+	`
+	pruneBackupCommentsSQL = `DELETE FROM backup.event_comments WHERE event_id NOT IN (SELECT id FROM backup.furnace_events)`
+)
+
+// Backup writes a consistent snapshot of the site's data to path. VACUUM
+// INTO reads inside one transaction, so the copy includes what the WAL
+// holds and nothing half-written; the rows of other sites sharing the
+// database are then deleted from the copy, which is vacuumed again so
+// nothing of them is left in its free pages. The server keeps running
+// meanwhile; other database calls wait for the connection until it is
+// done.
+func (r *BackupSQLite) Backup(ctx context.Context, path string) error {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.ExecContext(ctx, `VACUUM INTO ?`, path); err != nil {
+		return err
+	}
+	tables, err := siteTables(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS backup`, path); err != nil {
+		return err
+	}
+	defer func() { _, _ = conn.ExecContext(context.Background(), `DETACH DATABASE backup`) }()
+	for _, t := range tables {
+		if _, err := conn.ExecContext(ctx, `DELETE FROM backup."`+t+`" WHERE site_id != ?`, r.site); err != nil {
+			return err
+		}
+	}
+	if _, err := conn.ExecContext(ctx, pruneBackupCommentsSQL); err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, `VACUUM backup`)
+	return err
+}
+
+func siteTables(ctx context.Context, conn *sql.Conn) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, siteTablesSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		out = append(out, name)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository/db"
+)
+
+func TestBackupSQLite_SnapshotIsUsable(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.InitDB(filepath.Join(t.TempDir(), "live.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	repos := NewRepository(conn)
+	if err := repos.EventRepo.Append(ctx, models.FurnaceEvent{Type: "START"}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "backup.db")
+	if err := repos.Backup.Backup(ctx, path); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if err := repos.Backup.Backup(ctx, path); err == nil {
+		t.Fatal("expected an existing file to be refused")
+	}
+	copied, err := db.OpenDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = copied.Close() }()
+	var check string
+	if err := copied.QueryRowContext(ctx, `PRAGMA integrity_check`).Scan(&check); err != nil || check != "ok" {
+		t.Fatalf("integrity check: %q, %v", check, err)
+	}
+	if events, err := NewRepository(copied).EventRepo.Query(ctx, EventQuery{}); err != nil || len(events) != 1 {
+		t.Fatalf("events in the snapshot: %v, %v", events, err)
+	}
+
+	if err := NewInMemory().Backup.Backup(ctx, path); !errors.Is(err, ErrBackupUnsupported) {
+		t.Fatalf("in-memory backup: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// IsBusy reports whether err is SQLite giving up on a lock another
+// connection or process holds (SQLITE_BUSY or SQLITE_LOCKED), which a
+// later attempt can get past.
+func IsBusy(err error) bool {
+	var se *sqlite.Error
+	if !errors.As(err, &se) {
+		return false
+	}
+	switch se.Code() & 0xff { // primary code, without the extended bits
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+		return true
+	}
+	return false
+}
+
+// busyBackoff are the pauses before retrying a transaction that found the
+// database locked; each attempt already waits busy_timeout for the lock.
+var busyBackoff = []time.Duration{50 * time.Millisecond, 200 * time.Millisecond, 500 * time.Millisecond}
+
+// retryBusy calls fn until it returns an error other than a busy one, the
+// retries run out or ctx is done. fn must start its transaction afresh.
+func retryBusy(ctx context.Context, fn func() error) error {
+	for i := 0; ; i++ {
+		err := fn()
+		if err == nil || !IsBusy(err) || i == len(busyBackoff) {
+			return err
+		}
+		t := time.NewTimer(busyBackoff[i])
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
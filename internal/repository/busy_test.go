@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/repository/db"
+)
+
+func TestRetryBusy_WaitsOutAnotherProcessLock(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "furnace.db")
+	conn, err := db.InitDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// another process holds the write lock; this connection does not wait
+	// for it, so the insert fails straight away
+	other, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = other.Close() }()
+	lock, err := other.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lock.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx, "PRAGMA busy_timeout = 0"); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(b []time.Duration) { busyBackoff = b }(busyBackoff)
+	busyBackoff = []time.Duration{time.Millisecond, time.Millisecond}
+	attempts := 0
+	err = retryBusy(ctx, func() error {
+		attempts++
+		_, err := conn.ExecContext(ctx, `INSERT INTO furnace_samples (ts, temp_c, target_c, mode) VALUES ('2025-09-01T10:00:00Z', 20, 0, 'STANDBY')`)
+		if attempts == 1 {
+			if !IsBusy(err) {
+				t.Errorf("first attempt: %v, want a busy error", err)
+			}
+			_, _ = lock.ExecContext(ctx, "ROLLBACK")
+			_ = lock.Close()
+		}
+		return err
+	})
+	if err != nil || attempts != 2 {
+		t.Fatalf("retryBusy = %v after %d attempts; want success on the second", err, attempts)
+	}
+
+	// other errors are returned at once
+	attempts = 0
+	boom := errors.New("boom")
+	if err := retryBusy(ctx, func() error { attempts++; return boom }); err != boom || attempts != 1 {
+		t.Fatalf("retryBusy = %v after %d attempts", err, attempts)
+	}
+}
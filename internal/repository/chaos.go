@@ -0,0 +1,693 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrChaosInjected is returned by repository calls that chaos mode decided to fail.
+var ErrChaosInjected = errors.New("chaos: injected repository failure")
+
+// ErrInvalidChaosSettings reports settings that cannot be applied.
+var ErrInvalidChaosSettings = errors.New("invalid chaos settings")
+
+// ChaosSettings controls the artificial latency and failures injected into
+// repository calls. Probabilities are in [0, 1]; nothing is injected unless
+// Enabled is true.
+type ChaosSettings struct {
+	Enabled            bool
+	Latency            time.Duration
+	LatencyProbability float64
+	ErrorProbability   float64
+}
+
+// Validate checks that the settings are within range.
+func (s ChaosSettings) Validate() error {
+	if s.Latency < 0 {
+		return fmt.Errorf("%w: latency must be >= 0", ErrInvalidChaosSettings)
+	}
+	if s.LatencyProbability < 0 || s.LatencyProbability > 1 {
+		return fmt.Errorf("%w: latency probability must be within [0, 1]", ErrInvalidChaosSettings)
+	}
+	if s.ErrorProbability < 0 || s.ErrorProbability > 1 {
+		return fmt.Errorf("%w: error probability must be within [0, 1]", ErrInvalidChaosSettings)
+	}
+	return nil
+}
+
+// Chaos injects latency and errors into wrapped repositories. It is meant for
+// staging environments where clients need to exercise their retry and health
+// handling against a misbehaving store.
+type Chaos struct {
+	mu       sync.Mutex
+	settings ChaosSettings
+	rng      *rand.Rand
+}
+
+// NewChaos returns a controller with the given initial settings.
+// The seed makes injected faults reproducible between runs.
+func NewChaos(settings ChaosSettings, seed int64) (*Chaos, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	return &Chaos{settings: settings, rng: rand.New(rand.NewSource(seed))}, nil
+}
+
+// Settings returns the current settings.
+func (c *Chaos) Settings() ChaosSettings {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.settings
+}
+
+// Update replaces the settings; the next repository call observes them.
+func (c *Chaos) Update(s ChaosSettings) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.settings = s
+	c.mu.Unlock()
+	return nil
+}
+
+// roll decides, under the lock, whether to delay and/or fail the next call.
+func (c *Chaos) roll() (delay time.Duration, fail bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.settings.Enabled {
+		return 0, false
+	}
+	if c.settings.Latency > 0 && c.rng.Float64() < c.settings.LatencyProbability {
+		delay = c.settings.Latency
+	}
+	fail = c.rng.Float64() < c.settings.ErrorProbability
+	return delay, fail
+}
+
+// inject applies the configured faults before a repository call named op.
+func (c *Chaos) inject(ctx context.Context, op string) error {
+	delay, fail := c.roll()
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	if fail {
+		return fmt.Errorf("%s: %w", op, ErrChaosInjected)
+	}
+	return nil
+}
+
+// Wrap returns a copy of r whose repositories pass through chaos injection.
+func (c *Chaos) Wrap(r *Repository) *Repository {
+	return &Repository{
+		StateRepo:   &chaosStateRepo{StateRepo: r.StateRepo, chaos: c},
+		EventRepo:   &chaosEventRepo{EventRepo: r.EventRepo, chaos: c},
+		Events:      &chaosEventStreamRepo{EventStreamRepo: r.Events, chaos: c},
+		Chain:       &chaosChainRepo{EventChainRepo: r.Chain, chaos: c},
+		Comments:    &chaosCommentRepo{EventCommentRepo: r.Comments, chaos: c},
+		Retention:   &chaosRetentionRepo{EventRetentionRepo: r.Retention, chaos: c},
+		Deletions:   &chaosDeletionRepo{EventDeletionRepo: r.Deletions, chaos: c},
+		RunRepo:     &chaosRunRepo{RunRepo: r.RunRepo, chaos: c},
+		Telemetry:   &chaosTelemetryRepo{TelemetryRepo: r.Telemetry, chaos: c},
+		Samples:     &chaosSampleRepo{SampleRepo: r.Samples, chaos: c},
+		Rollups:     r.Rollups, // summaries are rebuilt from the samples on the next run
+		Settings:    &chaosSettingsRepo{SimSettingsRepo: r.Settings, chaos: c},
+		Health:      &chaosHealthRepo{HealthRepo: r.Health, chaos: c},
+		Alerts:      &chaosAlertRepo{AlertRepo: r.Alerts, chaos: c},
+		Incidents:   &chaosIncidentRepo{IncidentRepo: r.Incidents, chaos: c},
+		Sequences:   &chaosSequenceRepo{SequenceRepo: r.Sequences, chaos: c},
+		Maintenance: &chaosMaintenanceRepo{MaintenanceRepo: r.Maintenance, chaos: c},
+		Webhooks:    &chaosWebhookRepo{WebhookRepo: r.Webhooks, chaos: c},
+		Import:      &chaosImportRepo{ImportRepo: r.Import, chaos: c},
+		Status:      r.Status, // probes report on the real database
+		Backup:      r.Backup, // a backup copies the real database
+		Uptime:      r.Uptime, // and so does the record of their outcomes
+		Auth:        &chaosAuthRepo{Authorization: r.Auth, chaos: c},
+		Install:     r.Install, // setup runs once, before anyone can arm chaos
+		UnitOfWork:  &chaosUnitOfWork{UnitOfWork: r.UnitOfWork, chaos: c},
+		Chaos:       c,
+	}
+}
+
+// chaosUnitOfWork injects faults into the writes of a unit of work, so a
+// failure between them can be seen to roll both back.
+type chaosUnitOfWork struct {
+	UnitOfWork
+	chaos *Chaos
+}
+
+func (u *chaosUnitOfWork) Do(ctx context.Context, fn func(tx Tx) error) error {
+	return u.UnitOfWork.Do(ctx, func(tx Tx) error {
+		return fn(Tx{
+			StateRepo: &chaosStateRepo{StateRepo: tx.StateRepo, chaos: u.chaos},
+			EventRepo: &chaosEventRepo{EventRepo: tx.EventRepo, chaos: u.chaos},
+		})
+	})
+}
+
+type chaosStateRepo struct {
+	StateRepo
+	chaos *Chaos
+}
+
+func (r *chaosStateRepo) Save(ctx context.Context, s models.FurnaceState) error {
+	if err := r.chaos.inject(ctx, "state save"); err != nil {
+		return err
+	}
+	return r.StateRepo.Save(ctx, s)
+}
+
+func (r *chaosStateRepo) Load(ctx context.Context) (models.FurnaceState, error) {
+	if err := r.chaos.inject(ctx, "state load"); err != nil {
+		return models.FurnaceState{}, err
+	}
+	return r.StateRepo.Load(ctx)
+}
+
+type chaosEventRepo struct {
+	EventRepo
+	chaos *Chaos
+}
+
+func (r *chaosEventRepo) Append(ctx context.Context, e models.FurnaceEvent) error {
+	if err := r.chaos.inject(ctx, "event append"); err != nil {
+		return err
+	}
+	return r.EventRepo.Append(ctx, e)
+}
+
+func (r *chaosEventRepo) AppendBatch(ctx context.Context, events []models.FurnaceEvent) error {
+	if err := r.chaos.inject(ctx, "event append batch"); err != nil {
+		return err
+	}
+	return r.EventRepo.AppendBatch(ctx, events)
+}
+
+func (r *chaosEventRepo) List(ctx context.Context, from, to time.Time, typ string) ([]models.FurnaceEvent, error) {
+	if err := r.chaos.inject(ctx, "event list"); err != nil {
+		return nil, err
+	}
+	return r.EventRepo.List(ctx, from, to, typ)
+}
+
+func (r *chaosEventRepo) Query(ctx context.Context, q EventQuery) ([]models.FurnaceEvent, error) {
+	if err := r.chaos.inject(ctx, "event query"); err != nil {
+		return nil, err
+	}
+	return r.EventRepo.Query(ctx, q)
+}
+
+type chaosRunRepo struct {
+	RunRepo
+	chaos *Chaos
+}
+
+func (r *chaosRunRepo) Save(ctx context.Context, run models.Run) error {
+	if err := r.chaos.inject(ctx, "run save"); err != nil {
+		return err
+	}
+	return r.RunRepo.Save(ctx, run)
+}
+
+func (r *chaosRunRepo) Get(ctx context.Context, runID string) (models.Run, error) {
+	if err := r.chaos.inject(ctx, "run get"); err != nil {
+		return models.Run{}, err
+	}
+	return r.RunRepo.Get(ctx, runID)
+}
+
+type chaosTelemetryRepo struct {
+	TelemetryRepo
+	chaos *Chaos
+}
+
+func (r *chaosTelemetryRepo) Append(ctx context.Context, samples ...models.TelemetrySample) error {
+	if err := r.chaos.inject(ctx, "telemetry append"); err != nil {
+		return err
+	}
+	return r.TelemetryRepo.Append(ctx, samples...)
+}
+
+func (r *chaosTelemetryRepo) Query(ctx context.Context, q TelemetryQuery) ([]models.TelemetrySample, error) {
+	if err := r.chaos.inject(ctx, "telemetry query"); err != nil {
+		return nil, err
+	}
+	return r.TelemetryRepo.Query(ctx, q)
+}
+
+type chaosSettingsRepo struct {
+	SimSettingsRepo
+	chaos *Chaos
+}
+
+func (r *chaosSettingsRepo) Save(ctx context.Context, s models.SimSettings) error {
+	if err := r.chaos.inject(ctx, "settings save"); err != nil {
+		return err
+	}
+	return r.SimSettingsRepo.Save(ctx, s)
+}
+
+func (r *chaosSettingsRepo) Load(ctx context.Context) (models.SimSettings, error) {
+	if err := r.chaos.inject(ctx, "settings load"); err != nil {
+		return models.SimSettings{}, err
+	}
+	return r.SimSettingsRepo.Load(ctx)
+}
+
+type chaosEventStreamRepo struct {
+	EventStreamRepo
+	chaos *Chaos
+}
+
+func (r *chaosEventStreamRepo) Each(ctx context.Context, q EventQuery, fn func(models.FurnaceEvent) error) error {
+	if err := r.chaos.inject(ctx, "event stream"); err != nil {
+		return err
+	}
+	return r.EventStreamRepo.Each(ctx, q, fn)
+}
+
+func (r *chaosEventStreamRepo) Page(ctx context.Context, q EventQuery, p EventPageQuery) ([]models.FurnaceEvent, *EventKey, error) {
+	if err := r.chaos.inject(ctx, "event stream"); err != nil {
+		return nil, nil, err
+	}
+	return r.EventStreamRepo.Page(ctx, q, p)
+}
+
+func (r *chaosEventStreamRepo) Tail(ctx context.Context, q EventQuery, t EventTailQuery) ([]models.FurnaceEvent, string, error) {
+	if err := r.chaos.inject(ctx, "event stream"); err != nil {
+		return nil, "", err
+	}
+	return r.EventStreamRepo.Tail(ctx, q, t)
+}
+
+type chaosChainRepo struct {
+	EventChainRepo
+	chaos *Chaos
+}
+
+func (r *chaosChainRepo) VerifyChain(ctx context.Context) (models.ChainReport, error) {
+	if err := r.chaos.inject(ctx, "event chain verify"); err != nil {
+		return models.ChainReport{}, err
+	}
+	return r.EventChainRepo.VerifyChain(ctx)
+}
+
+type chaosCommentRepo struct {
+	EventCommentRepo
+	chaos *Chaos
+}
+
+func (r *chaosCommentRepo) AddComment(ctx context.Context, c models.EventComment) (int64, error) {
+	if err := r.chaos.inject(ctx, "event comment add"); err != nil {
+		return 0, err
+	}
+	return r.EventCommentRepo.AddComment(ctx, c)
+}
+
+func (r *chaosCommentRepo) CommentsFor(ctx context.Context, eventIDs []string) (map[string][]models.EventComment, error) {
+	if err := r.chaos.inject(ctx, "event comments list"); err != nil {
+		return nil, err
+	}
+	return r.EventCommentRepo.CommentsFor(ctx, eventIDs)
+}
+
+type chaosRetentionRepo struct {
+	EventRetentionRepo
+	chaos *Chaos
+}
+
+func (r *chaosRetentionRepo) Purge(ctx context.Context, p EventPurge, archive EventArchive) (models.PurgeReport, error) {
+	if err := r.chaos.inject(ctx, "event purge"); err != nil {
+		return models.PurgeReport{}, err
+	}
+	return r.EventRetentionRepo.Purge(ctx, p, archive)
+}
+
+type chaosDeletionRepo struct {
+	EventDeletionRepo
+	chaos *Chaos
+}
+
+func (r *chaosDeletionRepo) SoftDelete(ctx context.Context, eventID string, d EventDeletion) error {
+	if err := r.chaos.inject(ctx, "event soft delete"); err != nil {
+		return err
+	}
+	return r.EventDeletionRepo.SoftDelete(ctx, eventID, d)
+}
+
+type chaosSampleRepo struct {
+	SampleRepo
+	chaos *Chaos
+}
+
+func (r *chaosSampleRepo) Append(ctx context.Context, s models.FurnaceSample) error {
+	if err := r.chaos.inject(ctx, "sample append"); err != nil {
+		return err
+	}
+	return r.SampleRepo.Append(ctx, s)
+}
+
+func (r *chaosSampleRepo) Buckets(ctx context.Context, q HistoryQuery) ([]models.HistoryBucket, error) {
+	if err := r.chaos.inject(ctx, "sample buckets"); err != nil {
+		return nil, err
+	}
+	return r.SampleRepo.Buckets(ctx, q)
+}
+
+type chaosHealthRepo struct {
+	HealthRepo
+	chaos *Chaos
+}
+
+func (r *chaosHealthRepo) Save(ctx context.Context, h models.FurnaceHealth) error {
+	if err := r.chaos.inject(ctx, "health save"); err != nil {
+		return err
+	}
+	return r.HealthRepo.Save(ctx, h)
+}
+
+func (r *chaosHealthRepo) Load(ctx context.Context) (models.FurnaceHealth, error) {
+	if err := r.chaos.inject(ctx, "health load"); err != nil {
+		return models.FurnaceHealth{}, err
+	}
+	return r.HealthRepo.Load(ctx)
+}
+
+type chaosAlertRepo struct {
+	AlertRepo
+	chaos *Chaos
+}
+
+func (r *chaosAlertRepo) CreateRule(ctx context.Context, rule models.AlertRule) (int, error) {
+	if err := r.chaos.inject(ctx, "alert rule create"); err != nil {
+		return 0, err
+	}
+	return r.AlertRepo.CreateRule(ctx, rule)
+}
+
+func (r *chaosAlertRepo) UpdateRule(ctx context.Context, rule models.AlertRule) (bool, error) {
+	if err := r.chaos.inject(ctx, "alert rule update"); err != nil {
+		return false, err
+	}
+	return r.AlertRepo.UpdateRule(ctx, rule)
+}
+
+func (r *chaosAlertRepo) DeleteRule(ctx context.Context, id int) (bool, error) {
+	if err := r.chaos.inject(ctx, "alert rule delete"); err != nil {
+		return false, err
+	}
+	return r.AlertRepo.DeleteRule(ctx, id)
+}
+
+func (r *chaosAlertRepo) GetRule(ctx context.Context, id int) (models.AlertRule, error) {
+	if err := r.chaos.inject(ctx, "alert rule get"); err != nil {
+		return models.AlertRule{}, err
+	}
+	return r.AlertRepo.GetRule(ctx, id)
+}
+
+func (r *chaosAlertRepo) ListRules(ctx context.Context) ([]models.AlertRule, error) {
+	if err := r.chaos.inject(ctx, "alert rule list"); err != nil {
+		return nil, err
+	}
+	return r.AlertRepo.ListRules(ctx)
+}
+
+func (r *chaosAlertRepo) AppendAlert(ctx context.Context, a models.Alert) (int64, error) {
+	if err := r.chaos.inject(ctx, "alert append"); err != nil {
+		return 0, err
+	}
+	return r.AlertRepo.AppendAlert(ctx, a)
+}
+
+func (r *chaosAlertRepo) ListAlerts(ctx context.Context, q AlertQuery) ([]models.Alert, error) {
+	if err := r.chaos.inject(ctx, "alert list"); err != nil {
+		return nil, err
+	}
+	return r.AlertRepo.ListAlerts(ctx, q)
+}
+
+type chaosIncidentRepo struct {
+	IncidentRepo
+	chaos *Chaos
+}
+
+func (r *chaosIncidentRepo) Create(ctx context.Context, inc models.Incident) (int64, error) {
+	if err := r.chaos.inject(ctx, "incident create"); err != nil {
+		return 0, err
+	}
+	return r.IncidentRepo.Create(ctx, inc)
+}
+
+func (r *chaosIncidentRepo) Update(ctx context.Context, inc models.Incident) (bool, error) {
+	if err := r.chaos.inject(ctx, "incident update"); err != nil {
+		return false, err
+	}
+	return r.IncidentRepo.Update(ctx, inc)
+}
+
+func (r *chaosIncidentRepo) Acknowledge(ctx context.Context, id int64, userID int, at time.Time) (bool, error) {
+	if err := r.chaos.inject(ctx, "incident ack"); err != nil {
+		return false, err
+	}
+	return r.IncidentRepo.Acknowledge(ctx, id, userID, at)
+}
+
+func (r *chaosIncidentRepo) Get(ctx context.Context, id int64) (models.Incident, error) {
+	if err := r.chaos.inject(ctx, "incident get"); err != nil {
+		return models.Incident{}, err
+	}
+	return r.IncidentRepo.Get(ctx, id)
+}
+
+func (r *chaosIncidentRepo) Current(ctx context.Context) (models.Incident, error) {
+	if err := r.chaos.inject(ctx, "incident current"); err != nil {
+		return models.Incident{}, err
+	}
+	return r.IncidentRepo.Current(ctx)
+}
+
+func (r *chaosIncidentRepo) List(ctx context.Context, q IncidentQuery) ([]models.Incident, error) {
+	if err := r.chaos.inject(ctx, "incident list"); err != nil {
+		return nil, err
+	}
+	return r.IncidentRepo.List(ctx, q)
+}
+
+type chaosSequenceRepo struct {
+	SequenceRepo
+	chaos *Chaos
+}
+
+func (r *chaosSequenceRepo) Save(ctx context.Context, seq SequenceRecord) error {
+	if err := r.chaos.inject(ctx, "sequence save"); err != nil {
+		return err
+	}
+	return r.SequenceRepo.Save(ctx, seq)
+}
+
+func (r *chaosSequenceRepo) Get(ctx context.Context, id string) (SequenceRecord, error) {
+	if err := r.chaos.inject(ctx, "sequence get"); err != nil {
+		return SequenceRecord{}, err
+	}
+	return r.SequenceRepo.Get(ctx, id)
+}
+
+func (r *chaosSequenceRepo) Running(ctx context.Context) (SequenceRecord, error) {
+	if err := r.chaos.inject(ctx, "sequence running"); err != nil {
+		return SequenceRecord{}, err
+	}
+	return r.SequenceRepo.Running(ctx)
+}
+
+type chaosMaintenanceRepo struct {
+	MaintenanceRepo
+	chaos *Chaos
+}
+
+func (r *chaosMaintenanceRepo) CreateTask(ctx context.Context, t models.MaintenanceTask) (int, error) {
+	if err := r.chaos.inject(ctx, "maintenance task create"); err != nil {
+		return 0, err
+	}
+	return r.MaintenanceRepo.CreateTask(ctx, t)
+}
+
+func (r *chaosMaintenanceRepo) UpdateTask(ctx context.Context, t models.MaintenanceTask) (bool, error) {
+	if err := r.chaos.inject(ctx, "maintenance task update"); err != nil {
+		return false, err
+	}
+	return r.MaintenanceRepo.UpdateTask(ctx, t)
+}
+
+func (r *chaosMaintenanceRepo) DeleteTask(ctx context.Context, id int) (bool, error) {
+	if err := r.chaos.inject(ctx, "maintenance task delete"); err != nil {
+		return false, err
+	}
+	return r.MaintenanceRepo.DeleteTask(ctx, id)
+}
+
+func (r *chaosMaintenanceRepo) MarkOverdue(ctx context.Context, id int) (bool, error) {
+	if err := r.chaos.inject(ctx, "maintenance task overdue"); err != nil {
+		return false, err
+	}
+	return r.MaintenanceRepo.MarkOverdue(ctx, id)
+}
+
+func (r *chaosMaintenanceRepo) GetTask(ctx context.Context, id int) (models.MaintenanceTask, error) {
+	if err := r.chaos.inject(ctx, "maintenance task get"); err != nil {
+		return models.MaintenanceTask{}, err
+	}
+	return r.MaintenanceRepo.GetTask(ctx, id)
+}
+
+func (r *chaosMaintenanceRepo) ListTasks(ctx context.Context) ([]models.MaintenanceTask, error) {
+	if err := r.chaos.inject(ctx, "maintenance task list"); err != nil {
+		return nil, err
+	}
+	return r.MaintenanceRepo.ListTasks(ctx)
+}
+
+func (r *chaosMaintenanceRepo) Complete(ctx context.Context, rec models.MaintenanceRecord) (int64, error) {
+	if err := r.chaos.inject(ctx, "maintenance complete"); err != nil {
+		return 0, err
+	}
+	return r.MaintenanceRepo.Complete(ctx, rec)
+}
+
+func (r *chaosMaintenanceRepo) ListRecords(ctx context.Context, q MaintenanceRecordQuery) ([]models.MaintenanceRecord, error) {
+	if err := r.chaos.inject(ctx, "maintenance record list"); err != nil {
+		return nil, err
+	}
+	return r.MaintenanceRepo.ListRecords(ctx, q)
+}
+
+type chaosWebhookRepo struct {
+	WebhookRepo
+	chaos *Chaos
+}
+
+func (r *chaosWebhookRepo) CreateWebhook(ctx context.Context, w models.Webhook) (int, error) {
+	if err := r.chaos.inject(ctx, "webhook create"); err != nil {
+		return 0, err
+	}
+	return r.WebhookRepo.CreateWebhook(ctx, w)
+}
+
+func (r *chaosWebhookRepo) UpdateWebhook(ctx context.Context, w models.Webhook) (bool, error) {
+	if err := r.chaos.inject(ctx, "webhook update"); err != nil {
+		return false, err
+	}
+	return r.WebhookRepo.UpdateWebhook(ctx, w)
+}
+
+func (r *chaosWebhookRepo) DeleteWebhook(ctx context.Context, id int) (bool, error) {
+	if err := r.chaos.inject(ctx, "webhook delete"); err != nil {
+		return false, err
+	}
+	return r.WebhookRepo.DeleteWebhook(ctx, id)
+}
+
+func (r *chaosWebhookRepo) GetWebhook(ctx context.Context, id int) (models.Webhook, error) {
+	if err := r.chaos.inject(ctx, "webhook get"); err != nil {
+		return models.Webhook{}, err
+	}
+	return r.WebhookRepo.GetWebhook(ctx, id)
+}
+
+func (r *chaosWebhookRepo) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	if err := r.chaos.inject(ctx, "webhook list"); err != nil {
+		return nil, err
+	}
+	return r.WebhookRepo.ListWebhooks(ctx)
+}
+
+func (r *chaosWebhookRepo) NewEvents(ctx context.Context, limit int) ([]models.FurnaceEvent, int64, error) {
+	if err := r.chaos.inject(ctx, "webhook new events"); err != nil {
+		return nil, 0, err
+	}
+	return r.WebhookRepo.NewEvents(ctx, limit)
+}
+
+func (r *chaosWebhookRepo) Enqueue(ctx context.Context, deliveries []models.WebhookDelivery, through int64) error {
+	if err := r.chaos.inject(ctx, "webhook enqueue"); err != nil {
+		return err
+	}
+	return r.WebhookRepo.Enqueue(ctx, deliveries, through)
+}
+
+func (r *chaosWebhookRepo) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	if err := r.chaos.inject(ctx, "webhook due deliveries"); err != nil {
+		return nil, err
+	}
+	return r.WebhookRepo.DueDeliveries(ctx, now, limit)
+}
+
+func (r *chaosWebhookRepo) UpdateDelivery(ctx context.Context, d models.WebhookDelivery) error {
+	if err := r.chaos.inject(ctx, "webhook delivery update"); err != nil {
+		return err
+	}
+	return r.WebhookRepo.UpdateDelivery(ctx, d)
+}
+
+func (r *chaosWebhookRepo) GetDelivery(ctx context.Context, id int64) (models.WebhookDelivery, error) {
+	if err := r.chaos.inject(ctx, "webhook delivery get"); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return r.WebhookRepo.GetDelivery(ctx, id)
+}
+
+func (r *chaosWebhookRepo) ListDeliveries(ctx context.Context, q WebhookDeliveryQuery) ([]models.WebhookDelivery, error) {
+	if err := r.chaos.inject(ctx, "webhook delivery list"); err != nil {
+		return nil, err
+	}
+	return r.WebhookRepo.ListDeliveries(ctx, q)
+}
+
+type chaosImportRepo struct {
+	ImportRepo
+	chaos *Chaos
+}
+
+func (r *chaosImportRepo) ImportEvents(ctx context.Context, events []models.FurnaceEvent) (int, error) {
+	if err := r.chaos.inject(ctx, "import events"); err != nil {
+		return 0, err
+	}
+	return r.ImportRepo.ImportEvents(ctx, events)
+}
+
+func (r *chaosImportRepo) ImportTelemetry(ctx context.Context, samples []models.TelemetrySample) (int, error) {
+	if err := r.chaos.inject(ctx, "import telemetry"); err != nil {
+		return 0, err
+	}
+	return r.ImportRepo.ImportTelemetry(ctx, samples)
+}
+
+type chaosAuthRepo struct {
+	Authorization
+	chaos *Chaos
+}
+
+func (r *chaosAuthRepo) Create(ctx context.Context, username, hash string) (int, error) {
+	if err := r.chaos.inject(ctx, "user create"); err != nil {
+		return 0, err
+	}
+	return r.Authorization.Create(ctx, username, hash)
+}
+
+func (r *chaosAuthRepo) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	if err := r.chaos.inject(ctx, "user get"); err != nil {
+		return nil, err
+	}
+	return r.Authorization.GetByUsername(ctx, username)
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+type chaosStateStub struct{ loads int }
+
+func (s *chaosStateStub) Save(ctx context.Context, st models.FurnaceState) error { return nil }
+func (s *chaosStateStub) Load(ctx context.Context) (models.FurnaceState, error) {
+	s.loads++
+	return models.FurnaceState{ID: 1}, nil
+}
+
+func TestChaos_DisabledPassesThrough(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewChaos(ChaosSettings{ErrorProbability: 1}, 1)
+	if err != nil {
+		t.Fatalf("NewChaos: %v", err)
+	}
+	stub := &chaosStateStub{}
+	repos := c.Wrap(&Repository{StateRepo: stub})
+
+	if _, err := repos.StateRepo.Load(context.Background()); err != nil {
+		t.Fatalf("expected pass-through while disabled, got %v", err)
+	}
+	if stub.loads != 1 {
+		t.Fatalf("expected underlying Load to be called once, got %d", stub.loads)
+	}
+	if repos.Chaos != c {
+		t.Fatalf("expected wrapped repository to expose its chaos controller")
+	}
+}
+
+func TestChaos_InjectsErrors(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewChaos(ChaosSettings{Enabled: true, ErrorProbability: 1}, 1)
+	if err != nil {
+		t.Fatalf("NewChaos: %v", err)
+	}
+	stub := &chaosStateStub{}
+	repos := c.Wrap(&Repository{StateRepo: stub})
+
+	_, err = repos.StateRepo.Load(context.Background())
+	if !errors.Is(err, ErrChaosInjected) {
+		t.Fatalf("expected ErrChaosInjected, got %v", err)
+	}
+	if stub.loads != 0 {
+		t.Fatalf("underlying Load must not run on injected failure")
+	}
+}
+
+func TestChaos_LatencyRespectsContext(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewChaos(ChaosSettings{Enabled: true, Latency: time.Minute, LatencyProbability: 1}, 1)
+	if err != nil {
+		t.Fatalf("NewChaos: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = c.inject(ctx, "test")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("injected latency ignored context cancellation")
+	}
+}
+
+func TestChaos_UpdateValidates(t *testing.T) {
+	t.Parallel()
+
+	c, _ := NewChaos(ChaosSettings{}, 1)
+	for _, s := range []ChaosSettings{
+		{Latency: -time.Second},
+		{LatencyProbability: 1.5},
+		{ErrorProbability: -0.1},
+	} {
+		if err := c.Update(s); !errors.Is(err, ErrInvalidChaosSettings) {
+			t.Fatalf("Update(%+v): expected ErrInvalidChaosSettings, got %v", s, err)
+		}
+	}
+
+	want := ChaosSettings{Enabled: true, Latency: time.Second, LatencyProbability: 0.5, ErrorProbability: 0.1}
+	if err := c.Update(want); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got := c.Settings(); got != want {
+		t.Fatalf("Settings() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Columns holding credentials are encrypted with AES-256-GCM when a key is
+// configured, so a copy of the database file does not give away the JWT
+// signing key, webhook secrets or password hashes. Encrypted values are
+// stored as encryptedPrefix followed by the base64 nonce and ciphertext;
+// values without the prefix are plaintext from before encryption was
+// enabled and are read as they are.
+const encryptedPrefix = "enc:v1:"
+
+var (
+	// ErrEncryptionKeyMissing is returned when reading an encrypted value
+	// without a key.
+	ErrEncryptionKeyMissing = errors.New("value is encrypted but no db.encryption_key is configured")
+	// ErrDecrypt is returned when a value does not decrypt with the key,
+	// usually because the key changed.
+	ErrDecrypt = errors.New("value does not decrypt with the configured db.encryption_key")
+)
+
+// ColumnCipher encrypts sensitive column values. A nil *ColumnCipher
+// stores them in plaintext.
+type ColumnCipher struct {
+	aead cipher.AEAD
+}
+
+// NewColumnCipher returns a cipher for a 32-byte AES-256 key.
+func NewColumnCipher(key []byte) (*ColumnCipher, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &ColumnCipher{aead: aead}, nil
+}
+
+// seal encrypts s for storage. Empty values stay empty, so "no secret"
+// can still be told apart in SQL.
+func (c *ColumnCipher) seal(s string) (string, error) {
+	if c == nil || s == "" {
+		return s, nil
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(s), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts a stored value; plaintext values are returned unchanged.
+func (c *ColumnCipher) open(s string) (string, error) {
+	enc, ok := strings.CutPrefix(s, encryptedPrefix)
+	if !ok {
+		return s, nil
+	}
+	if c == nil {
+		return "", ErrEncryptionKeyMissing
+	}
+	sealed, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", ErrDecrypt
+	}
+	nonce, ct := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, ct, nil)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plain), nil
+}
+
+// encryptedColumns are the columns ColumnCipher protects, by table; each
+// table has an integer id.
+var encryptedColumns = []struct{ table, column string }{
+	{"users", "password_hash"},
+	{"webhooks", "secret"},
+	{"install_settings", "data"}, // holds the JWT signing key
+}
+
+// EncryptExisting encrypts the values of the protected columns that are
+// still stored in plaintext, in one transaction, and returns how many it
+// encrypted. Run it at startup after enabling encryption.
+func (c *ColumnCipher) EncryptExisting(ctx context.Context, db *sql.DB) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	n := 0
+	for _, col := range encryptedColumns {
+		k, err := c.encryptColumn(ctx, tx, col.table, col.column)
+		if err != nil {
+			return 0, fmt.Errorf("encrypt %s.%s: %w", col.table, col.column, err)
+		}
+		n += k
+	}
+	return n, tx.Commit()
+}
+
+func (c *ColumnCipher) encryptColumn(ctx context.Context, tx *sql.Tx, table, column string) (int, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT id, %s FROM %s WHERE %s != '' AND %s NOT LIKE '%s%%'`,
+		column, table, column, column, encryptedPrefix))
+	if err != nil {
+		return 0, err
+	}
+	plain := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var s string
+		if err := rows.Scan(&id, &s); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		plain[id] = s
+	}
+	err = rows.Err()
+	_ = rows.Close()
+	if err != nil {
+		return 0, err
+	}
+	update := fmt.Sprintf(`UPDATE %s SET %s = ? WHERE id = ?`, table, column)
+	for id, s := range plain {
+		sealed, err := c.seal(s)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, update, sealed, id); err != nil {
+			return 0, err
+		}
+	}
+	return len(plain), nil
+}
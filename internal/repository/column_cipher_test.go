@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository/db"
+)
+
+func testCipher(t *testing.T, b byte) *ColumnCipher {
+	t.Helper()
+	c, err := NewColumnCipher(bytes.Repeat([]byte{b}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestColumnCipher_SealAndOpen(t *testing.T) {
+	c := testCipher(t, 1)
+	sealed, err := c.seal("hook-secret")
+	if err != nil || !strings.HasPrefix(sealed, encryptedPrefix) || strings.Contains(sealed, "hook-secret") {
+		t.Fatalf("seal = %q, %v", sealed, err)
+	}
+	if again, _ := c.seal("hook-secret"); again == sealed {
+		t.Fatal("expected a fresh nonce per value")
+	}
+	if plain, err := c.open(sealed); err != nil || plain != "hook-secret" {
+		t.Fatalf("open = %q, %v", plain, err)
+	}
+	if plain, err := c.open("legacy"); err != nil || plain != "legacy" {
+		t.Fatalf("open plaintext = %q, %v", plain, err)
+	}
+	if empty, _ := c.seal(""); empty != "" {
+		t.Fatalf("seal(\"\") = %q, want it left empty", empty)
+	}
+
+	var none *ColumnCipher
+	if _, err := none.open(sealed); !errors.Is(err, ErrEncryptionKeyMissing) {
+		t.Fatalf("open without a key: %v", err)
+	}
+	if _, err := testCipher(t, 2).open(sealed); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("open with another key: %v", err)
+	}
+	if _, err := NewColumnCipher([]byte("short")); err == nil {
+		t.Fatal("expected a short key to be refused")
+	}
+}
+
+func TestColumnCipher_EncryptsExistingCredentials(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.InitDB(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// written before encryption was enabled
+	plain := NewRepository(conn)
+	if _, err := plain.Auth.Create(ctx, "ann", "bcrypt-hash"); err != nil {
+		t.Fatal(err)
+	}
+	id, err := plain.Webhooks.CreateWebhook(ctx, models.Webhook{URL: "http://hook", EventTypes: []string{"ERROR"}, Secret: "hook-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plain.Install.Save(ctx, models.Installation{SigningKey: "jwt-key"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := testCipher(t, 1)
+	if n, err := c.EncryptExisting(ctx, conn); err != nil || n != 3 {
+		t.Fatalf("EncryptExisting = %d, %v; want 3", n, err)
+	}
+	if n, err := c.EncryptExisting(ctx, conn); err != nil || n != 0 {
+		t.Fatalf("second EncryptExisting = %d, %v; want nothing left", n, err)
+	}
+	for _, col := range encryptedColumns {
+		var stored string
+		if err := conn.QueryRow(`SELECT ` + col.column + ` FROM ` + col.table).Scan(&stored); err != nil || !strings.HasPrefix(stored, encryptedPrefix) {
+			t.Fatalf("%s.%s = %q, %v; want it encrypted", col.table, col.column, stored, err)
+		}
+	}
+
+	repos := NewRepositoryWithConfig(conn, Config{Cipher: c})
+	if u, err := repos.Auth.GetByUsername(ctx, "ann"); err != nil || u.PasswordHash != "bcrypt-hash" {
+		t.Fatalf("user = %+v, %v", u, err)
+	}
+	if w, err := repos.Webhooks.GetWebhook(ctx, id); err != nil || w.Secret != "hook-secret" {
+		t.Fatalf("webhook = %+v, %v", w, err)
+	}
+	// an update without a secret keeps the stored one
+	if _, err := repos.Webhooks.UpdateWebhook(ctx, models.Webhook{ID: id, URL: "http://hook2", EventTypes: []string{"ERROR"}}); err != nil {
+		t.Fatal(err)
+	}
+	if w, err := repos.Webhooks.GetWebhook(ctx, id); err != nil || w.Secret != "hook-secret" || w.URL != "http://hook2" {
+		t.Fatalf("webhook after update = %+v, %v", w, err)
+	}
+	if inst, err := repos.Install.Load(ctx); err != nil || inst.SigningKey != "jwt-key" {
+		t.Fatalf("installation = %+v, %v", inst, err)
+	}
+
+	if _, err := plain.Auth.GetByUsername(ctx, "ann"); !errors.Is(err, ErrEncryptionKeyMissing) {
+		t.Fatalf("reading without the key: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrSchemaOutdated is returned by CheckSchema when migrations the code
+// relies on have not been applied.
+var ErrSchemaOutdated = errors.New("database schema is not up to date")
+
+// CheckSchema verifies, without changing anything, that the database is
+// at the latest migration of this build.
+func CheckSchema(ctx context.Context, db *sql.DB) error {
+	var name string
+	err := db.QueryRowContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'").Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: no migrations applied, want version %d", ErrSchemaOutdated, LatestVersion())
+	}
+	if err != nil {
+		return fmt.Errorf("inspect schema_migrations: %w", err)
+	}
+	var v int
+	if err := db.QueryRowContext(ctx, currentVersionSQL).Scan(&v); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	switch {
+	case v < LatestVersion():
+		return fmt.Errorf("%w: at version %d, want %d", ErrSchemaOutdated, v, LatestVersion())
+	case v > LatestVersion():
+		return fmt.Errorf("%w: at version %d, this build knows up to %d", ErrSchemaTooNew, v, LatestVersion())
+	}
+	return nil
+}
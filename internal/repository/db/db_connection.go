@@ -1,15 +1,70 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
+	"github.com/XSAM/otelsql"
+	"go.opentelemetry.io/otel/attribute"
 	_ "modernc.org/sqlite"
 )
 
-// InitDB opens/creates a SQLite DB file and ensures tables exist.
+// InitDB opens/creates a SQLite DB file, applies pending migrations and
+// refreshes the query planner's statistics.
 func InitDB(path string) (*sql.DB, error) {
-	db, err := sql.Open(sqliteDriverName, path)
+	db, err := OpenDB(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := Migrate(context.Background(), db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if err := Analyze(context.Background(), db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// analysisLimit caps the rows ANALYZE reads per index, so start-up stays
+// fast on a large event log; the estimate is enough to pick an index.
+const analysisLimit = 1000
+
+// Analyze gathers the statistics the query planner uses to choose between
+// indexes, for tables that have none yet or changed much since they were
+// last gathered (PRAGMA optimize with the flag for use right after opening).
+func Analyze(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("PRAGMA analysis_limit = %d", analysisLimit)); err != nil {
+		return fmt.Errorf("set PRAGMA analysis_limit: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, "PRAGMA optimize = 0x10002"); err != nil {
+		return fmt.Errorf("analyze: %w", err)
+	}
+	return nil
+}
+
+// connPragmas are set on every connection the pool opens, including the
+// ones that replace a connection the driver dropped, rather than once on
+// whichever connection happened to be open at start-up. BEGIN IMMEDIATE
+// makes a transaction wait out busy_timeout for the write lock when it
+// starts, instead of failing when a read inside it upgrades to a write.
+const connPragmas = "_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_txlock=immediate"
+
+// OpenDB opens/creates a SQLite DB file without touching its schema.
+func OpenDB(path string) (*sql.DB, error) {
+	dsn := path + "?" + connPragmas
+	if strings.Contains(path, "?") {
+		dsn = path + "&" + connPragmas
+	}
+	// Every statement gets a span when tracing is enabled; with the default
+	// no-op tracer provider the wrapper only adds a function call.
+	db, err := otelsql.Open(sqliteDriverName, dsn,
+		otelsql.WithAttributes(attribute.String("db.system", "sqlite")),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite at %q: %w", path, err)
 	}
@@ -18,24 +73,11 @@ func InitDB(path string) (*sql.DB, error) {
 	db.SetMaxOpenConns(1) // SQLite is not great with many writers
 	db.SetMaxIdleConns(1)
 
-	// Pragmas to improve reliability
+	// WAL is stored in the file, so setting it once is enough
 	if _, err := db.Exec("PRAGMA journal_mode = WAL;"); err != nil {
 		_ = db.Close()
 		return nil, fmt.Errorf("set PRAGMA journal_mode=WAL: %w", err)
 	}
-	if _, err := db.Exec("PRAGMA foreign_keys = ON;"); err != nil {
-		_ = db.Close()
-		return nil, fmt.Errorf("set PRAGMA foreign_keys=ON: %w", err)
-	}
-	if _, err := db.Exec("PRAGMA busy_timeout = 5000;"); err != nil {
-		_ = db.Close()
-		return nil, fmt.Errorf("set PRAGMA busy_timeout=5000: %w", err)
-	}
-
-	if err := ensureSchema(db); err != nil {
-		_ = db.Close()
-		return nil, err
-	}
 
 	// Fail fast if the DB cannot be reached
 	if err := db.Ping(); err != nil {
@@ -50,59 +92,80 @@ func InitDB(path string) (*sql.DB, error) {
 
 const sqliteDriverName = "sqlite"
 
-const schemaFurnaceState = `
-CREATE TABLE IF NOT EXISTS furnace_state (
-    id INTEGER PRIMARY KEY CHECK (id = 1),
-    mode TEXT NOT NULL,
-    temp_c REAL NOT NULL,
-    target_c REAL,
-    remaining_s INTEGER,
-    errors TEXT,
-    running BOOLEAN NOT NULL,
-    updated_at TIMESTAMP NOT NULL
-);
-`
-
-const schemaFurnaceEvents = `
-CREATE TABLE IF NOT EXISTS furnace_events (
-    id TEXT PRIMARY KEY,
-    occurred_at TIMESTAMP NOT NULL,
-    type TEXT NOT NULL,
-    message TEXT NOT NULL,
-    meta TEXT
-);
-`
-
-const schemaUsers = `
-CREATE TABLE IF NOT EXISTS users (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    username TEXT UNIQUE NOT NULL,
-    password_hash TEXT NOT NULL
-);
-`
-
-func ensureSchema(db *sql.DB) error {
-	tx, err := db.Begin()
+// columnDef describes a column added to a table before versioned
+// migrations, when the schema was only ever extended in place.
+type columnDef struct {
+	table string
+	name  string
+	ddl   string // column definition used by ALTER TABLE ... ADD COLUMN
+}
+
+// addedColumns lists columns that database files created before versioned
+// migrations may be missing. The baseline's CREATE TABLE IF NOT EXISTS does
+// not touch existing tables, so these are added when it is applied.
+var addedColumns = []columnDef{
+	{table: "furnace_state", name: "run_id", ddl: "run_id TEXT"},
+	{table: "furnace_state", name: "measured_c", ddl: "measured_c REAL"},
+	{table: "users", name: "role", ddl: "role TEXT NOT NULL DEFAULT 'operator'"},
+	{table: "furnace_state", name: "power_kw", ddl: "power_kw REAL"},
+	{table: "furnace_state", name: "energy_kwh", ddl: "energy_kwh REAL"},
+	{table: "runs", name: "energy_kwh", ddl: "energy_kwh REAL NOT NULL DEFAULT 0"},
+	{table: "furnace_state", name: "ambient_c", ddl: "ambient_c REAL"},
+	{table: "furnace_events", name: "prev_hash", ddl: "prev_hash TEXT"},
+	{table: "furnace_events", name: "hash", ddl: "hash TEXT"},
+	{table: "incidents", name: "overheat_s", ddl: "overheat_s REAL NOT NULL DEFAULT 0"},
+	{table: "incidents", name: "resolution", ddl: "resolution TEXT NOT NULL DEFAULT ''"},
+	{table: "furnace_health", name: "replaced_heating_s", ddl: "replaced_heating_s REAL NOT NULL DEFAULT 0"},
+	{table: "furnace_health", name: "replaced_cycles", ddl: "replaced_cycles INTEGER NOT NULL DEFAULT 0"},
+	{table: "furnace_state", name: "gas", ddl: "gas TEXT"},
+	{table: "furnace_state", name: "gas_setpoint_m3h", ddl: "gas_setpoint_m3h REAL"},
+	{table: "furnace_state", name: "gas_flow_m3h", ddl: "gas_flow_m3h REAL"},
+	{table: "furnace_state", name: "o2_ppm", ddl: "o2_ppm REAL"},
+	{table: "furnace_state", name: "max_o2_ppm", ddl: "max_o2_ppm REAL"},
+}
+
+// ensureColumn adds col to its table unless it already exists.
+func ensureColumn(tx *sql.Tx, col columnDef) error {
+	ok, err := hasColumn(context.Background(), tx, col.table, col.name)
+	if err != nil || ok {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", col.table, col.ddl)); err != nil {
+		return fmt.Errorf("add column %s.%s: %w", col.table, col.name, err)
+	}
+	return nil
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// hasColumn reports whether table has a column called name.
+func hasColumn(ctx context.Context, q querier, table, name string) (bool, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
 	if err != nil {
-		return fmt.Errorf("begin schema transaction: %w", err)
+		return false, fmt.Errorf("inspect table %s: %w", table, err)
 	}
-	defer func() {
-		// In case of panic, rollback to avoid leaving an open transaction
-		_ = tx.Rollback()
-	}()
-
-	for i, stmt := range []string{
-		schemaFurnaceState,
-		schemaFurnaceEvents,
-		schemaUsers,
-	} {
-		if _, err := tx.Exec(stmt); err != nil {
-			return fmt.Errorf("apply schema statement %d: %w", i+1, err)
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			colName   string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &colName, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, fmt.Errorf("scan table_info for %s: %w", table, err)
+		}
+		if colName == name {
+			return true, nil
 		}
 	}
-
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit schema transaction: %w", err)
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("read table_info for %s: %w", table, err)
 	}
-	return nil
+	return false, nil
 }
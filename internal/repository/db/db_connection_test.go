@@ -0,0 +1,81 @@
+package db
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInitDB_IndexesAndAnalyzesEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "furnace.db")
+	conn, err := InitDB(path)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	at := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 500; i++ {
+		typ := "TELEMETRY"
+		if i%50 == 0 {
+			typ = "ERROR"
+		}
+		if _, err := conn.Exec(`INSERT INTO furnace_events (id, occurred_at, type, message) VALUES (?, ?, ?, '')`,
+			i, at.Add(time.Duration(i)*time.Minute), typ); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	_ = conn.Close()
+
+	// the next start gathers statistics for the filled table
+	conn, err = InitDB(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	var stats int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM sqlite_stat1 WHERE tbl = 'furnace_events'`).Scan(&stats); err != nil || stats == 0 {
+		t.Fatalf("no statistics for furnace_events (%d rows, err %v)", stats, err)
+	}
+
+	for _, tc := range []struct{ query, index string }{
+		{`SELECT id FROM furnace_events WHERE occurred_at >= ? AND occurred_at <= ? ORDER BY occurred_at ASC`, "idx_furnace_events_occurred_type"},
+		{`SELECT id FROM furnace_events WHERE type = ? AND occurred_at >= ? ORDER BY occurred_at ASC`, "idx_furnace_events_type_occurred"},
+	} {
+		rows, err := conn.Query(`EXPLAIN QUERY PLAN `+tc.query, at, at.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("explain: %v", err)
+		}
+		var plan []string
+		for rows.Next() {
+			var id, parent, unused int
+			var detail string
+			if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+			plan = append(plan, detail)
+		}
+		_ = rows.Close()
+		if got := strings.Join(plan, "; "); !strings.Contains(got, tc.index) {
+			t.Errorf("%s\nplan: %s, want %s", tc.query, got, tc.index)
+		}
+	}
+}
+
+func TestOpenDB_ReconnectedConnectionsKeepPragmas(t *testing.T) {
+	conn, err := OpenDB(filepath.Join(t.TempDir(), "furnace.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	// every statement gets a fresh connection, as after the driver drops one
+	conn.SetMaxIdleConns(0)
+	for _, tc := range []struct {
+		pragma string
+		want   int
+	}{{"busy_timeout", 5000}, {"foreign_keys", 1}} {
+		var got int
+		if err := conn.QueryRow("PRAGMA " + tc.pragma).Scan(&got); err != nil || got != tc.want {
+			t.Errorf("PRAGMA %s = %d, %v; want %d", tc.pragma, got, err, tc.want)
+		}
+	}
+}
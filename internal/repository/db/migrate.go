@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// migrationFiles holds the schema changes as NNNN_name.up.sql and
+// NNNN_name.down.sql pairs. Versions are applied in ascending order and
+// never edited once released; change the schema by adding the next one.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// baselineVersion is the migration holding the schema from before
+// versioned migrations.
+const baselineVersion = 1
+
+const (
+	createMigrationsSQL = `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)
+	`
+	currentVersionSQL  = `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`
+	insertMigrationSQL = `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`
+	deleteMigrationSQL = `DELETE FROM schema_migrations WHERE version = ?`
+)
+
+var (
+	// ErrSchemaTooNew is returned when the database was migrated by a newer
+	// build than this one; running against it could lose data.
+	ErrSchemaTooNew = errors.New("database schema is newer than this build")
+	// ErrNoDownMigration is returned when rolling back a migration that
+	// cannot be undone.
+	ErrNoDownMigration = errors.New("migration cannot be rolled back")
+)
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string // empty if the migration cannot be rolled back
+}
+
+var migrationNameRe = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// migrations are the embedded migrations, oldest first.
+var migrations = mustLoadMigrations(migrationFiles)
+
+func mustLoadMigrations(fsys fs.FS) []Migration {
+	ms, err := loadMigrations(fsys)
+	if err != nil {
+		panic(err)
+	}
+	return ms
+}
+
+// loadMigrations reads the migrations in fsys. Versions must start at 1
+// and have no gaps, and each must have an up file.
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]*Migration)
+	for _, path := range files {
+		name := path[len("migrations/"):]
+		m := migrationNameRe.FindStringSubmatch(name)
+		if m == nil {
+			return nil, fmt.Errorf("migration %s: name must be NNNN_name.up.sql or NNNN_name.down.sql", name)
+		}
+		version, _ := strconv.Atoi(m[1])
+		b, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.up = string(b)
+		} else {
+			mig.down = string(b)
+		}
+	}
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	for i, m := range out {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration %d is missing", i+1)
+		}
+		if m.up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+	}
+	return out, nil
+}
+
+// Migrations returns the migrations built into this binary, oldest first.
+func Migrations() []Migration { return append([]Migration(nil), migrations...) }
+
+// LatestVersion is the version the database has once every migration is
+// applied.
+func LatestVersion() int { return len(migrations) }
+
+// Reversible reports whether the migration can be rolled back.
+func (m Migration) Reversible() bool { return m.down != "" }
+
+// Version returns the newest migration applied to the database, 0 for a
+// database that has none.
+func Version(ctx context.Context, db *sql.DB) (int, error) {
+	if _, err := db.ExecContext(ctx, createMigrationsSQL); err != nil {
+		return 0, fmt.Errorf("create schema_migrations: %w", err)
+	}
+	var v int
+	if err := db.QueryRowContext(ctx, currentVersionSQL).Scan(&v); err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+	return v, nil
+}
+
+// Migrate applies the pending migrations, each in its own transaction, and
+// returns the ones it applied. It refuses to touch a database migrated by
+// a newer build.
+func Migrate(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	current, err := Version(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if current > LatestVersion() {
+		return nil, fmt.Errorf("%w: database at version %d, this build knows up to %d", ErrSchemaTooNew, current, LatestVersion())
+	}
+	var applied []Migration
+	for _, m := range migrations[current:] {
+		if err := runMigration(ctx, db, m, true); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// MigrateDown rolls back the newest n applied migrations, newest first,
+// and returns the ones it rolled back. It stops at the first migration
+// that cannot be rolled back.
+func MigrateDown(ctx context.Context, db *sql.DB, n int) ([]Migration, error) {
+	current, err := Version(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if current > LatestVersion() {
+		return nil, fmt.Errorf("%w: database at version %d, this build knows up to %d", ErrSchemaTooNew, current, LatestVersion())
+	}
+	var reverted []Migration
+	for v := current; v > 0 && len(reverted) < n; v-- {
+		m := migrations[v-1]
+		if !m.Reversible() {
+			return reverted, fmt.Errorf("%w: %d_%s", ErrNoDownMigration, m.Version, m.Name)
+		}
+		if err := runMigration(ctx, db, m, false); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, m)
+	}
+	return reverted, nil
+}
+
+// runMigration applies m up or down and records the result in one
+// transaction, so a failed migration leaves the database as it was.
+func runMigration(ctx context.Context, db *sql.DB, m Migration, up bool) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin migration %d: %w", m.Version, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, record, args := m.up, insertMigrationSQL, []any{m.Version, m.Name, time.Now().UTC()}
+	if !up {
+		stmt, record, args = m.down, deleteMigrationSQL, []any{m.Version}
+	}
+	if _, err := tx.ExecContext(ctx, stmt); err != nil {
+		return fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+	}
+	if up && m.Version == baselineVersion {
+		// databases from before migrations may predate some columns
+		for _, col := range addedColumns {
+			if err := ensureColumn(tx, col); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return fmt.Errorf("record migration %d: %w", m.Version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration %d: %w", m.Version, err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func openMemory(t *testing.T) *sql.DB {
+	t.Helper()
+	conn, err := OpenDB(":memory:")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestMigrate_FreshDatabaseReachesLatest(t *testing.T) {
+	ctx := context.Background()
+	conn := openMemory(t)
+	if err := CheckSchema(ctx, conn); !errors.Is(err, ErrSchemaOutdated) {
+		t.Fatalf("check before migrating: %v, want ErrSchemaOutdated", err)
+	}
+
+	applied, err := Migrate(ctx, conn)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if len(applied) != LatestVersion() {
+		t.Fatalf("applied %d migrations, want %d", len(applied), LatestVersion())
+	}
+	if err := CheckSchema(ctx, conn); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	// a second run has nothing to do
+	if applied, err := Migrate(ctx, conn); err != nil || len(applied) != 0 {
+		t.Fatalf("second migrate: %v, %d applied", err, len(applied))
+	}
+}
+
+func TestMigrate_AdoptsDatabaseFromBeforeMigrations(t *testing.T) {
+	ctx := context.Background()
+	conn := openMemory(t)
+	// users as created before roles existed, with a row to keep
+	if _, err := conn.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, username TEXT NOT NULL UNIQUE, password_hash TEXT NOT NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(`INSERT INTO users (username, password_hash) VALUES ('ann', 'x')`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Migrate(ctx, conn); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	var role string
+	if err := conn.QueryRow(`SELECT role FROM users WHERE username = 'ann'`).Scan(&role); err != nil || role != "operator" {
+		t.Fatalf("role = %q, %v; want the column added with its default", role, err)
+	}
+	// users created from now on start as viewers
+	if _, err := conn.Exec(`INSERT INTO users (username, password_hash) VALUES ('bea', 'x')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.QueryRow(`SELECT role FROM users WHERE username = 'bea'`).Scan(&role); err != nil || role != "viewer" {
+		t.Fatalf("role of a new user = %q, %v; want viewer", role, err)
+	}
+}
+
+func TestMigrateDown_RollsBackAndRefusesNewerSchema(t *testing.T) {
+	ctx := context.Background()
+	conn := openMemory(t)
+	if _, err := Migrate(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+
+	reverted, err := MigrateDown(ctx, conn, 1)
+	if err != nil || len(reverted) != 1 || reverted[0].Version != LatestVersion() {
+		t.Fatalf("down: %v, %+v", err, reverted)
+	}
+	if v, err := Version(ctx, conn); err != nil || v != LatestVersion()-1 {
+		t.Fatalf("version = %d, %v", v, err)
+	}
+
+	if _, err := Migrate(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(insertMigrationSQL, LatestVersion()+1, "from_the_future", "2026-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Migrate(ctx, conn); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("migrate: %v, want ErrSchemaTooNew", err)
+	}
+	if err := CheckSchema(ctx, conn); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("check: %v, want ErrSchemaTooNew", err)
+	}
+}
+
+func TestLoadMigrations_RejectsGaps(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_a.up.sql": {Data: []byte("SELECT 1")},
+		"migrations/0003_c.up.sql": {Data: []byte("SELECT 1")},
+	}
+	if _, err := loadMigrations(fsys); err == nil {
+		t.Fatal("want an error for the missing migration 2")
+	}
+	fsys = fstest.MapFS{"migrations/0001_a.down.sql": {Data: []byte("SELECT 1")}}
+	if _, err := loadMigrations(fsys); err == nil {
+		t.Fatal("want an error for a migration without an up file")
+	}
+}
+
+func TestMigrate_UsernameKeySkipsLegacyCaseDuplicates(t *testing.T) {
+	ctx := context.Background()
+	conn := openMemory(t)
+	if _, err := conn.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, username TEXT NOT NULL UNIQUE, password_hash TEXT NOT NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(`INSERT INTO users (username, password_hash) VALUES ('ann', 'x'), ('Ann', 'x'), ('Bob', 'x')`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Migrate(ctx, conn); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	var keyed int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM users WHERE username_key IS NOT NULL`).Scan(&keyed); err != nil || keyed != 1 {
+		t.Fatalf("keyed users = %d, %v; want only bob", keyed, err)
+	}
+	if _, err := conn.Exec(`INSERT INTO users (username, username_key, password_hash) VALUES ('BOB', 'bob', 'x')`); err == nil {
+		t.Fatal("inserted a name that differs from another only in case")
+	}
+}
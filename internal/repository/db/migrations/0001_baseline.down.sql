@@ -0,0 +1,21 @@
+-- Drops every table of the baseline, and with them all data.
+DROP TABLE IF EXISTS event_comments;
+DROP TABLE IF EXISTS uptime_checks;
+DROP TABLE IF EXISTS webhook_cursor;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS maintenance_records;
+DROP TABLE IF EXISTS maintenance_tasks;
+DROP TABLE IF EXISTS event_purges;
+DROP TABLE IF EXISTS install_settings;
+DROP TABLE IF EXISTS incidents;
+DROP TABLE IF EXISTS alerts;
+DROP TABLE IF EXISTS alert_rules;
+DROP TABLE IF EXISTS furnace_health;
+DROP TABLE IF EXISTS sim_settings;
+DROP TABLE IF EXISTS furnace_samples;
+DROP TABLE IF EXISTS telemetry;
+DROP TABLE IF EXISTS runs;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS furnace_events;
+DROP TABLE IF EXISTS furnace_state;
@@ -0,0 +1,222 @@
+-- Baseline: the schema as it was when versioned migrations were introduced.
+-- IF NOT EXISTS lets it adopt databases created before then; their
+-- missing columns are added by the migrator in the same transaction.
+
+CREATE TABLE IF NOT EXISTS furnace_state (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    mode TEXT NOT NULL,
+    temp_c REAL NOT NULL,
+    target_c REAL,
+    remaining_s INTEGER,
+    errors TEXT,
+    running BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    run_id TEXT,
+    measured_c REAL,
+    power_kw REAL,
+    energy_kwh REAL,
+    ambient_c REAL,
+    gas TEXT,
+    gas_setpoint_m3h REAL,
+    gas_flow_m3h REAL,
+    o2_ppm REAL,
+    max_o2_ppm REAL
+);
+
+CREATE TABLE IF NOT EXISTS furnace_events (
+    id TEXT PRIMARY KEY,
+    occurred_at TIMESTAMP NOT NULL,
+    type TEXT NOT NULL,
+    message TEXT NOT NULL,
+    meta TEXT,
+    prev_hash TEXT,
+    hash TEXT
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT UNIQUE NOT NULL,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'operator'
+);
+
+CREATE TABLE IF NOT EXISTS runs (
+    run_id TEXT PRIMARY KEY,
+    target_c REAL NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    soak_s REAL NOT NULL DEFAULT 0,
+    soak_within_s REAL NOT NULL DEFAULT 0,
+    soak_mean_c REAL NOT NULL DEFAULT 0,
+    soak_stddev_c REAL NOT NULL DEFAULT 0,
+    stability REAL NOT NULL DEFAULT 0,
+    unstable BOOLEAN NOT NULL DEFAULT 0,
+    energy_kwh REAL NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS telemetry (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    ts TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    value REAL NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_telemetry_channel_ts ON telemetry (channel, ts);
+
+CREATE TABLE IF NOT EXISTS furnace_samples (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    ts TEXT NOT NULL,
+    temp_c REAL NOT NULL,
+    target_c REAL NOT NULL DEFAULT 0,
+    mode TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_furnace_samples_ts ON furnace_samples (ts);
+
+CREATE TABLE IF NOT EXISTS sim_settings (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    data TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS furnace_health (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    heating_s REAL NOT NULL DEFAULT 0,
+    cycles INTEGER NOT NULL DEFAULT 0,
+    last_run_id TEXT NOT NULL DEFAULT '',
+    maintenance_due BOOLEAN NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL,
+    replaced_heating_s REAL NOT NULL DEFAULT 0,
+    replaced_cycles INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    threshold REAL NOT NULL DEFAULT 0,
+    for_s INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT 1,
+    created_by INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    rule_id INTEGER NOT NULL,
+    rule_name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    fired_at TIMESTAMP NOT NULL,
+    value REAL NOT NULL DEFAULT 0,
+    message TEXT NOT NULL,
+    run_id TEXT NOT NULL DEFAULT '',
+    notified BOOLEAN NOT NULL DEFAULT 0,
+    notify_error TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_alerts_fired_at ON alerts (fired_at);
+
+CREATE TABLE IF NOT EXISTS incidents (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    started_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP,
+    run_id TEXT NOT NULL DEFAULT '',
+    alarm_codes TEXT NOT NULL DEFAULT '[]',
+    peak_temp_c REAL NOT NULL DEFAULT 0,
+    peak_measured_c REAL NOT NULL DEFAULT 0,
+    overheat_s REAL NOT NULL DEFAULT 0,
+    resolution TEXT NOT NULL DEFAULT '',
+    acked_by INTEGER NOT NULL DEFAULT 0,
+    acked_at TIMESTAMP,
+    events TEXT NOT NULL DEFAULT '[]',
+    telemetry TEXT NOT NULL DEFAULT '[]'
+);
+CREATE INDEX IF NOT EXISTS idx_incidents_started_at ON incidents (started_at);
+
+CREATE TABLE IF NOT EXISTS install_settings (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    data TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS event_purges (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    purged_at TIMESTAMP NOT NULL,
+    deleted INTEGER NOT NULL,
+    chain_anchor TEXT,
+    archive TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS maintenance_tasks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    part TEXT NOT NULL DEFAULT '',
+    interval_hours REAL NOT NULL DEFAULT 0,
+    interval_days INTEGER NOT NULL DEFAULT 0,
+    baseline_at TIMESTAMP NOT NULL,
+    baseline_hours REAL NOT NULL DEFAULT 0,
+    last_done_at TIMESTAMP,
+    overdue_logged BOOLEAN NOT NULL DEFAULT 0,
+    created_by INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS maintenance_records (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL,
+    task_name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    part TEXT NOT NULL DEFAULT '',
+    completed_at TIMESTAMP NOT NULL,
+    completed_by INTEGER NOT NULL DEFAULT 0,
+    heating_hours REAL NOT NULL DEFAULT 0,
+    note TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_maintenance_records_task ON maintenance_records (task_id, completed_at);
+
+-- webhook_cursor holds the rowid of the last furnace_events row queued for
+-- delivery.
+CREATE TABLE IF NOT EXISTS webhooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    event_types TEXT NOT NULL,
+    secret TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT 1,
+    created_by INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id INTEGER NOT NULL,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP,
+    last_attempt_at TIMESTAMP,
+    response_status INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    delivered_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_hook ON webhook_deliveries (webhook_id, id);
+CREATE TABLE IF NOT EXISTS webhook_cursor (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    event_rowid INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS uptime_checks (
+    at TIMESTAMP NOT NULL,
+    up BOOLEAN NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_uptime_checks_at ON uptime_checks (at);
+
+CREATE TABLE IF NOT EXISTS event_comments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id TEXT NOT NULL,
+    user_id INTEGER NOT NULL DEFAULT 0,
+    text TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_event_comments_event ON event_comments (event_id, id);
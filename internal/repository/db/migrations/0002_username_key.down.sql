@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_username_key;
+
+ALTER TABLE users DROP COLUMN username_key;
@@ -0,0 +1,12 @@
+-- Usernames are unique regardless of case. username_key holds the
+-- lowercased name and carries the unique index. Users from before this
+-- migration whose names differ only in case keep a NULL key, so the index
+-- can be built without touching their accounts; they sign in by their
+-- exact name. SQLite's lower() folds ASCII only, so existing names with
+-- other capitals are keyed as stored.
+ALTER TABLE users ADD COLUMN username_key TEXT;
+
+UPDATE users SET username_key = lower(username)
+WHERE (SELECT COUNT(*) FROM users AS u WHERE lower(u.username) = lower(users.username)) = 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_key ON users (username_key);
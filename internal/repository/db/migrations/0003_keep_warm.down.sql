@@ -0,0 +1 @@
+ALTER TABLE furnace_state DROP COLUMN keep_warm_c;
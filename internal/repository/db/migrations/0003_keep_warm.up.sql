@@ -0,0 +1,3 @@
+-- The setpoint STANDBY holds while keep-warm is engaged, 0 otherwise;
+-- NULL in rows saved before keep-warm existed.
+ALTER TABLE furnace_state ADD COLUMN keep_warm_c REAL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_furnace_events_type_occurred;
+DROP INDEX IF EXISTS idx_furnace_events_occurred_type;
@@ -0,0 +1,8 @@
+-- Event queries filter on a time range, a type, or both, and list by
+-- occurred_at; without indexes each of them scans the whole log.
+-- (occurred_at, type) serves time ranges, optionally narrowed by type, in
+-- order; (type, occurred_at) serves a single type over a long range, such
+-- as the ESCALATION lookup at start-up. ANALYZE at start-up lets the planner
+-- choose between them.
+CREATE INDEX IF NOT EXISTS idx_furnace_events_occurred_type ON furnace_events (occurred_at, type);
+CREATE INDEX IF NOT EXISTS idx_furnace_events_type_occurred ON furnace_events (type, occurred_at);
@@ -0,0 +1,3 @@
+UPDATE furnace_events
+SET occurred_at = strftime('%Y-%m-%d %H:%M:%S', occurred_at)
+WHERE strftime('%Y-%m-%d %H:%M:%S', occurred_at) IS NOT NULL;
@@ -0,0 +1,19 @@
+-- occurred_at was stored as "2006-01-02 15:04:05" by the event repository
+-- and in Go's time.Time String format ("2006-01-02 15:04:05.999 +0000 UTC")
+-- wherever a time.Time was bound, so text comparisons against range bounds
+-- went wrong at the boundaries. Rewrite every row to RFC 3339 in UTC to the
+-- second ("2006-01-02T15:04:05Z"), the one format the repository now writes
+-- and binds bounds in.
+
+-- Go's format is not one strftime reads: keep the date and time and turn
+-- the "+0200 CEST" suffix into "+02:00".
+UPDATE furnace_events
+SET occurred_at = substr(occurred_at, 1, 10 + instr(substr(occurred_at, 12), ' '))
+    || substr(occurred_at, 12 + instr(substr(occurred_at, 12), ' '), 3) || ':'
+    || substr(occurred_at, 15 + instr(substr(occurred_at, 12), ' '), 2)
+WHERE occurred_at GLOB '????-??-?? ??:??:??* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE furnace_events
+SET occurred_at = strftime('%Y-%m-%dT%H:%M:%SZ', occurred_at)
+WHERE strftime('%Y-%m-%dT%H:%M:%SZ', occurred_at) IS NOT NULL
+  AND occurred_at IS NOT strftime('%Y-%m-%dT%H:%M:%SZ', occurred_at);
@@ -0,0 +1,16 @@
+-- Hourly (period_s 3600) and daily (86400) summaries of furnace_samples,
+-- so history over weeks reads a few hundred rows instead of every tick and
+-- the raw samples can be pruned once summarised. start is the Unix time
+-- the period begins at; the average is sum_c / samples, so summaries
+-- combine into coarser ones exactly. runtime_s is the time spent heating.
+CREATE TABLE IF NOT EXISTS sample_rollups (
+    period_s INTEGER NOT NULL,
+    start INTEGER NOT NULL,
+    samples INTEGER NOT NULL,
+    min_c REAL NOT NULL,
+    max_c REAL NOT NULL,
+    sum_c REAL NOT NULL,
+    target_c REAL NOT NULL,
+    runtime_s REAL NOT NULL,
+    PRIMARY KEY (period_s, start)
+);
@@ -0,0 +1,47 @@
+-- Only the 'default' site survives a rollback.
+DELETE FROM event_purges WHERE site_id != 'default';
+ALTER TABLE event_purges DROP COLUMN site_id;
+DELETE FROM furnace_events WHERE site_id != 'default';
+ALTER TABLE furnace_events DROP COLUMN site_id;
+
+CREATE TABLE furnace_state_single (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    mode TEXT NOT NULL,
+    temp_c REAL NOT NULL,
+    target_c REAL,
+    remaining_s INTEGER,
+    errors TEXT,
+    running BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    run_id TEXT,
+    measured_c REAL,
+    power_kw REAL,
+    energy_kwh REAL,
+    ambient_c REAL,
+    gas TEXT,
+    gas_setpoint_m3h REAL,
+    gas_flow_m3h REAL,
+    o2_ppm REAL,
+    max_o2_ppm REAL,
+    keep_warm_c REAL
+);
+INSERT INTO furnace_state_single (id, mode, temp_c, target_c, remaining_s, errors, running, updated_at, run_id, measured_c,
+    power_kw, energy_kwh, ambient_c, gas, gas_setpoint_m3h, gas_flow_m3h, o2_ppm, max_o2_ppm, keep_warm_c)
+SELECT 1, mode, temp_c, target_c, remaining_s, errors, running, updated_at, run_id, measured_c,
+    power_kw, energy_kwh, ambient_c, gas, gas_setpoint_m3h, gas_flow_m3h, o2_ppm, max_o2_ppm, keep_warm_c
+FROM furnace_state WHERE site_id = 'default';
+DROP TABLE furnace_state;
+ALTER TABLE furnace_state_single RENAME TO furnace_state;
+
+CREATE TABLE users_single (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT UNIQUE NOT NULL,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'operator',
+    username_key TEXT
+);
+INSERT INTO users_single (id, username, password_hash, role, username_key)
+SELECT id, username, password_hash, role, username_key FROM users WHERE site_id = 'default';
+DROP TABLE users;
+ALTER TABLE users_single RENAME TO users;
+CREATE UNIQUE INDEX idx_users_username_key ON users (username_key);
@@ -0,0 +1,56 @@
+-- Users, the furnace state and the event log belong to a site, so several
+-- plants can share one database, each backend serving its own. Rows from
+-- before sites existed belong to the 'default' site.
+--
+-- users and furnace_state are rebuilt: usernames become unique per site
+-- rather than overall, and furnace_state holds one row per site instead of
+-- the single row with id 1.
+CREATE TABLE users_sites (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    site_id TEXT NOT NULL DEFAULT 'default',
+    username TEXT NOT NULL,
+    username_key TEXT,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'operator',
+    UNIQUE (site_id, username)
+);
+INSERT INTO users_sites (id, username, username_key, password_hash, role)
+SELECT id, username, username_key, password_hash, role FROM users;
+DROP TABLE users;
+ALTER TABLE users_sites RENAME TO users;
+CREATE UNIQUE INDEX idx_users_username_key ON users (site_id, username_key);
+
+CREATE TABLE furnace_state_sites (
+    id INTEGER PRIMARY KEY,
+    site_id TEXT NOT NULL UNIQUE DEFAULT 'default',
+    mode TEXT NOT NULL,
+    temp_c REAL NOT NULL,
+    target_c REAL,
+    remaining_s INTEGER,
+    errors TEXT,
+    running BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    run_id TEXT,
+    measured_c REAL,
+    power_kw REAL,
+    energy_kwh REAL,
+    ambient_c REAL,
+    gas TEXT,
+    gas_setpoint_m3h REAL,
+    gas_flow_m3h REAL,
+    o2_ppm REAL,
+    max_o2_ppm REAL,
+    keep_warm_c REAL
+);
+INSERT INTO furnace_state_sites (id, mode, temp_c, target_c, remaining_s, errors, running, updated_at, run_id, measured_c,
+    power_kw, energy_kwh, ambient_c, gas, gas_setpoint_m3h, gas_flow_m3h, o2_ppm, max_o2_ppm, keep_warm_c)
+SELECT id, mode, temp_c, target_c, remaining_s, errors, running, updated_at, run_id, measured_c,
+    power_kw, energy_kwh, ambient_c, gas, gas_setpoint_m3h, gas_flow_m3h, o2_ppm, max_o2_ppm, keep_warm_c
+FROM furnace_state;
+DROP TABLE furnace_state;
+ALTER TABLE furnace_state_sites RENAME TO furnace_state;
+
+ALTER TABLE furnace_events ADD COLUMN site_id TEXT NOT NULL DEFAULT 'default';
+
+-- Each site's hash chain resumes from the anchor of its own last purge.
+ALTER TABLE event_purges ADD COLUMN site_id TEXT NOT NULL DEFAULT 'default';
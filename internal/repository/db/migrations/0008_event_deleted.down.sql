@@ -0,0 +1,3 @@
+ALTER TABLE furnace_events DROP COLUMN delete_reason;
+ALTER TABLE furnace_events DROP COLUMN deleted_by;
+ALTER TABLE furnace_events DROP COLUMN deleted_at;
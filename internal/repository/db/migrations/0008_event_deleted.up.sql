@@ -0,0 +1,6 @@
+-- Events holding mistaken data are soft-deleted rather than removed: the
+-- row stays, with who hid it, when and why, so the audit history and the
+-- hash chain over it are kept. NULL deleted_at means the event is listed.
+ALTER TABLE furnace_events ADD COLUMN deleted_at TEXT;
+ALTER TABLE furnace_events ADD COLUMN deleted_by INTEGER;
+ALTER TABLE furnace_events ADD COLUMN delete_reason TEXT;
@@ -0,0 +1,14 @@
+CREATE TABLE users_operator (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    site_id TEXT NOT NULL DEFAULT 'default',
+    username TEXT NOT NULL,
+    username_key TEXT,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'operator',
+    UNIQUE (site_id, username)
+);
+INSERT INTO users_operator (id, site_id, username, username_key, password_hash, role)
+SELECT id, site_id, username, username_key, password_hash, role FROM users;
+DROP TABLE users;
+ALTER TABLE users_operator RENAME TO users;
+CREATE UNIQUE INDEX idx_users_username_key ON users (site_id, username_key);
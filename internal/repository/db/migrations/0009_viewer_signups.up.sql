@@ -0,0 +1,17 @@
+-- Users created by sign-up start as viewers; an admin grants more with
+-- PUT /api/v1/admin/users/:id/role. Existing users keep their roles. SQLite
+-- cannot change a column default in place, so users is rebuilt.
+CREATE TABLE users_viewer (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    site_id TEXT NOT NULL DEFAULT 'default',
+    username TEXT NOT NULL,
+    username_key TEXT,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'viewer',
+    UNIQUE (site_id, username)
+);
+INSERT INTO users_viewer (id, site_id, username, username_key, password_hash, role)
+SELECT id, site_id, username, username_key, password_hash, role FROM users;
+DROP TABLE users;
+ALTER TABLE users_viewer RENAME TO users;
+CREATE UNIQUE INDEX idx_users_username_key ON users (site_id, username_key);
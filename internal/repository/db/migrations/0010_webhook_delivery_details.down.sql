@@ -0,0 +1,2 @@
+ALTER TABLE webhook_deliveries DROP COLUMN response_snippet;
+ALTER TABLE webhook_deliveries DROP COLUMN latency_ms;
@@ -0,0 +1,4 @@
+-- Each delivery keeps how long its last attempt took and the start of the
+-- answer, so integrators can see why their endpoint rejected it.
+ALTER TABLE webhook_deliveries ADD COLUMN latency_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE webhook_deliveries ADD COLUMN response_snippet TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,105 @@
+-- Only the 'default' site's rows survive a rollback.
+DROP INDEX IF EXISTS idx_uptime_checks_site_at;
+DELETE FROM uptime_checks WHERE site_id != 'default';
+ALTER TABLE uptime_checks DROP COLUMN site_id;
+CREATE INDEX IF NOT EXISTS idx_uptime_checks_at ON uptime_checks (at);
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_site_due;
+DELETE FROM webhook_deliveries WHERE site_id != 'default';
+ALTER TABLE webhook_deliveries DROP COLUMN site_id;
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+DELETE FROM webhooks WHERE site_id != 'default';
+ALTER TABLE webhooks DROP COLUMN site_id;
+
+DELETE FROM maintenance_records WHERE site_id != 'default';
+ALTER TABLE maintenance_records DROP COLUMN site_id;
+DELETE FROM maintenance_tasks WHERE site_id != 'default';
+ALTER TABLE maintenance_tasks DROP COLUMN site_id;
+
+DROP INDEX IF EXISTS idx_incidents_site_started_at;
+DELETE FROM incidents WHERE site_id != 'default';
+ALTER TABLE incidents DROP COLUMN site_id;
+CREATE INDEX IF NOT EXISTS idx_incidents_started_at ON incidents (started_at);
+
+DROP INDEX IF EXISTS idx_alerts_site_fired_at;
+DELETE FROM alerts WHERE site_id != 'default';
+ALTER TABLE alerts DROP COLUMN site_id;
+CREATE INDEX IF NOT EXISTS idx_alerts_fired_at ON alerts (fired_at);
+DELETE FROM alert_rules WHERE site_id != 'default';
+ALTER TABLE alert_rules DROP COLUMN site_id;
+
+DROP INDEX IF EXISTS idx_furnace_samples_site_ts;
+DELETE FROM furnace_samples WHERE site_id != 'default';
+ALTER TABLE furnace_samples DROP COLUMN site_id;
+CREATE INDEX IF NOT EXISTS idx_furnace_samples_ts ON furnace_samples (ts);
+
+DROP INDEX IF EXISTS idx_telemetry_site_channel_ts;
+DELETE FROM telemetry WHERE site_id != 'default';
+ALTER TABLE telemetry DROP COLUMN site_id;
+CREATE INDEX IF NOT EXISTS idx_telemetry_channel_ts ON telemetry (channel, ts);
+
+DROP INDEX IF EXISTS idx_runs_site;
+DELETE FROM runs WHERE site_id != 'default';
+ALTER TABLE runs DROP COLUMN site_id;
+
+CREATE TABLE sample_rollups_single (
+    period_s INTEGER NOT NULL,
+    start INTEGER NOT NULL,
+    samples INTEGER NOT NULL,
+    min_c REAL NOT NULL,
+    max_c REAL NOT NULL,
+    sum_c REAL NOT NULL,
+    target_c REAL NOT NULL,
+    runtime_s REAL NOT NULL,
+    PRIMARY KEY (period_s, start)
+);
+INSERT INTO sample_rollups_single (period_s, start, samples, min_c, max_c, sum_c, target_c, runtime_s)
+SELECT period_s, start, samples, min_c, max_c, sum_c, target_c, runtime_s FROM sample_rollups WHERE site_id = 'default';
+DROP TABLE sample_rollups;
+ALTER TABLE sample_rollups_single RENAME TO sample_rollups;
+
+CREATE TABLE webhook_cursor_single (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    event_rowid INTEGER NOT NULL
+);
+INSERT INTO webhook_cursor_single (id, event_rowid)
+SELECT 1, event_rowid FROM webhook_cursor WHERE site_id = 'default';
+DROP TABLE webhook_cursor;
+ALTER TABLE webhook_cursor_single RENAME TO webhook_cursor;
+
+CREATE TABLE furnace_health_single (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    heating_s REAL NOT NULL DEFAULT 0,
+    cycles INTEGER NOT NULL DEFAULT 0,
+    last_run_id TEXT NOT NULL DEFAULT '',
+    maintenance_due BOOLEAN NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL,
+    replaced_heating_s REAL NOT NULL DEFAULT 0,
+    replaced_cycles INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO furnace_health_single (id, heating_s, cycles, last_run_id, maintenance_due, updated_at,
+    replaced_heating_s, replaced_cycles)
+SELECT 1, heating_s, cycles, last_run_id, maintenance_due, updated_at, replaced_heating_s, replaced_cycles
+FROM furnace_health WHERE site_id = 'default';
+DROP TABLE furnace_health;
+ALTER TABLE furnace_health_single RENAME TO furnace_health;
+
+CREATE TABLE install_settings_single (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    data TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+INSERT INTO install_settings_single (id, data, updated_at)
+SELECT 1, data, updated_at FROM install_settings WHERE site_id = 'default';
+DROP TABLE install_settings;
+ALTER TABLE install_settings_single RENAME TO install_settings;
+
+CREATE TABLE sim_settings_single (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    data TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+INSERT INTO sim_settings_single (id, data, updated_at)
+SELECT 1, data, updated_at FROM sim_settings WHERE site_id = 'default';
+DROP TABLE sim_settings;
+ALTER TABLE sim_settings_single RENAME TO sim_settings;
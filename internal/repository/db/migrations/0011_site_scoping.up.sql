@@ -0,0 +1,120 @@
+-- Every table belongs to a site, not only users, the furnace state and the
+-- event log, so sites sharing a database see nothing of each other:
+-- settings, the signing key, telemetry, history, runs, alerts, incidents,
+-- maintenance, webhooks and uptime.
+--
+-- Rows from before are the 'default' site's, except runs, alerts and
+-- incidents of runs whose events another site logged. The settings,
+-- installation and wear rows were shared, so every site that has users or
+-- a state keeps a copy of them.
+
+CREATE TABLE sim_settings_sites (
+    id INTEGER PRIMARY KEY,
+    site_id TEXT NOT NULL UNIQUE DEFAULT 'default',
+    data TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+INSERT INTO sim_settings_sites (site_id, data, updated_at)
+SELECT s.site_id, d.data, d.updated_at
+FROM sim_settings d, (SELECT site_id FROM users UNION SELECT site_id FROM furnace_state UNION SELECT 'default') s;
+DROP TABLE sim_settings;
+ALTER TABLE sim_settings_sites RENAME TO sim_settings;
+
+CREATE TABLE install_settings_sites (
+    id INTEGER PRIMARY KEY,
+    site_id TEXT NOT NULL UNIQUE DEFAULT 'default',
+    data TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+INSERT INTO install_settings_sites (site_id, data, updated_at)
+SELECT s.site_id, d.data, d.updated_at
+FROM install_settings d, (SELECT site_id FROM users UNION SELECT site_id FROM furnace_state UNION SELECT 'default') s;
+DROP TABLE install_settings;
+ALTER TABLE install_settings_sites RENAME TO install_settings;
+
+CREATE TABLE furnace_health_sites (
+    id INTEGER PRIMARY KEY,
+    site_id TEXT NOT NULL UNIQUE DEFAULT 'default',
+    heating_s REAL NOT NULL DEFAULT 0,
+    cycles INTEGER NOT NULL DEFAULT 0,
+    last_run_id TEXT NOT NULL DEFAULT '',
+    maintenance_due BOOLEAN NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL,
+    replaced_heating_s REAL NOT NULL DEFAULT 0,
+    replaced_cycles INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO furnace_health_sites (site_id, heating_s, cycles, last_run_id, maintenance_due, updated_at,
+    replaced_heating_s, replaced_cycles)
+SELECT s.site_id, h.heating_s, h.cycles, h.last_run_id, h.maintenance_due, h.updated_at,
+    h.replaced_heating_s, h.replaced_cycles
+FROM furnace_health h, (SELECT site_id FROM users UNION SELECT site_id FROM furnace_state UNION SELECT 'default') s;
+DROP TABLE furnace_health;
+ALTER TABLE furnace_health_sites RENAME TO furnace_health;
+
+-- each site's cursor starts at the shared one
+CREATE TABLE webhook_cursor_sites (
+    id INTEGER PRIMARY KEY,
+    site_id TEXT NOT NULL UNIQUE DEFAULT 'default',
+    event_rowid INTEGER NOT NULL
+);
+INSERT INTO webhook_cursor_sites (site_id, event_rowid)
+SELECT s.site_id, c.event_rowid
+FROM webhook_cursor c, (SELECT site_id FROM users UNION SELECT site_id FROM furnace_state UNION SELECT 'default') s;
+DROP TABLE webhook_cursor;
+ALTER TABLE webhook_cursor_sites RENAME TO webhook_cursor;
+
+CREATE TABLE sample_rollups_sites (
+    site_id TEXT NOT NULL DEFAULT 'default',
+    period_s INTEGER NOT NULL,
+    start INTEGER NOT NULL,
+    samples INTEGER NOT NULL,
+    min_c REAL NOT NULL,
+    max_c REAL NOT NULL,
+    sum_c REAL NOT NULL,
+    target_c REAL NOT NULL,
+    runtime_s REAL NOT NULL,
+    PRIMARY KEY (site_id, period_s, start)
+);
+INSERT INTO sample_rollups_sites (period_s, start, samples, min_c, max_c, sum_c, target_c, runtime_s)
+SELECT period_s, start, samples, min_c, max_c, sum_c, target_c, runtime_s FROM sample_rollups;
+DROP TABLE sample_rollups;
+ALTER TABLE sample_rollups_sites RENAME TO sample_rollups;
+
+ALTER TABLE runs ADD COLUMN site_id TEXT NOT NULL DEFAULT 'default';
+UPDATE runs SET site_id = COALESCE((
+    SELECT e.site_id FROM furnace_events e WHERE json_extract(e.meta, '$.run_id') = runs.run_id LIMIT 1
+), 'default');
+CREATE INDEX idx_runs_site ON runs (site_id, started_at);
+
+ALTER TABLE telemetry ADD COLUMN site_id TEXT NOT NULL DEFAULT 'default';
+DROP INDEX IF EXISTS idx_telemetry_channel_ts;
+CREATE INDEX idx_telemetry_site_channel_ts ON telemetry (site_id, channel, ts);
+
+ALTER TABLE furnace_samples ADD COLUMN site_id TEXT NOT NULL DEFAULT 'default';
+DROP INDEX IF EXISTS idx_furnace_samples_ts;
+CREATE INDEX idx_furnace_samples_site_ts ON furnace_samples (site_id, ts);
+
+ALTER TABLE alert_rules ADD COLUMN site_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE alerts ADD COLUMN site_id TEXT NOT NULL DEFAULT 'default';
+UPDATE alerts SET site_id = (SELECT r.site_id FROM runs r WHERE r.run_id = alerts.run_id)
+WHERE run_id != '' AND EXISTS (SELECT 1 FROM runs r WHERE r.run_id = alerts.run_id);
+DROP INDEX IF EXISTS idx_alerts_fired_at;
+CREATE INDEX idx_alerts_site_fired_at ON alerts (site_id, fired_at);
+
+ALTER TABLE incidents ADD COLUMN site_id TEXT NOT NULL DEFAULT 'default';
+UPDATE incidents SET site_id = (SELECT r.site_id FROM runs r WHERE r.run_id = incidents.run_id)
+WHERE run_id != '' AND EXISTS (SELECT 1 FROM runs r WHERE r.run_id = incidents.run_id);
+DROP INDEX IF EXISTS idx_incidents_started_at;
+CREATE INDEX idx_incidents_site_started_at ON incidents (site_id, started_at);
+
+ALTER TABLE maintenance_tasks ADD COLUMN site_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE maintenance_records ADD COLUMN site_id TEXT NOT NULL DEFAULT 'default';
+
+ALTER TABLE webhooks ADD COLUMN site_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE webhook_deliveries ADD COLUMN site_id TEXT NOT NULL DEFAULT 'default';
+DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+CREATE INDEX idx_webhook_deliveries_site_due ON webhook_deliveries (site_id, status, next_attempt_at);
+
+ALTER TABLE uptime_checks ADD COLUMN site_id TEXT NOT NULL DEFAULT 'default';
+DROP INDEX IF EXISTS idx_uptime_checks_at;
+CREATE INDEX idx_uptime_checks_site_at ON uptime_checks (site_id, at);
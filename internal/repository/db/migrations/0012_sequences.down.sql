@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_sequences_site_status;
+DROP TABLE IF EXISTS sequences;
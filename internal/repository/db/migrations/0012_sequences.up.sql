@@ -0,0 +1,18 @@
+-- Submitted mode sequences with the progress of their current step, so a
+-- running sequence resumes after a restart instead of being forgotten.
+CREATE TABLE IF NOT EXISTS sequences (
+    id TEXT PRIMARY KEY,
+    site_id TEXT NOT NULL DEFAULT 'default',
+    steps TEXT NOT NULL,
+    status TEXT NOT NULL,
+    step INTEGER NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_by INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP,
+    step_since TIMESTAMP NOT NULL,
+    step_run_id TEXT NOT NULL DEFAULT '',
+    held_s REAL NOT NULL DEFAULT 0,
+    saved_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sequences_site_status ON sequences (site_id, status);
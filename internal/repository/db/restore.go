@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSnapshot is returned by Restore for a file that is not a
+// usable database snapshot.
+var ErrInvalidSnapshot = errors.New("invalid database snapshot")
+
+// ErrOtherSites is returned by Restore when the current database holds
+// sites the snapshot does not. A backup holds a single site, so restoring
+// it over a database that several sites share would delete the others.
+var ErrOtherSites = errors.New("the database holds sites the snapshot does not")
+
+// sidecars are the files SQLite keeps next to a WAL-mode database.
+var sidecars = []string{"", "-wal", "-shm"}
+
+// Restore replaces the database at path with snapshot, a copy taken by
+// VACUUM INTO. The snapshot must pass an integrity check and must not have
+// been migrated by a newer build; older ones are migrated when the server
+// next opens the database. It must hold every site the current database
+// does (see ErrOtherSites). The current database, with its -wal and -shm
+// files, is moved aside to the returned path rather than deleted. Nothing
+// may use the database meanwhile.
+func Restore(ctx context.Context, snapshot, path string, now time.Time) (aside string, err error) {
+	if err := checkSnapshot(ctx, snapshot); err != nil {
+		return "", err
+	}
+	if err := checkSites(ctx, snapshot, path); err != nil {
+		return "", err
+	}
+
+	// copy first, so a failed copy leaves the current database in place
+	tmp := path + ".restore"
+	if err := copyFile(snapshot, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	if _, err := os.Stat(path); err == nil {
+		aside = path + ".before-restore-" + now.UTC().Format("20060102T150405Z")
+		for _, s := range sidecars {
+			if err := os.Rename(path+s, aside+s); err != nil && !errors.Is(err, os.ErrNotExist) {
+				_ = os.Remove(tmp)
+				return "", fmt.Errorf("move current database aside: %w", err)
+			}
+		}
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return aside, err
+	}
+	return aside, nil
+}
+
+// checkSnapshot opens snapshot read-only and verifies its integrity and
+// schema version.
+func checkSnapshot(ctx context.Context, snapshot string) error {
+	if _, err := os.Stat(snapshot); err != nil {
+		return err
+	}
+	conn, err := sql.Open(sqliteDriverName, "file:"+snapshot+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	var check string
+	if err := conn.QueryRowContext(ctx, `PRAGMA integrity_check`).Scan(&check); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if check != "ok" {
+		return fmt.Errorf("%w: integrity check: %s", ErrInvalidSnapshot, check)
+	}
+	var v int
+	if err := conn.QueryRowContext(ctx, currentVersionSQL).Scan(&v); err != nil {
+		return fmt.Errorf("%w: read schema version: %v", ErrInvalidSnapshot, err)
+	}
+	if v > LatestVersion() {
+		return fmt.Errorf("%w: at version %d, this build knows up to %d", ErrSchemaTooNew, v, LatestVersion())
+	}
+	return nil
+}
+
+// checkSites fails with ErrOtherSites if the database at path has users or
+// a furnace state of a site that snapshot has neither of.
+func checkSites(ctx context.Context, snapshot, path string) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	live, err := siteIDs(ctx, path)
+	if err != nil {
+		return fmt.Errorf("read sites of the current database: %w", err)
+	}
+	kept, err := siteIDs(ctx, snapshot)
+	if err != nil {
+		return fmt.Errorf("%w: read sites: %v", ErrInvalidSnapshot, err)
+	}
+	var lost []string
+	for _, site := range live {
+		if !slices.Contains(kept, site) {
+			lost = append(lost, site)
+		}
+	}
+	if len(lost) > 0 {
+		return fmt.Errorf("%w: %s", ErrOtherSites, strings.Join(lost, ", "))
+	}
+	return nil
+}
+
+// siteIDs lists the sites with users or a furnace state in the database
+// at path. A database from before sites existed only holds 'default'.
+func siteIDs(ctx context.Context, path string) ([]string, error) {
+	conn, err := sql.Open(sqliteDriverName, "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	var sited bool
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) > 0 FROM pragma_table_info('users') WHERE name = 'site_id'`).Scan(&sited); err != nil {
+		return nil, err
+	}
+	if !sited {
+		return []string{"default"}, nil
+	}
+	rows, err := conn.QueryContext(ctx, `SELECT site_id FROM users UNION SELECT site_id FROM furnace_state ORDER BY 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []string
+	for rows.Next() {
+		var site string
+		if err := rows.Scan(&site); err != nil {
+			return nil, err
+		}
+		out = append(out, site)
+	}
+	return out, rows.Err()
+}
+
+// copyFile copies src to dst and syncs it to disk.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// migratedFile creates a migrated database at path with one marker row.
+func migratedFile(t *testing.T, path, marker string) {
+	t.Helper()
+	ctx := context.Background()
+	conn, err := OpenDB(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if _, err := Migrate(ctx, conn); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE marker (v TEXT); INSERT INTO marker VALUES (?)`, marker); err != nil {
+		t.Fatalf("marker: %v", err)
+	}
+}
+
+func readMarker(t *testing.T, path string) string {
+	t.Helper()
+	conn, err := OpenDB(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	var v string
+	if err := conn.QueryRow(`SELECT v FROM marker`).Scan(&v); err != nil {
+		t.Fatalf("read marker: %v", err)
+	}
+	return v
+}
+
+func TestRestore_ReplacesDatabaseAndKeepsTheOldOne(t *testing.T) {
+	dir := t.TempDir()
+	live := filepath.Join(dir, "furnace.db")
+	src := filepath.Join(dir, "src.db")
+	snapshot := filepath.Join(dir, "snapshot.db")
+	migratedFile(t, live, "live")
+	migratedFile(t, src, "snapshot")
+
+	conn, err := OpenDB(src)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := conn.Exec(`VACUUM INTO ?`, snapshot); err != nil {
+		t.Fatalf("vacuum: %v", err)
+	}
+	_ = conn.Close()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	aside, err := Restore(context.Background(), snapshot, live, now)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if want := live + ".before-restore-20260301T120000Z"; aside != want {
+		t.Fatalf("aside = %q, want %q", aside, want)
+	}
+	if got := readMarker(t, live); got != "snapshot" {
+		t.Fatalf("restored marker = %q", got)
+	}
+	if got := readMarker(t, aside); got != "live" {
+		t.Fatalf("kept marker = %q", got)
+	}
+}
+
+func TestRestore_RejectsBadSnapshots(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	live := filepath.Join(dir, "furnace.db")
+	migratedFile(t, live, "live")
+
+	junk := filepath.Join(dir, "junk.db")
+	if err := os.WriteFile(junk, []byte("not a database"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Restore(ctx, junk, live, time.Now()); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Fatalf("junk: %v, want ErrInvalidSnapshot", err)
+	}
+
+	newer := filepath.Join(dir, "newer.db")
+	migratedFile(t, newer, "newer")
+	conn, err := OpenDB(newer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, 'future', ?)`, LatestVersion()+1, time.Now()); err != nil {
+		t.Fatalf("bump version: %v", err)
+	}
+	_ = conn.Close()
+	if _, err := Restore(ctx, newer, live, time.Now()); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("newer: %v, want ErrSchemaTooNew", err)
+	}
+
+	// a snapshot of one site cannot replace a database another site shares
+	conn, err = OpenDB(live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(`INSERT INTO users (username, username_key, password_hash, site_id) VALUES ('ann', 'ann', 'hash', 'plant-b')`); err != nil {
+		t.Fatalf("add a site: %v", err)
+	}
+	_ = conn.Close()
+	single := filepath.Join(dir, "single.db")
+	migratedFile(t, single, "single")
+	if _, err := Restore(ctx, single, live, time.Now()); !errors.Is(err, ErrOtherSites) {
+		t.Fatalf("single site: %v, want ErrOtherSites", err)
+	}
+
+	// the live database is untouched by a rejected restore
+	if got := readMarker(t, live); got != "live" {
+		t.Fatalf("marker = %q", got)
+	}
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// The hash chain links furnace_events in insertion (rowid) order: each row
+// stores the hash of its predecessor in prev_hash and the hash of its own
+// content plus prev_hash in hash. Rows written before the chain was enabled
+// have no hash; the chain starts at the first row that has one. Each site
+// has a chain of its own.
+
+const (
+	lastEventHashSQL = `SELECT hash FROM furnace_events WHERE site_id = ? AND hash IS NOT NULL ORDER BY rowid DESC LIMIT 1`
+
+	insertChainedEventSQL = `
+		INSERT INTO furnace_events (id, occurred_at, type, message, meta, prev_hash, hash, site_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	importChainedEventSQL = `
+		INSERT OR IGNORE INTO furnace_events (id, occurred_at, type, message, meta, prev_hash, hash, site_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	chainRowsSQL = `SELECT id, occurred_at, type, message, meta, prev_hash, hash FROM furnace_events WHERE site_id = ? ORDER BY rowid ASC`
+)
+
+// eventTimeLayout is how occurred_at is stored: RFC 3339 in UTC, to the
+// second. Every value has the same width and zone, so SQLite's text
+// comparison orders them in time; bounds are bound in the same format (see
+// eventTime).
+const eventTimeLayout = "2006-01-02T15:04:05Z"
+
+// eventHashLayout is how occurred_at enters the hash. It is the format rows
+// were stored in before migration 5, kept so their chains still verify.
+const eventHashLayout = "2006-01-02 15:04:05"
+
+// eventTime formats t as stored in occurred_at.
+func eventTime(t time.Time) string {
+	return t.UTC().Format(eventTimeLayout)
+}
+
+// eventHash hashes a row's fields. The fields are JSON-encoded as an array
+// so no two different rows share an input.
+func eventHash(prev, id, occurredAt, typ, message string, meta *string) string {
+	b, _ := json.Marshal([]any{prev, id, occurredAt, typ, message, meta})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// lastEventHash returns the hash at the end of site's chain, or "" before
+// its first chained row.
+func lastEventHash(ctx context.Context, tx *sql.Tx, site string) (string, error) {
+	var h string
+	err := tx.QueryRowContext(ctx, lastEventHashSQL, site).Scan(&h)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return h, err
+}
+
+// chainedRow is an event row with its place in the chain.
+type chainedRow struct {
+	id, occurredAt, typ, message string
+	meta                         *string
+}
+
+// args are the insert arguments for the row after prev, at site.
+func (r chainedRow) args(prev, site string) []any {
+	return []any{r.id, r.occurredAt, r.typ, r.message, r.meta, prev, r.hash(prev), site}
+}
+
+// hash is the row's hash after prev.
+func (r chainedRow) hash(prev string) string {
+	at := r.occurredAt
+	if t, err := time.Parse(eventTimeLayout, at); err == nil {
+		at = t.Format(eventHashLayout)
+	}
+	return eventHash(prev, r.id, at, r.typ, r.message, r.meta)
+}
+
+// appendChained inserts rows at the end of site's chain in one transaction.
+// With ignoreExisting, rows whose ID is already stored are skipped and do
+// not advance the chain. Returns the number of rows inserted. The
+// transaction is retried while the database is locked.
+func appendChained(ctx context.Context, db *sql.DB, site string, rows []chainedRow, ignoreExisting bool) (inserted int, err error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	err = retryBusy(ctx, func() (err error) {
+		inserted, err = appendChainedTx(ctx, db, site, rows, ignoreExisting)
+		return err
+	})
+	return inserted, err
+}
+
+func appendChainedTx(ctx context.Context, db *sql.DB, site string, rows []chainedRow, ignoreExisting bool) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	inserted, err := insertChained(ctx, tx, site, rows, ignoreExisting)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
+
+// insertChained is appendChained within tx, which the caller commits.
+func insertChained(ctx context.Context, tx *sql.Tx, site string, rows []chainedRow, ignoreExisting bool) (int, error) {
+	prev, err := lastEventHash(ctx, tx, site)
+	if err != nil {
+		return 0, err
+	}
+	stmt := insertChainedEventSQL
+	if ignoreExisting {
+		stmt = importChainedEventSQL
+	}
+	inserted := 0
+	for _, row := range rows {
+		args := row.args(prev, site)
+		res, err := tx.ExecContext(ctx, stmt, args...)
+		if err != nil {
+			return 0, err
+		}
+		if k, err := res.RowsAffected(); err == nil && k > 0 {
+			inserted++
+			prev = row.hash(prev)
+		}
+	}
+	return inserted, nil
+}
+
+// VerifyChain walks the site's events in insertion order and reports rows that
+// break the hash chain. After a purge the chain resumes from the hash of
+// the last row it removed.
+func (r *EventSQLite) VerifyChain(ctx context.Context) (models.ChainReport, error) {
+	rep := models.ChainReport{Enabled: r.hashChain, Problems: []models.ChainProblem{}}
+	prev, err := chainAnchor(ctx, r.db, r.site)
+	if err != nil {
+		return rep, err
+	}
+	rows, err := r.db.QueryContext(ctx, chainRowsSQL, r.site)
+	if err != nil {
+		return rep, err
+	}
+	defer rows.Close()
+
+	v := chainVerifier{rep: rep, prev: prev}
+	for rows.Next() {
+		var (
+			row            chainedRow
+			at             time.Time
+			meta, prevHash sql.NullString
+			hash           sql.NullString
+		)
+		if err := rows.Scan(&row.id, &at, &row.typ, &row.message, &meta, &prevHash, &hash); err != nil {
+			return v.rep, err
+		}
+		row.occurredAt = eventTime(at)
+		if meta.Valid {
+			row.meta = &meta.String
+		}
+		v.add(row, at, prevHash, hash)
+	}
+	if err := rows.Err(); err != nil {
+		return v.rep, err
+	}
+	return v.report(), nil
+}
+
+// chainVerifier checks rows against the chain one at a time, in insertion
+// order, starting from the hash in prev.
+type chainVerifier struct {
+	rep     models.ChainReport
+	prev    string
+	started bool
+}
+
+func (v *chainVerifier) add(row chainedRow, at time.Time, prevHash, hash sql.NullString) {
+	kind := ""
+	switch {
+	case !hash.Valid && !v.started:
+		v.rep.Unchained++
+		return
+	case !hash.Valid:
+		kind = models.ChainUnhashed
+	case row.hash(prevHash.String) != hash.String:
+		kind = models.ChainTampered
+	case prevHash.String != v.prev:
+		kind = models.ChainGap
+	}
+	v.started = true
+	v.rep.Checked++
+	if hash.Valid {
+		v.prev = hash.String
+	}
+	if kind != "" {
+		v.rep.Total++
+		if len(v.rep.Problems) < models.MaxChainProblems {
+			v.rep.Problems = append(v.rep.Problems, models.ChainProblem{EventID: row.id, OccurredAt: at.UTC(), Kind: kind})
+		}
+	}
+}
+
+func (v *chainVerifier) report() models.ChainReport {
+	v.rep.Head = v.prev
+	v.rep.Valid = v.rep.Total == 0
+	return v.rep
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestAppend_HashChainLinksToPrevious(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock new: %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	meta := `{"run_id":"run-1"}`
+	want := eventHash("prev", "ev-2", "2025-09-20 10:00:00", "START", "Furnace started", &meta)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lastEventHashSQL)).WithArgs(DefaultSite).
+		WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow("prev"))
+	mock.ExpectExec(regexp.QuoteMeta(insertChainedEventSQL)).
+		WithArgs("ev-2", "2025-09-20T10:00:00Z", "START", "Furnace started", meta, "prev", want, DefaultSite).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	repo := &EventSQLite{db: db, site: DefaultSite, hashChain: true}
+	err = repo.Append(ctx(t), models.FurnaceEvent{
+		EventID:     "ev-2",
+		OccurredAt:  at,
+		Type:        "start",
+		Description: "Furnace started",
+		Metadata:    map[string]any{"run_id": "run-1"},
+	})
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestVerifyChain_ReportsProblems(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock new: %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	ts := at.Format(eventHashLayout)
+	h1 := eventHash("", "e1", ts, "START", "a", nil)
+	h2 := eventHash(h1, "e2", ts, "STOP", "b", nil)
+	h3 := eventHash(h2, "e3", ts, "START", "c", nil)
+	h4 := eventHash(h3, "e4", ts, "STOP", "d", nil)
+	rows := sqlmock.NewRows([]string{"id", "occurred_at", "type", "message", "meta", "prev_hash", "hash"}).
+		AddRow("e0", at, "START", "before the chain", nil, nil, nil).
+		AddRow("e1", at, "START", "a", nil, "", h1).
+		AddRow("e2", at, "STOP", "edited", nil, h1, h2). // content changed
+		// e3 deleted
+		AddRow("e4", at, "STOP", "d", nil, h3, h4).
+		AddRow("e5", at, "ERROR", "bypassed the chain", nil, nil, nil)
+	mock.ExpectQuery(regexp.QuoteMeta(chainAnchorSQL)).WithArgs(DefaultSite).WillReturnRows(sqlmock.NewRows([]string{"chain_anchor"}))
+	mock.ExpectQuery(regexp.QuoteMeta(chainRowsSQL)).WithArgs(DefaultSite).WillReturnRows(rows)
+
+	rep, err := (&EventSQLite{db: db, site: DefaultSite, hashChain: true}).VerifyChain(ctx(t))
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if rep.Valid || !rep.Enabled || rep.Checked != 4 || rep.Unchained != 1 || rep.Head != h4 || rep.Total != 3 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	want := []string{"e2:" + models.ChainTampered, "e4:" + models.ChainGap, "e5:" + models.ChainUnhashed}
+	for i, p := range rep.Problems {
+		if got := p.EventID + ":" + p.Kind; got != want[i] {
+			t.Fatalf("problem %d = %s, want %s", i, got, want[i])
+		}
+	}
+}
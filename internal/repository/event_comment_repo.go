@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"strings"
+)
+
+type EventCommentSQLite struct {
+	db   *sql.DB
+	site string // only events of this site can be commented on
+}
+
+func NewEventCommentSQLite(db *sql.DB) *EventCommentSQLite {
+	return &EventCommentSQLite{db: db, site: DefaultSite}
+}
+
+// Ensure implementation of EventCommentRepo interface at compile time.
+var _ EventCommentRepo = (*EventCommentSQLite)(nil)
+
+const (
+	// insertEventCommentSQL inserts nothing unless the event exists at
+	// the site.
+	insertEventCommentSQL = `
+		INSERT INTO event_comments (event_id, user_id, text, created_at)
+		SELECT ?, ?, ?, ? WHERE EXISTS (SELECT 1 FROM furnace_events WHERE id = ? AND site_id = ?)
+	`
+	eventCommentColumns = `id, event_id, user_id, text, created_at`
+
+	// commentBatchSize bounds the IDs bound to one CommentsFor query.
+	commentBatchSize = 500
+)
+
+func (r *EventCommentSQLite) AddComment(ctx context.Context, c models.EventComment) (int64, error) {
+	res, err := r.db.ExecContext(ctx, insertEventCommentSQL, c.EventID, c.UserID, c.Text, c.CreatedAt.UTC(), c.EventID, r.site)
+	if err != nil {
+		return 0, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = ErrEventNotFound
+		}
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *EventCommentSQLite) CommentsFor(ctx context.Context, eventIDs []string) (map[string][]models.EventComment, error) {
+	out := make(map[string][]models.EventComment)
+	for len(eventIDs) > 0 {
+		batch := eventIDs[:min(len(eventIDs), commentBatchSize)]
+		eventIDs = eventIDs[len(batch):]
+
+		args := make([]any, len(batch))
+		for i, id := range batch {
+			args[i] = id
+		}
+		stmt := `SELECT ` + eventCommentColumns + ` FROM event_comments WHERE event_id IN (?` +
+			strings.Repeat(",?", len(batch)-1) + `) ORDER BY id ASC`
+		if err := r.queryComments(ctx, out, stmt, args); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (r *EventCommentSQLite) queryComments(ctx context.Context, out map[string][]models.EventComment, stmt string, args []any) error {
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.EventComment
+		if err := rows.Scan(&c.ID, &c.EventID, &c.UserID, &c.Text, &c.CreatedAt); err != nil {
+			return err
+		}
+		c.CreatedAt = c.CreatedAt.UTC()
+		out[c.EventID] = append(out[c.EventID], c)
+	}
+	return rows.Err()
+}
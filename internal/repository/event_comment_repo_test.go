@@ -0,0 +1,70 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestEventCommentSQLite_AddCommentNeedsTheEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO event_comments (event_id, user_id, text, created_at)")).
+		WithArgs("e1", 7, "door left open", at, "e1", repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO event_comments")).
+		WithArgs("gone", 7, "door left open", at, "gone", repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repo := repository.NewEventCommentSQLite(db)
+	c := models.EventComment{EventID: "e1", UserID: 7, Text: "door left open", CreatedAt: at}
+	if id, err := repo.AddComment(context.Background(), c); err != nil || id != 3 {
+		t.Fatalf("AddComment() = %d, %v", id, err)
+	}
+	c.EventID = "gone"
+	if _, err := repo.AddComment(context.Background(), c); !errors.Is(err, repository.ErrEventNotFound) {
+		t.Fatalf("AddComment() on a missing event: err %v, want ErrEventNotFound", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestEventCommentSQLite_CommentsForGroupsByEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM event_comments WHERE event_id IN (?,?,?) ORDER BY id ASC")).
+		WithArgs("e1", "e2", "e3").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "event_id", "user_id", "text", "created_at"}).
+			AddRow(1, "e1", 7, "door left open", at).
+			AddRow(2, "e3", 8, "sensor replaced", at).
+			AddRow(3, "e1", 8, "checked the seal", at))
+
+	got, err := repository.NewEventCommentSQLite(db).CommentsFor(context.Background(), []string{"e1", "e2", "e3"})
+	if err != nil {
+		t.Fatalf("CommentsFor(): %v", err)
+	}
+	if len(got) != 2 || len(got["e1"]) != 2 || got["e1"][1].Text != "checked the seal" || got["e3"][0].UserID != 8 {
+		t.Fatalf("CommentsFor() = %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// EventDeletion records who soft-deleted an event, when and why.
+type EventDeletion struct {
+	At     time.Time
+	By     int // user ID
+	Reason string
+}
+
+// EventDeletionRepo hides events holding mistaken data without removing
+// them: the rows, and the hash chain over them, stay for audits. Queries
+// leave soft-deleted events out unless EventQuery.IncludeDeleted is set.
+type EventDeletionRepo interface {
+	// SoftDelete marks the event deleted. It fails with ErrEventNotFound
+	// if eventID is not in the log and with ErrEventDeleted if it was
+	// deleted before.
+	SoftDelete(ctx context.Context, eventID string, d EventDeletion) error
+}
+
+// ErrEventDeleted is returned by SoftDelete for an event already deleted.
+var ErrEventDeleted = errors.New("event already deleted")
+
+// Ensure implementation of EventDeletionRepo interface at compile time.
+var _ EventDeletionRepo = (*EventSQLite)(nil)
+
+const (
+	softDeleteEventSQL = `
+		UPDATE furnace_events SET deleted_at = ?, deleted_by = ?, delete_reason = ?
+		WHERE id = ? AND site_id = ? AND deleted_at IS NULL
+	`
+	eventDeletedSQL = `SELECT deleted_at IS NOT NULL FROM furnace_events WHERE id = ? AND site_id = ?`
+)
+
+func (r *EventSQLite) SoftDelete(ctx context.Context, eventID string, d EventDeletion) error {
+	res, err := r.conn().ExecContext(ctx, softDeleteEventSQL, eventTime(d.At), d.By, d.Reason, eventID, r.site)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	// tell a missing event from one deleted before
+	var deleted bool
+	err = r.conn().QueryRowContext(ctx, eventDeletedSQL, eventID, r.site).Scan(&deleted)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrEventNotFound
+	case err != nil:
+		return err
+	case deleted:
+		return ErrEventDeleted
+	}
+	return nil
+}
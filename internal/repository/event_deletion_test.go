@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestSoftDelete_HidesTheEventButKeepsIt(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	cfg := func() Config { return Config{NewID: seqIDs(), EventHashChain: true} }
+	for name, repos := range backends(t, cfg) {
+		events := []models.FurnaceEvent{
+			{OccurredAt: at, Type: "START"},
+			{OccurredAt: at.Add(time.Minute), Type: "NOTE", Description: "wrong batch number"},
+			{OccurredAt: at.Add(2 * time.Minute), Type: "STOP"},
+		}
+		if err := repos.EventRepo.AppendBatch(ctx, events); err != nil {
+			t.Fatalf("%s: append: %v", name, err)
+		}
+		d := EventDeletion{At: at.Add(time.Hour), By: 3, Reason: "mistaken entry"}
+		if err := repos.Deletions.SoftDelete(ctx, "e2", d); err != nil {
+			t.Fatalf("%s: SoftDelete: %v", name, err)
+		}
+		if err := repos.Deletions.SoftDelete(ctx, "e2", d); !errors.Is(err, ErrEventDeleted) {
+			t.Fatalf("%s: deleting again: %v", name, err)
+		}
+		if err := repos.Deletions.SoftDelete(ctx, "gone", d); !errors.Is(err, ErrEventNotFound) {
+			t.Fatalf("%s: deleting a missing event: %v", name, err)
+		}
+
+		listed, err := repos.EventRepo.Query(ctx, EventQuery{})
+		if ids := eventIDs(listed); err != nil || len(ids) != 2 || ids[0] != "e1" || ids[1] != "e3" {
+			t.Fatalf("%s: listed %v, %v", name, ids, err)
+		}
+		if tail, _, err := repos.Events.Tail(ctx, EventQuery{}, EventTailQuery{AfterID: "e1"}); err != nil || len(tail) != 1 || tail[0].EventID != "e3" {
+			t.Fatalf("%s: tail %v, %v", name, eventIDs(tail), err)
+		}
+		all, err := repos.EventRepo.Query(ctx, EventQuery{IncludeDeleted: true})
+		if err != nil || len(all) != 3 {
+			t.Fatalf("%s: with deleted %v, %v", name, eventIDs(all), err)
+		}
+		if del := all[1]; del.DeletedAt == nil || !del.DeletedAt.Equal(d.At) || del.DeletedBy != 3 || del.DeleteReason != "mistaken entry" {
+			t.Fatalf("%s: deleted event %+v", name, del)
+		}
+		if all[0].DeletedAt != nil {
+			t.Fatalf("%s: e1 marked deleted: %+v", name, all[0])
+		}
+		if rep, err := repos.Chain.VerifyChain(ctx); err != nil || !rep.Valid || rep.Checked != 3 {
+			t.Fatalf("%s: chain %+v, %v", name, rep, err)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"controlling_furnace/internal/models"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,23 +14,113 @@ import (
 )
 
 type EventSQLite struct {
-	db *sql.DB
+	db        *sql.DB
+	tx        *sql.Tx // set inside a unit of work; see UnitOfWorkSQLite
+	site      string  // events of other sites are invisible
+	hashChain bool    // see event_chain.go
+
+	// fill events appended without an ID or timestamp
+	newID func() string
+	now   func() time.Time
 }
 
-func NewEventSQLite(db *sql.DB) *EventSQLite { return &EventSQLite{db: db} }
+func NewEventSQLite(db *sql.DB) *EventSQLite {
+	return &EventSQLite{db: db, site: DefaultSite, newID: uuid.NewString, now: time.Now}
+}
 
 // Append inserts a new event. If EventID or OccurredAt are empty, they’re set.
 func (r *EventSQLite) Append(ctx context.Context, e models.FurnaceEvent) error {
+	row := r.row(e)
+	if r.hashChain {
+		return r.inTx(ctx, func(tx *sql.Tx) error {
+			_, err := insertChained(ctx, tx, r.site, []chainedRow{row}, false)
+			return err
+		})
+	}
+	_, err := r.conn().ExecContext(ctx, insertEventSQL, row.id, row.occurredAt, row.typ, row.message, row.meta, r.site)
+	return err
+}
+
+// AppendBatch inserts events in order in one transaction with a single
+// prepared statement, filling IDs and timestamps like Append. Either all
+// of them are stored or none.
+func (r *EventSQLite) AppendBatch(ctx context.Context, events []models.FurnaceEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	rows := make([]chainedRow, len(events))
+	for i, e := range events {
+		rows[i] = r.row(e)
+	}
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		if r.hashChain {
+			_, err := insertChained(ctx, tx, r.site, rows, false)
+			return err
+		}
+		ps, err := tx.PrepareContext(ctx, insertEventSQL)
+		if err != nil {
+			return err
+		}
+		defer ps.Close()
+		for _, row := range rows {
+			if _, err := ps.ExecContext(ctx, row.id, row.occurredAt, row.typ, row.message, row.meta, r.site); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// conn returns the transaction of the unit of work r runs in, or the
+// database.
+func (r *EventSQLite) conn() dbtx {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// inTx runs fn in the unit of work's transaction, or in a transaction of
+// its own that is committed if fn returns nil, and retried from the start
+// while the database is locked.
+func (r *EventSQLite) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+	return retryBusy(ctx, func() error {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback() }()
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// insertEventSQL stores occurred_at as RFC 3339 in UTC, to the second
+// (eventTimeLayout).
+const insertEventSQL = `
+	INSERT INTO furnace_events (id, occurred_at, type, message, meta, site_id)
+	VALUES (?, ?, ?, ?, ?, ?)
+`
+
+// row fills a missing ID and timestamp and converts e to its stored form.
+func (r *EventSQLite) row(e models.FurnaceEvent) chainedRow {
 	if e.EventID == "" {
-		e.EventID = uuid.NewString()
+		e.EventID = r.newID()
 	}
 	if e.OccurredAt.IsZero() {
-		e.OccurredAt = time.Now().UTC()
-	} else {
-		e.OccurredAt = e.OccurredAt.UTC()
+		e.OccurredAt = r.now()
 	}
+	return eventRow(e)
+}
 
-	// marshal metadata if present
+// eventRow converts e to its stored form. Metadata that cannot be
+// marshalled is dropped.
+func eventRow(e models.FurnaceEvent) chainedRow {
 	var metaPtr *string
 	if e.Metadata != nil {
 		if b, err := json.Marshal(e.Metadata); err == nil {
@@ -36,75 +128,310 @@ func (r *EventSQLite) Append(ctx context.Context, e models.FurnaceEvent) error {
 			metaPtr = &s
 		}
 	}
-
-	// Insert with SQLite TIMESTAMP format "YYYY-MM-DD HH:MM:SS"
-	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO furnace_events (id, occurred_at, type, message, meta)
-		VALUES (?, ?, ?, ?, ?)
-	`,
-		e.EventID,
-		e.OccurredAt.Format("2006-01-02 15:04:05"), // ✅ SQLite TIMESTAMP format
-		strings.ToUpper(strings.TrimSpace(e.Type)),
-		e.Description,
-		metaPtr,
-	)
-
-	return err
+	return chainedRow{
+		id:         e.EventID,
+		occurredAt: eventTime(e.OccurredAt),
+		typ:        strings.ToUpper(strings.TrimSpace(e.Type)),
+		message:    e.Description,
+		meta:       metaPtr,
+	}
 }
 
 // List returns events filtered by [from, to] (inclusive) and/or type, ordered ASC.
 func (r *EventSQLite) List(ctx context.Context, from, to time.Time, typ string) ([]models.FurnaceEvent, error) {
-	var (
-		conds []string
-		args  []any
-	)
+	return r.Query(ctx, EventQuery{From: from, To: to, Type: typ})
+}
 
-	if !from.IsZero() {
-		conds = append(conds, "occurred_at >= ?")
-		args = append(args, from.UTC())
+// Query returns events matching q, ordered ASC.
+func (r *EventSQLite) Query(ctx context.Context, q EventQuery) ([]models.FurnaceEvent, error) {
+	conds, args := eventConds(r.site, q)
+	stmt := `SELECT ` + eventColumns + ` FROM furnace_events WHERE ` + strings.Join(conds, " AND ")
+	// rowid keeps events logged at the same instant in append order
+	stmt += " ORDER BY occurred_at ASC, rowid ASC"
+
+	rows, err := r.conn().QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
 	}
-	if !to.IsZero() {
-		conds = append(conds, "occurred_at <= ?")
-		args = append(args, to.UTC())
+	defer rows.Close()
+
+	out := make([]models.FurnaceEvent, 0, 64)
+	for rows.Next() {
+		ev, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ev)
 	}
-	if typ = strings.ToUpper(strings.TrimSpace(typ)); typ != "" {
-		conds = append(conds, "type = ?")
-		args = append(args, typ)
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
+	return out, nil
+}
+
+// EventPageSize is the number of rows Each reads per query, and the
+// default page size of Page.
+const EventPageSize = 500
+
+// Each calls fn for every event matching q, ordered ASC, and stops at the
+// first error fn returns. Rows are read in pages keyed on (occurred_at,
+// rowid) and the connection is released between pages, so neither memory
+// nor the database is held for the length of a slow consumer.
+func (r *EventSQLite) Each(ctx context.Context, q EventQuery, fn func(models.FurnaceEvent) error) error {
+	return eachPage(ctx, r, q, fn)
+}
 
-	q := `SELECT id, occurred_at, type, message, meta FROM furnace_events`
-	if len(conds) > 0 {
-		q += " WHERE " + strings.Join(conds, " AND ")
+// eachPage implements Each over the pages of s.
+func eachPage(ctx context.Context, s EventStreamRepo, q EventQuery, fn func(models.FurnaceEvent) error) error {
+	p := EventPageQuery{Limit: EventPageSize}
+	for {
+		page, next, err := s.Page(ctx, q, p)
+		if err != nil {
+			return err
+		}
+		for _, ev := range page {
+			if err := fn(ev); err != nil {
+				return err
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		p.After = next
 	}
-	q += " ORDER BY occurred_at ASC"
+}
 
-	rows, err := r.db.QueryContext(ctx, q, args...)
+// Page reads up to p.Limit events matching q that come after p.After in
+// (occurred_at, rowid) order, descending when p.Desc. It returns the key of
+// the last row when the page is full; a short page returns nil.
+func (r *EventSQLite) Page(ctx context.Context, q EventQuery, p EventPageQuery) ([]models.FurnaceEvent, *EventKey, error) {
+	if p.Limit <= 0 {
+		p.Limit = EventPageSize
+	}
+	cmp, dir := ">", "ASC"
+	if p.Desc {
+		cmp, dir = "<", "DESC"
+	}
+	conds, args := eventConds(r.site, q)
+	if p.After != nil {
+		conds = append(conds, "(occurred_at "+cmp+" ? OR (occurred_at = ? AND rowid "+cmp+" ?))")
+		args = append(args, p.After.At, p.After.At, p.After.RowID)
+	}
+	stmt := `SELECT ` + eventColumns + `, rowid, CAST(occurred_at AS TEXT) FROM furnace_events WHERE ` +
+		strings.Join(conds, " AND ")
+	stmt += " ORDER BY occurred_at " + dir + ", rowid " + dir + " LIMIT ?"
+
+	rows, err := r.conn().QueryContext(ctx, stmt, append(args, p.Limit)...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	out := make([]models.FurnaceEvent, 0, 64)
+	var last EventKey
+	page := make([]models.FurnaceEvent, 0, p.Limit)
 	for rows.Next() {
-		var ev models.FurnaceEvent
-		var metaStr sql.NullString
-		if err := rows.Scan(&ev.EventID, &ev.OccurredAt, &ev.Type, &ev.Description, &metaStr); err != nil {
-			return nil, err
+		ev, err := scanEvent(rows, &last.RowID, &last.At)
+		if err != nil {
+			return nil, nil, err
 		}
-		ev.OccurredAt = ev.OccurredAt.UTC()
+		page = append(page, ev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(page) < p.Limit {
+		return page, nil, nil
+	}
+	return page, &last, nil
+}
 
-		if metaStr.Valid && metaStr.String != "" {
-			var v any
-			if err := json.Unmarshal([]byte(metaStr.String), &v); err == nil {
-				ev.Metadata = v
-			} else {
-				ev.Metadata = metaStr.String // keep raw if malformed
-			}
+// ErrEventNotFound is returned by Tail for a cursor event, and by
+// AddComment for a commented event, that is not in the log, e.g. because
+// it was purged.
+var ErrEventNotFound = errors.New("event not found")
+
+// Tail reads by rowid, the order events were appended in, so an event
+// stamped with an earlier occurred_at than ones already returned is not
+// skipped.
+func (r *EventSQLite) Tail(ctx context.Context, q EventQuery, t EventTailQuery) ([]models.FurnaceEvent, string, error) {
+	if t.Limit <= 0 {
+		t.Limit = EventPageSize
+	}
+	var after int64
+	switch {
+	case t.AfterID != "":
+		err := r.conn().QueryRowContext(ctx, `SELECT rowid FROM furnace_events WHERE id = ? AND site_id = ?`, t.AfterID, r.site).Scan(&after)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", ErrEventNotFound
 		}
-		out = append(out, ev)
+		if err != nil {
+			return nil, "", err
+		}
+	case t.AfterAt.IsZero():
+		// start at the end: nothing to return yet, only where to continue
+		var last string
+		err := r.conn().QueryRowContext(ctx, `SELECT id FROM furnace_events WHERE site_id = ? ORDER BY rowid DESC LIMIT 1`, r.site).Scan(&last)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, "", err
+		}
+		return []models.FurnaceEvent{}, last, nil
+	}
+
+	conds, args := eventConds(r.site, q)
+	conds = append(conds, "rowid > ?")
+	args = append(args, after)
+	if !t.AfterAt.IsZero() {
+		conds = append(conds, "occurred_at > ?")
+		args = append(args, eventTime(t.AfterAt))
+	}
+	stmt := `SELECT ` + eventColumns + ` FROM furnace_events WHERE ` +
+		strings.Join(conds, " AND ") + ` ORDER BY rowid ASC LIMIT ?`
+	rows, err := r.conn().QueryContext(ctx, stmt, append(args, t.Limit)...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	events := make([]models.FurnaceEvent, 0, 16)
+	for rows.Next() {
+		ev, err := scanEvent(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		events = append(events, ev)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return out, nil
+	last := t.AfterID
+	if len(events) > 0 {
+		last = events[len(events)-1].EventID
+	}
+	return events, last, nil
+}
+
+// eventConds translates q into WHERE conditions on the events of site and
+// their arguments. Time bounds are compared to the second, the precision
+// occurred_at is stored in.
+func eventConds(site string, q EventQuery) ([]string, []any) {
+	conds := []string{"site_id = ?"}
+	args := []any{site}
+	if !q.IncludeDeleted {
+		conds = append(conds, "deleted_at IS NULL")
+	}
+	if !q.From.IsZero() {
+		conds = append(conds, "occurred_at >= ?")
+		args = append(args, eventTime(q.From))
+	}
+	if !q.To.IsZero() {
+		conds = append(conds, "occurred_at <= ?")
+		args = append(args, eventTime(q.To))
+	}
+	if typ := strings.ToUpper(strings.TrimSpace(q.Type)); typ != "" {
+		conds = append(conds, "type = ?")
+		args = append(args, typ)
+	}
+	if runID := strings.TrimSpace(q.RunID); runID != "" {
+		conds = append(conds, "json_extract(meta, '$.run_id') = ?")
+		args = append(args, runID)
+	}
+	if in := typeArgs(q.Types); len(in) > 0 {
+		conds = append(conds, "type IN ("+placeholders(len(in))+")")
+		args = append(args, in...)
+	}
+	if out := typeArgs(q.ExcludeTypes); len(out) > 0 {
+		conds = append(conds, "type NOT IN ("+placeholders(len(out))+")")
+		args = append(args, out...)
+	}
+	for _, m := range q.Meta {
+		cond, margs := metaCond(m)
+		conds = append(conds, cond)
+		args = append(args, margs...)
+	}
+	return conds, args
+}
+
+// metaCond translates m into a json_extract condition. Only the known
+// ordering operators reach the SQL text; anything else but != compares
+// for equality.
+func metaCond(m MetaFilter) (string, []any) {
+	args := []any{"$." + m.Key}
+	switch m.Op {
+	case "<", "<=", ">", ">=":
+		var v any = m.Value
+		if f, err := strconv.ParseFloat(m.Value, 64); err == nil {
+			v = f
+		}
+		return "json_extract(meta, ?) " + m.Op + " ?", append(args, v)
+	}
+	// JSON numbers and booleans come back as SQL numbers, so the text is
+	// also compared in those forms.
+	vals := []any{m.Value}
+	if f, err := strconv.ParseFloat(m.Value, 64); err == nil {
+		vals = append(vals, f)
+	}
+	switch m.Value {
+	case "true":
+		vals = append(vals, 1)
+	case "false":
+		vals = append(vals, 0)
+	}
+	in := "IN"
+	if m.Op == "!=" {
+		in = "NOT IN"
+	}
+	return "json_extract(meta, ?) " + in + " (" + placeholders(len(vals)) + ")", append(args, vals...)
+}
+
+// typeArgs uppercases types for an IN list, skipping blanks.
+func typeArgs(types []string) []any {
+	var args []any
+	for _, t := range types {
+		if t = strings.ToUpper(strings.TrimSpace(t)); t != "" {
+			args = append(args, t)
+		}
+	}
+	return args
+}
+
+// placeholders returns n comma-separated bind parameters.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// eventColumns are the columns scanEvent reads, in order.
+const eventColumns = `id, occurred_at, type, message, meta, deleted_at, deleted_by, delete_reason`
+
+// scanEvent reads eventColumns, followed by any extra columns into extra.
+func scanEvent(s rowScanner, extra ...any) (models.FurnaceEvent, error) {
+	var (
+		ev                 models.FurnaceEvent
+		metaStr, deletedAt sql.NullString
+		deletedBy          sql.NullInt64
+		deleteReason       sql.NullString
+	)
+	dest := append([]any{&ev.EventID, &ev.OccurredAt, &ev.Type, &ev.Description, &metaStr, &deletedAt, &deletedBy, &deleteReason}, extra...)
+	if err := s.Scan(dest...); err != nil {
+		return models.FurnaceEvent{}, err
+	}
+	ev.OccurredAt = ev.OccurredAt.UTC()
+	if metaStr.Valid {
+		ev.Metadata = decodeEventMeta(metaStr.String)
+	}
+	ev.DeletedAt = parseEventTime(deletedAt)
+	ev.DeletedBy = int(deletedBy.Int64)
+	ev.DeleteReason = deleteReason.String
+	return ev, nil
+}
+
+// decodeEventMeta decodes stored metadata, keeping it raw if malformed.
+func decodeEventMeta(s string) any {
+	if s == "" {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	return v
 }
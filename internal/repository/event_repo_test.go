@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"regexp"
 	"strings"
 	"testing"
@@ -18,7 +19,8 @@ import (
 
 func ctx(t *testing.T) context.Context {
 	t.Helper()
-	c, _ := context.WithTimeout(context.Background(), 3*time.Second)
+	c, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
 	return c
 }
 
@@ -40,13 +42,13 @@ func TestAppend_Success_WithDefaults(t *testing.T) {
 
 	// We don’t know generated id or exact timestamp string, but we can match Exec and argument count.
 	mock.ExpectExec(regexp.QuoteMeta(`
-		INSERT INTO furnace_events (id, occurred_at, type, message, meta)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO furnace_events (id, occurred_at, type, message, meta, site_id)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`)).
 		// accept any args but ensure count is 5; we can also add arg matchers if you want stricter checks
 		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(),
 			"INFO", "hello",
-			sqlmock.AnyArg(),
+			sqlmock.AnyArg(), DefaultSite,
 		).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
@@ -118,11 +120,12 @@ func TestList_NoFilters_And_MetadataParsing(t *testing.T) {
 	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
 	js, _ := json.Marshal(map[string]any{"a": "b"})
 
-	rows := sqlmock.NewRows([]string{"id", "occurred_at", "type", "message", "meta"}).
-		AddRow("1", now, "INFO", "m1", string(js)).
-		AddRow("2", now.Add(time.Hour), "ERROR", "m2", nil)
+	rows := sqlmock.NewRows([]string{"id", "occurred_at", "type", "message", "meta", "deleted_at", "deleted_by", "delete_reason"}).
+		AddRow("1", now, "INFO", "m1", string(js), nil, nil, nil).
+		AddRow("2", now.Add(time.Hour), "ERROR", "m2", nil, nil, nil, nil)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, occurred_at, type, message, meta FROM furnace_events ORDER BY occurred_at ASC`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, occurred_at, type, message, meta, deleted_at, deleted_by, delete_reason FROM furnace_events WHERE site_id = ? AND deleted_at IS NULL ORDER BY occurred_at ASC`)).
+		WithArgs(DefaultSite).
 		WillReturnRows(rows)
 
 	got, err := repo.List(ctx(t), time.Time{}, time.Time{}, "")
@@ -170,14 +173,14 @@ func TestList_WithFilters_OrderAndArgs(t *testing.T) {
 	to := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	typ := " error " // will be normalized to ERROR
 
-	query := `SELECT id, occurred_at, type, message, meta FROM furnace_events WHERE occurred_at >= ? AND occurred_at <= ? AND type = ? ORDER BY occurred_at ASC`
+	query := `SELECT id, occurred_at, type, message, meta, deleted_at, deleted_by, delete_reason FROM furnace_events WHERE site_id = ? AND deleted_at IS NULL AND occurred_at >= ? AND occurred_at <= ? AND type = ? ORDER BY occurred_at ASC`
 
-	rows := sqlmock.NewRows([]string{"id", "occurred_at", "type", "message", "meta"}).
-		AddRow("2", from, "ERROR", "b", nil).
-		AddRow("3", to, "ERROR", "c", nil)
+	rows := sqlmock.NewRows([]string{"id", "occurred_at", "type", "message", "meta", "deleted_at", "deleted_by", "delete_reason"}).
+		AddRow("2", from, "ERROR", "b", nil, nil, nil, nil).
+		AddRow("3", to, "ERROR", "c", nil, nil, nil, nil)
 
 	mock.ExpectQuery(regexp.QuoteMeta(query)).
-		WithArgs(from.UTC(), to.UTC(), "ERROR").
+		WithArgs(DefaultSite, eventTime(from), eventTime(to), "ERROR").
 		WillReturnRows(rows)
 
 	got, err := repo.List(ctx(t), from, to, typ)
@@ -209,11 +212,12 @@ func TestList_ScanError(t *testing.T) {
 
 	repo := NewEventSQLite(db)
 
-	rows := sqlmock.NewRows([]string{"id", "occurred_at", "type", "message", "meta"}).
+	rows := sqlmock.NewRows([]string{"id", "occurred_at", "type", "message", "meta", "deleted_at", "deleted_by", "delete_reason"}).
 		// occurred_at wrong type to force scan error
-		AddRow("x", 123, "INFO", "msg", nil)
+		AddRow("x", 123, "INFO", "msg", nil, nil, nil, nil)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, occurred_at, type, message, meta FROM furnace_events ORDER BY occurred_at ASC`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, occurred_at, type, message, meta, deleted_at, deleted_by, delete_reason FROM furnace_events WHERE site_id = ? AND deleted_at IS NULL ORDER BY occurred_at ASC`)).
+		WithArgs(DefaultSite).
 		WillReturnRows(rows)
 
 	_, err = repo.List(ctx(t), time.Time{}, time.Time{}, "")
@@ -224,3 +228,327 @@ func TestList_ScanError(t *testing.T) {
 		t.Fatalf("mock expectations: %v", err)
 	}
 }
+
+func TestQuery_RunIDFiltersOnMetadata(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock new: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewEventSQLite(db)
+
+	query := `SELECT id, occurred_at, type, message, meta, deleted_at, deleted_by, delete_reason FROM furnace_events WHERE site_id = ? AND deleted_at IS NULL AND type = ? AND json_extract(meta, '$.run_id') = ? ORDER BY occurred_at ASC`
+	rows := sqlmock.NewRows([]string{"id", "occurred_at", "type", "message", "meta", "deleted_at", "deleted_by", "delete_reason"}).
+		AddRow("1", time.Now(), "MODE_CHANGE", "a", `{"run_id":"run-1"}`, nil, nil, nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta(query)).
+		WithArgs(DefaultSite, "MODE_CHANGE", "run-1").
+		WillReturnRows(rows)
+
+	got, err := repo.Query(ctx(t), EventQuery{Type: "mode_change", RunID: " run-1 "})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(got) != 1 || got[0].EventID != "1" {
+		t.Fatalf("unexpected results: %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("mock expectations: %v", err)
+	}
+}
+
+func TestQuery_MetaFiltersCompareTextAndNumbers(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock new: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewEventSQLite(db)
+
+	query := `SELECT id, occurred_at, type, message, meta, deleted_at, deleted_by, delete_reason FROM furnace_events WHERE site_id = ? AND deleted_at IS NULL AND json_extract(meta, ?) IN (?) AND json_extract(meta, ?) > ? AND json_extract(meta, ?) NOT IN (?,?) ORDER BY occurred_at ASC`
+	rows := sqlmock.NewRows([]string{"id", "occurred_at", "type", "message", "meta", "deleted_at", "deleted_by", "delete_reason"}).
+		AddRow("1", time.Now(), "MODE_CHANGE", "a", `{"to":"COOL","temp_c":1010}`, nil, nil, nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta(query)).
+		WithArgs(DefaultSite, "$.to", "COOL", "$.temp_c", 1000.0, "$.limits.max_c", "1200", 1200.0).
+		WillReturnRows(rows)
+
+	got, err := repo.Query(ctx(t), EventQuery{Meta: []MetaFilter{
+		{Key: "to", Op: "=", Value: "COOL"},
+		{Key: "temp_c", Op: ">", Value: "1000"},
+		{Key: "limits.max_c", Op: "!=", Value: "1200"},
+	}})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(got) != 1 || got[0].EventID != "1" {
+		t.Fatalf("unexpected results: %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("mock expectations: %v", err)
+	}
+}
+
+func TestAppend_UsesConfiguredIDAndClock(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock new: %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	repo := NewRepositoryWithConfig(db, Config{
+		NewID: func() string { return "edge-1:42" },
+		Clock: func() time.Time { return at },
+	}).EventRepo
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO furnace_events")).
+		WithArgs("edge-1:42", "2025-09-20T10:00:00Z", "INFO", "hello", nil, DefaultSite).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.Append(ctx(t), models.FurnaceEvent{Type: "INFO", Description: "hello"}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestEach_PagesOnOccurredAtAndRowID(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock new: %v", err)
+	}
+	defer db.Close()
+	repo := &EventSQLite{db: db, site: DefaultSite}
+
+	cols := []string{"id", "occurred_at", "type", "message", "meta", "deleted_at", "deleted_by", "delete_reason", "rowid", "occurred_at"}
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	first := sqlmock.NewRows(cols)
+	for i := 1; i <= EventPageSize; i++ {
+		first.AddRow(fmt.Sprint(i), at, "TELEMETRY", "tick", nil, nil, nil, nil, int64(i), "2025-09-20T10:00:00Z")
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM furnace_events WHERE site_id = ? AND deleted_at IS NULL AND type = ? ORDER BY occurred_at ASC, rowid ASC LIMIT ?")).
+		WithArgs(DefaultSite, "TELEMETRY", EventPageSize).
+		WillReturnRows(first)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE site_id = ? AND deleted_at IS NULL AND type = ? AND (occurred_at > ? OR (occurred_at = ? AND rowid > ?)) ORDER BY")).
+		WithArgs(DefaultSite, "TELEMETRY", "2025-09-20T10:00:00Z", "2025-09-20T10:00:00Z", int64(EventPageSize), EventPageSize).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow("last", at, "TELEMETRY", "tick", `{"run_id":"run-1"}`, nil, nil, nil, int64(EventPageSize+1), "2025-09-20T10:00:00Z"))
+
+	var got []models.FurnaceEvent
+	err = repo.Each(ctx(t), EventQuery{Type: "telemetry"}, func(ev models.FurnaceEvent) error {
+		got = append(got, ev)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Each: %v", err)
+	}
+	if len(got) != EventPageSize+1 || got[len(got)-1].EventID != "last" || got[len(got)-1].Metadata == nil {
+		t.Fatalf("expected %d events ending with 'last', got %d", EventPageSize+1, len(got))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("mock expectations: %v", err)
+	}
+}
+
+func TestEach_StopsOnCallbackError(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock new: %v", err)
+	}
+	defer db.Close()
+	repo := &EventSQLite{db: db, site: DefaultSite}
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM furnace_events WHERE site_id = ? AND deleted_at IS NULL ORDER BY occurred_at ASC, rowid ASC LIMIT ?")).
+		WithArgs(DefaultSite, EventPageSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "occurred_at", "type", "message", "meta", "deleted_at", "deleted_by", "delete_reason", "rowid", "occurred_at"}).
+			AddRow("1", at, "START", "s", nil, nil, nil, nil, int64(1), "2025-09-20T10:00:00Z").
+			AddRow("2", at, "STOP", "s", nil, nil, nil, nil, int64(2), "2025-09-20T10:00:00Z"))
+
+	stop := errors.New("client gone")
+	calls := 0
+	err = repo.Each(ctx(t), EventQuery{}, func(models.FurnaceEvent) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("expected to stop after the first event, got err=%v calls=%d", err, calls)
+	}
+}
+
+func TestPage_DescendingAfterKey(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock new: %v", err)
+	}
+	defer db.Close()
+	repo := &EventSQLite{db: db, site: DefaultSite}
+
+	cols := []string{"id", "occurred_at", "type", "message", "meta", "deleted_at", "deleted_by", "delete_reason", "rowid", "occurred_at"}
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE site_id = ? AND deleted_at IS NULL AND (occurred_at < ? OR (occurred_at = ? AND rowid < ?)) ORDER BY occurred_at DESC, rowid DESC LIMIT ?")).
+		WithArgs(DefaultSite, "2025-09-20T10:00:01Z", "2025-09-20T10:00:01Z", int64(9), 2).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow("8", at, "START", "s", nil, nil, nil, nil, int64(8), "2025-09-20T10:00:00Z").
+			AddRow("7", at, "STOP", "s", nil, nil, nil, nil, int64(7), "2025-09-20T10:00:00Z"))
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY occurred_at DESC, rowid DESC LIMIT ?")).
+		WithArgs(DefaultSite, "2025-09-20T10:00:00Z", "2025-09-20T10:00:00Z", int64(7), 2).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow("6", at, "START", "s", nil, nil, nil, nil, int64(6), "2025-09-20T10:00:00Z"))
+
+	page, next, err := repo.Page(ctx(t), EventQuery{}, EventPageQuery{
+		After: &EventKey{At: "2025-09-20T10:00:01Z", RowID: 9},
+		Limit: 2,
+		Desc:  true,
+	})
+	if err != nil {
+		t.Fatalf("Page: %v", err)
+	}
+	if len(page) != 2 || page[0].EventID != "8" {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	if next == nil || *next != (EventKey{At: "2025-09-20T10:00:00Z", RowID: 7}) {
+		t.Fatalf("next = %+v, want key of the last row", next)
+	}
+
+	page, next, err = repo.Page(ctx(t), EventQuery{}, EventPageQuery{After: next, Limit: 2, Desc: true})
+	if err != nil {
+		t.Fatalf("Page: %v", err)
+	}
+	if len(page) != 1 || next != nil {
+		t.Fatalf("short page: got %d events, next=%+v; want 1 and nil", len(page), next)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("mock expectations: %v", err)
+	}
+}
+
+func TestQuery_TypeListsUseInClauses(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock new: %v", err)
+	}
+	defer db.Close()
+	repo := &EventSQLite{db: db, site: DefaultSite}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM furnace_events WHERE site_id = ? AND deleted_at IS NULL AND type IN (?,?) AND type NOT IN (?) ORDER BY occurred_at ASC")).
+		WithArgs(DefaultSite, "START", "STOP", "TELEMETRY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "occurred_at", "type", "message", "meta", "deleted_at", "deleted_by", "delete_reason"}))
+
+	if _, err := repo.Query(ctx(t), EventQuery{Types: []string{"start", " ", "stop"}, ExcludeTypes: []string{"telemetry"}}); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("mock expectations: %v", err)
+	}
+}
+
+func TestAppendBatch_OneTransactionAndPreparedStatement(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock new: %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	repo := NewEventSQLite(db)
+	repo.newID = func() string { return "gen" }
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO furnace_events"))
+	prep.ExpectExec().WithArgs("e1", "2025-09-20T10:00:00Z", "MODE_CHANGE", "to COOL", `{"to":"COOL"}`, DefaultSite).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	prep.ExpectExec().WithArgs("gen", "2025-09-20T10:00:00Z", "ERROR", "Overheat detected", nil, DefaultSite).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+	// a failed insert stores none of the batch
+	mock.ExpectBegin()
+	prep = mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO furnace_events"))
+	prep.ExpectExec().WillReturnError(errors.New("disk I/O error"))
+	mock.ExpectRollback()
+
+	err = repo.AppendBatch(ctx(t), []models.FurnaceEvent{
+		{EventID: "e1", OccurredAt: at, Type: "mode_change", Description: "to COOL", Metadata: map[string]any{"to": "COOL"}},
+		{OccurredAt: at, Type: "ERROR", Description: "Overheat detected"},
+	})
+	if err != nil {
+		t.Fatalf("AppendBatch() error = %v", err)
+	}
+	if err := repo.AppendBatch(ctx(t), []models.FurnaceEvent{{Type: "INFO"}}); err == nil {
+		t.Fatalf("expected the insert error")
+	}
+	if err := repo.AppendBatch(ctx(t), nil); err != nil {
+		t.Fatalf("AppendBatch(nil) error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTail_FollowsRowIDAfterTheCursor(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock new: %v", err)
+	}
+	defer db.Close()
+	repo := &EventSQLite{db: db, site: DefaultSite}
+
+	cols := []string{"id", "occurred_at", "type", "message", "meta", "deleted_at", "deleted_by", "delete_reason"}
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM furnace_events WHERE site_id = ? ORDER BY rowid DESC LIMIT 1")).
+		WithArgs(DefaultSite).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("e7"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT rowid FROM furnace_events WHERE id = ? AND site_id = ?")).
+		WithArgs("e7", DefaultSite).
+		WillReturnRows(sqlmock.NewRows([]string{"rowid"}).AddRow(int64(7)))
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE site_id = ? AND deleted_at IS NULL AND type IN (?) AND rowid > ? ORDER BY rowid ASC LIMIT ?")).
+		WithArgs(DefaultSite, "ERROR", int64(7), 50).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow("e8", at, "ERROR", "Overheat detected", nil, nil, nil, nil).
+			AddRow("e9", at, "ERROR", "Sensor fault", nil, nil, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT rowid FROM furnace_events WHERE id = ? AND site_id = ?")).
+		WithArgs("gone", DefaultSite).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE site_id = ? AND deleted_at IS NULL AND rowid > ? AND occurred_at > ? ORDER BY rowid ASC LIMIT ?")).
+		WithArgs(DefaultSite, int64(0), "2025-09-20T10:00:00Z", EventPageSize).
+		WillReturnRows(sqlmock.NewRows(cols))
+
+	events, last, err := repo.Tail(ctx(t), EventQuery{}, EventTailQuery{})
+	if err != nil || len(events) != 0 || last != "e7" {
+		t.Fatalf("from the end: events %v, last %q, err %v; want none and e7", events, last, err)
+	}
+	events, last, err = repo.Tail(ctx(t), EventQuery{Types: []string{"error"}}, EventTailQuery{AfterID: last, Limit: 50})
+	if err != nil || len(events) != 2 || last != "e9" {
+		t.Fatalf("after e7: events %v, last %q, err %v; want e8, e9", events, last, err)
+	}
+	if _, _, err = repo.Tail(ctx(t), EventQuery{}, EventTailQuery{AfterID: "gone"}); !errors.Is(err, ErrEventNotFound) {
+		t.Fatalf("unknown id: err %v, want ErrEventNotFound", err)
+	}
+	events, last, err = repo.Tail(ctx(t), EventQuery{}, EventTailQuery{AfterAt: at})
+	if err != nil || len(events) != 0 || last != "" {
+		t.Fatalf("after_ts: events %v, last %q, err %v", events, last, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("mock expectations: %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Retention removes a prefix of the log in insertion (rowid) order, so the
+// hash chain over the remaining rows stays contiguous. Each purge records
+// the hash of the last row it deleted; VerifyChain starts from that anchor
+// instead of reporting the first remaining row as a gap. An event imported
+// with an old timestamp after newer ones is therefore kept until every row
+// inserted before it has expired too. Only the events of the repository's
+// site are considered.
+
+const (
+	// ageBoundarySQL is the first rowid at or after the cutoff; rows below
+	// it are all older. With no such row, everything is older.
+	ageBoundarySQL = `
+		SELECT COALESCE(
+			(SELECT MIN(rowid) FROM furnace_events WHERE site_id = ? AND occurred_at >= ?),
+			(SELECT COALESCE(MAX(rowid), 0) + 1 FROM furnace_events))
+	`
+	// rowsBoundarySQL is the rowid of the oldest of the newest n rows.
+	rowsBoundarySQL = `SELECT rowid FROM furnace_events WHERE site_id = ? ORDER BY rowid DESC LIMIT 1 OFFSET ?`
+
+	purgeRangeSQL  = `SELECT COUNT(*), MIN(CAST(occurred_at AS TEXT)), MAX(CAST(occurred_at AS TEXT)) FROM furnace_events WHERE site_id = ? AND rowid < ?`
+	purgeRowsSQL   = `SELECT ` + eventColumns + ` FROM furnace_events WHERE site_id = ? AND rowid < ? ORDER BY rowid ASC`
+	purgeAnchorSQL = `SELECT hash FROM furnace_events WHERE site_id = ? AND rowid < ? AND hash IS NOT NULL ORDER BY rowid DESC LIMIT 1`
+	deletePurgeSQL = `DELETE FROM furnace_events WHERE site_id = ? AND rowid < ?`
+	// deletePurgeCommentsSQL removes the comments on the purged events.
+	deletePurgeCommentsSQL = `DELETE FROM event_comments WHERE event_id IN (SELECT id FROM furnace_events WHERE site_id = ? AND rowid < ?)`
+
+	insertPurgeSQL = `
+		INSERT INTO event_purges (purged_at, deleted, chain_anchor, archive, site_id)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	chainAnchorSQL = `SELECT chain_anchor FROM event_purges WHERE site_id = ? AND chain_anchor IS NOT NULL ORDER BY id DESC LIMIT 1`
+)
+
+// EventPurge selects the oldest events to remove. At least one limit must
+// be set; when both are, rows breaking either are removed.
+type EventPurge struct {
+	Before  time.Time // remove events older than this
+	KeepMax int       // keep at most this many of the newest events
+	DryRun  bool      // report the selection without deleting
+	// Archive names the archive the rows are written to; it is recorded
+	// with the purge.
+	Archive string
+}
+
+// EventArchive receives purged events before they are deleted. Close is
+// called before the deletion commits; an error from either aborts the purge.
+type EventArchive interface {
+	Write(ev models.FurnaceEvent) error
+	Close() error
+}
+
+// EventRetentionRepo removes expired events.
+type EventRetentionRepo interface {
+	// Purge deletes the events selected by p, first copying them to archive
+	// when it is non-nil. Dry runs leave archive untouched.
+	Purge(ctx context.Context, p EventPurge, archive EventArchive) (models.PurgeReport, error)
+}
+
+// ErrNoPurgeLimit is returned for a purge without an age or row limit.
+var ErrNoPurgeLimit = errors.New("purge needs an age or row limit")
+
+func (r *EventSQLite) Purge(ctx context.Context, p EventPurge, archive EventArchive) (models.PurgeReport, error) {
+	rep := models.PurgeReport{DryRun: p.DryRun}
+	if p.Before.IsZero() && p.KeepMax <= 0 {
+		return rep, ErrNoPurgeLimit
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return rep, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	bound, err := purgeBoundary(ctx, tx, r.site, p)
+	if err != nil {
+		return rep, err
+	}
+	var oldest, newest sql.NullString
+	if err := tx.QueryRowContext(ctx, purgeRangeSQL, r.site, bound).Scan(&rep.Deleted, &oldest, &newest); err != nil {
+		return rep, err
+	}
+	if rep.Deleted == 0 {
+		return rep, nil
+	}
+	rep.Oldest, rep.Newest = parseEventTime(oldest), parseEventTime(newest)
+	if p.DryRun {
+		return rep, nil
+	}
+
+	if archive != nil {
+		if err := archiveRows(ctx, tx, r.site, bound, archive); err != nil {
+			return rep, err
+		}
+		rep.Archive = p.Archive
+	}
+	var anchor sql.NullString
+	if err := tx.QueryRowContext(ctx, purgeAnchorSQL, r.site, bound).Scan(&anchor); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return rep, err
+	}
+	if _, err := tx.ExecContext(ctx, deletePurgeCommentsSQL, r.site, bound); err != nil {
+		return rep, err
+	}
+	if _, err := tx.ExecContext(ctx, deletePurgeSQL, r.site, bound); err != nil {
+		return rep, err
+	}
+	if _, err := tx.ExecContext(ctx, insertPurgeSQL, r.now().UTC(), rep.Deleted, anchor, rep.Archive, r.site); err != nil {
+		return rep, err
+	}
+	return rep, tx.Commit()
+}
+
+// purgeBoundary returns the rowid below which p removes every row of site.
+func purgeBoundary(ctx context.Context, tx *sql.Tx, site string, p EventPurge) (int64, error) {
+	var bound int64
+	if !p.Before.IsZero() {
+		if err := tx.QueryRowContext(ctx, ageBoundarySQL, site, eventTime(p.Before)).Scan(&bound); err != nil {
+			return 0, err
+		}
+	}
+	if p.KeepMax > 0 {
+		var keep int64
+		err := tx.QueryRowContext(ctx, rowsBoundarySQL, site, p.KeepMax-1).Scan(&keep)
+		switch {
+		case errors.Is(err, sql.ErrNoRows): // fewer rows than the limit
+		case err != nil:
+			return 0, err
+		case keep > bound:
+			bound = keep
+		}
+	}
+	return bound, nil
+}
+
+// archiveRows writes the rows of site below bound to archive and closes it.
+func archiveRows(ctx context.Context, tx *sql.Tx, site string, bound int64, archive EventArchive) error {
+	rows, err := tx.QueryContext(ctx, purgeRowsSQL, site, bound)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		ev, err := scanEvent(rows)
+		if err != nil {
+			return err
+		}
+		if err := archive.Write(ev); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// chainAnchor returns the hash of the last chained row of site removed by
+// a purge, or "" if none was.
+func chainAnchor(ctx context.Context, db *sql.DB, site string) (string, error) {
+	var h string
+	err := db.QueryRowContext(ctx, chainAnchorSQL, site).Scan(&h)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return h, err
+}
+
+// parseEventTime parses a stored occurred_at; nil when unset or malformed.
+func parseEventTime(s sql.NullString) *time.Time {
+	if !s.Valid {
+		return nil
+	}
+	for _, layout := range []string{eventTimeLayout, eventHashLayout, time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00"} {
+		if t, err := time.Parse(layout, s.String); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
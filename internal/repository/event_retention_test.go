@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type sliceArchive struct {
+	events []models.FurnaceEvent
+	closed bool
+}
+
+func (a *sliceArchive) Write(ev models.FurnaceEvent) error {
+	a.events = append(a.events, ev)
+	return nil
+}
+
+func (a *sliceArchive) Close() error {
+	a.closed = true
+	return nil
+}
+
+func TestPurge_ArchivesAndRecordsAnchor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock new: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	before := now.Add(-time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(ageBoundarySQL)).WithArgs(DefaultSite, eventTime(before)).
+		WillReturnRows(sqlmock.NewRows([]string{"b"}).AddRow(3))
+	// the row limit removes more than the age limit
+	mock.ExpectQuery(regexp.QuoteMeta(rowsBoundarySQL)).WithArgs(DefaultSite, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"rowid"}).AddRow(4))
+	mock.ExpectQuery(regexp.QuoteMeta(purgeRangeSQL)).WithArgs(DefaultSite, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"n", "min", "max"}).AddRow(2, "2025-09-01T08:00:00Z", "2025-09-20T09:30:00Z"))
+	mock.ExpectQuery(regexp.QuoteMeta(purgeRowsSQL)).WithArgs(DefaultSite, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "occurred_at", "type", "message", "meta", "deleted_at", "deleted_by", "delete_reason"}).
+			AddRow("e1", before, "START", "a", nil, nil, nil, nil).
+			AddRow("e2", before, "STOP", "b", nil, nil, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta(purgeAnchorSQL)).WithArgs(DefaultSite, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow("h2"))
+	mock.ExpectExec(regexp.QuoteMeta(deletePurgeCommentsSQL)).WithArgs(DefaultSite, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(deletePurgeSQL)).WithArgs(DefaultSite, 4).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(insertPurgeSQL)).WithArgs(now, int64(2), "h2", "a.ndjson.gz", DefaultSite).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	archive := &sliceArchive{}
+	repo := &EventSQLite{db: db, site: DefaultSite, now: func() time.Time { return now }}
+	rep, err := repo.Purge(ctx(t), EventPurge{Before: before, KeepMax: 2, Archive: "a.ndjson.gz"}, archive)
+	if err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if rep.Deleted != 2 || rep.Archive != "a.ndjson.gz" || rep.Oldest == nil || !rep.Oldest.Equal(time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	if len(archive.events) != 2 || !archive.closed {
+		t.Fatalf("archive got %d events, closed=%v", len(archive.events), archive.closed)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestPurge_DryRunDeletesNothing(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock new: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(rowsBoundarySQL)).WithArgs(DefaultSite, 99).
+		WillReturnRows(sqlmock.NewRows([]string{"rowid"}).AddRow(51))
+	mock.ExpectQuery(regexp.QuoteMeta(purgeRangeSQL)).WithArgs(DefaultSite, 51).
+		WillReturnRows(sqlmock.NewRows([]string{"n", "min", "max"}).AddRow(50, "2025-09-01 08:00:00", "2025-09-02 08:00:00"))
+	mock.ExpectRollback()
+
+	archive := &sliceArchive{}
+	rep, err := (&EventSQLite{db: db, site: DefaultSite}).Purge(ctx(t), EventPurge{KeepMax: 100, DryRun: true}, archive)
+	if err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if !rep.DryRun || rep.Deleted != 50 || archive.closed {
+		t.Fatalf("report %+v, archive closed=%v", rep, archive.closed)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestPurge_NeedsALimit(t *testing.T) {
+	if _, err := (&EventSQLite{}).Purge(ctx(t), EventPurge{DryRun: true}, nil); !errors.Is(err, ErrNoPurgeLimit) {
+		t.Fatalf("err = %v, want ErrNoPurgeLimit", err)
+	}
+}
+
+func TestVerifyChain_ResumesAfterPurge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock new: %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	ts := at.Format(eventHashLayout)
+	h3 := eventHash("h2", "e3", ts, "START", "c", nil)
+	mock.ExpectQuery(regexp.QuoteMeta(chainAnchorSQL)).WithArgs(DefaultSite).
+		WillReturnRows(sqlmock.NewRows([]string{"chain_anchor"}).AddRow("h2"))
+	mock.ExpectQuery(regexp.QuoteMeta(chainRowsSQL)).WithArgs(DefaultSite).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "occurred_at", "type", "message", "meta", "prev_hash", "hash"}).
+			AddRow("e3", at, "START", "c", nil, "h2", h3))
+
+	rep, err := (&EventSQLite{db: db, site: DefaultSite, hashChain: true}).VerifyChain(ctx(t))
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if !rep.Valid || rep.Checked != 1 || rep.Head != h3 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+}
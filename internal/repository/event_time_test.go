@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository/db"
+)
+
+func TestEventSQLite_TimesRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.InitDB(filepath.Join(t.TempDir(), "furnace.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	repos := NewRepositoryWithConfig(conn, Config{NewID: seqIDs(), EventHashChain: true})
+
+	// stored to the second in UTC, whatever zone it was stamped in
+	at := time.Date(2025, 9, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	if err := repos.EventRepo.Append(ctx, models.FurnaceEvent{OccurredAt: at.Add(400 * time.Millisecond), Type: "START"}); err != nil {
+		t.Fatal(err)
+	}
+	var stored string
+	if err := conn.QueryRow(`SELECT occurred_at FROM furnace_events`).Scan(&stored); err != nil || stored != "2025-09-01T10:00:00Z" {
+		t.Fatalf("stored occurred_at = %q, %v", stored, err)
+	}
+	events, err := repos.EventRepo.Query(ctx, EventQuery{})
+	if err != nil || len(events) != 1 || !events[0].OccurredAt.Equal(at) || events[0].OccurredAt.Location() != time.UTC {
+		t.Fatalf("read back %v, %v; want %v in UTC", events, err, at)
+	}
+
+	// bounds on the event's own second include it, in any zone
+	for _, tc := range []struct {
+		q    EventQuery
+		want int
+	}{
+		{EventQuery{From: at, To: at}, 1},
+		{EventQuery{From: at.UTC(), To: at.UTC()}, 1},
+		{EventQuery{From: at.Add(time.Second)}, 0},
+		{EventQuery{To: at.Add(-time.Second)}, 0},
+	} {
+		if got, err := repos.EventRepo.Query(ctx, tc.q); err != nil || len(got) != tc.want {
+			t.Fatalf("query %+v: %d events, %v; want %d", tc.q, len(got), err, tc.want)
+		}
+	}
+	if tail, _, err := repos.Events.Tail(ctx, EventQuery{}, EventTailQuery{AfterAt: at.Add(-time.Second)}); err != nil || len(tail) != 1 {
+		t.Fatalf("tail after the previous second: %v, %v", tail, err)
+	}
+	if rep, err := repos.Retention.Purge(ctx, EventPurge{Before: at, DryRun: true}, nil); err != nil || rep.Deleted != 0 {
+		t.Fatalf("purge before the event: %+v, %v", rep, err)
+	}
+	if rep, err := repos.Retention.Purge(ctx, EventPurge{Before: at.Add(time.Second), DryRun: true}, nil); err != nil || rep.Deleted != 1 {
+		t.Fatalf("purge after the event: %+v, %v", rep, err)
+	}
+	if rep, err := repos.Chain.VerifyChain(ctx); err != nil || !rep.Valid || rep.Checked != 1 {
+		t.Fatalf("chain: %+v, %v", rep, err)
+	}
+}
+
+func TestEventSQLite_MigratesLegacyTimes(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.InitDB(filepath.Join(t.TempDir(), "furnace.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	// back to before migration 5
+	if _, err := db.MigrateDown(ctx, conn, db.LatestVersion()-4); err != nil {
+		t.Fatal(err)
+	}
+	// unchained rows bound as time.Time, which the driver stores in Go's
+	// String format, then a chained row as the repository wrote it
+	for id, at := range map[string]time.Time{
+		"e2": time.Date(2025, 9, 1, 10, 0, 1, 500_000_000, time.UTC),
+		"e3": time.Date(2025, 9, 1, 12, 0, 2, 0, time.FixedZone("CEST", 2*60*60)),
+	} {
+		if _, err := conn.Exec(`INSERT INTO furnace_events (id, occurred_at, type, message) VALUES (?, ?, 'NOTE', '')`, id, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	legacy := "2025-09-01 10:00:00"
+	hash := eventHash("", "e1", legacy, "START", "", nil)
+	if _, err := conn.Exec(`INSERT INTO furnace_events (id, occurred_at, type, message, prev_hash, hash) VALUES ('e1', ?, 'START', '', '', ?)`, legacy, hash); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Migrate(ctx, conn); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	want := map[string]string{"e1": "2025-09-01T10:00:00Z", "e2": "2025-09-01T10:00:01Z", "e3": "2025-09-01T10:00:02Z"}
+	for id, ts := range want {
+		var stored string
+		if err := conn.QueryRow(`SELECT occurred_at FROM furnace_events WHERE id = ?`, id).Scan(&stored); err != nil || stored != ts {
+			t.Fatalf("%s: occurred_at = %q, %v; want %q", id, stored, err, ts)
+		}
+	}
+	rep, err := NewRepository(conn).Chain.VerifyChain(ctx)
+	if err != nil || !rep.Valid || rep.Checked != 1 || rep.Unchained != 2 {
+		t.Fatalf("chain after migrating: %+v, %v", rep, err)
+	}
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type HealthSQLite struct {
+	db   *sql.DB
+	site string // whose row to read and write
+}
+
+func NewHealthSQLite(db *sql.DB) *HealthSQLite { return &HealthSQLite{db: db, site: DefaultSite} }
+
+// Ensure implementation of HealthRepo interface at compile time.
+var _ HealthRepo = (*HealthSQLite)(nil)
+
+const (
+	upsertHealthSQL = `
+		INSERT INTO furnace_health (site_id, heating_s, cycles, last_run_id, maintenance_due, updated_at, replaced_heating_s, replaced_cycles)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(site_id) DO UPDATE SET
+			heating_s=excluded.heating_s,
+			cycles=excluded.cycles,
+			last_run_id=excluded.last_run_id,
+			maintenance_due=excluded.maintenance_due,
+			updated_at=excluded.updated_at,
+			replaced_heating_s=excluded.replaced_heating_s,
+			replaced_cycles=excluded.replaced_cycles
+	`
+	selectHealthSQL = `SELECT heating_s, cycles, last_run_id, maintenance_due, updated_at, replaced_heating_s, replaced_cycles
+		FROM furnace_health WHERE site_id=?`
+)
+
+// Save stores the wear counters of h as the site's health row.
+func (r *HealthSQLite) Save(ctx context.Context, h models.FurnaceHealth) error {
+	updated := h.UpdatedAt
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	_, err := r.db.ExecContext(ctx, upsertHealthSQL,
+		r.site,
+		h.HeatingSeconds,
+		h.Cycles,
+		h.LastRunID,
+		h.MaintenanceDue,
+		updated.UTC(),
+		h.ReplacedHeatingSeconds,
+		h.ReplacedCycles,
+	)
+	return err
+}
+
+// Load returns the stored wear counters, or a zero value if none were saved.
+func (r *HealthSQLite) Load(ctx context.Context) (models.FurnaceHealth, error) {
+	var h models.FurnaceHealth
+	err := r.db.QueryRowContext(ctx, selectHealthSQL, r.site).Scan(
+		&h.HeatingSeconds,
+		&h.Cycles,
+		&h.LastRunID,
+		&h.MaintenanceDue,
+		&h.UpdatedAt,
+		&h.ReplacedHeatingSeconds,
+		&h.ReplacedCycles,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.FurnaceHealth{}, nil
+	}
+	if err != nil {
+		return models.FurnaceHealth{}, err
+	}
+	h.UpdatedAt = h.UpdatedAt.UTC()
+	return h, nil
+}
@@ -0,0 +1,61 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestHealthSQLite_SaveAndLoad(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	h := models.FurnaceHealth{HeatingSeconds: 7200, Cycles: 3, LastRunID: "run-3", MaintenanceDue: true, UpdatedAt: at,
+		ReplacedHeatingSeconds: 3600, ReplacedCycles: 1}
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO furnace_health")).
+		WithArgs(repository.DefaultSite, 7200.0, 3, "run-3", true, at, 3600.0, 1).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM furnace_health WHERE site_id=?")).
+		WithArgs(repository.DefaultSite).
+		WillReturnRows(sqlmock.NewRows([]string{"heating_s", "cycles", "last_run_id", "maintenance_due", "updated_at", "replaced_heating_s", "replaced_cycles"}).
+			AddRow(7200.0, 3, "run-3", true, at, 3600.0, 1))
+
+	repo := repository.NewHealthSQLite(db)
+	if err := repo.Save(context.Background(), h); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := repo.Load(context.Background())
+	if err != nil || got != h {
+		t.Fatalf("Load() = %+v, %v", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestHealthSQLite_LoadMissingReturnsZero(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM furnace_health")).
+		WillReturnRows(sqlmock.NewRows([]string{"heating_s", "cycles", "last_run_id", "maintenance_due", "updated_at", "replaced_heating_s", "replaced_cycles"}))
+
+	got, err := repository.NewHealthSQLite(db).Load(context.Background())
+	if err != nil || got != (models.FurnaceHealth{}) {
+		t.Fatalf("Load() = %+v, %v", got, err)
+	}
+}
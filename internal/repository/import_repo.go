@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"time"
+)
+
+// ImportSQLite bulk-loads historical records, skipping ones already stored.
+type ImportSQLite struct {
+	db        *sql.DB
+	site      string // records are imported into this site's log and telemetry
+	hashChain bool   // imported events extend the event hash chain
+}
+
+func NewImportSQLite(db *sql.DB) *ImportSQLite { return &ImportSQLite{db: db, site: DefaultSite} }
+
+// Ensure implementation of ImportRepo interface at compile time.
+var _ ImportRepo = (*ImportSQLite)(nil)
+
+const (
+	importEventSQL = `
+		INSERT OR IGNORE INTO furnace_events (id, occurred_at, type, message, meta, site_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	// A sample is a duplicate if its channel already has one at that
+	// instant on the site.
+	importTelemetrySQL = `
+		INSERT INTO telemetry (ts, channel, value, site_id)
+		SELECT ?, ?, ?, ?
+		WHERE NOT EXISTS (SELECT 1 FROM telemetry WHERE site_id = ? AND channel = ? AND ts = ?)
+	`
+)
+
+// ImportEvents inserts events in one transaction. Events whose ID is already
+// stored are skipped. Returns the number of events inserted.
+func (r *ImportSQLite) ImportEvents(ctx context.Context, events []models.FurnaceEvent) (int, error) {
+	rows := make([]chainedRow, len(events))
+	for i, e := range events {
+		rows[i] = eventRow(e) // same format as Append
+	}
+	if r.hashChain {
+		return appendChained(ctx, r.db, r.site, rows, true)
+	}
+	return r.inTx(ctx, importEventSQL, len(rows), func(i int) []any {
+		row := rows[i]
+		return []any{row.id, row.occurredAt, row.typ, row.message, row.meta, r.site}
+	})
+}
+
+// ImportTelemetry inserts samples in one transaction, skipping any whose
+// channel already has a sample at the same timestamp. Returns the number of
+// samples inserted.
+func (r *ImportSQLite) ImportTelemetry(ctx context.Context, samples []models.TelemetrySample) (int, error) {
+	return r.inTx(ctx, importTelemetrySQL, len(samples), func(i int) []any {
+		s := samples[i]
+		at := s.At
+		if at.IsZero() {
+			at = time.Now()
+		}
+		ts := at.UTC().Format(telemetryTimeLayout)
+		return []any{ts, s.Channel, s.Value, r.site, r.site, s.Channel, ts}
+	})
+}
+
+// inTx runs stmt once per row with the arguments from args and sums the
+// affected rows. Nothing is written if any row fails.
+func (r *ImportSQLite) inTx(ctx context.Context, stmt string, n int, args func(i int) []any) (int, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	ps, err := tx.PrepareContext(ctx, stmt)
+	if err != nil {
+		return 0, err
+	}
+	defer ps.Close()
+
+	inserted := 0
+	for i := 0; i < n; i++ {
+		res, err := ps.ExecContext(ctx, args(i)...)
+		if err != nil {
+			return 0, err
+		}
+		if k, err := res.RowsAffected(); err == nil {
+			inserted += int(k)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
@@ -0,0 +1,71 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestImportSQLite_ImportTelemetryCountsInserted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2023, 5, 1, 8, 0, 0, 0, time.UTC)
+	ts := "2023-05-01 08:00:00.000"
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO telemetry (ts, channel, value, site_id)"))
+	prep.ExpectExec().WithArgs(ts, models.ChannelChamber, 640.5, repository.DefaultSite, repository.DefaultSite, models.ChannelChamber, ts).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	prep.ExpectExec().WithArgs(ts, models.ChannelAmbient, 24.0, repository.DefaultSite, repository.DefaultSite, models.ChannelAmbient, ts).
+		WillReturnResult(sqlmock.NewResult(0, 0)) // already stored
+	mock.ExpectCommit()
+
+	n, err := repository.NewImportSQLite(db).ImportTelemetry(context.Background(), []models.TelemetrySample{
+		{At: at, Channel: models.ChannelChamber, Value: 640.5},
+		{At: at, Channel: models.ChannelAmbient, Value: 24},
+	})
+	if err != nil || n != 1 {
+		t.Fatalf("ImportTelemetry() = %d, %v; want 1 inserted", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestImportSQLite_ImportEventsRollsBackOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2023, 5, 1, 8, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(regexp.QuoteMeta("INSERT OR IGNORE INTO furnace_events"))
+	prep.ExpectExec().WithArgs("legacy-1", "2023-05-01T08:00:00Z", "START", "started", `{"source":"legacy"}`, repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	prep.ExpectExec().WillReturnError(errors.New("disk full"))
+	mock.ExpectRollback()
+
+	_, err = repository.NewImportSQLite(db).ImportEvents(context.Background(), []models.FurnaceEvent{
+		{EventID: "legacy-1", OccurredAt: at, Type: "start", Description: "started", Metadata: map[string]any{"source": "legacy"}},
+		{EventID: "legacy-2", OccurredAt: at, Type: "STOP"},
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+type IncidentSQLite struct {
+	db   *sql.DB
+	site string // incidents of other sites are invisible
+}
+
+func NewIncidentSQLite(db *sql.DB) *IncidentSQLite {
+	return &IncidentSQLite{db: db, site: DefaultSite}
+}
+
+// Ensure implementation of IncidentRepo interface at compile time.
+var _ IncidentRepo = (*IncidentSQLite)(nil)
+
+const (
+	incidentSummaryColumns = `i.id, i.started_at, i.ended_at, i.run_id, i.alarm_codes, i.peak_temp_c, i.peak_measured_c,
+		i.overheat_s, i.resolution, i.acked_by, COALESCE(u.username, ''), i.acked_at`
+	incidentFrom = ` FROM incidents i LEFT JOIN users u ON u.id = i.acked_by`
+
+	insertIncidentSQL = `
+		INSERT INTO incidents (started_at, run_id, alarm_codes, peak_temp_c, peak_measured_c, site_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	updateIncidentSQL = `
+		UPDATE incidents SET ended_at=?, alarm_codes=?, peak_temp_c=?, peak_measured_c=?, overheat_s=?, resolution=?,
+			events=?, telemetry=?
+		WHERE id=? AND site_id=?
+	`
+	ackIncidentSQL      = `UPDATE incidents SET acked_by=?, acked_at=? WHERE id=? AND site_id=? AND acked_by=0`
+	selectIncidentSQL   = `SELECT ` + incidentSummaryColumns + `, i.events, i.telemetry` + incidentFrom + ` WHERE i.id=? AND i.site_id=?`
+	currentIncidentSQL  = `SELECT ` + incidentSummaryColumns + `, i.events, i.telemetry` + incidentFrom + ` WHERE i.site_id=? AND i.ended_at IS NULL ORDER BY i.id DESC LIMIT 1`
+	listIncidentsPrefix = `SELECT ` + incidentSummaryColumns + incidentFrom
+)
+
+// scanIncident reads the summary columns and, when detail is set, the
+// events and telemetry that follow them.
+func scanIncident(s rowScanner, detail bool) (models.Incident, error) {
+	var (
+		inc               models.Incident
+		ended, acked      sql.NullTime
+		codes             string
+		events, telemetry string
+	)
+	dest := []any{&inc.ID, &inc.StartedAt, &ended, &inc.RunID, &codes, &inc.PeakTempC, &inc.PeakMeasuredC,
+		&inc.OverheatS, &inc.Resolution, &inc.AckedBy, &inc.AckedByName, &acked}
+	if detail {
+		dest = append(dest, &events, &telemetry)
+	}
+	if err := s.Scan(dest...); err != nil {
+		return models.Incident{}, err
+	}
+	inc.StartedAt = inc.StartedAt.UTC()
+	if ended.Valid {
+		t := ended.Time.UTC()
+		inc.EndedAt = &t
+	}
+	if acked.Valid {
+		t := acked.Time.UTC()
+		inc.AckedAt = &t
+	}
+	if err := json.Unmarshal([]byte(codes), &inc.AlarmCodes); err != nil {
+		return models.Incident{}, err
+	}
+	if detail {
+		if err := json.Unmarshal([]byte(events), &inc.Events); err != nil {
+			return models.Incident{}, err
+		}
+		if err := json.Unmarshal([]byte(telemetry), &inc.Telemetry); err != nil {
+			return models.Incident{}, err
+		}
+	}
+	return inc, nil
+}
+
+// marshalList encodes a slice as JSON, storing nil as an empty array.
+func marshalList[T any](v []T) (string, error) {
+	if v == nil {
+		v = []T{}
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// Create stores a newly opened incident and returns its ID.
+func (r *IncidentSQLite) Create(ctx context.Context, inc models.Incident) (int64, error) {
+	codes, err := marshalList(inc.AlarmCodes)
+	if err != nil {
+		return 0, err
+	}
+	res, err := r.db.ExecContext(ctx, insertIncidentSQL,
+		inc.StartedAt.UTC(), inc.RunID, codes, inc.PeakTempC, inc.PeakMeasuredC, r.site)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// Update saves the progress of an incident, or its final record once
+// EndedAt is set. Acknowledgement is left alone. It reports false if the
+// incident does not exist.
+func (r *IncidentSQLite) Update(ctx context.Context, inc models.Incident) (bool, error) {
+	codes, err := marshalList(inc.AlarmCodes)
+	if err != nil {
+		return false, err
+	}
+	events, err := marshalList(inc.Events)
+	if err != nil {
+		return false, err
+	}
+	telemetry, err := marshalList(inc.Telemetry)
+	if err != nil {
+		return false, err
+	}
+	var ended any
+	if inc.EndedAt != nil {
+		ended = inc.EndedAt.UTC()
+	}
+	res, err := r.db.ExecContext(ctx, updateIncidentSQL,
+		ended, codes, inc.PeakTempC, inc.PeakMeasuredC, inc.OverheatS, inc.Resolution, events, telemetry, inc.ID, r.site)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Acknowledge records userID as the acknowledging operator. It reports
+// false if the incident does not exist or was already acknowledged.
+func (r *IncidentSQLite) Acknowledge(ctx context.Context, id int64, userID int, at time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, ackIncidentSQL, userID, at.UTC(), id, r.site)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Get fetches an incident with its events and telemetry; a missing
+// incident yields a zero value and nil error.
+func (r *IncidentSQLite) Get(ctx context.Context, id int64) (models.Incident, error) {
+	inc, err := scanIncident(r.db.QueryRowContext(ctx, selectIncidentSQL, id, r.site), true)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Incident{}, nil
+	}
+	return inc, err
+}
+
+// Current returns the open incident, or a zero value if none is open.
+func (r *IncidentSQLite) Current(ctx context.Context) (models.Incident, error) {
+	inc, err := scanIncident(r.db.QueryRowContext(ctx, currentIncidentSQL, r.site), true)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Incident{}, nil
+	}
+	return inc, err
+}
+
+// List returns incidents that started within q, newest first, without
+// their events and telemetry.
+func (r *IncidentSQLite) List(ctx context.Context, q IncidentQuery) ([]models.Incident, error) {
+	conds := []string{"i.site_id = ?"}
+	args := []any{r.site}
+	if !q.From.IsZero() {
+		conds = append(conds, "i.started_at >= ?")
+		args = append(args, q.From.UTC())
+	}
+	if !q.To.IsZero() {
+		conds = append(conds, "i.started_at <= ?")
+		args = append(args, q.To.UTC())
+	}
+	if q.Alarm != "" {
+		conds = append(conds, "EXISTS (SELECT 1 FROM json_each(i.alarm_codes) WHERE json_each.value = ?)")
+		args = append(args, q.Alarm)
+	}
+
+	stmt := listIncidentsPrefix + " WHERE " + strings.Join(conds, " AND ")
+	stmt += " ORDER BY i.started_at DESC, i.id DESC"
+	if q.Limit > 0 {
+		stmt += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.Incident, 0, 16)
+	for rows.Next() {
+		inc, err := scanIncident(rows, false)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, inc)
+	}
+	return out, rows.Err()
+}
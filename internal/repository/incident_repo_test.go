@@ -0,0 +1,125 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var incidentColumns = []string{"id", "started_at", "ended_at", "run_id", "alarm_codes", "peak_temp_c", "peak_measured_c",
+	"overheat_s", "resolution", "acked_by", "username", "acked_at", "events", "telemetry"}
+
+func TestIncidentSQLite_CreateAndUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	start := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Second)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO incidents")).
+		WithArgs(start, "run-1", `["OVERHEAT"]`, 1012.5, 1013.0, repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE incidents SET ended_at=?")).
+		WithArgs(end, `["OVERHEAT"]`, 1020.0, 1013.0, 45.0, models.IncidentCleared, sqlmock.AnyArg(), "[]", int64(7), repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := repository.NewIncidentSQLite(db)
+	inc := models.Incident{StartedAt: start, RunID: "run-1", AlarmCodes: []string{"OVERHEAT"}, PeakTempC: 1012.5, PeakMeasuredC: 1013}
+	id, err := repo.Create(context.Background(), inc)
+	if err != nil || id != 7 {
+		t.Fatalf("Create() = %d, %v", id, err)
+	}
+	inc.ID, inc.EndedAt, inc.PeakTempC, inc.OverheatS, inc.Resolution = id, &end, 1020, 45, models.IncidentCleared
+	inc.Events = []models.FurnaceEvent{{EventID: "e1", OccurredAt: start, Type: "ERROR"}}
+	if found, err := repo.Update(context.Background(), inc); err != nil || !found {
+		t.Fatalf("Update() = %v, %v", found, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestIncidentSQLite_GetDecodesReport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	start := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("LEFT JOIN users u ON u.id = i.acked_by WHERE i.id=? AND i.site_id=?")).
+		WithArgs(int64(7), repository.DefaultSite).
+		WillReturnRows(sqlmock.NewRows(incidentColumns).AddRow(
+			int64(7), start, start.Add(time.Minute), "run-1", `["OVERHEAT","SENSOR_FAULT"]`, 1020.0, 1013.0,
+			40.0, "stopped", 3, "alice", start.Add(30*time.Second),
+			`[{"event_id":"e1","occurred_at":"2025-09-20T10:00:00Z","type":"ERROR","description":"Overheat"}]`,
+			`[{"start":"2025-09-20T09:59:00Z","samples":60,"min_c":990,"max_c":1020,"avg_c":1005}]`))
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE i.id=? AND i.site_id=?")).
+		WithArgs(int64(8), repository.DefaultSite).
+		WillReturnRows(sqlmock.NewRows(incidentColumns))
+
+	repo := repository.NewIncidentSQLite(db)
+	inc, err := repo.Get(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if inc.Open() || len(inc.AlarmCodes) != 2 || inc.OverheatS != 40 || inc.Resolution != models.IncidentStopped ||
+		inc.AckedByName != "alice" || inc.AckedAt == nil {
+		t.Fatalf("unexpected incident: %+v", inc)
+	}
+	if len(inc.Events) != 1 || inc.Events[0].Type != "ERROR" || len(inc.Telemetry) != 1 || inc.Telemetry[0].MaxC != 1020 {
+		t.Fatalf("unexpected report: %+v / %+v", inc.Events, inc.Telemetry)
+	}
+	if inc, err := repo.Get(context.Background(), 8); err != nil || inc.ID != 0 {
+		t.Fatalf("expected a zero incident for a missing ID, got %+v, %v", inc, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestIncidentSQLite_ListAndAcknowledge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	from := time.Date(2025, 9, 20, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE i.site_id = ? AND i.started_at >= ? ORDER BY i.started_at DESC, i.id DESC LIMIT ?")).
+		WithArgs(repository.DefaultSite, from, 5).
+		WillReturnRows(sqlmock.NewRows(incidentColumns[:12]).
+			AddRow(int64(9), from.Add(time.Hour), nil, "", `["POWER_LOSS"]`, 600.0, 600.0, 0.0, "", 0, "", nil))
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE i.site_id = ? AND EXISTS (SELECT 1 FROM json_each(i.alarm_codes) WHERE json_each.value = ?) ORDER BY")).
+		WithArgs(repository.DefaultSite, "OVERHEAT").
+		WillReturnRows(sqlmock.NewRows(incidentColumns[:12]))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE incidents SET acked_by=?, acked_at=? WHERE id=? AND site_id=? AND acked_by=0")).
+		WithArgs(3, from, int64(9), repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repo := repository.NewIncidentSQLite(db)
+	got, err := repo.List(context.Background(), repository.IncidentQuery{From: from, Limit: 5})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(got) != 1 || !got[0].Open() || got[0].AckedAt != nil || got[0].AlarmCodes[0] != "POWER_LOSS" {
+		t.Fatalf("unexpected incidents: %+v", got)
+	}
+	if got, err := repo.List(context.Background(), repository.IncidentQuery{Alarm: "OVERHEAT"}); err != nil || len(got) != 0 {
+		t.Fatalf("List(alarm) = %+v, %v", got, err)
+	}
+	if found, err := repo.Acknowledge(context.Background(), 9, 3, from); err != nil || found {
+		t.Fatalf("Acknowledge() = %v, %v; want false", found, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
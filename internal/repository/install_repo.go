@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type InstallSQLite struct {
+	db     *sql.DB
+	site   string        // whose row to read and write
+	cipher *ColumnCipher // encrypts the row, which holds the JWT signing key
+}
+
+func NewInstallSQLite(db *sql.DB) *InstallSQLite { return &InstallSQLite{db: db, site: DefaultSite} }
+
+// Ensure implementation of InstallRepo interface at compile time.
+var _ InstallRepo = (*InstallSQLite)(nil)
+
+const (
+	upsertInstallSQL = `
+		INSERT INTO install_settings (site_id, data, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(site_id) DO UPDATE SET data=excluded.data, updated_at=excluded.updated_at
+	`
+	selectInstallSQL = `SELECT data FROM install_settings WHERE site_id=?`
+)
+
+// Save stores inst as the site's installation row.
+func (r *InstallSQLite) Save(ctx context.Context, inst models.Installation) error {
+	data, err := json.Marshal(inst)
+	if err != nil {
+		return err
+	}
+	stored, err := r.cipher.seal(string(data))
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, upsertInstallSQL, r.site, stored, time.Now().UTC())
+	return err
+}
+
+// Load returns the saved installation, or a zero value before setup.
+func (r *InstallSQLite) Load(ctx context.Context) (models.Installation, error) {
+	var (
+		inst models.Installation
+		data string
+	)
+	err := r.db.QueryRowContext(ctx, selectInstallSQL, r.site).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return inst, nil
+	}
+	if err != nil {
+		return inst, err
+	}
+	if data, err = r.cipher.open(data); err != nil {
+		return inst, err
+	}
+	err = json.Unmarshal([]byte(data), &inst)
+	return inst, err
+}
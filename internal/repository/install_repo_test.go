@@ -0,0 +1,50 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestInstallSQLite_SaveAndLoad(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	done := time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)
+	inst := models.Installation{SigningKey: "k", Units: models.UnitsFahrenheit, CompletedAt: &done}
+	data := `{"signing_key":"k","units":"F","completed_at":"2025-09-01T08:00:00Z"}`
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO install_settings")).
+		WithArgs(repository.DefaultSite, data, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT data FROM install_settings WHERE site_id=?")).
+		WithArgs(repository.DefaultSite).
+		WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow(data))
+
+	repo := repository.NewInstallSQLite(db)
+	if err := repo.Save(context.Background(), inst); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := repo.Load(context.Background())
+	if err != nil || got.SigningKey != "k" || got.Units != "F" || got.CompletedAt == nil || !got.CompletedAt.Equal(done) {
+		t.Fatalf("Load() = %+v, %v", got, err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT data FROM install_settings")).WillReturnError(sql.ErrNoRows)
+	if got, err := repo.Load(context.Background()); err != nil || got.CompletedAt != nil {
+		t.Fatalf("Load() before setup = %+v, %v; want zero value", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type MaintenanceSQLite struct {
+	db   *sql.DB
+	site string // tasks and records of other sites are invisible
+}
+
+func NewMaintenanceSQLite(db *sql.DB) *MaintenanceSQLite {
+	return &MaintenanceSQLite{db: db, site: DefaultSite}
+}
+
+// Ensure implementation of MaintenanceRepo interface at compile time.
+var _ MaintenanceRepo = (*MaintenanceSQLite)(nil)
+
+const (
+	maintenanceTaskColumns = `id, name, kind, part, interval_hours, interval_days, baseline_at, baseline_hours,
+		last_done_at, overdue_logged, created_by, created_at, updated_at`
+
+	insertMaintenanceTaskSQL = `
+		INSERT INTO maintenance_tasks (name, kind, part, interval_hours, interval_days, baseline_at, baseline_hours,
+			created_by, created_at, updated_at, site_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	updateMaintenanceTaskSQL = `
+		UPDATE maintenance_tasks SET name=?, kind=?, part=?, interval_hours=?, interval_days=?, overdue_logged=0, updated_at=?
+		WHERE id=? AND site_id=?
+	`
+	deleteMaintenanceTaskSQL  = `DELETE FROM maintenance_tasks WHERE id=? AND site_id=?`
+	markMaintenanceOverdueSQL = `UPDATE maintenance_tasks SET overdue_logged=1 WHERE id=? AND site_id=?`
+	selectMaintenanceTaskSQL  = `SELECT ` + maintenanceTaskColumns + ` FROM maintenance_tasks WHERE id=? AND site_id=?`
+	listMaintenanceTasksSQL   = `SELECT ` + maintenanceTaskColumns + ` FROM maintenance_tasks WHERE site_id=? ORDER BY id ASC`
+
+	restartMaintenanceTaskSQL = `
+		UPDATE maintenance_tasks SET baseline_at=?, baseline_hours=?, last_done_at=?, overdue_logged=0, updated_at=?
+		WHERE id=? AND site_id=?
+	`
+	insertMaintenanceRecordSQL = `
+		INSERT INTO maintenance_records (task_id, task_name, kind, part, completed_at, completed_by, heating_hours, note, site_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	maintenanceRecordColumns = `id, task_id, task_name, kind, part, completed_at, completed_by, heating_hours, note`
+)
+
+func scanMaintenanceTask(s rowScanner) (models.MaintenanceTask, error) {
+	var t models.MaintenanceTask
+	var lastDone sql.NullTime
+	err := s.Scan(&t.ID, &t.Name, &t.Kind, &t.Part, &t.IntervalHours, &t.IntervalDays, &t.BaselineAt, &t.BaselineHours,
+		&lastDone, &t.OverdueLogged, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt)
+	if lastDone.Valid {
+		at := lastDone.Time.UTC()
+		t.LastDoneAt = &at
+	}
+	t.BaselineAt, t.CreatedAt, t.UpdatedAt = t.BaselineAt.UTC(), t.CreatedAt.UTC(), t.UpdatedAt.UTC()
+	return t, err
+}
+
+// CreateTask stores a new task, starting its interval at t.BaselineAt and
+// t.BaselineHours, and returns its ID.
+func (r *MaintenanceSQLite) CreateTask(ctx context.Context, t models.MaintenanceTask) (int, error) {
+	now := time.Now().UTC()
+	res, err := r.db.ExecContext(ctx, insertMaintenanceTaskSQL,
+		t.Name, t.Kind, t.Part, t.IntervalHours, t.IntervalDays, t.BaselineAt.UTC(), t.BaselineHours,
+		t.CreatedBy, now, now, r.site)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// UpdateTask replaces the editable fields of a task. A changed interval
+// may no longer be overdue, so MAINTENANCE_OVERDUE is logged afresh.
+func (r *MaintenanceSQLite) UpdateTask(ctx context.Context, t models.MaintenanceTask) (bool, error) {
+	return r.exec(ctx, updateMaintenanceTaskSQL,
+		t.Name, t.Kind, t.Part, t.IntervalHours, t.IntervalDays, time.Now().UTC(), t.ID, r.site)
+}
+
+// DeleteTask removes a task; its records are kept.
+func (r *MaintenanceSQLite) DeleteTask(ctx context.Context, id int) (bool, error) {
+	return r.exec(ctx, deleteMaintenanceTaskSQL, id, r.site)
+}
+
+// MarkOverdue notes that MAINTENANCE_OVERDUE was logged for the task's
+// current interval.
+func (r *MaintenanceSQLite) MarkOverdue(ctx context.Context, id int) (bool, error) {
+	return r.exec(ctx, markMaintenanceOverdueSQL, id, r.site)
+}
+
+func (r *MaintenanceSQLite) exec(ctx context.Context, stmt string, args ...any) (bool, error) {
+	res, err := r.db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetTask fetches a task by ID; a missing task yields a zero value and nil error.
+func (r *MaintenanceSQLite) GetTask(ctx context.Context, id int) (models.MaintenanceTask, error) {
+	t, err := scanMaintenanceTask(r.db.QueryRowContext(ctx, selectMaintenanceTaskSQL, id, r.site))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.MaintenanceTask{}, nil
+	}
+	return t, err
+}
+
+func (r *MaintenanceSQLite) ListTasks(ctx context.Context) ([]models.MaintenanceTask, error) {
+	rows, err := r.db.QueryContext(ctx, listMaintenanceTasksSQL, r.site)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.MaintenanceTask, 0, 8)
+	for rows.Next() {
+		t, err := scanMaintenanceTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// Complete stores rec and moves the task's baseline to it in one
+// transaction.
+func (r *MaintenanceSQLite) Complete(ctx context.Context, rec models.MaintenanceRecord) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	at := rec.CompletedAt.UTC()
+	res, err := tx.ExecContext(ctx, restartMaintenanceTaskSQL, at, rec.HeatingHours, at, time.Now().UTC(), rec.TaskID, r.site)
+	if err != nil {
+		return 0, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return 0, err
+	}
+	res, err = tx.ExecContext(ctx, insertMaintenanceRecordSQL,
+		rec.TaskID, rec.TaskName, rec.Kind, rec.Part, at, rec.CompletedBy, rec.HeatingHours, rec.Note, r.site)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// ListRecords returns completion records matching q, newest first.
+func (r *MaintenanceSQLite) ListRecords(ctx context.Context, q MaintenanceRecordQuery) ([]models.MaintenanceRecord, error) {
+	stmt := `SELECT ` + maintenanceRecordColumns + ` FROM maintenance_records WHERE site_id = ?`
+	args := []any{r.site}
+	if q.TaskID != 0 {
+		stmt += " AND task_id = ?"
+		args = append(args, q.TaskID)
+	}
+	stmt += " ORDER BY completed_at DESC, id DESC"
+	if q.Limit > 0 {
+		stmt += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.MaintenanceRecord, 0, 16)
+	for rows.Next() {
+		var rec models.MaintenanceRecord
+		if err := rows.Scan(&rec.ID, &rec.TaskID, &rec.TaskName, &rec.Kind, &rec.Part, &rec.CompletedAt,
+			&rec.CompletedBy, &rec.HeatingHours, &rec.Note); err != nil {
+			return nil, err
+		}
+		rec.CompletedAt = rec.CompletedAt.UTC()
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
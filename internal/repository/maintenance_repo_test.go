@@ -0,0 +1,83 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestMaintenanceSQLite_CompleteRestartsTheInterval(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE maintenance_tasks SET baseline_at=?")).
+		WithArgs(at, 2013.5, at, sqlmock.AnyArg(), 3, repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO maintenance_records")).
+		WithArgs(3, "Elements", models.MaintenanceElements, "KANTHAL", at, 7, 2013.5, "all six", repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(11, 1))
+	mock.ExpectCommit()
+	// a deleted task records nothing
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE maintenance_tasks SET baseline_at=?")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	repo := repository.NewMaintenanceSQLite(db)
+	rec := models.MaintenanceRecord{
+		TaskID: 3, TaskName: "Elements", Kind: models.MaintenanceElements, Part: "KANTHAL",
+		CompletedAt: at, CompletedBy: 7, HeatingHours: 2013.5, Note: "all six",
+	}
+	if id, err := repo.Complete(context.Background(), rec); err != nil || id != 11 {
+		t.Fatalf("Complete() = %d, %v", id, err)
+	}
+	rec.TaskID = 4
+	if id, err := repo.Complete(context.Background(), rec); err != nil || id != 0 {
+		t.Fatalf("Complete() on a missing task = %d, %v", id, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestMaintenanceSQLite_GetTask(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	cols := []string{"id", "name", "kind", "part", "interval_hours", "interval_days", "baseline_at", "baseline_hours",
+		"last_done_at", "overdue_logged", "created_by", "created_at", "updated_at"}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM maintenance_tasks WHERE id=? AND site_id=?")).
+		WithArgs(3, repository.DefaultSite).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow(3, "Calibrate", models.MaintenanceCalibration, "", 0.0, 90, at, 12.5, nil, true, 7, at, at))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM maintenance_tasks WHERE id=? AND site_id=?")).
+		WithArgs(4, repository.DefaultSite).
+		WillReturnRows(sqlmock.NewRows(cols))
+
+	repo := repository.NewMaintenanceSQLite(db)
+	task, err := repo.GetTask(context.Background(), 3)
+	if err != nil || task.IntervalDays != 90 || task.LastDoneAt != nil || !task.OverdueLogged || !task.BaselineAt.Equal(at) {
+		t.Fatalf("GetTask() = %+v, %v", task, err)
+	}
+	if task, err := repo.GetTask(context.Background(), 4); err != nil || task.ID != 0 {
+		t.Fatalf("expected a zero task for a missing ID, got %+v, %v", task, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,523 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The in-memory repositories keep every table in process memory and lose it
+// on exit. They follow the SQLite implementations closely enough to stand
+// in for them in demos and tests: timestamps are stored at the same
+// precision, IDs are assigned in the same order, and lookups that find
+// nothing return the same zero values and errors.
+
+// memStore holds the tables of the in-memory repositories. One lock covers
+// all of them, so operations spanning tables, such as a purge removing
+// events and their comments, are atomic like the transactions they mirror.
+type memStore struct {
+	mu sync.Mutex
+
+	hashChain bool
+	newID     func() string
+	now       func() time.Time
+
+	state    *models.FurnaceState
+	runs     map[string]models.Run
+	settings *models.SimSettings
+	health   *models.FurnaceHealth
+	install  *models.Installation
+	checks   []models.UptimeCheck
+	users    []memUser
+
+	events    []memEvent // in rowid order
+	eventIDs  map[string]int64
+	lastRowID int64
+	anchor    string // hash of the last chained row purged
+	comments  []models.EventComment
+
+	telemetry []models.TelemetrySample
+	samples   []models.FurnaceSample
+
+	rules      []models.AlertRule
+	alerts     []models.Alert
+	incidents  []models.Incident
+	sequences  []SequenceRecord
+	tasks      []models.MaintenanceTask
+	records    []models.MaintenanceRecord
+	webhooks   []models.Webhook
+	deliveries []models.WebhookDelivery
+	cursor     *int64 // webhook delivery cursor; nil until first read
+	ids        map[string]int64
+}
+
+// nextID returns the next autoincrement value of table.
+func (s *memStore) nextID(table string) int64 {
+	s.ids[table]++
+	return s.ids[table]
+}
+
+// NewInMemory returns repositories backed by process memory instead of a
+// database file.
+func NewInMemory() *Repository {
+	return NewInMemoryWithConfig(Config{})
+}
+
+// NewInMemoryWithConfig is NewInMemory with explicit options.
+func NewInMemoryWithConfig(cfg Config) *Repository {
+	s := &memStore{
+		hashChain: cfg.EventHashChain,
+		newID:     uuid.NewString,
+		now:       time.Now,
+		runs:      make(map[string]models.Run),
+		eventIDs:  make(map[string]int64),
+		ids:       make(map[string]int64),
+	}
+	if cfg.NewID != nil {
+		s.newID = cfg.NewID
+	}
+	if cfg.Clock != nil {
+		s.now = cfg.Clock
+	}
+	events := &memEvents{s}
+	return &Repository{
+		StateRepo:   &memState{s},
+		EventRepo:   events,
+		Events:      events,
+		Chain:       events,
+		Comments:    events,
+		Retention:   events,
+		Deletions:   events,
+		RunRepo:     &memRuns{s},
+		Telemetry:   &memTelemetry{s},
+		Samples:     &memSamples{s},
+		Rollups:     &memSamples{s},
+		Settings:    &memSettings{s},
+		Health:      &memHealth{s},
+		Alerts:      &memAlerts{s},
+		Incidents:   &memIncidents{s},
+		Sequences:   &memSequences{s},
+		Maintenance: &memMaintenance{s},
+		Webhooks:    &memWebhooks{s},
+		Import:      events,
+		Status:      memStatus{},
+		Backup:      memBackup{},
+		Uptime:      &memUptime{s},
+		Auth:        &memUsers{s},
+		Install:     &memInstall{s},
+		UnitOfWork:  &memUnitOfWork{s},
+	}
+}
+
+type memState struct{ *memStore }
+
+var _ StateRepo = (*memState)(nil)
+
+func (r *memState) Save(ctx context.Context, st models.FurnaceState) error {
+	saved := storedState(st)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state = &saved
+	return nil
+}
+
+// memStateRowID is the ID of the in-memory state row; a memory store
+// serves only its own process, so it holds one site's state.
+const memStateRowID = 1
+
+// storedState returns st as it reads back after a save.
+func storedState(st models.FurnaceState) models.FurnaceState {
+	updated := st.UpdatedAt
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	// only the stored columns survive a round trip
+	return models.FurnaceState{
+		ID:               memStateRowID,
+		Mode:             st.Mode,
+		CurrentTempC:     st.CurrentTempC,
+		MeasuredTempC:    st.MeasuredTempC,
+		AmbientTempC:     st.AmbientTempC,
+		TargetTempC:      st.TargetTempC,
+		RemainingSeconds: st.RemainingSeconds,
+		ErrorCodes:       slices.Clone(st.ErrorCodes),
+		IsRunning:        st.IsRunning,
+		UpdatedAt:        updated.UTC(),
+		RunID:            st.RunID,
+		PowerKW:          st.PowerKW,
+		EnergyKWh:        st.EnergyKWh,
+		Gas:              st.Gas,
+		GasSetpointM3h:   st.GasSetpointM3h,
+		GasFlowM3h:       st.GasFlowM3h,
+		O2PPM:            st.O2PPM,
+		MaxO2PPM:         st.MaxO2PPM,
+		KeepWarmC:        st.KeepWarmC,
+	}
+}
+
+func (r *memState) Load(ctx context.Context) (models.FurnaceState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.state == nil {
+		return models.FurnaceState{}, nil
+	}
+	st := *r.state
+	st.ErrorCodes = slices.Clone(st.ErrorCodes)
+	return st, nil
+}
+
+// memUnitOfWork buffers the writes of a unit of work and applies them
+// under the store lock once fn succeeds. Reads through tx see the buffered
+// state but only the committed events.
+type memUnitOfWork struct{ *memStore }
+
+var _ UnitOfWork = (*memUnitOfWork)(nil)
+
+func (u *memUnitOfWork) Do(ctx context.Context, fn func(tx Tx) error) error {
+	tx := &memTx{store: u.memStore}
+	if err := fn(Tx{StateRepo: &memTxState{tx}, EventRepo: &memTxEvents{&memEvents{u.memStore}, tx}}); err != nil {
+		return err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	// insertEvents checks the whole batch before it inserts any of it
+	if _, err := u.insertEvents(tx.events, false); err != nil {
+		return err
+	}
+	if tx.state != nil {
+		u.state = tx.state
+	}
+	return nil
+}
+
+// memTx holds the writes of one unit of work until it commits.
+type memTx struct {
+	store  *memStore
+	state  *models.FurnaceState
+	events []chainedRow
+}
+
+type memTxState struct{ tx *memTx }
+
+func (r *memTxState) Save(ctx context.Context, st models.FurnaceState) error {
+	saved := storedState(st)
+	r.tx.state = &saved
+	return nil
+}
+
+func (r *memTxState) Load(ctx context.Context) (models.FurnaceState, error) {
+	if r.tx.state == nil {
+		return (&memState{r.tx.store}).Load(ctx)
+	}
+	st := *r.tx.state
+	st.ErrorCodes = slices.Clone(st.ErrorCodes)
+	return st, nil
+}
+
+type memTxEvents struct {
+	*memEvents
+	tx *memTx
+}
+
+func (r *memTxEvents) Append(ctx context.Context, e models.FurnaceEvent) error {
+	return r.AppendBatch(ctx, []models.FurnaceEvent{e})
+}
+
+func (r *memTxEvents) AppendBatch(ctx context.Context, events []models.FurnaceEvent) error {
+	for _, e := range events {
+		r.tx.events = append(r.tx.events, r.row(e))
+	}
+	return nil
+}
+
+type memRuns struct{ *memStore }
+
+var _ RunRepo = (*memRuns)(nil)
+
+// Save inserts the run or updates its soak statistics. StartedAt is kept
+// from the first insert.
+func (r *memRuns) Save(ctx context.Context, run models.Run) error {
+	if run.UpdatedAt.IsZero() {
+		run.UpdatedAt = time.Now()
+	}
+	if run.StartedAt.IsZero() {
+		run.StartedAt = run.UpdatedAt
+	}
+	run.StartedAt, run.UpdatedAt = run.StartedAt.UTC(), run.UpdatedAt.UTC()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if old, ok := r.runs[run.RunID]; ok {
+		run.StartedAt = old.StartedAt
+	}
+	r.runs[run.RunID] = run
+	return nil
+}
+
+func (r *memRuns) Get(ctx context.Context, runID string) (models.Run, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.runs[runID], nil
+}
+
+type memSettings struct{ *memStore }
+
+var _ SimSettingsRepo = (*memSettings)(nil)
+
+func (r *memSettings) Save(ctx context.Context, st models.SimSettings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.settings = &st
+	return nil
+}
+
+func (r *memSettings) Load(ctx context.Context) (models.SimSettings, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.settings == nil {
+		return models.SimSettings{}, nil
+	}
+	return *r.settings, nil
+}
+
+type memHealth struct{ *memStore }
+
+var _ HealthRepo = (*memHealth)(nil)
+
+func (r *memHealth) Save(ctx context.Context, h models.FurnaceHealth) error {
+	updated := h.UpdatedAt
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	saved := models.FurnaceHealth{
+		HeatingSeconds:         h.HeatingSeconds,
+		Cycles:                 h.Cycles,
+		LastRunID:              h.LastRunID,
+		MaintenanceDue:         h.MaintenanceDue,
+		UpdatedAt:              updated.UTC(),
+		ReplacedHeatingSeconds: h.ReplacedHeatingSeconds,
+		ReplacedCycles:         h.ReplacedCycles,
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.health = &saved
+	return nil
+}
+
+func (r *memHealth) Load(ctx context.Context) (models.FurnaceHealth, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.health == nil {
+		return models.FurnaceHealth{}, nil
+	}
+	return *r.health, nil
+}
+
+type memInstall struct{ *memStore }
+
+var _ InstallRepo = (*memInstall)(nil)
+
+func (r *memInstall) Save(ctx context.Context, inst models.Installation) error {
+	inst.CompletedAt = clonedTimePtr(inst.CompletedAt)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.install = &inst
+	return nil
+}
+
+func (r *memInstall) Load(ctx context.Context) (models.Installation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.install == nil {
+		return models.Installation{}, nil
+	}
+	inst := *r.install
+	inst.CompletedAt = clonedTimePtr(inst.CompletedAt)
+	return inst, nil
+}
+
+// memStatus reports the in-memory store as always reachable and migrated.
+type memStatus struct{}
+
+var _ StatusRepo = memStatus{}
+
+func (memStatus) Ping(ctx context.Context) error        { return nil }
+func (memStatus) CheckSchema(ctx context.Context) error { return nil }
+func (memStatus) Stats() sql.DBStats                    { return sql.DBStats{} }
+
+// memBackup refuses backups: there is no file to copy.
+type memBackup struct{}
+
+var _ BackupRepo = memBackup{}
+
+func (memBackup) Backup(ctx context.Context, path string) error { return ErrBackupUnsupported }
+
+type memUptime struct{ *memStore }
+
+var _ UptimeRepo = (*memUptime)(nil)
+
+func (r *memUptime) RecordCheck(ctx context.Context, c models.UptimeCheck) error {
+	c.At = c.At.UTC()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, c)
+	return nil
+}
+
+func (r *memUptime) LastCheck(ctx context.Context) (models.UptimeCheck, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var last models.UptimeCheck
+	for _, c := range r.checks {
+		if last.At.IsZero() || !c.At.Before(last.At) {
+			last = c
+		}
+	}
+	return last, nil
+}
+
+func (r *memUptime) CountChecks(ctx context.Context, since time.Time) (models.UptimeCounts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n models.UptimeCounts
+	for _, c := range r.checks {
+		if c.At.Before(since) {
+			continue
+		}
+		n.Checks++
+		if c.Up {
+			n.Up++
+		}
+		if n.First.IsZero() || c.At.Before(n.First) {
+			n.First = c.At
+		}
+	}
+	return n, nil
+}
+
+func (r *memUptime) PurgeChecks(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := len(r.checks)
+	r.checks = slices.DeleteFunc(r.checks, func(c models.UptimeCheck) bool { return c.At.Before(before) })
+	return int64(n - len(r.checks)), nil
+}
+
+// memUser is a users row with the key usernames are unique by.
+type memUser struct {
+	models.User
+	key string
+}
+
+type memUsers struct{ *memStore }
+
+var _ Authorization = (*memUsers)(nil)
+
+// Create adds a user with the operator role and returns its ID. It fails
+// with ErrUsernameTaken if the name differs only in case from another
+// user's.
+func (r *memUsers) Create(ctx context.Context, username, passwordHash string) (int, error) {
+	key := usernameKey(username)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.key == key || u.Username == username {
+			return 0, fmt.Errorf("insert user %q: %w", username, ErrUsernameTaken)
+		}
+	}
+	id := int(r.nextID("users"))
+	r.users = append(r.users, memUser{
+		User: models.User{ID: id, Username: username, PasswordHash: passwordHash, Role: models.RoleViewer},
+		key:  key,
+	})
+	return id, nil
+}
+
+// GetByUsername fetches a user by username regardless of case, preferring
+// an exact match. Returns (nil, nil) if not found.
+func (r *memUsers) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	key := usernameKey(username)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found *models.User
+	for _, u := range r.users {
+		if u.Username == username {
+			found = &u.User
+			break
+		}
+		if u.key == key && found == nil {
+			found = &u.User
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+	u := *found
+	return &u, nil
+}
+
+func (r *memUsers) Count(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.users), nil
+}
+
+func (r *memUsers) SetRole(ctx context.Context, id int, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.users {
+		if r.users[i].ID != id {
+			continue
+		}
+		if r.users[i].Role == models.RoleAdmin && role != models.RoleAdmin && r.admins() == 1 {
+			return fmt.Errorf("update role for user %d: %w", id, ErrLastAdmin)
+		}
+		r.users[i].Role = role
+		return nil
+	}
+	return fmt.Errorf("update role for user %d: %w", id, sql.ErrNoRows)
+}
+
+// admins counts the users with the admin role. The caller holds the lock.
+func (s *memStore) admins() int {
+	n := 0
+	for _, u := range s.users {
+		if u.Role == models.RoleAdmin {
+			n++
+		}
+	}
+	return n
+}
+
+// username returns the name of the user with the given ID, or "". The
+// caller holds the lock.
+func (s *memStore) username(id int) string {
+	for _, u := range s.users {
+		if u.ID == id {
+			return u.Username
+		}
+	}
+	return ""
+}
+
+func clonedTimePtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	at := t.UTC()
+	return &at
+}
+
+// limited returns the first limit elements of v, or all of them when limit
+// is not positive.
+func limited[T any](v []T, limit int) []T {
+	if limit > 0 && len(v) > limit {
+		return v[:limit]
+	}
+	return v
+}
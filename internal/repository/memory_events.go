@@ -0,0 +1,491 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// memEvent is a furnace_events row.
+type memEvent struct {
+	rowID          int64
+	row            chainedRow
+	prevHash, hash sql.NullString
+	deletion       *EventDeletion // set once soft-deleted
+}
+
+func (e memEvent) at() time.Time {
+	t, _ := time.Parse(eventTimeLayout, e.row.occurredAt)
+	return t
+}
+
+func (e memEvent) key() EventKey { return EventKey{At: e.row.occurredAt, RowID: e.rowID} }
+
+func (e memEvent) event() models.FurnaceEvent {
+	ev := models.FurnaceEvent{EventID: e.row.id, OccurredAt: e.at(), Type: e.row.typ, Description: e.row.message}
+	if e.row.meta != nil {
+		ev.Metadata = decodeEventMeta(*e.row.meta)
+	}
+	if d := e.deletion; d != nil {
+		at := d.At.UTC().Truncate(time.Second)
+		ev.DeletedAt, ev.DeletedBy, ev.DeleteReason = &at, d.By, d.Reason
+	}
+	return ev
+}
+
+func compareEventKeys(a, b EventKey) int {
+	return cmp.Or(strings.Compare(a.At, b.At), cmp.Compare(a.RowID, b.RowID))
+}
+
+// memEvents is the in-memory event log, with its comments, retention and
+// import.
+type memEvents struct{ *memStore }
+
+var (
+	_ EventRepo          = (*memEvents)(nil)
+	_ EventStreamRepo    = (*memEvents)(nil)
+	_ EventChainRepo     = (*memEvents)(nil)
+	_ EventCommentRepo   = (*memEvents)(nil)
+	_ EventRetentionRepo = (*memEvents)(nil)
+	_ EventDeletionRepo  = (*memEvents)(nil)
+	_ ImportRepo         = (*memEvents)(nil)
+)
+
+// row fills a missing ID and timestamp and converts e to its stored form.
+func (r *memEvents) row(e models.FurnaceEvent) chainedRow {
+	if e.EventID == "" {
+		e.EventID = r.newID()
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = r.now()
+	}
+	return eventRow(e)
+}
+
+func (r *memEvents) Append(ctx context.Context, e models.FurnaceEvent) error {
+	return r.AppendBatch(ctx, []models.FurnaceEvent{e})
+}
+
+func (r *memEvents) AppendBatch(ctx context.Context, events []models.FurnaceEvent) error {
+	rows := make([]chainedRow, len(events))
+	for i, e := range events {
+		rows[i] = r.row(e)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err := r.insertEvents(rows, false)
+	return err
+}
+
+// insertEvents appends rows to the log, chaining them when enabled. With
+// ignoreExisting, rows whose ID is already stored are skipped; otherwise
+// one fails the whole batch. Returns the number of rows inserted. The
+// caller holds the lock.
+func (s *memStore) insertEvents(rows []chainedRow, ignoreExisting bool) (int, error) {
+	if !ignoreExisting {
+		seen := make(map[string]bool, len(rows))
+		for _, row := range rows {
+			if _, ok := s.eventIDs[row.id]; ok || seen[row.id] {
+				return 0, fmt.Errorf("insert event %q: id already exists", row.id)
+			}
+			seen[row.id] = true
+		}
+	}
+	prev := ""
+	for i := len(s.events) - 1; i >= 0; i-- {
+		if s.events[i].hash.Valid {
+			prev = s.events[i].hash.String
+			break
+		}
+	}
+	inserted := 0
+	for _, row := range rows {
+		if _, ok := s.eventIDs[row.id]; ok {
+			continue
+		}
+		e := memEvent{row: row}
+		if s.hashChain {
+			e.prevHash = sql.NullString{String: prev, Valid: true}
+			prev = row.hash(prev)
+			e.hash = sql.NullString{String: prev, Valid: true}
+		}
+		s.lastRowID++
+		e.rowID = s.lastRowID
+		s.events = append(s.events, e)
+		s.eventIDs[row.id] = e.rowID
+		inserted++
+	}
+	return inserted, nil
+}
+
+func (r *memEvents) List(ctx context.Context, from, to time.Time, typ string) ([]models.FurnaceEvent, error) {
+	return r.Query(ctx, EventQuery{From: from, To: to, Type: typ})
+}
+
+func (r *memEvents) Query(ctx context.Context, q EventQuery) ([]models.FurnaceEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rows := r.matching(q)
+	out := make([]models.FurnaceEvent, len(rows))
+	for i, e := range rows {
+		out[i] = e.event()
+	}
+	return out, nil
+}
+
+// matching returns the events matching q in (occurred_at, rowid) order.
+// The caller holds the lock.
+func (s *memStore) matching(q EventQuery) []memEvent {
+	var out []memEvent
+	for _, e := range s.events {
+		if eventMatches(e, q) {
+			out = append(out, e)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b memEvent) int { return compareEventKeys(a.key(), b.key()) })
+	return out
+}
+
+func (r *memEvents) Each(ctx context.Context, q EventQuery, fn func(models.FurnaceEvent) error) error {
+	return eachPage(ctx, r, q, fn)
+}
+
+func (r *memEvents) Page(ctx context.Context, q EventQuery, p EventPageQuery) ([]models.FurnaceEvent, *EventKey, error) {
+	if p.Limit <= 0 {
+		p.Limit = EventPageSize
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rows := r.matching(q)
+	if p.Desc {
+		slices.Reverse(rows)
+	}
+	var last EventKey
+	page := make([]models.FurnaceEvent, 0, min(p.Limit, len(rows)))
+	for _, e := range rows {
+		if p.After != nil {
+			c := compareEventKeys(e.key(), *p.After)
+			if (!p.Desc && c <= 0) || (p.Desc && c >= 0) {
+				continue
+			}
+		}
+		page = append(page, e.event())
+		last = e.key()
+		if len(page) == p.Limit {
+			return page, &last, nil
+		}
+	}
+	return page, nil, nil
+}
+
+// Tail reads in insertion order, like EventSQLite.Tail.
+func (r *memEvents) Tail(ctx context.Context, q EventQuery, t EventTailQuery) ([]models.FurnaceEvent, string, error) {
+	if t.Limit <= 0 {
+		t.Limit = EventPageSize
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var after int64
+	switch {
+	case t.AfterID != "":
+		rowID, ok := r.eventIDs[t.AfterID]
+		if !ok {
+			return nil, "", ErrEventNotFound
+		}
+		after = rowID
+	case t.AfterAt.IsZero():
+		last := ""
+		if n := len(r.events); n > 0 {
+			last = r.events[n-1].row.id
+		}
+		return []models.FurnaceEvent{}, last, nil
+	}
+
+	afterAt := eventTime(t.AfterAt)
+	events := make([]models.FurnaceEvent, 0, 16)
+	for _, e := range r.events {
+		if e.rowID <= after || (!t.AfterAt.IsZero() && e.row.occurredAt <= afterAt) || !eventMatches(e, q) {
+			continue
+		}
+		events = append(events, e.event())
+		if len(events) == t.Limit {
+			break
+		}
+	}
+	last := t.AfterID
+	if len(events) > 0 {
+		last = events[len(events)-1].EventID
+	}
+	return events, last, nil
+}
+
+// eventMatches applies q to e the way eventConds does in SQL.
+func eventMatches(e memEvent, q EventQuery) bool {
+	if e.deletion != nil && !q.IncludeDeleted {
+		return false
+	}
+	at := e.at()
+	if !q.From.IsZero() && at.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && at.After(q.To) {
+		return false
+	}
+	if typ := strings.ToUpper(strings.TrimSpace(q.Type)); typ != "" && e.row.typ != typ {
+		return false
+	}
+	if runID := strings.TrimSpace(q.RunID); runID != "" {
+		if v, _ := metaValue(e.row.meta, "run_id"); v != runID {
+			return false
+		}
+	}
+	if in := typeArgs(q.Types); len(in) > 0 && !slices.Contains(in, any(e.row.typ)) {
+		return false
+	}
+	if out := typeArgs(q.ExcludeTypes); slices.Contains(out, any(e.row.typ)) {
+		return false
+	}
+	for _, m := range q.Meta {
+		if !metaMatches(e.row.meta, m) {
+			return false
+		}
+	}
+	return true
+}
+
+// metaValue looks up a dotted path in stored metadata. It reports false
+// when the key is missing or null, where json_extract returns NULL.
+func metaValue(meta *string, path string) (any, bool) {
+	if meta == nil {
+		return nil, false
+	}
+	var v any
+	if err := json.Unmarshal([]byte(*meta), &v); err != nil {
+		return nil, false
+	}
+	for _, k := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		v = obj[k]
+	}
+	return v, v != nil
+}
+
+// metaMatches applies m like metaCond: JSON numbers and booleans compare
+// as SQL numbers, which sort before text; objects and arrays as their JSON
+// text.
+func metaMatches(meta *string, m MetaFilter) bool {
+	v, ok := metaValue(meta, m.Key)
+	if !ok {
+		return false
+	}
+	var (
+		num   float64
+		text  string
+		isNum = true
+	)
+	switch v := v.(type) {
+	case float64:
+		num = v
+	case bool:
+		if v {
+			num = 1
+		}
+	case string:
+		text, isNum = v, false
+	default:
+		b, _ := json.Marshal(v)
+		text, isNum = string(b), false
+	}
+	f, err := strconv.ParseFloat(m.Value, 64)
+	isFloat := err == nil
+
+	switch m.Op {
+	case "<", "<=", ">", ">=":
+		var c int
+		switch {
+		case isNum && isFloat:
+			c = cmp.Compare(num, f)
+		case isNum:
+			c = -1
+		case isFloat:
+			c = 1
+		default:
+			c = strings.Compare(text, m.Value)
+		}
+		switch m.Op {
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		default:
+			return c >= 0
+		}
+	}
+	var eq bool
+	if isNum {
+		eq = (isFloat && num == f) || (m.Value == "true" && num == 1) || (m.Value == "false" && num == 0)
+	} else {
+		eq = text == m.Value
+	}
+	return eq != (m.Op == "!=")
+}
+
+func (r *memEvents) SoftDelete(ctx context.Context, eventID string, d EventDeletion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rowID, ok := r.eventIDs[eventID]
+	if !ok {
+		return ErrEventNotFound
+	}
+	i := slices.IndexFunc(r.events, func(e memEvent) bool { return e.rowID == rowID })
+	if r.events[i].deletion != nil {
+		return ErrEventDeleted
+	}
+	r.events[i].deletion = &d
+	return nil
+}
+
+func (r *memEvents) VerifyChain(ctx context.Context) (models.ChainReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v := chainVerifier{rep: models.ChainReport{Enabled: r.hashChain, Problems: []models.ChainProblem{}}, prev: r.anchor}
+	for _, e := range r.events {
+		v.add(e.row, e.at(), e.prevHash, e.hash)
+	}
+	return v.report(), nil
+}
+
+// Purge removes a prefix of the log in insertion order, like
+// EventSQLite.Purge.
+func (r *memEvents) Purge(ctx context.Context, p EventPurge, archive EventArchive) (models.PurgeReport, error) {
+	rep := models.PurgeReport{DryRun: p.DryRun}
+	if p.Before.IsZero() && p.KeepMax <= 0 {
+		return rep, ErrNoPurgeLimit
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	if !p.Before.IsZero() {
+		n = len(r.events)
+		if i := slices.IndexFunc(r.events, func(e memEvent) bool { return !e.at().Before(p.Before) }); i >= 0 {
+			n = i
+		}
+	}
+	if p.KeepMax > 0 && len(r.events)-p.KeepMax > n {
+		n = len(r.events) - p.KeepMax
+	}
+	if n == 0 {
+		return rep, nil
+	}
+	purged := r.events[:n]
+	rep.Deleted = int64(n)
+	oldest, newest := purged[0].row.occurredAt, purged[0].row.occurredAt
+	for _, e := range purged {
+		oldest, newest = min(oldest, e.row.occurredAt), max(newest, e.row.occurredAt)
+	}
+	rep.Oldest = parseEventTime(sql.NullString{String: oldest, Valid: true})
+	rep.Newest = parseEventTime(sql.NullString{String: newest, Valid: true})
+	if p.DryRun {
+		return rep, nil
+	}
+
+	if archive != nil {
+		for _, e := range purged {
+			if err := archive.Write(e.event()); err != nil {
+				return rep, err
+			}
+		}
+		if err := archive.Close(); err != nil {
+			return rep, err
+		}
+		rep.Archive = p.Archive
+	}
+	for i := n - 1; i >= 0; i-- {
+		if purged[i].hash.Valid {
+			r.anchor = purged[i].hash.String
+			break
+		}
+	}
+	for _, e := range purged {
+		delete(r.eventIDs, e.row.id)
+	}
+	r.comments = slices.DeleteFunc(r.comments, func(c models.EventComment) bool {
+		_, ok := r.eventIDs[c.EventID]
+		return !ok
+	})
+	r.events = slices.Clone(r.events[n:])
+	return rep, nil
+}
+
+func (r *memEvents) AddComment(ctx context.Context, c models.EventComment) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.eventIDs[c.EventID]; !ok {
+		return 0, ErrEventNotFound
+	}
+	c.ID = r.nextID("event_comments")
+	c.CreatedAt = c.CreatedAt.UTC()
+	r.comments = append(r.comments, c)
+	return c.ID, nil
+}
+
+func (r *memEvents) CommentsFor(ctx context.Context, eventIDs []string) (map[string][]models.EventComment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string][]models.EventComment)
+	for _, c := range r.comments {
+		if slices.Contains(eventIDs, c.EventID) {
+			out[c.EventID] = append(out[c.EventID], c)
+		}
+	}
+	return out, nil
+}
+
+// ImportEvents stores events as given, skipping IDs already in the log.
+func (r *memEvents) ImportEvents(ctx context.Context, events []models.FurnaceEvent) (int, error) {
+	rows := make([]chainedRow, len(events))
+	for i, e := range events {
+		rows[i] = eventRow(e)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.insertEvents(rows, true)
+}
+
+// ImportTelemetry skips samples whose channel already has one at the same
+// millisecond.
+func (r *memEvents) ImportTelemetry(ctx context.Context, samples []models.TelemetrySample) (int, error) {
+	type key struct {
+		channel string
+		at      time.Time
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := make(map[key]bool, len(r.telemetry))
+	for _, s := range r.telemetry {
+		stored[key{s.Channel, s.At}] = true
+	}
+	inserted := 0
+	for _, s := range samples {
+		s.At = telemetryTime(s.At)
+		if k := (key{s.Channel, s.At}); !stored[k] {
+			stored[k] = true
+			r.telemetry = append(r.telemetry, s)
+			inserted++
+		}
+	}
+	return inserted, nil
+}
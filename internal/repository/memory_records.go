@@ -0,0 +1,656 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"controlling_furnace/internal/models"
+	"slices"
+	"time"
+)
+
+// telemetryTime is t as telemetry and samples store it: UTC to the
+// millisecond, now when unset.
+func telemetryTime(t time.Time) time.Time {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.UTC().Truncate(time.Millisecond)
+}
+
+type memTelemetry struct{ *memStore }
+
+var _ TelemetryRepo = (*memTelemetry)(nil)
+
+func (r *memTelemetry) Append(ctx context.Context, samples ...models.TelemetrySample) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range samples {
+		s.At = telemetryTime(s.At)
+		r.telemetry = append(r.telemetry, s)
+	}
+	return nil
+}
+
+func (r *memTelemetry) Query(ctx context.Context, q TelemetryQuery) ([]models.TelemetrySample, error) {
+	from, to := q.From.UTC().Truncate(time.Millisecond), q.To.UTC().Truncate(time.Millisecond)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]models.TelemetrySample, 0, 256)
+	for _, s := range r.telemetry {
+		if (q.Channel != "" && s.Channel != q.Channel) ||
+			(!q.From.IsZero() && s.At.Before(from)) || (!q.To.IsZero() && s.At.After(to)) {
+			continue
+		}
+		out = append(out, s)
+	}
+	slices.SortStableFunc(out, func(a, b models.TelemetrySample) int { return a.At.Compare(b.At) })
+	return limited(out, q.Limit), nil
+}
+
+type memSamples struct{ *memStore }
+
+var (
+	_ SampleRepo       = (*memSamples)(nil)
+	_ SampleRollupRepo = (*memSamples)(nil)
+)
+
+func (r *memSamples) Append(ctx context.Context, s models.FurnaceSample) error {
+	s.At = telemetryTime(s.At)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.samples = append(r.samples, s)
+	return nil
+}
+
+// Buckets aggregates like SampleSQLite.Buckets, into intervals of
+// q.Resolution aligned to the Unix epoch, oldest first.
+func (r *memSamples) Buckets(ctx context.Context, q HistoryQuery) ([]models.HistoryBucket, error) {
+	res := int64(q.Resolution / time.Second)
+	if res < 1 {
+		res = 1
+	}
+	from, to := q.From.UTC().Truncate(time.Millisecond), q.To.UTC().Truncate(time.Millisecond)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	byStart := make(map[int64]*models.HistoryBucket)
+	for i, s := range r.samples {
+		if s.At.Before(from) || s.At.After(to) {
+			continue
+		}
+		start := s.At.Unix() / res * res
+		b := byStart[start]
+		if b == nil {
+			b = &models.HistoryBucket{Start: time.Unix(start, 0).UTC(), MinC: s.TempC, MaxC: s.TempC, TargetC: s.TargetC}
+			byStart[start] = b
+		}
+		b.AvgC += s.TempC // summed until the end
+		b.Samples++
+		b.MinC, b.MaxC, b.TargetC = min(b.MinC, s.TempC), max(b.MaxC, s.TempC), max(b.TargetC, s.TargetC)
+		if s.Mode == heatingMode && i+1 < len(r.samples) {
+			gap := r.samples[i+1].At.Sub(s.At)
+			b.RuntimeS += min(gap, maxSampleGap).Seconds()
+		}
+	}
+	out := make([]models.HistoryBucket, 0, len(byStart))
+	for _, b := range byStart {
+		b.AvgC /= float64(b.Samples)
+		out = append(out, *b)
+	}
+	slices.SortFunc(out, func(a, b models.HistoryBucket) int { return a.Start.Compare(b.Start) })
+	return out, nil
+}
+
+// Rollup summarises nothing: the samples stay in memory, and with them
+// Buckets is exact at any resolution.
+func (r *memSamples) Rollup(ctx context.Context, before time.Time) (int, error) {
+	return 0, nil
+}
+
+// PruneSamples keeps every sample, as none is covered by a summary.
+func (r *memSamples) PruneSamples(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type memAlerts struct{ *memStore }
+
+var _ AlertRepo = (*memAlerts)(nil)
+
+func (r *memAlerts) CreateRule(ctx context.Context, rule models.AlertRule) (int, error) {
+	now := time.Now().UTC()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rule.ID = int(r.nextID("alert_rules"))
+	rule.CreatedAt, rule.UpdatedAt = now, now
+	r.rules = append(r.rules, rule)
+	return rule.ID, nil
+}
+
+func (r *memAlerts) UpdateRule(ctx context.Context, rule models.AlertRule) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := slices.IndexFunc(r.rules, func(x models.AlertRule) bool { return x.ID == rule.ID })
+	if i < 0 {
+		return false, nil
+	}
+	old := &r.rules[i]
+	old.Name, old.Kind, old.Threshold, old.ForSeconds, old.Enabled = rule.Name, rule.Kind, rule.Threshold, rule.ForSeconds, rule.Enabled
+	old.UpdatedAt = time.Now().UTC()
+	return true, nil
+}
+
+func (r *memAlerts) DeleteRule(ctx context.Context, id int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := len(r.rules)
+	r.rules = slices.DeleteFunc(r.rules, func(x models.AlertRule) bool { return x.ID == id })
+	return len(r.rules) < n, nil
+}
+
+func (r *memAlerts) GetRule(ctx context.Context, id int) (models.AlertRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, x := range r.rules {
+		if x.ID == id {
+			return x, nil
+		}
+	}
+	return models.AlertRule{}, nil
+}
+
+func (r *memAlerts) ListRules(ctx context.Context) ([]models.AlertRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append(make([]models.AlertRule, 0, len(r.rules)), r.rules...), nil
+}
+
+func (r *memAlerts) AppendAlert(ctx context.Context, a models.Alert) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a.ID = r.nextID("alerts")
+	a.FiredAt = a.FiredAt.UTC()
+	r.alerts = append(r.alerts, a)
+	return a.ID, nil
+}
+
+// ListAlerts returns alerts matching q, newest first.
+func (r *memAlerts) ListAlerts(ctx context.Context, q AlertQuery) ([]models.Alert, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]models.Alert, 0, 32)
+	for _, a := range r.alerts {
+		if (q.RuleID != 0 && a.RuleID != q.RuleID) ||
+			(!q.From.IsZero() && a.FiredAt.Before(q.From)) || (!q.To.IsZero() && a.FiredAt.After(q.To)) {
+			continue
+		}
+		out = append(out, a)
+	}
+	slices.SortFunc(out, func(a, b models.Alert) int {
+		return cmp.Or(b.FiredAt.Compare(a.FiredAt), cmp.Compare(b.ID, a.ID))
+	})
+	return limited(out, q.Limit), nil
+}
+
+type memIncidents struct{ *memStore }
+
+var _ IncidentRepo = (*memIncidents)(nil)
+
+// listOrEmpty copies v, returning an empty slice for nil like a stored
+// empty JSON array.
+func listOrEmpty[T any](v []T) []T {
+	return append([]T{}, v...)
+}
+
+func (r *memIncidents) Create(ctx context.Context, inc models.Incident) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := models.Incident{
+		ID:            r.nextID("incidents"),
+		StartedAt:     inc.StartedAt.UTC(),
+		RunID:         inc.RunID,
+		AlarmCodes:    listOrEmpty(inc.AlarmCodes),
+		PeakTempC:     inc.PeakTempC,
+		PeakMeasuredC: inc.PeakMeasuredC,
+		Events:        []models.FurnaceEvent{},
+		Telemetry:     []models.HistoryBucket{},
+	}
+	r.incidents = append(r.incidents, stored)
+	return stored.ID, nil
+}
+
+func (r *memIncidents) Update(ctx context.Context, inc models.Incident) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := slices.IndexFunc(r.incidents, func(x models.Incident) bool { return x.ID == inc.ID })
+	if i < 0 {
+		return false, nil
+	}
+	old := &r.incidents[i]
+	old.EndedAt = clonedTimePtr(inc.EndedAt)
+	old.AlarmCodes = listOrEmpty(inc.AlarmCodes)
+	old.PeakTempC, old.PeakMeasuredC = inc.PeakTempC, inc.PeakMeasuredC
+	old.OverheatS, old.Resolution = inc.OverheatS, inc.Resolution
+	old.Events, old.Telemetry = listOrEmpty(inc.Events), listOrEmpty(inc.Telemetry)
+	return true, nil
+}
+
+func (r *memIncidents) Acknowledge(ctx context.Context, id int64, userID int, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := slices.IndexFunc(r.incidents, func(x models.Incident) bool { return x.ID == id })
+	if i < 0 || r.incidents[i].AckedBy != 0 {
+		return false, nil
+	}
+	r.incidents[i].AckedBy = userID
+	r.incidents[i].AckedAt = clonedTimePtr(&at)
+	return true, nil
+}
+
+// incident copies a stored incident, without its events and telemetry
+// unless detail is set. The caller holds the lock.
+func (s *memStore) incident(inc models.Incident, detail bool) models.Incident {
+	inc.AckedByName = s.username(inc.AckedBy)
+	inc.EndedAt, inc.AckedAt = clonedTimePtr(inc.EndedAt), clonedTimePtr(inc.AckedAt)
+	inc.AlarmCodes = listOrEmpty(inc.AlarmCodes)
+	if detail {
+		inc.Events, inc.Telemetry = listOrEmpty(inc.Events), listOrEmpty(inc.Telemetry)
+	} else {
+		inc.Events, inc.Telemetry = nil, nil
+	}
+	return inc
+}
+
+func (r *memIncidents) Get(ctx context.Context, id int64) (models.Incident, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, inc := range r.incidents {
+		if inc.ID == id {
+			return r.incident(inc, true), nil
+		}
+	}
+	return models.Incident{}, nil
+}
+
+func (r *memIncidents) Current(ctx context.Context) (models.Incident, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.incidents) - 1; i >= 0; i-- {
+		if r.incidents[i].EndedAt == nil {
+			return r.incident(r.incidents[i], true), nil
+		}
+	}
+	return models.Incident{}, nil
+}
+
+// List returns incidents that started within q, newest first, without
+// their events and telemetry.
+func (r *memIncidents) List(ctx context.Context, q IncidentQuery) ([]models.Incident, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]models.Incident, 0, 16)
+	for _, inc := range r.incidents {
+		if (!q.From.IsZero() && inc.StartedAt.Before(q.From)) || (!q.To.IsZero() && inc.StartedAt.After(q.To)) ||
+			(q.Alarm != "" && !slices.Contains(inc.AlarmCodes, q.Alarm)) {
+			continue
+		}
+		out = append(out, r.incident(inc, false))
+	}
+	slices.SortFunc(out, func(a, b models.Incident) int {
+		return cmp.Or(b.StartedAt.Compare(a.StartedAt), cmp.Compare(b.ID, a.ID))
+	})
+	return limited(out, q.Limit), nil
+}
+
+type memSequences struct{ *memStore }
+
+var _ SequenceRepo = (*memSequences)(nil)
+
+func (r *memSequences) Save(ctx context.Context, seq SequenceRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seq = cloneSequenceRecord(seq)
+	i := slices.IndexFunc(r.sequences, func(x SequenceRecord) bool { return x.ID == seq.ID })
+	if i < 0 {
+		r.sequences = append(r.sequences, seq)
+		return nil
+	}
+	// the steps and creation are kept from the first save
+	seq.Steps, seq.CreatedBy, seq.CreatedAt = r.sequences[i].Steps, r.sequences[i].CreatedBy, r.sequences[i].CreatedAt
+	r.sequences[i] = seq
+	return nil
+}
+
+func (r *memSequences) Get(ctx context.Context, id string) (SequenceRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, seq := range r.sequences {
+		if seq.ID == id {
+			return cloneSequenceRecord(seq), nil
+		}
+	}
+	return SequenceRecord{}, nil
+}
+
+func (r *memSequences) Running(ctx context.Context) (SequenceRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.sequences) - 1; i >= 0; i-- {
+		if r.sequences[i].Status == models.SequenceRunning {
+			return cloneSequenceRecord(r.sequences[i]), nil
+		}
+	}
+	return SequenceRecord{}, nil
+}
+
+// cloneSequenceRecord copies seq as it reads back after a save.
+func cloneSequenceRecord(seq SequenceRecord) SequenceRecord {
+	seq.Steps = listOrEmpty(seq.Steps)
+	seq.FinishedAt = clonedTimePtr(seq.FinishedAt)
+	seq.CreatedAt, seq.StepSince, seq.SavedAt = seq.CreatedAt.UTC(), seq.StepSince.UTC(), seq.SavedAt.UTC()
+	return seq
+}
+
+type memMaintenance struct{ *memStore }
+
+var _ MaintenanceRepo = (*memMaintenance)(nil)
+
+func (r *memMaintenance) CreateTask(ctx context.Context, t models.MaintenanceTask) (int, error) {
+	now := time.Now().UTC()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := models.MaintenanceTask{
+		ID:            int(r.nextID("maintenance_tasks")),
+		Name:          t.Name,
+		Kind:          t.Kind,
+		Part:          t.Part,
+		IntervalHours: t.IntervalHours,
+		IntervalDays:  t.IntervalDays,
+		BaselineAt:    t.BaselineAt.UTC(),
+		BaselineHours: t.BaselineHours,
+		CreatedBy:     t.CreatedBy,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	r.tasks = append(r.tasks, stored)
+	return stored.ID, nil
+}
+
+// task returns the stored task with the given ID, or nil. The caller holds
+// the lock.
+func (s *memStore) task(id int) *models.MaintenanceTask {
+	i := slices.IndexFunc(s.tasks, func(t models.MaintenanceTask) bool { return t.ID == id })
+	if i < 0 {
+		return nil
+	}
+	return &s.tasks[i]
+}
+
+func (r *memMaintenance) UpdateTask(ctx context.Context, t models.MaintenanceTask) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old := r.task(t.ID)
+	if old == nil {
+		return false, nil
+	}
+	old.Name, old.Kind, old.Part = t.Name, t.Kind, t.Part
+	old.IntervalHours, old.IntervalDays = t.IntervalHours, t.IntervalDays
+	old.OverdueLogged = false
+	old.UpdatedAt = time.Now().UTC()
+	return true, nil
+}
+
+func (r *memMaintenance) DeleteTask(ctx context.Context, id int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := len(r.tasks)
+	r.tasks = slices.DeleteFunc(r.tasks, func(t models.MaintenanceTask) bool { return t.ID == id })
+	return len(r.tasks) < n, nil
+}
+
+func (r *memMaintenance) MarkOverdue(ctx context.Context, id int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.task(id)
+	if t == nil {
+		return false, nil
+	}
+	t.OverdueLogged = true
+	return true, nil
+}
+
+func (r *memMaintenance) GetTask(ctx context.Context, id int) (models.MaintenanceTask, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.task(id)
+	if t == nil {
+		return models.MaintenanceTask{}, nil
+	}
+	out := *t
+	out.LastDoneAt = clonedTimePtr(t.LastDoneAt)
+	return out, nil
+}
+
+func (r *memMaintenance) ListTasks(ctx context.Context) ([]models.MaintenanceTask, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]models.MaintenanceTask, len(r.tasks))
+	for i, t := range r.tasks {
+		t.LastDoneAt = clonedTimePtr(t.LastDoneAt)
+		out[i] = t
+	}
+	return out, nil
+}
+
+func (r *memMaintenance) Complete(ctx context.Context, rec models.MaintenanceRecord) (int64, error) {
+	at := rec.CompletedAt.UTC()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.task(rec.TaskID)
+	if t == nil {
+		return 0, nil
+	}
+	t.BaselineAt, t.BaselineHours, t.LastDoneAt = at, rec.HeatingHours, &at
+	t.OverdueLogged = false
+	t.UpdatedAt = time.Now().UTC()
+
+	rec.ID = r.nextID("maintenance_records")
+	rec.CompletedAt = at
+	r.records = append(r.records, rec)
+	return rec.ID, nil
+}
+
+// ListRecords returns completion records matching q, newest first.
+func (r *memMaintenance) ListRecords(ctx context.Context, q MaintenanceRecordQuery) ([]models.MaintenanceRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]models.MaintenanceRecord, 0, 16)
+	for _, rec := range r.records {
+		if q.TaskID == 0 || rec.TaskID == q.TaskID {
+			out = append(out, rec)
+		}
+	}
+	slices.SortFunc(out, func(a, b models.MaintenanceRecord) int {
+		return cmp.Or(b.CompletedAt.Compare(a.CompletedAt), cmp.Compare(b.ID, a.ID))
+	})
+	return limited(out, q.Limit), nil
+}
+
+type memWebhooks struct{ *memStore }
+
+var _ WebhookRepo = (*memWebhooks)(nil)
+
+func (r *memWebhooks) CreateWebhook(ctx context.Context, w models.Webhook) (int, error) {
+	now := time.Now().UTC()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w.ID = int(r.nextID("webhooks"))
+	w.EventTypes = slices.Clone(w.EventTypes)
+	w.CreatedAt, w.UpdatedAt = now, now
+	r.webhooks = append(r.webhooks, w)
+	return w.ID, nil
+}
+
+func (r *memWebhooks) UpdateWebhook(ctx context.Context, w models.Webhook) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := slices.IndexFunc(r.webhooks, func(x models.Webhook) bool { return x.ID == w.ID })
+	if i < 0 {
+		return false, nil
+	}
+	old := &r.webhooks[i]
+	old.URL, old.EventTypes, old.Enabled = w.URL, slices.Clone(w.EventTypes), w.Enabled
+	if w.Secret != "" {
+		old.Secret = w.Secret
+	}
+	old.UpdatedAt = time.Now().UTC()
+	return true, nil
+}
+
+func (r *memWebhooks) DeleteWebhook(ctx context.Context, id int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := len(r.webhooks)
+	r.webhooks = slices.DeleteFunc(r.webhooks, func(w models.Webhook) bool { return w.ID == id })
+	if len(r.webhooks) == n {
+		return false, nil
+	}
+	r.deliveries = slices.DeleteFunc(r.deliveries, func(d models.WebhookDelivery) bool { return d.WebhookID == id })
+	return true, nil
+}
+
+// webhook copies a stored webhook as scanWebhook reads it.
+func (s *memStore) webhook(w models.Webhook) models.Webhook {
+	// event types are stored comma-joined
+	w.EventTypes = append([]string{}, w.EventTypes...)
+	if len(w.EventTypes) == 0 {
+		w.EventTypes = []string{""}
+	}
+	w.HasSecret = w.Secret != ""
+	return w
+}
+
+func (r *memWebhooks) GetWebhook(ctx context.Context, id int) (models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, w := range r.webhooks {
+		if w.ID == id {
+			return r.webhook(w), nil
+		}
+	}
+	return models.Webhook{}, nil
+}
+
+func (r *memWebhooks) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]models.Webhook, len(r.webhooks))
+	for i, w := range r.webhooks {
+		out[i] = r.webhook(w)
+	}
+	return out, nil
+}
+
+func (r *memWebhooks) NewEvents(ctx context.Context, limit int) ([]models.FurnaceEvent, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cursor == nil {
+		// start at the end of the log
+		var end int64
+		if n := len(r.events); n > 0 {
+			end = r.events[n-1].rowID
+		}
+		r.cursor = &end
+	}
+	cursor := *r.cursor
+	var out []models.FurnaceEvent
+	for _, e := range r.events {
+		if len(out) == limit {
+			break
+		}
+		if e.rowID > *r.cursor {
+			out = append(out, e.event())
+			cursor = e.rowID
+		}
+	}
+	return out, cursor, nil
+}
+
+func (r *memWebhooks) Enqueue(ctx context.Context, deliveries []models.WebhookDelivery, through int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range deliveries {
+		r.deliveries = append(r.deliveries, models.WebhookDelivery{
+			ID:            r.nextID("webhook_deliveries"),
+			WebhookID:     d.WebhookID,
+			EventID:       d.EventID,
+			EventType:     d.EventType,
+			Payload:       d.Payload,
+			Status:        d.Status,
+			NextAttemptAt: clonedTimePtr(d.NextAttemptAt),
+			CreatedAt:     d.CreatedAt.UTC(),
+		})
+	}
+	r.cursor = &through
+	return nil
+}
+
+// delivery copies a stored delivery.
+func delivery(d models.WebhookDelivery) models.WebhookDelivery {
+	d.NextAttemptAt = clonedTimePtr(d.NextAttemptAt)
+	d.LastAttemptAt = clonedTimePtr(d.LastAttemptAt)
+	d.DeliveredAt = clonedTimePtr(d.DeliveredAt)
+	return d
+}
+
+func (r *memWebhooks) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]models.WebhookDelivery, 0, 16)
+	for _, d := range r.deliveries {
+		if d.Status == "pending" && d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) {
+			out = append(out, delivery(d))
+		}
+	}
+	slices.SortStableFunc(out, func(a, b models.WebhookDelivery) int { return a.NextAttemptAt.Compare(*b.NextAttemptAt) })
+	return limited(out, limit), nil
+}
+
+func (r *memWebhooks) UpdateDelivery(ctx context.Context, d models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := slices.IndexFunc(r.deliveries, func(x models.WebhookDelivery) bool { return x.ID == d.ID })
+	if i < 0 {
+		return nil
+	}
+	old := &r.deliveries[i]
+	old.Status, old.Attempts = d.Status, d.Attempts
+	old.NextAttemptAt, old.LastAttemptAt = clonedTimePtr(d.NextAttemptAt), clonedTimePtr(d.LastAttemptAt)
+	old.ResponseStatus, old.LastError = d.ResponseStatus, d.LastError
+	old.LatencyMs, old.ResponseSnippet = d.LatencyMs, d.ResponseSnippet
+	old.DeliveredAt = clonedTimePtr(d.DeliveredAt)
+	return nil
+}
+
+func (r *memWebhooks) GetDelivery(ctx context.Context, id int64) (models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range r.deliveries {
+		if d.ID == id {
+			return delivery(d), nil
+		}
+	}
+	return models.WebhookDelivery{}, nil
+}
+
+// ListDeliveries returns deliveries matching q, newest first.
+func (r *memWebhooks) ListDeliveries(ctx context.Context, q WebhookDeliveryQuery) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]models.WebhookDelivery, 0, 16)
+	for i := len(r.deliveries) - 1; i >= 0; i-- {
+		d := r.deliveries[i]
+		if (q.WebhookID == 0 || d.WebhookID == q.WebhookID) && (q.Status == "" || d.Status == q.Status) {
+			out = append(out, delivery(d))
+		}
+	}
+	return limited(out, q.Limit), nil
+}
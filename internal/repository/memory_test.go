@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository/db"
+)
+
+// backends returns the in-memory repositories and SQLite ones on a fresh
+// database, each configured by cfg, so tests can check they behave the same.
+func backends(t *testing.T, cfg func() Config) map[string]*Repository {
+	t.Helper()
+	conn, err := db.InitDB(":memory:")
+	if err != nil {
+		t.Fatalf("init db: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return map[string]*Repository{
+		"memory": NewInMemoryWithConfig(cfg()),
+		"sqlite": NewRepositoryWithConfig(conn, cfg()),
+	}
+}
+
+// seqIDs returns IDs e1, e2, ... in order.
+func seqIDs() func() string {
+	n := 0
+	return func() string {
+		n++
+		return fmt.Sprintf("e%d", n)
+	}
+}
+
+func eventIDs(events []models.FurnaceEvent) []string {
+	ids := make([]string, len(events))
+	for i, ev := range events {
+		ids[i] = ev.EventID
+	}
+	return ids
+}
+
+func TestInMemory_EventsMatchSQLite(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	events := []models.FurnaceEvent{
+		{OccurredAt: base, Type: "start", Metadata: map[string]any{"run_id": "r1"}},
+		{OccurredAt: base.Add(time.Minute), Type: "MODE_CHANGE", Metadata: map[string]any{"run_id": "r1", "to": "HEAT", "limits": map[string]any{"max_c": 900}}},
+		{OccurredAt: base.Add(2 * time.Minute), Type: "ERROR", Metadata: map[string]any{"run_id": "r1", "ok": false}},
+		// imported late with an earlier timestamp
+		{OccurredAt: base.Add(30 * time.Second), Type: "TELEMETRY", Metadata: map[string]any{"temp_c": 850.5}},
+		{OccurredAt: base.Add(3 * time.Minute), Type: "STOP"},
+	}
+	queries := []EventQuery{
+		{},
+		{Type: "error"},
+		{RunID: "r1"},
+		{From: base.Add(20 * time.Second), To: base.Add(2 * time.Minute)},
+		{Types: []string{"start", "STOP"}},
+		{ExcludeTypes: []string{"TELEMETRY"}},
+		{Meta: []MetaFilter{{Key: "to", Op: "=", Value: "HEAT"}}},
+		{Meta: []MetaFilter{{Key: "to", Op: "!=", Value: "COOL"}}},
+		{Meta: []MetaFilter{{Key: "limits.max_c", Op: ">=", Value: "900"}}},
+		{Meta: []MetaFilter{{Key: "temp_c", Op: "<", Value: "851"}}},
+		{Meta: []MetaFilter{{Key: "ok", Op: "=", Value: "false"}}},
+	}
+
+	got := make(map[string][][]string)
+	for name, repos := range backends(t, func() Config { return Config{NewID: seqIDs()} }) {
+		if err := repos.EventRepo.AppendBatch(ctx, events); err != nil {
+			t.Fatalf("%s: append: %v", name, err)
+		}
+		var results [][]string
+		for _, q := range queries {
+			evs, err := repos.EventRepo.Query(ctx, q)
+			if err != nil {
+				t.Fatalf("%s: query %+v: %v", name, q, err)
+			}
+			results = append(results, eventIDs(evs))
+		}
+		page, next, err := repos.Events.Page(ctx, EventQuery{}, EventPageQuery{Limit: 2, Desc: true})
+		if err != nil || next == nil {
+			t.Fatalf("%s: page: %v, next %v", name, err, next)
+		}
+		rest, _, err := repos.Events.Page(ctx, EventQuery{}, EventPageQuery{Limit: 10, Desc: true, After: next})
+		if err != nil {
+			t.Fatalf("%s: second page: %v", name, err)
+		}
+		results = append(results, eventIDs(page), eventIDs(rest))
+		tail, last, err := repos.Events.Tail(ctx, EventQuery{}, EventTailQuery{AfterID: "e2"})
+		if err != nil {
+			t.Fatalf("%s: tail: %v", name, err)
+		}
+		results = append(results, append(eventIDs(tail), last))
+		if _, _, err := repos.Events.Tail(ctx, EventQuery{}, EventTailQuery{AfterID: "gone"}); !errors.Is(err, ErrEventNotFound) {
+			t.Fatalf("%s: tail after unknown event: %v", name, err)
+		}
+		got[name] = results
+	}
+	if !reflect.DeepEqual(got["memory"], got["sqlite"]) {
+		t.Fatalf("in-memory results differ from SQLite:\nmemory %v\nsqlite %v", got["memory"], got["sqlite"])
+	}
+}
+
+func TestInMemory_PurgeKeepsChainValid(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	for name, repos := range backends(t, func() Config { return Config{EventHashChain: true, NewID: seqIDs()} }) {
+		for i := 0; i < 5; i++ {
+			if err := repos.EventRepo.Append(ctx, models.FurnaceEvent{OccurredAt: base.Add(time.Duration(i) * time.Minute), Type: "START"}); err != nil {
+				t.Fatalf("%s: append: %v", name, err)
+			}
+		}
+		if _, err := repos.Comments.AddComment(ctx, models.EventComment{EventID: "e1", Text: "old", CreatedAt: base}); err != nil {
+			t.Fatalf("%s: comment: %v", name, err)
+		}
+
+		archive := &sliceArchive{}
+		rep, err := repos.Retention.Purge(ctx, EventPurge{KeepMax: 2, Archive: "a.ndjson.gz"}, archive)
+		if err != nil {
+			t.Fatalf("%s: purge: %v", name, err)
+		}
+		if rep.Deleted != 3 || !rep.Newest.Equal(base.Add(2*time.Minute)) || rep.Archive != "a.ndjson.gz" {
+			t.Fatalf("%s: report %+v", name, rep)
+		}
+		if len(archive.events) != 3 || !archive.closed {
+			t.Fatalf("%s: archived %d events, closed %v", name, len(archive.events), archive.closed)
+		}
+		chain, err := repos.Chain.VerifyChain(ctx)
+		if err != nil || !chain.Valid || chain.Checked != 2 {
+			t.Fatalf("%s: chain after purge %+v, %v", name, chain, err)
+		}
+		comments, err := repos.Comments.CommentsFor(ctx, []string{"e1"})
+		if err != nil || len(comments) != 0 {
+			t.Fatalf("%s: comments on purged event %v, %v", name, comments, err)
+		}
+		if _, err := repos.Comments.AddComment(ctx, models.EventComment{EventID: "e1", Text: "late"}); !errors.Is(err, ErrEventNotFound) {
+			t.Fatalf("%s: comment on purged event: %v", name, err)
+		}
+	}
+}
+
+func TestInMemory_Users(t *testing.T) {
+	ctx := context.Background()
+	repos := NewInMemory()
+	id, err := repos.Auth.Create(ctx, "Alice", "hash")
+	if err != nil || id != 1 {
+		t.Fatalf("create: %d, %v", id, err)
+	}
+	if _, err := repos.Auth.Create(ctx, "alice", "hash"); !errors.Is(err, ErrUsernameTaken) {
+		t.Fatalf("case-only duplicate: %v", err)
+	}
+	u, err := repos.Auth.GetByUsername(ctx, "ALICE")
+	if err != nil || u == nil || u.Username != "Alice" || u.Role != models.RoleViewer {
+		t.Fatalf("lookup: %+v, %v", u, err)
+	}
+	if u, err := repos.Auth.GetByUsername(ctx, "bob"); u != nil || err != nil {
+		t.Fatalf("missing user: %+v, %v", u, err)
+	}
+	if err := repos.Auth.SetRole(ctx, 7, models.RoleAdmin); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("set role of missing user: %v", err)
+	}
+}
+
+func TestInMemory_WebhooksStartAtEndOfLog(t *testing.T) {
+	ctx := context.Background()
+	repos := NewInMemory()
+	_ = repos.EventRepo.Append(ctx, models.FurnaceEvent{Type: "START"})
+
+	events, cursor, err := repos.Webhooks.NewEvents(ctx, 10)
+	if err != nil || len(events) != 0 || cursor != 1 {
+		t.Fatalf("first read replayed history: %v, cursor %d, %v", events, cursor, err)
+	}
+	_ = repos.EventRepo.Append(ctx, models.FurnaceEvent{Type: "STOP"})
+	events, cursor, err = repos.Webhooks.NewEvents(ctx, 10)
+	if err != nil || len(events) != 1 || events[0].Type != "STOP" || cursor != 2 {
+		t.Fatalf("new events %v, cursor %d, %v", events, cursor, err)
+	}
+	next := time.Now().Add(-time.Second)
+	err = repos.Webhooks.Enqueue(ctx, []models.WebhookDelivery{{WebhookID: 1, EventID: events[0].EventID, Status: "pending", NextAttemptAt: &next}}, cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	due, err := repos.Webhooks.DueDeliveries(ctx, time.Now(), 10)
+	if err != nil || len(due) != 1 || due[0].ID != 1 {
+		t.Fatalf("due deliveries %v, %v", due, err)
+	}
+	if events, _, _ := repos.Webhooks.NewEvents(ctx, 10); len(events) != 0 {
+		t.Fatalf("enqueued events returned again: %v", events)
+	}
+}
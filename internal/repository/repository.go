@@ -4,12 +4,22 @@ import (
 	"context"
 	"controlling_furnace/internal/models"
 	"database/sql"
+	"fmt"
 	"time"
 )
 
 type Authorization interface {
-	Create(username, hash string) (int, error)
-	GetByUsername(username string) (*models.User, error)
+	Create(ctx context.Context, username, hash string) (int, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	Count(ctx context.Context) (int, error)
+	SetRole(ctx context.Context, id int, role string) error
+}
+
+// InstallRepo stores the settings chosen at first-run setup.
+type InstallRepo interface {
+	Save(ctx context.Context, inst models.Installation) error
+	// Load returns a zero value before setup.
+	Load(ctx context.Context) (models.Installation, error)
 }
 
 type StateRepo interface {
@@ -19,27 +29,493 @@ type StateRepo interface {
 
 type EventRepo interface {
 	Append(ctx context.Context, e models.FurnaceEvent) error
+	// AppendBatch appends events in order in one transaction; either all
+	// of them are stored or none.
+	AppendBatch(ctx context.Context, events []models.FurnaceEvent) error
 	List(ctx context.Context, from, to time.Time, typ string) ([]models.FurnaceEvent, error)
+	Query(ctx context.Context, q EventQuery) ([]models.FurnaceEvent, error)
+}
+
+// EventStreamRepo iterates the event log without loading it into memory.
+type EventStreamRepo interface {
+	// Each calls fn for every event matching q in the order of
+	// EventRepo.Query, stopping at the first error fn returns.
+	Each(ctx context.Context, q EventQuery, fn func(models.FurnaceEvent) error) error
+	// Page returns up to p.Limit events matching q after p.After, and the
+	// key to pass as After for the next page; nil once the log is exhausted.
+	Page(ctx context.Context, q EventQuery, p EventPageQuery) ([]models.FurnaceEvent, *EventKey, error)
+	// Tail returns up to t.Limit events matching q appended after the
+	// point t selects, in the order they were appended, and the ID of the
+	// event to continue after. It fails with ErrEventNotFound if
+	// t.AfterID is not in the log.
+	Tail(ctx context.Context, q EventQuery, t EventTailQuery) ([]models.FurnaceEvent, string, error)
+}
+
+// EventCommentRepo stores operators' comments on logged events.
+type EventCommentRepo interface {
+	// AddComment stores c and returns its ID. It fails with
+	// ErrEventNotFound if c.EventID is not in the log.
+	AddComment(ctx context.Context, c models.EventComment) (int64, error)
+	// CommentsFor returns the comments on the given events keyed by event
+	// ID, oldest first. Events without comments are left out.
+	CommentsFor(ctx context.Context, eventIDs []string) (map[string][]models.EventComment, error)
+}
+
+// EventChainRepo verifies the tamper-evident hash chain over the event log.
+type EventChainRepo interface {
+	VerifyChain(ctx context.Context) (models.ChainReport, error)
+}
+
+// RunRepo stores one record per heat cycle.
+type RunRepo interface {
+	Save(ctx context.Context, r models.Run) error
+	// Get returns the run, or a zero Run if it does not exist.
+	Get(ctx context.Context, runID string) (models.Run, error)
+}
+
+// TelemetryRepo stores time series samples per channel.
+type TelemetryRepo interface {
+	Append(ctx context.Context, samples ...models.TelemetrySample) error
+	Query(ctx context.Context, q TelemetryQuery) ([]models.TelemetrySample, error)
+}
+
+// SampleRepo stores the temperature history and serves it downsampled.
+type SampleRepo interface {
+	Append(ctx context.Context, s models.FurnaceSample) error
+	Buckets(ctx context.Context, q HistoryQuery) ([]models.HistoryBucket, error)
+}
+
+// SampleRollupRepo keeps hourly and daily summaries of the temperature
+// history, which Buckets reads for coarse resolutions, so the per-tick
+// samples can be pruned.
+type SampleRollupRepo interface {
+	// Rollup summarises every hour and day that ended by before and was
+	// not summarised yet, and returns the number of summaries written.
+	Rollup(ctx context.Context, before time.Time) (int, error)
+	// PruneSamples deletes the samples older than before that a summary
+	// covers, and returns how many it deleted.
+	PruneSamples(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SimSettingsRepo persists the runtime simulator settings.
+type SimSettingsRepo interface {
+	Save(ctx context.Context, s models.SimSettings) error
+	// Load returns the saved settings, or a zero value if none were saved.
+	Load(ctx context.Context) (models.SimSettings, error)
+}
+
+// HealthRepo persists the heater wear counters.
+type HealthRepo interface {
+	Save(ctx context.Context, h models.FurnaceHealth) error
+	// Load returns the saved counters, or a zero value if none were saved.
+	Load(ctx context.Context) (models.FurnaceHealth, error)
+}
+
+// StatusRepo reports whether the database can serve requests.
+type StatusRepo interface {
+	// Ping fails when the database is unreachable or locked.
+	Ping(ctx context.Context) error
+	// CheckSchema fails when schema migrations have not been applied.
+	CheckSchema(ctx context.Context) error
+	// Stats reports connection pool usage.
+	Stats() sql.DBStats
+}
+
+// UptimeRepo records the outcome of periodic readiness checks.
+type UptimeRepo interface {
+	RecordCheck(ctx context.Context, c models.UptimeCheck) error
+	// LastCheck returns the newest check, or a zero UptimeCheck if none
+	// was recorded.
+	LastCheck(ctx context.Context) (models.UptimeCheck, error)
+	// CountChecks tallies the checks at or after since.
+	CountChecks(ctx context.Context, since time.Time) (models.UptimeCounts, error)
+	// PurgeChecks removes checks before the cutoff and reports how many.
+	PurgeChecks(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AlertRepo stores alert rules and the alerts they fired.
+type AlertRepo interface {
+	CreateRule(ctx context.Context, r models.AlertRule) (int, error)
+	// UpdateRule and DeleteRule report false if the rule does not exist.
+	UpdateRule(ctx context.Context, r models.AlertRule) (bool, error)
+	DeleteRule(ctx context.Context, id int) (bool, error)
+	// GetRule returns the rule, or a zero AlertRule if it does not exist.
+	GetRule(ctx context.Context, id int) (models.AlertRule, error)
+	ListRules(ctx context.Context) ([]models.AlertRule, error)
+	AppendAlert(ctx context.Context, a models.Alert) (int64, error)
+	ListAlerts(ctx context.Context, q AlertQuery) ([]models.Alert, error)
+}
+
+// MaintenanceRepo stores maintenance tasks and their completion records.
+type MaintenanceRepo interface {
+	CreateTask(ctx context.Context, t models.MaintenanceTask) (int, error)
+	// UpdateTask, DeleteTask and MarkOverdue report false if the task does
+	// not exist. UpdateTask keeps the baseline and completion history.
+	UpdateTask(ctx context.Context, t models.MaintenanceTask) (bool, error)
+	DeleteTask(ctx context.Context, id int) (bool, error)
+	MarkOverdue(ctx context.Context, id int) (bool, error)
+	// GetTask returns the task, or a zero MaintenanceTask if it does not exist.
+	GetTask(ctx context.Context, id int) (models.MaintenanceTask, error)
+	ListTasks(ctx context.Context) ([]models.MaintenanceTask, error)
+	// Complete records rec and restarts the task's interval from it. It
+	// returns the record ID, or 0 if the task does not exist.
+	Complete(ctx context.Context, rec models.MaintenanceRecord) (int64, error)
+	ListRecords(ctx context.Context, q MaintenanceRecordQuery) ([]models.MaintenanceRecord, error)
+}
+
+// WebhookRepo stores webhooks and the queue of their deliveries.
+type WebhookRepo interface {
+	CreateWebhook(ctx context.Context, w models.Webhook) (int, error)
+	// UpdateWebhook and DeleteWebhook report false if the webhook does not
+	// exist. An empty w.Secret keeps the stored one; DeleteWebhook also
+	// drops its deliveries.
+	UpdateWebhook(ctx context.Context, w models.Webhook) (bool, error)
+	DeleteWebhook(ctx context.Context, id int) (bool, error)
+	// GetWebhook returns the webhook, or a zero Webhook if it does not exist.
+	GetWebhook(ctx context.Context, id int) (models.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]models.Webhook, error)
+	// NewEvents returns up to limit events appended after the delivery
+	// cursor, in insertion order, and the cursor value after the last of
+	// them. The cursor starts at the end of the log, so history is not
+	// replayed to new installations.
+	NewEvents(ctx context.Context, limit int) ([]models.FurnaceEvent, int64, error)
+	// Enqueue stores deliveries and moves the cursor to through in one
+	// transaction.
+	Enqueue(ctx context.Context, deliveries []models.WebhookDelivery, through int64) error
+	// DueDeliveries returns pending deliveries whose next attempt is due at
+	// now, oldest first.
+	DueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error)
+	// UpdateDelivery stores the outcome of an attempt.
+	UpdateDelivery(ctx context.Context, d models.WebhookDelivery) error
+	// GetDelivery returns the delivery, or a zero WebhookDelivery if it
+	// does not exist.
+	GetDelivery(ctx context.Context, id int64) (models.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, q WebhookDeliveryQuery) ([]models.WebhookDelivery, error)
+}
+
+// IncidentRepo stores the records of alarm episodes.
+type IncidentRepo interface {
+	Create(ctx context.Context, inc models.Incident) (int64, error)
+	// Update and Acknowledge report false if the incident does not exist;
+	// Acknowledge also if it was already acknowledged.
+	Update(ctx context.Context, inc models.Incident) (bool, error)
+	Acknowledge(ctx context.Context, id int64, userID int, at time.Time) (bool, error)
+	// Get returns the incident, or a zero Incident if it does not exist.
+	Get(ctx context.Context, id int64) (models.Incident, error)
+	// Current returns the open incident, or a zero Incident if none is open.
+	Current(ctx context.Context) (models.Incident, error)
+	List(ctx context.Context, q IncidentQuery) ([]models.Incident, error)
+}
+
+// SequenceRepo stores submitted mode sequences with the progress of their
+// current step, so the running one can be resumed after a restart.
+type SequenceRepo interface {
+	// Save stores seq, replacing its earlier save.
+	Save(ctx context.Context, seq SequenceRecord) error
+	// Get returns the sequence, or a zero SequenceRecord if it does not exist.
+	Get(ctx context.Context, id string) (SequenceRecord, error)
+	// Running returns the sequence left running, or a zero SequenceRecord.
+	Running(ctx context.Context) (SequenceRecord, error)
+}
+
+// SequenceRecord is a sequence with what is needed to carry on with its
+// current step.
+type SequenceRecord struct {
+	models.Sequence
+	StepSince time.Time // UpdatedAt of the state the step was entered in
+	StepRunID string    // run a HEAT step started
+	HeldS     float64   // simulated seconds a COOL or STANDBY step has held
+	SavedAt   time.Time // when the progress was saved
+}
+
+// BackupRepo copies the database while it is in use.
+type BackupRepo interface {
+	// Backup writes a snapshot of the whole database to path, which must
+	// not exist yet.
+	Backup(ctx context.Context, path string) error
+}
+
+// ImportRepo bulk-loads history migrated from other systems. Both methods
+// skip records that are already stored and return how many were inserted.
+type ImportRepo interface {
+	ImportEvents(ctx context.Context, events []models.FurnaceEvent) (int, error)
+	ImportTelemetry(ctx context.Context, samples []models.TelemetrySample) (int, error)
+}
+
+// TelemetryQuery holds the filters accepted by TelemetryRepo.Query.
+// Zero values disable the corresponding filter.
+type TelemetryQuery struct {
+	Channel string
+	From    time.Time // inclusive lower bound
+	To      time.Time // inclusive upper bound
+	Limit   int       // maximum number of samples
+}
+
+// HistoryQuery selects the samples aggregated by SampleRepo.Buckets.
+type HistoryQuery struct {
+	From       time.Time     // inclusive lower bound
+	To         time.Time     // inclusive upper bound
+	Resolution time.Duration // bucket width, whole seconds
+}
+
+// AlertQuery holds the filters accepted by AlertRepo.ListAlerts.
+// Zero values disable the corresponding filter.
+type AlertQuery struct {
+	RuleID int
+	From   time.Time // inclusive lower bound
+	To     time.Time // inclusive upper bound
+	Limit  int       // maximum number of alerts
+}
+
+// WebhookDeliveryQuery holds the filters accepted by
+// WebhookRepo.ListDeliveries. Zero values disable the corresponding filter.
+type WebhookDeliveryQuery struct {
+	WebhookID int
+	Status    string
+	Limit     int // maximum number of deliveries
+}
+
+// MaintenanceRecordQuery holds the filters accepted by
+// MaintenanceRepo.ListRecords. Zero values disable the corresponding filter.
+type MaintenanceRecordQuery struct {
+	TaskID int
+	Limit  int // maximum number of records
+}
+
+// IncidentQuery holds the filters accepted by IncidentRepo.List.
+// Zero values disable the corresponding filter.
+type IncidentQuery struct {
+	From  time.Time // inclusive lower bound on the start
+	To    time.Time // inclusive upper bound on the start
+	Alarm string    // an alarm code the incident raised, e.g. OVERHEAT
+	Limit int       // maximum number of incidents
+}
+
+// EventQuery holds the filters accepted by EventRepo.Query.
+// Zero values disable the corresponding filter.
+type EventQuery struct {
+	From  time.Time // inclusive lower bound
+	To    time.Time // inclusive upper bound
+	Type  string    // exact event type
+	RunID string    // run_id stored in event metadata
+	// Types keeps events of any of these types and ExcludeTypes drops
+	// events of these; either may be combined with Type.
+	Types        []string
+	ExcludeTypes []string
+	// Meta keeps events whose metadata satisfies every filter.
+	Meta []MetaFilter
+	// IncludeDeleted also returns soft-deleted events; see EventDeletionRepo.
+	IncludeDeleted bool
+}
+
+// MetaFilter compares one metadata value. Events without the key never
+// match, not even with !=.
+type MetaFilter struct {
+	Key   string // dotted path below the metadata root, e.g. "to" or "limits.max_c"
+	Op    string // =, !=, <, <=, > or >=
+	Value string // compared as a number or boolean too when it parses as one
+}
+
+// EventKey is the position of an event in the log: its stored occurred_at
+// text and rowid, which break ties between events in the same second.
+type EventKey struct {
+	At    string
+	RowID int64
+}
+
+// EventPageQuery selects one page of the event log.
+type EventPageQuery struct {
+	After *EventKey // nil starts at the first event, or the last when Desc
+	Limit int       // 0 means EventPageSize
+	Desc  bool      // newest first
+}
+
+// EventTailQuery selects the events appended after a point in the log.
+type EventTailQuery struct {
+	AfterID string    // continue after this event; with a zero AfterAt too, start at the end of the log
+	AfterAt time.Time // only events that occurred after this
+	Limit   int       // 0 means EventPageSize
 }
 
 type Repository struct {
-	StateRepo StateRepo
-	EventRepo EventRepo
-	Auth      Authorization
+	StateRepo   StateRepo
+	EventRepo   EventRepo
+	Events      EventStreamRepo
+	Chain       EventChainRepo
+	Comments    EventCommentRepo
+	Retention   EventRetentionRepo
+	Deletions   EventDeletionRepo
+	RunRepo     RunRepo
+	Telemetry   TelemetryRepo
+	Samples     SampleRepo
+	Rollups     SampleRollupRepo
+	Settings    SimSettingsRepo
+	Health      HealthRepo
+	Alerts      AlertRepo
+	Incidents   IncidentRepo
+	Sequences   SequenceRepo
+	Maintenance MaintenanceRepo
+	Webhooks    WebhookRepo
+	Import      ImportRepo
+	Status      StatusRepo
+	Backup      BackupRepo
+	Uptime      UptimeRepo
+	Auth        Authorization
+	Install     InstallRepo
+	// UnitOfWork commits a state save and event appends together.
+	UnitOfWork UnitOfWork
+
+	// Chaos is set when the repositories are wrapped with fault injection.
+	Chaos *Chaos
 }
 
 // Provide indirection for constructor functions to enable test doubles.
 // These default to the real constructors and can be overridden in tests.
 var (
-	newStateRepoFn = NewStateSQLite
-	newEventRepoFn = NewEventSQLite
-	newAuthRepoFn  = NewUserRepository
+	newStateRepoFn   = NewStateSQLite
+	newEventRepoFn   = NewEventSQLite
+	newCommentFn     = NewEventCommentSQLite
+	newRunRepoFn     = NewRunSQLite
+	newTelemetryFn   = NewTelemetrySQLite
+	newSamplesFn     = NewSampleSQLite
+	newSettingsFn    = NewSimSettingsSQLite
+	newHealthFn      = NewHealthSQLite
+	newAlertFn       = NewAlertSQLite
+	newIncidentFn    = NewIncidentSQLite
+	newSequenceFn    = NewSequenceSQLite
+	newMaintenanceFn = NewMaintenanceSQLite
+	newWebhookFn     = NewWebhookSQLite
+	newImportFn      = NewImportSQLite
+	newStatusFn      = NewStatusSQLite
+	newBackupFn      = NewBackupSQLite
+	newUptimeFn      = NewUptimeSQLite
+	newAuthRepoFn    = NewUserRepository
+	newInstallFn     = NewInstallSQLite
 )
 
+// DefaultSite is the site of a deployment that does not name one, and of
+// the rows stored before sites existed.
+const DefaultSite = "default"
+
+// maxSiteLen bounds site IDs, which are stored on every event.
+const maxSiteLen = 64
+
+// ValidateSite checks a configured site ID: empty (DefaultSite) or up to
+// maxSiteLen letters, digits, '-', '_' and '.'.
+func ValidateSite(id string) error {
+	if len(id) > maxSiteLen {
+		return fmt.Errorf("site id %q is longer than %d characters", id, maxSiteLen)
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.':
+		default:
+			return fmt.Errorf("site id %q may only contain letters, digits, '-', '_' and '.'", id)
+		}
+	}
+	return nil
+}
+
+// Config holds optional repository behaviour.
+type Config struct {
+	// Site scopes every table, the installation row with its signing key
+	// included, so backends for several sites can share a database;
+	// DefaultSite when empty.
+	Site string
+	// EventHashChain links every new event to the previous one by hash, so
+	// edits and deletions in furnace_events can be detected.
+	EventHashChain bool
+	// NewID and Clock fill events appended without an ID or timestamp;
+	// random UUIDs and time.Now when nil.
+	NewID func() string
+	Clock func() time.Time
+	// Cipher encrypts the columns holding credentials (see
+	// encryptedColumns); nil stores them in plaintext.
+	Cipher *ColumnCipher
+}
+
 func NewRepository(db *sql.DB) *Repository {
+	return NewRepositoryWithConfig(db, Config{})
+}
+
+// NewRepositoryWithConfig is NewRepository with explicit options.
+func NewRepositoryWithConfig(db *sql.DB, cfg Config) *Repository {
+	if cfg.Site == "" {
+		cfg.Site = DefaultSite
+	}
+	state := newStateRepoFn(db)
+	state.site = cfg.Site
+	events := newEventRepoFn(db)
+	events.site = cfg.Site
+	events.hashChain = cfg.EventHashChain
+	if cfg.NewID != nil {
+		events.newID = cfg.NewID
+	}
+	if cfg.Clock != nil {
+		events.now = cfg.Clock
+	}
+	comments := newCommentFn(db)
+	comments.site = cfg.Site
+	imports := newImportFn(db)
+	imports.site = cfg.Site
+	imports.hashChain = cfg.EventHashChain
+	samples := newSamplesFn(db)
+	samples.site = cfg.Site
+	webhooks := newWebhookFn(db)
+	webhooks.site = cfg.Site
+	webhooks.cipher = cfg.Cipher
+	auth := newAuthRepoFn(db)
+	auth.site = cfg.Site
+	auth.cipher = cfg.Cipher
+	install := newInstallFn(db)
+	install.site = cfg.Site
+	install.cipher = cfg.Cipher
+	runs := newRunRepoFn(db)
+	runs.site = cfg.Site
+	telemetry := newTelemetryFn(db)
+	telemetry.site = cfg.Site
+	settings := newSettingsFn(db)
+	settings.site = cfg.Site
+	health := newHealthFn(db)
+	health.site = cfg.Site
+	alerts := newAlertFn(db)
+	alerts.site = cfg.Site
+	incidents := newIncidentFn(db)
+	incidents.site = cfg.Site
+	sequences := newSequenceFn(db)
+	sequences.site = cfg.Site
+	maintenance := newMaintenanceFn(db)
+	maintenance.site = cfg.Site
+	backup := newBackupFn(db)
+	backup.site = cfg.Site
+	uptime := newUptimeFn(db)
+	uptime.site = cfg.Site
 	return &Repository{
-		StateRepo: newStateRepoFn(db),
-		EventRepo: newEventRepoFn(db),
-		Auth:      newAuthRepoFn(db),
+		StateRepo:   state,
+		EventRepo:   events,
+		Events:      events,
+		Chain:       events,
+		Comments:    comments,
+		Retention:   events,
+		Deletions:   events,
+		RunRepo:     runs,
+		Telemetry:   telemetry,
+		Samples:     samples,
+		Rollups:     samples,
+		Settings:    settings,
+		Health:      health,
+		Alerts:      alerts,
+		Incidents:   incidents,
+		Sequences:   sequences,
+		Maintenance: maintenance,
+		Webhooks:    webhooks,
+		Import:      imports,
+		Status:      newStatusFn(db),
+		Backup:      backup,
+		Uptime:      uptime,
+		Auth:        auth,
+		Install:     install,
+		UnitOfWork:  NewUnitOfWorkSQLite(db, events),
 	}
 }
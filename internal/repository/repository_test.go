@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository/db"
+)
+
+func TestRepository_SitesShareTheDatabaseButNotTheirData(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.InitDB(filepath.Join(t.TempDir(), "furnace.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	a := NewRepositoryWithConfig(conn, Config{NewID: seqIDs(), EventHashChain: true, Site: "plant-a"})
+	b := NewRepositoryWithConfig(conn, Config{EventHashChain: true, Site: "plant-b"})
+
+	// the same username in both sites
+	for _, r := range []*Repository{a, b} {
+		if _, err := r.Auth.Create(ctx, "ann", "hash"); err != nil {
+			t.Fatalf("create ann: %v", err)
+		}
+	}
+	if _, err := a.Auth.Create(ctx, "bob", "hash"); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := a.Auth.Count(ctx); err != nil || n != 2 {
+		t.Fatalf("plant-a users = %d, %v", n, err)
+	}
+	if n, err := b.Auth.Count(ctx); err != nil || n != 1 {
+		t.Fatalf("plant-b users = %d, %v", n, err)
+	}
+	if u, err := b.Auth.GetByUsername(ctx, "bob"); err != nil || u != nil {
+		t.Fatal("plant-b sees plant-a's user")
+	}
+
+	if err := a.StateRepo.Save(ctx, models.FurnaceState{Mode: "HEAT", TargetTempC: 200}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.StateRepo.Save(ctx, models.FurnaceState{Mode: "STANDBY", TargetTempC: 20}); err != nil {
+		t.Fatal(err)
+	}
+	if st, err := a.StateRepo.Load(ctx); err != nil || st.Mode != "HEAT" || st.TargetTempC != 200 {
+		t.Fatalf("plant-a state = %+v, %v", st, err)
+	}
+	if st, err := b.StateRepo.Load(ctx); err != nil || st.Mode != "STANDBY" || st.TargetTempC != 20 {
+		t.Fatalf("plant-b state = %+v, %v", st, err)
+	}
+
+	// interleaved appends keep one chain per site
+	at := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	for i, r := range []*Repository{a, b, a} {
+		e := models.FurnaceEvent{EventID: "e" + string(rune('1'+i)), OccurredAt: at.Add(time.Duration(i) * time.Hour), Type: "NOTE"}
+		if err := r.EventRepo.Append(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, err := a.EventRepo.Query(ctx, EventQuery{}); err != nil || len(got) != 2 {
+		t.Fatalf("plant-a events = %v, %v", got, err)
+	}
+	if got, err := b.EventRepo.Query(ctx, EventQuery{}); err != nil || len(got) != 1 || got[0].EventID != "e2" {
+		t.Fatalf("plant-b events = %v, %v", got, err)
+	}
+	for site, r := range map[string]*Repository{"plant-a": a, "plant-b": b} {
+		if rep, err := r.Chain.VerifyChain(ctx); err != nil || !rep.Valid {
+			t.Fatalf("%s chain: %+v, %v", site, rep, err)
+		}
+	}
+
+	// a purge only reaches its own site's events
+	if rep, err := b.Retention.Purge(ctx, EventPurge{Before: at.Add(24 * time.Hour)}, nil); err != nil || rep.Deleted != 1 {
+		t.Fatalf("plant-b purge = %+v, %v", rep, err)
+	}
+	if got, err := a.EventRepo.Query(ctx, EventQuery{}); err != nil || len(got) != 2 {
+		t.Fatalf("plant-a events after plant-b's purge = %v, %v", got, err)
+	}
+	if rep, err := a.Chain.VerifyChain(ctx); err != nil || !rep.Valid || rep.Checked != 2 {
+		t.Fatalf("plant-a chain after plant-b's purge: %+v, %v", rep, err)
+	}
+}
+
+func TestRepository_SitesKeepTheirOwnSettingsAndRecords(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.InitDB(filepath.Join(t.TempDir(), "furnace.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	a := NewRepositoryWithConfig(conn, Config{Site: "plant-a"})
+	b := NewRepositoryWithConfig(conn, Config{Site: "plant-b"})
+
+	if err := a.Install.Save(ctx, models.Installation{SigningKey: "key-a"}); err != nil {
+		t.Fatal(err)
+	}
+	if inst, err := b.Install.Load(ctx); err != nil || inst.SigningKey != "" {
+		t.Fatalf("plant-b sees plant-a's signing key: %+v, %v", inst, err)
+	}
+	if err := b.Install.Save(ctx, models.Installation{SigningKey: "key-b"}); err != nil {
+		t.Fatal(err)
+	}
+	if inst, err := a.Install.Load(ctx); err != nil || inst.SigningKey != "key-a" {
+		t.Fatalf("plant-a installation = %+v, %v", inst, err)
+	}
+	if err := a.Settings.Save(ctx, models.SimSettings{TickMs: 250}); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := b.Settings.Load(ctx); err != nil || s.TickMs != 0 {
+		t.Fatalf("plant-b settings = %+v, %v", s, err)
+	}
+
+	at := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	if err := a.RunRepo.Save(ctx, models.Run{RunID: "run-1", TargetTempC: 800, StartedAt: at}); err != nil {
+		t.Fatal(err)
+	}
+	// another site cannot take over the run by saving the same ID
+	if err := b.RunRepo.Save(ctx, models.Run{RunID: "run-1", TargetTempC: 20, StartedAt: at}); err != nil {
+		t.Fatal(err)
+	}
+	if run, err := b.RunRepo.Get(ctx, "run-1"); err != nil || run.RunID != "" {
+		t.Fatalf("plant-b sees plant-a's run: %+v, %v", run, err)
+	}
+	if run, err := a.RunRepo.Get(ctx, "run-1"); err != nil || run.TargetTempC != 800 {
+		t.Fatalf("plant-a run = %+v, %v", run, err)
+	}
+	if err := a.Telemetry.Append(ctx, models.TelemetrySample{At: at, Channel: models.ChannelChamber, Value: 700}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := b.Telemetry.Query(ctx, TelemetryQuery{}); err != nil || len(got) != 0 {
+		t.Fatalf("plant-b telemetry = %v, %v", got, err)
+	}
+	if err := a.Samples.Append(ctx, models.FurnaceSample{At: at, TempC: 700, Mode: "HEAT"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := b.Samples.Buckets(ctx, HistoryQuery{From: at, To: at.Add(time.Hour), Resolution: time.Minute}); err != nil || len(got) != 0 {
+		t.Fatalf("plant-b history = %v, %v", got, err)
+	}
+	ruleID, err := a.Alerts.CreateRule(ctx, models.AlertRule{Name: "hot", Kind: models.AlertTempAbove, Threshold: 900})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule, err := b.Alerts.GetRule(ctx, ruleID); err != nil || rule.ID != 0 {
+		t.Fatalf("plant-b sees plant-a's rule: %+v, %v", rule, err)
+	}
+	if found, err := b.Alerts.DeleteRule(ctx, ruleID); err != nil || found {
+		t.Fatalf("plant-b deleted plant-a's rule: %v, %v", found, err)
+	}
+	hookID, err := a.Webhooks.CreateWebhook(ctx, models.Webhook{URL: "https://mes.example.com/a", EventTypes: []string{"STOP"}, Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hooks, err := b.Webhooks.ListWebhooks(ctx); err != nil || len(hooks) != 0 {
+		t.Fatalf("plant-b webhooks = %v, %v", hooks, err)
+	}
+	if _, err := a.Incidents.Create(ctx, models.Incident{StartedAt: at, RunID: "run-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if inc, err := b.Incidents.Current(ctx); err != nil || inc.ID != 0 {
+		t.Fatalf("plant-b sees plant-a's incident: %+v, %v", inc, err)
+	}
+	if _, err := a.Maintenance.CreateTask(ctx, models.MaintenanceTask{Name: "Elements", Kind: models.MaintenanceElements, BaselineAt: at}); err != nil {
+		t.Fatal(err)
+	}
+	if tasks, err := b.Maintenance.ListTasks(ctx); err != nil || len(tasks) != 0 {
+		t.Fatalf("plant-b tasks = %v, %v", tasks, err)
+	}
+	if err := a.Uptime.RecordCheck(ctx, models.UptimeCheck{At: at, Up: true}); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := b.Uptime.LastCheck(ctx); err != nil || !c.At.IsZero() {
+		t.Fatalf("plant-b uptime = %+v, %v", c, err)
+	}
+
+	// webhook deliveries only follow the site's own events
+	if err := b.EventRepo.Append(ctx, models.FurnaceEvent{OccurredAt: at, Type: "STOP"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.EventRepo.Append(ctx, models.FurnaceEvent{OccurredAt: at, Type: "STOP"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, through, err := a.Webhooks.NewEvents(ctx, 10); err != nil {
+		t.Fatal(err)
+	} else if err := a.Webhooks.Enqueue(ctx, []models.WebhookDelivery{{WebhookID: hookID, EventID: "x", Status: models.DeliveryPending, NextAttemptAt: &at, CreatedAt: at}}, through); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := b.Webhooks.DueDeliveries(ctx, at, 10); err != nil || len(got) != 0 {
+		t.Fatalf("plant-b deliveries = %v, %v", got, err)
+	}
+
+	// a site's backup holds nothing of the other site
+	path := filepath.Join(t.TempDir(), "plant-b.db")
+	if err := b.Backup.Backup(ctx, path); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	copied, err := db.OpenDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = copied.Close() }()
+	for _, table := range []string{"install_settings", "sim_settings", "runs", "telemetry", "alert_rules", "webhooks", "incidents", "furnace_events"} {
+		var n int
+		if err := copied.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table+` WHERE site_id != 'plant-b'`).Scan(&n); err != nil || n != 0 {
+			t.Fatalf("%s rows of other sites in plant-b's backup = %d, %v", table, n, err)
+		}
+	}
+	if inst, err := NewRepositoryWithConfig(copied, Config{Site: "plant-b"}).Install.Load(ctx); err != nil || inst.SigningKey != "key-b" {
+		t.Fatalf("plant-b installation in its backup = %+v, %v", inst, err)
+	}
+}
+
+func TestMigrate_ExistingRowsJoinTheDefaultSite(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.InitDB(filepath.Join(t.TempDir(), "furnace.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	// back to before migration 7
+	if _, err := db.MigrateDown(ctx, conn, db.LatestVersion()-6); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(`INSERT INTO users (username, username_key, password_hash) VALUES ('ann', 'ann', 'hash')`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(`INSERT INTO furnace_events (id, occurred_at, type, message) VALUES ('e1', '2025-09-01T10:00:00Z', 'NOTE', '')`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Migrate(ctx, conn); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repos := NewRepository(conn)
+	if u, err := repos.Auth.GetByUsername(ctx, "ann"); err != nil || u.Username != "ann" {
+		t.Fatalf("ann after migrating = %+v, %v", u, err)
+	}
+	if got, err := repos.EventRepo.Query(ctx, EventQuery{}); err != nil || len(got) != 1 {
+		t.Fatalf("events after migrating = %v, %v", got, err)
+	}
+}
+
+func TestMigrate_SharedSettingsAreCopiedToEverySite(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.InitDB(filepath.Join(t.TempDir(), "furnace.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	// back to before migration 11
+	if _, err := db.MigrateDown(ctx, conn, db.LatestVersion()-10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(`INSERT INTO users (username, username_key, password_hash, site_id) VALUES ('ann', 'ann', 'hash', 'plant-a')`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(`INSERT INTO install_settings (id, data, updated_at) VALUES (1, '{"signing_key":"shared"}', CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(`INSERT INTO furnace_events (id, occurred_at, type, message, meta, site_id) VALUES ('e1', '2025-09-01T10:00:00Z', 'START', '', '{"run_id":"run-1"}', 'plant-a')`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(`INSERT INTO runs (run_id, target_c, started_at, updated_at) VALUES ('run-1', 800, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Migrate(ctx, conn); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	for _, site := range []string{DefaultSite, "plant-a"} {
+		if inst, err := NewRepositoryWithConfig(conn, Config{Site: site}).Install.Load(ctx); err != nil || inst.SigningKey != "shared" {
+			t.Fatalf("%s installation after migrating = %+v, %v", site, inst, err)
+		}
+	}
+	if run, err := NewRepositoryWithConfig(conn, Config{Site: "plant-a"}).RunRepo.Get(ctx, "run-1"); err != nil || run.RunID != "run-1" {
+		t.Fatalf("plant-a run after migrating = %+v, %v", run, err)
+	}
+	if run, err := NewRepository(conn).RunRepo.Get(ctx, "run-1"); err != nil || run.RunID != "" {
+		t.Fatalf("the default site kept plant-a's run: %+v, %v", run, err)
+	}
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type RunSQLite struct {
+	db   *sql.DB
+	site string // runs of other sites are invisible
+}
+
+func NewRunSQLite(db *sql.DB) *RunSQLite { return &RunSQLite{db: db, site: DefaultSite} }
+
+// Ensure implementation of RunRepo interface at compile time.
+var _ RunRepo = (*RunSQLite)(nil)
+
+const (
+	upsertRunSQL = `
+		INSERT INTO runs (run_id, target_c, started_at, updated_at, soak_s, soak_within_s, soak_mean_c, soak_stddev_c, stability, unstable, energy_kwh, site_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(run_id) DO UPDATE SET
+			target_c=excluded.target_c,
+			updated_at=excluded.updated_at,
+			soak_s=excluded.soak_s,
+			soak_within_s=excluded.soak_within_s,
+			soak_mean_c=excluded.soak_mean_c,
+			soak_stddev_c=excluded.soak_stddev_c,
+			stability=excluded.stability,
+			unstable=excluded.unstable,
+			energy_kwh=excluded.energy_kwh
+		WHERE runs.site_id=excluded.site_id
+	`
+
+	selectRunSQL = `
+		SELECT run_id, target_c, started_at, updated_at, soak_s, soak_within_s, soak_mean_c, soak_stddev_c, stability, unstable, energy_kwh
+		FROM runs WHERE run_id=? AND site_id=?
+	`
+)
+
+// Save inserts the run or updates its soak statistics. StartedAt is kept
+// from the first insert; a run ID another site owns is left alone.
+func (r *RunSQLite) Save(ctx context.Context, run models.Run) error {
+	updated := run.UpdatedAt
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	started := run.StartedAt
+	if started.IsZero() {
+		started = updated
+	}
+	_, err := r.db.ExecContext(ctx, upsertRunSQL,
+		run.RunID,
+		run.TargetTempC,
+		started.UTC(),
+		updated.UTC(),
+		run.SoakSeconds,
+		run.SoakWithinSeconds,
+		run.SoakMeanC,
+		run.SoakStdDevC,
+		run.StabilityScore,
+		run.SoakUnstable,
+		run.EnergyKWh,
+		r.site,
+	)
+	return err
+}
+
+// Get fetches a run by ID; a missing run yields a zero value and nil error.
+func (r *RunSQLite) Get(ctx context.Context, runID string) (models.Run, error) {
+	var run models.Run
+	err := r.db.QueryRowContext(ctx, selectRunSQL, runID, r.site).Scan(
+		&run.RunID,
+		&run.TargetTempC,
+		&run.StartedAt,
+		&run.UpdatedAt,
+		&run.SoakSeconds,
+		&run.SoakWithinSeconds,
+		&run.SoakMeanC,
+		&run.SoakStdDevC,
+		&run.StabilityScore,
+		&run.SoakUnstable,
+		&run.EnergyKWh,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Run{}, nil
+		}
+		return models.Run{}, err
+	}
+	run.StartedAt = run.StartedAt.UTC()
+	run.UpdatedAt = run.UpdatedAt.UTC()
+	return run, nil
+}
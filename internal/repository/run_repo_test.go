@@ -0,0 +1,90 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRunSQLite_SaveUpsertsStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	started := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	run := models.Run{
+		RunID:             "run-1",
+		TargetTempC:       800,
+		StartedAt:         started,
+		UpdatedAt:         started.Add(time.Minute),
+		SoakSeconds:       30,
+		SoakWithinSeconds: 27,
+		SoakMeanC:         799.5,
+		SoakStdDevC:       1.2,
+		StabilityScore:    0.9,
+		EnergyKWh:         42.5,
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO runs")).
+		WithArgs("run-1", 800.0, started, started.Add(time.Minute), 30.0, 27.0, 799.5, 1.2, 0.9, false, 42.5, repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repository.NewRunSQLite(db).Save(context.Background(), run); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRunSQLite_GetMissingReturnsZero(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM runs WHERE run_id=? AND site_id=?")).
+		WithArgs("nope", repository.DefaultSite).
+		WillReturnRows(sqlmock.NewRows([]string{"run_id"}))
+
+	run, err := repository.NewRunSQLite(db).Get(context.Background(), "nope")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if run.RunID != "" {
+		t.Fatalf("expected zero run, got %+v", run)
+	}
+}
+
+func TestRunSQLite_GetScansRow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	ts := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM runs WHERE run_id=? AND site_id=?")).
+		WithArgs("run-1", repository.DefaultSite).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"run_id", "target_c", "started_at", "updated_at", "soak_s", "soak_within_s",
+			"soak_mean_c", "soak_stddev_c", "stability", "unstable", "energy_kwh",
+		}).AddRow("run-1", 800.0, ts, ts, 60.0, 30.0, 798.0, 4.0, 0.5, true, 42.5))
+
+	run, err := repository.NewRunSQLite(db).Get(context.Background(), "run-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if run.StabilityScore != 0.5 || !run.SoakUnstable || run.SoakStdDevC != 4 || run.EnergyKWh != 42.5 {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+}
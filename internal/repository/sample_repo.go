@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"time"
+)
+
+type SampleSQLite struct {
+	db   *sql.DB
+	site string // samples and summaries of other sites are invisible
+}
+
+func NewSampleSQLite(db *sql.DB) *SampleSQLite { return &SampleSQLite{db: db, site: DefaultSite} }
+
+// Ensure implementation of SampleRepo and SampleRollupRepo interfaces at
+// compile time.
+var (
+	_ SampleRepo       = (*SampleSQLite)(nil)
+	_ SampleRollupRepo = (*SampleSQLite)(nil)
+)
+
+// Rollup periods, in seconds.
+const (
+	rollupHour = 3600
+	rollupDay  = 24 * rollupHour
+)
+
+// heatingMode is the mode whose samples count as runtime (service.ModeHeat).
+const heatingMode = "HEAT"
+
+// maxSampleGap caps the time a sample accounts for until the next one; a
+// longer gap means the simulator was not running in between.
+const maxSampleGap = time.Minute
+
+// Timestamps share telemetryTimeLayout, which strftime parses as well.
+// Every statement takes the site before its other arguments.
+const (
+	insertSampleSQL = `INSERT INTO furnace_samples (site_id, ts, temp_c, target_c, mode) VALUES (?, ?, ?, ?, ?)`
+
+	// sampleGapsSQL lists the site's samples in [?, ?) with the seconds until the
+	// next one. Callers read a little past their window so the last sample
+	// in it still gets its gap.
+	sampleGapsSQL = `
+		SELECT ts, temp_c, target_c, mode,
+			(julianday(LEAD(ts) OVER (ORDER BY ts)) - julianday(ts)) * 86400 AS gap_s
+		FROM furnace_samples WHERE site_id = ? AND ts >= ? AND ts < ?
+	`
+	sampleRuntimeSQL = `CASE WHEN mode = '` + heatingMode + `' THEN MIN(COALESCE(gap_s, 0), ?) ELSE 0 END`
+
+	// bucketSamplesSQL merges daily and hourly rollups with the samples
+	// after them; each source is bounded by the arguments so no interval
+	// is counted twice.
+	bucketSamplesSQL = `
+		SELECT bucket, SUM(n), MIN(min_c), MAX(max_c), SUM(sum_c) / SUM(n), MAX(target_c), SUM(runtime_s)
+		FROM (
+			SELECT start / ? * ? AS bucket, samples AS n, min_c, max_c, sum_c, target_c, runtime_s
+			FROM sample_rollups WHERE site_id = ? AND period_s = 86400 AND start >= ? AND start < ?
+			UNION ALL
+			SELECT start / ? * ?, samples, min_c, max_c, sum_c, target_c, runtime_s
+			FROM sample_rollups WHERE site_id = ? AND period_s = 3600 AND start >= ? AND start < ?
+			UNION ALL
+			SELECT CAST(strftime('%s', ts) AS INTEGER) / ? * ?, 1, temp_c, temp_c, temp_c, target_c, ` + sampleRuntimeSQL + `
+			FROM (` + sampleGapsSQL + `) WHERE ts <= ?
+		)
+		GROUP BY bucket
+		ORDER BY bucket ASC
+	`
+
+	rollupEndsSQL = `SELECT period_s, MAX(start) + period_s FROM sample_rollups WHERE site_id = ? GROUP BY period_s`
+
+	rollupHoursSQL = `
+		INSERT OR REPLACE INTO sample_rollups (site_id, period_s, start, samples, min_c, max_c, sum_c, target_c, runtime_s)
+		SELECT ?, 3600, CAST(strftime('%s', ts) AS INTEGER) / 3600 * 3600 AS hour,
+			COUNT(*), MIN(temp_c), MAX(temp_c), SUM(temp_c), MAX(target_c), SUM(` + sampleRuntimeSQL + `)
+		FROM (` + sampleGapsSQL + `) WHERE ts < ?
+		GROUP BY hour
+	`
+	rollupDaysSQL = `
+		INSERT OR REPLACE INTO sample_rollups (site_id, period_s, start, samples, min_c, max_c, sum_c, target_c, runtime_s)
+		SELECT ?, 86400, start / 86400 * 86400 AS day,
+			SUM(samples), MIN(min_c), MAX(max_c), SUM(sum_c), MAX(target_c), SUM(runtime_s)
+		FROM sample_rollups WHERE site_id = ? AND period_s = 3600 AND start >= ? AND start < ?
+		GROUP BY day
+	`
+	pruneSamplesSQL = `DELETE FROM furnace_samples WHERE site_id = ? AND ts < ?`
+)
+
+func (r *SampleSQLite) Append(ctx context.Context, s models.FurnaceSample) error {
+	at := s.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	_, err := r.db.ExecContext(ctx, insertSampleSQL, r.site, at.UTC().Format(telemetryTimeLayout), s.TempC, s.TargetC, s.Mode)
+	return err
+}
+
+// Buckets aggregates the samples between q.From and q.To into intervals of
+// q.Resolution aligned to the Unix epoch, oldest first. When the
+// resolution is a whole number of days or hours, the periods already
+// rolled up are read from their summaries, which count whole: a period
+// that starts before q.From or ends after q.To is included entirely.
+func (r *SampleSQLite) Buckets(ctx context.Context, q HistoryQuery) ([]models.HistoryBucket, error) {
+	res := int64(q.Resolution / time.Second)
+	if res < 1 {
+		res = 1
+	}
+	from, to := q.From.UTC(), q.To.UTC()
+
+	// [dayFrom, dayTo) from daily rollups, then [hourFrom, hourTo) from
+	// hourly ones, then samples from rawFrom on
+	dayFrom, dayTo := from.Unix(), from.Unix()
+	hourFrom, hourTo := from.Unix(), from.Unix()
+	rawFrom := from
+	if res%rollupHour == 0 {
+		ends, err := queryRollupEnds(ctx, r.db, r.site)
+		if err != nil {
+			return nil, err
+		}
+		if res%rollupDay == 0 {
+			dayFrom = floorTo(from.Unix(), rollupDay)
+			dayTo = max(dayFrom, min(ends[rollupDay], to.Unix()+1))
+		}
+		hourFrom = max(dayTo, floorTo(from.Unix(), rollupHour))
+		hourTo = max(hourFrom, min(ends[rollupHour], to.Unix()+1))
+		if end := time.Unix(hourTo, 0).UTC(); end.After(rawFrom) {
+			rawFrom = end
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, bucketSamplesSQL,
+		res, res, r.site, dayFrom, dayTo,
+		res, res, r.site, hourFrom, hourTo,
+		res, res, maxSampleGap.Seconds(),
+		r.site, rawFrom.Format(telemetryTimeLayout), to.Add(maxSampleGap).Format(telemetryTimeLayout),
+		to.Format(telemetryTimeLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.HistoryBucket, 0, 64)
+	for rows.Next() {
+		var (
+			b     models.HistoryBucket
+			start int64
+		)
+		if err := rows.Scan(&start, &b.Samples, &b.MinC, &b.MaxC, &b.AvgC, &b.TargetC, &b.RuntimeS); err != nil {
+			return nil, err
+		}
+		b.Start = time.Unix(start, 0).UTC()
+		out = append(out, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Rollup summarises the samples of every hour that ended by before, and
+// the hours of every day that did, picking up after the last summary of
+// each. Returns the number of summaries written.
+func (r *SampleSQLite) Rollup(ctx context.Context, before time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	ends, err := queryRollupEnds(ctx, tx, r.site)
+	if err != nil {
+		return 0, err
+	}
+	hourTo := floorTo(before.UTC().Unix(), rollupHour)
+	n := int64(0)
+	if from := ends[rollupHour]; from < hourTo {
+		// read past the hour so its last sample gets its gap
+		res, err := tx.ExecContext(ctx, rollupHoursSQL, r.site, maxSampleGap.Seconds(),
+			r.site, time.Unix(from, 0).UTC().Format(telemetryTimeLayout),
+			time.Unix(hourTo, 0).Add(maxSampleGap).UTC().Format(telemetryTimeLayout),
+			time.Unix(hourTo, 0).UTC().Format(telemetryTimeLayout))
+		if err != nil {
+			return 0, err
+		}
+		k, _ := res.RowsAffected()
+		n += k
+	}
+	if from, dayTo := ends[rollupDay], floorTo(hourTo, rollupDay); from < dayTo {
+		res, err := tx.ExecContext(ctx, rollupDaysSQL, r.site, r.site, from, dayTo)
+		if err != nil {
+			return 0, err
+		}
+		k, _ := res.RowsAffected()
+		n += k
+	}
+	return int(n), tx.Commit()
+}
+
+// PruneSamples deletes the samples older than before that are covered by
+// an hourly summary, and returns how many it deleted.
+func (r *SampleSQLite) PruneSamples(ctx context.Context, before time.Time) (int64, error) {
+	ends, err := queryRollupEnds(ctx, r.db, r.site)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Unix(min(ends[rollupHour], before.Unix()), 0).UTC()
+	res, err := r.db.ExecContext(ctx, pruneSamplesSQL, r.site, cutoff.Format(telemetryTimeLayout))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// queryRollupEnds returns the Unix time the site's summaries of each
+// period end at; 0 for a period without any.
+func queryRollupEnds(ctx context.Context, db dbtx, site string) (map[int64]int64, error) {
+	rows, err := db.QueryContext(ctx, rollupEndsSQL, site)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ends := make(map[int64]int64, 2)
+	for rows.Next() {
+		var period, end int64
+		if err := rows.Scan(&period, &end); err != nil {
+			return nil, err
+		}
+		ends[period] = end
+	}
+	return ends, rows.Err()
+}
+
+// floorTo rounds the Unix time t down to a multiple of period.
+func floorTo(t, period int64) int64 {
+	return t / period * period
+}
@@ -0,0 +1,135 @@
+package repository_test
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+	"controlling_furnace/internal/repository/db"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSampleSQLite_Append(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2025, 9, 20, 10, 0, 1, 500e6, time.UTC)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO furnace_samples (site_id, ts, temp_c, target_c, mode) VALUES (?, ?, ?, ?, ?)")).
+		WithArgs(repository.DefaultSite, "2025-09-20 10:00:01.500", 612.5, 800.0, "HEAT").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repository.NewSampleSQLite(db).Append(context.Background(),
+		models.FurnaceSample{At: at, TempC: 612.5, TargetC: 800, Mode: "HEAT"})
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSampleSQLite_BucketsGroupsByResolution(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	from := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"bucket", "count", "min", "max", "avg", "target", "runtime"}).
+		AddRow(from.Unix(), 60, 500.0, 560.0, 530.0, 800.0, 60.0).
+		AddRow(from.Unix()+60, 58, 560.5, 618.0, 589.2, 800.0, 58.0)
+	// below an hour the rollups are not consulted: their ranges are empty
+	mock.ExpectQuery(regexp.QuoteMeta("FROM furnace_samples")).
+		WithArgs(int64(60), int64(60), repository.DefaultSite, from.Unix(), from.Unix(),
+			int64(60), int64(60), repository.DefaultSite, from.Unix(), from.Unix(),
+			int64(60), int64(60), 60.0,
+			repository.DefaultSite, "2025-09-20 10:00:00.000", "2025-09-20 10:06:00.000", "2025-09-20 10:05:00.000").
+		WillReturnRows(rows)
+
+	got, err := repository.NewSampleSQLite(db).Buckets(context.Background(), repository.HistoryQuery{
+		From:       from,
+		To:         from.Add(5 * time.Minute),
+		Resolution: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("Buckets() error = %v", err)
+	}
+	if len(got) != 2 || !got[1].Start.Equal(from.Add(time.Minute)) || got[1].Samples != 58 || got[1].AvgC != 589.2 || got[1].RuntimeS != 58 {
+		t.Fatalf("unexpected buckets: %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSampleSQLite_RollupsServeCoarseHistory(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.InitDB(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	repo := repository.NewSampleSQLite(conn)
+
+	// two days of a sample every 30s, heating on the first day only
+	start := time.Date(2025, 9, 20, 0, 0, 0, 0, time.UTC)
+	for at := start; at.Before(start.Add(48 * time.Hour)); at = at.Add(30 * time.Second) {
+		mode := "HEAT"
+		if at.Sub(start) >= 24*time.Hour {
+			mode = "STANDBY"
+		}
+		temp := 500 + float64(at.Sub(start)/time.Hour)
+		if err := repo.Append(ctx, models.FurnaceSample{At: at, TempC: temp, TargetC: 800, Mode: mode}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q := repository.HistoryQuery{From: start, To: start.Add(48*time.Hour - time.Second), Resolution: 24 * time.Hour}
+	want, err := repo.Buckets(ctx, q)
+	if err != nil || len(want) != 2 || want[0].RuntimeS != 24*3600 || want[1].RuntimeS != 0 {
+		t.Fatalf("buckets from samples: %+v, %v", want, err)
+	}
+
+	// the second day has not ended yet
+	n, err := repo.Rollup(ctx, start.Add(36*time.Hour+10*time.Minute))
+	if err != nil || n != 36+1 {
+		t.Fatalf("Rollup = %d, %v; want 36 hours and 1 day", n, err)
+	}
+	if n, err := repo.Rollup(ctx, start.Add(36*time.Hour+20*time.Minute)); err != nil || n != 0 {
+		t.Fatalf("second Rollup = %d, %v; want nothing new", n, err)
+	}
+	// only what a summary covers is pruned
+	pruned, err := repo.PruneSamples(ctx, start.Add(40*time.Hour))
+	if err != nil || pruned != 36*120 {
+		t.Fatalf("PruneSamples = %d, %v; want the %d samples of 36 hours", pruned, err, 36*120)
+	}
+
+	for _, res := range []time.Duration{24 * time.Hour, time.Hour} {
+		q.Resolution = res
+		got, err := repo.Buckets(ctx, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var samples int
+		var runtime float64
+		for _, b := range got {
+			samples += b.Samples
+			runtime += b.RuntimeS
+		}
+		if samples != 48*120 || runtime != 24*3600 {
+			t.Fatalf("%v: %d samples, %vs runtime after pruning; want %d and %d", res, samples, runtime, 48*120, 24*3600)
+		}
+		if res == 24*time.Hour && (len(got) != 2 || got[0].MinC != want[0].MinC || got[0].MaxC != want[0].MaxC ||
+			math.Abs(got[0].AvgC-want[0].AvgC) > 1e-9 || math.Abs(got[1].AvgC-want[1].AvgC) > 1e-9) {
+			t.Fatalf("daily buckets from summaries %+v, want %+v", got, want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type SequenceSQLite struct {
+	db   *sql.DB
+	site string // sequences of other sites are invisible
+}
+
+func NewSequenceSQLite(db *sql.DB) *SequenceSQLite {
+	return &SequenceSQLite{db: db, site: DefaultSite}
+}
+
+// Ensure implementation of SequenceRepo interface at compile time.
+var _ SequenceRepo = (*SequenceSQLite)(nil)
+
+const (
+	sequenceColumns = `id, steps, status, step, reason, created_by, created_at, finished_at,
+		step_since, step_run_id, held_s, saved_at`
+
+	upsertSequenceSQL = `
+		INSERT INTO sequences (` + sequenceColumns + `, site_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			status=excluded.status,
+			step=excluded.step,
+			reason=excluded.reason,
+			finished_at=excluded.finished_at,
+			step_since=excluded.step_since,
+			step_run_id=excluded.step_run_id,
+			held_s=excluded.held_s,
+			saved_at=excluded.saved_at
+		WHERE sequences.site_id=excluded.site_id
+	`
+	selectSequenceSQL  = `SELECT ` + sequenceColumns + ` FROM sequences WHERE id=? AND site_id=?`
+	runningSequenceSQL = `SELECT ` + sequenceColumns + ` FROM sequences
+		WHERE site_id=? AND status='` + models.SequenceRunning + `' ORDER BY created_at DESC LIMIT 1`
+)
+
+// Save inserts the sequence or updates its status and progress; the steps
+// and creation are kept from the first save.
+func (r *SequenceSQLite) Save(ctx context.Context, seq SequenceRecord) error {
+	steps, err := marshalList(seq.Steps)
+	if err != nil {
+		return err
+	}
+	var finished sql.NullTime
+	if seq.FinishedAt != nil {
+		finished = sql.NullTime{Time: seq.FinishedAt.UTC(), Valid: true}
+	}
+	_, err = r.db.ExecContext(ctx, upsertSequenceSQL,
+		seq.ID, steps, seq.Status, seq.Step, seq.Reason, seq.CreatedBy, seq.CreatedAt.UTC(), finished,
+		seq.StepSince.UTC(), seq.StepRunID, seq.HeldS, seq.SavedAt.UTC(), r.site)
+	if err != nil {
+		return fmt.Errorf("save sequence %s: %w", seq.ID, err)
+	}
+	return nil
+}
+
+// Get returns the sequence, or a zero SequenceRecord if it does not exist.
+func (r *SequenceSQLite) Get(ctx context.Context, id string) (SequenceRecord, error) {
+	return r.one(ctx, selectSequenceSQL, id, r.site)
+}
+
+// Running returns the sequence left running, or a zero SequenceRecord.
+func (r *SequenceSQLite) Running(ctx context.Context) (SequenceRecord, error) {
+	return r.one(ctx, runningSequenceSQL, r.site)
+}
+
+func (r *SequenceSQLite) one(ctx context.Context, query string, args ...any) (SequenceRecord, error) {
+	var (
+		seq      SequenceRecord
+		steps    string
+		finished sql.NullTime
+	)
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&seq.ID, &steps, &seq.Status, &seq.Step, &seq.Reason,
+		&seq.CreatedBy, &seq.CreatedAt, &finished, &seq.StepSince, &seq.StepRunID, &seq.HeldS, &seq.SavedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return SequenceRecord{}, nil
+	}
+	if err != nil {
+		return SequenceRecord{}, err
+	}
+	if err := json.Unmarshal([]byte(steps), &seq.Steps); err != nil {
+		return SequenceRecord{}, fmt.Errorf("decode steps of sequence %s: %w", seq.ID, err)
+	}
+	seq.CreatedAt, seq.StepSince, seq.SavedAt = seq.CreatedAt.UTC(), seq.StepSince.UTC(), seq.SavedAt.UTC()
+	if finished.Valid {
+		t := finished.Time.UTC()
+		seq.FinishedAt = &t
+	}
+	return seq, nil
+}
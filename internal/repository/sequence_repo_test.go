@@ -0,0 +1,72 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+	"controlling_furnace/internal/repository/db"
+)
+
+func TestSequenceSQLite_SavesProgressAndFindsTheRunningOne(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.InitDB(filepath.Join(t.TempDir(), "furnace.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	repo := repository.NewRepositoryWithConfig(conn, repository.Config{Site: "plant-a"}).Sequences
+	other := repository.NewRepositoryWithConfig(conn, repository.Config{Site: "plant-b"}).Sequences
+
+	if rec, err := repo.Running(ctx); err != nil || rec.ID != "" {
+		t.Fatalf("Running() on an empty table = %+v, %v", rec, err)
+	}
+	at := time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)
+	rec := repository.SequenceRecord{
+		Sequence: models.Sequence{
+			ID:        "seq-1",
+			Steps:     []models.SequenceStep{{Mode: "HEAT", TargetTempC: 300, DurationSec: 60}, {Mode: "STANDBY", DurationSec: 30}},
+			Status:    models.SequenceRunning,
+			Step:      1,
+			CreatedBy: 7,
+			CreatedAt: at,
+		},
+		StepSince: at,
+		StepRunID: "run-1",
+		SavedAt:   at,
+	}
+	if err := repo.Save(ctx, rec); err != nil {
+		t.Fatal(err)
+	}
+	rec.Step, rec.StepSince, rec.StepRunID, rec.HeldS, rec.SavedAt = 2, at.Add(time.Minute), "", 12.5, at.Add(2*time.Minute)
+	rec.Steps = nil // kept from the first save
+	if err := repo.Save(ctx, rec); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.Running(ctx)
+	if err != nil || got.ID != "seq-1" || got.Step != 2 || got.HeldS != 12.5 || len(got.Steps) != 2 || got.CreatedBy != 7 ||
+		!got.StepSince.Equal(at.Add(time.Minute)) || !got.SavedAt.Equal(at.Add(2*time.Minute)) {
+		t.Fatalf("Running() = %+v, %v", got, err)
+	}
+	if got, err := other.Running(ctx); err != nil || got.ID != "" {
+		t.Fatalf("plant-b sees plant-a's sequence: %+v, %v", got, err)
+	}
+
+	end := at.Add(3 * time.Minute)
+	rec.Status, rec.FinishedAt = models.SequenceCompleted, &end
+	if err := repo.Save(ctx, rec); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := repo.Running(ctx); err != nil || got.ID != "" {
+		t.Fatalf("Running() after it finished = %+v, %v", got, err)
+	}
+	if got, err := repo.Get(ctx, "seq-1"); err != nil || got.Status != models.SequenceCompleted || got.FinishedAt == nil || !got.FinishedAt.Equal(end) {
+		t.Fatalf("Get() = %+v, %v", got, err)
+	}
+	if got, err := other.Get(ctx, "seq-1"); err != nil || got.ID != "" {
+		t.Fatalf("plant-b Get() = %+v, %v", got, err)
+	}
+}
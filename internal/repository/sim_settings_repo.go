@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type SimSettingsSQLite struct {
+	db   *sql.DB
+	site string // whose row to read and write
+}
+
+func NewSimSettingsSQLite(db *sql.DB) *SimSettingsSQLite {
+	return &SimSettingsSQLite{db: db, site: DefaultSite}
+}
+
+// Ensure implementation of SimSettingsRepo interface at compile time.
+var _ SimSettingsRepo = (*SimSettingsSQLite)(nil)
+
+const (
+	upsertSimSettingsSQL = `
+		INSERT INTO sim_settings (site_id, data, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(site_id) DO UPDATE SET data=excluded.data, updated_at=excluded.updated_at
+	`
+	selectSimSettingsSQL = `SELECT data FROM sim_settings WHERE site_id=?`
+)
+
+// Save stores s as the site's settings row.
+func (r *SimSettingsSQLite) Save(ctx context.Context, s models.SimSettings) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, upsertSimSettingsSQL, r.site, string(data), time.Now().UTC())
+	return err
+}
+
+// Load returns the saved settings, or a zero value if none were saved.
+func (r *SimSettingsSQLite) Load(ctx context.Context) (models.SimSettings, error) {
+	var (
+		s    models.SimSettings
+		data string
+	)
+	err := r.db.QueryRowContext(ctx, selectSimSettingsSQL, r.site).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal([]byte(data), &s)
+	return s, err
+}
@@ -0,0 +1,58 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSimSettingsSQLite_SaveAndLoad(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	set := models.SimSettings{TickMs: 500, AmbientC: 20, MaxSafeC: 1100, RampUpCPerSec: 6}
+	data := `{"tick_ms":500,"ambient_c":20,"max_safe_c":1100,"ramp_up_c_per_sec":6,"ramp_down_c_per_sec":0,"standby_cool_c_per_sec":0,"noise_stddev_c":0,"drift_c_per_hour":0,"max_drift_c":0,"ambient_noise_stddev_c":0}`
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO sim_settings")).
+		WithArgs(repository.DefaultSite, data, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT data FROM sim_settings WHERE site_id=?")).
+		WithArgs(repository.DefaultSite).
+		WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow(data))
+
+	repo := repository.NewSimSettingsSQLite(db)
+	if err := repo.Save(context.Background(), set); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := repo.Load(context.Background())
+	if err != nil || got != set {
+		t.Fatalf("Load() = %+v, %v", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSimSettingsSQLite_LoadMissingReturnsZero(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT data FROM sim_settings")).
+		WillReturnRows(sqlmock.NewRows([]string{"data"}))
+
+	got, err := repository.NewSimSettingsSQLite(db).Load(context.Background())
+	if err != nil || got != (models.SimSettings{}) {
+		t.Fatalf("Load() = %+v, %v", got, err)
+	}
+}
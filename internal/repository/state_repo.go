@@ -10,33 +10,45 @@ import (
 )
 
 type StateSQLite struct {
-	db *sql.DB
+	db   dbtx   // *sql.Tx inside a unit of work
+	site string // whose row to read and write
 }
 
 func NewStateSQLite(db *sql.DB) *StateSQLite {
-	return &StateSQLite{db: db}
+	return &StateSQLite{db: db, site: DefaultSite}
 }
 
 // constants and helpers for clarity and reuse
 const (
-	furnaceStateRowID = 1
-
 	insertOrUpdateStateSQL = `
-		INSERT INTO furnace_state (id, mode, temp_c, target_c, remaining_s, errors, running, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(id) DO UPDATE SET
+		INSERT INTO furnace_state (site_id, mode, temp_c, target_c, remaining_s, errors, running, updated_at, run_id, measured_c, power_kw, energy_kwh, ambient_c,
+			gas, gas_setpoint_m3h, gas_flow_m3h, o2_ppm, max_o2_ppm, keep_warm_c)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(site_id) DO UPDATE SET
 			mode=excluded.mode,
 			temp_c=excluded.temp_c,
 			target_c=excluded.target_c,
 			remaining_s=excluded.remaining_s,
 			errors=excluded.errors,
 			running=excluded.running,
-			updated_at=excluded.updated_at
+			updated_at=excluded.updated_at,
+			run_id=excluded.run_id,
+			measured_c=excluded.measured_c,
+			power_kw=excluded.power_kw,
+			energy_kwh=excluded.energy_kwh,
+			ambient_c=excluded.ambient_c,
+			gas=excluded.gas,
+			gas_setpoint_m3h=excluded.gas_setpoint_m3h,
+			gas_flow_m3h=excluded.gas_flow_m3h,
+			o2_ppm=excluded.o2_ppm,
+			max_o2_ppm=excluded.max_o2_ppm,
+			keep_warm_c=excluded.keep_warm_c
 	`
 
 	selectStateSQL = `
-		SELECT id, mode, temp_c, target_c, remaining_s, errors, running, updated_at
-		FROM furnace_state WHERE id=?
+		SELECT id, mode, temp_c, target_c, remaining_s, errors, running, updated_at, run_id, measured_c, power_kw, energy_kwh, ambient_c,
+			gas, gas_setpoint_m3h, gas_flow_m3h, o2_ppm, max_o2_ppm, keep_warm_c
+		FROM furnace_state WHERE site_id=?
 	`
 )
 
@@ -61,7 +73,12 @@ func unmarshalErrorCodes(s string) ([]string, error) {
 	return codes, nil
 }
 
-// Save updates or inserts the furnace_state row (id always 1).
+// nullableString maps an empty string to SQL NULL.
+func nullableString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// Save updates or inserts the site's furnace_state row.
 func (r *StateSQLite) Save(ctx context.Context, state models.FurnaceState) error {
 	errorsJSONStr, err := marshalErrorCodes(state.ErrorCodes)
 	if err != nil {
@@ -77,7 +94,7 @@ func (r *StateSQLite) Save(ctx context.Context, state models.FurnaceState) error
 	}
 
 	_, err = r.db.ExecContext(ctx, insertOrUpdateStateSQL,
-		furnaceStateRowID,
+		r.site,
 		state.Mode,
 		state.CurrentTempC,
 		state.TargetTempC,
@@ -85,16 +102,30 @@ func (r *StateSQLite) Save(ctx context.Context, state models.FurnaceState) error
 		errorsJSONStr,
 		state.IsRunning,
 		tsUTC,
+		nullableString(state.RunID),
+		state.MeasuredTempC,
+		state.PowerKW,
+		state.EnergyKWh,
+		state.AmbientTempC,
+		nullableString(state.Gas),
+		state.GasSetpointM3h,
+		state.GasFlowM3h,
+		state.O2PPM,
+		state.MaxO2PPM,
+		state.KeepWarmC,
 	)
 	return err
 }
 
-// Load fetches the single furnace_state row (id=1).
+// Load fetches the site's furnace_state row.
 func (r *StateSQLite) Load(ctx context.Context) (models.FurnaceState, error) {
-	row := r.db.QueryRowContext(ctx, selectStateSQL, furnaceStateRowID)
+	row := r.db.QueryRowContext(ctx, selectStateSQL, r.site)
 
 	var s models.FurnaceState
 	var errorsJSONStr string
+	var runID, gas sql.NullString
+	var measured, power, energy, ambient sql.NullFloat64
+	var gasSetpoint, gasFlow, o2, maxO2, keepWarm sql.NullFloat64
 	if err := row.Scan(
 		&s.ID,
 		&s.Mode,
@@ -104,6 +135,17 @@ func (r *StateSQLite) Load(ctx context.Context) (models.FurnaceState, error) {
 		&errorsJSONStr,
 		&s.IsRunning,
 		&s.UpdatedAt,
+		&runID,
+		&measured,
+		&power,
+		&energy,
+		&ambient,
+		&gas,
+		&gasSetpoint,
+		&gasFlow,
+		&o2,
+		&maxO2,
+		&keepWarm,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.FurnaceState{}, nil // no state yet
@@ -117,6 +159,21 @@ func (r *StateSQLite) Load(ctx context.Context) (models.FurnaceState, error) {
 	}
 	s.ErrorCodes = codes
 	s.UpdatedAt = s.UpdatedAt.UTC()
+	s.RunID = runID.String
+	s.MeasuredTempC = measured.Float64
+	if !measured.Valid {
+		// rows written before the sensor model existed
+		s.MeasuredTempC = s.CurrentTempC
+	}
+	s.PowerKW = power.Float64
+	s.EnergyKWh = energy.Float64
+	s.AmbientTempC = ambient.Float64
+	s.Gas = gas.String
+	s.GasSetpointM3h = gasSetpoint.Float64
+	s.GasFlowM3h = gasFlow.Float64
+	s.O2PPM = o2.Float64 // 0 for rows written before the atmosphere model; see advanceAtmosphere
+	s.MaxO2PPM = maxO2.Float64
+	s.KeepWarmC = keepWarm.Float64
 
 	return s, nil
 }
@@ -62,7 +62,7 @@ func TestStateSQLite_Save_SetsUTCAndMarshalsErrors_WhenTimeZero(t *testing.T) {
 	// We don't have direct access to the private SQL constant, so match by fragment.
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO furnace_state")).
 		WithArgs(
-			1, // id constant
+			repository.DefaultSite,
 			state.Mode,
 			state.CurrentTempC,
 			state.TargetTempC,
@@ -70,6 +70,17 @@ func TestStateSQLite_Save_SetsUTCAndMarshalsErrors_WhenTimeZero(t *testing.T) {
 			`["E1","E2"]`, // JSON marshaled errors
 			state.IsRunning,
 			isUTCRecent, // UpdatedAt written as UTC "now"
+			nil,         // no active run -> NULL
+			state.MeasuredTempC,
+			state.PowerKW,
+			state.EnergyKWh,
+			state.AmbientTempC,
+			nil, // no gas -> NULL
+			state.GasSetpointM3h,
+			state.GasFlowM3h,
+			state.O2PPM,
+			state.MaxO2PPM,
+			state.KeepWarmC,
 		).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
@@ -120,7 +131,7 @@ func TestStateSQLite_Save_PreservesGivenTimeButConvertsToUTC(t *testing.T) {
 
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO furnace_state")).
 		WithArgs(
-			1,
+			repository.DefaultSite,
 			state.Mode,
 			state.CurrentTempC,
 			state.TargetTempC,
@@ -128,6 +139,17 @@ func TestStateSQLite_Save_PreservesGivenTimeButConvertsToUTC(t *testing.T) {
 			"[]", // empty slice -> "[]"
 			state.IsRunning,
 			isExactUTC, // exact UTC-converted input time
+			nil,
+			state.MeasuredTempC,
+			state.PowerKW,
+			state.EnergyKWh,
+			state.AmbientTempC,
+			nil, // no gas -> NULL
+			state.GasSetpointM3h,
+			state.GasFlowM3h,
+			state.O2PPM,
+			state.MaxO2PPM,
+			state.KeepWarmC,
 		).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
@@ -166,7 +188,7 @@ func TestStateSQLite_Save_ExecErrorIsPropagated(t *testing.T) {
 
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO furnace_state")).
 		WithArgs(
-			1,
+			repository.DefaultSite,
 			state.Mode,
 			state.CurrentTempC,
 			state.TargetTempC,
@@ -174,6 +196,17 @@ func TestStateSQLite_Save_ExecErrorIsPropagated(t *testing.T) {
 			"null",
 			state.IsRunning,
 			sqlmock.AnyArg(), // time
+			nil,
+			state.MeasuredTempC,
+			state.PowerKW,
+			state.EnergyKWh,
+			state.AmbientTempC,
+			nil, // no gas -> NULL
+			state.GasSetpointM3h,
+			state.GasFlowM3h,
+			state.O2PPM,
+			state.MaxO2PPM,
+			state.KeepWarmC,
 		).
 		WillReturnError(errors.New("db down"))
 
@@ -197,7 +230,7 @@ func TestStateSQLite_Load_NoRowsReturnsZeroValueAndNilError(t *testing.T) {
 	repo := repository.NewStateSQLite(db)
 
 	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, mode, temp_c, target_c, remaining_s, errors, running, updated_at")).
-		WithArgs(1).
+		WithArgs(repository.DefaultSite).
 		WillReturnError(sql.ErrNoRows)
 
 	got, err := repo.Load(context.Background())
@@ -226,7 +259,7 @@ func TestStateSQLite_Load_HappyPath_UnmarshalsAndUTC(t *testing.T) {
 	repo := repository.NewStateSQLite(db)
 
 	// Prepare row data
-	cols := []string{"id", "mode", "temp_c", "target_c", "remaining_s", "errors", "running", "updated_at"}
+	cols := []string{"id", "mode", "temp_c", "target_c", "remaining_s", "errors", "running", "updated_at", "run_id", "measured_c", "power_kw", "energy_kwh", "ambient_c", "gas", "gas_setpoint_m3h", "gas_flow_m3h", "o2_ppm", "max_o2_ppm", "keep_warm_c"}
 	locNY, _ := time.LoadLocation("America/New_York")
 	nonUTC := time.Date(2024, 2, 1, 8, 30, 0, 0, locNY)
 
@@ -240,10 +273,21 @@ func TestStateSQLite_Load_HappyPath_UnmarshalsAndUTC(t *testing.T) {
 			`["OVERHEAT","SENSOR_FAIL"]`,
 			true,
 			nonUTC, // DB gives a non-UTC time; Load should convert to UTC
+			"run-42",
+			121.5,
+			85.0,
+			12.5,
+			27.5,
+			"N2",
+			20.0,
+			19.5,
+			12.0,
+			50.0,
+			150.0,
 		)
 
 	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, mode, temp_c, target_c, remaining_s, errors, running, updated_at")).
-		WithArgs(1).
+		WithArgs(repository.DefaultSite).
 		WillReturnRows(rows)
 
 	got, err := repo.Load(context.Background())
@@ -256,7 +300,17 @@ func TestStateSQLite_Load_HappyPath_UnmarshalsAndUTC(t *testing.T) {
 		got.CurrentTempC != 123.0 ||
 		got.TargetTempC != 150.0 ||
 		got.RemainingSeconds != 900 ||
-		!got.IsRunning {
+		!got.IsRunning ||
+		got.RunID != "run-42" ||
+		got.MeasuredTempC != 121.5 ||
+		got.PowerKW != 85.0 ||
+		got.EnergyKWh != 12.5 ||
+		got.AmbientTempC != 27.5 ||
+		got.Gas != "N2" ||
+		got.GasFlowM3h != 19.5 ||
+		got.O2PPM != 12.0 ||
+		got.MaxO2PPM != 50.0 ||
+		got.KeepWarmC != 150.0 {
 		t.Fatalf("Load() unexpected fields: %+v", got)
 	}
 
@@ -286,7 +340,7 @@ func TestStateSQLite_Load_InvalidErrorsJSON_ReturnsError(t *testing.T) {
 
 	repo := repository.NewStateSQLite(db)
 
-	cols := []string{"id", "mode", "temp_c", "target_c", "remaining_s", "errors", "running", "updated_at"}
+	cols := []string{"id", "mode", "temp_c", "target_c", "remaining_s", "errors", "running", "updated_at", "run_id", "measured_c", "power_kw", "energy_kwh", "ambient_c", "gas", "gas_setpoint_m3h", "gas_flow_m3h", "o2_ppm", "max_o2_ppm", "keep_warm_c"}
 	rows := sqlmock.NewRows(cols).
 		AddRow(
 			1,
@@ -297,10 +351,21 @@ func TestStateSQLite_Load_InvalidErrorsJSON_ReturnsError(t *testing.T) {
 			`{not: "an array"}`, // invalid for []string
 			false,
 			time.Now(),
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 		)
 
 	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, mode, temp_c, target_c, remaining_s, errors, running, updated_at")).
-		WithArgs(1).
+		WithArgs(repository.DefaultSite).
 		WillReturnRows(rows)
 
 	_, err = repo.Load(context.Background())
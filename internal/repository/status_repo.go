@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/repository/db"
+	"database/sql"
+)
+
+type StatusSQLite struct {
+	db *sql.DB
+}
+
+func NewStatusSQLite(db *sql.DB) *StatusSQLite { return &StatusSQLite{db: db} }
+
+// Ensure implementation of StatusRepo interface at compile time.
+var _ StatusRepo = (*StatusSQLite)(nil)
+
+// Ping takes and releases the write lock on a dedicated connection, so it
+// fails while another process holds the database locked, not only when the
+// file cannot be opened.
+func (r *StatusSQLite) Ping(ctx context.Context) error {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, "ROLLBACK")
+	return err
+}
+
+func (r *StatusSQLite) CheckSchema(ctx context.Context) error {
+	return db.CheckSchema(ctx, r.db)
+}
+
+func (r *StatusSQLite) Stats() sql.DBStats { return r.db.Stats() }
@@ -0,0 +1,35 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"controlling_furnace/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestStatusSQLite_PingTakesWriteLock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("BEGIN IMMEDIATE").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK").WillReturnResult(sqlmock.NewResult(0, 0))
+	locked := errors.New("database is locked")
+	mock.ExpectExec("BEGIN IMMEDIATE").WillReturnError(locked)
+
+	repo := repository.NewStatusSQLite(db)
+	if err := repo.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if err := repo.Ping(context.Background()); !errors.Is(err, locked) {
+		t.Fatalf("expected the lock error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
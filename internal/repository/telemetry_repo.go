@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type TelemetrySQLite struct {
+	db   *sql.DB
+	site string // samples of other sites are invisible
+}
+
+func NewTelemetrySQLite(db *sql.DB) *TelemetrySQLite {
+	return &TelemetrySQLite{db: db, site: DefaultSite}
+}
+
+// Ensure implementation of TelemetryRepo interface at compile time.
+var _ TelemetryRepo = (*TelemetrySQLite)(nil)
+
+// telemetryTimeLayout is fixed-width so stored timestamps compare correctly as text.
+const telemetryTimeLayout = "2006-01-02 15:04:05.000"
+
+// Append inserts samples in a single statement.
+func (r *TelemetrySQLite) Append(ctx context.Context, samples ...models.TelemetrySample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	var (
+		sb   strings.Builder
+		args = make([]any, 0, 4*len(samples))
+	)
+	sb.WriteString("INSERT INTO telemetry (ts, channel, value, site_id) VALUES ")
+	for i, s := range samples {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("(?, ?, ?, ?)")
+		at := s.At
+		if at.IsZero() {
+			at = time.Now()
+		}
+		args = append(args, at.UTC().Format(telemetryTimeLayout), s.Channel, s.Value, r.site)
+	}
+	_, err := r.db.ExecContext(ctx, sb.String(), args...)
+	return err
+}
+
+// Query returns samples matching q, ordered by time ascending.
+func (r *TelemetrySQLite) Query(ctx context.Context, q TelemetryQuery) ([]models.TelemetrySample, error) {
+	conds := []string{"site_id = ?"}
+	args := []any{r.site}
+	if q.Channel != "" {
+		conds = append(conds, "channel = ?")
+		args = append(args, q.Channel)
+	}
+	if !q.From.IsZero() {
+		conds = append(conds, "ts >= ?")
+		args = append(args, q.From.UTC().Format(telemetryTimeLayout))
+	}
+	if !q.To.IsZero() {
+		conds = append(conds, "ts <= ?")
+		args = append(args, q.To.UTC().Format(telemetryTimeLayout))
+	}
+
+	stmt := `SELECT ts, channel, value FROM telemetry WHERE ` + strings.Join(conds, " AND ")
+	stmt += " ORDER BY ts ASC, id ASC"
+	if q.Limit > 0 {
+		stmt += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.TelemetrySample, 0, 64)
+	for rows.Next() {
+		var (
+			s  models.TelemetrySample
+			ts string
+		)
+		if err := rows.Scan(&ts, &s.Channel, &s.Value); err != nil {
+			return nil, err
+		}
+		if s.At, err = time.Parse(telemetryTimeLayout, ts); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,69 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestTelemetrySQLite_AppendBatches(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 500_000_000, time.UTC)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO telemetry (ts, channel, value, site_id) VALUES (?, ?, ?, ?), (?, ?, ?, ?)")).
+		WithArgs("2025-09-20 10:00:00.500", models.ChannelChamber, 700.5, repository.DefaultSite,
+			"2025-09-20 10:00:00.500", models.ChannelAmbient, 27.1, repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(2, 2))
+
+	err = repository.NewTelemetrySQLite(db).Append(context.Background(),
+		models.TelemetrySample{At: at, Channel: models.ChannelChamber, Value: 700.5},
+		models.TelemetrySample{At: at, Channel: models.ChannelAmbient, Value: 27.1},
+	)
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTelemetrySQLite_QueryFiltersAndParses(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	from := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"ts", "channel", "value"}).
+		AddRow("2025-09-20 10:00:01.000", models.ChannelAmbient, 25.0).
+		AddRow("2025-09-20 10:00:02.250", models.ChannelAmbient, 25.2)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT ts, channel, value FROM telemetry WHERE site_id = ? AND channel = ? AND ts >= ? ORDER BY ts ASC, id ASC LIMIT ?")).
+		WithArgs(repository.DefaultSite, models.ChannelAmbient, "2025-09-20 10:00:00.000", 10).
+		WillReturnRows(rows)
+
+	got, err := repository.NewTelemetrySQLite(db).Query(context.Background(), repository.TelemetryQuery{
+		Channel: models.ChannelAmbient,
+		From:    from,
+		Limit:   10,
+	})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(got) != 2 || got[1].Value != 25.2 || !got[1].At.Equal(from.Add(2250*time.Millisecond)) {
+		t.Fatalf("unexpected samples: %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Tx holds the repositories a unit of work writes through.
+type Tx struct {
+	StateRepo StateRepo
+	EventRepo EventRepo
+}
+
+// UnitOfWork runs writes to several repositories atomically, so a crash
+// between them cannot leave the furnace state without the event that
+// explains it, or the other way round.
+type UnitOfWork interface {
+	// Do calls fn with repositories bound to one transaction and commits
+	// it if fn returns nil. Otherwise nothing written through tx is stored
+	// and fn's error is returned.
+	Do(ctx context.Context, fn func(tx Tx) error) error
+}
+
+// dbtx is what the repositories need from *sql.DB and *sql.Tx, so the same
+// code runs inside and outside a unit of work.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type UnitOfWorkSQLite struct {
+	db     *sql.DB
+	events *EventSQLite // site, hash chain, ID and clock settings for appends
+}
+
+func NewUnitOfWorkSQLite(db *sql.DB, events *EventSQLite) *UnitOfWorkSQLite {
+	return &UnitOfWorkSQLite{db: db, events: events}
+}
+
+// Do runs fn in one SQLite transaction. Reads through tx see its own
+// writes; the pool has a single connection, so fn must not use
+// repositories outside tx. The transaction is run again from the start
+// while the database is locked by another process.
+func (u *UnitOfWorkSQLite) Do(ctx context.Context, fn func(tx Tx) error) error {
+	return retryBusy(ctx, func() error { return u.do(ctx, fn) })
+}
+
+func (u *UnitOfWorkSQLite) do(ctx context.Context, fn func(tx Tx) error) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	events := *u.events
+	events.tx = tx
+	if err := fn(Tx{StateRepo: &StateSQLite{db: tx, site: events.site}, EventRepo: &events}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
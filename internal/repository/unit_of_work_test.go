@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestUnitOfWork_CommitsOrRollsBackTogether(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	for name, repos := range backends(t, func() Config { return Config{EventHashChain: true, NewID: seqIDs()} }) {
+		boom := errors.New("boom")
+		err := repos.UnitOfWork.Do(ctx, func(tx Tx) error {
+			if err := tx.StateRepo.Save(ctx, models.FurnaceState{Mode: "HEAT", IsRunning: true, UpdatedAt: at}); err != nil {
+				return err
+			}
+			if err := tx.EventRepo.Append(ctx, models.FurnaceEvent{OccurredAt: at, Type: "MODE_CHANGE"}); err != nil {
+				return err
+			}
+			return boom
+		})
+		if !errors.Is(err, boom) {
+			t.Fatalf("%s: do: %v", name, err)
+		}
+		st, _ := repos.StateRepo.Load(ctx)
+		events, _ := repos.EventRepo.Query(ctx, EventQuery{})
+		if st.ID != 0 || len(events) != 0 {
+			t.Fatalf("%s: rolled back writes were stored: %+v, %v", name, st, events)
+		}
+
+		err = repos.UnitOfWork.Do(ctx, func(tx Tx) error {
+			if err := tx.StateRepo.Save(ctx, models.FurnaceState{Mode: "HEAT", IsRunning: true, UpdatedAt: at}); err != nil {
+				return err
+			}
+			if st, err := tx.StateRepo.Load(ctx); err != nil || st.Mode != "HEAT" {
+				return errors.New("state not visible within the unit of work")
+			}
+			return tx.EventRepo.Append(ctx, models.FurnaceEvent{OccurredAt: at, Type: "MODE_CHANGE"})
+		})
+		if err != nil {
+			t.Fatalf("%s: do: %v", name, err)
+		}
+		st, _ = repos.StateRepo.Load(ctx)
+		events, _ = repos.EventRepo.Query(ctx, EventQuery{})
+		if st.Mode != "HEAT" || len(events) != 1 || events[0].Type != "MODE_CHANGE" {
+			t.Fatalf("%s: committed %+v, %v", name, st, events)
+		}
+		if chain, err := repos.Chain.VerifyChain(ctx); err != nil || !chain.Valid || chain.Checked != 1 {
+			t.Fatalf("%s: chain %+v, %v", name, chain, err)
+		}
+	}
+}
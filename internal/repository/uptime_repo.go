@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type UptimeSQLite struct {
+	db   *sql.DB
+	site string // checks of other sites are invisible
+}
+
+func NewUptimeSQLite(db *sql.DB) *UptimeSQLite { return &UptimeSQLite{db: db, site: DefaultSite} }
+
+// Ensure implementation of UptimeRepo interface at compile time.
+var _ UptimeRepo = (*UptimeSQLite)(nil)
+
+const (
+	insertUptimeCheckSQL = `INSERT INTO uptime_checks (at, up, site_id) VALUES (?, ?, ?)`
+	lastUptimeCheckSQL   = `SELECT at, up FROM uptime_checks WHERE site_id = ? ORDER BY at DESC LIMIT 1`
+	countUptimeChecksSQL = `SELECT COUNT(*), COALESCE(SUM(up), 0) FROM uptime_checks WHERE site_id = ? AND at >= ?`
+	// the first check is read by itself so the column keeps its TIMESTAMP
+	// type; MIN(at) would come back as text
+	firstUptimeCheckSQL  = `SELECT at FROM uptime_checks WHERE site_id = ? AND at >= ? ORDER BY at ASC LIMIT 1`
+	purgeUptimeChecksSQL = `DELETE FROM uptime_checks WHERE site_id = ? AND at < ?`
+)
+
+func (r *UptimeSQLite) RecordCheck(ctx context.Context, c models.UptimeCheck) error {
+	_, err := r.db.ExecContext(ctx, insertUptimeCheckSQL, c.At.UTC(), c.Up, r.site)
+	return err
+}
+
+func (r *UptimeSQLite) LastCheck(ctx context.Context) (models.UptimeCheck, error) {
+	var c models.UptimeCheck
+	err := r.db.QueryRowContext(ctx, lastUptimeCheckSQL, r.site).Scan(&c.At, &c.Up)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.UptimeCheck{}, nil
+	}
+	c.At = c.At.UTC()
+	return c, err
+}
+
+func (r *UptimeSQLite) CountChecks(ctx context.Context, since time.Time) (models.UptimeCounts, error) {
+	var n models.UptimeCounts
+	if err := r.db.QueryRowContext(ctx, countUptimeChecksSQL, r.site, since.UTC()).Scan(&n.Checks, &n.Up); err != nil {
+		return models.UptimeCounts{}, err
+	}
+	if n.Checks == 0 {
+		return n, nil
+	}
+	if err := r.db.QueryRowContext(ctx, firstUptimeCheckSQL, r.site, since.UTC()).Scan(&n.First); err != nil {
+		return models.UptimeCounts{}, err
+	}
+	n.First = n.First.UTC()
+	return n, nil
+}
+
+func (r *UptimeSQLite) PurgeChecks(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, purgeUptimeChecksSQL, r.site, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
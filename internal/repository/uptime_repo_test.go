@@ -0,0 +1,44 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestUptimeSQLite_CountChecks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	since := time.Date(2025, 9, 19, 12, 0, 0, 0, time.UTC)
+	first := since.Add(30 * time.Second)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*), COALESCE(SUM(up), 0) FROM uptime_checks WHERE site_id = ? AND at >= ?")).
+		WithArgs(repository.DefaultSite, since).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "up"}).AddRow(1440, 1438))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT at FROM uptime_checks WHERE site_id = ? AND at >= ? ORDER BY at ASC LIMIT 1")).
+		WithArgs(repository.DefaultSite, since).
+		WillReturnRows(sqlmock.NewRows([]string{"at"}).AddRow(first))
+	// an empty window skips the first-check lookup
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*)")).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "up"}).AddRow(0, 0))
+
+	repo := repository.NewUptimeSQLite(db)
+	n, err := repo.CountChecks(context.Background(), since)
+	if err != nil || n.Checks != 1440 || n.Up != 1438 || !n.First.Equal(first) {
+		t.Fatalf("CountChecks() = %+v, %v", n, err)
+	}
+	if n, err := repo.CountChecks(context.Background(), since); err != nil || n.Checks != 0 || !n.First.IsZero() {
+		t.Fatalf("CountChecks() on an empty window = %+v, %v", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+type WebhookSQLite struct {
+	db     *sql.DB
+	site   string        // webhooks, deliveries and events of other sites are invisible
+	cipher *ColumnCipher // encrypts signing secrets; nil stores them as they are
+}
+
+func NewWebhookSQLite(db *sql.DB) *WebhookSQLite { return &WebhookSQLite{db: db, site: DefaultSite} }
+
+// Ensure implementation of WebhookRepo interface at compile time.
+var _ WebhookRepo = (*WebhookSQLite)(nil)
+
+const (
+	webhookColumns = `id, url, event_types, secret, enabled, created_by, created_at, updated_at`
+
+	insertWebhookSQL = `
+		INSERT INTO webhooks (url, event_types, secret, enabled, created_by, created_at, updated_at, site_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	updateWebhookSQL = `
+		UPDATE webhooks SET url=?, event_types=?, secret=CASE WHEN ? = '' THEN secret ELSE ? END, enabled=?, updated_at=?
+		WHERE id=? AND site_id=?
+	`
+	deleteWebhookSQL           = `DELETE FROM webhooks WHERE id=? AND site_id=?`
+	deleteWebhookDeliveriesSQL = `DELETE FROM webhook_deliveries WHERE webhook_id=? AND site_id=?`
+	selectWebhookSQL           = `SELECT ` + webhookColumns + ` FROM webhooks WHERE id=? AND site_id=?`
+	listWebhooksSQL            = `SELECT ` + webhookColumns + ` FROM webhooks WHERE site_id=? ORDER BY id ASC`
+
+	// initWebhookCursorSQL starts the site's cursor at the end of its log
+	// the first time it is read.
+	initWebhookCursorSQL = `
+		INSERT OR IGNORE INTO webhook_cursor (site_id, event_rowid)
+		SELECT ?, COALESCE(MAX(rowid), 0) FROM furnace_events WHERE site_id = ?
+	`
+	selectWebhookCursorSQL = `SELECT event_rowid FROM webhook_cursor WHERE site_id=?`
+	updateWebhookCursorSQL = `UPDATE webhook_cursor SET event_rowid=? WHERE site_id=?`
+	newEventsSQL           = `
+		SELECT ` + eventColumns + `, rowid FROM furnace_events
+		WHERE site_id = ? AND rowid > ? ORDER BY rowid ASC LIMIT ?
+	`
+
+	webhookDeliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+		last_attempt_at, response_status, latency_ms, response_snippet, last_error, created_at, delivered_at`
+
+	insertWebhookDeliverySQL = `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at, created_at, site_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	updateWebhookDeliverySQL = `
+		UPDATE webhook_deliveries SET status=?, attempts=?, next_attempt_at=?, last_attempt_at=?, response_status=?,
+			latency_ms=?, response_snippet=?, last_error=?, delivered_at=?
+		WHERE id=? AND site_id=?
+	`
+	selectWebhookDeliverySQL = `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id=? AND site_id=?`
+	dueWebhookDeliveriesSQL  = `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries
+		WHERE site_id = ? AND status = 'pending' AND next_attempt_at <= ? ORDER BY next_attempt_at ASC, id ASC LIMIT ?`
+)
+
+func (r *WebhookSQLite) scanWebhook(s rowScanner) (models.Webhook, error) {
+	var w models.Webhook
+	var types string
+	err := s.Scan(&w.ID, &w.URL, &types, &w.Secret, &w.Enabled, &w.CreatedBy, &w.CreatedAt, &w.UpdatedAt)
+	if err == nil {
+		w.Secret, err = r.cipher.open(w.Secret)
+	}
+	w.EventTypes = strings.Split(types, ",")
+	w.HasSecret = w.Secret != ""
+	w.CreatedAt, w.UpdatedAt = w.CreatedAt.UTC(), w.UpdatedAt.UTC()
+	return w, err
+}
+
+func scanWebhookDelivery(s rowScanner) (models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var next, last, delivered sql.NullTime
+	err := s.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &next,
+		&last, &d.ResponseStatus, &d.LatencyMs, &d.ResponseSnippet, &d.LastError, &d.CreatedAt, &delivered)
+	d.NextAttemptAt, d.LastAttemptAt, d.DeliveredAt = nullTimePtr(next), nullTimePtr(last), nullTimePtr(delivered)
+	d.CreatedAt = d.CreatedAt.UTC()
+	return d, err
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	at := t.Time.UTC()
+	return &at
+}
+
+func timePtrArg(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// CreateWebhook stores a new webhook and returns its ID.
+func (r *WebhookSQLite) CreateWebhook(ctx context.Context, w models.Webhook) (int, error) {
+	secret, err := r.cipher.seal(w.Secret)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	res, err := r.db.ExecContext(ctx, insertWebhookSQL,
+		w.URL, strings.Join(w.EventTypes, ","), secret, w.Enabled, w.CreatedBy, now, now, r.site)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// UpdateWebhook replaces the editable fields of a webhook, keeping the
+// stored secret when w.Secret is empty.
+func (r *WebhookSQLite) UpdateWebhook(ctx context.Context, w models.Webhook) (bool, error) {
+	secret, err := r.cipher.seal(w.Secret)
+	if err != nil {
+		return false, err
+	}
+	res, err := r.db.ExecContext(ctx, updateWebhookSQL,
+		w.URL, strings.Join(w.EventTypes, ","), secret, secret, w.Enabled, time.Now().UTC(), w.ID, r.site)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteWebhook removes a webhook and its deliveries in one transaction.
+func (r *WebhookSQLite) DeleteWebhook(ctx context.Context, id int) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, deleteWebhookSQL, id, r.site)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, deleteWebhookDeliveriesSQL, id, r.site); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// GetWebhook fetches a webhook by ID; a missing webhook yields a zero value and nil error.
+func (r *WebhookSQLite) GetWebhook(ctx context.Context, id int) (models.Webhook, error) {
+	w, err := r.scanWebhook(r.db.QueryRowContext(ctx, selectWebhookSQL, id, r.site))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Webhook{}, nil
+	}
+	return w, err
+}
+
+func (r *WebhookSQLite) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, listWebhooksSQL, r.site)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.Webhook, 0, 4)
+	for rows.Next() {
+		w, err := r.scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// NewEvents reads the events after the delivery cursor by rowid, the order
+// they were appended in, so events stamped with an earlier occurred_at
+// than ones already queued are not skipped.
+func (r *WebhookSQLite) NewEvents(ctx context.Context, limit int) ([]models.FurnaceEvent, int64, error) {
+	if _, err := r.db.ExecContext(ctx, initWebhookCursorSQL, r.site, r.site); err != nil {
+		return nil, 0, err
+	}
+	var cursor int64
+	if err := r.db.QueryRowContext(ctx, selectWebhookCursorSQL, r.site).Scan(&cursor); err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.db.QueryContext(ctx, newEventsSQL, r.site, cursor, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var out []models.FurnaceEvent
+	for rows.Next() {
+		ev, err := scanEvent(rows, &cursor)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, ev)
+	}
+	return out, cursor, rows.Err()
+}
+
+func (r *WebhookSQLite) Enqueue(ctx context.Context, deliveries []models.WebhookDelivery, through int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, d := range deliveries {
+		if _, err := tx.ExecContext(ctx, insertWebhookDeliverySQL,
+			d.WebhookID, d.EventID, d.EventType, d.Payload, d.Status, timePtrArg(d.NextAttemptAt), d.CreatedAt.UTC(), r.site); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, updateWebhookCursorSQL, through, r.site); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *WebhookSQLite) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	return r.queryDeliveries(ctx, dueWebhookDeliveriesSQL, r.site, now.UTC(), limit)
+}
+
+func (r *WebhookSQLite) UpdateDelivery(ctx context.Context, d models.WebhookDelivery) error {
+	_, err := r.db.ExecContext(ctx, updateWebhookDeliverySQL,
+		d.Status, d.Attempts, timePtrArg(d.NextAttemptAt), timePtrArg(d.LastAttemptAt), d.ResponseStatus,
+		d.LatencyMs, d.ResponseSnippet, d.LastError, timePtrArg(d.DeliveredAt), d.ID, r.site)
+	return err
+}
+
+// GetDelivery fetches a delivery by ID; a missing delivery yields a zero value and nil error.
+func (r *WebhookSQLite) GetDelivery(ctx context.Context, id int64) (models.WebhookDelivery, error) {
+	d, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, selectWebhookDeliverySQL, id, r.site))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.WebhookDelivery{}, nil
+	}
+	return d, err
+}
+
+// ListDeliveries returns deliveries matching q, newest first.
+func (r *WebhookSQLite) ListDeliveries(ctx context.Context, q WebhookDeliveryQuery) ([]models.WebhookDelivery, error) {
+	stmt := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries`
+	conds := []string{"site_id = ?"}
+	args := []any{r.site}
+	if q.WebhookID != 0 {
+		conds = append(conds, "webhook_id = ?")
+		args = append(args, q.WebhookID)
+	}
+	if q.Status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, q.Status)
+	}
+	stmt += " WHERE " + strings.Join(conds, " AND ")
+	stmt += " ORDER BY id DESC"
+	if q.Limit > 0 {
+		stmt += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+	return r.queryDeliveries(ctx, stmt, args...)
+}
+
+func (r *WebhookSQLite) queryDeliveries(ctx context.Context, stmt string, args ...any) ([]models.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.WebhookDelivery, 0, 16)
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
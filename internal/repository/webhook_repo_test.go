@@ -0,0 +1,131 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestWebhookSQLite_NewEventsReadsAfterTheCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT OR IGNORE INTO webhook_cursor")).
+		WithArgs(repository.DefaultSite, repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT event_rowid FROM webhook_cursor WHERE site_id=?")).
+		WithArgs(repository.DefaultSite).
+		WillReturnRows(sqlmock.NewRows([]string{"event_rowid"}).AddRow(int64(40)))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM furnace_events\n\t\tWHERE site_id = ? AND rowid > ? ORDER BY rowid ASC LIMIT ?")).
+		WithArgs(repository.DefaultSite, int64(40), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "occurred_at", "type", "message", "meta", "deleted_at", "deleted_by", "delete_reason", "rowid"}).
+			AddRow("e1", at, "STOP", "Furnace stopped", `{"run_id":"run-1"}`, nil, nil, nil, int64(41)).
+			AddRow("e2", at, "ERROR", "Overheat", nil, nil, nil, nil, int64(43)))
+
+	repo := repository.NewWebhookSQLite(db)
+	events, through, err := repo.NewEvents(context.Background(), 10)
+	if err != nil || through != 43 || len(events) != 2 || events[0].EventID != "e1" || events[1].Type != "ERROR" {
+		t.Fatalf("NewEvents() = %+v, %d, %v", events, through, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestWebhookSQLite_EnqueueMovesTheCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO webhook_deliveries")).
+		WithArgs(2, "e1", "STOP", `{"type":"STOP"}`, models.DeliveryPending, at, at, repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE webhook_cursor SET event_rowid=?")).
+		WithArgs(int64(43), repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	repo := repository.NewWebhookSQLite(db)
+	d := models.WebhookDelivery{WebhookID: 2, EventID: "e1", EventType: "STOP", Payload: `{"type":"STOP"}`,
+		Status: models.DeliveryPending, NextAttemptAt: &at, CreatedAt: at}
+	if err := repo.Enqueue(context.Background(), []models.WebhookDelivery{d}, 43); err != nil {
+		t.Fatalf("Enqueue(): %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestWebhookSQLite_UpdateKeepsSecretWhenEmpty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE webhooks SET url=?, event_types=?, secret=CASE WHEN ? = '' THEN secret ELSE ? END")).
+		WithArgs("https://mes.example.com/hook", "STOP,ERROR", "", "", true, sqlmock.AnyArg(), 2, repository.DefaultSite).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM webhooks WHERE id=? AND site_id=?")).
+		WithArgs(2, repository.DefaultSite).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "event_types", "secret", "enabled", "created_by", "created_at", "updated_at"}).
+			AddRow(2, "https://mes.example.com/hook", "STOP,ERROR", "s3cret", true, 1, time.Now(), time.Now()))
+
+	repo := repository.NewWebhookSQLite(db)
+	w := models.Webhook{ID: 2, URL: "https://mes.example.com/hook", EventTypes: []string{"STOP", "ERROR"}, Enabled: true}
+	if found, err := repo.UpdateWebhook(context.Background(), w); err != nil || !found {
+		t.Fatalf("UpdateWebhook() = %v, %v", found, err)
+	}
+	got, err := repo.GetWebhook(context.Background(), 2)
+	if err != nil || !got.HasSecret || len(got.EventTypes) != 2 || got.EventTypes[1] != "ERROR" {
+		t.Fatalf("GetWebhook() = %+v, %v", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestWebhookSQLite_GetDeliveryReadsTheLastAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	cols := []string{"id", "webhook_id", "event_id", "event_type", "payload", "status", "attempts", "next_attempt_at",
+		"last_attempt_at", "response_status", "latency_ms", "response_snippet", "last_error", "created_at", "delivered_at"}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM webhook_deliveries WHERE id=? AND site_id=?")).
+		WithArgs(int64(5), repository.DefaultSite).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow(int64(5), 2, "e1", "STOP", `{}`, models.DeliveryFailed, 8, nil, at, 502, int64(87), "bad gateway", "delivery rejected: 502 Bad Gateway", at, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM webhook_deliveries WHERE id=? AND site_id=?")).
+		WithArgs(int64(6), repository.DefaultSite).
+		WillReturnRows(sqlmock.NewRows(cols))
+
+	repo := repository.NewWebhookSQLite(db)
+	d, err := repo.GetDelivery(context.Background(), 5)
+	if err != nil || d.ID != 5 || d.LatencyMs != 87 || d.ResponseSnippet != "bad gateway" || d.LastAttemptAt == nil {
+		t.Fatalf("GetDelivery(5) = %+v, %v", d, err)
+	}
+	if d, err := repo.GetDelivery(context.Background(), 6); err != nil || d.ID != 0 {
+		t.Fatalf("GetDelivery(6) = %+v, %v, want a zero value", d, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
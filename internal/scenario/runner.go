@@ -0,0 +1,127 @@
+package scenario
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+	"controlling_furnace/internal/service"
+)
+
+// ErrExpectationFailed is returned when an expect step finds no matching event.
+var ErrExpectationFailed = errors.New("scenario expectation failed")
+
+// Result is what a scenario produced.
+type Result struct {
+	Name   string                `json:"name"`
+	Start  time.Time             `json:"start"`
+	End    time.Time             `json:"end"`
+	Events []models.FurnaceEvent `json:"events"` // logged between Start and End
+	State  models.FurnaceState   `json:"state"`  // furnace state after the last step
+}
+
+// eventsSince lists events logged from t on. occurred_at is stored without
+// the zone suffix the driver adds to bound times, so an event in the very
+// second of t would compare lower; the bound is widened by a second.
+func eventsSince(ctx context.Context, svc *service.Service, t time.Time, typ string) ([]models.FurnaceEvent, error) {
+	return svc.EventLog.List(ctx, service.LogFilter{From: t.Add(-time.Second), Type: typ})
+}
+
+// clock is the simulated time shared by commands and simulation steps.
+type clock struct{ t time.Time }
+
+func (c *clock) Now() time.Time { return c.t }
+
+// Run executes sc against services built on conn with cfg. The simulator is
+// not run in the background: waits advance it with Simulator.Step, so the
+// outcome is reproducible and a long soak replays in milliseconds. conn
+// should not be shared with a running server. On failure the partial result
+// is returned with the error.
+func Run(ctx context.Context, conn *sql.DB, sc Script, cfg service.Config) (Result, error) {
+	if err := sc.Validate(); err != nil {
+		return Result{}, err
+	}
+	start := sc.Start
+	if start.IsZero() {
+		start = time.Now()
+	}
+	// events are stored with second precision
+	clk := &clock{t: start.UTC().Truncate(time.Second)}
+	cfg.Clock = clk.Now
+	tick := sc.Tick
+	if tick == 0 {
+		tick = DefaultTick
+	}
+
+	svc := service.NewServiceWithConfig(repository.NewRepository(conn), cfg)
+	res := Result{Name: sc.Name, Start: clk.t}
+	var err error
+	for i, st := range sc.Steps {
+		if err = runStep(ctx, svc, clk, tick, res.Start, st); err != nil {
+			err = fmt.Errorf("step %d (%s): %w", i+1, st.Do, err)
+			break
+		}
+	}
+
+	res.End = clk.t
+	var qerr error
+	if res.Events, qerr = eventsSince(ctx, svc, res.Start, ""); qerr != nil && err == nil {
+		err = qerr
+	}
+	if res.State, qerr = svc.Monitoring.GetState(ctx); qerr != nil && err == nil {
+		err = qerr
+	}
+	return res, err
+}
+
+func runStep(ctx context.Context, svc *service.Service, clk *clock, tick time.Duration, since time.Time, st Step) error {
+	switch strings.ToLower(st.Do) {
+	case ActionStart:
+		return svc.Furnace.Start(ctx)
+	case ActionStop:
+		return svc.Furnace.Stop(ctx)
+	case ActionHeat:
+		return svc.Furnace.SetMode(ctx, service.ModeParams{
+			Mode:        service.ModeHeat,
+			TargetTempC: st.TargetC,
+			DurationSec: int(st.Duration / time.Second),
+		})
+	case ActionCool:
+		return svc.Furnace.SetMode(ctx, service.ModeParams{Mode: service.ModeCool})
+	case ActionStandby:
+		return svc.Furnace.SetMode(ctx, service.ModeParams{Mode: service.ModeStandby})
+	case ActionWait:
+		for left := st.For; left > 0; {
+			dt := min(tick, left)
+			if err := svc.Simulator.Step(ctx, dt); err != nil {
+				return err
+			}
+			clk.t = clk.t.Add(dt)
+			left -= dt
+		}
+		return nil
+	case ActionFault:
+		return svc.Faults.InjectFault(strings.ToUpper(st.Fault))
+	case ActionClearFault:
+		if st.Fault == "" {
+			svc.Faults.ClearAllFaults()
+			return nil
+		}
+		return svc.Faults.ClearFault(strings.ToUpper(st.Fault))
+	case ActionExpect:
+		evs, err := eventsSince(ctx, svc, since, strings.ToUpper(st.Event))
+		if err != nil {
+			return err
+		}
+		if len(evs) == 0 {
+			return fmt.Errorf("%w: no %s event", ErrExpectationFailed, strings.ToUpper(st.Event))
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: unknown action %q", ErrInvalidScript, st.Do)
+}
@@ -0,0 +1,104 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/repository/db"
+	"controlling_furnace/internal/service"
+)
+
+const heaterFailure = `
+name: heater failure during soak
+start: 2024-01-01T08:00:00Z
+steps:
+  - do: start
+  - do: heat
+    target_c: 800
+    duration: 10m
+  - do: wait
+    for: 5m
+  - do: fault
+    fault: heater_failure
+  - do: wait
+    for: 30s
+  - do: expect
+    event: ERROR
+  - do: stop
+`
+
+func run(t *testing.T, script string) (Result, error) {
+	t.Helper()
+	sc, err := Parse([]byte(script))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	conn, err := db.InitDB(":memory:")
+	if err != nil {
+		t.Fatalf("init db: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return Run(context.Background(), conn, sc, service.DefaultConfig())
+}
+
+func TestRun_ReplaysOnSimulatedClock(t *testing.T) {
+	res, err := run(t, heaterFailure)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	if !res.Start.Equal(start) || !res.End.Equal(start.Add(5*time.Minute+30*time.Second)) {
+		t.Fatalf("unexpected timeline %s..%s", res.Start, res.End)
+	}
+
+	var types []string
+	for _, ev := range res.Events {
+		types = append(types, ev.Type)
+	}
+	want := []string{"START", "MODE_CHANGE", "ERROR", "SOAK_UNSTABLE", "STOP"}
+	if len(types) != len(want) {
+		t.Fatalf("events = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("events = %v, want %v", types, want)
+		}
+	}
+	if last := res.Events[len(res.Events)-1]; !last.OccurredAt.Equal(res.End) {
+		t.Fatalf("STOP should be stamped at the simulated end, got %s", last.OccurredAt)
+	}
+	if res.State.IsRunning || res.State.CurrentTempC < 700 {
+		t.Fatalf("expected a stopped, still hot furnace, got %+v", res.State)
+	}
+
+	again, err := run(t, heaterFailure)
+	if err != nil || again.State.CurrentTempC != res.State.CurrentTempC {
+		t.Fatalf("replay diverged: %.2f vs %.2f (%v)", again.State.CurrentTempC, res.State.CurrentTempC, err)
+	}
+}
+
+func TestRun_FailedExpectationReturnsPartialResult(t *testing.T) {
+	res, err := run(t, `{"steps": [{"do": "start"}, {"do": "wait", "for": "10s"}, {"do": "expect", "event": "OVERHEAT"}]}`)
+	if !errors.Is(err, ErrExpectationFailed) {
+		t.Fatalf("expected ErrExpectationFailed, got %v", err)
+	}
+	if len(res.Events) != 1 || res.Events[0].Type != "START" {
+		t.Fatalf("expected the START event in the partial result, got %+v", res.Events)
+	}
+}
+
+func TestParse_RejectsInvalidSteps(t *testing.T) {
+	for _, script := range []string{
+		`steps: []`,
+		`steps: [{do: explode}]`,
+		`steps: [{do: heat, target_c: 800}]`,
+		`steps: [{do: wait, for: 300}]`, // durations need a unit
+		`steps: [{do: fault}]`,
+	} {
+		if _, err := Parse([]byte(script)); !errors.Is(err, ErrInvalidScript) {
+			t.Fatalf("%s: expected ErrInvalidScript, got %v", script, err)
+		}
+	}
+}
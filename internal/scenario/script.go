@@ -0,0 +1,118 @@
+// Package scenario replays scripted furnace sessions — commands, faults and
+// time jumps — against the real services on a simulated clock. Scripts are
+// YAML (or JSON, which YAML accepts):
+//
+//	name: heater failure during soak
+//	start: 2024-01-01T08:00:00Z
+//	steps:
+//	  - do: start
+//	  - do: heat
+//	    target_c: 800
+//	    duration: 10m
+//	  - do: wait
+//	    for: 5m
+//	  - do: fault
+//	    fault: HEATER_FAILURE
+//	  - do: wait
+//	    for: 1m
+//	  - do: expect
+//	    event: ERROR
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidScript is returned for scripts that cannot be run.
+var ErrInvalidScript = errors.New("invalid scenario script")
+
+// DefaultTick is the simulation step used for waits when a script sets none.
+const DefaultTick = time.Second
+
+// Step actions.
+const (
+	ActionStart      = "start"
+	ActionStop       = "stop"
+	ActionHeat       = "heat"        // target_c, duration
+	ActionCool       = "cool"        //
+	ActionStandby    = "standby"     //
+	ActionWait       = "wait"        // for
+	ActionFault      = "fault"       // fault
+	ActionClearFault = "clear_fault" // fault, or all faults when empty
+	ActionExpect     = "expect"      // event: fails the run unless such an event occurred
+)
+
+// Script is a named sequence of steps.
+type Script struct {
+	Name  string        `yaml:"name"`
+	Start time.Time     `yaml:"start"` // simulated start time; now when omitted
+	Tick  time.Duration `yaml:"tick"`  // simulation step for waits; DefaultTick when zero
+	Steps []Step        `yaml:"steps"`
+}
+
+// Step is one scripted action. Only the fields its action uses are read.
+type Step struct {
+	Do       string        `yaml:"do"`
+	TargetC  float64       `yaml:"target_c"`
+	Duration time.Duration `yaml:"duration"`
+	For      time.Duration `yaml:"for"`
+	Fault    string        `yaml:"fault"`
+	Event    string        `yaml:"event"`
+}
+
+// Parse decodes and validates a YAML or JSON script.
+func Parse(data []byte) (Script, error) {
+	var sc Script
+	if err := yaml.Unmarshal(data, &sc); err != nil {
+		return sc, fmt.Errorf("%w: %v", ErrInvalidScript, err)
+	}
+	return sc, sc.Validate()
+}
+
+// Validate checks that every step has what its action needs.
+func (sc Script) Validate() error {
+	if sc.Tick < 0 {
+		return fmt.Errorf("%w: tick must be positive", ErrInvalidScript)
+	}
+	if len(sc.Steps) == 0 {
+		return fmt.Errorf("%w: no steps", ErrInvalidScript)
+	}
+	for i, st := range sc.Steps {
+		if err := st.validate(); err != nil {
+			return fmt.Errorf("%w: step %d: %v", ErrInvalidScript, i+1, err)
+		}
+	}
+	return nil
+}
+
+func (st Step) validate() error {
+	switch strings.ToLower(st.Do) {
+	case ActionStart, ActionStop, ActionCool, ActionStandby, ActionClearFault:
+	case ActionHeat:
+		if st.TargetC <= 0 || st.Duration < time.Second {
+			return errors.New("heat needs target_c and a duration of at least 1s")
+		}
+	case ActionWait:
+		if st.For <= 0 {
+			return errors.New("wait needs a positive for")
+		}
+	case ActionFault:
+		if st.Fault == "" {
+			return errors.New("fault needs a fault type")
+		}
+	case ActionExpect:
+		if st.Event == "" {
+			return errors.New("expect needs an event type")
+		}
+	case "":
+		return errors.New("missing do")
+	default:
+		return fmt.Errorf("unknown action %q", st.Do)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidListenAddr is returned when a port names no usable socket.
+var ErrInvalidListenAddr = errors.New("invalid listen address")
+
+// Forms of a port other than a TCP port.
+const (
+	unixPrefix    = "unix:"   // unix:/run/furnace/api.sock; a path with a slash also works
+	systemdPrefix = "systemd" // systemd, or systemd:NAME for the socket of FileDescriptorName=NAME
+)
+
+// socketMode lets the owner and group of the service, e.g. an HMI
+// process in the group, connect to a unix socket.
+const socketMode fs.FileMode = 0o660
+
+// listenFdsStart is the first file descriptor systemd passes
+// (SD_LISTEN_FDS_START).
+var listenFdsStart = 3
+
+// listen opens the listener port names: a TCP port ("8080" or ":8080"),
+// a unix socket path, or a socket inherited through systemd socket
+// activation.
+func listen(port string) (net.Listener, error) {
+	switch {
+	case port == systemdPrefix || strings.HasPrefix(port, systemdPrefix+":"):
+		return systemdListener(strings.TrimPrefix(strings.TrimPrefix(port, systemdPrefix), ":"))
+	case strings.HasPrefix(port, unixPrefix):
+		return unixListener(strings.TrimPrefix(port, unixPrefix))
+	case strings.Contains(port, "/"):
+		return unixListener(port)
+	}
+	return net.Listen("tcp", normalizeAddr(port))
+}
+
+// unixListener listens on the socket at path, replacing the one a
+// previous run left behind. The socket is removed when the listener
+// closes.
+func unixListener(path string) (net.Listener, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%w: empty unix socket path", ErrInvalidListenAddr)
+	}
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("%w: %s exists and is not a socket", ErrInvalidListenAddr, path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, socketMode); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// systemdListener takes over a socket passed by systemd: the first one,
+// or the one named name with FileDescriptorName=.
+func systemdListener(name string) (net.Listener, error) {
+	if pid, _ := strconv.Atoi(os.Getenv("LISTEN_PID")); pid != os.Getpid() {
+		return nil, fmt.Errorf("%w: no sockets passed by systemd", ErrInvalidListenAddr)
+	}
+	n, _ := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	i := 0
+	for name != "" && i < n && (i >= len(names) || names[i] != name) {
+		i++
+	}
+	if i >= n {
+		return nil, fmt.Errorf("%w: systemd passed no socket named %q", ErrInvalidListenAddr, name)
+	}
+	f := os.NewFile(uintptr(listenFdsStart+i), "systemd:"+name)
+	// the listener holds a duplicate
+	defer f.Close()
+	return net.FileListener(f)
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestListen_UnixSocket(t *testing.T) {
+	// socket paths are limited to about 100 bytes, too few for t.TempDir
+	dir, err := os.MkdirTemp("", "furnace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "api.sock")
+
+	// a socket left behind by a previous run is replaced
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	srv := &Server{}
+	go func() {
+		_ = srv.Run("unix:"+path, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { _, _ = io.WriteString(w, "ok") }))
+	}()
+	defer srv.Shutdown(context.Background())
+
+	client := &http.Client{Transport: &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, "unix", path)
+	}}}
+	var resp *http.Response
+	for i := 0; i < 100; i++ {
+		if resp, err = client.Get("http://furnace/healthz"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Fatalf("body %q", body)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != socketMode {
+		t.Fatalf("socket mode: %v, %v", fi, err)
+	}
+
+	// other files are not replaced
+	file := filepath.Join(dir, "config.yml")
+	writeFile(t, file, []byte("port: 8080"))
+	if _, err := listen(file); !errors.Is(err, ErrInvalidListenAddr) {
+		t.Fatalf("expected ErrInvalidListenAddr, got %v", err)
+	}
+	if b, _ := os.ReadFile(file); string(b) != "port: 8080" {
+		t.Fatal("the file was replaced")
+	}
+}
+
+func TestListen_SystemdSocket(t *testing.T) {
+	if _, err := listen("systemd"); !errors.Is(err, ErrInvalidListenAddr) {
+		t.Fatalf("without systemd: %v", err)
+	}
+
+	// pass the sockets as systemd would, from the descriptor of the first
+	api, _ := net.Listen("tcp", "127.0.0.1:0")
+	defer api.Close()
+	admin, _ := net.Listen("tcp", "127.0.0.1:0")
+	defer admin.Close()
+	// bare duplicates, which listen takes over and closes
+	fds := make([]int, 2)
+	for i, ln := range []net.Listener{api, admin} {
+		f, _ := ln.(*net.TCPListener).File()
+		fds[i], _ = syscall.Dup(int(f.Fd()))
+		_ = f.Close()
+	}
+	if fds[1] != fds[0]+1 {
+		_, _ = syscall.Close(fds[0]), syscall.Close(fds[1])
+		t.Skip("descriptors not consecutive")
+	}
+	defer func(start int) { listenFdsStart = start }(listenFdsStart)
+	listenFdsStart = fds[0]
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "2")
+	t.Setenv("LISTEN_FDNAMES", "api:admin")
+
+	for port, want := range map[string]net.Addr{"systemd:admin": admin.Addr(), "systemd": api.Addr()} {
+		ln, err := listen(port)
+		if err != nil {
+			t.Fatalf("%s: %v", port, err)
+		}
+		if ln.Addr().String() != want.String() {
+			t.Errorf("%s: listening on %s, want %s", port, ln.Addr(), want)
+		}
+		_ = ln.Close()
+	}
+	if _, err := listen("systemd:metrics"); !errors.Is(err, ErrInvalidListenAddr) {
+		t.Fatalf("unknown name: %v", err)
+	}
+}
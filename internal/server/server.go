@@ -2,17 +2,32 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
 // Server wraps an *http.Server to provide start/shutdown lifecycle.
 type Server struct {
-	httpServer *http.Server
+	// TLS, when enabled, serves HTTPS with the configured certificate and,
+	// optionally, verifies client certificates.
+	TLS TLSConfig
+	// OnCertReload, when set, is told the outcome of each reload of the
+	// TLS files after they changed; a failed reload keeps the old ones.
+	OnCertReload func(err error)
+	// HTTP sets the timeouts and limits of the listeners.
+	HTTP HTTPConfig
+
+	mu          sync.Mutex
+	httpServer  *http.Server
+	adminServer *http.Server
+	certs       *certReloader
 }
 
-// Extracted constants to avoid magic numbers and centralize tuning knobs.
+// Defaults of HTTPConfig.
 const (
 	maxHeaderBytes    = 1 << 20 // 1 MB
 	readHeaderTimeout = 10 * time.Second
@@ -20,15 +35,64 @@ const (
 	idleTimeout       = 60 * time.Second
 )
 
+// ErrInvalidHTTPConfig is returned by HTTPConfig.Validate.
+var ErrInvalidHTTPConfig = errors.New("invalid http config")
+
+// HTTPConfig tunes the timeouts and limits of both listeners. Zero
+// fields take the defaults, except ReadTimeout, which is off unless set.
+// The write timeout bounds ordinary responses; WebSocket connections and
+// the log streams manage their own write deadlines and are not cut by it.
+type HTTPConfig struct {
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	// ReadTimeout bounds reading a whole request, body included.
+	ReadTimeout    time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout   time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout    time.Duration `mapstructure:"idle_timeout"` // keep-alive connections
+	MaxHeaderBytes int           `mapstructure:"max_header_bytes"`
+	// ShutdownTimeout bounds the whole graceful shutdown: draining the
+	// streams, the final save and in-flight requests.
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+}
+
+// Validate rejects negative settings.
+func (c HTTPConfig) Validate() error {
+	if c.ReadHeaderTimeout < 0 || c.ReadTimeout < 0 || c.WriteTimeout < 0 || c.IdleTimeout < 0 || c.ShutdownTimeout < 0 {
+		return fmt.Errorf("%w: timeouts must not be negative", ErrInvalidHTTPConfig)
+	}
+	if c.MaxHeaderBytes < 0 {
+		return fmt.Errorf("%w: max_header_bytes must not be negative", ErrInvalidHTTPConfig)
+	}
+	return nil
+}
+
+func (c HTTPConfig) withDefaults() HTTPConfig {
+	if c.ReadHeaderTimeout == 0 {
+		c.ReadHeaderTimeout = readHeaderTimeout
+	}
+	if c.WriteTimeout == 0 {
+		c.WriteTimeout = writeTimeout
+	}
+	if c.IdleTimeout == 0 {
+		c.IdleTimeout = idleTimeout
+	}
+	if c.MaxHeaderBytes == 0 {
+		c.MaxHeaderBytes = maxHeaderBytes
+	}
+
+	return c
+}
+
 // newHTTPServer builds a configured *http.Server for the given address and handler.
-func newHTTPServer(addr string, handler http.Handler) *http.Server {
+func newHTTPServer(addr string, handler http.Handler, cfg HTTPConfig) *http.Server {
+	cfg = cfg.withDefaults()
 	return &http.Server{
 		Addr:              addr,
 		Handler:           handler,
-		MaxHeaderBytes:    maxHeaderBytes,
-		ReadHeaderTimeout: readHeaderTimeout,
-		WriteTimeout:      writeTimeout,
-		IdleTimeout:       idleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
 	}
 }
 
@@ -44,19 +108,64 @@ func normalizeAddr(port string) string {
 	return ":" + port
 }
 
-// Run starts the HTTP server on the given port using the provided handler.
+// Run starts the HTTP server on the given port using the provided handler,
+// serving HTTPS when TLS is enabled. The port may also be a unix socket
+// path or name a socket passed by systemd (see listen).
 func (s *Server) Run(port string, handler http.Handler) error {
-	addr := normalizeAddr(port)
+	ln, err := listen(port)
+	if err != nil {
+		return err
+	}
 	// ... existing code ...
-	s.httpServer = newHTTPServer(addr, handler)
-	return s.httpServer.ListenAndServe()
+	srv := newHTTPServer(ln.Addr().String(), handler, s.HTTP)
+	if !s.TLS.Enabled() {
+		s.mu.Lock()
+		s.httpServer = srv
+		s.mu.Unlock()
+		return srv.Serve(ln)
+	}
+	certs, err := newCertReloader(s.TLS, s.OnCertReload)
+	if err != nil {
+		_ = ln.Close()
+		return err
+	}
+	srv.TLSConfig = certs.serverConfig()
+	s.mu.Lock()
+	s.httpServer, s.certs = srv, certs
+	s.mu.Unlock()
+	// the certificate comes from TLSConfig
+	return srv.ServeTLS(ln, "", "")
+}
+
+// RunAdmin starts a second, plain HTTP listener on the given port for
+// the operational endpoints (probes, metrics, profiling), so that it can
+// be firewalled apart from the API. It is stopped by Shutdown too.
+func (s *Server) RunAdmin(port string, handler http.Handler) error {
+	ln, err := listen(port)
+	if err != nil {
+		return err
+	}
+	srv := newHTTPServer(ln.Addr().String(), handler, s.HTTP)
+	s.mu.Lock()
+	s.adminServer = srv
+	s.mu.Unlock()
+	return srv.Serve(ln)
 }
 
 // Shutdown gracefully stops the server, allowing in-flight requests to complete.
 func (s *Server) Shutdown(ctx context.Context) error {
-	if s.httpServer == nil {
-		return nil
-	}
+	s.mu.Lock()
+	api, admin, certs := s.httpServer, s.adminServer, s.certs
+	s.mu.Unlock()
 	// ... existing code ...
-	return s.httpServer.Shutdown(ctx)
+	if certs != nil {
+		certs.close()
+	}
+	var errs []error
+	for _, srv := range []*http.Server{api, admin} {
+		if srv != nil {
+			errs = append(errs, srv.Shutdown(ctx))
+		}
+	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHTTPConfig_Defaults(t *testing.T) {
+	srv := newHTTPServer(":8080", nil, HTTPConfig{})
+	if srv.ReadHeaderTimeout != readHeaderTimeout || srv.WriteTimeout != writeTimeout || srv.IdleTimeout != idleTimeout ||
+		srv.MaxHeaderBytes != maxHeaderBytes || srv.ReadTimeout != 0 {
+		t.Fatalf("defaults: %+v", srv)
+	}
+
+	cfg := HTTPConfig{ReadHeaderTimeout: 2 * time.Second, ReadTimeout: time.Minute, WriteTimeout: 30 * time.Second, IdleTimeout: 5 * time.Minute, MaxHeaderBytes: 8 << 10}
+	srv = newHTTPServer(":8080", nil, cfg)
+	if srv.ReadHeaderTimeout != cfg.ReadHeaderTimeout || srv.ReadTimeout != cfg.ReadTimeout || srv.WriteTimeout != cfg.WriteTimeout ||
+		srv.IdleTimeout != cfg.IdleTimeout || srv.MaxHeaderBytes != cfg.MaxHeaderBytes {
+		t.Fatalf("configured: %+v", srv)
+	}
+}
+
+func TestHTTPConfig_Validate(t *testing.T) {
+	if err := (HTTPConfig{}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []HTTPConfig{{ReadHeaderTimeout: -1}, {ReadTimeout: -1}, {WriteTimeout: -time.Second}, {IdleTimeout: -1}, {MaxHeaderBytes: -1}} {
+		if err := bad.Validate(); !errors.Is(err, ErrInvalidHTTPConfig) {
+			t.Errorf("%+v: expected ErrInvalidHTTPConfig, got %v", bad, err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// shutdownTimeout is the default deadline of Shutdown.
+const shutdownTimeout = 10 * time.Second
+
+// ShutdownStep is a stage of a graceful shutdown. Stop must return once
+// its context is done.
+type ShutdownStep struct {
+	Name string
+	Stop func(ctx context.Context) error
+}
+
+// GracefulShutdown runs steps in order under a single deadline of timeout,
+// the default when zero. A step that fails or overruns the deadline does
+// not hold back the later ones, which then run with the expired context,
+// so that the listeners are always stopped. The errors are returned
+// joined, each prefixed with the name of its step.
+func GracefulShutdown(timeout time.Duration, steps ...ShutdownStep) error {
+	if timeout <= 0 {
+		timeout = shutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	for _, step := range steps {
+		if err := step.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", step.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
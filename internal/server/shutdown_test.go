@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGracefulShutdown_RunsStepsInOrderUnderOneDeadline(t *testing.T) {
+	var ran []string
+	step := func(name string, err error) ShutdownStep {
+		return ShutdownStep{Name: name, Stop: func(context.Context) error {
+			ran = append(ran, name)
+			return err
+		}}
+	}
+	if err := GracefulShutdown(time.Second, step("streams", nil), step("loops", nil), step("http", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ran, ",") != "streams,loops,http" {
+		t.Fatalf("order: %v", ran)
+	}
+
+	// a failed or overrunning step does not hold back the listeners
+	ran = nil
+	boom := errors.New("boom")
+	stuck := ShutdownStep{Name: "stuck", Stop: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	start := time.Now()
+	err := GracefulShutdown(50*time.Millisecond, step("streams", boom), stuck, step("http", nil))
+	if time.Since(start) > time.Second {
+		t.Fatalf("the deadline was not kept: %v", time.Since(start))
+	}
+	if !errors.Is(err, boom) || !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "stuck: ") {
+		t.Fatalf("errors: %v", err)
+	}
+	if strings.Join(ran, ",") != "streams,http" {
+		t.Fatalf("ran: %v", ran)
+	}
+}
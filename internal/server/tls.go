@@ -0,0 +1,224 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ErrInvalidTLSConfig is returned by TLSConfig.Validate.
+var ErrInvalidTLSConfig = errors.New("invalid tls config")
+
+// Client certificate policies of TLSConfig.ClientAuth.
+const (
+	ClientAuthNone     = "none"     // no client certificate is asked for
+	ClientAuthOptional = "optional" // verified against the client CAs when presented
+	ClientAuthRequire  = "require"  // every client must present a valid one
+)
+
+// reloadDelay lets a certificate and its key both be rewritten before
+// they are read again.
+const reloadDelay = 250 * time.Millisecond
+
+// TLSConfig serves HTTPS instead of HTTP when CertFile is set. The files
+// are read again whenever they change, so renewed certificates take
+// effect without a restart.
+type TLSConfig struct {
+	CertFile string `mapstructure:"cert_file"` // PEM certificate chain
+	KeyFile  string `mapstructure:"key_file"`  // PEM private key
+	// ClientCAFile holds the PEM CAs client certificates are verified
+	// against, for machine clients authenticating with mutual TLS.
+	ClientCAFile string `mapstructure:"client_ca_file"`
+	// ClientAuth is none (the default), optional or require.
+	ClientAuth string `mapstructure:"client_auth"`
+}
+
+// Enabled reports whether the server is to serve HTTPS.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// Validate checks that the settings are complete and the files load.
+func (c TLSConfig) Validate() error {
+	if !c.Enabled() {
+		if c.KeyFile != "" || c.ClientCAFile != "" {
+			return fmt.Errorf("%w: key_file and client_ca_file need cert_file", ErrInvalidTLSConfig)
+		}
+		return nil
+	}
+	if c.KeyFile == "" {
+		return fmt.Errorf("%w: cert_file needs key_file", ErrInvalidTLSConfig)
+	}
+	switch c.ClientAuth {
+	case "", ClientAuthNone:
+	case ClientAuthOptional, ClientAuthRequire:
+		if c.ClientCAFile == "" {
+			return fmt.Errorf("%w: client_auth %s needs client_ca_file", ErrInvalidTLSConfig, c.ClientAuth)
+		}
+	default:
+		return fmt.Errorf("%w: client_auth must be none, optional or require", ErrInvalidTLSConfig)
+	}
+	_, err := c.load()
+	return err
+}
+
+// load reads the files into a server configuration.
+func (c TLSConfig) load() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTLSConfig, err)
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{cert}}
+	if c.ClientCAFile == "" {
+		return cfg, nil
+	}
+	pem, err := os.ReadFile(c.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTLSConfig, err)
+	}
+	cfg.ClientCAs = x509.NewCertPool()
+	if !cfg.ClientCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%w: no certificates in %s", ErrInvalidTLSConfig, c.ClientCAFile)
+	}
+	switch c.ClientAuth {
+	case ClientAuthOptional:
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// certReloader hands each TLS handshake the configuration last loaded
+// from the files, loading them again when they change. A failed reload
+// keeps the previous configuration.
+type certReloader struct {
+	cfg     TLSConfig
+	current atomic.Pointer[tls.Config]
+	// reloaded, when set, is told the outcome of every reload
+	reloaded func(error)
+
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+	once    sync.Once
+}
+
+// newCertReloader loads the files and starts watching them.
+func newCertReloader(cfg TLSConfig, reloaded func(error)) (*certReloader, error) {
+	r := &certReloader{cfg: cfg, reloaded: reloaded, done: make(chan struct{})}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// watch the directories: renewals often replace the files (or the
+	// symlinks to them) rather than write them in place
+	for _, dir := range r.dirs() {
+		if err := w.Add(dir); err != nil {
+			_ = w.Close()
+			return nil, err
+		}
+	}
+	r.watcher = w
+	go r.watch()
+	return r, nil
+}
+
+// serverConfig is the configuration of the listener.
+func (r *certReloader) serverConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.current.Load(), nil
+		},
+	}
+}
+
+func (r *certReloader) reload() error {
+	cfg, err := r.cfg.load()
+	if err != nil {
+		return err
+	}
+	r.current.Store(cfg)
+	return nil
+}
+
+func (r *certReloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	for i, f := range files {
+		files[i] = filepath.Clean(f)
+	}
+	return files
+}
+
+func (r *certReloader) dirs() []string {
+	var dirs []string
+	seen := map[string]bool{}
+	for _, f := range r.files() {
+		if d := filepath.Dir(f); !seen[d] {
+			seen[d] = true
+			dirs = append(dirs, d)
+		}
+	}
+	return dirs
+}
+
+// watch reloads once the files have been quiet for reloadDelay after a
+// change.
+func (r *certReloader) watch() {
+	files := map[string]bool{}
+	for _, f := range r.files() {
+		files[f] = true
+	}
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	for {
+		select {
+		case <-r.done:
+			timer.Stop()
+			return
+		case ev, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			// a replaced symlink changes the directory entry ..data, not
+			// the file names, so every change in the directories counts
+			if files[filepath.Clean(ev.Name)] || ev.Op&(fsnotify.Create|fsnotify.Rename) != 0 {
+				timer.Reset(reloadDelay)
+			}
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			if r.reloaded != nil {
+				r.reloaded(err)
+			}
+		case <-timer.C:
+			err := r.reload()
+			if r.reloaded != nil {
+				r.reloaded(err)
+			}
+		}
+	}
+}
+
+// close stops watching the files.
+func (r *certReloader) close() {
+	r.once.Do(func() {
+		close(r.done)
+		_ = r.watcher.Close()
+	})
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA issues the certificates of a test.
+type testCA struct {
+	t    *testing.T
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "furnace test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{t: t, cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key for name, for servers at
+// 127.0.0.1 or for clients.
+func (ca *testCA) issue(name string, serial int64, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	kder, _ := x509.MarshalECPrivateKey(key)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kder})
+}
+
+func writeFile(t *testing.T, path string, b []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTLSConfig_Validate(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	cert, key := ca.issue("furnace", 2, x509.ExtKeyUsageServerAuth)
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	writeFile(t, certFile, cert)
+	writeFile(t, keyFile, key)
+	writeFile(t, caFile, ca.pem)
+
+	for _, ok := range []TLSConfig{
+		{},
+		{CertFile: certFile, KeyFile: keyFile},
+		{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, ClientAuth: ClientAuthRequire},
+	} {
+		if err := ok.Validate(); err != nil {
+			t.Errorf("%+v: %v", ok, err)
+		}
+	}
+	for _, bad := range []TLSConfig{
+		{KeyFile: keyFile},
+		{CertFile: certFile},
+		{CertFile: certFile, KeyFile: caFile},
+		{CertFile: certFile, KeyFile: keyFile, ClientAuth: ClientAuthOptional},
+		{CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile, ClientAuth: ClientAuthRequire},
+		{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, ClientAuth: "always"},
+	} {
+		if err := bad.Validate(); !errors.Is(err, ErrInvalidTLSConfig) {
+			t.Errorf("%+v: expected ErrInvalidTLSConfig, got %v", bad, err)
+		}
+	}
+}
+
+func TestCertReloader_MutualTLSAndReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	cert, key := ca.issue("furnace-1", 2, x509.ExtKeyUsageServerAuth)
+	cfg := TLSConfig{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+		ClientAuth:   ClientAuthRequire,
+	}
+	writeFile(t, cfg.CertFile, cert)
+	writeFile(t, cfg.KeyFile, key)
+	writeFile(t, cfg.ClientCAFile, ca.pem)
+
+	reloaded := make(chan error, 4)
+	certs, err := newCertReloader(cfg, func(err error) { reloaded <- err })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer certs.close()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", certs.serverConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() { _ = http.Serve(ln, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})) }()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	// get answers the name of the server certificate, on a new connection
+	get := func(clientCert []tls.Certificate) (string, error) {
+		conn, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{RootCAs: roots, Certificates: clientCert})
+		if err != nil {
+			return "", err
+		}
+		defer conn.Close()
+		// TLS 1.3 reports a refused client certificate on the first read
+		if _, err := conn.Write([]byte("GET / HTTP/1.0\r\n\r\n")); err != nil {
+			return "", err
+		}
+		if _, err := conn.Read(make([]byte, 1)); err != nil {
+			return "", err
+		}
+		return conn.ConnectionState().PeerCertificates[0].Subject.CommonName, nil
+	}
+
+	if _, err := get(nil); err == nil {
+		t.Fatal("a client without a certificate was served")
+	}
+	clientPEM, clientKey := ca.issue("mes-gateway", 3, x509.ExtKeyUsageClientAuth)
+	client, err := tls.X509KeyPair(clientPEM, clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, err := get([]tls.Certificate{client}); err != nil || name != "furnace-1" {
+		t.Fatalf("mutual TLS: %q, %v", name, err)
+	}
+
+	// a renewal takes effect without a restart
+	cert, key = ca.issue("furnace-2", 4, x509.ExtKeyUsageServerAuth)
+	writeFile(t, cfg.CertFile, cert)
+	writeFile(t, cfg.KeyFile, key)
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("reload: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the renewed certificate was not loaded")
+	}
+	if name, err := get([]tls.Certificate{client}); err != nil || name != "furnace-2" {
+		t.Fatalf("after renewal: %q, %v", name, err)
+	}
+
+	// a broken renewal keeps the previous certificate
+	writeFile(t, cfg.KeyFile, []byte("not a key"))
+	if err := <-reloaded; !errors.Is(err, ErrInvalidTLSConfig) {
+		t.Fatalf("broken reload: %v", err)
+	}
+	if name, err := get([]tls.Certificate{client}); err != nil || name != "furnace-2" {
+		t.Fatalf("after broken renewal: %q, %v", name, err)
+	}
+}
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Limits for alert rules and history queries.
+const (
+	MaxAlertRuleName  = 100
+	MaxAlertForSecs   = 24 * 60 * 60
+	DefaultAlertLimit = 100
+	MaxAlertLimit     = 1000
+)
+
+var (
+	// ErrInvalidAlertRule is returned for rules that cannot be evaluated.
+	ErrInvalidAlertRule = errors.New("invalid alert rule")
+	// ErrAlertRuleNotFound is returned when no rule exists for an ID.
+	ErrAlertRuleNotFound = errors.New("alert rule not found")
+	// ErrInvalidAlertConfig is returned for notification settings that
+	// cannot be followed.
+	ErrInvalidAlertConfig = errors.New("invalid alerts config")
+)
+
+// DefaultCriticalAlerts are the rule kinds whose alerts skip quiet hours
+// and digests when AlertConfig.Critical is empty.
+var DefaultCriticalAlerts = []string{models.AlertTempAbove, models.AlertErrorEvent}
+
+// AlertConfig configures outbound alert notifications.
+type AlertConfig struct {
+	NotifyURL     string        `mapstructure:"notify_url"`     // alerts are POSTed here as JSON, one by one
+	NotifyTimeout time.Duration `mapstructure:"notify_timeout"` // per notification; DefaultNotifyTimeout when 0
+	// Channels are further destinations, each with its own quiet hours and
+	// digest. Without NotifyURL and channels alerts are only recorded.
+	Channels []NotifyChannel `mapstructure:"channels"`
+	Critical []string        `mapstructure:"critical"` // rule kinds that always notify at once; DefaultCriticalAlerts when empty
+}
+
+// Validate rejects channels without a unique name, an absolute http(s) URL
+// or readable quiet hours, channels named DefaultChannel, and unknown
+// critical kinds.
+func (c AlertConfig) Validate() error {
+	if c.NotifyTimeout < 0 {
+		return fmt.Errorf("%w: notify_timeout must be >= 0", ErrInvalidAlertConfig)
+	}
+	if c.NotifyURL != "" && !isHTTPURL(c.NotifyURL) {
+		return fmt.Errorf("%w: notify_url must be an absolute http(s) URL", ErrInvalidAlertConfig)
+	}
+	seen := make(map[string]bool, len(c.Channels))
+	for i, ch := range c.Channels {
+		name := strings.TrimSpace(ch.Name)
+		if name == "" || seen[name] {
+			return fmt.Errorf("%w: channel %d needs a unique name", ErrInvalidAlertConfig, i+1)
+		}
+		seen[name] = true
+		if name == DefaultChannel {
+			return fmt.Errorf("%w: channel name %q is taken by notify_url", ErrInvalidAlertConfig, name)
+		}
+		if !isHTTPURL(ch.NotifyURL) {
+			return fmt.Errorf("%w: channel %q needs an absolute http(s) notify_url", ErrInvalidAlertConfig, name)
+		}
+		if _, err := parseQuietHours(ch.QuietHours); err != nil {
+			return fmt.Errorf("%w: channel %q: %v", ErrInvalidAlertConfig, name, err)
+		}
+		if ch.Digest < 0 {
+			return fmt.Errorf("%w: channel %q: digest must be >= 0", ErrInvalidAlertConfig, name)
+		}
+	}
+	for _, kind := range c.Critical {
+		switch kind {
+		case models.AlertTempAbove, models.AlertRemainingBelow, models.AlertErrorEvent:
+		default:
+			return fmt.Errorf("%w: critical: unknown rule kind %q", ErrInvalidAlertConfig, kind)
+		}
+	}
+	return nil
+}
+
+// AlertFilter selects alerts from the history.
+type AlertFilter struct {
+	RuleID int       // 0 for all rules
+	From   time.Time // inclusive; zero means no lower bound
+	To     time.Time // inclusive; zero means no upper bound
+	Limit  int       // 0 means DefaultAlertLimit; capped at MaxAlertLimit
+}
+
+// ruleCondition tracks how long a rule's condition has held.
+type ruleCondition struct {
+	since time.Time // first state the condition held in; zero while it does not
+	fired bool      // fired since the condition last started holding
+}
+
+// AlertService manages alert rules and evaluates them against every state
+// the simulator publishes.
+type AlertService struct {
+	repo     repository.AlertRepo
+	events   repository.EventRepo
+	bus      *StateBroker
+	notifier Notifier // optional; alerts are only recorded when nil
+	newID    func() string
+
+	dirty atomic.Bool // rules changed; the evaluator reloads them
+
+	// evaluator state, owned by Run
+	rules  []models.AlertRule
+	loaded bool
+	conds  map[int]*ruleCondition
+	codes  []string // error codes of the previous state
+	primed bool     // codes holds a state; error codes present at startup do not fire
+}
+
+func NewAlertService(repo repository.AlertRepo, events repository.EventRepo, bus *StateBroker) *AlertService {
+	return &AlertService{repo: repo, events: events, bus: bus, newID: uuid.NewString, conds: make(map[int]*ruleCondition)}
+}
+
+// normalizeRule trims the name and checks that rule can be evaluated.
+func normalizeRule(rule models.AlertRule) (models.AlertRule, error) {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.Kind = strings.ToLower(strings.TrimSpace(rule.Kind))
+	switch {
+	case rule.Name == "" || len(rule.Name) > MaxAlertRuleName:
+		return rule, fmt.Errorf("%w: name must be 1..%d characters", ErrInvalidAlertRule, MaxAlertRuleName)
+	case rule.ForSeconds < 0 || rule.ForSeconds > MaxAlertForSecs:
+		return rule, fmt.Errorf("%w: for_seconds must be within 0..%d", ErrInvalidAlertRule, MaxAlertForSecs)
+	case math.IsNaN(rule.Threshold) || math.IsInf(rule.Threshold, 0):
+		return rule, fmt.Errorf("%w: threshold must be a number", ErrInvalidAlertRule)
+	}
+	switch rule.Kind {
+	case models.AlertTempAbove:
+	case models.AlertRemainingBelow:
+		if rule.Threshold <= 0 {
+			return rule, fmt.Errorf("%w: remaining_below needs a positive threshold in seconds", ErrInvalidAlertRule)
+		}
+	case models.AlertErrorEvent:
+		rule.Threshold, rule.ForSeconds = 0, 0
+	default:
+		return rule, fmt.Errorf("%w: kind must be one of %s, %s, %s", ErrInvalidAlertRule,
+			models.AlertTempAbove, models.AlertRemainingBelow, models.AlertErrorEvent)
+	}
+	return rule, nil
+}
+
+func (s *AlertService) ListRules(ctx context.Context) ([]models.AlertRule, error) {
+	return s.repo.ListRules(ctx)
+}
+
+func (s *AlertService) GetRule(ctx context.Context, id int) (models.AlertRule, error) {
+	rule, err := s.repo.GetRule(ctx, id)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	if rule.ID == 0 {
+		return models.AlertRule{}, ErrAlertRuleNotFound
+	}
+	return rule, nil
+}
+
+// CreateRule validates and stores rule on behalf of userID.
+func (s *AlertService) CreateRule(ctx context.Context, rule models.AlertRule, userID int) (models.AlertRule, error) {
+	rule, err := normalizeRule(rule)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	rule.CreatedBy = userID
+	id, err := s.repo.CreateRule(ctx, rule)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	s.dirty.Store(true)
+	return s.GetRule(ctx, id)
+}
+
+// UpdateRule replaces the rule with rule.ID.
+func (s *AlertService) UpdateRule(ctx context.Context, rule models.AlertRule) (models.AlertRule, error) {
+	rule, err := normalizeRule(rule)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	found, err := s.repo.UpdateRule(ctx, rule)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	if !found {
+		return models.AlertRule{}, ErrAlertRuleNotFound
+	}
+	s.dirty.Store(true)
+	return s.GetRule(ctx, rule.ID)
+}
+
+func (s *AlertService) DeleteRule(ctx context.Context, id int) error {
+	found, err := s.repo.DeleteRule(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrAlertRuleNotFound
+	}
+	s.dirty.Store(true)
+	return nil
+}
+
+// ListAlerts returns fired alerts, newest first.
+func (s *AlertService) ListAlerts(ctx context.Context, f AlertFilter) (_ []models.Alert, err error) {
+	ctx, span := startSpan(ctx, "Alerts.List", rangeAttrs(f.From, f.To)...)
+	defer func() { endSpan(span, err) }()
+
+	from, to := normalizeToUTC(f.From), normalizeToUTC(f.To)
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return nil, errInvalidTimeRange
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultAlertLimit
+	}
+	if limit > MaxAlertLimit {
+		limit = MaxAlertLimit
+	}
+	return s.repo.ListAlerts(ctx, repository.AlertQuery{RuleID: f.RuleID, From: from, To: to, Limit: limit})
+}
+
+// Run evaluates the rules against each published state, and sends the
+// notifier's digests as they fall due, until ctx is canceled. It then sends
+// the digests still held, which would not survive the process.
+func (s *AlertService) Run(ctx context.Context) {
+	if s.repo == nil || s.bus == nil {
+		return
+	}
+	updates, cancel := s.bus.Subscribe(16)
+	defer cancel()
+	var digests <-chan time.Time
+	flusher, ok := s.notifier.(interface{ Flush(context.Context) })
+	if ok {
+		t := time.NewTicker(DigestCheckInterval)
+		defer t.Stop()
+		digests = t.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			if drainer, ok := s.notifier.(interface{ Drain(context.Context) }); ok {
+				drainer.Drain(context.WithoutCancel(ctx))
+			}
+			return
+		case st := <-updates:
+			s.evaluate(ctx, st)
+		case <-digests:
+			flusher.Flush(ctx)
+		}
+	}
+}
+
+// evaluate fires every enabled rule whose condition st completes. Durations
+// are measured on state timestamps, so they follow the simulated clock.
+func (s *AlertService) evaluate(ctx context.Context, st models.FurnaceState) {
+	if s.dirty.Swap(false) || !s.loaded {
+		rules, err := s.repo.ListRules(ctx)
+		if err != nil {
+			s.dirty.Store(true) // retry on the next state
+			return
+		}
+		s.rules, s.loaded = rules, true
+	}
+
+	var newCodes []string
+	if s.primed {
+		for _, code := range st.ErrorCodes {
+			if !hasString(s.codes, code) {
+				newCodes = append(newCodes, code)
+			}
+		}
+	}
+	s.codes, s.primed = append(s.codes[:0], st.ErrorCodes...), true
+
+	live := make(map[int]*ruleCondition, len(s.rules))
+	for _, rule := range s.rules {
+		cond := s.conds[rule.ID]
+		if cond == nil {
+			cond = &ruleCondition{}
+		}
+		live[rule.ID] = cond
+		if !rule.Enabled {
+			*cond = ruleCondition{}
+			continue
+		}
+		switch rule.Kind {
+		case models.AlertTempAbove:
+			v := st.MeasuredTempC
+			if s.holds(cond, rule, v > rule.Threshold, st.UpdatedAt) {
+				s.fire(ctx, rule, st, v, fmt.Sprintf("temperature %.1f °C above %.1f °C", v, rule.Threshold))
+			}
+		case models.AlertRemainingBelow:
+			v := float64(st.RemainingSeconds)
+			active := st.IsRunning && st.RemainingSeconds > 0 && v < rule.Threshold
+			if s.holds(cond, rule, active, st.UpdatedAt) {
+				s.fire(ctx, rule, st, v, fmt.Sprintf("%d s remaining, below %.0f s", st.RemainingSeconds, rule.Threshold))
+			}
+		case models.AlertErrorEvent:
+			for _, code := range newCodes {
+				s.fire(ctx, rule, st, 0, "error "+code)
+			}
+		}
+	}
+	s.conds = live
+}
+
+// holds updates cond for a state at t and reports whether the rule should
+// fire now: the condition has held for ForSeconds and has not fired since
+// it started holding.
+func (s *AlertService) holds(cond *ruleCondition, rule models.AlertRule, active bool, t time.Time) bool {
+	if !active {
+		*cond = ruleCondition{}
+		return false
+	}
+	if cond.since.IsZero() {
+		cond.since = t
+	}
+	if cond.fired || t.Sub(cond.since) < time.Duration(rule.ForSeconds)*time.Second {
+		return false
+	}
+	cond.fired = true
+	return true
+}
+
+// fire notifies, records the alert and logs an ALERT event.
+func (s *AlertService) fire(ctx context.Context, rule models.AlertRule, st models.FurnaceState, value float64, msg string) {
+	at := st.UpdatedAt.UTC()
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+	a := models.Alert{
+		RuleID:   rule.ID,
+		RuleName: rule.Name,
+		Kind:     rule.Kind,
+		FiredAt:  at,
+		Value:    value,
+		Message:  msg,
+		RunID:    st.RunID,
+	}
+	if s.notifier != nil {
+		if err := s.notifier.Notify(ctx, a); err != nil {
+			a.NotifyError = err.Error()
+		} else {
+			a.Notified = true
+		}
+	}
+	_, _ = s.repo.AppendAlert(ctx, a)
+	if s.events != nil {
+		_ = s.events.Append(ctx, models.FurnaceEvent{
+			EventID:     s.newID(),
+			OccurredAt:  at,
+			Type:        "ALERT",
+			Description: fmt.Sprintf("Alert %q: %s", rule.Name, msg),
+			Metadata: withRunID(map[string]any{
+				"rule_id":   rule.ID,
+				"kind":      rule.Kind,
+				"value":     value,
+				"threshold": rule.Threshold,
+				"notified":  a.Notified,
+			}, st.RunID),
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+type alertRepoStub struct {
+	rules   []models.AlertRule
+	fired   []models.Alert
+	listErr error
+	lists   int
+}
+
+func (r *alertRepoStub) CreateRule(ctx context.Context, rule models.AlertRule) (int, error) {
+	rule.ID = len(r.rules) + 1
+	r.rules = append(r.rules, rule)
+	return rule.ID, nil
+}
+func (r *alertRepoStub) UpdateRule(ctx context.Context, rule models.AlertRule) (bool, error) {
+	for i := range r.rules {
+		if r.rules[i].ID == rule.ID {
+			r.rules[i] = rule
+			return true, nil
+		}
+	}
+	return false, nil
+}
+func (r *alertRepoStub) DeleteRule(ctx context.Context, id int) (bool, error) { return false, nil }
+func (r *alertRepoStub) GetRule(ctx context.Context, id int) (models.AlertRule, error) {
+	for _, rule := range r.rules {
+		if rule.ID == id {
+			return rule, nil
+		}
+	}
+	return models.AlertRule{}, nil
+}
+func (r *alertRepoStub) ListRules(ctx context.Context) ([]models.AlertRule, error) {
+	r.lists++
+	return append([]models.AlertRule(nil), r.rules...), r.listErr
+}
+func (r *alertRepoStub) AppendAlert(ctx context.Context, a models.Alert) (int64, error) {
+	r.fired = append(r.fired, a)
+	return int64(len(r.fired)), nil
+}
+func (r *alertRepoStub) ListAlerts(ctx context.Context, q repository.AlertQuery) ([]models.Alert, error) {
+	return r.fired, nil
+}
+
+type notifierStub struct {
+	err  error
+	sent []models.Alert
+}
+
+func (n *notifierStub) Notify(ctx context.Context, a models.Alert) error {
+	n.sent = append(n.sent, a)
+	return n.err
+}
+
+func TestNormalizeRule(t *testing.T) {
+	rule, err := normalizeRule(models.AlertRule{Name: " Errors ", Kind: "ERROR_EVENT", Threshold: 5, ForSeconds: 10})
+	if err != nil || rule.Name != "Errors" || rule.Kind != models.AlertErrorEvent || rule.Threshold != 0 || rule.ForSeconds != 0 {
+		t.Fatalf("normalizeRule() = %+v, %v", rule, err)
+	}
+	for _, bad := range []models.AlertRule{
+		{Name: "", Kind: models.AlertTempAbove},
+		{Name: "x", Kind: "humidity_above"},
+		{Name: "x", Kind: models.AlertTempAbove, ForSeconds: -1},
+		{Name: "x", Kind: models.AlertTempAbove, Threshold: math.NaN()},
+		{Name: "x", Kind: models.AlertRemainingBelow},
+	} {
+		if _, err := normalizeRule(bad); !errors.Is(err, ErrInvalidAlertRule) {
+			t.Fatalf("%+v: expected ErrInvalidAlertRule, got %v", bad, err)
+		}
+	}
+}
+
+func TestAlertService_RuleNotFound(t *testing.T) {
+	svc := NewAlertService(&alertRepoStub{}, nil, nil)
+	if _, err := svc.GetRule(context.Background(), 1); !errors.Is(err, ErrAlertRuleNotFound) {
+		t.Fatalf("GetRule: expected ErrAlertRuleNotFound, got %v", err)
+	}
+	if _, err := svc.UpdateRule(context.Background(), models.AlertRule{ID: 1, Name: "x", Kind: models.AlertTempAbove}); !errors.Is(err, ErrAlertRuleNotFound) {
+		t.Fatalf("UpdateRule: expected ErrAlertRuleNotFound, got %v", err)
+	}
+	if err := svc.DeleteRule(context.Background(), 1); !errors.Is(err, ErrAlertRuleNotFound) {
+		t.Fatalf("DeleteRule: expected ErrAlertRuleNotFound, got %v", err)
+	}
+}
+
+func TestAlertService_TempAboveWaitsForSeconds(t *testing.T) {
+	repo := &alertRepoStub{}
+	events := &simEventRepoStub{}
+	notifier := &notifierStub{err: errors.New("status 502")}
+	svc := NewAlertService(repo, events, nil)
+	svc.notifier = notifier
+	ctx := context.Background()
+	if _, err := svc.CreateRule(ctx, models.AlertRule{Name: "Too hot", Kind: models.AlertTempAbove, Threshold: 700, ForSeconds: 30, Enabled: true}, 1); err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+
+	now := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	st := heatingState(now)
+	for i, temp := range []float64{710, 720, 730, 740, 750, 690, 710} {
+		st.MeasuredTempC, st.UpdatedAt = temp, now.Add(time.Duration(i)*15*time.Second)
+		svc.evaluate(ctx, st)
+	}
+	// 710 at 0s, fires at 30s; drops below at 75s and rearms at 90s
+	if len(repo.fired) != 1 || repo.fired[0].Value != 730 || !repo.fired[0].FiredAt.Equal(now.Add(30*time.Second)) {
+		t.Fatalf("expected one alert at 30s, got %+v", repo.fired)
+	}
+	if a := repo.fired[0]; a.Notified || a.NotifyError != "status 502" || len(notifier.sent) != 1 {
+		t.Fatalf("expected the failed notification to be recorded, got %+v", a)
+	}
+	if len(events.appends) != 1 || events.appends[0].Type != "ALERT" {
+		t.Fatalf("expected an ALERT event, got %+v", events.appends)
+	}
+	if meta, _ := events.appends[0].Metadata.(map[string]any); meta["run_id"] != "run-1" || meta["rule_id"] != 1 {
+		t.Fatalf("expected an ALERT event, got %+v", events.appends)
+	}
+
+	st.UpdatedAt = st.UpdatedAt.Add(30 * time.Second)
+	svc.evaluate(ctx, st)
+	if len(repo.fired) != 2 {
+		t.Fatalf("expected the rule to fire again after rearming, got %d alerts", len(repo.fired))
+	}
+}
+
+func TestAlertService_ErrorEventIgnoresCodesPresentAtStart(t *testing.T) {
+	repo := &alertRepoStub{rules: []models.AlertRule{
+		{ID: 1, Name: "Errors", Kind: models.AlertErrorEvent, Enabled: true},
+		{ID: 2, Name: "Off", Kind: models.AlertErrorEvent},
+	}}
+	svc := NewAlertService(repo, nil, nil)
+	ctx := context.Background()
+
+	now := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	st := heatingState(now)
+	st.ErrorCodes = []string{"SENSOR_FAULT"}
+	svc.evaluate(ctx, st)
+	st.ErrorCodes = []string{"SENSOR_FAULT", "OVERHEAT"}
+	svc.evaluate(ctx, st)
+	svc.evaluate(ctx, st)
+
+	if len(repo.fired) != 1 || repo.fired[0].RuleID != 1 || repo.fired[0].Message != "error OVERHEAT" {
+		t.Fatalf("expected one alert for OVERHEAT, got %+v", repo.fired)
+	}
+}
+
+func TestAlertService_ReloadsRulesAfterChanges(t *testing.T) {
+	repo := &alertRepoStub{}
+	svc := NewAlertService(repo, nil, nil)
+	ctx := context.Background()
+	st := heatingState(time.Now())
+
+	svc.evaluate(ctx, st)
+	svc.evaluate(ctx, st)
+	if repo.lists != 1 {
+		t.Fatalf("expected rules to be cached, listed %d times", repo.lists)
+	}
+	if _, err := svc.CreateRule(ctx, models.AlertRule{Name: "Ending", Kind: models.AlertRemainingBelow, Threshold: 900, Enabled: true}, 1); err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+	svc.evaluate(ctx, st)
+	if repo.lists != 2 || len(repo.fired) != 1 || repo.fired[0].Value != 600 {
+		t.Fatalf("expected the new rule to fire, lists=%d alerts=%+v", repo.lists, repo.fired)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// AmbientConfig models the room around the furnace and the cold-junction
+// sensor in the controller cabinet. The room follows a daily profile around
+// PhysicsConfig.AmbientC; the sensor reading warms with the chamber, lagging
+// behind it.
+type AmbientConfig struct {
+	WarmingC      float64 // cold-junction rise above ambient with the chamber at the max safe temperature, °C
+	TimeConstantS float64 // first-order lag of the cabinet, seconds; 0 follows the chamber instantly
+	NoiseStdDevC  float64 // Gaussian noise per reading, °C
+
+	DailySwingC float64 // amplitude of the daily room temperature sine, °C; 0 keeps the room constant
+	PeakHour    float64 // UTC hour at which the room is warmest
+}
+
+// ambientModel turns the chamber temperature into a cold-junction reading.
+type ambientModel struct {
+	cfg AmbientConfig
+	rng *rand.Rand
+}
+
+func newAmbientModel(cfg AmbientConfig, seed int64) *ambientModel {
+	return &ambientModel{cfg: cfg, rng: rand.New(rand.NewSource(seed))}
+}
+
+// read moves the cabinet temperature prevC toward its equilibrium for the
+// current chamber temperature over elapsed seconds and returns a reading.
+func (m *ambientModel) read(phys PhysicsConfig, prevC, chamberC, elapsed float64) float64 {
+	if prevC == 0 {
+		prevC = phys.AmbientC // rows written before the channel existed
+	}
+	heat := math.Max(chamberC-phys.AmbientC, 0) / (phys.MaxSafeC - phys.AmbientC)
+	target := phys.AmbientC + m.cfg.WarmingC*heat
+	v := target
+	if tau := m.cfg.TimeConstantS; tau > 0 {
+		v = prevC + (target-prevC)*(1-math.Exp(-elapsed/tau))
+	}
+	if m.cfg.NoiseStdDevC > 0 {
+		v += m.rng.NormFloat64() * m.cfg.NoiseStdDevC
+	}
+	return math.Round(v*100) / 100
+}
+
+// measureAmbient updates the cold-junction reading. Returns true if it changed.
+func (s *SimulatorService) measureAmbient(st *models.FurnaceState, elapsed float64) bool {
+	prev := st.AmbientTempC
+	phys := s.cfg.Physics
+	phys.AmbientC = s.room
+	st.AmbientTempC = s.ambient.read(phys, prev, st.CurrentTempC, elapsed)
+	return st.AmbientTempC != prev
+}
+
+// recordTelemetry stores this tick's channel readings.
+func (s *SimulatorService) recordTelemetry(ctx context.Context, st models.FurnaceState, now time.Time) {
+	if s.telemetryRepo == nil {
+		return
+	}
+	at := now.UTC()
+	samples := []models.TelemetrySample{
+		{At: at, Channel: models.ChannelChamber, Value: st.MeasuredTempC},
+		{At: at, Channel: models.ChannelAmbient, Value: st.AmbientTempC},
+	}
+	if s.cfg.Atmosphere.enabled() {
+		samples = append(samples,
+			models.TelemetrySample{At: at, Channel: models.ChannelO2, Value: st.O2PPM},
+			models.TelemetrySample{At: at, Channel: models.ChannelGasFlow, Value: st.GasFlowM3h},
+		)
+	}
+	_ = s.telemetryRepo.Append(ctx, samples...)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+type telemetryRepoStub struct {
+	appended []models.TelemetrySample
+	lastQ    repository.TelemetryQuery
+}
+
+func (r *telemetryRepoStub) Append(ctx context.Context, samples ...models.TelemetrySample) error {
+	r.appended = append(r.appended, samples...)
+	return nil
+}
+
+func (r *telemetryRepoStub) Query(ctx context.Context, q repository.TelemetryQuery) ([]models.TelemetrySample, error) {
+	r.lastQ = q
+	return nil, nil
+}
+
+func TestAmbientModel_LagsBehindChamber(t *testing.T) {
+	m := newAmbientModel(AmbientConfig{WarmingC: 10, TimeConstantS: 900}, 1)
+	phys := PhysicsConfig{}.withDefaults()
+
+	first := m.read(phys, AmbientC, MaxSafeC, 60)
+	if first <= AmbientC || first >= AmbientC+10 {
+		t.Fatalf("expected a partial rise after 60s, got %.2f", first)
+	}
+	settled := m.read(phys, first, MaxSafeC, 100*900)
+	if settled != AmbientC+10 {
+		t.Fatalf("expected equilibrium at %.2f, got %.2f", AmbientC+10, settled)
+	}
+	if cooled := m.read(phys, settled, AmbientC, 100*900); cooled != AmbientC {
+		t.Fatalf("expected return to ambient, got %.2f", cooled)
+	}
+}
+
+func TestTick_RecordsBothChannels(t *testing.T) {
+	now := time.Now()
+	states := &simStateRepoStub{loadResp: heatingState(now)}
+	telemetry := &telemetryRepoStub{}
+	svc := NewSimulatorServiceWithConfig(states, &simEventRepoStub{}, nil, telemetry, nil, DefaultSimConfig())
+
+	svc.tick(context.Background(), now)
+
+	st := states.saves[0]
+	if st.AmbientTempC <= AmbientC {
+		t.Fatalf("expected the cold junction to warm with a hot chamber, got %.2f", st.AmbientTempC)
+	}
+	if len(telemetry.appended) != 4 {
+		t.Fatalf("expected four samples, got %+v", telemetry.appended)
+	}
+	chamber, ambient := telemetry.appended[0], telemetry.appended[1]
+	if chamber.Channel != models.ChannelChamber || chamber.Value != st.MeasuredTempC {
+		t.Fatalf("unexpected chamber sample: %+v", chamber)
+	}
+	if ambient.Channel != models.ChannelAmbient || ambient.Value != st.AmbientTempC {
+		t.Fatalf("unexpected ambient sample: %+v", ambient)
+	}
+	o2, flow := telemetry.appended[2], telemetry.appended[3]
+	if o2.Channel != models.ChannelO2 || o2.Value != st.O2PPM || flow.Channel != models.ChannelGasFlow {
+		t.Fatalf("unexpected atmosphere samples: %+v, %+v", o2, flow)
+	}
+}
+
+func TestTelemetryService_Samples(t *testing.T) {
+	repo := &telemetryRepoStub{}
+	svc := NewTelemetryService(repo)
+	ctx := context.Background()
+
+	if _, err := svc.Samples(ctx, TelemetryFilter{Channel: "pressure"}); !errors.Is(err, ErrUnknownChannel) {
+		t.Fatalf("expected ErrUnknownChannel, got %v", err)
+	}
+	now := time.Now()
+	if _, err := svc.Samples(ctx, TelemetryFilter{From: now, To: now.Add(-time.Hour)}); !errors.Is(err, errInvalidTimeRange) {
+		t.Fatalf("expected errInvalidTimeRange, got %v", err)
+	}
+
+	if _, err := svc.Samples(ctx, TelemetryFilter{Channel: " Ambient "}); err != nil {
+		t.Fatalf("Samples: %v", err)
+	}
+	if repo.lastQ.Channel != models.ChannelAmbient || repo.lastQ.Limit != DefaultTelemetryLimit {
+		t.Fatalf("unexpected query: %+v", repo.lastQ)
+	}
+	_, _ = svc.Samples(ctx, TelemetryFilter{Limit: 1 << 20})
+	if repo.lastQ.Limit != MaxTelemetryLimit {
+		t.Fatalf("expected limit capped at %d, got %d", MaxTelemetryLimit, repo.lastQ.Limit)
+	}
+}
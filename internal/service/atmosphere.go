@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// Protective gases the furnace can be purged with.
+const (
+	GasNitrogen = "N2"
+	GasArgon    = "AR"
+)
+
+// AirO2PPM is the oxygen content of air; the chamber holds air until it is
+// purged.
+const AirO2PPM = 209500.0
+
+// MaxGasFlowM3h is the highest purge flow the gas panel accepts, m³/h.
+const MaxGasFlowM3h = 100.0
+
+// AlarmO2High is reported in ErrorCodes while the chamber is hot, a
+// protective gas is requested and residual oxygen is above the limit.
+const AlarmO2High = "O2_HIGH"
+
+// ErrInvalidAtmosphere reports atmosphere settings the gas panel cannot run.
+var ErrInvalidAtmosphere = errors.New("invalid atmosphere")
+
+// AtmosphereConfig models the protective gas atmosphere. The purge gas
+// displaces the chamber volume while air leaks back in; residual oxygen
+// relaxes toward the balance of the two. The zero value disables it.
+type AtmosphereConfig struct {
+	ChamberVolumeM3   float64 // free gas volume of the chamber, m³; 0 disables the model
+	FlowTimeConstantS float64 // first-order lag of the flow behind its setpoint, seconds
+	GasO2PPM          float64 // oxygen impurity of the supplied gas, ppm
+	LeakPerHour       float64 // air infiltration while purged, chamber volumes per hour
+	IdleAirPerHour    float64 // air exchange with no gas flowing, chamber volumes per hour
+	DefaultMaxO2PPM   float64 // O2_HIGH limit when the mode sets none, ppm
+	AlarmAboveC       float64 // O2_HIGH is only raised above this chamber temperature, °C
+	DoorAirShare      float64 // fraction of the chamber replaced by air when a charge goes in or out
+}
+
+func (c AtmosphereConfig) enabled() bool { return c.ChamberVolumeM3 > 0 }
+
+// AtmosphereParams sets the protective gas. An empty Gas with zero flow
+// turns the purge off; zero MaxO2PPM uses AtmosphereConfig.DefaultMaxO2PPM.
+type AtmosphereParams struct {
+	Gas      string  // N2 | AR
+	FlowM3h  float64 // purge flow setpoint, m³/h
+	MaxO2PPM float64 // O2_HIGH limit, ppm
+}
+
+// normalize validates p and upper-cases the gas.
+func (p *AtmosphereParams) normalize() error {
+	p.Gas = strings.ToUpper(strings.TrimSpace(p.Gas))
+	switch {
+	case p.Gas != "" && p.Gas != GasNitrogen && p.Gas != GasArgon:
+		return fmt.Errorf("%w: gas must be %s or %s", ErrInvalidAtmosphere, GasNitrogen, GasArgon)
+	case p.FlowM3h < 0 || p.FlowM3h > MaxGasFlowM3h:
+		return fmt.Errorf("%w: flow must be within 0..%.0f m³/h", ErrInvalidAtmosphere, MaxGasFlowM3h)
+	case (p.Gas == "") != (p.FlowM3h == 0):
+		return fmt.Errorf("%w: a gas needs a flow and a flow needs a gas", ErrInvalidAtmosphere)
+	case p.MaxO2PPM < 0 || p.MaxO2PPM > AirO2PPM:
+		return fmt.Errorf("%w: max O2 must be within 0..%.0f ppm", ErrInvalidAtmosphere, AirO2PPM)
+	}
+	return nil
+}
+
+// apply stores p as the gas setpoint of st.
+func (p AtmosphereParams) apply(st *models.FurnaceState) {
+	st.Gas = p.Gas
+	st.GasSetpointM3h = p.FlowM3h
+	st.MaxO2PPM = p.MaxO2PPM
+}
+
+// metadata describes p for MODE_CHANGE and ATMOSPHERE_CHANGE events.
+func (p AtmosphereParams) metadata() map[string]any {
+	return map[string]any{
+		"gas":              p.Gas,
+		"gas_setpoint_m3h": p.FlowM3h,
+		"max_o2_ppm":       p.MaxO2PPM,
+	}
+}
+
+// SetAtmosphere changes the protective gas without touching mode or run.
+// The furnace may be stopped: purging usually starts before heating.
+func (s *FurnaceService) SetAtmosphere(ctx context.Context, p AtmosphereParams) (err error) {
+	ctx, span := startSpan(ctx, "Furnace.SetAtmosphere")
+	defer func() { endSpan(span, err) }()
+
+	if err := p.normalize(); err != nil {
+		return err
+	}
+	now := s.now().UTC()
+	st, err := s.state.Update(ctx, func(st *models.FurnaceState) error {
+		if st.ID == 0 {
+			return errors.New("cannot change atmosphere: furnace has never been started")
+		}
+		p.apply(st)
+		st.UpdatedAt = now
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	desc := "Purge gas off"
+	if p.Gas != "" {
+		desc = fmt.Sprintf("Purging with %s at %.1f m³/h", p.Gas, p.FlowM3h)
+	}
+	return s.eventRepo.Append(ctx, models.FurnaceEvent{
+		EventID:     s.newID(),
+		OccurredAt:  now,
+		Type:        "ATMOSPHERE_CHANGE",
+		Description: desc,
+		Metadata:    withRunID(p.metadata(), st.RunID),
+	})
+}
+
+// advanceAtmosphere moves the gas flow toward its setpoint and residual
+// oxygen toward the balance between purge and leakage over elapsed seconds.
+// The flow stops while the furnace is stopped or without power. Returns
+// true if st changed.
+func (s *SimulatorService) advanceAtmosphere(st *models.FurnaceState, elapsed float64) bool {
+	cfg := s.cfg.Atmosphere
+	if !cfg.enabled() || elapsed <= 0 {
+		return false
+	}
+	prevFlow, prevO2 := st.GasFlowM3h, st.O2PPM
+	if st.O2PPM == 0 && st.Gas == "" {
+		st.O2PPM = AirO2PPM // rows written before the channel existed
+	}
+
+	target := st.GasSetpointM3h
+	if !st.IsRunning || s.faults.has(FaultPowerLoss) {
+		target = 0
+	}
+	flow := target
+	if tau := cfg.FlowTimeConstantS; tau > 0 {
+		flow = st.GasFlowM3h + (target-st.GasFlowM3h)*(1-math.Exp(-elapsed/tau))
+	}
+	if math.Abs(flow-target) < 0.01 {
+		flow = target
+	}
+	st.GasFlowM3h = math.Round(flow*100) / 100
+
+	// exchange rates in chamber volumes per hour
+	purge := st.GasFlowM3h / cfg.ChamberVolumeM3
+	leak := cfg.LeakPerHour
+	if st.GasFlowM3h == 0 {
+		leak = cfg.IdleAirPerHour
+	}
+	if k := purge + leak; k > 0 {
+		eq := (purge*cfg.GasO2PPM + leak*AirO2PPM) / k
+		st.O2PPM = eq + (st.O2PPM-eq)*math.Exp(-k*elapsed/3600)
+	}
+	st.O2PPM = math.Round(st.O2PPM*100) / 100
+	return st.GasFlowM3h != prevFlow || st.O2PPM != prevO2
+}
+
+// admitAir replaces DoorAirShare of the chamber atmosphere with air, as
+// when the door opens for a charge.
+func (s *SimulatorService) admitAir(st *models.FurnaceState) {
+	cfg := s.cfg.Atmosphere
+	if !cfg.enabled() || cfg.DoorAirShare <= 0 {
+		return
+	}
+	st.O2PPM = math.Round((st.O2PPM+(AirO2PPM-st.O2PPM)*cfg.DoorAirShare)*100) / 100
+}
+
+// checkO2 raises O2_HIGH and logs an ERROR event when residual oxygen
+// exceeds the limit while a protective gas is requested and the chamber is
+// above AlarmAboveC, and clears it once any of these no longer holds.
+// Returns true if the state changed.
+func (s *SimulatorService) checkO2(ctx context.Context, st *models.FurnaceState, now time.Time) bool {
+	cfg := s.cfg.Atmosphere
+	limit := st.MaxO2PPM
+	if limit == 0 {
+		limit = cfg.DefaultMaxO2PPM
+	}
+	high := cfg.enabled() && limit > 0 && st.Gas != "" &&
+		st.CurrentTempC > cfg.AlarmAboveC && st.O2PPM > limit
+	active := hasString(st.ErrorCodes, AlarmO2High)
+
+	switch {
+	case high && !active:
+		st.ErrorCodes = append(st.ErrorCodes, AlarmO2High)
+		s.emit(models.FurnaceEvent{
+			EventID:     s.newID(),
+			OccurredAt:  now.UTC(),
+			Type:        "ERROR",
+			Description: "Oxygen above limit in protective atmosphere",
+			Metadata: withRunID(map[string]any{
+				"alarm":        AlarmO2High,
+				"o2_ppm":       st.O2PPM,
+				"max_o2_ppm":   limit,
+				"gas":          st.Gas,
+				"gas_flow_m3h": st.GasFlowM3h,
+				"temp_c":       st.CurrentTempC,
+			}, st.RunID),
+		})
+		return true
+	case !high && active:
+		st.ErrorCodes = removeString(st.ErrorCodes, AlarmO2High)
+		s.emit(models.FurnaceEvent{
+			EventID:     s.newID(),
+			OccurredAt:  now.UTC(),
+			Type:        "ALARM_CLEARED",
+			Description: "Oxygen back within limit",
+			Metadata: withRunID(map[string]any{
+				"alarm":  AlarmO2High,
+				"o2_ppm": st.O2PPM,
+			}, st.RunID),
+		})
+		return true
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestSetAtmosphere_ValidatesAndLogs(t *testing.T) {
+	srepo := &fakeStateRepo{loadResp: models.FurnaceState{ID: 1, Mode: ModeStandby}}
+	erepo := &localEventRepo{}
+	fs := &FurnaceService{state: NewStateManager(srepo), eventRepo: erepo}
+
+	for _, p := range []AtmosphereParams{
+		{Gas: "CO2", FlowM3h: 10},
+		{Gas: "N2"},
+		{FlowM3h: 10},
+		{Gas: "N2", FlowM3h: MaxGasFlowM3h + 1},
+		{Gas: "N2", FlowM3h: 10, MaxO2PPM: -1},
+	} {
+		if err := fs.SetAtmosphere(context.Background(), p); !errors.Is(err, ErrInvalidAtmosphere) {
+			t.Errorf("%+v: err = %v, want ErrInvalidAtmosphere", p, err)
+		}
+	}
+
+	if err := fs.SetAtmosphere(context.Background(), AtmosphereParams{Gas: "ar", FlowM3h: 15, MaxO2PPM: 20}); err != nil {
+		t.Fatalf("SetAtmosphere: %v", err)
+	}
+	st := lastSavedState(t, srepo)
+	if st.Gas != GasArgon || st.GasSetpointM3h != 15 || st.MaxO2PPM != 20 || st.Mode != ModeStandby {
+		t.Fatalf("saved %+v", st)
+	}
+	if len(erepo.events) != 1 || erepo.events[0].Type != "ATMOSPHERE_CHANGE" {
+		t.Fatalf("events = %+v", erepo.events)
+	}
+}
+
+func TestSetMode_AppliesAtmosphere(t *testing.T) {
+	srepo := &fakeStateRepo{loadResp: models.FurnaceState{ID: 1, Mode: ModeStandby, CurrentTempC: 25, IsRunning: true}}
+	erepo := &localEventRepo{}
+	fs := &FurnaceService{state: NewStateManager(srepo), eventRepo: erepo}
+
+	err := fs.SetMode(context.Background(), ModeParams{Mode: ModeHeat, TargetTempC: 900, DurationSec: 600,
+		Atmosphere: &AtmosphereParams{Gas: "N2", FlowM3h: 20}})
+	if err != nil {
+		t.Fatalf("SetMode: %v", err)
+	}
+	if st := lastSavedState(t, srepo); st.Gas != GasNitrogen || st.GasSetpointM3h != 20 {
+		t.Fatalf("saved %+v", st)
+	}
+	if meta, _ := erepo.events[0].Metadata.(map[string]any); meta["gas"] != GasNitrogen {
+		t.Fatalf("MODE_CHANGE metadata = %#v", erepo.events[0].Metadata)
+	}
+}
+
+func TestStep_PurgeClearsO2High(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	states := &simStateRepoStub{loadResp: models.FurnaceState{
+		ID: 1, Mode: ModeHeat, IsRunning: true, CurrentTempC: 850, TargetTempC: 850, RemainingSeconds: 36000,
+		Gas: GasNitrogen, GasSetpointM3h: 20, O2PPM: AirO2PPM, UpdatedAt: start,
+	}}
+	events := &simEventRepoStub{}
+	svc := NewSimulatorService(states, events)
+	step := func(dt time.Duration) models.FurnaceState {
+		t.Helper()
+		if err := svc.Step(context.Background(), dt); err != nil {
+			t.Fatalf("Step: %v", err)
+		}
+		states.loadResp = states.saves[len(states.saves)-1]
+		return states.loadResp
+	}
+
+	st := step(time.Second)
+	if !hasString(st.ErrorCodes, AlarmO2High) {
+		t.Fatalf("hot chamber full of air: errors = %v, want %s", st.ErrorCodes, AlarmO2High)
+	}
+	for i := 0; i < 120; i++ {
+		st = step(time.Minute)
+	}
+	if st.GasFlowM3h != 20 || st.O2PPM > 50 || hasString(st.ErrorCodes, AlarmO2High) {
+		t.Fatalf("after 2 h of purge: flow %.2f, O2 %.1f ppm, errors %v", st.GasFlowM3h, st.O2PPM, st.ErrorCodes)
+	}
+
+	// opening the door for a charge lets air back in
+	if _, err := svc.InsertCharge(ChargeRequest{MassKg: 100, TempC: &st.CurrentTempC}); err != nil {
+		t.Fatalf("InsertCharge: %v", err)
+	}
+	if st = step(time.Second); st.O2PPM < 10000 || !hasString(st.ErrorCodes, AlarmO2High) {
+		t.Fatalf("after loading: O2 %.1f ppm, errors %v", st.O2PPM, st.ErrorCodes)
+	}
+
+	var raised, cleared int
+	for _, ev := range events.appends {
+		meta, _ := ev.Metadata.(map[string]any)
+		switch {
+		case ev.Type == "ERROR" && meta["alarm"] == AlarmO2High:
+			raised++
+		case ev.Type == "ALARM_CLEARED" && meta["alarm"] == AlarmO2High:
+			cleared++
+		}
+	}
+	if raised != 2 || cleared != 1 {
+		t.Fatalf("O2_HIGH raised %d and cleared %d times, want 2 and 1", raised, cleared)
+	}
+}
+
+func TestStep_StoppedFurnaceFillsWithAir(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	states := &simStateRepoStub{loadResp: models.FurnaceState{
+		ID: 1, Mode: ModeStandby, CurrentTempC: 25, Gas: GasNitrogen, GasSetpointM3h: 20, GasFlowM3h: 20, O2PPM: 30, UpdatedAt: start,
+	}}
+	svc := NewSimulatorService(states, &simEventRepoStub{})
+	if err := svc.Step(context.Background(), 2*time.Hour); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	st := states.saves[len(states.saves)-1]
+	if st.GasFlowM3h != 0 || st.O2PPM < 0.9*AirO2PPM {
+		t.Fatalf("stopped for 2 h: flow %.2f, O2 %.1f ppm", st.GasFlowM3h, st.O2PPM)
+	}
+}
@@ -0,0 +1,393 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"slices"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+// Audit export errors.
+var (
+	// ErrInvalidAuditExport is returned for an export without a valid period.
+	ErrInvalidAuditExport = errors.New("invalid audit export")
+	// ErrAuditKeyNotConfigured is returned while audit.signing_key is
+	// unset: a key made up at startup would let anyone re-sign an edited
+	// package, so none is exported.
+	ErrAuditKeyNotConfigured = errors.New("audit.signing_key is not configured; audit packages are not exported without it")
+)
+
+// Names of the audit package entries. The manifest lists the SHA-256 of
+// every other entry and manifest.sig holds its Ed25519 signature.
+const (
+	auditManifest   = "manifest.json"
+	auditSignature  = "manifest.sig"
+	auditEvents     = "events.ndjson"
+	auditChain      = "audit/chain.json"
+	auditAlerts     = "alarms/alerts.json"
+	auditIncidents  = "alarms/incidents.json"
+	auditRuns       = "runs.json"
+	auditSimConfig  = "config/sim_settings.json"
+	auditAlertRules = "config/alert_rules.json"
+)
+
+// AuditConfig holds the key audit packages are signed with.
+type AuditConfig struct {
+	// SigningKey is a base64-encoded 32-byte Ed25519 seed. When empty,
+	// exports fail with ErrAuditKeyNotConfigured.
+	SigningKey string `mapstructure:"signing_key"`
+}
+
+// Validate rejects a signing key that is not a base64 Ed25519 seed.
+func (c AuditConfig) Validate() error {
+	_, err := c.privateKey()
+	return err
+}
+
+// Fingerprint returns the fingerprint of the configured key's public half
+// (see AuditKey), or "" when none is set.
+func (c AuditConfig) Fingerprint() (string, error) {
+	key, err := c.privateKey()
+	if key == nil || err != nil {
+		return "", err
+	}
+	k, err := newAuditKey(key.Public())
+	return k.Fingerprint, err
+}
+
+// privateKey returns the configured key, or nil when none is set.
+func (c AuditConfig) privateKey() (ed25519.PrivateKey, error) {
+	if c.SigningKey == "" {
+		return nil, nil
+	}
+	seed, err := base64.StdEncoding.DecodeString(c.SigningKey)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("audit signing_key must be %d base64-encoded bytes", ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// AuditExportRequest selects the period of an audit package.
+type AuditExportRequest struct {
+	From   time.Time // inclusive
+	To     time.Time // inclusive
+	UserID int       // who asked for the package, recorded in the manifest
+}
+
+// AuditManifest describes an audit package. Verify manifest.sig against
+// the exact bytes of manifest.json, then each file against its SHA-256.
+type AuditManifest struct {
+	From        time.Time           `json:"from"`
+	To          time.Time           `json:"to"`
+	GeneratedAt time.Time           `json:"generated_at"`
+	GeneratedBy int                 `json:"generated_by"`
+	Version     string              `json:"version,omitempty"`
+	Files       []AuditManifestFile `json:"files"`
+	// PublicKey is the PEM-encoded Ed25519 key the manifest is signed with
+	// and KeyFingerprint its fingerprint. Both come with the package, so
+	// they only say which key to check against: whoever edits a package can
+	// re-sign it and replace them. Verify with a copy of the key obtained
+	// apart from the package (AuditPublicKey).
+	PublicKey      string `json:"public_key"`
+	KeyFingerprint string `json:"key_fingerprint"`
+}
+
+// AuditKey is the public key audit packages are signed with.
+type AuditKey struct {
+	// PEM-encoded Ed25519 public key
+	PublicKey string `json:"public_key"`
+	// Hex SHA-256 of the DER-encoded public key
+	Fingerprint string `json:"fingerprint" example:"3b6a27bcceb6a42d62a3a8d02a6f0d73653215771de243a63ac048a18b59da29"`
+}
+
+func newAuditKey(pub any) (AuditKey, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return AuditKey{}, err
+	}
+	sum := sha256.Sum256(der)
+	return AuditKey{
+		PublicKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		Fingerprint: hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// AuditManifestFile is one entry of an audit package.
+type AuditManifestFile struct {
+	Name    string `json:"name"`
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256"`
+	Records int    `json:"records"`
+}
+
+// AuditService assembles signed audit packages from the repositories.
+type AuditService struct {
+	events    repository.EventStreamRepo
+	comments  repository.EventCommentRepo // optional; events are exported without comments when nil
+	chain     repository.EventChainRepo   // optional; no chain report when nil
+	runs      repository.RunRepo
+	alerts    repository.AlertRepo
+	incidents repository.IncidentRepo
+	settings  func() models.SimSettings
+	key       ed25519.PrivateKey // nil refuses exports
+	version   string
+	now       func() time.Time
+}
+
+// NewAuditService signs packages with the key in cfg; without one it
+// refuses to export. cfg must have passed Validate.
+func NewAuditService(events repository.EventStreamRepo, runs repository.RunRepo, alerts repository.AlertRepo,
+	incidents repository.IncidentRepo, settings func() models.SimSettings, cfg AuditConfig) *AuditService {
+	key, _ := cfg.privateKey()
+	return &AuditService{
+		events: events, runs: runs, alerts: alerts, incidents: incidents, settings: settings,
+		key: key, now: time.Now,
+	}
+}
+
+// AuditPublicKey returns the key packages are signed with, for verifiers
+// to keep apart from the packages. It fails with ErrAuditKeyNotConfigured
+// when there is none.
+func (s *AuditService) AuditPublicKey() (AuditKey, error) {
+	if s.key == nil {
+		return AuditKey{}, ErrAuditKeyNotConfigured
+	}
+	return newAuditKey(s.key.Public())
+}
+
+// ExportAudit writes a ZIP of the records of the period to w: the events
+// with their comments, soft-deleted ones included, the verification of the
+// hash chain, the alerts and incidents, the runs the events belong to and
+// the current configuration.
+// Events are streamed from the repository rather than loaded at once. An
+// invalid request fails with ErrInvalidAuditExport, and any request without
+// a signing key with ErrAuditKeyNotConfigured, before anything is written;
+// a later failure leaves w with a package missing its signature.
+func (s *AuditService) ExportAudit(ctx context.Context, req AuditExportRequest, w io.Writer) (err error) {
+	ctx, span := startSpan(ctx, "Audit.Export")
+	defer func() { endSpan(span, err) }()
+
+	from, to := normalizeToUTC(req.From), normalizeToUTC(req.To)
+	if from.IsZero() || to.IsZero() || from.After(to) {
+		return fmt.Errorf("%w: from and to are required and from must be <= to", ErrInvalidAuditExport)
+	}
+	if s.key == nil {
+		return ErrAuditKeyNotConfigured
+	}
+	if s.events == nil {
+		return errNoEventPager
+	}
+	m := AuditManifest{From: from, To: to, GeneratedAt: s.now().UTC(), GeneratedBy: req.UserID, Version: s.version}
+
+	pkg := &auditPackage{zip: zip.NewWriter(w), at: m.GeneratedAt}
+	runIDs, err := s.writeEvents(ctx, pkg, repository.EventQuery{From: from, To: to, IncludeDeleted: true})
+	if err != nil {
+		return err
+	}
+	if s.chain != nil {
+		rep, err := s.chain.VerifyChain(ctx)
+		if err != nil {
+			return err
+		}
+		if err := pkg.writeJSON(auditChain, rep, 1); err != nil {
+			return err
+		}
+	}
+	alerts, err := s.alerts.ListAlerts(ctx, repository.AlertQuery{From: from, To: to})
+	if err != nil {
+		return err
+	}
+	if err := pkg.writeJSON(auditAlerts, alerts, len(alerts)); err != nil {
+		return err
+	}
+	incidents, err := s.incidents.List(ctx, repository.IncidentQuery{From: from, To: to})
+	if err != nil {
+		return err
+	}
+	if err := pkg.writeJSON(auditIncidents, incidents, len(incidents)); err != nil {
+		return err
+	}
+	runs := make([]models.Run, 0, len(runIDs))
+	for _, id := range runIDs {
+		r, err := s.runs.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if r.RunID != "" {
+			runs = append(runs, r)
+		}
+	}
+	if err := pkg.writeJSON(auditRuns, runs, len(runs)); err != nil {
+		return err
+	}
+	if err := pkg.writeJSON(auditSimConfig, s.settings(), 1); err != nil {
+		return err
+	}
+	rules, err := s.alerts.ListRules(ctx)
+	if err != nil {
+		return err
+	}
+	if err := pkg.writeJSON(auditAlertRules, rules, len(rules)); err != nil {
+		return err
+	}
+
+	m.Files = pkg.files
+	key, err := newAuditKey(s.key.Public())
+	if err != nil {
+		return err
+	}
+	m.PublicKey, m.KeyFingerprint = key.PublicKey, key.Fingerprint
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := pkg.writeRaw(auditManifest, manifest); err != nil {
+		return err
+	}
+	if err := pkg.writeRaw(auditSignature, ed25519.Sign(s.key, manifest)); err != nil {
+		return err
+	}
+	return pkg.zip.Close()
+}
+
+// writeEvents streams the events matching q into events.ndjson with their
+// comments, and returns the IDs of the runs they were recorded in.
+func (s *AuditService) writeEvents(ctx context.Context, pkg *auditPackage, q repository.EventQuery) ([]string, error) {
+	f, err := pkg.create(auditEvents)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(f)
+	var (
+		runIDs []string
+		batch  = make([]models.FurnaceEvent, 0, auditCommentBatch)
+	)
+	flush := func() error {
+		if s.comments != nil && len(batch) > 0 {
+			ids := make([]string, len(batch))
+			for i, ev := range batch {
+				ids[i] = ev.EventID
+			}
+			byEvent, err := s.comments.CommentsFor(ctx, ids)
+			if err != nil {
+				return err
+			}
+			for i := range batch {
+				batch[i].Comments = byEvent[batch[i].EventID]
+			}
+		}
+		for _, ev := range batch {
+			if err := enc.Encode(ev); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+	err = s.events.Each(ctx, q, func(ev models.FurnaceEvent) error {
+		if id := metadataString(ev.Metadata, "run_id"); id != "" && !slices.Contains(runIDs, id) {
+			runIDs = append(runIDs, id)
+		}
+		f.records++
+		if batch = append(batch, ev); len(batch) == cap(batch) {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return nil, err
+	}
+	pkg.close(f)
+	return runIDs, nil
+}
+
+// auditCommentBatch is how many streamed events share one comment lookup.
+const auditCommentBatch = 500
+
+// metadataString returns the string stored under key in event metadata.
+func metadataString(meta any, key string) string {
+	m, _ := meta.(map[string]any)
+	s, _ := m[key].(string)
+	return s
+}
+
+// auditPackage writes the entries of a ZIP one after the other, recording
+// the size and SHA-256 of each for the manifest.
+type auditPackage struct {
+	zip   *zip.Writer
+	at    time.Time
+	files []AuditManifestFile
+}
+
+// auditEntry is the entry being written.
+type auditEntry struct {
+	name    string
+	w       io.Writer
+	sum     hash.Hash
+	size    int64
+	records int
+}
+
+func (e *auditEntry) Write(p []byte) (int, error) {
+	n, err := e.w.Write(p)
+	e.sum.Write(p[:n])
+	e.size += int64(n)
+	return n, err
+}
+
+func (p *auditPackage) create(name string) (*auditEntry, error) {
+	w, err := p.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: p.at})
+	if err != nil {
+		return nil, err
+	}
+	return &auditEntry{name: name, w: w, sum: sha256.New()}, nil
+}
+
+// close records e in the manifest.
+func (p *auditPackage) close(e *auditEntry) {
+	p.files = append(p.files, AuditManifestFile{
+		Name: e.name, Size: e.size, SHA256: hex.EncodeToString(e.sum.Sum(nil)), Records: e.records,
+	})
+}
+
+func (p *auditPackage) writeJSON(name string, v any, records int) error {
+	e, err := p.create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(e)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	e.records = records
+	p.close(e)
+	return nil
+}
+
+// writeRaw writes an entry that is not listed in the manifest.
+func (p *auditPackage) writeRaw(name string, b []byte) error {
+	w, err := p.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: p.at})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
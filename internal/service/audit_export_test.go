@@ -0,0 +1,153 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestAuditService_ExportsASignedPackage(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	seed := bytes.Repeat([]byte{7}, ed25519.SeedSize)
+	cfg := AuditConfig{SigningKey: base64.StdEncoding.EncodeToString(seed)}
+	stream := &streamRepoStub{events: []models.FurnaceEvent{
+		{EventID: "e1", Type: "START", Metadata: map[string]any{"run_id": "run-1"}},
+		{EventID: "e2", Type: "ERROR", Metadata: map[string]any{"run_id": "run-1"}},
+		{EventID: "e3", Type: "STOP"},
+	}}
+	incidents := &incidentListStub{incidentRepoStub: newIncidentRepoStub()}
+	svc := NewAuditService(stream,
+		&runRepoStub{runs: map[string]models.Run{"run-1": {RunID: "run-1", TargetTempC: 800}}},
+		&alertRepoStub{rules: []models.AlertRule{{ID: 1}}, fired: []models.Alert{{ID: 4}}},
+		incidents,
+		func() models.SimSettings { return models.SimSettings{RampUpCPerSec: 3} },
+		cfg)
+	svc.comments = &commentRepoStub{comments: []models.EventComment{{EventID: "e2", Text: "door left open"}}}
+	svc.now = func() time.Time { return at }
+
+	var buf bytes.Buffer
+	req := AuditExportRequest{From: at.Add(-time.Hour), To: at, UserID: 9}
+	if err := svc.ExportAudit(context.Background(), req, &buf); err != nil {
+		t.Fatalf("ExportAudit() error = %v", err)
+	}
+	if stream.gotQ.From != req.From || stream.gotQ.To != req.To || incidents.lastQ.From != req.From {
+		t.Fatalf("period not passed on: events %+v, incidents %+v", stream.gotQ, incidents.lastQ)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+
+	// the signature covers the manifest, and the manifest every file
+	key := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+	if !ed25519.Verify(key, files[auditManifest], files[auditSignature]) {
+		t.Fatalf("manifest signature does not verify")
+	}
+	var m AuditManifest
+	if err := json.Unmarshal(files[auditManifest], &m); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	block, _ := pem.Decode([]byte(m.PublicKey))
+	if block == nil {
+		t.Fatalf("public key is not PEM: %q", m.PublicKey)
+	}
+	if pub, err := x509.ParsePKIXPublicKey(block.Bytes); err != nil || !key.Equal(pub) {
+		t.Fatalf("manifest public key %v, err %v; want the configured key", pub, err)
+	}
+	published, err := svc.AuditPublicKey()
+	if err != nil || published.PublicKey != m.PublicKey || published.Fingerprint != m.KeyFingerprint {
+		t.Fatalf("AuditPublicKey() = %+v, %v; want the manifest's key %q", published, err, m.KeyFingerprint)
+	}
+	if fp, _ := cfg.Fingerprint(); fp != published.Fingerprint {
+		t.Fatalf("config fingerprint %q, want %q", fp, published.Fingerprint)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(key)
+	if sum := sha256.Sum256(der); m.KeyFingerprint != hex.EncodeToString(sum[:]) {
+		t.Fatalf("fingerprint %q is not the SHA-256 of the DER key", m.KeyFingerprint)
+	}
+	if m.GeneratedBy != 9 || !m.GeneratedAt.Equal(at) || len(m.Files) != len(files)-2 {
+		t.Fatalf("manifest %+v for files %d", m, len(files))
+	}
+	for _, f := range m.Files {
+		sum := sha256.Sum256(files[f.Name])
+		if f.SHA256 != hex.EncodeToString(sum[:]) || f.Size != int64(len(files[f.Name])) {
+			t.Fatalf("%s does not match the manifest", f.Name)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(files[auditEvents])), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "door left open") || m.Files[0].Records != 3 {
+		t.Fatalf("events.ndjson = %q", files[auditEvents])
+	}
+	var runs []models.Run
+	if err := json.Unmarshal(files[auditRuns], &runs); err != nil || len(runs) != 1 || runs[0].RunID != "run-1" {
+		t.Fatalf("runs.json = %s", files[auditRuns])
+	}
+	for _, name := range []string{auditAlerts, auditIncidents, auditSimConfig, auditAlertRules} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("package is missing %s", name)
+		}
+	}
+}
+
+func TestAuditService_RejectsInvalidPeriods(t *testing.T) {
+	t.Parallel()
+
+	cfg := AuditConfig{SigningKey: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, ed25519.SeedSize))}
+	svc := NewAuditService(&streamRepoStub{}, &runRepoStub{}, &alertRepoStub{}, newIncidentRepoStub(),
+		func() models.SimSettings { return models.SimSettings{} }, cfg)
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	for _, req := range []AuditExportRequest{{To: at}, {From: at}, {From: at, To: at.Add(-time.Second)}} {
+		var buf bytes.Buffer
+		if err := svc.ExportAudit(context.Background(), req, &buf); !errors.Is(err, ErrInvalidAuditExport) || buf.Len() != 0 {
+			t.Fatalf("%+v: err %v after writing %d bytes; want ErrInvalidAuditExport and nothing written", req, err, buf.Len())
+		}
+	}
+	if err := (AuditConfig{SigningKey: "c2hvcnQ="}).Validate(); err == nil {
+		t.Fatalf("expected a short key to be rejected")
+	}
+}
+
+func TestAuditService_RefusesToExportWithoutAKey(t *testing.T) {
+	t.Parallel()
+
+	svc := NewAuditService(&streamRepoStub{}, &runRepoStub{}, &alertRepoStub{}, newIncidentRepoStub(),
+		func() models.SimSettings { return models.SimSettings{} }, AuditConfig{})
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	if err := svc.ExportAudit(context.Background(), AuditExportRequest{From: at, To: at}, &buf); !errors.Is(err, ErrAuditKeyNotConfigured) || buf.Len() != 0 {
+		t.Fatalf("err %v after writing %d bytes; want ErrAuditKeyNotConfigured and nothing written", err, buf.Len())
+	}
+	if _, err := svc.AuditPublicKey(); !errors.Is(err, ErrAuditKeyNotConfigured) {
+		t.Fatalf("AuditPublicKey() = %v, want ErrAuditKeyNotConfigured", err)
+	}
+	if fp, err := (AuditConfig{}).Fingerprint(); fp != "" || err != nil {
+		t.Fatalf("Fingerprint() = %q, %v; want none", fp, err)
+	}
+}
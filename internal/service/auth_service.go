@@ -1,11 +1,15 @@
 package service
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	"controlling_furnace/internal/models"
 	"controlling_furnace/internal/repository"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -13,8 +17,10 @@ import (
 )
 
 const (
-	tokenTTL   = time.Hour   // 1 hour
-	signingKey = "asd234asd" // TODO: move to config
+	tokenTTL = time.Hour // 1 hour
+	// defaultSigningKey signs tokens until setup stores a key of its own;
+	// installations from before setup existed keep using it.
+	defaultSigningKey = "asd234asd"
 )
 
 // Domain errors for auth flows.
@@ -22,35 +28,127 @@ var (
 	ErrInvalidPassword = errors.New("invalid password")
 	ErrUserNotFound    = errors.New("user not found")
 	ErrInvalidToken    = errors.New("invalid token")
+	ErrSetupRequired   = errors.New("setup required: create the first admin with POST /api/v1/setup")
+	ErrInvalidRole     = errors.New("invalid role: use admin, operator or viewer")
+	ErrLastAdmin       = repository.ErrLastAdmin
+	// ErrWrongSite is returned for a token issued by the backend of
+	// another site sharing the database.
+	ErrWrongSite = errors.New("token is for another site")
 )
 
 // AuthService handles user auth logic
 type AuthService struct {
 	authRepo repository.Authorization
+	policy   UsernamePolicy
+	site     string // tokens are issued for, and only accepted from, this site
+
+	keyMu sync.RWMutex
+	key   []byte
 }
 
 func NewAuthService(repo repository.Authorization) *AuthService {
-	return &AuthService{authRepo: repo}
+	return &AuthService{authRepo: repo, site: repository.DefaultSite, key: []byte(defaultSigningKey)}
 }
 
-// SignUp hashes password and creates a new user
-func (s *AuthService) SignUp(username, password string) (int, error) {
+// SignUp hashes password and creates a new user with the viewer role; an
+// admin grants more with SetRole. An empty installation is set up through Setup instead, so an anonymous
+// sign-up can never claim the first admin account. A name the policy
+// rejects fails with a *UsernameError, one already in use in any case with
+// ErrUsernameTaken.
+func (s *AuthService) SignUp(ctx context.Context, username, password string) (int, error) {
+	existing, err := s.authRepo.Count(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if existing == 0 {
+		return 0, ErrSetupRequired
+	}
+	if username, err = s.policy.normalize(username, false); err != nil {
+		return 0, err
+	}
 	hash, err := hashPassword(password)
 	if err != nil {
 		return 0, fmt.Errorf("invalid password: %w", err)
 	}
-	return s.authRepo.Create(username, hash)
+	if err := s.checkUsernameFree(ctx, username); err != nil {
+		return 0, err
+	}
+	return s.authRepo.Create(ctx, username, hash)
+}
+
+// SetRole changes the role of the user with the given ID. Tokens carry
+// the role, so the change applies from the user's next sign-in. Demoting
+// the site's last admin, themselves included, fails with ErrLastAdmin.
+func (s *AuthService) SetRole(ctx context.Context, userID int, role string) error {
+	switch role {
+	case models.RoleAdmin, models.RoleOperator, models.RoleViewer:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidRole, role)
+	}
+	err := s.authRepo.SetRole(ctx, userID, role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// checkUsernameFree fails with ErrUsernameTaken when a user has username
+// regardless of case. The unique index on the key still decides a race.
+func (s *AuthService) checkUsernameFree(ctx context.Context, username string) error {
+	u, err := s.authRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if u != nil {
+		return ErrUsernameTaken
+	}
+	return nil
+}
+
+// signingKey returns the key tokens are signed and verified with.
+func (s *AuthService) signingKey() []byte {
+	s.keyMu.RLock()
+	defer s.keyMu.RUnlock()
+	return s.key
+}
+
+// setSigningKey replaces the key; tokens signed with the old one stop
+// verifying.
+func (s *AuthService) setSigningKey(key string) {
+	s.keyMu.Lock()
+	s.key = []byte(key)
+	s.keyMu.Unlock()
 }
 
 // Claims defines JWT claims
 type Claims struct {
 	jwt.RegisteredClaims
-	UserID int `json:"user_id"`
+	UserID int    `json:"user_id"`
+	Role   string `json:"role,omitempty"`
+	Site   string `json:"site,omitempty"`
+}
+
+// EffectiveRole returns the role carried by the token.
+// Tokens issued before roles existed are treated as operator tokens.
+func (c *Claims) EffectiveRole() string {
+	if c.Role == "" {
+		return models.RoleOperator
+	}
+	return c.Role
+}
+
+// EffectiveSite returns the site the token was issued for. Tokens issued
+// before sites existed belong to the default site.
+func (c *Claims) EffectiveSite() string {
+	if c.Site == "" {
+		return repository.DefaultSite
+	}
+	return c.Site
 }
 
 // GenerateToken validates credentials and returns JWT
-func (s *AuthService) GenerateToken(username, password string) (string, error) {
-	u, err := s.authRepo.GetByUsername(username)
+func (s *AuthService) GenerateToken(ctx context.Context, username, password string) (string, error) {
+	u, err := s.authRepo.GetByUsername(ctx, canonicalUsername(username))
 	if err != nil {
 		return "", err
 	}
@@ -62,28 +160,42 @@ func (s *AuthService) GenerateToken(username, password string) (string, error) {
 		return "", ErrInvalidPassword
 	}
 
-	return issueToken(u.ID)
+	return s.issueToken(u.ID, u.Role)
 }
 
 // ParseToken parses JWT and returns userID
 func (s *AuthService) ParseToken(accessToken string) (int, error) {
+	claims, err := s.ParseClaims(accessToken)
+	if err != nil {
+		return 0, err
+	}
+	return claims.UserID, nil
+}
+
+// ParseClaims parses JWT and returns all of its claims. A token issued for
+// another site fails with ErrWrongSite: sites set up before each had an
+// installation row of its own still share their signing key.
+func (s *AuthService) ParseClaims(accessToken string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(accessToken, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		// Ensure HMAC signing is used
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(signingKey), nil
+		return s.signingKey(), nil
 	})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok || !token.Valid {
-		return 0, ErrInvalidToken
+		return nil, ErrInvalidToken
+	}
+	if claims.EffectiveSite() != s.site {
+		return nil, ErrWrongSite
 	}
 
-	return claims.UserID, nil
+	return claims, nil
 }
 
 // helper: hash password safely
@@ -104,7 +216,7 @@ func verifyPassword(hash, password string) error {
 }
 
 // helper: issue a signed JWT for a user
-func issueToken(userID int) (string, error) {
+func (s *AuthService) issueToken(userID int, role string) (string, error) {
 	now := time.Now()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -112,6 +224,8 @@ func issueToken(userID int) (string, error) {
 			IssuedAt:  jwt.NewNumericDate(now),
 		},
 		UserID: userID,
+		Role:   role,
+		Site:   s.site,
 	})
-	return token.SignedString([]byte(signingKey))
+	return token.SignedString(s.signingKey())
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"errors"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -16,15 +18,17 @@ import (
 type mockAuthRepo struct {
 	CreateFn        func(username, hash string) (int, error)
 	GetByUsernameFn func(username string) (*models.User, error)
+	count           int
 
 	createCalls []struct {
 		username string
 		hash     string
 	}
 	getCalls []string
+	setRoles map[int]string
 }
 
-func (m *mockAuthRepo) Create(username, hash string) (int, error) {
+func (m *mockAuthRepo) Create(ctx context.Context, username, hash string) (int, error) {
 	m.createCalls = append(m.createCalls, struct {
 		username string
 		hash     string
@@ -32,11 +36,26 @@ func (m *mockAuthRepo) Create(username, hash string) (int, error) {
 	return m.CreateFn(username, hash)
 }
 
-func (m *mockAuthRepo) GetByUsername(username string) (*models.User, error) {
+func (m *mockAuthRepo) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	m.getCalls = append(m.getCalls, username)
+	if m.GetByUsernameFn == nil {
+		return nil, nil
+	}
 	return m.GetByUsernameFn(username)
 }
 
+func (m *mockAuthRepo) Count(ctx context.Context) (int, error) {
+	return m.count, nil
+}
+
+func (m *mockAuthRepo) SetRole(ctx context.Context, id int, role string) error {
+	if m.setRoles == nil {
+		m.setRoles = map[int]string{}
+	}
+	m.setRoles[id] = role
+	return nil
+}
+
 // --- SignUp tests ---
 
 func TestAuthService_SignUp_SuccessHashesPasswordAndCallsRepo(t *testing.T) {
@@ -44,10 +63,11 @@ func TestAuthService_SignUp_SuccessHashesPasswordAndCallsRepo(t *testing.T) {
 		CreateFn: func(username, hash string) (int, error) {
 			return 42, nil
 		},
+		count: 1,
 	}
 	svc := NewAuthService(mock)
 
-	id, err := svc.SignUp("alice", "s3cr3t")
+	id, err := svc.SignUp(context.Background(), "alice", "s3cr3t")
 	if err != nil {
 		t.Fatalf("SignUp returned error: %v", err)
 	}
@@ -80,7 +100,7 @@ func TestAuthService_SignUp_EmptyPassword(t *testing.T) {
 	}
 	svc := NewAuthService(mock)
 
-	_, err := svc.SignUp("bob", "   ")
+	_, err := svc.SignUp(context.Background(), "bob", "   ")
 	if err == nil {
 		t.Fatalf("expected error for empty password, got nil")
 	}
@@ -94,15 +114,97 @@ func TestAuthService_SignUp_RepoError(t *testing.T) {
 		CreateFn: func(username, hash string) (int, error) {
 			return 0, errors.New("db down")
 		},
+		count: 1,
 	}
 	svc := NewAuthService(mock)
 
-	_, err := svc.SignUp("carl", "pass123")
+	_, err := svc.SignUp(context.Background(), "carl", "pass123")
 	if err == nil {
 		t.Fatalf("expected repo error, got nil")
 	}
 }
 
+func TestAuthService_SignUp_EmptyInstallationNeedsSetup(t *testing.T) {
+	mock := &mockAuthRepo{
+		CreateFn: func(username, hash string) (int, error) { return 1, nil },
+	}
+	svc := NewAuthService(mock)
+
+	if _, err := svc.SignUp(context.Background(), "root", "pw"); !errors.Is(err, ErrSetupRequired) {
+		t.Fatalf("SignUp on an empty installation: err = %v, want ErrSetupRequired", err)
+	}
+	if len(mock.createCalls) != 0 {
+		t.Fatalf("expected no user created, got %d", len(mock.createCalls))
+	}
+
+	mock.count = 1
+	mock.CreateFn = func(username, hash string) (int, error) { return 2, nil }
+	if _, err := svc.SignUp(context.Background(), "second", "pw"); err != nil {
+		t.Fatalf("SignUp returned error: %v", err)
+	}
+	if _, ok := mock.setRoles[2]; ok {
+		t.Fatalf("expected later users to keep the default role, got %v", mock.setRoles)
+	}
+}
+
+func TestAuthService_SignUpCreatesViewersAnAdminCanPromote(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewInMemory()
+	svc := NewAuthService(repos.Auth)
+	if _, err := repos.Auth.Create(ctx, "root", "x"); err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := svc.SignUp(ctx, "ann", "pw")
+	if err != nil {
+		t.Fatalf("SignUp: %v", err)
+	}
+	if u, _ := repos.Auth.GetByUsername(ctx, "ann"); u == nil || u.Role != models.RoleViewer {
+		t.Fatalf("signed-up user %+v, want a viewer", u)
+	}
+
+	if err := svc.SetRole(ctx, id, "superuser"); !errors.Is(err, ErrInvalidRole) {
+		t.Fatalf("SetRole with an unknown role: %v, want ErrInvalidRole", err)
+	}
+	if err := svc.SetRole(ctx, 99, models.RoleOperator); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("SetRole for a missing user: %v, want ErrUserNotFound", err)
+	}
+	if err := svc.SetRole(ctx, id, models.RoleOperator); err != nil {
+		t.Fatalf("SetRole: %v", err)
+	}
+	if u, _ := repos.Auth.GetByUsername(ctx, "ann"); u.Role != models.RoleOperator {
+		t.Fatalf("role = %q after SetRole, want operator", u.Role)
+	}
+}
+
+func TestAuthService_SetRole_KeepsTheLastAdmin(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewInMemory()
+	svc := NewAuthService(repos.Auth)
+	root, _ := repos.Auth.Create(ctx, "root", "x")
+	ann, _ := repos.Auth.Create(ctx, "ann", "x")
+	if err := svc.SetRole(ctx, root, models.RoleAdmin); err != nil {
+		t.Fatalf("SetRole: %v", err)
+	}
+
+	if err := svc.SetRole(ctx, root, models.RoleOperator); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("demoting the only admin: %v, want ErrLastAdmin", err)
+	}
+	if u, _ := repos.Auth.GetByUsername(ctx, "root"); u.Role != models.RoleAdmin {
+		t.Fatalf("role = %q after a refused demotion, want admin", u.Role)
+	}
+
+	if err := svc.SetRole(ctx, ann, models.RoleAdmin); err != nil {
+		t.Fatalf("SetRole: %v", err)
+	}
+	if err := svc.SetRole(ctx, root, models.RoleViewer); err != nil {
+		t.Fatalf("demoting one of two admins: %v", err)
+	}
+	if err := svc.SetRole(ctx, ann, models.RoleViewer); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("demoting the remaining admin: %v, want ErrLastAdmin", err)
+	}
+}
+
 // --- GenerateToken tests ---
 
 func TestAuthService_GenerateToken_Success(t *testing.T) {
@@ -123,7 +225,7 @@ func TestAuthService_GenerateToken_Success(t *testing.T) {
 	}
 	svc := NewAuthService(mock)
 
-	token, err := svc.GenerateToken("diana", "letmein")
+	token, err := svc.GenerateToken(context.Background(), "diana", "letmein")
 	if err != nil {
 		t.Fatalf("GenerateToken returned error: %v", err)
 	}
@@ -153,7 +255,7 @@ func TestAuthService_GenerateToken_UserNotFound(t *testing.T) {
 	}
 	svc := NewAuthService(mock)
 
-	_, err := svc.GenerateToken("ghost", "pw")
+	_, err := svc.GenerateToken(context.Background(), "ghost", "pw")
 	if err == nil {
 		t.Fatalf("expected ErrUserNotFound, got nil")
 	}
@@ -175,7 +277,7 @@ func TestAuthService_GenerateToken_InvalidPassword(t *testing.T) {
 	}
 	svc := NewAuthService(mock)
 
-	_, err = svc.GenerateToken("eve", "wrong")
+	_, err = svc.GenerateToken(context.Background(), "eve", "wrong")
 	if err == nil {
 		t.Fatalf("expected ErrInvalidPassword, got nil")
 	}
@@ -192,7 +294,7 @@ func TestAuthService_GenerateToken_RepoError(t *testing.T) {
 	}
 	svc := NewAuthService(mock)
 
-	_, err := svc.GenerateToken("john", "pw")
+	_, err := svc.GenerateToken(context.Background(), "john", "pw")
 	if err == nil {
 		t.Fatalf("expected repo error, got nil")
 	}
@@ -202,7 +304,7 @@ func TestAuthService_GenerateToken_RepoError(t *testing.T) {
 
 func TestAuthService_ParseToken_Success(t *testing.T) {
 	svc := NewAuthService(&mockAuthRepo{})
-	token, err := issueToken(99)
+	token, err := svc.issueToken(99, models.RoleOperator)
 	if err != nil {
 		t.Fatalf("issueToken failed: %v", err)
 	}
@@ -216,6 +318,27 @@ func TestAuthService_ParseToken_Success(t *testing.T) {
 	}
 }
 
+func TestAuthService_ParseClaims_CarriesRole(t *testing.T) {
+	svc := NewAuthService(&mockAuthRepo{})
+	token, err := svc.issueToken(5, models.RoleAdmin)
+	if err != nil {
+		t.Fatalf("issueToken failed: %v", err)
+	}
+
+	claims, err := svc.ParseClaims(token)
+	if err != nil {
+		t.Fatalf("ParseClaims returned error: %v", err)
+	}
+	if claims.UserID != 5 || claims.EffectiveRole() != models.RoleAdmin {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+
+	legacy := &Claims{UserID: 5}
+	if legacy.EffectiveRole() != models.RoleOperator {
+		t.Fatalf("expected role-less tokens to act as operator, got %q", legacy.EffectiveRole())
+	}
+}
+
 func TestAuthService_ParseToken_Malformed(t *testing.T) {
 	svc := NewAuthService(&mockAuthRepo{})
 	_, err := svc.ParseToken("not-a-jwt")
@@ -260,7 +383,7 @@ func TestAuthService_ParseToken_Expired(t *testing.T) {
 		},
 		UserID: 11,
 	})
-	expiredToken, err := tk.SignedString([]byte(signingKey))
+	expiredToken, err := tk.SignedString([]byte(defaultSigningKey))
 	if err != nil {
 		t.Fatalf("SignedString failed: %v", err)
 	}
@@ -271,6 +394,41 @@ func TestAuthService_ParseToken_Expired(t *testing.T) {
 	}
 }
 
+func TestAuthService_TokensAreBoundToTheSite(t *testing.T) {
+	plantA := NewAuthService(&mockAuthRepo{})
+	plantA.site = "plant-a"
+	plantB := NewAuthService(&mockAuthRepo{})
+	plantB.site = "plant-b"
+
+	token, err := plantA.issueToken(7, models.RoleOperator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims, err := plantA.ParseClaims(token); err != nil || claims.Site != "plant-a" {
+		t.Fatalf("claims = %+v, %v; want plant-a", claims, err)
+	}
+	// same signing key, other site
+	if _, err := plantB.ParseClaims(token); !errors.Is(err, ErrWrongSite) {
+		t.Fatalf("other site: err %v, want ErrWrongSite", err)
+	}
+
+	// tokens from before sites belong to the default site
+	now := time.Now()
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour))},
+		UserID:           7,
+	}).SignedString([]byte(defaultSigningKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewAuthService(&mockAuthRepo{}).ParseClaims(legacy); err != nil {
+		t.Fatalf("legacy token on the default site: %v", err)
+	}
+	if _, err := plantA.ParseClaims(legacy); !errors.Is(err, ErrWrongSite) {
+		t.Fatalf("legacy token on plant-a: err %v, want ErrWrongSite", err)
+	}
+}
+
 func TestAuthService_ParseToken_UnexpectedAlg(t *testing.T) {
 	svc := NewAuthService(&mockAuthRepo{})
 
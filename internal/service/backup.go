@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ErrBackupNotConfigured is returned for a stored backup without a backup
+// directory; a download still works.
+var ErrBackupNotConfigured = errors.New("backup.dir is not configured; request a download instead")
+
+// ErrInvalidBackup is returned for backup settings that cannot be applied.
+var ErrInvalidBackup = errors.New("invalid backup config")
+
+// backupPrefix and backupExt name the snapshots, so pruning only ever
+// removes files a backup wrote.
+const (
+	backupPrefix = "furnace-"
+	backupExt    = ".db"
+)
+
+// BackupConfig selects where snapshots of the database are kept.
+type BackupConfig struct {
+	// Dir receives the snapshots of POST /admin/backup; empty allows
+	// downloads only.
+	Dir  string `mapstructure:"dir"`
+	Keep int    `mapstructure:"keep"` // newest snapshots kept in Dir; 0 keeps all
+}
+
+// Validate rejects a negative Keep.
+func (c BackupConfig) Validate() error {
+	if c.Keep < 0 {
+		return fmt.Errorf("%w: keep must be >= 0", ErrInvalidBackup)
+	}
+	return nil
+}
+
+// BackupService takes consistent snapshots of the database while it is in
+// use. Each is logged as a BACKUP event.
+type BackupService struct {
+	repo   repository.BackupRepo
+	events repository.EventRepo // may be nil
+	cfg    BackupConfig
+	now    func() time.Time
+	newID  func() string
+}
+
+func NewBackupService(repo repository.BackupRepo, events repository.EventRepo, cfg BackupConfig) *BackupService {
+	return &BackupService{repo: repo, events: events, cfg: cfg, now: time.Now, newID: uuid.NewString}
+}
+
+// Backup stores a snapshot in the backup directory and removes the oldest
+// beyond Keep. The file is written under a temporary name first, so the
+// directory only ever holds complete snapshots.
+func (s *BackupService) Backup(ctx context.Context) (models.BackupReport, error) {
+	if s.cfg.Dir == "" {
+		return models.BackupReport{}, ErrBackupNotConfigured
+	}
+	if err := os.MkdirAll(s.cfg.Dir, 0o755); err != nil {
+		return models.BackupReport{}, fmt.Errorf("create backup dir: %w", err)
+	}
+	rep := s.report()
+	rep.Path = filepath.Join(s.cfg.Dir, rep.File)
+	tmp := rep.Path + ".tmp"
+	_ = os.Remove(tmp) // left by a crash; VACUUM INTO refuses existing files
+	if err := s.snapshot(ctx, tmp, &rep); err != nil {
+		_ = os.Remove(tmp)
+		return models.BackupReport{}, err
+	}
+	if err := os.Rename(tmp, rep.Path); err != nil {
+		_ = os.Remove(tmp)
+		return models.BackupReport{}, err
+	}
+	s.prune()
+	s.logBackup(ctx, rep)
+	return rep, nil
+}
+
+// OpenBackup takes a snapshot into a temporary file for download and
+// returns it with its report. Closing it removes the file.
+func (s *BackupService) OpenBackup(ctx context.Context) (models.BackupReport, io.ReadCloser, error) {
+	dir, err := os.MkdirTemp("", "furnace-backup-")
+	if err != nil {
+		return models.BackupReport{}, nil, err
+	}
+	rep := s.report()
+	path := filepath.Join(dir, rep.File)
+	var f *os.File
+	if err = s.snapshot(ctx, path, &rep); err == nil {
+		f, err = os.Open(path)
+	}
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return models.BackupReport{}, nil, err
+	}
+	s.logBackup(ctx, rep)
+	return rep, &tempSnapshot{File: f, dir: dir}, nil
+}
+
+// tempSnapshot removes the directory of a downloaded snapshot on Close.
+type tempSnapshot struct {
+	*os.File
+	dir string
+}
+
+func (t *tempSnapshot) Close() error {
+	err := t.File.Close()
+	_ = os.RemoveAll(t.dir)
+	return err
+}
+
+func (s *BackupService) report() models.BackupReport {
+	now := s.now().UTC()
+	return models.BackupReport{File: backupPrefix + now.Format("20060102T150405Z") + backupExt, CreatedAt: now}
+}
+
+// snapshot writes the database to path and fills in its size and checksum.
+func (s *BackupService) snapshot(ctx context.Context, path string, rep *models.BackupReport) error {
+	if err := s.repo.Backup(ctx, path); err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return err
+	}
+	rep.Bytes, rep.SHA256 = n, hex.EncodeToString(h.Sum(nil))
+	return nil
+}
+
+// prune removes the oldest snapshots beyond Keep. Failures are left for
+// the next backup to retry.
+func (s *BackupService) prune() {
+	if s.cfg.Keep <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(s.cfg.Dir)
+	if err != nil {
+		return
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), backupPrefix) && strings.HasSuffix(e.Name(), backupExt) {
+			names = append(names, e.Name())
+		}
+	}
+	// timestamped names sort oldest first
+	sort.Strings(names)
+	for len(names) > s.cfg.Keep {
+		_ = os.Remove(filepath.Join(s.cfg.Dir, names[0]))
+		names = names[1:]
+	}
+}
+
+func (s *BackupService) logBackup(ctx context.Context, rep models.BackupReport) {
+	if s.events == nil {
+		return
+	}
+	meta := map[string]any{"file": rep.File, "bytes": rep.Bytes, "sha256": rep.SHA256}
+	desc := "Database backed up to " + rep.Path
+	if rep.Path == "" {
+		meta["download"] = true
+		desc = "Database backup downloaded"
+	}
+	_ = s.events.Append(ctx, models.FurnaceEvent{
+		EventID:     s.newID(),
+		OccurredAt:  rep.CreatedAt,
+		Type:        "BACKUP",
+		Description: desc,
+		Metadata:    meta,
+	})
+}
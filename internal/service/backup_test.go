@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/repository"
+)
+
+// fileBackups writes a fixed snapshot, failing like VACUUM INTO when the
+// file exists.
+type fileBackups struct{ data string }
+
+func (b fileBackups) Backup(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return errors.New("output file already exists")
+	}
+	return os.WriteFile(path, []byte(b.data), 0o644)
+}
+
+func TestBackupService_StoresAndPrunes(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	// not a snapshot; pruning must leave it alone
+	_ = os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep"), 0o644)
+	events := repository.NewInMemory().EventRepo
+	s := NewBackupService(fileBackups{"snapshot"}, events, BackupConfig{Dir: dir, Keep: 2})
+	now := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	var first string
+	for i := 0; i < 3; i++ {
+		r, err := s.Backup(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			first = r.Path
+		}
+		sum := sha256.Sum256([]byte("snapshot"))
+		if r.Bytes != 8 || r.SHA256 != hex.EncodeToString(sum[:]) || r.File != "furnace-"+now.Format("20060102T150405Z")+".db" {
+			t.Fatalf("report %+v", r)
+		}
+		now = now.Add(time.Hour)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 3 { // two snapshots and notes.txt
+		t.Fatalf("dir holds %v", entries)
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Fatalf("oldest snapshot kept: %v", err)
+	}
+	logged, _ := events.Query(ctx, repository.EventQuery{Type: "BACKUP"})
+	if len(logged) != 3 {
+		t.Fatalf("expected an event per backup, got %d", len(logged))
+	}
+}
+
+func TestBackupService_Download(t *testing.T) {
+	ctx := context.Background()
+	s := NewBackupService(fileBackups{"snapshot"}, nil, BackupConfig{})
+	if _, err := s.Backup(ctx); !errors.Is(err, ErrBackupNotConfigured) {
+		t.Fatalf("stored backup without a dir: %v", err)
+	}
+	rep, f, err := s.OpenBackup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(f)
+	tmp := f.(*tempSnapshot).dir
+	_ = f.Close()
+	if string(b) != "snapshot" || rep.Path != "" || rep.Bytes != 8 {
+		t.Fatalf("downloaded %q, %+v", b, rep)
+	}
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Fatalf("temporary snapshot left behind: %v", err)
+	}
+}
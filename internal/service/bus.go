@@ -0,0 +1,72 @@
+package service
+
+import (
+	"sync"
+
+	"controlling_furnace/internal/models"
+)
+
+// StateBroker fans out furnace state snapshots to in-process subscribers,
+// so live consumers share the simulator's writes instead of polling the
+// database. It is safe for concurrent use.
+type StateBroker struct {
+	mu   sync.RWMutex
+	subs map[chan models.FurnaceState]struct{}
+}
+
+func NewStateBroker() *StateBroker {
+	return &StateBroker{subs: make(map[chan models.FurnaceState]struct{})}
+}
+
+// Publish delivers st to every subscriber without blocking. A subscriber
+// whose buffer is full loses its oldest pending snapshot, so a slow
+// consumer skips ahead to the latest state instead of stalling the
+// simulator.
+func (b *StateBroker) Publish(st models.FurnaceState) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subs {
+		select {
+		case ch <- st:
+			continue
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- st:
+		default:
+		}
+	}
+}
+
+// Subscribe registers a subscriber with room for buffer pending snapshots
+// (at least 1). Call cancel to unsubscribe; it closes the channel.
+func (b *StateBroker) Subscribe(buffer int) (updates <-chan models.FurnaceState, cancel func()) {
+	if buffer < 1 {
+		buffer = 1
+	}
+	ch := make(chan models.FurnaceState, buffer)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Subscribers returns the number of active subscriptions.
+func (b *StateBroker) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestStateBroker_SlowSubscriberGetsLatest(t *testing.T) {
+	b := NewStateBroker()
+	fast, cancelFast := b.Subscribe(4)
+	defer cancelFast()
+	slow, cancelSlow := b.Subscribe(1)
+
+	for i := 1; i <= 3; i++ {
+		b.Publish(models.FurnaceState{CurrentTempC: float64(i)})
+	}
+
+	if got := (<-slow).CurrentTempC; got != 3 {
+		t.Fatalf("slow subscriber should skip to the latest snapshot, got %.0f", got)
+	}
+	for want := 1.0; want <= 3; want++ {
+		if got := (<-fast).CurrentTempC; got != want {
+			t.Fatalf("buffered subscriber got %.0f, want %.0f", got, want)
+		}
+	}
+
+	cancelSlow()
+	cancelSlow() // idempotent
+	if _, ok := <-slow; ok {
+		t.Fatal("expected channel closed after cancel")
+	}
+	if n := b.Subscribers(); n != 1 {
+		t.Fatalf("expected 1 subscriber left, got %d", n)
+	}
+	b.Publish(models.FurnaceState{}) // must not panic on the closed channel
+}
+
+func TestSimulator_PublishesSavedStates(t *testing.T) {
+	now := time.Now()
+	states := &simStateRepoStub{loadResp: heatingState(now)}
+	svc := NewSimulatorService(states, &simEventRepoStub{})
+	svc.bus = NewStateBroker()
+	updates, cancel := svc.bus.Subscribe(1)
+	defer cancel()
+
+	svc.tick(context.Background(), now)
+
+	select {
+	case st := <-updates:
+		if len(states.saves) != 1 || st.CurrentTempC != states.saves[0].CurrentTempC {
+			t.Fatalf("published %+v, saved %+v", st, states.saves)
+		}
+	default:
+		t.Fatal("expected the saved state to be published")
+	}
+}
@@ -0,0 +1,22 @@
+package service
+
+import "controlling_furnace/internal/repository"
+
+// ChaosService exposes runtime control of repository fault injection.
+type ChaosService struct {
+	chaos *repository.Chaos
+}
+
+func NewChaosService(chaos *repository.Chaos) *ChaosService {
+	return &ChaosService{chaos: chaos}
+}
+
+// ChaosSettings returns the active fault-injection settings.
+func (s *ChaosService) ChaosSettings() repository.ChaosSettings {
+	return s.chaos.Settings()
+}
+
+// UpdateChaos validates and applies new fault-injection settings.
+func (s *ChaosService) UpdateChaos(settings repository.ChaosSettings) error {
+	return s.chaos.Update(settings)
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// DefaultSpecificHeat is the specific heat of a charge when none is given:
+// carbon steel, J/(kg·K).
+const DefaultSpecificHeat = 490.0
+
+var (
+	// ErrInvalidCharge reports a charge the simulator cannot model, or any
+	// charge while ChargeConfig is disabled.
+	ErrInvalidCharge = errors.New("invalid charge")
+	// ErrChargeLoaded is returned when inserting while a charge is in.
+	ErrChargeLoaded = errors.New("furnace already holds a charge")
+	// ErrNoCharge is returned when removing from an empty furnace.
+	ErrNoCharge = errors.New("furnace holds no charge")
+)
+
+// ChargeConfig models loads placed in the chamber. A charge is one lumped
+// mass exchanging heat with the chamber. The heater only heats the chamber,
+// so a cold charge pulls the chamber temperature down and slows its
+// recovery until both have equalized.
+type ChargeConfig struct {
+	// ChamberKJPerK is the heat capacity of the empty chamber and its
+	// lining, kJ/K; 0 disables charges. The heater delivers
+	// RampUpCPerSec times this.
+	ChamberKJPerK float64
+	// CouplingKWPerK is the heat flow between chamber and charge per kelvin
+	// of difference, kW/K.
+	CouplingKWPerK float64
+}
+
+func (c ChargeConfig) enabled() bool {
+	return c.ChamberKJPerK > 0 && c.CouplingKWPerK > 0
+}
+
+// ChargeRequest describes a load to insert. Zero SpecificHeat means
+// DefaultSpecificHeat; a nil TempC inserts it at room temperature.
+type ChargeRequest struct {
+	MassKg       float64
+	SpecificHeat float64 // J/(kg·K)
+	TempC        *float64
+}
+
+// ChargeStatus describes the load in the furnace, if any.
+type ChargeStatus struct {
+	Loaded       bool       `json:"loaded"`
+	MassKg       float64    `json:"mass_kg,omitempty" example:"800"`
+	SpecificHeat float64    `json:"specific_heat_j_per_kg_k,omitempty" example:"490"`
+	TempC        float64    `json:"temp_c,omitempty" example:"412.5"` // °C, current core temperature of the load
+	InsertedAt   *time.Time `json:"inserted_at,omitempty"`
+}
+
+// charge is a load in the chamber. Its temperature is advanced by the
+// simulator loop.
+type charge struct {
+	massKg, specificHeat, tempC float64
+	insertedAt                  time.Time
+}
+
+// kjPerK is the heat capacity of the load.
+func (c *charge) kjPerK() float64 { return c.massKg * c.specificHeat / 1000 }
+
+// chargeSlot holds the charge; it is shared between API handlers and the
+// simulator loop.
+type chargeSlot struct {
+	mu     sync.Mutex
+	load   *charge
+	logged *charge // the load last announced by CHARGE_INSERTED
+}
+
+func (c *chargeSlot) status() ChargeStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.load == nil {
+		return ChargeStatus{}
+	}
+	at := c.load.insertedAt
+	return ChargeStatus{
+		Loaded:       true,
+		MassKg:       c.load.massKg,
+		SpecificHeat: c.load.specificHeat,
+		TempC:        math.Round(c.load.tempC*100) / 100,
+		InsertedAt:   &at,
+	}
+}
+
+// Charge reports the load in the furnace.
+func (s *SimulatorService) Charge() ChargeStatus {
+	return s.charge.status()
+}
+
+// InsertCharge places a load in the chamber. It exchanges heat from the
+// next tick on, which logs CHARGE_INSERTED.
+func (s *SimulatorService) InsertCharge(req ChargeRequest) (ChargeStatus, error) {
+	s.settingsMu.RLock()
+	cfg, maxSafe := s.published.Charge, s.published.Physics.MaxSafeC
+	s.settingsMu.RUnlock()
+	if req.SpecificHeat == 0 {
+		req.SpecificHeat = DefaultSpecificHeat
+	}
+	temp := s.Ambient().TempC
+	if req.TempC != nil {
+		temp = *req.TempC
+	}
+	switch {
+	case !cfg.enabled():
+		return ChargeStatus{}, fmt.Errorf("%w: charge simulation is disabled", ErrInvalidCharge)
+	case req.MassKg <= 0 || req.SpecificHeat <= 0:
+		return ChargeStatus{}, fmt.Errorf("%w: mass and specific heat must be positive", ErrInvalidCharge)
+	case temp < MinAmbientC || temp > maxSafe:
+		return ChargeStatus{}, fmt.Errorf("%w: temperature must be within %.0f..%.0f °C", ErrInvalidCharge, MinAmbientC, maxSafe)
+	}
+
+	s.charge.mu.Lock()
+	defer s.charge.mu.Unlock()
+	if s.charge.load != nil {
+		return ChargeStatus{}, ErrChargeLoaded
+	}
+	s.charge.load = &charge{massKg: req.MassKg, specificHeat: req.SpecificHeat, tempC: temp, insertedAt: s.now().UTC()}
+	at := s.charge.load.insertedAt
+	return ChargeStatus{Loaded: true, MassKg: req.MassKg, SpecificHeat: req.SpecificHeat, TempC: temp, InsertedAt: &at}, nil
+}
+
+// RemoveCharge takes the load out; the next tick logs CHARGE_REMOVED. It
+// returns the load as it was removed.
+func (s *SimulatorService) RemoveCharge() (ChargeStatus, error) {
+	st := s.charge.status()
+	if !st.Loaded {
+		return st, ErrNoCharge
+	}
+	s.charge.mu.Lock()
+	s.charge.load = nil
+	s.charge.mu.Unlock()
+	return st, nil
+}
+
+// exchangeCharge logs charges inserted or removed since the last tick and
+// moves heat between the chamber and the load over elapsed seconds. Both
+// relax exponentially toward their common temperature, so the exchange
+// stays stable however long the step. Returns true if st changed.
+func (s *SimulatorService) exchangeCharge(ctx context.Context, st *models.FurnaceState, elapsed float64, now time.Time) bool {
+	s.charge.mu.Lock()
+	defer s.charge.mu.Unlock()
+	load := s.charge.load
+	if load != s.charge.logged {
+		s.admitAir(st) // the door opens either way
+		if prev := s.charge.logged; prev != nil {
+			s.logCharge(ctx, st, now, "CHARGE_REMOVED", "Charge removed", prev)
+		}
+		if load != nil {
+			s.logCharge(ctx, st, now, "CHARGE_INSERTED", "Charge inserted", load)
+		}
+		s.charge.logged = load
+	}
+	cfg := s.cfg.Charge
+	if load == nil || !cfg.enabled() || elapsed <= 0 {
+		return false
+	}
+
+	cf, cl := cfg.ChamberKJPerK, load.kjPerK()
+	mix := (cf*st.CurrentTempC + cl*load.tempC) / (cf + cl)
+	decay := math.Exp(-cfg.CouplingKWPerK * (1/cf + 1/cl) * elapsed)
+	prev := st.CurrentTempC
+	st.CurrentTempC = mix + (st.CurrentTempC-mix)*decay
+	load.tempC = mix + (load.tempC-mix)*decay
+	return st.CurrentTempC != prev
+}
+
+func (s *SimulatorService) logCharge(ctx context.Context, st *models.FurnaceState, now time.Time, typ, desc string, c *charge) {
+	s.emit(models.FurnaceEvent{
+		EventID:     s.newID(),
+		OccurredAt:  now.UTC(),
+		Type:        typ,
+		Description: fmt.Sprintf("%s: %.0f kg at %.0f °C", desc, c.massKg, c.tempC),
+		Metadata: withRunID(map[string]any{
+			"mass_kg":        c.massKg,
+			"specific_heat":  c.specificHeat,
+			"charge_temp_c":  math.Round(c.tempC*100) / 100,
+			"chamber_temp_c": st.CurrentTempC,
+		}, st.RunID),
+	})
+}
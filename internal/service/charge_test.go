@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestInsertCharge_Validates(t *testing.T) {
+	svc := NewSimulatorService(&simStateRepoStub{}, &simEventRepoStub{})
+	hot := MaxSafeC + 1
+	for _, req := range []ChargeRequest{{}, {MassKg: 100, SpecificHeat: -1}, {MassKg: 100, TempC: &hot}} {
+		if _, err := svc.InsertCharge(req); !errors.Is(err, ErrInvalidCharge) {
+			t.Errorf("%+v: err = %v, want ErrInvalidCharge", req, err)
+		}
+	}
+	if _, err := svc.RemoveCharge(); !errors.Is(err, ErrNoCharge) {
+		t.Fatalf("RemoveCharge on an empty furnace: %v", err)
+	}
+
+	st, err := svc.InsertCharge(ChargeRequest{MassKg: 100})
+	if err != nil {
+		t.Fatalf("InsertCharge: %v", err)
+	}
+	if !st.Loaded || st.SpecificHeat != DefaultSpecificHeat || st.TempC != AmbientC {
+		t.Fatalf("unexpected status %+v", st)
+	}
+	if _, err := svc.InsertCharge(ChargeRequest{MassKg: 100}); !errors.Is(err, ErrChargeLoaded) {
+		t.Fatalf("second InsertCharge: %v", err)
+	}
+}
+
+func TestStep_ColdChargeDipsAndRecovers(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	states := &simStateRepoStub{loadResp: models.FurnaceState{
+		ID: 1, Mode: ModeHeat, IsRunning: true, CurrentTempC: 850, TargetTempC: 850, RemainingSeconds: 3600, UpdatedAt: start,
+	}}
+	events := &simEventRepoStub{}
+	svc := NewSimulatorService(states, events)
+	step := func() float64 {
+		t.Helper()
+		if err := svc.Step(context.Background(), time.Second); err != nil {
+			t.Fatalf("Step: %v", err)
+		}
+		states.loadResp = states.saves[len(states.saves)-1]
+		return states.loadResp.CurrentTempC
+	}
+
+	if _, err := svc.InsertCharge(ChargeRequest{MassKg: 800}); err != nil {
+		t.Fatalf("InsertCharge: %v", err)
+	}
+	low, lowAt := 850.0, 0
+	for i := 1; i <= 1200; i++ {
+		if temp := step(); temp < low {
+			low, lowAt = temp, i
+		}
+	}
+	if low > 800 || low < 700 {
+		t.Fatalf("lowest chamber temperature %.1f, want a dip of about 100 °C", low)
+	}
+	if lowAt < 10 {
+		t.Fatalf("the dip bottomed out after %ds, want a gradual exchange", lowAt)
+	}
+	if got := states.loadResp.CurrentTempC; got < 850-SoakToleranceC {
+		t.Fatalf("chamber at %.1f after 20 min, want recovered to the target", got)
+	}
+	if load := svc.Charge(); load.TempC < 800 {
+		t.Fatalf("charge at %.1f after 20 min, want heated through", load.TempC)
+	}
+
+	if _, err := svc.RemoveCharge(); err != nil {
+		t.Fatalf("RemoveCharge: %v", err)
+	}
+	step()
+	var types []string
+	for _, ev := range events.appends {
+		if ev.Type == "CHARGE_INSERTED" || ev.Type == "CHARGE_REMOVED" {
+			types = append(types, ev.Type)
+		}
+	}
+	if len(types) != 2 || types[0] != "CHARGE_INSERTED" || types[1] != "CHARGE_REMOVED" {
+		t.Fatalf("charge events = %v", types)
+	}
+}
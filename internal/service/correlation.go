@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+type correlationKey int
+
+const (
+	requestIDKey correlationKey = iota
+	userIDKey
+)
+
+// WithRequestID returns ctx carrying the ID of the HTTP request it serves.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithUserID returns ctx carrying the authenticated user on whose behalf
+// it runs.
+func WithUserID(ctx context.Context, id int) context.Context {
+	return context.WithValue(ctx, userIDKey, id)
+}
+
+// UserID returns the user carried by ctx, or 0.
+func UserID(ctx context.Context) int {
+	id, _ := ctx.Value(userIDKey).(int)
+	return id
+}
+
+// withCorrelation adds the request and user IDs carried by ctx to meta,
+// keeping keys the caller already set. Metadata that is not a map is left
+// alone.
+func withCorrelation(ctx context.Context, meta any) any {
+	reqID, userID := RequestID(ctx), UserID(ctx)
+	if reqID == "" && userID == 0 {
+		return meta
+	}
+	var m map[string]any
+	switch v := meta.(type) {
+	case nil:
+		m = make(map[string]any, 2)
+	case map[string]any:
+		m = v
+	default:
+		return meta
+	}
+	if _, ok := m["request_id"]; !ok && reqID != "" {
+		m["request_id"] = reqID
+	}
+	if _, ok := m["user_id"]; !ok && userID != 0 {
+		m["user_id"] = userID
+	}
+	return m
+}
+
+// correlatedEvents stamps events appended while serving a request with
+// its request and user IDs, so a MODE_CHANGE can be traced back to the
+// call that caused it (GET /logs?meta.request_id=...).
+type correlatedEvents struct {
+	repository.EventRepo
+}
+
+// correlate wraps r in correlatedEvents; nil stays nil.
+func correlate(r repository.EventRepo) repository.EventRepo {
+	if r == nil {
+		return nil
+	}
+	return correlatedEvents{r}
+}
+
+func (r correlatedEvents) Append(ctx context.Context, e models.FurnaceEvent) error {
+	e.Metadata = withCorrelation(ctx, e.Metadata)
+	return r.EventRepo.Append(ctx, e)
+}
+
+func (r correlatedEvents) AppendBatch(ctx context.Context, events []models.FurnaceEvent) error {
+	if RequestID(ctx) != "" || UserID(ctx) != 0 {
+		events = append([]models.FurnaceEvent(nil), events...)
+		for i := range events {
+			events[i].Metadata = withCorrelation(ctx, events[i].Metadata)
+		}
+	}
+	return r.EventRepo.AppendBatch(ctx, events)
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestCorrelatedEvents_TagsModeChangeWithRequestAndUser(t *testing.T) {
+	erepo := &localEventRepo{}
+	fs := &FurnaceService{state: NewStateManager(&fakeStateRepo{loadResp: models.FurnaceState{ID: 1, IsRunning: true, Mode: ModeStandby}}), eventRepo: correlate(erepo)}
+
+	ctx := WithUserID(WithRequestID(context.Background(), "req-42"), 7)
+	if err := fs.SetMode(ctx, ModeParams{Mode: ModeCool}); err != nil {
+		t.Fatalf("SetMode: %v", err)
+	}
+	if len(erepo.events) != 1 || erepo.events[0].Type != "MODE_CHANGE" {
+		t.Fatalf("events = %+v, want one MODE_CHANGE", erepo.events)
+	}
+	meta := erepo.events[0].Metadata.(map[string]any)
+	if meta["request_id"] != "req-42" || meta["user_id"] != 7 {
+		t.Fatalf("metadata = %v, want request_id req-42 and user_id 7", meta)
+	}
+}
+
+func TestCorrelatedEvents_KeepsCallerKeysAndUntaggedContexts(t *testing.T) {
+	erepo := &localEventRepo{}
+	events := correlate(erepo)
+
+	ctx := WithUserID(WithRequestID(context.Background(), "req-1"), 3)
+	_ = events.Append(ctx, models.FurnaceEvent{Type: "MAINTENANCE_DONE", Metadata: map[string]any{"user_id": 9}})
+	_ = events.AppendBatch(context.Background(), []models.FurnaceEvent{{Type: "TELEMETRY"}})
+
+	if got := erepo.events[0].Metadata.(map[string]any); got["user_id"] != 9 || got["request_id"] != "req-1" {
+		t.Fatalf("metadata = %v, want the caller's user_id kept and the request ID added", got)
+	}
+	if got := erepo.events[1].Metadata; got != nil {
+		t.Fatalf("event outside a request got metadata %v", got)
+	}
+}
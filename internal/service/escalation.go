@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// DefaultEscalationInterval is how often the open incident is checked for
+// escalation when EscalationConfig.Interval is 0.
+const DefaultEscalationInterval = 15 * time.Second
+
+// DefaultCriticalAlarms are the alarm codes that escalate when
+// EscalationConfig.Critical is empty.
+var DefaultCriticalAlarms = []string{AlarmOverheat, AlarmRateOfRise, AlarmO2High}
+
+// ErrInvalidEscalation is returned for an escalation chain that cannot be
+// followed.
+var ErrInvalidEscalation = errors.New("invalid escalation config")
+
+// EscalationTier is one step of the escalation chain.
+type EscalationTier struct {
+	Name string `mapstructure:"name"`
+	// After is how long after the incident started the tier is paged if
+	// nobody has acknowledged it; it grows along the chain.
+	After     time.Duration `mapstructure:"after"`
+	NotifyURL string        `mapstructure:"notify_url"` // the escalation is POSTed here as JSON
+	Users     []string      `mapstructure:"users"`      // passed on for the gateway to page
+}
+
+// EscalationConfig configures the escalation of unacknowledged critical
+// incidents. Without tiers nothing escalates.
+type EscalationConfig struct {
+	Tiers    []EscalationTier `mapstructure:"tiers"`
+	Critical []string         `mapstructure:"critical"` // alarm codes that escalate; DefaultCriticalAlarms when empty
+	Interval time.Duration    `mapstructure:"interval"` // between checks; DefaultEscalationInterval when 0
+	Timeout  time.Duration    `mapstructure:"timeout"`  // per notification; DefaultNotifyTimeout when 0
+}
+
+// Validate rejects tiers without a name or an absolute http(s) URL, and
+// delays that do not grow along the chain.
+func (c EscalationConfig) Validate() error {
+	if c.Interval < 0 || c.Timeout < 0 {
+		return fmt.Errorf("%w: interval and timeout must be >= 0", ErrInvalidEscalation)
+	}
+	var prev time.Duration
+	for i, tier := range c.Tiers {
+		u, err := url.Parse(tier.NotifyURL)
+		switch {
+		case strings.TrimSpace(tier.Name) == "":
+			return fmt.Errorf("%w: tier %d needs a name", ErrInvalidEscalation, i+1)
+		case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+			return fmt.Errorf("%w: tier %q needs an absolute http(s) notify_url", ErrInvalidEscalation, tier.Name)
+		case tier.After <= prev:
+			return fmt.Errorf("%w: tier %q must come after %s", ErrInvalidEscalation, tier.Name, prev)
+		}
+		prev = tier.After
+	}
+	return nil
+}
+
+// EscalationService pages the tiers of the escalation chain in turn while
+// the open incident has a critical alarm and nobody acknowledges it. Each
+// step is logged as an ESCALATION event.
+type EscalationService struct {
+	incidents repository.IncidentRepo
+	events    repository.EventRepo
+	cfg       EscalationConfig
+	notifiers []EscalationNotifier // one per tier
+	now       func() time.Time
+	newID     func() string
+
+	// escalation state, owned by Run
+	incident int64 // incident the levels belong to
+	level    int   // tiers paged so far
+}
+
+func NewEscalationService(incidents repository.IncidentRepo, events repository.EventRepo, cfg EscalationConfig) *EscalationService {
+	if len(cfg.Critical) == 0 {
+		cfg.Critical = DefaultCriticalAlarms
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultEscalationInterval
+	}
+	s := &EscalationService{incidents: incidents, events: events, cfg: cfg, now: time.Now, newID: uuid.NewString}
+	for _, tier := range cfg.Tiers {
+		s.notifiers = append(s.notifiers, NewHTTPNotifier(tier.NotifyURL, cfg.Timeout))
+	}
+	return s
+}
+
+// Run checks the open incident every Interval until ctx is canceled.
+func (s *EscalationService) Run(ctx context.Context) {
+	if s.incidents == nil || len(s.cfg.Tiers) == 0 {
+		<-ctx.Done()
+		return
+	}
+	t := time.NewTicker(s.cfg.Interval)
+	defer t.Stop()
+	for {
+		s.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// check pages every tier whose delay the open incident has outlasted
+// without an acknowledgement. Failed reads are retried on the next check.
+func (s *EscalationService) check(ctx context.Context) {
+	inc, err := s.incidents.Current(ctx)
+	if err != nil || inc.ID == 0 || inc.AckedAt != nil || !s.critical(inc.AlarmCodes) {
+		return
+	}
+	if inc.ID != s.incident {
+		level, err := s.escalated(ctx, inc)
+		if err != nil {
+			return
+		}
+		s.incident, s.level = inc.ID, level
+	}
+	unacked := s.now().Sub(inc.StartedAt)
+	for s.level < len(s.cfg.Tiers) && unacked >= s.cfg.Tiers[s.level].After {
+		s.escalate(ctx, inc, unacked)
+		s.level++
+	}
+}
+
+// critical reports whether any of codes escalates.
+func (s *EscalationService) critical(codes []string) bool {
+	for _, code := range codes {
+		for _, c := range s.cfg.Critical {
+			if strings.EqualFold(code, c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// escalated returns how many tiers were already paged for inc, from the
+// ESCALATION events logged since it started, so a restart does not page
+// them again.
+func (s *EscalationService) escalated(ctx context.Context, inc models.Incident) (int, error) {
+	if s.events == nil {
+		return 0, nil
+	}
+	// occurred_at is stored to the second; start a second early
+	from := inc.StartedAt.Truncate(time.Second).Add(-time.Second)
+	events, err := s.events.Query(ctx, repository.EventQuery{Type: "ESCALATION", From: from})
+	if err != nil {
+		return 0, err
+	}
+	level := 0
+	for _, ev := range events {
+		meta, _ := ev.Metadata.(map[string]any)
+		if fmt.Sprint(meta["incident_id"]) != fmt.Sprint(inc.ID) {
+			continue
+		}
+		if l, err := strconv.Atoi(fmt.Sprint(meta["level"])); err == nil && l > level {
+			level = l
+		}
+	}
+	return level, nil
+}
+
+// escalate pages the next tier for inc and logs an ESCALATION event, also
+// when the notification failed.
+func (s *EscalationService) escalate(ctx context.Context, inc models.Incident, unacked time.Duration) {
+	tier := s.cfg.Tiers[s.level]
+	e := models.Escalation{
+		IncidentID: inc.ID,
+		Level:      s.level + 1,
+		Tier:       tier.Name,
+		Users:      tier.Users,
+		AlarmCodes: inc.AlarmCodes,
+		StartedAt:  inc.StartedAt,
+		UnackedS:   unacked.Seconds(),
+		RunID:      inc.RunID,
+		At:         s.now().UTC(),
+	}
+	meta := map[string]any{
+		"incident_id": inc.ID,
+		"level":       e.Level,
+		"tier":        tier.Name,
+		"alarm_codes": inc.AlarmCodes,
+		"notified":    true,
+	}
+	if len(tier.Users) > 0 {
+		meta["users"] = tier.Users
+	}
+	if err := s.notifiers[s.level].NotifyEscalation(ctx, e); err != nil {
+		meta["notified"], meta["notify_error"] = false, err.Error()
+	}
+	if s.events != nil {
+		_ = s.events.Append(ctx, models.FurnaceEvent{
+			EventID:    s.newID(),
+			OccurredAt: e.At,
+			Type:       "ESCALATION",
+			Description: fmt.Sprintf("Incident %d unacknowledged for %s: escalated to %s (level %d)",
+				inc.ID, unacked.Round(time.Second), tier.Name, e.Level),
+			Metadata: withRunID(meta, inc.RunID),
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+type escalationNotifierStub struct {
+	sent []models.Escalation
+	err  error
+}
+
+func (n *escalationNotifierStub) NotifyEscalation(ctx context.Context, e models.Escalation) error {
+	n.sent = append(n.sent, e)
+	return n.err
+}
+
+func TestEscalationService_PagesTiersInTurn(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	incidents := newIncidentRepoStub()
+	incidents.open = models.Incident{ID: 3, StartedAt: start, AlarmCodes: []string{"STUCK_SENSOR", "OVERHEAT"}, RunID: "r1"}
+	events := repository.NewInMemory().EventRepo
+	cfg := EscalationConfig{Tiers: []EscalationTier{
+		{Name: "shift-lead", After: 5 * time.Minute, NotifyURL: "https://pager.example.com/shift", Users: []string{"alice"}},
+		{Name: "manager", After: 15 * time.Minute, NotifyURL: "https://pager.example.com/manager"},
+	}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	shift, manager := &escalationNotifierStub{}, &escalationNotifierStub{err: errors.New("pager down")}
+	newService := func(now time.Time) *EscalationService {
+		s := NewEscalationService(incidents, events, cfg)
+		s.notifiers = []EscalationNotifier{shift, manager}
+		s.now = func() time.Time { return now }
+		return s
+	}
+
+	newService(start.Add(4 * time.Minute)).check(ctx)
+	if len(shift.sent) != 0 {
+		t.Fatalf("paged before the first delay: %+v", shift.sent)
+	}
+	s := newService(start.Add(6 * time.Minute))
+	s.check(ctx)
+	s.check(ctx)
+	if len(shift.sent) != 1 || shift.sent[0].Level != 1 || shift.sent[0].Users[0] != "alice" || shift.sent[0].UnackedS != 360 {
+		t.Fatalf("first tier: %+v", shift.sent)
+	}
+
+	// a restart does not page the first tier again
+	s = newService(start.Add(20 * time.Minute))
+	s.check(ctx)
+	if len(shift.sent) != 1 || len(manager.sent) != 1 || manager.sent[0].Tier != "manager" {
+		t.Fatalf("second tier: shift %+v, manager %+v", shift.sent, manager.sent)
+	}
+	logged, _ := events.Query(ctx, repository.EventQuery{Type: "ESCALATION"})
+	if len(logged) != 2 {
+		t.Fatalf("expected an event per step, got %+v", logged)
+	}
+	meta, _ := logged[1].Metadata.(map[string]any)
+	if meta["tier"] != "manager" || meta["notified"] != false || meta["notify_error"] != "pager down" || meta["run_id"] != "r1" {
+		t.Fatalf("second step metadata: %v", meta)
+	}
+}
+
+func TestEscalationService_SkipsAckedAndMinorIncidents(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	incidents := newIncidentRepoStub()
+	notifier := &escalationNotifierStub{}
+	s := NewEscalationService(incidents, nil, EscalationConfig{Tiers: []EscalationTier{{Name: "t1", After: time.Minute, NotifyURL: "http://pager"}}})
+	s.notifiers = []EscalationNotifier{notifier}
+	s.now = func() time.Time { return start.Add(time.Hour) }
+
+	incidents.open = models.Incident{ID: 1, StartedAt: start, AlarmCodes: []string{"STUCK_SENSOR"}}
+	s.check(ctx)
+	acked := start.Add(30 * time.Second)
+	incidents.open = models.Incident{ID: 2, StartedAt: start, AlarmCodes: []string{"OVERHEAT"}, AckedAt: &acked}
+	s.check(ctx)
+	if len(notifier.sent) != 0 {
+		t.Fatalf("escalated %+v", notifier.sent)
+	}
+}
+
+func TestEscalationConfig_Validate(t *testing.T) {
+	bad := []EscalationConfig{
+		{Tiers: []EscalationTier{{After: time.Minute, NotifyURL: "http://pager"}}},
+		{Tiers: []EscalationTier{{Name: "t1", After: time.Minute, NotifyURL: "pager"}}},
+		{Tiers: []EscalationTier{{Name: "t1", NotifyURL: "http://pager"}}},
+		{Tiers: []EscalationTier{
+			{Name: "t1", After: 5 * time.Minute, NotifyURL: "http://pager"},
+			{Name: "t2", After: 5 * time.Minute, NotifyURL: "http://pager"},
+		}},
+		{Interval: -time.Second},
+	}
+	for _, cfg := range bad {
+		if err := cfg.Validate(); !errors.Is(err, ErrInvalidEscalation) {
+			t.Errorf("%+v: err = %v, want ErrInvalidEscalation", cfg, err)
+		}
+	}
+	if err := (EscalationConfig{}).Validate(); err != nil {
+		t.Fatalf("empty chain: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// eventType lists a logged event type with the struct its metadata
+// follows; nil meta means the event carries only the common fields.
+type eventType struct {
+	typ, severity, desc string
+	meta                any
+}
+
+// eventTypes are the event types this service logs, in the order the
+// catalog lists them. Events imported from CSV keep their source's types
+// and are not covered.
+var eventTypes = []eventType{
+	{"START", models.SeverityInfo, "The furnace was started.", nil},
+	{"STOP", models.SeverityInfo, "The furnace was stopped; the active run ends.", nil},
+	{"MODE_CHANGE", models.SeverityInfo, "The mode was changed by a command, or to COOL when a soak ended.", models.ModeChangeMeta{}},
+	{"ATMOSPHERE_CHANGE", models.SeverityInfo, "The protective gas or its flow was changed.", models.AtmosphereChangeMeta{}},
+	{"ERROR", models.SeverityCritical, "An alarm was raised or a fault became active.", models.ErrorMeta{}},
+	{"ALARM_CLEARED", models.SeverityInfo, "An alarm condition ended.", models.AlarmClearedMeta{}},
+	{"FAULT_CLEARED", models.SeverityInfo, "An injected fault was cleared.", models.FaultClearedMeta{}},
+	{"SAFETY_TRIP", models.SeverityCritical, "An alarm shut the furnace down automatically.", models.SafetyTripMeta{}},
+	{"SOAK_UNSTABLE", models.SeverityWarning, "The temperature strayed from the target for too much of the soak.", models.SoakUnstableMeta{}},
+	{"SEQUENCE_STARTED", models.SeverityInfo, "A sequence of mode steps was submitted and its first step set.", models.SequenceMeta{}},
+	{"SEQUENCE_COMPLETED", models.SeverityInfo, "The last step of a sequence ended.", models.SequenceMeta{}},
+	{"SEQUENCE_RESUMED", models.SeverityInfo, "A sequence left running by a previous process carried on with its step after a restart.", models.SequenceMeta{}},
+	{"SEQUENCE_ABORTED", models.SeverityWarning, "A sequence ended early: the furnace stopped, another command took over or a step could not be set.", models.SequenceMeta{}},
+	{"KEEP_WARM_ENGAGED", models.SeverityInfo, "STANDBY started holding the keep-warm setpoint.", models.KeepWarmEngagedMeta{}},
+	{"KEEP_WARM_DISENGAGED", models.SeverityInfo, "Keep-warm ended because the furnace left STANDBY or stopped.", models.KeepWarmDisengagedMeta{}},
+	{"CHARGE_INSERTED", models.SeverityInfo, "A load was put into the chamber.", models.ChargeMeta{}},
+	{"CHARGE_REMOVED", models.SeverityInfo, "The load was taken out of the chamber.", models.ChargeMeta{}},
+	{"ALERT", models.SeverityWarning, "An alert rule fired.", models.AlertMeta{}},
+	{"ESCALATION", models.SeverityCritical, "An unacknowledged incident was escalated to the next tier.", models.EscalationMeta{}},
+	{"MAINTENANCE_DUE", models.SeverityWarning, "Heater wear reached the maintenance interval.", models.MaintenanceDueMeta{}},
+	{"MAINTENANCE_OVERDUE", models.SeverityWarning, "A maintenance task fell due.", models.MaintenanceOverdueMeta{}},
+	{"MAINTENANCE_DONE", models.SeverityInfo, "A maintenance task was completed.", models.MaintenanceDoneMeta{}},
+	{"BOOT", models.SeverityInfo, "The simulator started after downtime.", models.BootMeta{}},
+	{"SHUTDOWN", models.SeverityInfo, "The simulator stopped and flushed the state.", models.ShutdownMeta{}},
+	{"LOG_PURGED", models.SeverityInfo, "Old events were removed from the log.", models.LogPurgedMeta{}},
+	{"BACKUP", models.SeverityInfo, "The database was backed up.", models.BackupMeta{}},
+	{"INTERNAL_ERROR", models.SeverityCritical, "A request handler panicked; the request was answered with 500.", models.InternalErrorMeta{}},
+}
+
+// EventCatalog describes the logged event types and their metadata. The
+// field definitions are read from the metadata structs in models, so the
+// catalog cannot drift from them.
+func EventCatalog() models.EventCatalog {
+	cat := models.EventCatalog{
+		SchemaVersion: models.SchemaVersion,
+		Common:        eventFields(models.CommonEventMeta{}),
+		Types:         make([]models.EventSchema, 0, len(eventTypes)),
+	}
+	for _, t := range eventTypes {
+		cat.Types = append(cat.Types, models.EventSchema{
+			Type:        t.typ,
+			Severity:    t.severity,
+			Description: t.desc,
+			Fields:      eventFields(t.meta),
+		})
+	}
+	return cat
+}
+
+// severityRank orders the severities of the catalog.
+var severityRank = map[string]int{models.SeverityInfo: 1, models.SeverityWarning: 2, models.SeverityCritical: 3}
+
+// typesAtLeast returns the catalogued event types rated severity or
+// above.
+func typesAtLeast(severity string) ([]string, error) {
+	least, ok := severityRank[strings.ToLower(strings.TrimSpace(severity))]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q; use info, warning or critical", ErrInvalidSeverity, severity)
+	}
+	var out []string
+	for _, t := range eventTypes {
+		if severityRank[t.severity] >= least {
+			out = append(out, t.typ)
+		}
+	}
+	return out, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// eventFields describes the fields of the metadata struct meta.
+func eventFields(meta any) []models.EventField {
+	fields := []models.EventField{}
+	if meta == nil {
+		return fields
+	}
+	t := reflect.TypeOf(meta)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		field := models.EventField{
+			Name:        name,
+			Required:    !strings.Contains(opts, "omitempty"),
+			Description: f.Tag.Get("doc"),
+		}
+		field.Type, field.Format = jsonType(f.Type)
+		if f.Type.Kind() == reflect.Slice {
+			field.Items, _ = jsonType(f.Type.Elem())
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// jsonType returns the JSON type and string format t encodes as.
+func jsonType(t reflect.Type) (typ, format string) {
+	if t == timeType {
+		return "string", "date-time"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string", ""
+	case reflect.Bool:
+		return "boolean", ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer", ""
+	case reflect.Float32, reflect.Float64:
+		return "number", ""
+	case reflect.Slice, reflect.Array:
+		return "array", ""
+	}
+	return "object", ""
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// TestEventCatalog_ListsEveryLoggedType scans this package for event types
+// it logs, so a new type cannot be added without describing it.
+func TestEventCatalog_ListsEveryLoggedType(t *testing.T) {
+	cat := EventCatalog()
+	listed := map[string]bool{}
+	for _, s := range cat.Types {
+		if listed[s.Type] {
+			t.Errorf("%s listed twice", s.Type)
+		}
+		listed[s.Type] = true
+	}
+
+	literal := regexp.MustCompile(`(?:Type:\s*|logTask\(ctx, t, |logCharge\(ctx, st, now, )"([A-Z_]+)"`)
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := 0
+	for _, f := range files {
+		if strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range literal.FindAllStringSubmatch(string(src), -1) {
+			found++
+			if !listed[m[1]] {
+				t.Errorf("%s logs %s, which the catalog does not list", f, m[1])
+			}
+		}
+	}
+	if found < len(cat.Types) {
+		t.Fatalf("found %d logged types in the source, fewer than the %d listed", found, len(cat.Types))
+	}
+}
+
+func TestEventCatalog_FieldsFromStructs(t *testing.T) {
+	cat := EventCatalog()
+	if cat.SchemaVersion != models.SchemaVersion || len(cat.Common) != 3 {
+		t.Fatalf("catalog = %+v", cat)
+	}
+	field := func(typ, name string) models.EventField {
+		t.Helper()
+		for _, s := range cat.Types {
+			if s.Type != typ {
+				continue
+			}
+			for _, f := range s.Fields {
+				if f.Name == name {
+					return f
+				}
+			}
+		}
+		t.Fatalf("%s has no field %s", typ, name)
+		return models.EventField{}
+	}
+
+	if f := field("MODE_CHANGE", "target_temp_c"); f.Type != "number" || f.Required || f.Description == "" {
+		t.Errorf("target_temp_c = %+v", f)
+	}
+	if f := field("ESCALATION", "alarm_codes"); f.Type != "array" || f.Items != "string" || !f.Required {
+		t.Errorf("alarm_codes = %+v", f)
+	}
+	if f := field("BOOT", "last_update"); f.Type != "string" || f.Format != "date-time" {
+		t.Errorf("last_update = %+v", f)
+	}
+	if f := field("LOG_PURGED", "deleted"); f.Type != "integer" {
+		t.Errorf("deleted = %+v", f)
+	}
+}
+
+// TestEventCatalog_MatchesSimulatorEvents checks the metadata the simulator
+// actually logs over a heat cycle against the catalog.
+func TestEventCatalog_MatchesSimulatorEvents(t *testing.T) {
+	fields := map[string]map[string]models.EventField{}
+	cat := EventCatalog()
+	for _, s := range cat.Types {
+		fields[s.Type] = map[string]models.EventField{}
+		for _, f := range append(s.Fields, cat.Common...) {
+			fields[s.Type][f.Name] = f
+		}
+	}
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	states := &simStateRepoStub{loadResp: models.FurnaceState{
+		ID: 1, Mode: ModeHeat, IsRunning: true, CurrentTempC: 25, MeasuredTempC: 25, TargetTempC: 100,
+		RemainingSeconds: 5, RunID: "run-1", O2PPM: AirO2PPM, UpdatedAt: start,
+	}}
+	events := &simEventRepoStub{}
+	cfg := DefaultSimConfig()
+	cfg.KeepWarm = KeepWarmConfig{SetpointC: 60}
+	svc := NewSimulatorServiceWithConfig(states, events, nil, nil, nil, cfg)
+	for i := 0; i < 60; i++ {
+		if err := svc.Step(context.Background(), time.Second); err != nil {
+			t.Fatalf("Step: %v", err)
+		}
+	}
+	command(t, svc, func(st *models.FurnaceState) { st.Mode = ModeStandby })
+	for i := 0; i < 5; i++ {
+		if err := svc.Step(context.Background(), time.Second); err != nil {
+			t.Fatalf("Step: %v", err)
+		}
+	}
+	command(t, svc, func(st *models.FurnaceState) { st.IsRunning = false })
+	if err := svc.Step(context.Background(), time.Second); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+
+	seen := map[string]bool{}
+	for _, e := range events.appends {
+		seen[e.Type] = true
+		want, ok := fields[e.Type]
+		if !ok {
+			t.Errorf("%s is not in the catalog", e.Type)
+			continue
+		}
+		meta, _ := e.Metadata.(map[string]any)
+		for k := range meta {
+			if _, ok := want[k]; !ok {
+				t.Errorf("%s carries %s, which the catalog does not describe", e.Type, k)
+			}
+		}
+		for name, f := range want {
+			if _, ok := meta[name]; f.Required && !ok {
+				t.Errorf("%s lacks required field %s", e.Type, name)
+			}
+		}
+	}
+	for _, typ := range []string{"MODE_CHANGE", "KEEP_WARM_ENGAGED", "KEEP_WARM_DISENGAGED"} {
+		if !seen[typ] {
+			t.Errorf("no %s logged; events = %+v", typ, events.appends)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+// MaxCommentLength caps the characters of one event comment.
+const MaxCommentLength = 2000
+
+var (
+	// ErrInvalidComment is returned for a blank or overlong comment.
+	ErrInvalidComment = errors.New("invalid comment")
+	// ErrEventNotFound is returned when no event exists for an ID.
+	ErrEventNotFound = errors.New("event not found")
+
+	errNoCommentRepo = errors.New("event comments need a comment repository")
+)
+
+// AddComment attaches text to the event as the given user, for example
+// the cause of an alarm for the next shift.
+func (s *EventLogService) AddComment(ctx context.Context, eventID string, userID int, text string) (models.EventComment, error) {
+	text = strings.TrimSpace(text)
+	switch {
+	case text == "":
+		return models.EventComment{}, fmt.Errorf("%w: text is required", ErrInvalidComment)
+	case utf8.RuneCountInString(text) > MaxCommentLength:
+		return models.EventComment{}, fmt.Errorf("%w: text is longer than %d characters", ErrInvalidComment, MaxCommentLength)
+	case s.comments == nil:
+		return models.EventComment{}, errNoCommentRepo
+	}
+	c := models.EventComment{EventID: eventID, UserID: userID, Text: text, CreatedAt: s.now().UTC()}
+	id, err := s.comments.AddComment(ctx, c)
+	if errors.Is(err, repository.ErrEventNotFound) {
+		return models.EventComment{}, ErrEventNotFound
+	}
+	if err != nil {
+		return models.EventComment{}, err
+	}
+	c.ID = id
+	return c, nil
+}
+
+// withComments fills in the comments on events.
+func (s *EventLogService) withComments(ctx context.Context, events []models.FurnaceEvent) error {
+	if s.comments == nil || len(events) == 0 {
+		return nil
+	}
+	ids := make([]string, len(events))
+	for i, ev := range events {
+		ids[i] = ev.EventID
+	}
+	byEvent, err := s.comments.CommentsFor(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range events {
+		events[i].Comments = byEvent[events[i].EventID]
+	}
+	return nil
+}
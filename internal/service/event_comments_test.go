@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+// commentRepoStub keeps comments in memory; events lists the IDs in the log.
+type commentRepoStub struct {
+	events   []string
+	comments []models.EventComment
+	gotIDs   []string
+}
+
+func (r *commentRepoStub) AddComment(ctx context.Context, c models.EventComment) (int64, error) {
+	for _, id := range r.events {
+		if id == c.EventID {
+			c.ID = int64(len(r.comments) + 1)
+			r.comments = append(r.comments, c)
+			return c.ID, nil
+		}
+	}
+	return 0, repository.ErrEventNotFound
+}
+
+func (r *commentRepoStub) CommentsFor(ctx context.Context, eventIDs []string) (map[string][]models.EventComment, error) {
+	r.gotIDs = eventIDs
+	out := make(map[string][]models.EventComment)
+	for _, c := range r.comments {
+		out[c.EventID] = append(out[c.EventID], c)
+	}
+	return out, nil
+}
+
+func TestEventLogService_AddComment(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	repo := &commentRepoStub{events: []string{"e1"}}
+	svc := NewEventLogService(&fakeEventRepo{})
+	svc.comments = repo
+	svc.now = func() time.Time { return at }
+	ctx := context.Background()
+
+	c, err := svc.AddComment(ctx, "e1", 7, "  overheat was caused by the door left open\n")
+	if err != nil {
+		t.Fatalf("AddComment() error = %v", err)
+	}
+	want := models.EventComment{ID: 1, EventID: "e1", UserID: 7, Text: "overheat was caused by the door left open", CreatedAt: at}
+	if c != want {
+		t.Fatalf("AddComment() = %+v, want %+v", c, want)
+	}
+	if _, err := svc.AddComment(ctx, "gone", 7, "note"); !errors.Is(err, ErrEventNotFound) {
+		t.Fatalf("missing event: err %v, want ErrEventNotFound", err)
+	}
+	for _, text := range []string{" ", strings.Repeat("ä", MaxCommentLength+1)} {
+		if _, err := svc.AddComment(ctx, "e1", 7, text); !errors.Is(err, ErrInvalidComment) {
+			t.Fatalf("text of %d characters: err %v, want ErrInvalidComment", len([]rune(text)), err)
+		}
+	}
+	if len(repo.comments) != 1 {
+		t.Fatalf("stored %d comments, want 1", len(repo.comments))
+	}
+}
+
+func TestEventLogService_ListReturnsComments(t *testing.T) {
+	t.Parallel()
+
+	repo := &commentRepoStub{
+		events:   []string{"e1", "e2"},
+		comments: []models.EventComment{{ID: 1, EventID: "e2", UserID: 7, Text: "sensor replaced"}},
+	}
+	svc := NewEventLogService(&fakeEventRepo{events: []models.FurnaceEvent{{EventID: "e1"}, {EventID: "e2"}}})
+	svc.comments = repo
+
+	events, err := svc.List(context.Background(), LogFilter{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(repo.gotIDs) != 2 || events[0].Comments != nil || len(events[1].Comments) != 1 || events[1].Comments[0].Text != "sensor replaced" {
+		t.Fatalf("List() = %+v after asking for %v", events, repo.gotIDs)
+	}
+}
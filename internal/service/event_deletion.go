@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"controlling_furnace/internal/repository"
+)
+
+// MaxDeleteReasonLength caps the characters of the reason for deleting an
+// event.
+const MaxDeleteReasonLength = 500
+
+var (
+	// ErrInvalidDeletion is returned for a blank or overlong reason.
+	ErrInvalidDeletion = errors.New("invalid deletion")
+	// ErrEventDeleted is returned for an event that was deleted before.
+	ErrEventDeleted = errors.New("event already deleted")
+
+	errNoDeletionRepo = errors.New("deleting events needs a deletion repository")
+)
+
+// DeleteEvent soft-deletes the event as the given user, for example when it
+// holds mistaken data. The event stays in the database and in audit
+// exports; List, Page and Stream return it only with
+// LogFilter.IncludeDeleted.
+func (s *EventLogService) DeleteEvent(ctx context.Context, eventID string, userID int, reason string) (err error) {
+	ctx, span := startSpan(ctx, "EventLog.DeleteEvent")
+	defer func() { endSpan(span, err) }()
+
+	reason = strings.TrimSpace(reason)
+	switch {
+	case reason == "":
+		return fmt.Errorf("%w: reason is required", ErrInvalidDeletion)
+	case utf8.RuneCountInString(reason) > MaxDeleteReasonLength:
+		return fmt.Errorf("%w: reason is longer than %d characters", ErrInvalidDeletion, MaxDeleteReasonLength)
+	case s.deletions == nil:
+		return errNoDeletionRepo
+	}
+	err = s.deletions.SoftDelete(ctx, eventID, repository.EventDeletion{At: s.now().UTC(), By: userID, Reason: reason})
+	switch {
+	case errors.Is(err, repository.ErrEventNotFound):
+		return ErrEventNotFound
+	case errors.Is(err, repository.ErrEventDeleted):
+		return ErrEventDeleted
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+func TestEventLogService_DeleteEvent(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	repos := repository.NewInMemory()
+	ctx := context.Background()
+	if err := repos.EventRepo.Append(ctx, models.FurnaceEvent{EventID: "e1", OccurredAt: at, Type: "NOTE"}); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewEventLogService(repos.EventRepo)
+	svc.deletions = repos.Deletions
+	svc.now = func() time.Time { return at.Add(time.Hour) }
+
+	for _, reason := range []string{" ", strings.Repeat("x", MaxDeleteReasonLength+1)} {
+		if err := svc.DeleteEvent(ctx, "e1", 3, reason); !errors.Is(err, ErrInvalidDeletion) {
+			t.Fatalf("reason of %d characters: err %v, want ErrInvalidDeletion", len(reason), err)
+		}
+	}
+	if err := svc.DeleteEvent(ctx, "e1", 3, " test entry\n"); err != nil {
+		t.Fatalf("DeleteEvent() error = %v", err)
+	}
+	if err := svc.DeleteEvent(ctx, "e1", 3, "again"); !errors.Is(err, ErrEventDeleted) {
+		t.Fatalf("deleting again: err %v, want ErrEventDeleted", err)
+	}
+	if err := svc.DeleteEvent(ctx, "gone", 3, "test entry"); !errors.Is(err, ErrEventNotFound) {
+		t.Fatalf("missing event: err %v, want ErrEventNotFound", err)
+	}
+
+	if events, err := svc.List(ctx, LogFilter{}); err != nil || len(events) != 0 {
+		t.Fatalf("List() = %v, %v; want the deleted event left out", events, err)
+	}
+	events, err := svc.List(ctx, LogFilter{IncludeDeleted: true})
+	if err != nil || len(events) != 1 {
+		t.Fatalf("List(IncludeDeleted) = %v, %v", events, err)
+	}
+	if ev := events[0]; ev.DeletedAt == nil || !ev.DeletedAt.Equal(at.Add(time.Hour)) || ev.DeletedBy != 3 || ev.DeleteReason != "test entry" {
+		t.Fatalf("deleted event = %+v", ev)
+	}
+}
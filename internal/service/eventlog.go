@@ -3,25 +3,90 @@ package service
 import (
 	"context"
 	"controlling_furnace/internal/models"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"controlling_furnace/internal/repository"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type EventLogService struct {
 	eventRepo repository.EventRepo
+	chain     repository.EventChainRepo    // optional; nothing to verify when nil
+	stream    repository.EventStreamRepo   // optional; Stream buffers through List when nil
+	comments  repository.EventCommentRepo  // optional; events are listed without comments when nil
+	deletions repository.EventDeletionRepo // optional; events cannot be deleted when nil
+	now       func() time.Time
 }
 
 func NewEventLogService(eventRepo repository.EventRepo) *EventLogService {
-	return &EventLogService{eventRepo: eventRepo}
+	return &EventLogService{eventRepo: eventRepo, now: time.Now}
 }
 
 var (
 	errInvalidTimeRange = errors.New("invalid time range: From must be <= To")
+	errNoEventPager     = errors.New("paged log listing needs an event stream repository")
+)
+
+// Limits for paged log listings.
+const (
+	DefaultLogLimit = 100
+	MaxLogLimit     = 1000
 )
 
+// ErrInvalidCursor is returned by Page for a cursor that is malformed or
+// was issued for the other order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInvalidMetaFilter is returned for a metadata filter that cannot be
+// parsed, and for more than MaxMetaFilters of them.
+var ErrInvalidMetaFilter = errors.New("invalid metadata filter")
+
+// ErrInvalidSeverity is returned for a severity filter other than info,
+// warning or critical.
+var ErrInvalidSeverity = errors.New("invalid severity")
+
+// MaxMetaFilters caps the metadata filters of one log query.
+const MaxMetaFilters = 8
+
+// metaKeyRe matches a dotted path of metadata keys.
+var metaKeyRe = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+
+// ParseMetaFilter parses a comparison such as "to=COOL", "temp_c>1000" or
+// "limits.max_c<=1200". The first operator splits key from value, so the
+// value may itself contain operator characters.
+func ParseMetaFilter(expr string) (MetaFilter, error) {
+	i := strings.IndexAny(expr, "=!<>")
+	if i < 0 {
+		return MetaFilter{}, fmt.Errorf("%w: %q has no operator", ErrInvalidMetaFilter, expr)
+	}
+	op := expr[i : i+1]
+	if i+1 < len(expr) && expr[i+1] == '=' && op != "=" {
+		op += "="
+	}
+	f := MetaFilter{Key: strings.TrimSpace(expr[:i]), Op: op, Value: strings.TrimSpace(expr[i+len(op):])}
+	return f, validateMetaFilter(f)
+}
+
+func validateMetaFilter(f MetaFilter) error {
+	switch {
+	case !metaKeyRe.MatchString(f.Key):
+		return fmt.Errorf("%w: key %q must be dot-separated letters, digits and underscores", ErrInvalidMetaFilter, f.Key)
+	case !slices.Contains([]string{"=", "!=", "<", "<=", ">", ">="}, f.Op):
+		return fmt.Errorf("%w: operator %q must be one of =, !=, <, <=, >, >=", ErrInvalidMetaFilter, f.Op)
+	case f.Value == "":
+		return fmt.Errorf("%w: %s%s needs a value", ErrInvalidMetaFilter, f.Key, f.Op)
+	}
+	return nil
+}
+
 // normalizeToUTC returns t in UTC, preserving zero time values.
 func normalizeToUTC(t time.Time) time.Time {
 	if t.IsZero() {
@@ -35,6 +100,18 @@ func normalizeEventType(s string) string {
 	return strings.TrimSpace(strings.ToUpper(s))
 }
 
+// normalizeEventTypes normalizes each type filter, dropping blanks and
+// duplicates.
+func normalizeEventTypes(types []string) []string {
+	var out []string
+	for _, t := range types {
+		if t = normalizeEventType(t); t != "" && !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
 // normalizeAndValidateFilter prepares query parameters and validates the time range.
 func normalizeAndValidateFilter(f LogFilter) (time.Time, time.Time, string, error) {
 	from := normalizeToUTC(f.From)
@@ -48,10 +125,263 @@ func normalizeAndValidateFilter(f LogFilter) (time.Time, time.Time, string, erro
 	return from, to, eventType, nil
 }
 
-func (s *EventLogService) List(ctx context.Context, f LogFilter) ([]models.FurnaceEvent, error) {
-	from, to, typ, err := normalizeAndValidateFilter(f)
+func (s *EventLogService) List(ctx context.Context, f LogFilter) (events []models.FurnaceEvent, err error) {
+	ctx, span := startSpan(ctx, "EventLog.List", logFilterAttrs(f)...)
+	defer func() {
+		span.SetAttributes(attribute.Int("events.count", len(events)))
+		endSpan(span, err)
+	}()
+
+	q, err := eventQuery(f)
 	if err != nil {
 		return nil, err
 	}
-	return s.eventRepo.List(ctx, from, to, typ)
+	if events, err = s.eventRepo.Query(ctx, q); err != nil {
+		return nil, err
+	}
+	return events, s.withComments(ctx, events)
+}
+
+// Stream passes the events matching f to fn one at a time, without
+// loading the whole range into memory.
+func (s *EventLogService) Stream(ctx context.Context, f LogFilter, fn func(models.FurnaceEvent) error) (err error) {
+	ctx, span := startSpan(ctx, "EventLog.Stream", logFilterAttrs(f)...)
+	n := 0
+	defer func() {
+		span.SetAttributes(attribute.Int("events.count", n))
+		endSpan(span, err)
+	}()
+	counted := func(ev models.FurnaceEvent) error {
+		n++
+		return fn(ev)
+	}
+
+	q, err := eventQuery(f)
+	if err != nil {
+		return err
+	}
+	if s.stream != nil {
+		return s.stream.Each(ctx, q, counted)
+	}
+	events, err := s.eventRepo.Query(ctx, q)
+	if err != nil {
+		return err
+	}
+	for _, ev := range events {
+		if err := counted(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Page returns one page of the events matching f in the order p asks for.
+// Pages are keyed on the last event returned rather than an offset, so
+// events appended while a client pages through do not shift later pages.
+func (s *EventLogService) Page(ctx context.Context, f LogFilter, p LogPageParams) (page LogPage, err error) {
+	ctx, span := startSpan(ctx, "EventLog.Page", append(logFilterAttrs(f),
+		attribute.Int("page.limit", p.Limit),
+		attribute.Bool("page.desc", p.Desc),
+	)...)
+	defer func() {
+		span.SetAttributes(attribute.Int("events.count", len(page.Events)))
+		endSpan(span, err)
+	}()
+
+	q, err := eventQuery(f)
+	if err != nil {
+		return LogPage{}, err
+	}
+	if s.stream == nil {
+		return LogPage{}, errNoEventPager
+	}
+	after, err := decodeLogCursor(p.Cursor, p.Desc)
+	if err != nil {
+		return LogPage{}, err
+	}
+	limit := p.Limit
+	if limit <= 0 {
+		limit = DefaultLogLimit
+	}
+	if limit > MaxLogLimit {
+		limit = MaxLogLimit
+	}
+	events, next, err := s.stream.Page(ctx, q, repository.EventPageQuery{After: after, Limit: limit, Desc: p.Desc})
+	if err != nil {
+		return LogPage{}, err
+	}
+	if err := s.withComments(ctx, events); err != nil {
+		return LogPage{}, err
+	}
+	page.Events = events
+	if next != nil {
+		page.NextCursor = encodeLogCursor(*next, p.Desc)
+	}
+	return page, nil
+}
+
+// Limits for tails of the log.
+const (
+	MaxTailWait      = time.Minute
+	TailPollInterval = 500 * time.Millisecond
+)
+
+// ErrTailEventNotFound is returned by Tail when the event to continue
+// after is no longer in the log, e.g. because retention purged it.
+var ErrTailEventNotFound = errors.New("event to continue after not found")
+
+// Tail returns the events matching f appended after the point p selects,
+// in the order they were appended. With p.Wait and nothing new yet, it
+// checks again every TailPollInterval until an event arrives, the wait is
+// over or ctx is done, and then answers with what it has.
+func (s *EventLogService) Tail(ctx context.Context, f LogFilter, p TailParams) (tail LogTail, err error) {
+	ctx, span := startSpan(ctx, "EventLog.Tail", append(logFilterAttrs(f),
+		attribute.Int("tail.limit", p.Limit),
+		attribute.Int64("tail.wait_ms", p.Wait.Milliseconds()),
+	)...)
+	defer func() {
+		span.SetAttributes(attribute.Int("events.count", len(tail.Events)))
+		endSpan(span, err)
+	}()
+
+	q, err := eventQuery(f)
+	if err != nil {
+		return LogTail{}, err
+	}
+	if s.stream == nil {
+		return LogTail{}, errNoEventPager
+	}
+	t := repository.EventTailQuery{AfterID: p.AfterID, AfterAt: normalizeToUTC(p.AfterAt), Limit: p.Limit}
+	if t.Limit <= 0 {
+		t.Limit = DefaultLogLimit
+	}
+	if t.Limit > MaxLogLimit {
+		t.Limit = MaxLogLimit
+	}
+	wait := min(p.Wait, MaxTailWait)
+
+	var deadline <-chan time.Time
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	poll := time.NewTicker(TailPollInterval)
+	defer poll.Stop()
+	for {
+		events, next, err := s.stream.Tail(ctx, q, t)
+		if errors.Is(err, repository.ErrEventNotFound) {
+			return LogTail{}, ErrTailEventNotFound
+		}
+		if err != nil {
+			return LogTail{}, err
+		}
+		tail = LogTail{Events: events, NextAfterID: next}
+		if len(events) > 0 || deadline == nil {
+			return tail, s.withComments(ctx, events)
+		}
+		switch {
+		case next != "":
+			t.AfterID = next
+		case t.AfterID == "" && t.AfterAt.IsZero():
+			// started at the end of an empty log: whatever arrives is new
+			t.AfterAt = time.Unix(0, 0).UTC()
+		}
+		select {
+		case <-ctx.Done():
+			return tail, nil
+		case <-deadline:
+			return tail, nil
+		case <-poll.C:
+		}
+	}
+}
+
+// logCursor is the decoded form of LogPage.NextCursor. Clients treat the
+// encoded string as opaque.
+type logCursor struct {
+	At    string `json:"t"`
+	RowID int64  `json:"r"`
+	Desc  bool   `json:"d,omitempty"`
+}
+
+func encodeLogCursor(k repository.EventKey, desc bool) string {
+	b, _ := json.Marshal(logCursor{At: k.At, RowID: k.RowID, Desc: desc})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeLogCursor returns the key s continues from; "" starts at the
+// beginning.
+func decodeLogCursor(s string, desc bool) (*repository.EventKey, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c logCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.At == "" || c.Desc != desc {
+		return nil, ErrInvalidCursor
+	}
+	return &repository.EventKey{At: c.At, RowID: c.RowID}, nil
+}
+
+// eventQuery normalizes and validates f for the repository.
+func eventQuery(f LogFilter) (repository.EventQuery, error) {
+	from, to, typ, err := normalizeAndValidateFilter(f)
+	if err != nil {
+		return repository.EventQuery{}, err
+	}
+	if len(f.Meta) > MaxMetaFilters {
+		return repository.EventQuery{}, fmt.Errorf("%w: at most %d filters", ErrInvalidMetaFilter, MaxMetaFilters)
+	}
+	var meta []repository.MetaFilter
+	for _, m := range f.Meta {
+		if err := validateMetaFilter(m); err != nil {
+			return repository.EventQuery{}, err
+		}
+		meta = append(meta, repository.MetaFilter{Key: m.Key, Op: m.Op, Value: m.Value})
+	}
+	q := repository.EventQuery{
+		From:           from,
+		To:             to,
+		Type:           typ,
+		RunID:          strings.TrimSpace(f.RunID),
+		Types:          normalizeEventTypes(f.Types),
+		ExcludeTypes:   normalizeEventTypes(f.ExcludeTypes),
+		Meta:           meta,
+		IncludeDeleted: f.IncludeDeleted,
+	}
+	if f.Severity == "" {
+		return q, nil
+	}
+	severe, err := typesAtLeast(f.Severity)
+	if err != nil {
+		return repository.EventQuery{}, err
+	}
+	if len(q.Types) == 0 {
+		q.Types = severe
+		return q, nil
+	}
+	kept := slices.DeleteFunc(slices.Clone(q.Types), func(t string) bool { return !slices.Contains(severe, t) })
+	if len(kept) == 0 {
+		// none of the types asked for is severe enough: excluding them
+		// too leaves nothing to match
+		q.ExcludeTypes = append(q.ExcludeTypes, q.Types...)
+		return q, nil
+	}
+	q.Types = kept
+	return q, nil
+}
+
+// VerifyChain checks the event log against its hash chain.
+func (s *EventLogService) VerifyChain(ctx context.Context) (_ models.ChainReport, err error) {
+	ctx, span := startSpan(ctx, "EventLog.VerifyChain")
+	defer func() { endSpan(span, err) }()
+
+	if s.chain == nil {
+		return models.ChainReport{Valid: true, Problems: []models.ChainProblem{}}, nil
+	}
+	return s.chain.VerifyChain(ctx)
 }
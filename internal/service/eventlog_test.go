@@ -3,19 +3,24 @@ package service
 import (
 	"context"
 	"errors"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
 	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
 )
 
 // fakeEventRepo is a minimal stub that satisfies the repository.EventRepo interface.
 type fakeEventRepo struct {
 	// captured inputs
-	gotCtx  context.Context
-	gotFrom time.Time
-	gotTo   time.Time
-	gotType string
+	gotCtx   context.Context
+	gotFrom  time.Time
+	gotTo    time.Time
+	gotType  string
+	gotRunID string
+	gotMeta  []repository.MetaFilter
 
 	// configured outputs
 	events []models.FurnaceEvent
@@ -33,10 +38,19 @@ func (f *fakeEventRepo) List(ctx context.Context, from, to time.Time, typ string
 	return f.events, f.err
 }
 
+func (f *fakeEventRepo) Query(ctx context.Context, q repository.EventQuery) ([]models.FurnaceEvent, error) {
+	f.gotRunID = q.RunID
+	f.gotMeta = q.Meta
+	return f.List(ctx, q.From, q.To, q.Type)
+}
+
 // helpers
 func (f *fakeEventRepo) Append(ctx context.Context, e models.FurnaceEvent) error {
 	return nil
 }
+func (f *fakeEventRepo) AppendBatch(ctx context.Context, events []models.FurnaceEvent) error {
+	return nil
+}
 
 func fixedZone(name string, offsetSec int) *time.Location {
 	return time.FixedZone(name, offsetSec)
@@ -286,3 +300,314 @@ func TestEventLogService_List_ZeroBoundsPassedAsZero(t *testing.T) {
 		t.Fatalf("expected zero bounds and empty type; got from=%v to=%v type=%q", frepo.gotFrom, frepo.gotTo, frepo.gotType)
 	}
 }
+
+func TestEventLogService_List_PassesRunID(t *testing.T) {
+	t.Parallel()
+
+	frepo := &fakeEventRepo{}
+	svc := NewEventLogService(frepo)
+
+	if _, err := svc.List(context.Background(), LogFilter{RunID: " run-7 "}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if frepo.gotRunID != "run-7" {
+		t.Fatalf("repo gotRunID=%q; want %q", frepo.gotRunID, "run-7")
+	}
+}
+
+func TestParseMetaFilter(t *testing.T) {
+	t.Parallel()
+
+	for expr, want := range map[string]MetaFilter{
+		"to=COOL":            {Key: "to", Op: "=", Value: "COOL"},
+		"temp_c > 1000":      {Key: "temp_c", Op: ">", Value: "1000"},
+		"limits.max_c<=1200": {Key: "limits.max_c", Op: "<=", Value: "1200"},
+		"note!=a<b":          {Key: "note", Op: "!=", Value: "a<b"},
+	} {
+		if got, err := ParseMetaFilter(expr); err != nil || got != want {
+			t.Fatalf("ParseMetaFilter(%q) = %+v, %v; want %+v", expr, got, err, want)
+		}
+	}
+	for _, expr := range []string{"to", "to=", "to!COOL", "$.to=COOL", "a..b=1", "=1"} {
+		if _, err := ParseMetaFilter(expr); !errors.Is(err, ErrInvalidMetaFilter) {
+			t.Fatalf("ParseMetaFilter(%q) = %v; want ErrInvalidMetaFilter", expr, err)
+		}
+	}
+}
+
+func TestEventLogService_List_PassesMetaFilters(t *testing.T) {
+	t.Parallel()
+
+	frepo := &fakeEventRepo{}
+	svc := NewEventLogService(frepo)
+
+	if _, err := svc.List(context.Background(), LogFilter{Meta: []MetaFilter{{Key: "to", Op: "=", Value: "COOL"}}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(frepo.gotMeta) != 1 || frepo.gotMeta[0] != (repository.MetaFilter{Key: "to", Op: "=", Value: "COOL"}) {
+		t.Fatalf("repo gotMeta=%+v", frepo.gotMeta)
+	}
+	if _, err := svc.List(context.Background(), LogFilter{Meta: []MetaFilter{{Key: "to", Op: "~", Value: "COOL"}}}); !errors.Is(err, ErrInvalidMetaFilter) {
+		t.Fatalf("expected ErrInvalidMetaFilter for an unknown operator, got %v", err)
+	}
+}
+
+// streamRepoStub records the query passed to Each and replays events.
+type streamRepoStub struct {
+	mu     sync.Mutex // guards events for Tail
+	gotQ   repository.EventQuery
+	gotP   repository.EventPageQuery
+	events []models.FurnaceEvent
+	next   *repository.EventKey // returned by Page
+	tails  int
+}
+
+func (s *streamRepoStub) Each(ctx context.Context, q repository.EventQuery, fn func(models.FurnaceEvent) error) error {
+	s.gotQ = q
+	for _, ev := range s.events {
+		if err := fn(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *streamRepoStub) Page(ctx context.Context, q repository.EventQuery, p repository.EventPageQuery) ([]models.FurnaceEvent, *repository.EventKey, error) {
+	s.gotQ, s.gotP = q, p
+	return s.events, s.next, nil
+}
+
+// Tail treats events as the log in append order; AfterAt is ignored.
+func (s *streamRepoStub) Tail(ctx context.Context, q repository.EventQuery, t repository.EventTailQuery) ([]models.FurnaceEvent, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gotQ = q
+	s.tails++
+	start := 0
+	switch {
+	case t.AfterID != "":
+		start = slices.IndexFunc(s.events, func(ev models.FurnaceEvent) bool { return ev.EventID == t.AfterID }) + 1
+		if start == 0 {
+			return nil, "", repository.ErrEventNotFound
+		}
+	case t.AfterAt.IsZero():
+		if len(s.events) == 0 {
+			return []models.FurnaceEvent{}, "", nil
+		}
+		return []models.FurnaceEvent{}, s.events[len(s.events)-1].EventID, nil
+	}
+	out := append([]models.FurnaceEvent{}, s.events[start:]...)
+	last := t.AfterID
+	if len(out) > 0 {
+		last = out[len(out)-1].EventID
+	}
+	return out, last, nil
+}
+
+func (s *streamRepoStub) append(ev models.FurnaceEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, ev)
+}
+
+func TestEventLogService_Tail_ContinuesAfterTheCursor(t *testing.T) {
+	t.Parallel()
+
+	stream := &streamRepoStub{events: []models.FurnaceEvent{{EventID: "a"}, {EventID: "b"}, {EventID: "c"}}}
+	svc := NewEventLogService(&fakeEventRepo{})
+	svc.stream = stream
+	ctx := context.Background()
+
+	tail, err := svc.Tail(ctx, LogFilter{Type: "start"}, TailParams{})
+	if err != nil || len(tail.Events) != 0 || tail.NextAfterID != "c" {
+		t.Fatalf("from the end: tail %+v, err %v; want no events and next c", tail, err)
+	}
+	if stream.gotQ.Type != "START" {
+		t.Fatalf("filter not passed on: %+v", stream.gotQ)
+	}
+	if tail, err = svc.Tail(ctx, LogFilter{}, TailParams{AfterID: "a"}); err != nil || len(tail.Events) != 2 || tail.NextAfterID != "c" {
+		t.Fatalf("after a: tail %+v, err %v; want b, c", tail, err)
+	}
+	if _, err = svc.Tail(ctx, LogFilter{}, TailParams{AfterID: "purged"}); !errors.Is(err, ErrTailEventNotFound) {
+		t.Fatalf("unknown cursor: err %v, want ErrTailEventNotFound", err)
+	}
+}
+
+func TestEventLogService_Tail_LongPollsForTheNextEvent(t *testing.T) {
+	t.Parallel()
+
+	stream := &streamRepoStub{events: []models.FurnaceEvent{{EventID: "a"}}}
+	svc := NewEventLogService(&fakeEventRepo{})
+	svc.stream = stream
+
+	go func() {
+		time.Sleep(TailPollInterval / 2)
+		stream.append(models.FurnaceEvent{EventID: "b"})
+	}()
+	tail, err := svc.Tail(context.Background(), LogFilter{}, TailParams{Wait: 10 * time.Second})
+	if err != nil || len(tail.Events) != 1 || tail.Events[0].EventID != "b" || tail.NextAfterID != "b" {
+		t.Fatalf("tail %+v, err %v; want the event appended while waiting", tail, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), TailPollInterval+TailPollInterval/2)
+	defer cancel()
+	tail, err = svc.Tail(ctx, LogFilter{}, TailParams{AfterID: "b", Wait: time.Minute})
+	if err != nil || len(tail.Events) != 0 || tail.NextAfterID != "b" {
+		t.Fatalf("nothing new: tail %+v, err %v", tail, err)
+	}
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	if stream.tails < 4 {
+		t.Fatalf("repository read %d times, want a check every poll interval", stream.tails)
+	}
+}
+
+func TestEventLogService_Page_CursorRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	stream := &streamRepoStub{
+		events: []models.FurnaceEvent{{EventID: "a"}},
+		next:   &repository.EventKey{At: "2025-09-20 10:00:00", RowID: 42},
+	}
+	svc := NewEventLogService(&fakeEventRepo{})
+	svc.stream = stream
+
+	page, err := svc.Page(context.Background(), LogFilter{Type: " error "}, LogPageParams{Desc: true})
+	if err != nil {
+		t.Fatalf("Page: %v", err)
+	}
+	if stream.gotP.After != nil || stream.gotP.Limit != DefaultLogLimit || !stream.gotP.Desc || stream.gotQ.Type != "ERROR" {
+		t.Fatalf("first page query = %+v %+v", stream.gotQ, stream.gotP)
+	}
+	if len(page.Events) != 1 || page.NextCursor == "" {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+
+	stream.next = nil
+	page, err = svc.Page(context.Background(), LogFilter{}, LogPageParams{Cursor: page.NextCursor, Limit: MaxLogLimit + 1, Desc: true})
+	if err != nil {
+		t.Fatalf("Page: %v", err)
+	}
+	if stream.gotP.After == nil || *stream.gotP.After != (repository.EventKey{At: "2025-09-20 10:00:00", RowID: 42}) {
+		t.Fatalf("cursor decoded to %+v", stream.gotP.After)
+	}
+	if stream.gotP.Limit != MaxLogLimit {
+		t.Fatalf("limit = %d, want capped at %d", stream.gotP.Limit, MaxLogLimit)
+	}
+	if page.NextCursor != "" {
+		t.Fatalf("last page has cursor %q", page.NextCursor)
+	}
+}
+
+func TestEventLogService_Page_RejectsForeignCursor(t *testing.T) {
+	t.Parallel()
+
+	svc := NewEventLogService(&fakeEventRepo{})
+	svc.stream = &streamRepoStub{}
+	asc := encodeLogCursor(repository.EventKey{At: "2025-09-20 10:00:00", RowID: 1}, false)
+
+	for _, cursor := range []string{"not-base64!", "e30", asc} {
+		if _, err := svc.Page(context.Background(), LogFilter{}, LogPageParams{Cursor: cursor, Desc: true}); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("cursor %q: err = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
+
+func TestEventLogService_Stream_UsesCursorRepo(t *testing.T) {
+	t.Parallel()
+
+	frepo := &fakeEventRepo{}
+	stream := &streamRepoStub{events: []models.FurnaceEvent{{EventID: "a"}, {EventID: "b"}}}
+	svc := NewEventLogService(frepo)
+	svc.stream = stream
+
+	var got []string
+	err := svc.Stream(context.Background(), LogFilter{Type: " error ", RunID: " run-7 "}, func(ev models.FurnaceEvent) error {
+		got = append(got, ev.EventID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || frepo.calls != 0 {
+		t.Fatalf("expected 2 streamed events without Query, got %v (query calls=%d)", got, frepo.calls)
+	}
+	if stream.gotQ.Type != "ERROR" || stream.gotQ.RunID != "run-7" {
+		t.Fatalf("filter not normalized: %+v", stream.gotQ)
+	}
+}
+
+func TestEventLogService_NormalizesTypeLists(t *testing.T) {
+	t.Parallel()
+
+	stream := &streamRepoStub{}
+	svc := NewEventLogService(&fakeEventRepo{})
+	svc.stream = stream
+
+	f := LogFilter{Types: []string{" start", "STOP", "", "Start"}, ExcludeTypes: []string{"telemetry "}}
+	if err := svc.Stream(context.Background(), f, func(models.FurnaceEvent) error { return nil }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(stream.gotQ.Types, []string{"START", "STOP"}) || !slices.Equal(stream.gotQ.ExcludeTypes, []string{"TELEMETRY"}) {
+		t.Fatalf("type lists not normalized: %+v", stream.gotQ)
+	}
+}
+
+func TestEventLogService_SeverityNarrowsTypes(t *testing.T) {
+	t.Parallel()
+
+	stream := &streamRepoStub{}
+	svc := NewEventLogService(&fakeEventRepo{})
+	svc.stream = stream
+	each := func(f LogFilter) error {
+		return svc.Stream(context.Background(), f, func(models.FurnaceEvent) error { return nil })
+	}
+
+	if err := each(LogFilter{Severity: "critical"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Contains(stream.gotQ.Types, "SAFETY_TRIP") || slices.Contains(stream.gotQ.Types, "ALERT") || slices.Contains(stream.gotQ.Types, "START") {
+		t.Fatalf("critical types: %v", stream.gotQ.Types)
+	}
+
+	if err := each(LogFilter{Severity: "Warning", Types: []string{"start", "alert", "error"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(stream.gotQ.Types, []string{"ALERT", "ERROR"}) {
+		t.Fatalf("warning of listed types: %v", stream.gotQ.Types)
+	}
+
+	// nothing asked for is severe enough
+	if err := each(LogFilter{Severity: "critical", Types: []string{"START"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(stream.gotQ.Types, []string{"START"}) || !slices.Equal(stream.gotQ.ExcludeTypes, []string{"START"}) {
+		t.Fatalf("empty selection: %+v", stream.gotQ)
+	}
+
+	if err := each(LogFilter{Severity: "fatal"}); !errors.Is(err, ErrInvalidSeverity) {
+		t.Fatalf("expected ErrInvalidSeverity, got %v", err)
+	}
+}
+
+func TestEventLogService_Stream_FallsBackToQuery(t *testing.T) {
+	t.Parallel()
+
+	frepo := &fakeEventRepo{events: []models.FurnaceEvent{{EventID: "a"}, {EventID: "b"}}}
+	svc := NewEventLogService(frepo)
+
+	stop := errors.New("client gone")
+	calls := 0
+	err := svc.Stream(context.Background(), LogFilter{}, func(models.FurnaceEvent) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("expected to stop after the first event, got err=%v calls=%d", err, calls)
+	}
+
+	from := time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)
+	if err := svc.Stream(context.Background(), LogFilter{From: from, To: from.Add(-time.Hour)}, nil); !errors.Is(err, errInvalidTimeRange) {
+		t.Fatalf("expected errInvalidTimeRange, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// Injectable simulator faults. Each kind doubles as the error code reported
+// in FurnaceState.ErrorCodes while the fault is active.
+const (
+	FaultStuckSensor       = "STUCK_SENSOR"
+	FaultHeaterFailure     = "HEATER_FAILURE"
+	FaultPowerLoss         = "POWER_LOSS"
+	FaultThermocoupleBreak = "THERMOCOUPLE_BREAK"
+)
+
+// openCircuitReadingC is what a broken type K thermocouple reads:
+// the amplifier saturates at the top of its range.
+const openCircuitReadingC = 1372.0
+
+// ErrUnknownFault is returned for fault kinds the simulator does not model.
+var ErrUnknownFault = errors.New("unknown fault type")
+
+var faultDescriptions = map[string]string{
+	FaultStuckSensor:       "Temperature sensor stuck",
+	FaultHeaterFailure:     "Heater failure",
+	FaultPowerLoss:         "Power loss",
+	FaultThermocoupleBreak: "Thermocouple break (open circuit)",
+}
+
+// faultKinds lists the modeled faults in a stable order.
+var faultKinds = []string{FaultStuckSensor, FaultHeaterFailure, FaultPowerLoss, FaultThermocoupleBreak}
+
+// ActiveFault is a fault currently injected into the simulator.
+type ActiveFault struct {
+	Type       string    `json:"type" example:"HEATER_FAILURE"`
+	InjectedAt time.Time `json:"injected_at" example:"2025-09-20T10:00:00Z"`
+}
+
+// faultSet holds injected faults; it is shared between API handlers and the
+// simulator loop.
+type faultSet struct {
+	mu     sync.RWMutex
+	active map[string]time.Time
+}
+
+func newFaultSet() *faultSet {
+	return &faultSet{active: make(map[string]time.Time)}
+}
+
+func (f *faultSet) has(kind string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	_, ok := f.active[kind]
+	return ok
+}
+
+// InjectFault activates a fault; the simulator applies it on the next tick.
+// Injecting an already active fault is a no-op.
+func (s *SimulatorService) InjectFault(kind string) error {
+	if _, ok := faultDescriptions[kind]; !ok {
+		return ErrUnknownFault
+	}
+	s.faults.mu.Lock()
+	defer s.faults.mu.Unlock()
+	if _, ok := s.faults.active[kind]; !ok {
+		s.faults.active[kind] = s.now().UTC()
+	}
+	return nil
+}
+
+// ClearFault deactivates a single fault.
+func (s *SimulatorService) ClearFault(kind string) error {
+	if _, ok := faultDescriptions[kind]; !ok {
+		return ErrUnknownFault
+	}
+	s.faults.mu.Lock()
+	delete(s.faults.active, kind)
+	s.faults.mu.Unlock()
+	return nil
+}
+
+// ClearAllFaults deactivates every injected fault.
+func (s *SimulatorService) ClearAllFaults() {
+	s.faults.mu.Lock()
+	s.faults.active = make(map[string]time.Time)
+	s.faults.mu.Unlock()
+}
+
+// ActiveFaults returns the injected faults sorted by type.
+func (s *SimulatorService) ActiveFaults() []ActiveFault {
+	s.faults.mu.RLock()
+	defer s.faults.mu.RUnlock()
+	out := make([]ActiveFault, 0, len(s.faults.active))
+	for kind, at := range s.faults.active {
+		out = append(out, ActiveFault{Type: kind, InjectedAt: at})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Type < out[j].Type })
+	return out
+}
+
+// reconcileFaults syncs fault error codes with the injected set, logging an
+// ERROR event when a fault first takes effect and FAULT_CLEARED when it goes
+// away. Returns true if the error codes changed.
+func (s *SimulatorService) reconcileFaults(ctx context.Context, st *models.FurnaceState, now time.Time) bool {
+	changed := false
+	for _, kind := range faultKinds {
+		active := s.faults.has(kind)
+		reported := hasString(st.ErrorCodes, kind)
+		switch {
+		case active && !reported:
+			st.ErrorCodes = append(st.ErrorCodes, kind)
+			s.emit(models.FurnaceEvent{
+				EventID:     s.newID(),
+				OccurredAt:  now.UTC(),
+				Type:        "ERROR",
+				Description: faultDescriptions[kind],
+				Metadata: withRunID(map[string]any{
+					"fault":     kind,
+					"temp_c":    st.CurrentTempC,
+					"mode":      st.Mode,
+					"isRunning": st.IsRunning,
+				}, st.RunID),
+			})
+			changed = true
+		case !active && reported:
+			st.ErrorCodes = removeString(st.ErrorCodes, kind)
+			s.emit(models.FurnaceEvent{
+				EventID:     s.newID(),
+				OccurredAt:  now.UTC(),
+				Type:        "FAULT_CLEARED",
+				Description: "Fault cleared: " + faultDescriptions[kind],
+				Metadata:    withRunID(map[string]any{"fault": kind}, st.RunID),
+			})
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+func heatingState(now time.Time) models.FurnaceState {
+	return models.FurnaceState{
+		ID:               1,
+		Mode:             ModeHeat,
+		CurrentTempC:     500,
+		MeasuredTempC:    500,
+		TargetTempC:      800,
+		RemainingSeconds: 600,
+		IsRunning:        true,
+		RunID:            "run-1",
+		UpdatedAt:        now.Add(-10 * time.Second),
+	}
+}
+
+func TestInjectFault_RejectsUnknown(t *testing.T) {
+	svc := NewSimulatorService(&simStateRepoStub{}, &simEventRepoStub{})
+
+	if err := svc.InjectFault("MELTDOWN"); !errors.Is(err, ErrUnknownFault) {
+		t.Fatalf("expected ErrUnknownFault, got %v", err)
+	}
+	if err := svc.InjectFault(FaultPowerLoss); err != nil {
+		t.Fatalf("InjectFault: %v", err)
+	}
+	// idempotent
+	_ = svc.InjectFault(FaultPowerLoss)
+	if got := svc.ActiveFaults(); len(got) != 1 || got[0].Type != FaultPowerLoss {
+		t.Fatalf("unexpected active faults: %+v", got)
+	}
+	svc.ClearAllFaults()
+	if got := svc.ActiveFaults(); len(got) != 0 {
+		t.Fatalf("expected no faults after clear, got %+v", got)
+	}
+}
+
+func TestTick_HeaterFailureCoolsAndLogsOnce(t *testing.T) {
+	now := time.Now()
+	states := &simStateRepoStub{loadResp: heatingState(now)}
+	events := &simEventRepoStub{}
+	svc := NewSimulatorService(states, events)
+	_ = svc.InjectFault(FaultHeaterFailure)
+
+	svc.tick(context.Background(), now)
+
+	if len(states.saves) != 1 {
+		t.Fatalf("expected one save, got %d", len(states.saves))
+	}
+	st := states.saves[0]
+	if st.CurrentTempC >= 500 {
+		t.Fatalf("expected furnace to lose heat, got %.2f", st.CurrentTempC)
+	}
+	if !hasString(st.ErrorCodes, FaultHeaterFailure) {
+		t.Fatalf("expected %s error code, got %v", FaultHeaterFailure, st.ErrorCodes)
+	}
+	if len(events.appends) != 1 || events.appends[0].Type != "ERROR" {
+		t.Fatalf("expected one ERROR event, got %+v", events.appends)
+	}
+	meta, _ := events.appends[0].Metadata.(map[string]any)
+	if meta["fault"] != FaultHeaterFailure || meta["run_id"] != "run-1" {
+		t.Fatalf("unexpected metadata: %v", events.appends[0].Metadata)
+	}
+
+	// Next tick with the code already reported must not log again.
+	states.loadResp = st
+	states.loadResp.UpdatedAt = now
+	svc.tick(context.Background(), now.Add(5*time.Second))
+	if len(events.appends) != 1 {
+		t.Fatalf("expected no duplicate ERROR event, got %d events", len(events.appends))
+	}
+}
+
+func TestTick_ClearedFaultRemovesCode(t *testing.T) {
+	now := time.Now()
+	st := heatingState(now)
+	st.ErrorCodes = []string{"SENSOR_FAULT", FaultPowerLoss}
+	states := &simStateRepoStub{loadResp: st}
+	events := &simEventRepoStub{}
+	svc := NewSimulatorService(states, events)
+
+	svc.tick(context.Background(), now)
+
+	got := states.saves[0].ErrorCodes
+	if hasString(got, FaultPowerLoss) || !hasString(got, "SENSOR_FAULT") {
+		t.Fatalf("expected only the fault code removed, got %v", got)
+	}
+	if len(events.appends) != 1 || events.appends[0].Type != "FAULT_CLEARED" {
+		t.Fatalf("expected FAULT_CLEARED event, got %+v", events.appends)
+	}
+}
+
+func TestTick_PowerLossPausesSoak(t *testing.T) {
+	now := time.Now()
+	st := heatingState(now)
+	st.CurrentTempC = st.TargetTempC
+	states := &simStateRepoStub{loadResp: st}
+	svc := NewSimulatorService(states, &simEventRepoStub{})
+	_ = svc.InjectFault(FaultPowerLoss)
+
+	svc.tick(context.Background(), now)
+
+	saved := states.saves[0]
+	if saved.RemainingSeconds != st.RemainingSeconds {
+		t.Fatalf("soak timer must not run without power, got %d", saved.RemainingSeconds)
+	}
+	if saved.CurrentTempC >= st.CurrentTempC {
+		t.Fatalf("expected temperature to drop, got %.2f", saved.CurrentTempC)
+	}
+}
+
+func TestMeasure_SensorFaults(t *testing.T) {
+	svc := NewSimulatorService(&simStateRepoStub{}, &simEventRepoStub{})
+	st := models.FurnaceState{CurrentTempC: 700, MeasuredTempC: 650}
+
+	_ = svc.InjectFault(FaultStuckSensor)
+	if svc.measure(&st, 1) || st.MeasuredTempC != 650 {
+		t.Fatalf("stuck sensor should hold its reading, got %.2f", st.MeasuredTempC)
+	}
+
+	_ = svc.InjectFault(FaultThermocoupleBreak)
+	_ = svc.measure(&st, 1)
+	if st.MeasuredTempC != openCircuitReadingC {
+		t.Fatalf("broken thermocouple should read %.0f, got %.2f", openCircuitReadingC, st.MeasuredTempC)
+	}
+
+	svc.ClearAllFaults()
+	_ = svc.measure(&st, 1)
+	if st.MeasuredTempC != 700 {
+		t.Fatalf("expected normal reading after clear, got %.2f", st.MeasuredTempC)
+	}
+}
@@ -4,12 +4,12 @@ import (
 	"context"
 	"controlling_furnace/internal/models"
 	"errors"
-	"fmt"
 	"time"
 
 	"controlling_furnace/internal/repository"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // -------- Public API --------
@@ -17,84 +17,131 @@ import (
 // -------- Implementation --------
 
 type FurnaceService struct {
-	stateRepo repository.StateRepo
+	state     *StateManager
 	eventRepo repository.EventRepo
+	uow       repository.UnitOfWork // commits state and event together; two writes when nil
+
+	limits func() PhysicsConfig // live simulator physics; defaults when nil
+	clock  func() time.Time     // command timestamps; time.Now when nil
+	ids    func() string        // event and run IDs; random UUIDs when nil
 }
 
 func NewFurnaceService(stateRepo repository.StateRepo, eventRepo repository.EventRepo) *FurnaceService {
-	return &FurnaceService{stateRepo: stateRepo, eventRepo: eventRepo}
+	return &FurnaceService{state: managedState(stateRepo), eventRepo: eventRepo}
 }
 
-var (
-	errInvalidMode    = errors.New("invalid mode: must be HEAT, COOL, or STANDBY")
-	errInvalidHeatCfg = errors.New("invalid HEAT params: target_temp_c > 0 and duration_sec > 0 are required")
-)
+func (s *FurnaceService) now() time.Time {
+	if s.clock != nil {
+		return s.clock()
+	}
+	return time.Now()
+}
 
-// Start sets IsRunning=true and logs START.
-// If state row doesn't exist yet, it initializes a default one.
-func (s *FurnaceService) Start(ctx context.Context) error {
-	now := time.Now().UTC()
+func (s *FurnaceService) newID() string {
+	if s.ids != nil {
+		return s.ids()
+	}
+	return uuid.NewString()
+}
 
-	st, err := s.stateRepo.Load(ctx)
-	if err != nil {
+// record changes the state with fn and saves it together with the event
+// fn returns: in one unit of work, so a crash cannot store one without the
+// other, or as two writes without one.
+func (s *FurnaceService) record(ctx context.Context, fn func(st *models.FurnaceState) (models.FurnaceEvent, error)) error {
+	var ev models.FurnaceEvent
+	change := func(st *models.FurnaceState) (err error) {
+		ev, err = fn(st)
 		return err
 	}
-	// Initialize default state if empty
-	if st.ID == 0 {
-		st = models.FurnaceState{
-			ID:               1,
-			Mode:             "STANDBY",
-			CurrentTempC:     25, // ambient default
-			TargetTempC:      0,
-			RemainingSeconds: 0,
-			ErrorCodes:       nil,
-			IsRunning:        true,
-			UpdatedAt:        now,
+	if s.uow == nil {
+		if _, err := s.state.Update(ctx, change); err != nil {
+			return err
 		}
-	} else {
-		st.IsRunning = true
-		st.UpdatedAt = now
+		return s.eventRepo.Append(ctx, ev)
 	}
+	_, err := s.state.updateWith(ctx, change, func(ctx context.Context, st models.FurnaceState) error {
+		return s.uow.Do(ctx, func(tx repository.Tx) error {
+			if err := tx.StateRepo.Save(ctx, st); err != nil {
+				return err
+			}
+			return correlate(tx.EventRepo).Append(ctx, ev)
+		})
+	})
+	return err
+}
 
-	if err := s.stateRepo.Save(ctx, st); err != nil {
-		return err
-	}
+var (
+	errInvalidMode    = errors.New("invalid mode: must be HEAT, COOL, or STANDBY")
+	errInvalidHeatCfg = errors.New("invalid HEAT params: target_temp_c > 0 and duration_sec > 0 are required")
+)
 
-	return s.eventRepo.Append(ctx, models.FurnaceEvent{
-		EventID:     uuid.NewString(),
-		OccurredAt:  now,
-		Type:        "START",
-		Description: "Furnace started",
+// Start sets IsRunning=true and logs START.
+// If state row doesn't exist yet, it initializes a default one.
+func (s *FurnaceService) Start(ctx context.Context) (err error) {
+	ctx, span := startSpan(ctx, "Furnace.Start")
+	defer func() { endSpan(span, err) }()
+
+	now := s.now().UTC()
+
+	return s.record(ctx, func(st *models.FurnaceState) (models.FurnaceEvent, error) {
+		// Initialize default state if empty
+		if st.ID == 0 {
+			*st = models.FurnaceState{
+				ID:               1,
+				Mode:             "STANDBY",
+				CurrentTempC:     25, // ambient default
+				MeasuredTempC:    25,
+				O2PPM:            AirO2PPM,
+				TargetTempC:      0,
+				RemainingSeconds: 0,
+				ErrorCodes:       nil,
+				IsRunning:        true,
+				UpdatedAt:        now,
+			}
+		} else {
+			st.IsRunning = true
+			st.UpdatedAt = now
+		}
+		return models.FurnaceEvent{
+			EventID:     s.newID(),
+			OccurredAt:  now,
+			Type:        "START",
+			Description: "Furnace started",
+		}, nil
 	})
 }
 
 // Stop sets IsRunning=false, switches to STANDBY, clears timing/target, and logs STOP.
-func (s *FurnaceService) Stop(ctx context.Context) error {
-	now := time.Now().UTC()
+func (s *FurnaceService) Stop(ctx context.Context) (err error) {
+	ctx, span := startSpan(ctx, "Furnace.Stop")
+	defer func() { endSpan(span, err) }()
 
-	st, err := s.stateRepo.Load(ctx)
-	if err != nil {
-		return err
-	}
-	if st.ID == 0 {
-		// If no state existed, create a baseline stopped state.
-		st.ID = 1
-	}
-	st.IsRunning = false
-	st.Mode = "STANDBY"
-	st.TargetTempC = 0
-	st.RemainingSeconds = 0
-	st.UpdatedAt = now
+	now := s.now().UTC()
 
-	if err := s.stateRepo.Save(ctx, st); err != nil {
-		return err
-	}
+	return s.record(ctx, func(st *models.FurnaceState) (models.FurnaceEvent, error) {
+		if st.ID == 0 {
+			// If no state existed, create a baseline stopped state.
+			st.ID = 1
+		}
+		// Stopping ends the active run; the STOP event is its last entry.
+		runID := st.RunID
+		st.IsRunning = false
+		st.Mode = "STANDBY"
+		st.TargetTempC = 0
+		st.RemainingSeconds = 0
+		st.RunID = ""
+		st.UpdatedAt = now
 
-	return s.eventRepo.Append(ctx, models.FurnaceEvent{
-		EventID:     uuid.NewString(),
-		OccurredAt:  now,
-		Type:        "STOP",
-		Description: "Furnace stopped",
+		ev := models.FurnaceEvent{
+			EventID:     s.newID(),
+			OccurredAt:  now,
+			Type:        "STOP",
+			Description: "Furnace stopped",
+		}
+		if runID != "" {
+			ev.Metadata = withRunID(nil, runID)
+		}
+		return ev, nil
 	})
 }
 
@@ -102,20 +149,17 @@ func (s *FurnaceService) Stop(ctx context.Context) error {
 // - HEAT requires target_temp_c > 0 and duration_sec > 0.
 // - COOL/STANDBY clear target/duration.
 // This does NOT implicitly start/stop the furnace; Start/Stop own IsRunning.
-func (s *FurnaceService) SetMode(ctx context.Context, p ModeParams) error {
-	now := time.Now().UTC()
+func (s *FurnaceService) SetMode(ctx context.Context, p ModeParams) (err error) {
+	ctx, span := startSpan(ctx, "Furnace.SetMode", attribute.String("furnace.mode", p.Mode))
+	defer func() { endSpan(span, err) }()
+
+	now := s.now().UTC()
 
 	// Basic validation
 	switch p.Mode {
 	case "HEAT":
-		if !(p.TargetTempC > 0 && p.DurationSec > 0) {
-			return errInvalidHeatCfg
-		}
-		if p.TargetTempC < AmbientC {
-			return fmt.Errorf("target temperature %.1f is below ambient temperature %.1f", p.TargetTempC, AmbientC)
-		}
-		if p.TargetTempC > MaxSafeC { // ✅ new check
-			return fmt.Errorf("target temperature %.1f exceeds max safe limit %.1f", p.TargetTempC, MaxSafeC)
+		if b := heatParamsBlocker(p, s.physics()); b != nil {
+			return b.err
 		}
 
 	case "COOL", "STANDBY":
@@ -123,44 +167,66 @@ func (s *FurnaceService) SetMode(ctx context.Context, p ModeParams) error {
 	default:
 		return errInvalidMode
 	}
-
-	st, err := s.stateRepo.Load(ctx)
-	if err != nil {
-		return err
-	}
-	if st.ID == 0 {
-		// Furnace never started
-		return errors.New("cannot change mode: furnace is not running, start it first")
+	if p.Atmosphere != nil {
+		if err := p.Atmosphere.normalize(); err != nil {
+			return err
+		}
 	}
 
-	if !st.IsRunning {
-		return errors.New("cannot change mode: furnace is stopped, start it first")
-	}
+	return s.record(ctx, func(st *models.FurnaceState) (models.FurnaceEvent, error) {
+		if st.ID == 0 {
+			// Furnace never started
+			return models.FurnaceEvent{}, errors.New("cannot change mode: furnace is not running, start it first")
+		}
 
-	// Apply mode change
-	st.Mode = p.Mode
-	if p.Mode == "HEAT" {
-		st.TargetTempC = p.TargetTempC
-		st.RemainingSeconds = p.DurationSec
-	} else {
-		st.TargetTempC = 0
-		st.RemainingSeconds = 0
-	}
-	st.UpdatedAt = now
+		if !st.IsRunning {
+			return models.FurnaceEvent{}, errors.New("cannot change mode: furnace is stopped, start it first")
+		}
+		if p.Mode == ModeHeat {
+			// again under the state lock, which PreviewSimSettings holds
+			// while it changes the limits
+			if b := heatParamsBlocker(p, s.physics()); b != nil {
+				return models.FurnaceEvent{}, b.err
+			}
+		}
 
-	if err := s.stateRepo.Save(ctx, st); err != nil {
-		return err
-	}
+		// Apply mode change. HEAT always begins a new run; COOL keeps the
+		// current run (cool-down is part of the cycle); STANDBY ends it.
+		st.Mode = p.Mode
+		if p.Mode == "HEAT" {
+			st.TargetTempC = p.TargetTempC
+			st.RemainingSeconds = p.DurationSec
+			st.RunID = s.newID()
+			st.EnergyKWh = 0
+		} else {
+			st.TargetTempC = 0
+			st.RemainingSeconds = 0
+		}
+		if p.Atmosphere != nil {
+			p.Atmosphere.apply(st)
+		}
+		runID := st.RunID
+		if p.Mode == ModeStandby {
+			st.RunID = ""
+		}
+		st.UpdatedAt = now
 
-	return s.eventRepo.Append(ctx, models.FurnaceEvent{
-		EventID:     uuid.NewString(),
-		OccurredAt:  now,
-		Type:        "MODE_CHANGE",
-		Description: "Mode changed to " + p.Mode,
-		Metadata: map[string]any{
+		meta := map[string]any{
 			"target_temp_c": st.TargetTempC,
 			"duration_sec":  st.RemainingSeconds,
 			"is_running":    st.IsRunning,
-		},
+		}
+		if p.Atmosphere != nil {
+			for k, v := range p.Atmosphere.metadata() {
+				meta[k] = v
+			}
+		}
+		return models.FurnaceEvent{
+			EventID:     s.newID(),
+			OccurredAt:  now,
+			Type:        "MODE_CHANGE",
+			Description: "Mode changed to " + p.Mode,
+			Metadata:    withRunID(meta, runID),
+		}, nil
 	})
 }
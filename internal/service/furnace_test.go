@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
 	"errors"
 	"testing"
 	"time"
@@ -13,9 +14,11 @@ type fakeStateRepo struct {
 	loadErr    error
 	saveErr    error
 	savedCalls []models.FurnaceState
+	loadCalls  int
 }
 
 func (f *fakeStateRepo) Load(ctx context.Context) (models.FurnaceState, error) {
+	f.loadCalls++
 	return f.loadResp, f.loadErr
 }
 func (f *fakeStateRepo) Save(ctx context.Context, s models.FurnaceState) error {
@@ -33,6 +36,10 @@ func (f *localEventRepo) Append(ctx context.Context, e models.FurnaceEvent) erro
 	f.events = append(f.events, e)
 	return f.appendErr
 }
+func (f *localEventRepo) AppendBatch(ctx context.Context, events []models.FurnaceEvent) error {
+	f.events = append(f.events, events...)
+	return f.appendErr
+}
 func (f *localEventRepo) List(ctx context.Context, from time.Time, to time.Time, typ string) ([]models.FurnaceEvent, error) {
 	if f.listErr != nil {
 		return nil, f.listErr
@@ -47,6 +54,9 @@ func (f *localEventRepo) List(ctx context.Context, from time.Time, to time.Time,
 	}
 	return out, nil
 }
+func (f *localEventRepo) Query(ctx context.Context, q repository.EventQuery) ([]models.FurnaceEvent, error) {
+	return f.List(ctx, q.From, q.To, q.Type)
+}
 func assertWithinTimeWindow(t *testing.T, ts time.Time, start time.Time, end time.Time) {
 	t.Helper()
 	if ts.Before(start) || ts.After(end) {
@@ -64,7 +74,7 @@ func lastSavedState(t *testing.T, f *fakeStateRepo) models.FurnaceState {
 // ... existing code ...
 func TestFurnaceService_Start_LoadError(t *testing.T) {
 	fs := &FurnaceService{
-		stateRepo: &fakeStateRepo{loadErr: errors.New("db down")},
+		state:     NewStateManager(&fakeStateRepo{loadErr: errors.New("db down")}),
 		eventRepo: &localEventRepo{},
 	}
 	err := fs.Start(context.Background())
@@ -79,7 +89,7 @@ func TestFurnaceService_Start_InitializesDefaultStateAndAppendsEvent(t *testing.
 		loadResp: models.FurnaceState{},
 	}
 	erepo := &localEventRepo{}
-	fs := &FurnaceService{stateRepo: srepo, eventRepo: erepo}
+	fs := &FurnaceService{state: NewStateManager(srepo), eventRepo: erepo}
 	t0 := time.Now().UTC()
 	err := fs.Start(context.Background())
 	t1 := time.Now().UTC()
@@ -127,7 +137,7 @@ func TestFurnaceService_Start_ExistingStateSetsRunningAndAppendsEvent(t *testing
 		},
 	}
 	erepo := &localEventRepo{}
-	fs := &FurnaceService{stateRepo: srepo, eventRepo: erepo}
+	fs := &FurnaceService{state: NewStateManager(srepo), eventRepo: erepo}
 	t0 := time.Now().UTC()
 	err := fs.Start(context.Background())
 	t1 := time.Now().UTC()
@@ -153,7 +163,7 @@ func TestFurnaceService_Stop_BaselineWhenNoStateAndAppendsEvent(t *testing.T) {
 		loadResp: models.FurnaceState{},
 	}
 	erepo := &localEventRepo{}
-	fs := &FurnaceService{stateRepo: srepo, eventRepo: erepo}
+	fs := &FurnaceService{state: NewStateManager(srepo), eventRepo: erepo}
 	t0 := time.Now().UTC()
 	err := fs.Stop(context.Background())
 	t1 := time.Now().UTC()
@@ -180,3 +190,71 @@ func TestFurnaceService_Stop_BaselineWhenNoStateAndAppendsEvent(t *testing.T) {
 }
 
 // ... existing code ...
+
+func TestFurnaceService_SetModeHeat_StartsRunAndTagsEvents(t *testing.T) {
+	srepo := &fakeStateRepo{
+		loadResp: models.FurnaceState{ID: 1, Mode: "STANDBY", CurrentTempC: 25, IsRunning: true},
+	}
+	erepo := &localEventRepo{}
+	fs := &FurnaceService{state: NewStateManager(srepo), eventRepo: erepo}
+
+	if err := fs.SetMode(context.Background(), ModeParams{Mode: "HEAT", TargetTempC: 500, DurationSec: 60}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := lastSavedState(t, srepo)
+	if s.RunID == "" {
+		t.Fatalf("expected HEAT to start a run")
+	}
+	meta, _ := erepo.events[0].Metadata.(map[string]any)
+	if meta["run_id"] != s.RunID {
+		t.Fatalf("expected MODE_CHANGE tagged with run %q, got %#v", s.RunID, erepo.events[0].Metadata)
+	}
+
+	// Stopping tags the STOP event with the run and clears it from state.
+	srepo.loadResp = s
+	if err := fs.Stop(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stopped := lastSavedState(t, srepo)
+	if stopped.RunID != "" {
+		t.Fatalf("expected run cleared on stop, got %q", stopped.RunID)
+	}
+	meta, _ = erepo.events[1].Metadata.(map[string]any)
+	if erepo.events[1].Type != "STOP" || meta["run_id"] != s.RunID {
+		t.Fatalf("expected STOP tagged with run %q, got %#v", s.RunID, erepo.events[1])
+	}
+}
+
+// failingEvents fails every append made through a unit of work.
+type failingEvents struct{ repository.UnitOfWork }
+
+func (u failingEvents) Do(ctx context.Context, fn func(tx repository.Tx) error) error {
+	return u.UnitOfWork.Do(ctx, func(tx repository.Tx) error {
+		tx.EventRepo = &localEventRepo{appendErr: errors.New("disk full")}
+		return fn(tx)
+	})
+}
+
+func TestFurnaceService_StateAndEventCommitTogether(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewInMemory()
+	svc := NewServiceWithConfig(repos, DefaultConfig())
+	if err := svc.Furnace.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the MODE_CHANGE cannot be logged, so HEAT must not be stored either
+	svc.Furnace.(*FurnaceService).uow = failingEvents{repos.UnitOfWork}
+	err := svc.Furnace.SetMode(ctx, ModeParams{Mode: ModeHeat, TargetTempC: 500, DurationSec: 60})
+	if err == nil {
+		t.Fatal("expected the failed append to fail SetMode")
+	}
+	stored, _ := repos.StateRepo.Load(ctx)
+	current, _ := svc.Furnace.(*FurnaceService).state.Load(ctx)
+	if stored.Mode != ModeStandby || current.Mode != ModeStandby || stored.RunID != "" {
+		t.Fatalf("state changed without its event: stored %+v, current %+v", stored, current)
+	}
+	if events, _ := repos.EventRepo.Query(ctx, repository.EventQuery{}); len(events) != 1 || events[0].Type != "START" {
+		t.Fatalf("events %+v", events)
+	}
+}
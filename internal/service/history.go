@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+// Defaults and limits for temperature history queries.
+const (
+	DefaultHistoryWindow  = time.Hour
+	DefaultHistoryBuckets = 500   // buckets aimed for when no resolution is given
+	MaxHistoryBuckets     = 10000 // most buckets a single query may span
+)
+
+// ErrInvalidHistoryQuery is returned for inverted windows and for
+// resolutions that are not whole seconds or would produce too many buckets.
+var ErrInvalidHistoryQuery = errors.New("invalid history query")
+
+// HistoryFilter selects a window of the temperature history.
+type HistoryFilter struct {
+	From       time.Time     // zero means DefaultHistoryWindow before To
+	To         time.Time     // zero means now
+	Resolution time.Duration // bucket width; zero picks one for DefaultHistoryBuckets
+}
+
+// DefaultRollupInterval is how often Run summarises the temperature
+// history when no interval is configured.
+const DefaultRollupInterval = 5 * time.Minute
+
+// HistoryConfig configures the summaries behind long-range history.
+type HistoryConfig struct {
+	RollupInterval time.Duration `mapstructure:"rollup_interval"` // DefaultRollupInterval when 0
+	// RawRetention is how long per-tick samples are kept; older ones are
+	// deleted once an hourly summary covers them. 0 keeps them all.
+	RawRetention time.Duration `mapstructure:"raw_retention"`
+}
+
+// Validate rejects negative durations.
+func (c HistoryConfig) Validate() error {
+	if c.RollupInterval < 0 || c.RawRetention < 0 {
+		return errors.New("history durations must be >= 0")
+	}
+	return nil
+}
+
+type HistoryService struct {
+	repo    repository.SampleRepo
+	rollups repository.SampleRollupRepo // nil: Run summarises nothing
+	cfg     HistoryConfig
+	now     func() time.Time
+}
+
+func NewHistoryService(repo repository.SampleRepo) *HistoryService {
+	return &HistoryService{repo: repo, now: time.Now}
+}
+
+// History returns the temperature between f.From and f.To reduced to
+// min/max/avg per resolution interval.
+func (s *HistoryService) History(ctx context.Context, f HistoryFilter) (_ models.TemperatureHistory, err error) {
+	ctx, sp := startSpan(ctx, "History.History", rangeAttrs(f.From, f.To)...)
+	defer func() { endSpan(sp, err) }()
+
+	from, to := normalizeToUTC(f.From), normalizeToUTC(f.To)
+	if to.IsZero() {
+		to = s.now().UTC()
+	}
+	if from.IsZero() {
+		from = to.Add(-DefaultHistoryWindow)
+	}
+	if from.After(to) {
+		return models.TemperatureHistory{}, fmt.Errorf("%w: %w", ErrInvalidHistoryQuery, errInvalidTimeRange)
+	}
+
+	span := to.Sub(from)
+	res := f.Resolution
+	if res == 0 {
+		res = (span/DefaultHistoryBuckets + time.Second - 1).Truncate(time.Second)
+		res = max(res, time.Second)
+	}
+	if res < time.Second || res%time.Second != 0 {
+		return models.TemperatureHistory{}, fmt.Errorf("%w: resolution must be a whole number of seconds", ErrInvalidHistoryQuery)
+	}
+	if span/res >= MaxHistoryBuckets {
+		return models.TemperatureHistory{}, fmt.Errorf("%w: more than %d buckets; use a coarser resolution", ErrInvalidHistoryQuery, MaxHistoryBuckets)
+	}
+
+	buckets, err := s.repo.Buckets(ctx, repository.HistoryQuery{From: from, To: to, Resolution: res})
+	if err != nil {
+		return models.TemperatureHistory{}, err
+	}
+	return models.TemperatureHistory{
+		From:        from,
+		To:          to,
+		ResolutionS: int(res / time.Second),
+		Count:       len(buckets),
+		Buckets:     buckets,
+	}, nil
+}
+
+// Run summarises the finished hours and days of the temperature history
+// every interval, and prunes the samples past RawRetention that the
+// summaries cover, until ctx is canceled.
+func (s *HistoryService) Run(ctx context.Context) {
+	every := s.cfg.RollupInterval
+	if every <= 0 {
+		every = DefaultRollupInterval
+	}
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		s.rollup(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *HistoryService) rollup(ctx context.Context) {
+	if s.rollups == nil {
+		return
+	}
+	now := s.now().UTC()
+	if _, err := s.rollups.Rollup(ctx, now); err != nil {
+		return // retried on the next run; nothing is pruned meanwhile
+	}
+	if s.cfg.RawRetention > 0 {
+		_, _ = s.rollups.PruneSamples(ctx, now.Add(-s.cfg.RawRetention))
+	}
+}
+
+// recordSample appends this tick's point to the temperature history.
+func (s *SimulatorService) recordSample(ctx context.Context, st models.FurnaceState, now time.Time) {
+	if s.sampleRepo == nil {
+		return
+	}
+	_ = s.sampleRepo.Append(ctx, models.FurnaceSample{
+		At:      now.UTC(),
+		TempC:   st.CurrentTempC,
+		TargetC: st.TargetTempC,
+		Mode:    st.Mode,
+	})
+}
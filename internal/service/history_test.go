@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+type sampleRepoStub struct {
+	appended []models.FurnaceSample
+	lastQ    repository.HistoryQuery
+}
+
+func (r *sampleRepoStub) Append(ctx context.Context, s models.FurnaceSample) error {
+	r.appended = append(r.appended, s)
+	return nil
+}
+
+func (r *sampleRepoStub) Buckets(ctx context.Context, q repository.HistoryQuery) ([]models.HistoryBucket, error) {
+	r.lastQ = q
+	return []models.HistoryBucket{{Start: q.From, Samples: 1}}, nil
+}
+
+// rollupRepoStub records rollups and prunes.
+type rollupRepoStub struct {
+	rollupErr error
+	rolled    []time.Time
+	pruned    []time.Time
+}
+
+func (r *rollupRepoStub) Rollup(ctx context.Context, before time.Time) (int, error) {
+	r.rolled = append(r.rolled, before)
+	return 1, r.rollupErr
+}
+
+func (r *rollupRepoStub) PruneSamples(ctx context.Context, before time.Time) (int64, error) {
+	r.pruned = append(r.pruned, before)
+	return 1, nil
+}
+
+func TestHistoryService_RollupPrunesAfterSummarising(t *testing.T) {
+	now := time.Date(2025, 9, 20, 12, 0, 0, 0, time.UTC)
+	rollups := &rollupRepoStub{}
+	svc := NewHistoryService(&sampleRepoStub{})
+	svc.rollups, svc.cfg = rollups, HistoryConfig{RawRetention: 7 * 24 * time.Hour}
+	svc.now = func() time.Time { return now }
+
+	svc.rollup(context.Background())
+	if len(rollups.rolled) != 1 || !rollups.rolled[0].Equal(now) || len(rollups.pruned) != 1 || !rollups.pruned[0].Equal(now.Add(-7*24*time.Hour)) {
+		t.Fatalf("rolled %v, pruned %v", rollups.rolled, rollups.pruned)
+	}
+
+	// nothing is pruned while summarising fails
+	rollups.rollupErr = errors.New("disk full")
+	svc.rollup(context.Background())
+	if len(rollups.pruned) != 1 {
+		t.Fatalf("pruned after a failed rollup: %v", rollups.pruned)
+	}
+	// and nothing at all without a retention
+	rollups.rollupErr, svc.cfg.RawRetention = nil, 0
+	svc.rollup(context.Background())
+	if len(rollups.rolled) != 3 || len(rollups.pruned) != 1 {
+		t.Fatalf("rolled %v, pruned %v without a retention", rollups.rolled, rollups.pruned)
+	}
+	if err := (HistoryConfig{RawRetention: -time.Hour}).Validate(); err == nil {
+		t.Fatal("expected a negative retention to be rejected")
+	}
+}
+
+func TestHistoryService_DefaultsAndLimits(t *testing.T) {
+	repo := &sampleRepoStub{}
+	svc := NewHistoryService(repo)
+	now := time.Date(2025, 9, 20, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	hist, err := svc.History(ctx, HistoryFilter{})
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	// one hour over 500 buckets rounds up to 8s
+	if !hist.From.Equal(now.Add(-time.Hour)) || !hist.To.Equal(now) || hist.ResolutionS != 8 || hist.Count != 1 {
+		t.Fatalf("unexpected defaults: %+v", hist)
+	}
+	if repo.lastQ.Resolution != 8*time.Second {
+		t.Fatalf("unexpected query: %+v", repo.lastQ)
+	}
+
+	if _, err := svc.History(ctx, HistoryFilter{Resolution: time.Minute}); err != nil || repo.lastQ.Resolution != time.Minute {
+		t.Fatalf("explicit resolution not used: %+v, %v", repo.lastQ, err)
+	}
+
+	for _, f := range []HistoryFilter{
+		{From: now, To: now.Add(-time.Minute)},
+		{Resolution: 1500 * time.Millisecond},
+		{From: now.Add(-24 * time.Hour), Resolution: time.Second},
+	} {
+		if _, err := svc.History(ctx, f); !errors.Is(err, ErrInvalidHistoryQuery) {
+			t.Fatalf("%+v: expected ErrInvalidHistoryQuery, got %v", f, err)
+		}
+	}
+}
+
+func TestTick_RecordsTemperatureSample(t *testing.T) {
+	now := time.Now()
+	states := &simStateRepoStub{loadResp: heatingState(now)}
+	samples := &sampleRepoStub{}
+	svc := NewSimulatorServiceWithConfig(states, &simEventRepoStub{}, nil, nil, nil, DefaultSimConfig())
+	svc.sampleRepo = samples
+
+	svc.tick(context.Background(), now)
+
+	if len(samples.appended) != 1 {
+		t.Fatalf("expected one sample per tick, got %d", len(samples.appended))
+	}
+	st, got := states.saves[0], samples.appended[0]
+	if !got.At.Equal(now.UTC()) || got.TempC != st.CurrentTempC || got.TargetC != st.TargetTempC || got.Mode != ModeHeat {
+		t.Fatalf("sample %+v does not match state %+v", got, st)
+	}
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// NodeIDs returns an ID source for controllers that sync into one central
+// store: random UUIDs prefixed with node, e.g. "kiln-2:3f1c…", so the origin
+// of every event and run stays visible after the merge. An empty node
+// yields plain UUIDs.
+func NodeIDs(node string) func() string {
+	node = strings.TrimSpace(node)
+	if node == "" {
+		return uuid.NewString
+	}
+	return func() string { return node + ":" + uuid.NewString() }
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestNodeIDs(t *testing.T) {
+	id := NodeIDs(" kiln-2 ")()
+	if !strings.HasPrefix(id, "kiln-2:") || len(id) != len("kiln-2:")+36 {
+		t.Fatalf("unexpected node ID %q", id)
+	}
+	if id := NodeIDs("")(); len(id) != 36 {
+		t.Fatalf("expected a plain UUID without a node, got %q", id)
+	}
+}
+
+func TestInjectedIDsAndClock(t *testing.T) {
+	n := 0
+	ids := func() string { n++; return "edge-1:" + strings.Repeat("0", n) }
+	at := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return at }
+
+	srepo := &fakeStateRepo{loadResp: models.FurnaceState{ID: 1, Mode: "STANDBY", CurrentTempC: 25, IsRunning: true}}
+	erepo := &localEventRepo{}
+	fs := &FurnaceService{state: NewStateManager(srepo), eventRepo: erepo, clock: clock, ids: ids}
+	if err := fs.SetMode(context.Background(), ModeParams{Mode: "HEAT", TargetTempC: 500, DurationSec: 60}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	st := lastSavedState(t, srepo)
+	if st.RunID != "edge-1:0" || erepo.events[0].EventID != "edge-1:00" || !erepo.events[0].OccurredAt.Equal(at) {
+		t.Fatalf("expected injected IDs and time, got run %q and %+v", st.RunID, erepo.events[0])
+	}
+
+	events := &simEventRepoStub{}
+	sim := NewSimulatorService(&simStateRepoStub{loadResp: heatingState(at)}, events)
+	sim.newID, sim.now = ids, clock
+	_ = sim.InjectFault(FaultPowerLoss)
+	sim.tick(context.Background(), at)
+	if len(events.appends) != 1 || events.appends[0].EventID != "edge-1:000" {
+		t.Fatalf("expected the simulator to use the injected IDs, got %+v", events.appends)
+	}
+	if f := sim.ActiveFaults(); len(f) != 1 || !f[0].InjectedAt.Equal(at) {
+		t.Fatalf("expected faults stamped by the injected clock, got %+v", f)
+	}
+}
@@ -0,0 +1,472 @@
+package service
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"controlling_furnace/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// MaxImportErrors caps the row errors listed in an ImportReport.
+const MaxImportErrors = 100
+
+// Errors returned by ImportService.
+var (
+	ErrInvalidImport  = errors.New("invalid import")
+	ErrUnknownMapping = errors.New("unknown import mapping")
+)
+
+// importNamespace seeds event IDs derived from row content, so re-importing
+// a file without an ID column does not duplicate its events.
+var importNamespace = uuid.MustParse("6f1c1d8e-3b7a-4c55-9a57-2f4e0c1a9b10")
+
+// ImportMapping describes the CSV layout of a legacy export. Column names
+// are matched case-insensitively.
+type ImportMapping struct {
+	Delimiter  string `mapstructure:"delimiter"`   // one character; "," when empty
+	TimeColumn string `mapstructure:"time_column"` // required
+	// TimeLayout is a Go time layout, "unix" or "unix_ms". When empty,
+	// RFC 3339 and "2006-01-02 15:04:05" are accepted.
+	TimeLayout string `mapstructure:"time_layout"`
+	Timezone   string `mapstructure:"timezone"` // for layouts without an offset; UTC when empty
+
+	// Telemetry in wide form, one column per channel (column -> channel)...
+	Channels map[string]string `mapstructure:"channels"`
+	// ...or in long form, one sample per row.
+	ChannelColumn string `mapstructure:"channel_column"`
+	ValueColumn   string `mapstructure:"value_column"`
+
+	// Events.
+	IDColumn          string            `mapstructure:"id_column"` // optional
+	TypeColumn        string            `mapstructure:"type_column"`
+	DescriptionColumn string            `mapstructure:"description_column"`
+	Types             map[string]string `mapstructure:"types"`        // legacy type -> event type
+	MetaColumns       []string          `mapstructure:"meta_columns"` // copied into metadata
+}
+
+// Built-in mappings matching this system's own field names, used when no
+// mapping is named and none called "default" is configured.
+var (
+	defaultTelemetryMapping = ImportMapping{TimeColumn: "at", ChannelColumn: "channel", ValueColumn: "value"}
+	defaultEventMapping     = ImportMapping{IDColumn: "event_id", TimeColumn: "occurred_at", TypeColumn: "type", DescriptionColumn: "description"}
+)
+
+// ImportConfig holds the named mappings available to importers.
+type ImportConfig struct {
+	Mappings map[string]ImportMapping `mapstructure:"mappings"`
+}
+
+// Validate checks every configured mapping.
+func (c ImportConfig) Validate() error {
+	for name, m := range c.Mappings {
+		if err := m.validate(); err != nil {
+			return fmt.Errorf("mapping %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (m ImportMapping) validate() error {
+	if utf8.RuneCountInString(m.Delimiter) > 1 {
+		return fmt.Errorf("%w: delimiter must be a single character", ErrInvalidImport)
+	}
+	if strings.TrimSpace(m.TimeColumn) == "" {
+		return fmt.Errorf("%w: time_column is required", ErrInvalidImport)
+	}
+	if _, err := time.LoadLocation(m.Timezone); err != nil {
+		return fmt.Errorf("%w: timezone: %v", ErrInvalidImport, err)
+	}
+	if (m.ChannelColumn == "") != (m.ValueColumn == "") {
+		return fmt.Errorf("%w: channel_column and value_column go together", ErrInvalidImport)
+	}
+	for col, ch := range m.Channels {
+		if !knownChannel(ch) {
+			return fmt.Errorf("%w: column %q maps to unknown channel %q", ErrInvalidImport, col, ch)
+		}
+	}
+	return nil
+}
+
+func knownChannel(ch string) bool {
+	switch ch {
+	case models.ChannelChamber, models.ChannelAmbient, models.ChannelO2, models.ChannelGasFlow:
+		return true
+	}
+	return false
+}
+
+// ImportRowError reports a CSV row that was skipped.
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportReport summarizes one import.
+type ImportReport struct {
+	Rows       int              `json:"rows"`       // data rows read
+	Imported   int              `json:"imported"`   // records written
+	Duplicates int              `json:"duplicates"` // valid records already stored
+	Invalid    int              `json:"invalid"`    // rows skipped by validation
+	Errors     []ImportRowError `json:"errors,omitempty"`
+}
+
+func (r *ImportReport) reject(line int, err error) {
+	r.Invalid++
+	if len(r.Errors) < MaxImportErrors {
+		r.Errors = append(r.Errors, ImportRowError{Line: line, Error: err.Error()})
+	}
+}
+
+type ImportService struct {
+	repo     repository.ImportRepo
+	mappings map[string]ImportMapping
+	now      func() time.Time
+}
+
+func NewImportService(repo repository.ImportRepo, cfg ImportConfig) *ImportService {
+	mappings := make(map[string]ImportMapping, len(cfg.Mappings))
+	for name, m := range cfg.Mappings {
+		mappings[strings.ToLower(name)] = m
+	}
+	return &ImportService{repo: repo, mappings: mappings, now: time.Now}
+}
+
+func (s *ImportService) mapping(name string, fallback ImportMapping) (ImportMapping, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if m, ok := s.mappings[name]; ok {
+		return m, nil
+	}
+	if name == "" {
+		if m, ok := s.mappings["default"]; ok {
+			return m, nil
+		}
+		return fallback, nil
+	}
+	return ImportMapping{}, fmt.Errorf("%w: %q", ErrUnknownMapping, name)
+}
+
+// ImportTelemetry reads samples from CSV using the named mapping. Invalid
+// rows are skipped and listed in the report; a malformed file or missing
+// column fails the whole import without writing anything.
+func (s *ImportService) ImportTelemetry(ctx context.Context, mapping string, r io.Reader) (ImportReport, error) {
+	var rep ImportReport
+	m, err := s.mapping(mapping, defaultTelemetryMapping)
+	if err != nil {
+		return rep, err
+	}
+	if len(m.Channels) == 0 && m.ChannelColumn == "" {
+		return rep, fmt.Errorf("%w: mapping has no telemetry columns", ErrInvalidImport)
+	}
+	t, err := newCSVTable(r, m)
+	if err != nil {
+		return rep, err
+	}
+	type channelColumn struct {
+		index   int
+		channel string
+	}
+	var (
+		timeCol, chanCol, valCol int
+		wide                     []channelColumn
+	)
+	if timeCol, err = t.column(m.TimeColumn); err != nil {
+		return rep, err
+	}
+	if m.ChannelColumn != "" {
+		if chanCol, err = t.column(m.ChannelColumn); err != nil {
+			return rep, err
+		}
+		if valCol, err = t.column(m.ValueColumn); err != nil {
+			return rep, err
+		}
+	}
+	for col, ch := range m.Channels {
+		i, err := t.column(col)
+		if err != nil {
+			return rep, err
+		}
+		wide = append(wide, channelColumn{i, ch})
+	}
+	sort.Slice(wide, func(a, b int) bool { return wide[a].index < wide[b].index })
+
+	var samples []models.TelemetrySample
+	for {
+		row, line, err := t.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return rep, err
+		}
+		rep.Rows++
+		at, err := s.rowTime(m, t.cell(row, timeCol))
+		if err != nil {
+			rep.reject(line, err)
+			continue
+		}
+		var rowSamples []models.TelemetrySample
+		if m.ChannelColumn != "" {
+			ch := strings.ToLower(t.cell(row, chanCol))
+			if !knownChannel(ch) {
+				rep.reject(line, fmt.Errorf("unknown channel %q", ch))
+				continue
+			}
+			v, err := parseReading(t.cell(row, valCol))
+			if err != nil {
+				rep.reject(line, err)
+				continue
+			}
+			rowSamples = append(rowSamples, models.TelemetrySample{At: at, Channel: ch, Value: v})
+		}
+		for _, wc := range wide {
+			cell := t.cell(row, wc.index)
+			if cell == "" {
+				continue // channel not recorded in this row
+			}
+			v, err := parseReading(cell)
+			if err != nil {
+				rowSamples = nil
+				rep.reject(line, fmt.Errorf("column %q: %w", t.header[wc.index], err))
+				break
+			}
+			rowSamples = append(rowSamples, models.TelemetrySample{At: at, Channel: wc.channel, Value: v})
+		}
+		samples = append(samples, rowSamples...)
+	}
+
+	n, err := s.repo.ImportTelemetry(ctx, samples)
+	if err != nil {
+		return rep, err
+	}
+	rep.Imported, rep.Duplicates = n, len(samples)-n
+	return rep, nil
+}
+
+// ImportEvents reads events from CSV using the named mapping. Rows without
+// an ID get one derived from their content, so repeated imports are no-ops.
+func (s *ImportService) ImportEvents(ctx context.Context, mapping string, r io.Reader) (ImportReport, error) {
+	var rep ImportReport
+	m, err := s.mapping(mapping, defaultEventMapping)
+	if err != nil {
+		return rep, err
+	}
+	if m.TypeColumn == "" {
+		return rep, fmt.Errorf("%w: mapping has no type_column", ErrInvalidImport)
+	}
+	t, err := newCSVTable(r, m)
+	if err != nil {
+		return rep, err
+	}
+	timeCol, err := t.column(m.TimeColumn)
+	if err != nil {
+		return rep, err
+	}
+	typeCol, err := t.column(m.TypeColumn)
+	if err != nil {
+		return rep, err
+	}
+	idCol, descCol := -1, -1
+	if m.IDColumn != "" {
+		if idCol, err = t.column(m.IDColumn); err != nil {
+			return rep, err
+		}
+	}
+	if m.DescriptionColumn != "" {
+		if descCol, err = t.column(m.DescriptionColumn); err != nil {
+			return rep, err
+		}
+	}
+	metaCols := make([]int, 0, len(m.MetaColumns))
+	for _, col := range m.MetaColumns {
+		i, err := t.column(col)
+		if err != nil {
+			return rep, err
+		}
+		metaCols = append(metaCols, i)
+	}
+	types := make(map[string]string, len(m.Types))
+	for legacy, typ := range m.Types {
+		types[strings.ToLower(legacy)] = typ
+	}
+
+	var events []models.FurnaceEvent
+	for {
+		row, line, err := t.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return rep, err
+		}
+		rep.Rows++
+		at, err := s.rowTime(m, t.cell(row, timeCol))
+		if err != nil {
+			rep.reject(line, err)
+			continue
+		}
+		typ := t.cell(row, typeCol)
+		if mapped, ok := types[strings.ToLower(typ)]; ok {
+			typ = mapped
+		}
+		typ = strings.ToUpper(typ)
+		if typ == "" {
+			rep.reject(line, errors.New("missing event type"))
+			continue
+		}
+		ev := models.FurnaceEvent{OccurredAt: at, Type: typ, Description: t.cell(row, descCol)}
+		if len(metaCols) > 0 {
+			meta := make(map[string]any, len(metaCols))
+			for _, i := range metaCols {
+				if v := t.cell(row, i); v != "" {
+					meta[t.header[i]] = v
+				}
+			}
+			if len(meta) > 0 {
+				ev.Metadata = meta
+			}
+		}
+		ev.EventID = t.cell(row, idCol)
+		if ev.EventID == "" {
+			key := at.UTC().Format(time.RFC3339Nano) + "|" + typ + "|" + ev.Description
+			ev.EventID = uuid.NewSHA1(importNamespace, []byte(key)).String()
+		}
+		events = append(events, ev)
+	}
+
+	n, err := s.repo.ImportEvents(ctx, events)
+	if err != nil {
+		return rep, err
+	}
+	rep.Imported, rep.Duplicates = n, len(events)-n
+	return rep, nil
+}
+
+// rowTime parses a timestamp cell and rejects times in the future.
+func (s *ImportService) rowTime(m ImportMapping, v string) (time.Time, error) {
+	at, err := parseImportTime(m, v)
+	if err != nil {
+		return at, err
+	}
+	if at.After(s.now()) {
+		return at, fmt.Errorf("timestamp %q is in the future", v)
+	}
+	return at.UTC(), nil
+}
+
+func parseImportTime(m ImportMapping, v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, errors.New("missing timestamp")
+	}
+	loc, err := time.LoadLocation(m.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch m.TimeLayout {
+	case "unix":
+		secs, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid unix timestamp %q", v)
+		}
+		whole, frac := math.Modf(secs)
+		return time.Unix(int64(whole), int64(frac*1e9)), nil
+	case "unix_ms":
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid unix_ms timestamp %q", v)
+		}
+		return time.UnixMilli(ms), nil
+	case "":
+		if at, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return at, nil
+		}
+		if at, err := time.ParseInLocation("2006-01-02 15:04:05", v, loc); err == nil {
+			return at, nil
+		}
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", v)
+	default:
+		at, err := time.ParseInLocation(m.TimeLayout, v, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("timestamp %q does not match layout %q", v, m.TimeLayout)
+		}
+		return at, nil
+	}
+}
+
+func parseReading(v string) (float64, error) {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("invalid value %q", v)
+	}
+	return f, nil
+}
+
+// csvTable reads a CSV file with a header row.
+type csvTable struct {
+	r      *csv.Reader
+	header []string
+	index  map[string]int
+}
+
+func newCSVTable(r io.Reader, m ImportMapping) (*csvTable, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1 // short rows are reported per row
+	cr.TrimLeadingSpace = true
+	if m.Delimiter != "" {
+		cr.Comma, _ = utf8.DecodeRuneInString(m.Delimiter)
+	}
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: empty file", ErrInvalidImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	t := &csvTable{r: cr, header: header, index: make(map[string]int, len(header))}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		t.header[i] = h
+		t.index[h] = i
+	}
+	return t, nil
+}
+
+func (t *csvTable) column(name string) (int, error) {
+	i, ok := t.index[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, fmt.Errorf("%w: missing column %q", ErrInvalidImport, name)
+	}
+	return i, nil
+}
+
+// next returns the next row and its line number. Malformed CSV is fatal.
+func (t *csvTable) next() ([]string, int, error) {
+	row, err := t.r.Read()
+	if err == io.EOF {
+		return nil, 0, err
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	line, _ := t.r.FieldPos(0)
+	return row, line, nil
+}
+
+// cell returns the trimmed value at i, or "" if the row is short or i < 0.
+func (t *csvTable) cell(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
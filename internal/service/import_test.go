@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// importRepoStub keeps what was imported and skips repeats like the
+// SQLite implementation does.
+type importRepoStub struct {
+	events  map[string]models.FurnaceEvent
+	samples map[string]models.TelemetrySample
+}
+
+func newImportRepoStub() *importRepoStub {
+	return &importRepoStub{events: map[string]models.FurnaceEvent{}, samples: map[string]models.TelemetrySample{}}
+}
+
+func (r *importRepoStub) ImportEvents(ctx context.Context, events []models.FurnaceEvent) (int, error) {
+	n := 0
+	for _, e := range events {
+		if _, ok := r.events[e.EventID]; !ok {
+			r.events[e.EventID] = e
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *importRepoStub) ImportTelemetry(ctx context.Context, samples []models.TelemetrySample) (int, error) {
+	n := 0
+	for _, s := range samples {
+		key := s.Channel + "|" + s.At.Format(time.RFC3339Nano)
+		if _, ok := r.samples[key]; !ok {
+			r.samples[key] = s
+			n++
+		}
+	}
+	return n, nil
+}
+
+func TestImportTelemetry_WideLegacyExport(t *testing.T) {
+	repo := newImportRepoStub()
+	svc := NewImportService(repo, ImportConfig{Mappings: map[string]ImportMapping{
+		"Legacy": {
+			Delimiter:  ";",
+			TimeColumn: "Timestamp",
+			TimeLayout: "02.01.2006 15:04:05",
+			Channels:   map[string]string{"chamber_t": models.ChannelChamber, "ambient_t": models.ChannelAmbient},
+		},
+	}})
+	svc.now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	csv := "Timestamp;Chamber_T;Ambient_T\n" +
+		"01.05.2023 08:00:00;640.5;24\n" +
+		"01.05.2023 08:00:01;641;\n" + // ambient not logged
+		"01.05.2023 08:00:02;n/a;24\n" +
+		"01.05.2030 08:00:00;650;24\n" // future
+	rep, err := svc.ImportTelemetry(context.Background(), "legacy", strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ImportTelemetry: %v", err)
+	}
+	if rep.Rows != 4 || rep.Imported != 3 || rep.Invalid != 2 || rep.Duplicates != 0 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	if len(rep.Errors) != 2 || rep.Errors[0].Line != 4 || rep.Errors[1].Line != 5 {
+		t.Fatalf("expected errors on lines 4 and 5, got %+v", rep.Errors)
+	}
+	want := models.TelemetrySample{At: time.Date(2023, 5, 1, 8, 0, 0, 0, time.UTC), Channel: models.ChannelChamber, Value: 640.5}
+	if got := repo.samples[models.ChannelChamber+"|2023-05-01T08:00:00Z"]; got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	rep, err = svc.ImportTelemetry(context.Background(), "legacy", strings.NewReader(csv))
+	if err != nil || rep.Imported != 0 || rep.Duplicates != 3 {
+		t.Fatalf("re-import should only find duplicates, got %+v, %v", rep, err)
+	}
+}
+
+func TestImportEvents_DerivedIDsDeduplicate(t *testing.T) {
+	repo := newImportRepoStub()
+	svc := NewImportService(repo, ImportConfig{Mappings: map[string]ImportMapping{
+		"legacy": {
+			TimeColumn:        "time",
+			TimeLayout:        "unix",
+			TypeColumn:        "code",
+			DescriptionColumn: "text",
+			Types:             map[string]string{"e01": "ERROR", "run": "START"},
+			MetaColumns:       []string{"operator"},
+		},
+	}})
+
+	csv := "time,code,text,operator\n" +
+		"1682928000,RUN,cycle started,alice\n" +
+		"1682928060,E01,door open,\n" +
+		"1682928120,,no code,bob\n"
+	for i, wantImported := range []int{2, 0} {
+		rep, err := svc.ImportEvents(context.Background(), "legacy", strings.NewReader(csv))
+		if err != nil {
+			t.Fatalf("run %d: %v", i, err)
+		}
+		if rep.Rows != 3 || rep.Invalid != 1 || rep.Imported != wantImported || rep.Imported+rep.Duplicates != 2 {
+			t.Fatalf("run %d: unexpected report %+v", i, rep)
+		}
+	}
+
+	var start models.FurnaceEvent
+	for _, e := range repo.events {
+		if e.Type == "START" {
+			start = e
+		}
+	}
+	meta, _ := start.Metadata.(map[string]any)
+	if start.Description != "cycle started" || meta["operator"] != "alice" || !start.OccurredAt.Equal(time.Unix(1682928000, 0)) {
+		t.Fatalf("unexpected event: %+v", start)
+	}
+}
+
+func TestImport_RejectsBadInput(t *testing.T) {
+	svc := NewImportService(newImportRepoStub(), ImportConfig{})
+
+	if _, err := svc.ImportEvents(context.Background(), "nope", strings.NewReader("")); !errors.Is(err, ErrUnknownMapping) {
+		t.Fatalf("expected ErrUnknownMapping, got %v", err)
+	}
+	if _, err := svc.ImportTelemetry(context.Background(), "", strings.NewReader("at,value\n")); !errors.Is(err, ErrInvalidImport) {
+		t.Fatalf("expected ErrInvalidImport for a missing column, got %v", err)
+	}
+	if _, err := svc.ImportTelemetry(context.Background(), "", strings.NewReader("at,channel,value\n\"x,1\n")); !errors.Is(err, ErrInvalidImport) {
+		t.Fatalf("expected ErrInvalidImport for malformed CSV, got %v", err)
+	}
+
+	bad := ImportConfig{Mappings: map[string]ImportMapping{"x": {TimeColumn: "t", Channels: map[string]string{"t1": "exhaust"}}}}
+	if err := bad.Validate(); !errors.Is(err, ErrInvalidImport) {
+		t.Fatalf("expected unknown channel to be rejected, got %v", err)
+	}
+}
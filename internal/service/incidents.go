@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Limits for incident listings and reports.
+const (
+	DefaultIncidentLimit = 50
+	MaxIncidentLimit     = 500
+	MaxIncidentEvents    = 500 // events kept in a report; the earliest win
+
+	incidentBuckets = 60          // telemetry excerpt aims for about this many buckets
+	incidentMargin  = time.Minute // telemetry kept before and after the episode
+)
+
+var (
+	// ErrIncidentNotFound is returned when no incident exists for an ID.
+	ErrIncidentNotFound = errors.New("incident not found")
+	// ErrIncidentAcknowledged is returned when acknowledging twice.
+	ErrIncidentAcknowledged = errors.New("incident already acknowledged")
+)
+
+// IncidentFilter selects incidents by start time and alarm.
+type IncidentFilter struct {
+	From  time.Time // inclusive; zero means no lower bound
+	To    time.Time // inclusive; zero means no upper bound
+	Alarm string    // "" or an alarm code the incident raised, e.g. OVERHEAT
+	Limit int       // 0 means DefaultIncidentLimit; capped at MaxIncidentLimit
+}
+
+// IncidentService compiles an incident record for every alarm episode the
+// simulator publishes: from the first error code raised until none remain.
+type IncidentService struct {
+	repo    repository.IncidentRepo
+	events  repository.EventRepo
+	samples repository.SampleRepo // optional; reports carry no telemetry when nil
+	bus     *StateBroker
+	now     func() time.Time
+
+	// tracker state, owned by Run
+	current *models.Incident
+	resumed bool      // looked for an incident left open by a previous process
+	last    time.Time // time of the previous state
+	hot     bool      // the previous state had OVERHEAT raised
+}
+
+func NewIncidentService(repo repository.IncidentRepo, events repository.EventRepo, samples repository.SampleRepo, bus *StateBroker) *IncidentService {
+	return &IncidentService{repo: repo, events: events, samples: samples, bus: bus, now: time.Now}
+}
+
+// ListIncidents returns incidents without their events and telemetry,
+// newest first.
+func (s *IncidentService) ListIncidents(ctx context.Context, f IncidentFilter) (_ []models.Incident, err error) {
+	ctx, span := startSpan(ctx, "Incidents.List", rangeAttrs(f.From, f.To)...)
+	defer func() { endSpan(span, err) }()
+
+	from, to := normalizeToUTC(f.From), normalizeToUTC(f.To)
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return nil, errInvalidTimeRange
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultIncidentLimit
+	}
+	if limit > MaxIncidentLimit {
+		limit = MaxIncidentLimit
+	}
+	out, err := s.repo.List(ctx, repository.IncidentQuery{From: from, To: to, Alarm: normalizeEventType(f.Alarm), Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		out[i] = s.describe(out[i])
+	}
+	return out, nil
+}
+
+// GetIncident returns the full record of an incident.
+func (s *IncidentService) GetIncident(ctx context.Context, id int64) (_ models.Incident, err error) {
+	ctx, span := startSpan(ctx, "Incidents.Get", attribute.Int64("incident.id", id))
+	defer func() { endSpan(span, err) }()
+
+	inc, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return models.Incident{}, err
+	}
+	if inc.ID == 0 {
+		return models.Incident{}, ErrIncidentNotFound
+	}
+	return s.describe(inc), nil
+}
+
+// AcknowledgeIncident records userID as the operator who acknowledged the
+// incident. Open incidents can be acknowledged; only the first
+// acknowledgement counts.
+func (s *IncidentService) AcknowledgeIncident(ctx context.Context, id int64, userID int) (models.Incident, error) {
+	found, err := s.repo.Acknowledge(ctx, id, userID, s.now())
+	if err != nil {
+		return models.Incident{}, err
+	}
+	inc, err := s.GetIncident(ctx, id)
+	if err != nil {
+		return models.Incident{}, err
+	}
+	if !found {
+		return models.Incident{}, ErrIncidentAcknowledged
+	}
+	return inc, nil
+}
+
+// describe fills DurationS, which runs on the clock for open incidents.
+func (s *IncidentService) describe(inc models.Incident) models.Incident {
+	end := s.now()
+	if inc.EndedAt != nil {
+		end = *inc.EndedAt
+	}
+	inc.DurationS = math.Max(end.Sub(inc.StartedAt).Seconds(), 0)
+	return inc
+}
+
+// Run tracks alarm episodes in the published states until ctx is canceled.
+func (s *IncidentService) Run(ctx context.Context) {
+	if s.repo == nil || s.bus == nil {
+		return
+	}
+	updates, cancel := s.bus.Subscribe(16)
+	defer cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case st := <-updates:
+			s.track(ctx, st)
+		}
+	}
+}
+
+// track opens, updates or closes the current incident for st. Failed
+// writes are retried on the next state.
+func (s *IncidentService) track(ctx context.Context, st models.FurnaceState) {
+	if !s.resumed {
+		inc, err := s.repo.Current(ctx)
+		if err != nil {
+			return
+		}
+		if inc.ID != 0 {
+			s.current = &inc
+		}
+		s.resumed = true
+	}
+
+	at := st.UpdatedAt.UTC()
+	if at.IsZero() {
+		at = s.now().UTC()
+	}
+	// the chamber was above MaxSafeC from the previous state until this one
+	if s.current != nil && s.hot && at.After(s.last) {
+		s.current.OverheatS += at.Sub(s.last).Seconds()
+	}
+	s.last, s.hot = at, hasString(st.ErrorCodes, AlarmOverheat)
+
+	switch {
+	case len(st.ErrorCodes) == 0:
+		if s.current != nil {
+			s.finish(ctx, at, resolution(st))
+		}
+	case s.current == nil:
+		inc := models.Incident{StartedAt: at, RunID: st.RunID}
+		observe(&inc, st)
+		id, err := s.repo.Create(ctx, inc)
+		if err != nil {
+			return
+		}
+		inc.ID = id
+		s.current = &inc
+	default:
+		// peaks are saved on close; new codes right away
+		if observe(s.current, st) {
+			_, _ = s.repo.Update(ctx, *s.current)
+		}
+	}
+}
+
+// observe folds st into inc and reports whether a new code was raised.
+func observe(inc *models.Incident, st models.FurnaceState) bool {
+	inc.PeakTempC = math.Max(inc.PeakTempC, st.CurrentTempC)
+	inc.PeakMeasuredC = math.Max(inc.PeakMeasuredC, st.MeasuredTempC)
+	added := false
+	for _, code := range st.ErrorCodes {
+		if !hasString(inc.AlarmCodes, code) {
+			inc.AlarmCodes = append(inc.AlarmCodes, code)
+			added = true
+		}
+	}
+	return added
+}
+
+// resolution tells how the episode that cleared in st ended.
+func resolution(st models.FurnaceState) string {
+	if st.IsRunning {
+		return models.IncidentCleared
+	}
+	return models.IncidentStopped
+}
+
+// finish compiles the report of the current incident, which ended at end
+// as told by how. The related events and telemetry are best effort: the
+// incident is still closed without them.
+func (s *IncidentService) finish(ctx context.Context, end time.Time, how string) {
+	inc := *s.current
+	if inc.EndedAt == nil {
+		inc.EndedAt = &end
+		inc.Resolution = how
+	}
+	end = *inc.EndedAt
+
+	if s.events != nil {
+		// occurred_at is stored to the second, and a bound in the same second
+		// sorts after it; start a second early to keep the opening ERROR event
+		from := inc.StartedAt.Truncate(time.Second).Add(-time.Second)
+		events, err := s.events.Query(ctx, repository.EventQuery{From: from, To: end})
+		if err == nil {
+			if len(events) > MaxIncidentEvents {
+				events = events[:MaxIncidentEvents]
+			}
+			inc.Events = events
+		}
+	}
+	if s.samples != nil {
+		from, to := inc.StartedAt.Add(-incidentMargin), end.Add(incidentMargin)
+		res := time.Duration(math.Ceil(to.Sub(from).Seconds()/incidentBuckets)) * time.Second
+		buckets, err := s.samples.Buckets(ctx, repository.HistoryQuery{From: from, To: to, Resolution: max(res, time.Second)})
+		if err == nil {
+			inc.Telemetry = buckets
+		}
+	}
+
+	if _, err := s.repo.Update(ctx, inc); err != nil {
+		s.current.EndedAt, s.current.Resolution = inc.EndedAt, inc.Resolution
+		return
+	}
+	s.current = nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+type incidentRepoStub struct {
+	open    models.Incident // returned by Current
+	saved   map[int64]models.Incident
+	acked   map[int64]int
+	creates int
+	updates int
+}
+
+func newIncidentRepoStub() *incidentRepoStub {
+	return &incidentRepoStub{saved: make(map[int64]models.Incident), acked: make(map[int64]int)}
+}
+
+func (r *incidentRepoStub) Create(ctx context.Context, inc models.Incident) (int64, error) {
+	r.creates++
+	inc.ID = int64(len(r.saved) + 1)
+	r.saved[inc.ID] = inc
+	return inc.ID, nil
+}
+func (r *incidentRepoStub) Update(ctx context.Context, inc models.Incident) (bool, error) {
+	r.updates++
+	if _, ok := r.saved[inc.ID]; !ok {
+		return false, nil
+	}
+	r.saved[inc.ID] = inc
+	return true, nil
+}
+func (r *incidentRepoStub) Acknowledge(ctx context.Context, id int64, userID int, at time.Time) (bool, error) {
+	if _, ok := r.saved[id]; !ok || r.acked[id] != 0 {
+		return false, nil
+	}
+	r.acked[id] = userID
+	return true, nil
+}
+func (r *incidentRepoStub) Get(ctx context.Context, id int64) (models.Incident, error) {
+	return r.saved[id], nil
+}
+func (r *incidentRepoStub) Current(ctx context.Context) (models.Incident, error) {
+	return r.open, nil
+}
+func (r *incidentRepoStub) List(ctx context.Context, q repository.IncidentQuery) ([]models.Incident, error) {
+	return nil, nil
+}
+
+type incidentEventsStub struct {
+	simEventRepoStub
+	lastQ repository.EventQuery
+}
+
+func (e *incidentEventsStub) Query(ctx context.Context, q repository.EventQuery) ([]models.FurnaceEvent, error) {
+	e.lastQ = q
+	return []models.FurnaceEvent{{EventID: "e1", OccurredAt: q.From, Type: "ERROR"}}, nil
+}
+
+func TestIncidentService_CompilesEpisode(t *testing.T) {
+	repo := newIncidentRepoStub()
+	events := &incidentEventsStub{}
+	samples := &sampleRepoStub{}
+	svc := NewIncidentService(repo, events, samples, nil)
+	ctx := context.Background()
+
+	now := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	st := heatingState(now)
+	step := func(d time.Duration, temp float64, codes ...string) {
+		st.UpdatedAt, st.CurrentTempC, st.MeasuredTempC, st.ErrorCodes = now.Add(d), temp, temp+2, codes
+		svc.track(ctx, st)
+	}
+	step(0, 900)
+	step(10*time.Second, 1010, "OVERHEAT")
+	step(20*time.Second, 1030, "OVERHEAT")
+	step(30*time.Second, 1020, "OVERHEAT", "SENSOR_FAULT")
+	step(40*time.Second, 980, "SENSOR_FAULT")
+	if repo.creates != 1 || repo.updates != 1 {
+		t.Fatalf("expected one create and an update for the new code, got %d/%d", repo.creates, repo.updates)
+	}
+	if open := repo.saved[1]; !open.Open() || len(open.AlarmCodes) != 2 {
+		t.Fatalf("unexpected open incident: %+v", open)
+	}
+
+	step(70*time.Second, 950)
+	inc := repo.saved[1]
+	if inc.Open() || !inc.StartedAt.Equal(now.Add(10*time.Second)) || !inc.EndedAt.Equal(now.Add(70*time.Second)) {
+		t.Fatalf("unexpected episode bounds: %+v", inc)
+	}
+	if inc.PeakTempC != 1030 || inc.PeakMeasuredC != 1032 || inc.RunID != "run-1" {
+		t.Fatalf("unexpected peaks: %+v", inc)
+	}
+	if inc.OverheatS != 30 || inc.Resolution != models.IncidentCleared {
+		t.Fatalf("expected 30 s above the limit, cleared while running; got %.0f s, %q", inc.OverheatS, inc.Resolution)
+	}
+	if len(inc.Events) != 1 || !events.lastQ.From.Equal(inc.StartedAt.Add(-time.Second)) || !events.lastQ.To.Equal(*inc.EndedAt) {
+		t.Fatalf("expected the episode's events, queried %+v", events.lastQ)
+	}
+	if len(inc.Telemetry) != 1 || !samples.lastQ.From.Equal(inc.StartedAt.Add(-time.Minute)) || samples.lastQ.Resolution != 3*time.Second {
+		t.Fatalf("unexpected telemetry query: %+v", samples.lastQ)
+	}
+
+	step(80*time.Second, 950)
+	if repo.creates != 1 || repo.updates != 2 {
+		t.Fatalf("expected nothing to change once closed, got %d/%d", repo.creates, repo.updates)
+	}
+}
+
+func TestIncidentService_OverheatStoppedByOperator(t *testing.T) {
+	repo := newIncidentRepoStub()
+	svc := NewIncidentService(repo, nil, nil, nil)
+	ctx := context.Background()
+
+	now := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	st := heatingState(now)
+	st.ErrorCodes = []string{AlarmOverheat}
+	for _, d := range []time.Duration{0, 15 * time.Second, 25 * time.Second} {
+		st.UpdatedAt = now.Add(d)
+		svc.track(ctx, st)
+	}
+	// stopped while still hot; the alarm clears as it cools
+	st.IsRunning, st.Mode = false, ModeStandby
+	st.UpdatedAt = now.Add(40 * time.Second)
+	svc.track(ctx, st)
+	st.UpdatedAt, st.ErrorCodes = now.Add(90*time.Second), nil
+	svc.track(ctx, st)
+
+	inc := repo.saved[1]
+	if inc.Open() || inc.OverheatS != 90 || inc.Resolution != models.IncidentStopped {
+		t.Fatalf("expected a stopped incident 90 s above the limit, got %+v", inc)
+	}
+}
+
+func TestIncidentService_ListByAlarm(t *testing.T) {
+	repo := &incidentListStub{incidentRepoStub: newIncidentRepoStub()}
+	svc := NewIncidentService(repo, nil, nil, nil)
+	if _, err := svc.ListIncidents(context.Background(), IncidentFilter{Alarm: " overheat "}); err != nil {
+		t.Fatalf("ListIncidents() error = %v", err)
+	}
+	if repo.lastQ.Alarm != AlarmOverheat || repo.lastQ.Limit != DefaultIncidentLimit {
+		t.Fatalf("unexpected query: %+v", repo.lastQ)
+	}
+}
+
+type incidentListStub struct {
+	*incidentRepoStub
+	lastQ repository.IncidentQuery
+}
+
+func (r *incidentListStub) List(ctx context.Context, q repository.IncidentQuery) ([]models.Incident, error) {
+	r.lastQ = q
+	return nil, nil
+}
+
+func TestIncidentService_ResumesOpenIncident(t *testing.T) {
+	repo := newIncidentRepoStub()
+	started := time.Date(2025, 9, 20, 9, 0, 0, 0, time.UTC)
+	repo.open = models.Incident{ID: 1, StartedAt: started, AlarmCodes: []string{"POWER_LOSS"}, PeakTempC: 700}
+	repo.saved[1] = repo.open
+	svc := NewIncidentService(repo, nil, nil, nil)
+
+	st := heatingState(started.Add(time.Hour))
+	svc.track(context.Background(), st)
+
+	inc := repo.saved[1]
+	if repo.creates != 0 || inc.Open() || inc.PeakTempC != 700 {
+		t.Fatalf("expected the incident left open to be closed, got %+v", inc)
+	}
+}
+
+func TestIncidentService_Acknowledge(t *testing.T) {
+	repo := newIncidentRepoStub()
+	repo.saved[1] = models.Incident{ID: 1, StartedAt: time.Date(2025, 9, 20, 9, 0, 0, 0, time.UTC)}
+	svc := NewIncidentService(repo, nil, nil, nil)
+	svc.now = func() time.Time { return time.Date(2025, 9, 20, 9, 5, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	inc, err := svc.AcknowledgeIncident(ctx, 1, 3)
+	if err != nil || repo.acked[1] != 3 {
+		t.Fatalf("AcknowledgeIncident() = %+v, %v", inc, err)
+	}
+	if inc.DurationS != 300 {
+		t.Fatalf("expected an open incident to run on the clock, got %.0f s", inc.DurationS)
+	}
+	if _, err := svc.AcknowledgeIncident(ctx, 1, 4); !errors.Is(err, ErrIncidentAcknowledged) {
+		t.Fatalf("expected ErrIncidentAcknowledged, got %v", err)
+	}
+	if _, err := svc.AcknowledgeIncident(ctx, 2, 3); !errors.Is(err, ErrIncidentNotFound) {
+		t.Fatalf("expected ErrIncidentNotFound, got %v", err)
+	}
+}
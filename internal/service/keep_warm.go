@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// KeepWarmConfig holds the chamber at a low idle temperature while the
+// furnace runs in STANDBY, so the next cycle does not start from cold. The
+// zero value lets STANDBY drift to the room temperature.
+type KeepWarmConfig struct {
+	SetpointC float64 // °C held in STANDBY; 0 disables keep-warm
+}
+
+// keepWarmSetpoint returns the temperature STANDBY should hold for st, or 0
+// if keep-warm does not apply. A setpoint at or above max_safe_c, which
+// settings may have lowered since start-up, is ignored.
+func (s *SimulatorService) keepWarmSetpoint(st models.FurnaceState) float64 {
+	sp := s.cfg.KeepWarm.SetpointC
+	if sp <= 0 || sp >= s.cfg.Physics.MaxSafeC || !st.IsRunning || st.Mode != ModeStandby {
+		return 0
+	}
+	return sp
+}
+
+// reconcileKeepWarm engages keep-warm when the running furnace is in
+// STANDBY and disengages it once it is stopped or switched to another mode,
+// logging KEEP_WARM_ENGAGED and KEEP_WARM_DISENGAGED. Returns true if st
+// changed.
+func (s *SimulatorService) reconcileKeepWarm(st *models.FurnaceState, now time.Time) bool {
+	want := s.keepWarmSetpoint(*st)
+	prev := st.KeepWarmC
+	if want == prev {
+		return false
+	}
+	st.KeepWarmC = want
+	if want > 0 {
+		s.emit(models.FurnaceEvent{
+			EventID:     s.newID(),
+			OccurredAt:  now.UTC(),
+			Type:        "KEEP_WARM_ENGAGED",
+			Description: "Keep-warm engaged",
+			Metadata: map[string]any{
+				"setpoint_c": want,
+				"temp_c":     st.CurrentTempC,
+			},
+		})
+		return true
+	}
+	s.emit(models.FurnaceEvent{
+		EventID:     s.newID(),
+		OccurredAt:  now.UTC(),
+		Type:        "KEEP_WARM_DISENGAGED",
+		Description: "Keep-warm disengaged",
+		Metadata: withRunID(map[string]any{
+			"setpoint_c": prev,
+			"temp_c":     st.CurrentTempC,
+			"mode":       st.Mode,
+			"isRunning":  st.IsRunning,
+		}, st.RunID),
+	})
+	return true
+}
+
+// handleStandby advances a running STANDBY chamber. With keep-warm engaged
+// the elements bring it up to the setpoint at the ramp rate and hold it
+// there, and a hotter chamber cools passively down to it; otherwise, or
+// with failed elements, it drifts toward the room temperature. Returns true
+// if temp changed.
+func (s *SimulatorService) handleStandby(ctx context.Context, st *models.FurnaceState, elapsed float64) bool {
+	sp := st.KeepWarmC
+	if sp <= s.room || s.faults.has(FaultHeaterFailure) {
+		return s.handleCooling(st, elapsed, s.cfg.Physics.StandbyCoolPerSec)
+	}
+	prev := st.CurrentTempC
+	switch {
+	case prev < sp:
+		st.CurrentTempC = math.Min(prev+s.rampUpRate(ctx)*elapsed, sp)
+	case prev > sp:
+		st.CurrentTempC = math.Max(prev-s.cfg.Physics.StandbyCoolPerSec*elapsed, sp)
+	}
+	return st.CurrentTempC != prev
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestStep_KeepWarmHoldsStandbySetpoint(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	states := &simStateRepoStub{loadResp: models.FurnaceState{
+		ID: 1, Mode: ModeStandby, IsRunning: true, CurrentTempC: 25, MeasuredTempC: 25, O2PPM: AirO2PPM, UpdatedAt: start,
+	}}
+	events := &simEventRepoStub{}
+	cfg := DefaultSimConfig()
+	cfg.KeepWarm = KeepWarmConfig{SetpointC: 150}
+	svc := NewSimulatorServiceWithConfig(states, events, nil, nil, nil, cfg)
+	step := func(dt time.Duration) models.FurnaceState {
+		t.Helper()
+		if err := svc.Step(context.Background(), dt); err != nil {
+			t.Fatalf("Step: %v", err)
+		}
+		states.loadResp = states.saves[len(states.saves)-1]
+		return states.loadResp
+	}
+
+	st := step(time.Second)
+	if st.KeepWarmC != 150 || st.PowerKW < cfg.Power.HeaterKW {
+		t.Fatalf("engaging: keep_warm_c = %v, power = %v kW, want 150 at full power", st.KeepWarmC, st.PowerKW)
+	}
+	if len(events.appends) != 1 || events.appends[0].Type != "KEEP_WARM_ENGAGED" {
+		t.Fatalf("events = %+v", events.appends)
+	}
+
+	for i := 0; i < 120; i++ {
+		st = step(time.Second)
+	}
+	if st.CurrentTempC != 150 {
+		t.Fatalf("temp = %v, want held at 150", st.CurrentTempC)
+	}
+	if st.PowerKW <= cfg.Power.IdleKW || st.PowerKW >= cfg.Power.HeaterKW/2 {
+		t.Fatalf("holding draws %v kW, want above idle and well below the rated power", st.PowerKW)
+	}
+
+	// a HEAT command disengages it, and a later STANDBY engages it again
+	command(t, svc, func(st *models.FurnaceState) {
+		st.Mode, st.TargetTempC, st.RemainingSeconds = ModeHeat, 400, 600
+	})
+	st = step(time.Second)
+	if st.KeepWarmC != 0 || events.appends[1].Type != "KEEP_WARM_DISENGAGED" {
+		t.Fatalf("after HEAT: keep_warm_c = %v, events = %+v", st.KeepWarmC, events.appends)
+	}
+	command(t, svc, func(st *models.FurnaceState) {
+		st.Mode, st.TargetTempC, st.RemainingSeconds = ModeStandby, 0, 0
+	})
+	for i := 0; i < 600; i++ {
+		st = step(time.Second)
+	}
+	if st.CurrentTempC != 150 || events.appends[2].Type != "KEEP_WARM_ENGAGED" {
+		t.Fatalf("back in STANDBY: temp = %v, events = %+v", st.CurrentTempC, events.appends)
+	}
+}
+
+func TestStep_KeepWarmOffWhenStopped(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	states := &simStateRepoStub{loadResp: models.FurnaceState{
+		ID: 1, Mode: ModeStandby, IsRunning: true, CurrentTempC: 150, MeasuredTempC: 150, KeepWarmC: 150, O2PPM: AirO2PPM, UpdatedAt: start,
+	}}
+	events := &simEventRepoStub{}
+	cfg := DefaultSimConfig()
+	cfg.KeepWarm = KeepWarmConfig{SetpointC: 150}
+	svc := NewSimulatorServiceWithConfig(states, events, nil, nil, nil, cfg)
+
+	// already engaged before a restart: nothing to log
+	if err := svc.Step(context.Background(), time.Second); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	if len(events.appends) != 0 {
+		t.Fatalf("events = %+v", events.appends)
+	}
+
+	command(t, svc, func(st *models.FurnaceState) { st.IsRunning = false })
+	if err := svc.Step(context.Background(), 10*time.Second); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	st := states.saves[len(states.saves)-1]
+	if st.KeepWarmC != 0 || st.CurrentTempC >= 150 || st.PowerKW != 0 {
+		t.Fatalf("stopped: %+v", st)
+	}
+	if len(events.appends) != 1 || events.appends[0].Type != "KEEP_WARM_DISENGAGED" {
+		t.Fatalf("events = %+v", events.appends)
+	}
+}
+
+// command changes the shared state as a furnace command would between steps.
+func command(t *testing.T, svc *SimulatorService, fn func(st *models.FurnaceState)) {
+	t.Helper()
+	if _, err := svc.state.Update(context.Background(), func(st *models.FurnaceState) error {
+		fn(st)
+		return nil
+	}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Limits for maintenance tasks and record listings.
+const (
+	MaxMaintenanceName     = 100
+	MaxMaintenanceDays     = 10 * 365
+	MaxMaintenanceNote     = 1000
+	DefaultMaintenanceList = 100
+	MaxMaintenanceList     = 1000
+
+	// DefaultMaintenanceCheck is how often Run looks for overdue tasks
+	// when no interval is configured.
+	DefaultMaintenanceCheck = time.Minute
+)
+
+var (
+	// ErrInvalidMaintenanceTask is returned for tasks that can never fall due.
+	ErrInvalidMaintenanceTask = errors.New("invalid maintenance task")
+	// ErrMaintenanceTaskNotFound is returned when no task exists for an ID.
+	ErrMaintenanceTaskNotFound = errors.New("maintenance task not found")
+)
+
+// MaintenanceConfig configures the overdue check.
+type MaintenanceConfig struct {
+	CheckInterval time.Duration `mapstructure:"check_interval"` // DefaultMaintenanceCheck when 0
+}
+
+// elementReplacer restarts heater wear once new elements are fitted.
+type elementReplacer interface {
+	ReplaceElements(ctx context.Context) error
+}
+
+// MaintenanceService tracks recurring maintenance tasks against heating
+// hours and the calendar, and logs MAINTENANCE_OVERDUE when one falls due.
+type MaintenanceService struct {
+	repo     repository.MaintenanceRepo
+	health   repository.HealthRepo // heating hours; hour intervals never fall due when nil
+	events   repository.EventRepo
+	elements elementReplacer // optional; completing element_replacement leaves wear alone when nil
+	cfg      MaintenanceConfig
+	now      func() time.Time
+	newID    func() string
+}
+
+func NewMaintenanceService(repo repository.MaintenanceRepo, health repository.HealthRepo, events repository.EventRepo, cfg MaintenanceConfig) *MaintenanceService {
+	return &MaintenanceService{repo: repo, health: health, events: events, cfg: cfg, now: time.Now, newID: uuid.NewString}
+}
+
+// normalizeTask trims the text fields and checks that t can fall due.
+func normalizeTask(t models.MaintenanceTask) (models.MaintenanceTask, error) {
+	t.Name = strings.TrimSpace(t.Name)
+	t.Kind = strings.ToLower(strings.TrimSpace(t.Kind))
+	t.Part = strings.TrimSpace(t.Part)
+	switch {
+	case t.Name == "" || len(t.Name) > MaxMaintenanceName:
+		return t, fmt.Errorf("%w: name must be 1..%d characters", ErrInvalidMaintenanceTask, MaxMaintenanceName)
+	case len(t.Part) > MaxMaintenanceName:
+		return t, fmt.Errorf("%w: part must be at most %d characters", ErrInvalidMaintenanceTask, MaxMaintenanceName)
+	case t.Kind != models.MaintenanceCalibration && t.Kind != models.MaintenanceElements && t.Kind != models.MaintenanceInspection:
+		return t, fmt.Errorf("%w: kind must be one of %s, %s, %s", ErrInvalidMaintenanceTask,
+			models.MaintenanceCalibration, models.MaintenanceElements, models.MaintenanceInspection)
+	case t.IntervalHours < 0 || math.IsNaN(t.IntervalHours) || math.IsInf(t.IntervalHours, 0):
+		return t, fmt.Errorf("%w: interval_hours must be a number >= 0", ErrInvalidMaintenanceTask)
+	case t.IntervalDays < 0 || t.IntervalDays > MaxMaintenanceDays:
+		return t, fmt.Errorf("%w: interval_days must be within 0..%d", ErrInvalidMaintenanceTask, MaxMaintenanceDays)
+	case t.IntervalHours == 0 && t.IntervalDays == 0:
+		return t, fmt.Errorf("%w: set interval_hours, interval_days or both", ErrInvalidMaintenanceTask)
+	}
+	return t, nil
+}
+
+// heatingHours returns the furnace's total heating hours.
+func (s *MaintenanceService) heatingHours(ctx context.Context) (float64, error) {
+	if s.health == nil {
+		return 0, nil
+	}
+	h, err := s.health.Load(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return h.HeatingSeconds / 3600, nil
+}
+
+// describeTask fills the due date, due hours and overdue flag of t.
+func describeTask(t models.MaintenanceTask, hours float64, now time.Time) models.MaintenanceTask {
+	if t.IntervalDays > 0 {
+		due := t.BaselineAt.AddDate(0, 0, t.IntervalDays)
+		t.DueAt = &due
+		t.Overdue = !now.Before(due)
+	}
+	if t.IntervalHours > 0 {
+		due := t.BaselineHours + t.IntervalHours
+		t.DueHours = &due
+		t.Overdue = t.Overdue || hours >= due
+	}
+	return t
+}
+
+// ListTasks returns every task with its due status.
+func (s *MaintenanceService) ListTasks(ctx context.Context) ([]models.MaintenanceTask, error) {
+	tasks, err := s.repo.ListTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := s.heatingHours(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	for i := range tasks {
+		tasks[i] = describeTask(tasks[i], hours, now)
+	}
+	return tasks, nil
+}
+
+func (s *MaintenanceService) GetTask(ctx context.Context, id int) (models.MaintenanceTask, error) {
+	t, err := s.repo.GetTask(ctx, id)
+	if err != nil {
+		return models.MaintenanceTask{}, err
+	}
+	if t.ID == 0 {
+		return models.MaintenanceTask{}, ErrMaintenanceTaskNotFound
+	}
+	hours, err := s.heatingHours(ctx)
+	if err != nil {
+		return models.MaintenanceTask{}, err
+	}
+	return describeTask(t, hours, s.now().UTC()), nil
+}
+
+// CreateTask validates and stores t on behalf of userID. Its first
+// interval starts now.
+func (s *MaintenanceService) CreateTask(ctx context.Context, t models.MaintenanceTask, userID int) (models.MaintenanceTask, error) {
+	t, err := normalizeTask(t)
+	if err != nil {
+		return models.MaintenanceTask{}, err
+	}
+	if t.BaselineHours, err = s.heatingHours(ctx); err != nil {
+		return models.MaintenanceTask{}, err
+	}
+	t.BaselineAt = s.now().UTC()
+	t.CreatedBy = userID
+	id, err := s.repo.CreateTask(ctx, t)
+	if err != nil {
+		return models.MaintenanceTask{}, err
+	}
+	return s.GetTask(ctx, id)
+}
+
+// UpdateTask replaces the task with t.ID. The interval still counts from
+// the last completion.
+func (s *MaintenanceService) UpdateTask(ctx context.Context, t models.MaintenanceTask) (models.MaintenanceTask, error) {
+	t, err := normalizeTask(t)
+	if err != nil {
+		return models.MaintenanceTask{}, err
+	}
+	found, err := s.repo.UpdateTask(ctx, t)
+	if err != nil {
+		return models.MaintenanceTask{}, err
+	}
+	if !found {
+		return models.MaintenanceTask{}, ErrMaintenanceTaskNotFound
+	}
+	return s.GetTask(ctx, t.ID)
+}
+
+// DeleteTask removes a task; its completion records are kept.
+func (s *MaintenanceService) DeleteTask(ctx context.Context, id int) error {
+	found, err := s.repo.DeleteTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrMaintenanceTaskNotFound
+	}
+	return nil
+}
+
+// CompleteTask records that userID did the task now and starts its next
+// interval. Completing an element replacement also restarts heater wear.
+// Logs MAINTENANCE_DONE.
+func (s *MaintenanceService) CompleteTask(ctx context.Context, id, userID int, note string) (models.MaintenanceRecord, error) {
+	note = strings.TrimSpace(note)
+	if len(note) > MaxMaintenanceNote {
+		return models.MaintenanceRecord{}, fmt.Errorf("%w: note must be at most %d characters", ErrInvalidMaintenanceTask, MaxMaintenanceNote)
+	}
+	t, err := s.GetTask(ctx, id)
+	if err != nil {
+		return models.MaintenanceRecord{}, err
+	}
+	if t.Kind == models.MaintenanceElements && s.elements != nil {
+		if err := s.elements.ReplaceElements(ctx); err != nil {
+			return models.MaintenanceRecord{}, err
+		}
+	}
+	hours, err := s.heatingHours(ctx)
+	if err != nil {
+		return models.MaintenanceRecord{}, err
+	}
+	rec := models.MaintenanceRecord{
+		TaskID:       t.ID,
+		TaskName:     t.Name,
+		Kind:         t.Kind,
+		Part:         t.Part,
+		CompletedAt:  s.now().UTC(),
+		CompletedBy:  userID,
+		HeatingHours: math.Round(hours*100) / 100,
+		Note:         note,
+	}
+	if rec.ID, err = s.repo.Complete(ctx, rec); err != nil {
+		return models.MaintenanceRecord{}, err
+	}
+	if rec.ID == 0 {
+		return models.MaintenanceRecord{}, ErrMaintenanceTaskNotFound
+	}
+	s.logTask(ctx, t, "MAINTENANCE_DONE", "Maintenance done: "+t.Name, rec.CompletedAt, map[string]any{
+		"user_id":       userID,
+		"heating_hours": rec.HeatingHours,
+		"was_overdue":   t.Overdue,
+	})
+	return rec, nil
+}
+
+// ListRecords returns completion records, newest first; taskID 0 lists
+// every task's.
+func (s *MaintenanceService) ListRecords(ctx context.Context, taskID, limit int) ([]models.MaintenanceRecord, error) {
+	if limit <= 0 {
+		limit = DefaultMaintenanceList
+	}
+	if limit > MaxMaintenanceList {
+		limit = MaxMaintenanceList
+	}
+	return s.repo.ListRecords(ctx, repository.MaintenanceRecordQuery{TaskID: taskID, Limit: limit})
+}
+
+// Run checks for overdue tasks until ctx is canceled.
+func (s *MaintenanceService) Run(ctx context.Context) {
+	every := s.cfg.CheckInterval
+	if every <= 0 {
+		every = DefaultMaintenanceCheck
+	}
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		s.checkOverdue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// checkOverdue logs MAINTENANCE_OVERDUE once for each task that fell due
+// since its last completion. The task is marked before the event is
+// appended, so a failed mark is retried on the next check.
+func (s *MaintenanceService) checkOverdue(ctx context.Context) {
+	tasks, err := s.ListTasks(ctx)
+	if err != nil {
+		return
+	}
+	for _, t := range tasks {
+		if !t.Overdue || t.OverdueLogged {
+			continue
+		}
+		if found, err := s.repo.MarkOverdue(ctx, t.ID); err != nil || !found {
+			continue
+		}
+		meta := map[string]any{}
+		if t.DueAt != nil {
+			meta["due_at"] = t.DueAt.Format(time.RFC3339)
+		}
+		if t.DueHours != nil {
+			meta["due_hours"] = *t.DueHours
+		}
+		s.logTask(ctx, t, "MAINTENANCE_OVERDUE", "Maintenance overdue: "+t.Name, s.now(), meta)
+	}
+}
+
+func (s *MaintenanceService) logTask(ctx context.Context, t models.MaintenanceTask, typ, desc string, at time.Time, meta map[string]any) {
+	if s.events == nil {
+		return
+	}
+	meta["task_id"] = t.ID
+	meta["kind"] = t.Kind
+	if t.Part != "" {
+		meta["part"] = t.Part
+	}
+	_ = s.events.Append(ctx, models.FurnaceEvent{
+		EventID:     s.newID(),
+		OccurredAt:  at.UTC(),
+		Type:        typ,
+		Description: desc,
+		Metadata:    meta,
+	})
+}
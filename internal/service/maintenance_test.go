@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+// fakeMaintenanceRepo keeps tasks and records in memory.
+type fakeMaintenanceRepo struct {
+	tasks   map[int]models.MaintenanceTask
+	records []models.MaintenanceRecord
+}
+
+func newFakeMaintenanceRepo() *fakeMaintenanceRepo {
+	return &fakeMaintenanceRepo{tasks: map[int]models.MaintenanceTask{}}
+}
+
+func (r *fakeMaintenanceRepo) CreateTask(ctx context.Context, t models.MaintenanceTask) (int, error) {
+	t.ID = len(r.tasks) + 1
+	r.tasks[t.ID] = t
+	return t.ID, nil
+}
+func (r *fakeMaintenanceRepo) UpdateTask(ctx context.Context, t models.MaintenanceTask) (bool, error) {
+	old, ok := r.tasks[t.ID]
+	if ok {
+		old.Name, old.Kind, old.Part, old.IntervalHours, old.IntervalDays, old.OverdueLogged = t.Name, t.Kind, t.Part, t.IntervalHours, t.IntervalDays, false
+		r.tasks[t.ID] = old
+	}
+	return ok, nil
+}
+func (r *fakeMaintenanceRepo) DeleteTask(ctx context.Context, id int) (bool, error) {
+	_, ok := r.tasks[id]
+	delete(r.tasks, id)
+	return ok, nil
+}
+func (r *fakeMaintenanceRepo) MarkOverdue(ctx context.Context, id int) (bool, error) {
+	t, ok := r.tasks[id]
+	t.OverdueLogged = true
+	if ok {
+		r.tasks[id] = t
+	}
+	return ok, nil
+}
+func (r *fakeMaintenanceRepo) GetTask(ctx context.Context, id int) (models.MaintenanceTask, error) {
+	return r.tasks[id], nil
+}
+func (r *fakeMaintenanceRepo) ListTasks(ctx context.Context) ([]models.MaintenanceTask, error) {
+	out := []models.MaintenanceTask{}
+	for id := 1; id <= len(r.tasks)+1; id++ {
+		if t, ok := r.tasks[id]; ok {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+func (r *fakeMaintenanceRepo) Complete(ctx context.Context, rec models.MaintenanceRecord) (int64, error) {
+	t, ok := r.tasks[rec.TaskID]
+	if !ok {
+		return 0, nil
+	}
+	at := rec.CompletedAt
+	t.BaselineAt, t.BaselineHours, t.LastDoneAt, t.OverdueLogged = at, rec.HeatingHours, &at, false
+	r.tasks[t.ID] = t
+	rec.ID = int64(len(r.records) + 1)
+	r.records = append(r.records, rec)
+	return rec.ID, nil
+}
+func (r *fakeMaintenanceRepo) ListRecords(ctx context.Context, q repository.MaintenanceRecordQuery) ([]models.MaintenanceRecord, error) {
+	return r.records, nil
+}
+
+type elementReplacerStub struct{ calls int }
+
+func (e *elementReplacerStub) ReplaceElements(ctx context.Context) error {
+	e.calls++
+	return nil
+}
+
+func newTestMaintenance(now *time.Time, hours float64) (*MaintenanceService, *fakeMaintenanceRepo, *healthRepoStub, *simEventRepoStub) {
+	repo := newFakeMaintenanceRepo()
+	health := &healthRepoStub{saved: models.FurnaceHealth{HeatingSeconds: hours * 3600}}
+	events := &simEventRepoStub{}
+	svc := NewMaintenanceService(repo, health, events, MaintenanceConfig{})
+	svc.now = func() time.Time { return *now }
+	svc.newID = func() string { return "ev" }
+	return svc, repo, health, events
+}
+
+func TestMaintenance_RejectsTasksThatNeverFallDue(t *testing.T) {
+	now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	svc, _, _, _ := newTestMaintenance(&now, 0)
+
+	for _, task := range []models.MaintenanceTask{
+		{Name: "Calibrate", Kind: models.MaintenanceCalibration},
+		{Name: "Calibrate", Kind: "polish", IntervalDays: 30},
+		{Name: " ", Kind: models.MaintenanceInspection, IntervalDays: 30},
+		{Name: "Calibrate", Kind: models.MaintenanceCalibration, IntervalHours: -1},
+	} {
+		if _, err := svc.CreateTask(context.Background(), task, 1); !errors.Is(err, ErrInvalidMaintenanceTask) {
+			t.Fatalf("CreateTask(%+v) = %v, want ErrInvalidMaintenanceTask", task, err)
+		}
+	}
+	if _, err := svc.GetTask(context.Background(), 9); !errors.Is(err, ErrMaintenanceTaskNotFound) {
+		t.Fatalf("GetTask(9) = %v, want ErrMaintenanceTaskNotFound", err)
+	}
+}
+
+func TestMaintenance_FallsDueByCalendarOrHeatingHours(t *testing.T) {
+	now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	svc, _, health, events := newTestMaintenance(&now, 500)
+	ctx := context.Background()
+
+	cal, err := svc.CreateTask(ctx, models.MaintenanceTask{Name: "Calibrate thermocouple", Kind: models.MaintenanceCalibration, IntervalDays: 90}, 2)
+	if err != nil || cal.CreatedBy != 2 || cal.DueAt == nil || !cal.DueAt.Equal(now.AddDate(0, 0, 90)) || cal.Overdue {
+		t.Fatalf("CreateTask() = %+v, %v", cal, err)
+	}
+	hot, err := svc.CreateTask(ctx, models.MaintenanceTask{Name: "Replace elements", Kind: models.MaintenanceElements, IntervalHours: 100}, 2)
+	if err != nil || hot.DueHours == nil || *hot.DueHours != 600 {
+		t.Fatalf("expected the hour interval to start at the current 500 h, got %+v, %v", hot, err)
+	}
+
+	health.saved.HeatingSeconds = 600 * 3600
+	svc.checkOverdue(ctx)
+	svc.checkOverdue(ctx)
+	if meta, _ := events.appends[0].Metadata.(map[string]any); len(events.appends) != 1 || events.appends[0].Type != "MAINTENANCE_OVERDUE" || meta["task_id"] != hot.ID {
+		t.Fatalf("expected one MAINTENANCE_OVERDUE for the element task, got %+v", events.appends)
+	}
+
+	now = now.AddDate(0, 0, 90)
+	svc.checkOverdue(ctx)
+	if meta, _ := events.appends[len(events.appends)-1].Metadata.(map[string]any); len(events.appends) != 2 || meta["task_id"] != cal.ID {
+		t.Fatalf("expected the calibration to fall due after 90 days, got %+v", events.appends)
+	}
+}
+
+func TestMaintenance_CompleteRestartsIntervalAndWear(t *testing.T) {
+	now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	svc, repo, health, events := newTestMaintenance(&now, 0)
+	elements := &elementReplacerStub{}
+	svc.elements = elements
+	ctx := context.Background()
+
+	task, _ := svc.CreateTask(ctx, models.MaintenanceTask{Name: "Replace elements", Kind: models.MaintenanceElements, Part: "A1-8MM", IntervalHours: 100}, 1)
+	health.saved.HeatingSeconds = 120 * 3600
+	svc.checkOverdue(ctx)
+
+	now = now.Add(time.Hour)
+	rec, err := svc.CompleteTask(ctx, task.ID, 3, " six new elements ")
+	if err != nil {
+		t.Fatalf("CompleteTask: %v", err)
+	}
+	if rec.CompletedBy != 3 || rec.HeatingHours != 120 || rec.Part != "A1-8MM" || rec.Note != "six new elements" || !rec.CompletedAt.Equal(now) {
+		t.Fatalf("unexpected record %+v", rec)
+	}
+	if elements.calls != 1 {
+		t.Fatalf("expected completing an element replacement to reset wear once, got %d calls", elements.calls)
+	}
+	last := events.appends[len(events.appends)-1]
+	if meta, _ := last.Metadata.(map[string]any); last.Type != "MAINTENANCE_DONE" || meta["user_id"] != 3 || meta["was_overdue"] != true {
+		t.Fatalf("unexpected event %+v", last)
+	}
+
+	got, _ := svc.GetTask(ctx, task.ID)
+	if got.Overdue || *got.DueHours != 220 || got.LastDoneAt == nil || repo.tasks[task.ID].OverdueLogged {
+		t.Fatalf("expected the next interval to start at 120 h, got %+v", got)
+	}
+
+	cal, _ := svc.CreateTask(ctx, models.MaintenanceTask{Name: "Calibrate", Kind: models.MaintenanceCalibration, IntervalDays: 30}, 1)
+	if _, err := svc.CompleteTask(ctx, cal.ID, 3, ""); err != nil || elements.calls != 1 {
+		t.Fatalf("a calibration must leave wear alone: %v, %d calls", err, elements.calls)
+	}
+	if _, err := svc.CompleteTask(ctx, 99, 3, ""); !errors.Is(err, ErrMaintenanceTaskNotFound) {
+		t.Fatalf("CompleteTask(99) = %v, want ErrMaintenanceTaskNotFound", err)
+	}
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"controlling_furnace/internal/models"
+	"math"
+	"sync"
 	"time"
 
 	"controlling_furnace/internal/repository"
@@ -13,19 +15,28 @@ const (
 	defaultAmbientTempC = 25.0
 )
 
+// RateWindow is the stretch of temperature history the heating rate in
+// GetState is measured over.
+const RateWindow = time.Minute
+
 // ... existing code ...
 type MonitoringService struct {
-	stateRepo repository.StateRepo
+	state *StateManager
+	stats *liveStats // optional; no rate, ETA or soak progress when nil
+	speed SimClock   // optional; the soak end assumes real time when nil
 }
 
 func NewMonitoringService(stateRepo repository.StateRepo) *MonitoringService {
-	return &MonitoringService{stateRepo: stateRepo}
+	return &MonitoringService{state: managedState(stateRepo)}
 }
 
-// GetState returns the latest persisted furnace state.
+// GetState returns the current furnace state.
 // If no state is persisted yet, returns a baseline STANDBY snapshot.
-func (s *MonitoringService) GetState(ctx context.Context) (models.FurnaceState, error) {
-	state, err := s.stateRepo.Load(ctx)
+func (s *MonitoringService) GetState(ctx context.Context) (_ models.FurnaceState, err error) {
+	ctx, span := startSpan(ctx, "Monitoring.GetState")
+	defer func() { endSpan(span, err) }()
+
+	state, err := s.state.Load(ctx)
 	if err != nil {
 		return models.FurnaceState{}, err
 	}
@@ -33,9 +44,133 @@ func (s *MonitoringService) GetState(ctx context.Context) (models.FurnaceState,
 		return s.baselineState(), nil
 	}
 	state.UpdatedAt = toUTC(state.UpdatedAt)
+	derive(&state, s.stats, s.speed)
 	return state, nil
 }
 
+// derive fills the rate, ETA, soak progress and soak end of st from what
+// the simulator observed in memory, so reading a state never queries the
+// history. Fields the stats cannot tell yet, e.g. right after a restart,
+// are left unset.
+func derive(st *models.FurnaceState, stats *liveStats, clock SimClock) {
+	if stats != nil {
+		st.RateCPerSec = stats.rate(st.UpdatedAt)
+	}
+	if !st.IsRunning || st.Mode != ModeHeat || st.TargetTempC <= 0 {
+		return
+	}
+	switch gap := st.TargetTempC - st.CurrentTempC; {
+	case math.Abs(gap) <= SoakToleranceC:
+		eta := 0
+		st.ETASeconds = &eta
+	case gap > 0 && st.RateCPerSec != nil && *st.RateCPerSec > 0:
+		eta := int(math.Ceil(gap / *st.RateCPerSec))
+		st.ETASeconds = &eta
+	}
+	st.SoakEndsAt = soakEndsAt(*st, clock)
+	if stats == nil || st.RunID == "" || st.RemainingSeconds <= 0 {
+		return
+	}
+	if soaked, ok := stats.soaked(st.RunID); ok {
+		pct := soakPercent(soaked, st.RemainingSeconds)
+		st.SoakPercent = &pct
+	}
+}
+
+// soakEndsAt returns when the soak of st completes, or nil unless the
+// furnace holds at target with soak time left. It counts from the last
+// saved tick, so it only moves when the countdown stops, e.g. while the
+// chamber drops below the band or the process is down.
+func soakEndsAt(st models.FurnaceState, clock SimClock) *time.Time {
+	if st.RemainingSeconds <= 0 || st.CurrentTempC < st.TargetTempC-SoakToleranceC {
+		return nil
+	}
+	scale := 1.0
+	if clock != nil {
+		if sp := clock.Speed(); sp.TimeScale > 0 {
+			scale = sp.TimeScale
+		}
+	}
+	left := time.Duration(float64(st.RemainingSeconds) / scale * float64(time.Second))
+	end := st.UpdatedAt.Add(left).Truncate(time.Millisecond)
+	return &end
+}
+
+// liveStats holds what derive needs beyond the state itself: the chamber
+// temperatures of the last RateWindow and the soak time of the active run.
+// The simulator updates it after every saved step. It is safe for
+// concurrent use.
+type liveStats struct {
+	mu       sync.Mutex
+	readings []tempReading // oldest first
+	runID    string
+	soakedS  float64
+}
+
+type tempReading struct {
+	at    time.Time
+	tempC float64
+}
+
+// observe records the chamber temperature of st and the seconds its run
+// has soaked so far.
+func (l *liveStats) observe(st models.FurnaceState, soakedS float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	at := st.UpdatedAt.UTC()
+	keep := 0
+	for keep < len(l.readings) && l.readings[keep].at.Before(at.Add(-RateWindow)) {
+		keep++
+	}
+	l.readings = append(l.readings[keep:], tempReading{at: at, tempC: st.CurrentTempC})
+	l.runID, l.soakedS = st.RunID, soakedS
+}
+
+// rate returns the slope over the readings of the RateWindow ending at
+// at in °C per second, or nil with fewer than two readings.
+func (l *liveStats) rate(at time.Time) *float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var first, last *tempReading
+	for i := range l.readings {
+		r := &l.readings[i]
+		if r.at.Before(at.Add(-RateWindow)) || r.at.After(at) {
+			continue
+		}
+		if first == nil {
+			first = r
+		}
+		last = r
+	}
+	if first == nil {
+		return nil
+	}
+	secs := last.at.Sub(first.at).Seconds()
+	if secs <= 0 {
+		return nil
+	}
+	rate := math.Round((last.tempC-first.tempC)/secs*1000) / 1000
+	return &rate
+}
+
+// soaked returns the seconds runID has soaked so far, if it is the run
+// last observed.
+func (l *liveStats) soaked(runID string) (float64, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.soakedS, runID != "" && runID == l.runID
+}
+
+// soakPercent returns how much of a soak is done when soakedS seconds were
+// spent at target and remainingS are left, 0..100.
+func soakPercent(soakedS float64, remainingS int) float64 {
+	total := soakedS + float64(remainingS)
+	if total <= 0 {
+		return 0
+	}
+	return math.Round(math.Min(soakedS/total, 1)*1000) / 10
+}
+
 // ... existing code ...
 
 // baselineState returns a sensible default snapshot for an uninitialized DB.
@@ -44,6 +179,7 @@ func (s *MonitoringService) baselineState() models.FurnaceState {
 		ID:               1, // DB schema enforces single-row state with id=1
 		Mode:             modeStandby,
 		CurrentTempC:     defaultAmbientTempC,
+		MeasuredTempC:    defaultAmbientTempC,
 		TargetTempC:      0,
 		RemainingSeconds: 0,
 		ErrorCodes:       nil,
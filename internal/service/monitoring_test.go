@@ -232,3 +232,99 @@ func assertWithin(t *testing.T, got time.Time, dur time.Duration) {
 		t.Fatalf("time %v not within %v of now; diff=%v", got, dur, diff)
 	}
 }
+
+func TestMonitoringService_GetStateDerivesRateETAAndSoak(t *testing.T) {
+	now := time.Date(2025, 9, 20, 12, 0, 0, 0, time.UTC)
+	stats := &liveStats{}
+	// the first reading falls out of the rate window
+	for _, r := range []tempReading{{now.Add(-RateWindow - time.Second), 100}, {now.Add(-20 * time.Second), 500}, {now.Add(-10 * time.Second), 505}, {now, 510}} {
+		stats.observe(models.FurnaceState{RunID: "run-1", CurrentTempC: r.tempC, UpdatedAt: r.at}, 150)
+	}
+	svc := NewMonitoringService(&monitoringStateRepoStub{loadResp: models.FurnaceState{
+		ID: 1, Mode: ModeHeat, IsRunning: true, RunID: "run-1",
+		CurrentTempC: 510, TargetTempC: 800, UpdatedAt: now,
+	}})
+	svc.stats = stats
+
+	st, err := svc.GetState(context.Background())
+	if err != nil {
+		t.Fatalf("GetState: %v", err)
+	}
+	if st.RateCPerSec == nil || *st.RateCPerSec != 0.5 {
+		t.Fatalf("rate = %v, want 0.5", st.RateCPerSec)
+	}
+	if st.ETASeconds == nil || *st.ETASeconds != 580 {
+		t.Fatalf("eta = %v, want 580", st.ETASeconds)
+	}
+	if st.SoakPercent != nil {
+		t.Fatalf("no soak requested, got %v", *st.SoakPercent)
+	}
+
+	// holding at target with 50 s of a 200 s soak left
+	svc.state = NewStateManager(&monitoringStateRepoStub{loadResp: models.FurnaceState{
+		ID: 1, Mode: ModeHeat, IsRunning: true, RunID: "run-1",
+		CurrentTempC: 800, TargetTempC: 800, RemainingSeconds: 50, UpdatedAt: now,
+	}})
+	if st, err = svc.GetState(context.Background()); err != nil {
+		t.Fatalf("GetState: %v", err)
+	}
+	if st.ETASeconds == nil || *st.ETASeconds != 0 {
+		t.Fatalf("eta at target = %v, want 0", st.ETASeconds)
+	}
+	if st.SoakPercent == nil || *st.SoakPercent != 75 {
+		t.Fatalf("soak percent = %v, want 75", st.SoakPercent)
+	}
+	if st.SoakEndsAt == nil || !st.SoakEndsAt.Equal(now.Add(50*time.Second)) {
+		t.Fatalf("soak ends at %v, want 50 s after the last tick", st.SoakEndsAt)
+	}
+}
+
+type speedStub struct{ sp Speed }
+
+func (s speedStub) Speed() Speed            { return s.sp }
+func (s speedStub) SetSpeed(sp Speed) error { return nil }
+
+func TestMonitoringService_SoakEndsAtFollowsTimeScaleAndBand(t *testing.T) {
+	now := time.Date(2025, 9, 20, 12, 0, 0, 0, time.UTC)
+	holding := models.FurnaceState{
+		ID: 1, Mode: ModeHeat, IsRunning: true,
+		CurrentTempC: 799, TargetTempC: 800, RemainingSeconds: 600, UpdatedAt: now,
+	}
+	svc := NewMonitoringService(&monitoringStateRepoStub{loadResp: holding})
+	svc.speed = speedStub{Speed{Tick: time.Second, TimeScale: 60}}
+
+	st, err := svc.GetState(context.Background())
+	if err != nil {
+		t.Fatalf("GetState: %v", err)
+	}
+	if st.SoakEndsAt == nil || !st.SoakEndsAt.Equal(now.Add(10*time.Second)) {
+		t.Fatalf("soak ends at %v, want 10 wall-clock seconds at 60x", st.SoakEndsAt)
+	}
+
+	// still ramping: the countdown has not started
+	ramping := holding
+	ramping.CurrentTempC = 700
+	svc.state = NewStateManager(&monitoringStateRepoStub{loadResp: ramping})
+	if st, err = svc.GetState(context.Background()); err != nil {
+		t.Fatalf("GetState: %v", err)
+	}
+	if st.SoakEndsAt != nil {
+		t.Fatalf("soak end while ramping = %v, want none", st.SoakEndsAt)
+	}
+}
+
+func TestMonitoringService_GetStateWithoutHistoryLeavesDerivedUnset(t *testing.T) {
+	svc := NewMonitoringService(&monitoringStateRepoStub{loadResp: models.FurnaceState{
+		ID: 1, Mode: ModeHeat, IsRunning: true, CurrentTempC: 500, TargetTempC: 800, UpdatedAt: time.Now(),
+	}})
+	svc.stats = &liveStats{}
+	svc.stats.observe(models.FurnaceState{CurrentTempC: 500, UpdatedAt: time.Now()}, 0)
+
+	st, err := svc.GetState(context.Background())
+	if err != nil {
+		t.Fatalf("GetState: %v", err)
+	}
+	if st.RateCPerSec != nil || st.ETASeconds != nil || st.SoakPercent != nil {
+		t.Fatalf("expected no derived fields, got rate=%v eta=%v soak=%v", st.RateCPerSec, st.ETASeconds, st.SoakPercent)
+	}
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+const (
+	// DefaultNotifyTimeout bounds one outbound notification.
+	DefaultNotifyTimeout = 5 * time.Second
+	// MaxDigestAlerts bounds the alerts a channel holds for its next digest;
+	// later ones are only counted.
+	MaxDigestAlerts = 200
+	// DigestCheckInterval is how often held alerts are checked for a due
+	// digest.
+	DigestCheckInterval = 30 * time.Second
+	// DefaultChannel is the name of the channel AlertConfig.NotifyURL
+	// becomes; configured channels cannot take it.
+	DefaultChannel = "default"
+)
+
+// Notifier delivers fired alerts outside the process.
+type Notifier interface {
+	Notify(ctx context.Context, a models.Alert) error
+}
+
+// EscalationNotifier pages one tier of the escalation chain.
+type EscalationNotifier interface {
+	NotifyEscalation(ctx context.Context, e models.Escalation) error
+}
+
+// HTTPNotifier POSTs each alert or escalation as JSON to a fixed URL, for
+// chat integrations and paging gateways that accept generic webhooks.
+type HTTPNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPNotifier(url string, timeout time.Duration) *HTTPNotifier {
+	if timeout <= 0 {
+		timeout = DefaultNotifyTimeout
+	}
+	return &HTTPNotifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Notify fails on transport errors and non-2xx responses.
+func (n *HTTPNotifier) Notify(ctx context.Context, a models.Alert) error {
+	return n.post(ctx, a)
+}
+
+// NotifyEscalation fails like Notify.
+func (n *HTTPNotifier) NotifyEscalation(ctx context.Context, e models.Escalation) error {
+	return n.post(ctx, e)
+}
+
+// NotifyDigest fails like Notify.
+func (n *HTTPNotifier) NotifyDigest(ctx context.Context, d models.AlertDigest) error {
+	return n.post(ctx, d)
+}
+
+func (n *HTTPNotifier) post(ctx context.Context, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notification rejected: %s", resp.Status)
+	}
+	return nil
+}
+
+// isHTTPURL reports whether raw is an absolute http(s) URL.
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// NotifyChannel is one destination of alert notifications.
+type NotifyChannel struct {
+	Name      string `mapstructure:"name"`
+	NotifyURL string `mapstructure:"notify_url"`
+	// QuietHours is a daily window of local time, such as "22:00-06:00",
+	// in which non-critical alerts are held and then sent as one digest.
+	QuietHours string `mapstructure:"quiet_hours"`
+	// Digest batches non-critical alerts into one summary sent once the
+	// oldest is this old; 0 sends each alert at once.
+	Digest time.Duration `mapstructure:"digest"`
+}
+
+// quietHours is a daily window in minutes after local midnight; it wraps
+// past midnight when end < start.
+type quietHours struct {
+	start, end int
+	set        bool
+}
+
+// parseQuietHours reads "HH:MM-HH:MM"; empty means no quiet hours.
+func parseQuietHours(s string) (quietHours, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return quietHours{}, nil
+	}
+	from, to, ok := strings.Cut(s, "-")
+	start, err1 := time.Parse("15:04", strings.TrimSpace(from))
+	end, err2 := time.Parse("15:04", strings.TrimSpace(to))
+	if !ok || err1 != nil || err2 != nil || start.Equal(end) {
+		return quietHours{}, fmt.Errorf("quiet_hours %q must look like 22:00-06:00", s)
+	}
+	return quietHours{start: start.Hour()*60 + start.Minute(), end: end.Hour()*60 + end.Minute(), set: true}, nil
+}
+
+// contains reports whether t falls in the window, in t's location.
+func (q quietHours) contains(t time.Time) bool {
+	if !q.set {
+		return false
+	}
+	m := t.Hour()*60 + t.Minute()
+	if q.start < q.end {
+		return m >= q.start && m < q.end
+	}
+	return m >= q.start || m < q.end
+}
+
+// digestNotifier is the outbound side of a channel.
+type digestNotifier interface {
+	Notifier
+	NotifyDigest(ctx context.Context, d models.AlertDigest) error
+}
+
+// alertChannel holds a channel's non-critical alerts during quiet hours and
+// until its digest is due.
+type alertChannel struct {
+	name   string
+	out    digestNotifier
+	quiet  quietHours
+	digest time.Duration
+
+	mu      sync.Mutex
+	held    []models.Alert
+	since   time.Time // when the oldest held alert arrived
+	dropped int       // held alerts beyond MaxDigestAlerts
+}
+
+// ChannelNotifier sends alerts to every channel: critical ones at once,
+// the others subject to each channel's quiet hours and digest. Flush sends
+// the digests that are due; held alerts live in memory only, so Drain sends
+// every digest early when the process stops.
+type ChannelNotifier struct {
+	channels []*alertChannel
+	critical []string
+	now      func() time.Time
+}
+
+// NewChannelNotifier returns nil when cfg has nowhere to notify.
+// NotifyURL becomes a channel named DefaultChannel without quiet hours or
+// digest. cfg must be valid.
+func NewChannelNotifier(cfg AlertConfig) *ChannelNotifier {
+	channels := cfg.Channels
+	if cfg.NotifyURL != "" {
+		channels = append([]NotifyChannel{{Name: DefaultChannel, NotifyURL: cfg.NotifyURL}}, channels...)
+	}
+	if len(channels) == 0 {
+		return nil
+	}
+	n := &ChannelNotifier{critical: cfg.Critical, now: time.Now}
+	if len(n.critical) == 0 {
+		n.critical = DefaultCriticalAlerts
+	}
+	for _, ch := range channels {
+		quiet, _ := parseQuietHours(ch.QuietHours)
+		n.channels = append(n.channels, &alertChannel{
+			name:   strings.TrimSpace(ch.Name),
+			out:    NewHTTPNotifier(ch.NotifyURL, cfg.NotifyTimeout),
+			quiet:  quiet,
+			digest: ch.Digest,
+		})
+	}
+	return n
+}
+
+// Notify sends a to each channel or holds it for the channel's digest. It
+// fails if any channel failed to send.
+func (n *ChannelNotifier) Notify(ctx context.Context, a models.Alert) error {
+	critical, now := hasString(n.critical, a.Kind), n.now()
+	var errs []error
+	for _, ch := range n.channels {
+		if !critical && ch.hold(a, now) {
+			continue
+		}
+		if err := ch.out.Notify(ctx, a); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ch.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Flush sends the digest of every channel that has one due. A failed digest
+// is kept and tried again on the next flush.
+func (n *ChannelNotifier) Flush(ctx context.Context) {
+	now := n.now()
+	for _, ch := range n.channels {
+		ch.flush(ctx, now, false)
+	}
+}
+
+// Drain sends the digest of every channel holding alerts, due or not and
+// even in quiet hours, so stopping the process does not lose them.
+func (n *ChannelNotifier) Drain(ctx context.Context) {
+	now := n.now()
+	for _, ch := range n.channels {
+		ch.flush(ctx, now, true)
+	}
+}
+
+// hold keeps a for the next digest when the channel is quiet or batches
+// alerts, and reports whether it did.
+func (ch *alertChannel) hold(a models.Alert, now time.Time) bool {
+	if ch.digest <= 0 && !ch.quiet.contains(now) {
+		return false
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if len(ch.held) == 0 && ch.dropped == 0 {
+		ch.since = now
+	}
+	if len(ch.held) < MaxDigestAlerts {
+		ch.held = append(ch.held, a)
+	} else {
+		ch.dropped++
+	}
+	return true
+}
+
+// flush sends the held alerts as one digest once the channel is out of
+// quiet hours and the oldest has waited for the digest interval, or at once
+// with force.
+func (ch *alertChannel) flush(ctx context.Context, now time.Time, force bool) {
+	ch.mu.Lock()
+	if (len(ch.held) == 0 && ch.dropped == 0) || (!force && (ch.quiet.contains(now) || now.Sub(ch.since) < ch.digest)) {
+		ch.mu.Unlock()
+		return
+	}
+	d := models.AlertDigest{
+		Channel: ch.name,
+		From:    ch.since.UTC(),
+		To:      now.UTC(),
+		Count:   len(ch.held) + ch.dropped,
+		Dropped: ch.dropped,
+		Alerts:  ch.held,
+	}
+	ch.held, ch.dropped = nil, 0
+	ch.mu.Unlock()
+
+	if err := ch.out.NotifyDigest(ctx, d); err != nil {
+		ch.requeue(d)
+	}
+}
+
+// requeue puts the alerts of an undelivered digest back in front of those
+// held since.
+func (ch *alertChannel) requeue(d models.AlertDigest) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	held := append(d.Alerts, ch.held...)
+	dropped := d.Dropped + ch.dropped
+	if len(held) > MaxDigestAlerts {
+		dropped += len(held) - MaxDigestAlerts
+		held = held[:MaxDigestAlerts]
+	}
+	ch.held, ch.dropped, ch.since = held, dropped, d.From
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+type digestStub struct {
+	notifierStub
+	digestErr error
+	digests   []models.AlertDigest
+}
+
+func (n *digestStub) NotifyDigest(ctx context.Context, d models.AlertDigest) error {
+	n.digests = append(n.digests, d)
+	return n.digestErr
+}
+
+func TestParseQuietHours(t *testing.T) {
+	for _, bad := range []string{"22:00", "22:00-22:00", "25:00-06:00", "night"} {
+		if _, err := parseQuietHours(bad); err == nil {
+			t.Fatalf("parseQuietHours(%q) accepted", bad)
+		}
+	}
+	q, err := parseQuietHours(" 22:00 - 06:30 ")
+	if err != nil {
+		t.Fatalf("parseQuietHours() = %v", err)
+	}
+	at := func(h, m int) time.Time { return time.Date(2025, 9, 20, h, m, 0, 0, time.UTC) }
+	for clock, want := range map[time.Time]bool{at(21, 59): false, at(22, 0): true, at(3, 0): true, at(6, 29): true, at(6, 30): false, at(12, 0): false} {
+		if got := q.contains(clock); got != want {
+			t.Fatalf("contains(%s) = %v, want %v", clock.Format("15:04"), got, want)
+		}
+	}
+	if day, _ := parseQuietHours("12:00-13:00"); !day.contains(at(12, 30)) || day.contains(at(13, 0)) {
+		t.Fatalf("a window within the day must not wrap")
+	}
+}
+
+func TestAlertConfig_Validate(t *testing.T) {
+	ok := NotifyChannel{Name: "night", NotifyURL: "https://chat.example.com/hook", QuietHours: "22:00-06:00", Digest: time.Hour}
+	for _, cfg := range []AlertConfig{
+		{NotifyURL: "chat.example.com"},
+		{Channels: []NotifyChannel{ok, ok}},
+		{Channels: []NotifyChannel{{Name: "ops", NotifyURL: "ftp://x"}}},
+		{Channels: []NotifyChannel{{Name: "ops", NotifyURL: ok.NotifyURL, QuietHours: "late"}}},
+		{Channels: []NotifyChannel{{Name: "ops", NotifyURL: ok.NotifyURL, Digest: -time.Second}}},
+		{Critical: []string{"MODE_CHANGE"}},
+		{NotifyURL: ok.NotifyURL, Channels: []NotifyChannel{{Name: DefaultChannel, NotifyURL: ok.NotifyURL}}},
+	} {
+		if err := cfg.Validate(); !errors.Is(err, ErrInvalidAlertConfig) {
+			t.Fatalf("Validate(%+v) = %v, want ErrInvalidAlertConfig", cfg, err)
+		}
+	}
+	if err := (AlertConfig{NotifyURL: ok.NotifyURL, Channels: []NotifyChannel{ok}, Critical: []string{models.AlertErrorEvent}}).Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	if NewChannelNotifier(AlertConfig{}) != nil {
+		t.Fatalf("expected no notifier without a URL or channels")
+	}
+}
+
+func TestChannelNotifier_HoldsNonCriticalAlertsForDigests(t *testing.T) {
+	now := time.Date(2025, 9, 20, 23, 0, 0, 0, time.UTC)
+	n := NewChannelNotifier(AlertConfig{
+		NotifyURL: "https://chat.example.com/all",
+		Channels: []NotifyChannel{
+			{Name: "night", NotifyURL: "https://chat.example.com/night", QuietHours: "22:00-06:00"},
+			{Name: "ops", NotifyURL: "https://chat.example.com/ops", Digest: 10 * time.Minute},
+		},
+	})
+	n.now = func() time.Time { return now }
+	all, night, ops := &digestStub{}, &digestStub{}, &digestStub{}
+	n.channels[0].out, n.channels[1].out, n.channels[2].out = all, night, ops
+	ctx := context.Background()
+
+	if err := n.Notify(ctx, models.Alert{RuleName: "ending", Kind: models.AlertRemainingBelow}); err != nil {
+		t.Fatalf("Notify() = %v", err)
+	}
+	if err := n.Notify(ctx, models.Alert{RuleName: "hot", Kind: models.AlertTempAbove}); err != nil {
+		t.Fatalf("Notify() = %v", err)
+	}
+	if len(all.sent) != 2 || len(night.sent) != 1 || len(ops.sent) != 1 || night.sent[0].RuleName != "hot" {
+		t.Fatalf("expected only the critical alert sent to quiet and digest channels: all=%d night=%+v ops=%+v", len(all.sent), night.sent, ops.sent)
+	}
+
+	now = now.Add(5 * time.Minute)
+	n.Flush(ctx)
+	if len(ops.digests) != 0 || len(night.digests) != 0 {
+		t.Fatalf("flushed before a digest was due: ops=%+v night=%+v", ops.digests, night.digests)
+	}
+	now = now.Add(5 * time.Minute)
+	n.Flush(ctx)
+	if len(ops.digests) != 1 || ops.digests[0].Count != 1 || ops.digests[0].Alerts[0].RuleName != "ending" || ops.digests[0].Channel != "ops" {
+		t.Fatalf("expected the ops digest after 10 minutes, got %+v", ops.digests)
+	}
+	if len(night.digests) != 0 {
+		t.Fatalf("sent a digest during quiet hours: %+v", night.digests)
+	}
+
+	now = time.Date(2025, 9, 21, 6, 0, 0, 0, time.UTC)
+	n.Flush(ctx)
+	if len(night.digests) != 1 || night.digests[0].Count != 1 || !night.digests[0].From.Equal(time.Date(2025, 9, 20, 23, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the night digest when quiet hours end, got %+v", night.digests)
+	}
+	if err := n.Notify(ctx, models.Alert{RuleName: "ending", Kind: models.AlertRemainingBelow}); err != nil || len(night.sent) != 2 {
+		t.Fatalf("expected alerts sent at once outside quiet hours: %v, %+v", err, night.sent)
+	}
+}
+
+func TestChannelNotifier_KeepsFailedDigests(t *testing.T) {
+	now := time.Date(2025, 9, 20, 12, 0, 0, 0, time.UTC)
+	n := NewChannelNotifier(AlertConfig{
+		Channels: []NotifyChannel{{Name: "ops", NotifyURL: "https://chat.example.com/ops", Digest: time.Minute}},
+		Critical: []string{models.AlertErrorEvent},
+	})
+	n.now = func() time.Time { return now }
+	ops := &digestStub{digestErr: errors.New("status 502")}
+	n.channels[0].out = ops
+	ctx := context.Background()
+
+	for range MaxDigestAlerts + 3 {
+		_ = n.Notify(ctx, models.Alert{Kind: models.AlertTempAbove})
+	}
+	now = now.Add(time.Minute)
+	n.Flush(ctx)
+	_ = n.Notify(ctx, models.Alert{Kind: models.AlertTempAbove})
+	ops.digestErr = nil
+	n.Flush(ctx)
+	if len(ops.digests) != 2 || ops.digests[1].Count != MaxDigestAlerts+4 || ops.digests[1].Dropped != 4 || len(ops.digests[1].Alerts) != MaxDigestAlerts {
+		t.Fatalf("expected the failed digest resent with the alert held since, got %d digests, last %+v", len(ops.digests), ops.digests[len(ops.digests)-1].Count)
+	}
+	n.Flush(ctx)
+	if len(ops.digests) != 2 || len(ops.sent) != 0 {
+		t.Fatalf("expected nothing left to send, got %d digests and %d alerts", len(ops.digests), len(ops.sent))
+	}
+}
+
+func TestChannelNotifier_DrainsHeldAlertsWhenAlertsStop(t *testing.T) {
+	now := time.Date(2025, 9, 20, 23, 0, 0, 0, time.UTC)
+	n := NewChannelNotifier(AlertConfig{
+		Channels: []NotifyChannel{{Name: "night", NotifyURL: "https://chat.example.com/night", QuietHours: "22:00-06:00", Digest: time.Hour}},
+	})
+	n.now = func() time.Time { return now }
+	night := &digestStub{}
+	n.channels[0].out = night
+	svc := NewAlertService(&alertRepoStub{}, &simEventRepoStub{}, NewStateBroker())
+	svc.notifier = n
+
+	if err := n.Notify(context.Background(), models.Alert{RuleName: "ending", Kind: models.AlertRemainingBelow}); err != nil || len(night.sent) != 0 {
+		t.Fatalf("expected the alert held: %v, %+v", err, night.sent)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	svc.Run(ctx)
+	if len(night.digests) != 1 || night.digests[0].Count != 1 || night.digests[0].Alerts[0].RuleName != "ending" {
+		t.Fatalf("expected the held alert sent when Run stopped, got %+v", night.digests)
+	}
+	n.Drain(context.Background())
+	if len(night.digests) != 1 {
+		t.Fatalf("drained an empty channel: %+v", night.digests)
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"math"
+
+	"controlling_furnace/internal/models"
+)
+
+// PowerConfig describes the furnace's electrical load.
+type PowerConfig struct {
+	HeaterKW     float64 // rated element power, drawn in full while ramping up
+	HoldFraction float64 // share of HeaterKW needed to hold at MaxSafeC; scales with temperature above ambient
+	CoolingKW    float64 // blowers during forced COOL
+	IdleKW       float64 // controls and fans whenever the furnace is running
+}
+
+// powerDraw returns the instantaneous draw in kW for the tick that just
+// moved the chamber from prevTempC to st.CurrentTempC.
+func (s *SimulatorService) powerDraw(st *models.FurnaceState, prevTempC float64) float64 {
+	if !st.IsRunning || s.faults.has(FaultPowerLoss) {
+		return 0
+	}
+	cfg := s.cfg.Power
+	kw := cfg.IdleKW
+	switch st.Mode {
+	case ModeHeat:
+		if s.faults.has(FaultHeaterFailure) {
+			break
+		}
+		if prevTempC < st.TargetTempC-SoakToleranceC {
+			kw += cfg.HeaterKW
+		} else {
+			kw += s.holdKW(st)
+		}
+	case ModeStandby:
+		// keep-warm ramps up like HEAT, then modulates to hold the setpoint
+		sp := st.KeepWarmC
+		if sp <= s.room || s.faults.has(FaultHeaterFailure) {
+			break
+		}
+		switch {
+		case prevTempC < sp-SoakToleranceC:
+			kw += cfg.HeaterKW
+		case st.CurrentTempC <= sp:
+			kw += s.holdKW(st)
+		}
+	case ModeCool:
+		if st.CurrentTempC > s.room {
+			kw += cfg.CoolingKW
+		}
+	}
+	return math.Round(kw*100) / 100
+}
+
+// holdKW is what the elements draw to replace the chamber's losses at its
+// current temperature.
+func (s *SimulatorService) holdKW(st *models.FurnaceState) float64 {
+	loss := (st.CurrentTempC - s.room) / (s.cfg.Physics.MaxSafeC - s.room)
+	return s.cfg.Power.HeaterKW * s.cfg.Power.HoldFraction * math.Max(loss, 0)
+}
+
+// meterEnergy sets the current draw and adds the tick's consumption to the
+// active run. Returns true if either value changed.
+func (s *SimulatorService) meterEnergy(st *models.FurnaceState, prevTempC, elapsed float64) bool {
+	prevKW, prevKWh := st.PowerKW, st.EnergyKWh
+	st.PowerKW = s.powerDraw(st, prevTempC)
+	if st.RunID != "" {
+		st.EnergyKWh += st.PowerKW * elapsed / 3600
+	}
+	return st.PowerKW != prevKW || st.EnergyKWh != prevKWh
+}
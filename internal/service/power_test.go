@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestPowerDraw_ByPhase(t *testing.T) {
+	svc := NewSimulatorService(&simStateRepoStub{}, &simEventRepoStub{})
+	cfg := svc.cfg.Power
+
+	ramp := models.FurnaceState{Mode: ModeHeat, IsRunning: true, TargetTempC: 800, CurrentTempC: 503}
+	if got := svc.powerDraw(&ramp, 500); got != cfg.IdleKW+cfg.HeaterKW {
+		t.Fatalf("ramping draw = %v, want full heater", got)
+	}
+
+	hold := models.FurnaceState{Mode: ModeHeat, IsRunning: true, TargetTempC: 800, CurrentTempC: 800}
+	got := svc.powerDraw(&hold, 800)
+	if got <= cfg.IdleKW || got >= cfg.IdleKW+cfg.HeaterKW {
+		t.Fatalf("holding draw = %v, want between idle and full heater", got)
+	}
+
+	_ = svc.InjectFault(FaultHeaterFailure)
+	if got := svc.powerDraw(&ramp, 500); got != cfg.IdleKW {
+		t.Fatalf("heater failure draw = %v, want idle only", got)
+	}
+	_ = svc.InjectFault(FaultPowerLoss)
+	if got := svc.powerDraw(&ramp, 500); got != 0 {
+		t.Fatalf("power loss draw = %v, want 0", got)
+	}
+
+	stopped := models.FurnaceState{Mode: ModeHeat, CurrentTempC: 500}
+	if got := svc.powerDraw(&stopped, 500); got != 0 {
+		t.Fatalf("stopped draw = %v, want 0", got)
+	}
+}
+
+func TestTick_AccumulatesRunEnergy(t *testing.T) {
+	now := time.Now()
+	states := &simStateRepoStub{loadResp: models.FurnaceState{
+		ID: 1, Mode: ModeHeat, IsRunning: true, RunID: "run-1",
+		TargetTempC: 800, CurrentTempC: 100, MeasuredTempC: 100, RemainingSeconds: 600,
+		EnergyKWh: 1, UpdatedAt: now.Add(-36 * time.Second),
+	}}
+	runs := &runRepoStub{}
+	svc := NewSimulatorServiceWithConfig(states, &simEventRepoStub{}, runs, nil, nil, DefaultSimConfig())
+
+	svc.tick(context.Background(), now)
+
+	st := states.saves[0]
+	cfg := svc.cfg.Power
+	// 36 s at full power is 1/100 h
+	want := 1 + (cfg.HeaterKW+cfg.IdleKW)/100
+	if math.Abs(st.EnergyKWh-want) > 1e-9 {
+		t.Fatalf("energy = %v, want %v", st.EnergyKWh, want)
+	}
+	if runs.runs["run-1"].EnergyKWh != st.EnergyKWh {
+		t.Fatalf("run record energy %v != state energy %v", runs.runs["run-1"].EnergyKWh, st.EnergyKWh)
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"controlling_furnace/internal/repository"
+)
+
+// Readiness components.
+const (
+	ComponentDatabase  = "database"
+	ComponentSchema    = "schema"
+	ComponentSimulator = "simulator"
+)
+
+// ProbeConfig tunes the readiness check.
+type ProbeConfig struct {
+	// MaxTickAge is how long the simulator may go without a tick before the
+	// instance is not ready. It never drops below three tick intervals, so
+	// a slowed-down simulator does not fail the probe.
+	MaxTickAge time.Duration
+	Timeout    time.Duration // per database check
+}
+
+// DefaultProbeConfig returns the readiness settings used when none are configured.
+func DefaultProbeConfig() ProbeConfig {
+	return ProbeConfig{MaxTickAge: 10 * time.Second, Timeout: 2 * time.Second}
+}
+
+// ComponentStatus is the outcome of one readiness check.
+type ComponentStatus struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// ReadinessReport lists every component; Ready is false if any failed.
+type ReadinessReport struct {
+	Ready      bool                       `json:"ready"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// tickSource is the part of the simulator the readiness check watches.
+type tickSource interface {
+	LastTick() time.Time
+	Speed() Speed
+}
+
+type ProbeService struct {
+	repo repository.StatusRepo // optional; database checks are skipped when nil
+	sim  tickSource
+	cfg  ProbeConfig
+	now  func() time.Time
+}
+
+func NewProbeService(repo repository.StatusRepo, sim tickSource, cfg ProbeConfig) *ProbeService {
+	def := DefaultProbeConfig()
+	if cfg.MaxTickAge <= 0 {
+		cfg.MaxTickAge = def.MaxTickAge
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	return &ProbeService{repo: repo, sim: sim, cfg: cfg, now: time.Now}
+}
+
+// Ready checks that the database accepts writes, that its schema is
+// current, and that the simulator loop is still ticking.
+func (s *ProbeService) Ready(ctx context.Context) ReadinessReport {
+	rep := ReadinessReport{Ready: true, Components: make(map[string]ComponentStatus, 3)}
+	set := func(name string, err error) {
+		st := ComponentStatus{OK: err == nil}
+		if err != nil {
+			st.Error = err.Error()
+			rep.Ready = false
+		}
+		rep.Components[name] = st
+	}
+
+	if s.repo != nil {
+		set(ComponentDatabase, s.withTimeout(ctx, s.repo.Ping))
+		set(ComponentSchema, s.withTimeout(ctx, s.repo.CheckSchema))
+	}
+	set(ComponentSimulator, s.checkTicking())
+	return rep
+}
+
+func (s *ProbeService) withTimeout(ctx context.Context, check func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	return check(ctx)
+}
+
+func (s *ProbeService) checkTicking() error {
+	last := s.sim.LastTick()
+	if last.IsZero() {
+		return fmt.Errorf("simulator has not ticked yet")
+	}
+	limit := max(s.cfg.MaxTickAge, 3*s.sim.Speed().Tick)
+	if age := s.now().Sub(last); age > limit {
+		return fmt.Errorf("last tick %s ago, limit %s", age.Round(time.Second), limit)
+	}
+	return nil
+}
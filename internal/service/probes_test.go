@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type statusRepoStub struct {
+	pingErr, schemaErr error
+}
+
+func (r *statusRepoStub) Ping(ctx context.Context) error        { return r.pingErr }
+func (r *statusRepoStub) CheckSchema(ctx context.Context) error { return r.schemaErr }
+func (r *statusRepoStub) Stats() sql.DBStats                    { return sql.DBStats{OpenConnections: 1, InUse: 1} }
+
+type tickSourceStub struct {
+	last time.Time
+	tick time.Duration
+}
+
+func (t tickSourceStub) LastTick() time.Time { return t.last }
+func (t tickSourceStub) Speed() Speed        { return Speed{Tick: t.tick} }
+
+func TestProbeService_Ready(t *testing.T) {
+	now := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	repo := &statusRepoStub{}
+	sim := tickSourceStub{last: now.Add(-2 * time.Second), tick: time.Second}
+	svc := NewProbeService(repo, sim, ProbeConfig{})
+	svc.now = func() time.Time { return now }
+
+	if rep := svc.Ready(context.Background()); !rep.Ready || len(rep.Components) != 3 {
+		t.Fatalf("expected ready with three components, got %+v", rep)
+	}
+
+	repo.pingErr = errors.New("database is locked")
+	rep := svc.Ready(context.Background())
+	if rep.Ready || rep.Components[ComponentDatabase].Error != "database is locked" || !rep.Components[ComponentSchema].OK {
+		t.Fatalf("expected only the database to fail, got %+v", rep)
+	}
+}
+
+func TestProbeService_SimulatorStalled(t *testing.T) {
+	now := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name  string
+		sim   tickSourceStub
+		ready bool
+	}{
+		{"never ticked", tickSourceStub{tick: time.Second}, false},
+		{"stalled", tickSourceStub{last: now.Add(-11 * time.Second), tick: time.Second}, false},
+		// three 5s ticks outlast the 10s default
+		{"slow tick", tickSourceStub{last: now.Add(-12 * time.Second), tick: 5 * time.Second}, true},
+	} {
+		svc := NewProbeService(nil, tc.sim, ProbeConfig{})
+		svc.now = func() time.Time { return now }
+		rep := svc.Ready(context.Background())
+		if rep.Ready != tc.ready {
+			t.Fatalf("%s: ready=%v, want %v (%+v)", tc.name, rep.Ready, tc.ready, rep)
+		}
+		if _, ok := rep.Components[ComponentDatabase]; ok {
+			t.Fatalf("%s: database checked without a status repository", tc.name)
+		}
+		if !tc.ready && !strings.Contains(rep.Components[ComponentSimulator].Error, "tick") {
+			t.Fatalf("%s: unexpected error %q", tc.name, rep.Components[ComponentSimulator].Error)
+		}
+	}
+}
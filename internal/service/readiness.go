@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Readiness blocker codes.
+const (
+	BlockerInvalidParams  = "INVALID_PARAMS"
+	BlockerBelowAmbient   = "TARGET_BELOW_AMBIENT"
+	BlockerAboveMaxSafe   = "TARGET_ABOVE_MAX_SAFE"
+	BlockerNotRunning     = "FURNACE_NOT_RUNNING"
+	BlockerActiveAlarm    = "ACTIVE_ALARM"
+	BlockerHeatInProgress = "HEAT_IN_PROGRESS"
+)
+
+// Blocker is one reason a HEAT command would be refused or is unsafe.
+type Blocker struct {
+	Code    string `json:"code" example:"FURNACE_NOT_RUNNING"`
+	Message string `json:"message" example:"furnace is stopped, start it first"`
+
+	err error // returned by SetMode for parameter blockers
+}
+
+// Readiness is the outcome of a pre-heat check.
+type Readiness struct {
+	Ready    bool      `json:"ready"`
+	Blockers []Blocker `json:"blockers"`
+}
+
+func newBlocker(code string, err error) *Blocker {
+	return &Blocker{Code: code, Message: err.Error(), err: err}
+}
+
+// heatParamsBlocker returns the first reason p is not a valid HEAT
+// command under phys, or nil. SetMode enforces the same rules.
+func heatParamsBlocker(p ModeParams, phys PhysicsConfig) *Blocker {
+	switch {
+	case !(p.TargetTempC > 0 && p.DurationSec > 0):
+		return newBlocker(BlockerInvalidParams, errInvalidHeatCfg)
+	case p.TargetTempC < phys.AmbientC:
+		return newBlocker(BlockerBelowAmbient,
+			fmt.Errorf("target temperature %.1f is below ambient temperature %.1f", p.TargetTempC, phys.AmbientC))
+	case p.TargetTempC > phys.MaxSafeC:
+		return newBlocker(BlockerAboveMaxSafe,
+			fmt.Errorf("target temperature %.1f exceeds max safe limit %.1f", p.TargetTempC, phys.MaxSafeC))
+	}
+	return nil
+}
+
+// physics returns the limits HEAT commands are checked against.
+func (s *FurnaceService) physics() PhysicsConfig {
+	if s.limits == nil {
+		return PhysicsConfig{}.withDefaults()
+	}
+	return s.limits()
+}
+
+// CheckReadiness reports everything that stands in the way of a HEAT
+// command with the given target and duration, without changing state.
+// Unlike SetMode it does not stop at the first problem.
+func (s *FurnaceService) CheckReadiness(ctx context.Context, targetTempC float64, durationSec int) (Readiness, error) {
+	st, err := s.state.Load(ctx)
+	if err != nil {
+		return Readiness{}, err
+	}
+
+	blockers := []Blocker{}
+	if b := heatParamsBlocker(ModeParams{Mode: ModeHeat, TargetTempC: targetTempC, DurationSec: durationSec}, s.physics()); b != nil {
+		blockers = append(blockers, *b)
+	}
+	if !st.IsRunning {
+		blockers = append(blockers, Blocker{Code: BlockerNotRunning, Message: "furnace is stopped, start it first"})
+	}
+	if len(st.ErrorCodes) > 0 {
+		blockers = append(blockers, Blocker{
+			Code:    BlockerActiveAlarm,
+			Message: "active alarms: " + strings.Join(st.ErrorCodes, ", "),
+		})
+	}
+	if st.IsRunning && st.Mode == ModeHeat {
+		blockers = append(blockers, Blocker{
+			Code:    BlockerHeatInProgress,
+			Message: "a heat cycle is already in progress; a new HEAT command would replace it",
+		})
+	}
+	return Readiness{Ready: len(blockers) == 0, Blockers: blockers}, nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"controlling_furnace/internal/models"
+)
+
+func blockerCodes(r Readiness) []string {
+	codes := make([]string, 0, len(r.Blockers))
+	for _, b := range r.Blockers {
+		codes = append(codes, b.Code)
+	}
+	return codes
+}
+
+func TestCheckReadiness_Ready(t *testing.T) {
+	st := &fakeStateRepo{loadResp: models.FurnaceState{ID: 1, Mode: ModeStandby, IsRunning: true}}
+	svc := NewFurnaceService(st, &localEventRepo{})
+
+	r, err := svc.CheckReadiness(context.Background(), 850, 600)
+	if err != nil {
+		t.Fatalf("CheckReadiness: %v", err)
+	}
+	if !r.Ready || len(r.Blockers) != 0 {
+		t.Fatalf("expected ready, got %+v", r)
+	}
+	if len(st.savedCalls) != 0 {
+		t.Fatalf("readiness check must not modify state")
+	}
+}
+
+func TestCheckReadiness_ReportsAllBlockers(t *testing.T) {
+	st := &fakeStateRepo{loadResp: models.FurnaceState{ID: 1, Mode: ModeStandby, ErrorCodes: []string{"OVERHEAT"}}}
+	svc := NewFurnaceService(st, &localEventRepo{})
+
+	r, err := svc.CheckReadiness(context.Background(), MaxSafeC+100, 600)
+	if err != nil {
+		t.Fatalf("CheckReadiness: %v", err)
+	}
+	got := blockerCodes(r)
+	want := []string{BlockerAboveMaxSafe, BlockerNotRunning, BlockerActiveAlarm}
+	if r.Ready || len(got) != len(want) {
+		t.Fatalf("blockers = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("blockers = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestCheckReadiness_HeatInProgress(t *testing.T) {
+	st := &fakeStateRepo{loadResp: models.FurnaceState{ID: 1, Mode: ModeHeat, IsRunning: true}}
+	svc := NewFurnaceService(st, &localEventRepo{})
+
+	r, _ := svc.CheckReadiness(context.Background(), 0, 0)
+	got := blockerCodes(r)
+	if len(got) != 2 || got[0] != BlockerInvalidParams || got[1] != BlockerHeatInProgress {
+		t.Fatalf("unexpected blockers: %v", got)
+	}
+}
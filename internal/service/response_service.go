@@ -1,16 +1,76 @@
 package service
 
-import "time"
+import (
+	"time"
+
+	"controlling_furnace/internal/models"
+)
 
 type ModeParams struct {
 	Mode        string  // "HEAT" | "COOL" | "STANDBY"
 	TargetTempC float64 // only used when Mode == "HEAT"
 	DurationSec int     // only used when Mode == "HEAT"
+	// Atmosphere, if set, changes the protective gas with the mode.
+	Atmosphere *AtmosphereParams
 }
 
 // LogFilter supports history filtering by time range and type (per test).
 type LogFilter struct {
-	From time.Time // inclusive; zero means no lower bound
-	To   time.Time // inclusive; zero means no upper bound
-	Type string    // "", "START", "STOP", "MODE_CHANGE", "ERROR", "TELEMETRY"
+	From  time.Time // inclusive; zero means no lower bound
+	To    time.Time // inclusive; zero means no upper bound
+	Type  string    // "", "START", "STOP", "MODE_CHANGE", "ERROR", "TELEMETRY"
+	RunID string    // "" or a heat-cycle run id
+	// Types keeps events of any of these types; ExcludeTypes drops events
+	// of these, e.g. TELEMETRY to see only operator actions.
+	Types        []string
+	ExcludeTypes []string
+	// Meta keeps events whose metadata satisfies every filter; see
+	// ParseMetaFilter.
+	Meta []MetaFilter
+	// IncludeDeleted also returns soft-deleted events; see DeleteEvent.
+	// Callers check that the user may see them.
+	IncludeDeleted bool
+	// Severity keeps events of the types the catalog rates at least this
+	// severe: "info", "warning" or "critical". Types outside the catalog,
+	// such as imported ones, have no severity and are left out.
+	Severity string
+}
+
+// MetaFilter compares the metadata value at Key with Value. Values that
+// parse as numbers or booleans also match JSON numbers and booleans.
+type MetaFilter struct {
+	Key   string // dotted path, e.g. "to" or "limits.max_c"
+	Op    string // =, !=, <, <=, > or >=
+	Value string
+}
+
+// LogPageParams selects a page of a log listing.
+type LogPageParams struct {
+	Cursor string // NextCursor of the previous page; "" for the first
+	Limit  int    // 0 means DefaultLogLimit; capped at MaxLogLimit
+	Desc   bool   // newest first
+}
+
+// LogPage is one page of a log listing.
+type LogPage struct {
+	Events []models.FurnaceEvent
+	// NextCursor continues the listing; empty once the log is exhausted.
+	NextCursor string
+}
+
+// TailParams selects where a tail of the log continues and how long it
+// waits for new events.
+type TailParams struct {
+	AfterID string        // NextAfterID of the previous read; with a zero AfterAt too, start at the end of the log
+	AfterAt time.Time     // only events that occurred after this
+	Limit   int           // 0 means DefaultLogLimit; capped at MaxLogLimit
+	Wait    time.Duration // long-poll up to this long for an event; capped at MaxTailWait
+}
+
+// LogTail is one read of a tail of the log.
+type LogTail struct {
+	Events []models.FurnaceEvent
+	// NextAfterID continues the tail; empty until the log has an event to
+	// continue after.
+	NextAfterID string
 }
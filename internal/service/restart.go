@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// downtime returns the part of the wall-clock gap since st was saved that
+// must not be simulated as running time. On the first tick after a start
+// that is the whole gap, and a BOOT event records it.
+func (s *SimulatorService) downtime(ctx context.Context, st models.FurnaceState, gap time.Duration, now time.Time) time.Duration {
+	if s.booting {
+		s.booting = false
+		if gap < 0 {
+			gap = 0
+		}
+		s.emit(models.FurnaceEvent{
+			EventID:     s.newID(),
+			OccurredAt:  now.UTC(),
+			Type:        "BOOT",
+			Description: "Simulator started; downtime not counted toward the soak timer",
+			Metadata: withRunID(map[string]any{
+				"downtime_s":        gap.Seconds(),
+				"last_update":       st.UpdatedAt.UTC(),
+				"mode":              st.Mode,
+				"isRunning":         st.IsRunning,
+				"remaining_seconds": st.RemainingSeconds,
+			}, st.RunID),
+		})
+		return gap
+	}
+	limit := s.cfg.MaxElapsed
+	if limit <= 0 {
+		return 0
+	}
+	// a slow tick must not be mistaken for an outage
+	if tick := s.Speed().Tick; limit < 2*tick {
+		limit = 2 * tick
+	}
+	if gap > limit {
+		return gap - limit
+	}
+	return 0
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBoot_DowntimePausesSoak(t *testing.T) {
+	now := time.Now()
+	st := heatingState(now)
+	st.CurrentTempC = st.TargetTempC
+	st.UpdatedAt = now.Add(-time.Hour)
+	states := &simStateRepoStub{loadResp: st}
+	events := &simEventRepoStub{}
+	svc := NewSimulatorService(states, events)
+	svc.booting = true
+
+	svc.tick(context.Background(), now)
+
+	saved := states.saves[0]
+	if saved.RemainingSeconds != st.RemainingSeconds {
+		t.Fatalf("soak timer ran during downtime: %d -> %d", st.RemainingSeconds, saved.RemainingSeconds)
+	}
+	if saved.Mode != ModeHeat || !saved.IsRunning {
+		t.Fatalf("cycle should resume after restart, got %+v", saved)
+	}
+	if saved.CurrentTempC >= st.CurrentTempC {
+		t.Fatalf("chamber should have cooled while down, got %.2f", saved.CurrentTempC)
+	}
+	if !saved.UpdatedAt.Equal(now.UTC()) {
+		t.Fatalf("downtime must be consumed, UpdatedAt=%s", saved.UpdatedAt)
+	}
+	if len(events.appends) != 1 || events.appends[0].Type != "BOOT" {
+		t.Fatalf("expected one BOOT event, got %+v", events.appends)
+	}
+	meta, _ := events.appends[0].Metadata.(map[string]any)
+	if meta["downtime_s"] != time.Hour.Seconds() || meta["run_id"] != "run-1" {
+		t.Fatalf("unexpected metadata: %v", meta)
+	}
+
+	// Later ticks are simulated normally and log no further BOOT.
+	states.loadResp = saved
+	svc.tick(context.Background(), now.Add(2*time.Second))
+	if len(events.appends) != 1 {
+		t.Fatalf("BOOT logged twice: %+v", events.appends)
+	}
+}
+
+func TestTick_ClampsLongGaps(t *testing.T) {
+	now := time.Now()
+	st := heatingState(now)
+	st.CurrentTempC = st.TargetTempC
+	// 2s over the clamp: cools 1 °C, still inside the soak band
+	st.UpdatedAt = now.Add(-32 * time.Second)
+	states := &simStateRepoStub{loadResp: st}
+	cfg := DefaultSimConfig()
+	cfg.MaxElapsed = 30 * time.Second
+	svc := NewSimulatorServiceWithConfig(states, &simEventRepoStub{}, nil, nil, nil, cfg)
+
+	svc.tick(context.Background(), now)
+
+	if got := states.saves[0].RemainingSeconds; got != st.RemainingSeconds-30 {
+		t.Fatalf("expected soak to advance by the 30s clamp, remaining %d", got)
+	}
+}
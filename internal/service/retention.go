@@ -0,0 +1,199 @@
+package service
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// DefaultRetentionInterval is how often the janitor purges the event log
+// when no interval is configured.
+const DefaultRetentionInterval = time.Hour
+
+// ErrInvalidPurge is returned for purge limits that cannot be applied,
+// including a purge with neither an age nor a row limit.
+var ErrInvalidPurge = errors.New("invalid purge")
+
+// RetentionConfig limits how much of the event log is kept. The zero value
+// keeps everything.
+type RetentionConfig struct {
+	MaxAge   time.Duration `mapstructure:"max_age"`  // events older than this are purged; 0 for no age limit
+	MaxRows  int           `mapstructure:"max_rows"` // only this many of the newest events are kept; 0 for no limit
+	Interval time.Duration `mapstructure:"interval"` // between janitor runs; DefaultRetentionInterval when 0
+	// ArchiveDir receives purged events as gzipped NDJSON, one file per
+	// purge; empty deletes them outright.
+	ArchiveDir string `mapstructure:"archive_dir"`
+}
+
+// Enabled reports whether the janitor has anything to purge.
+func (c RetentionConfig) Enabled() bool {
+	return c.MaxAge > 0 || c.MaxRows > 0
+}
+
+// Validate rejects negative limits.
+func (c RetentionConfig) Validate() error {
+	if c.MaxAge < 0 || c.MaxRows < 0 || c.Interval < 0 {
+		return fmt.Errorf("%w: retention limits must be >= 0", ErrInvalidPurge)
+	}
+	return nil
+}
+
+// PurgeRequest selects the events removed by an on-demand purge. Unset
+// limits fall back to the configured retention.
+type PurgeRequest struct {
+	MaxAge  time.Duration
+	MaxRows int
+	DryRun  bool
+}
+
+// RetentionService purges expired events, on demand and from Run.
+type RetentionService struct {
+	repo   repository.EventRetentionRepo
+	events repository.EventRepo // records each purge; may be nil
+	cfg    RetentionConfig
+	now    func() time.Time
+	newID  func() string
+}
+
+func NewRetentionService(repo repository.EventRetentionRepo, events repository.EventRepo, cfg RetentionConfig) *RetentionService {
+	return &RetentionService{repo: repo, events: events, cfg: cfg, now: time.Now, newID: uuid.NewString}
+}
+
+// PurgeEvents removes the events beyond the requested or configured limits,
+// archiving them first when an archive directory is configured. A real
+// purge appends a LOG_PURGED event, which is kept.
+func (s *RetentionService) PurgeEvents(ctx context.Context, req PurgeRequest) (models.PurgeReport, error) {
+	if req.MaxAge < 0 || req.MaxRows < 0 {
+		return models.PurgeReport{}, fmt.Errorf("%w: max_age and max_rows must be >= 0", ErrInvalidPurge)
+	}
+	if req.MaxAge == 0 {
+		req.MaxAge = s.cfg.MaxAge
+	}
+	if req.MaxRows == 0 {
+		req.MaxRows = s.cfg.MaxRows
+	}
+	if req.MaxAge == 0 && req.MaxRows == 0 {
+		return models.PurgeReport{DryRun: req.DryRun}, fmt.Errorf("%w: no max_age or max_rows given or configured", ErrInvalidPurge)
+	}
+	now := s.now().UTC()
+	p := repository.EventPurge{KeepMax: req.MaxRows, DryRun: req.DryRun}
+	if req.MaxAge > 0 {
+		p.Before = now.Add(-req.MaxAge)
+	}
+
+	var archive *gzipArchive
+	if s.cfg.ArchiveDir != "" && !req.DryRun {
+		archive = &gzipArchive{path: filepath.Join(s.cfg.ArchiveDir, "events-"+now.Format("20060102T150405Z")+".ndjson.gz")}
+		p.Archive = archive.path
+	}
+	var rep models.PurgeReport
+	var err error
+	if archive != nil {
+		rep, err = s.repo.Purge(ctx, p, archive)
+		if err != nil {
+			archive.discard()
+		}
+	} else {
+		rep, err = s.repo.Purge(ctx, p, nil)
+	}
+	if err != nil || req.DryRun || rep.Deleted == 0 || s.events == nil {
+		return rep, err
+	}
+	meta := map[string]any{"deleted": rep.Deleted}
+	if rep.Archive != "" {
+		meta["archive"] = rep.Archive
+	}
+	_ = s.events.Append(ctx, models.FurnaceEvent{
+		EventID:     s.newID(),
+		OccurredAt:  now,
+		Type:        "LOG_PURGED",
+		Description: fmt.Sprintf("Purged %d events from the log", rep.Deleted),
+		Metadata:    meta,
+	})
+	return rep, nil
+}
+
+// Run purges the event log by the configured retention every interval
+// until ctx is canceled, starting at once. Failed purges are retried on
+// the next interval.
+func (s *RetentionService) Run(ctx context.Context) {
+	if !s.cfg.Enabled() {
+		<-ctx.Done()
+		return
+	}
+	interval := s.cfg.Interval
+	if interval <= 0 {
+		interval = DefaultRetentionInterval
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		_, _ = s.PurgeEvents(ctx, PurgeRequest{})
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// gzipArchive writes purged events as gzipped NDJSON. The file is created
+// on the first event under a temporary name and renamed into place by
+// Close, so a failed purge leaves no partial archive behind.
+type gzipArchive struct {
+	path string
+	f    *os.File
+	gz   *gzip.Writer
+	enc  *json.Encoder
+}
+
+func (a *gzipArchive) Write(ev models.FurnaceEvent) error {
+	if a.f == nil {
+		if err := os.MkdirAll(filepath.Dir(a.path), 0o755); err != nil {
+			return fmt.Errorf("create archive dir: %w", err)
+		}
+		f, err := os.Create(a.path + ".tmp")
+		if err != nil {
+			return fmt.Errorf("create archive: %w", err)
+		}
+		a.f, a.gz = f, gzip.NewWriter(f)
+		a.enc = json.NewEncoder(a.gz)
+	}
+	return a.enc.Encode(ev)
+}
+
+func (a *gzipArchive) Close() error {
+	if a.f == nil {
+		return nil
+	}
+	if err := a.gz.Close(); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+	if err := a.f.Sync(); err != nil {
+		return fmt.Errorf("sync archive: %w", err)
+	}
+	if err := a.f.Close(); err != nil {
+		return fmt.Errorf("close archive: %w", err)
+	}
+	a.f = nil
+	return os.Rename(a.path+".tmp", a.path)
+}
+
+// discard removes the archive of a purge that did not commit.
+func (a *gzipArchive) discard() {
+	if a.f != nil {
+		_ = a.f.Close()
+	}
+	_ = os.Remove(a.path + ".tmp")
+	_ = os.Remove(a.path)
+}
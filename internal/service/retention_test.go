@@ -0,0 +1,126 @@
+package service
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+// fakeRetentionRepo "purges" its events, copying them to the archive.
+type fakeRetentionRepo struct {
+	events []models.FurnaceEvent
+	err    error
+	got    repository.EventPurge
+}
+
+func (f *fakeRetentionRepo) Purge(ctx context.Context, p repository.EventPurge, archive repository.EventArchive) (models.PurgeReport, error) {
+	f.got = p
+	rep := models.PurgeReport{DryRun: p.DryRun, Deleted: int64(len(f.events))}
+	if p.DryRun || archive == nil {
+		return rep, f.err
+	}
+	for _, ev := range f.events {
+		if err := archive.Write(ev); err != nil {
+			return rep, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return rep, err
+	}
+	rep.Archive = p.Archive
+	return rep, f.err
+}
+
+func TestPurgeEvents_FallsBackToConfig(t *testing.T) {
+	now := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	repo := &fakeRetentionRepo{events: []models.FurnaceEvent{{EventID: "a"}}}
+	events := &localEventRepo{}
+	svc := NewRetentionService(repo, events, RetentionConfig{MaxAge: 24 * time.Hour, MaxRows: 1000})
+	svc.now = func() time.Time { return now }
+	svc.newID = func() string { return "purge-1" }
+
+	rep, err := svc.PurgeEvents(context.Background(), PurgeRequest{MaxRows: 10})
+	if err != nil {
+		t.Fatalf("PurgeEvents: %v", err)
+	}
+	if !repo.got.Before.Equal(now.Add(-24*time.Hour)) || repo.got.KeepMax != 10 {
+		t.Fatalf("purge = %+v, want configured age and requested rows", repo.got)
+	}
+	if rep.Deleted != 1 || len(events.events) != 1 || events.events[0].Type != "LOG_PURGED" {
+		t.Fatalf("report %+v, events %+v", rep, events.events)
+	}
+
+	if _, err := svc.PurgeEvents(context.Background(), PurgeRequest{DryRun: true}); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !repo.got.DryRun || len(events.events) != 1 {
+		t.Fatal("dry run was not passed on or logged a purge")
+	}
+}
+
+func TestPurgeEvents_RequiresALimit(t *testing.T) {
+	svc := NewRetentionService(&fakeRetentionRepo{}, nil, RetentionConfig{})
+	for _, req := range []PurgeRequest{{}, {MaxRows: -1}, {MaxAge: -time.Hour}} {
+		if _, err := svc.PurgeEvents(context.Background(), req); !errors.Is(err, ErrInvalidPurge) {
+			t.Errorf("%+v: err = %v, want ErrInvalidPurge", req, err)
+		}
+	}
+}
+
+func TestPurgeEvents_WritesGzipArchive(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	repo := &fakeRetentionRepo{events: []models.FurnaceEvent{{EventID: "a", Type: "START"}, {EventID: "b", Type: "STOP"}}}
+	svc := NewRetentionService(repo, nil, RetentionConfig{MaxRows: 5, ArchiveDir: dir})
+	svc.now = func() time.Time { return now }
+
+	rep, err := svc.PurgeEvents(context.Background(), PurgeRequest{})
+	if err != nil {
+		t.Fatalf("PurgeEvents: %v", err)
+	}
+	if want := filepath.Join(dir, "events-20250920T100000Z.ndjson.gz"); rep.Archive != want {
+		t.Fatalf("archive = %q, want %q", rep.Archive, want)
+	}
+	f, err := os.Open(rep.Archive)
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	dec := json.NewDecoder(gz)
+	var ids []string
+	for dec.More() {
+		var ev models.FurnaceEvent
+		if err := dec.Decode(&ev); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		ids = append(ids, ev.EventID)
+	}
+	if len(ids) != 2 || ids[0] != "a" || ids[1] != "b" {
+		t.Fatalf("archived %v, want [a b]", ids)
+	}
+}
+
+func TestPurgeEvents_DiscardsArchiveOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	repo := &fakeRetentionRepo{events: []models.FurnaceEvent{{EventID: "a"}}, err: errors.New("commit failed")}
+	svc := NewRetentionService(repo, nil, RetentionConfig{MaxRows: 5, ArchiveDir: dir})
+
+	if _, err := svc.PurgeEvents(context.Background(), PurgeRequest{}); err == nil {
+		t.Fatal("want error")
+	}
+	if left, _ := os.ReadDir(dir); len(left) != 0 {
+		t.Fatalf("archive left behind: %v", left)
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// MinAmbientC is the coldest room temperature that can be set.
+const MinAmbientC = -60.0
+
+// Where the current room temperature comes from.
+const (
+	AmbientSourceProfile  = "profile"  // physics ambient_c with the daily swing
+	AmbientSourceOverride = "override" // set through SetAmbient
+)
+
+// ErrInvalidAmbient reports a room temperature the simulator cannot use.
+var ErrInvalidAmbient = errors.New("invalid ambient temperature")
+
+// AmbientStatus is the room temperature the chamber converges to.
+type AmbientStatus struct {
+	TempC       float64 `json:"temp_c" example:"31.5"`
+	Source      string  `json:"source" example:"profile"`
+	MeanC       float64 `json:"mean_c" example:"25"`       // centre of the daily profile
+	DailySwingC float64 `json:"daily_swing_c" example:"8"` // amplitude of the daily profile
+	PeakHour    float64 `json:"peak_hour" example:"15"`    // UTC hour of the daily maximum
+}
+
+// roomAt returns the profile temperature at t: a sine around AmbientC that
+// peaks DailySwingC higher at PeakHour (UTC) and bottoms out 12 hours later.
+func roomAt(phys PhysicsConfig, cfg AmbientConfig, t time.Time) float64 {
+	if cfg.DailySwingC == 0 {
+		return phys.AmbientC
+	}
+	t = t.UTC()
+	hour := float64(t.Hour()) + float64(t.Minute())/60 + float64(t.Second())/3600
+	v := phys.AmbientC + cfg.DailySwingC*math.Cos(2*math.Pi*(hour-cfg.PeakHour)/24)
+	return math.Round(v*100) / 100
+}
+
+// updateRoom sets the room temperature for the step ending at now. The
+// profile follows the timestamps the simulator steps through, so it does
+// not speed up with the time scale.
+func (s *SimulatorService) updateRoom(now time.Time) {
+	s.settingsMu.RLock()
+	override := s.ambientOverride
+	s.settingsMu.RUnlock()
+	if override != nil {
+		s.room = *override
+		return
+	}
+	s.room = roomAt(s.cfg.Physics, s.cfg.Ambient, now)
+}
+
+// Ambient reports the current room temperature and its source.
+func (s *SimulatorService) Ambient() AmbientStatus {
+	s.settingsMu.RLock()
+	cfg, override := s.published, s.ambientOverride
+	s.settingsMu.RUnlock()
+	st := AmbientStatus{
+		TempC:       roomAt(cfg.Physics, cfg.Ambient, s.now()),
+		Source:      AmbientSourceProfile,
+		MeanC:       cfg.Physics.AmbientC,
+		DailySwingC: cfg.Ambient.DailySwingC,
+		PeakHour:    cfg.Ambient.PeakHour,
+	}
+	if override != nil {
+		st.TempC, st.Source = *override, AmbientSourceOverride
+	}
+	return st
+}
+
+// SetAmbient holds the room at tempC from the next tick on, replacing the
+// daily profile until ClearAmbient.
+func (s *SimulatorService) SetAmbient(tempC float64) error {
+	s.settingsMu.Lock()
+	defer s.settingsMu.Unlock()
+	if maxSafe := s.published.Physics.MaxSafeC; tempC < MinAmbientC || tempC >= maxSafe {
+		return fmt.Errorf("%w: must be within %.0f..%.0f °C", ErrInvalidAmbient, MinAmbientC, maxSafe)
+	}
+	s.ambientOverride = &tempC
+	return nil
+}
+
+// ClearAmbient returns the room to the configured profile.
+func (s *SimulatorService) ClearAmbient() {
+	s.settingsMu.Lock()
+	s.ambientOverride = nil
+	s.settingsMu.Unlock()
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestRoomAt_DailyProfile(t *testing.T) {
+	phys := PhysicsConfig{}.withDefaults()
+	cfg := AmbientConfig{DailySwingC: 8, PeakHour: 15}
+	day := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	if got := roomAt(phys, cfg, day.Add(15*time.Hour)); got != AmbientC+8 {
+		t.Fatalf("peak = %.2f, want %.2f", got, AmbientC+8)
+	}
+	if got := roomAt(phys, cfg, day.Add(3*time.Hour)); got != AmbientC-8 {
+		t.Fatalf("trough = %.2f, want %.2f", got, AmbientC-8)
+	}
+	if got := roomAt(phys, AmbientConfig{}, day.Add(15*time.Hour)); got != AmbientC {
+		t.Fatalf("without a swing the room should stay at %.2f, got %.2f", AmbientC, got)
+	}
+}
+
+func TestStep_CoolsTowardOverriddenAmbient(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	states := &simStateRepoStub{loadResp: models.FurnaceState{ID: 1, Mode: ModeStandby, CurrentTempC: 20, UpdatedAt: start}}
+	svc := NewSimulatorService(states, &simEventRepoStub{})
+
+	if err := svc.SetAmbient(-10); err != nil {
+		t.Fatalf("SetAmbient: %v", err)
+	}
+	if a := svc.Ambient(); a.TempC != -10 || a.Source != AmbientSourceOverride {
+		t.Fatalf("unexpected status %+v", a)
+	}
+	for i := 0; i < 2; i++ {
+		if err := svc.Step(context.Background(), time.Minute); err != nil {
+			t.Fatalf("Step: %v", err)
+		}
+		states.loadResp = states.saves[len(states.saves)-1]
+	}
+	if got := states.loadResp.CurrentTempC; got != -10 {
+		t.Fatalf("expected the stopped chamber to settle at the room temperature, got %.2f", got)
+	}
+
+	// a warmer room warms the chamber back up at the passive rate
+	svc.ClearAmbient()
+	if err := svc.Step(context.Background(), 10*time.Second); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	if got, want := states.saves[len(states.saves)-1].CurrentTempC, -10+StandbyCoolPerSec*10; got != want {
+		t.Fatalf("expected %.2f after warming for 10s, got %.2f", want, got)
+	}
+
+	if err := svc.SetAmbient(MaxSafeC); !errors.Is(err, ErrInvalidAmbient) {
+		t.Fatalf("expected ErrInvalidAmbient, got %v", err)
+	}
+}
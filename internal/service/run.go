@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"time"
+)
+
+// A run is a single heat cycle: it begins with a HEAT command and lasts
+// through soak and the automatic cool-down until the furnace is stopped,
+// put into STANDBY, or a new HEAT command starts the next run.
+
+// withRunID attaches runID to event metadata. A nil map is allocated
+// when needed; an empty runID leaves meta untouched.
+func withRunID(meta map[string]any, runID string) map[string]any {
+	if runID == "" {
+		return meta
+	}
+	if meta == nil {
+		meta = make(map[string]any, 1)
+	}
+	meta["run_id"] = runID
+	return meta
+}
+
+// runTracker caches the record of the active run between simulator ticks.
+type runTracker struct {
+	run models.Run
+	m2  float64 // weighted sum of squared soak deviations from the mean (Welford)
+}
+
+// recordRun updates the record of st's run with this tick's energy and
+// soak statistics. Returns true if the tick counted towards the soak, so
+// the caller must persist it.
+func (s *SimulatorService) recordRun(ctx context.Context, st *models.FurnaceState, elapsed float64, now time.Time) bool {
+	if st.RunID == "" {
+		return false
+	}
+	t := s.runFor(ctx, st, now)
+	if t == nil {
+		return false
+	}
+	prev := t.run
+	t.run.EnergyKWh = st.EnergyKWh
+	soaked := s.trackSoak(ctx, t, st, elapsed, now)
+	if soaked || t.run != prev {
+		s.saveRun(ctx, t, now)
+	}
+	return soaked
+}
+
+// runFor returns the tracker for st's run, resuming a persisted record or
+// starting a new one on the first tick of the run.
+func (s *SimulatorService) runFor(ctx context.Context, st *models.FurnaceState, now time.Time) *runTracker {
+	if s.run != nil && s.run.run.RunID == st.RunID {
+		return s.run
+	}
+	var run models.Run
+	if s.runRepo != nil {
+		var err error
+		if run, err = s.runRepo.Get(ctx, st.RunID); err != nil {
+			return nil
+		}
+	}
+	t := &runTracker{run: run}
+	if run.RunID == "" {
+		t.run = models.Run{RunID: st.RunID, TargetTempC: st.TargetTempC, StartedAt: now.UTC()}
+		s.saveRun(ctx, t, now)
+	} else {
+		t.m2 = run.SoakStdDevC * run.SoakStdDevC * run.SoakSeconds
+	}
+	s.run = t
+	return t
+}
+
+func (s *SimulatorService) saveRun(ctx context.Context, t *runTracker, now time.Time) {
+	t.run.UpdatedAt = now.UTC()
+	if s.runRepo != nil {
+		_ = s.runRepo.Save(ctx, t.run)
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"errors"
+	"strings"
+
+	"controlling_furnace/internal/repository"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrRunNotFound is returned when no record exists for a run ID.
+var ErrRunNotFound = errors.New("run not found")
+
+type RunService struct {
+	runRepo repository.RunRepo
+}
+
+func NewRunService(runRepo repository.RunRepo) *RunService {
+	return &RunService{runRepo: runRepo}
+}
+
+// GetRun returns the record of a heat cycle.
+func (s *RunService) GetRun(ctx context.Context, runID string) (_ models.Run, err error) {
+	ctx, span := startSpan(ctx, "Runs.GetRun", attribute.String("run.id", runID))
+	defer func() { endSpan(span, err) }()
+
+	runID = strings.TrimSpace(runID)
+	if runID == "" {
+		return models.Run{}, ErrRunNotFound
+	}
+	run, err := s.runRepo.Get(ctx, runID)
+	if err != nil {
+		return models.Run{}, err
+	}
+	if run.RunID == "" {
+		return models.Run{}, ErrRunNotFound
+	}
+	return run, nil
+}
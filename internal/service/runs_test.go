@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestGetRun(t *testing.T) {
+	svc := NewRunService(&runRepoStub{runs: map[string]models.Run{"run-1": {RunID: "run-1"}}})
+
+	if _, err := svc.GetRun(context.Background(), "missing"); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("expected ErrRunNotFound, got %v", err)
+	}
+	if _, err := svc.GetRun(context.Background(), "  "); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("expected ErrRunNotFound for blank id, got %v", err)
+	}
+	run, err := svc.GetRun(context.Background(), " run-1 ")
+	if err != nil || run.RunID != "run-1" {
+		t.Fatalf("GetRun = %+v, %v", run, err)
+	}
+}
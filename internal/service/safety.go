@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// AlarmOverheat is reported in ErrorCodes while the chamber is above
+// PhysicsConfig.MaxSafeC.
+const AlarmOverheat = "OVERHEAT"
+
+// AlarmRateOfRise is reported in ErrorCodes while the measured temperature
+// climbs faster than SafetyConfig.MaxRiseCPerMin.
+const AlarmRateOfRise = "RATE_OF_RISE"
+
+// SafetyConfig holds protective limits enforced by the simulator.
+type SafetyConfig struct {
+	MaxRiseCPerMin   float64 // rate-of-rise alarm threshold, °C/min; 0 disables
+	TripOnRateOfRise bool    // shut the furnace down when the alarm is raised
+}
+
+// checkRateOfRise compares the measured rate of rise over the last tick with
+// the configured limit, raising or clearing RATE_OF_RISE and tripping the
+// furnace if configured. Returns true if the state changed.
+func (s *SimulatorService) checkRateOfRise(ctx context.Context, st *models.FurnaceState, prevMeasuredC, elapsed float64, now time.Time) bool {
+	limit := s.cfg.Safety.MaxRiseCPerMin
+	if limit <= 0 || elapsed <= 0 {
+		return false
+	}
+	rate := (st.MeasuredTempC - prevMeasuredC) / elapsed * 60
+	active := hasString(st.ErrorCodes, AlarmRateOfRise)
+
+	switch {
+	case rate > limit && !active:
+		st.ErrorCodes = append(st.ErrorCodes, AlarmRateOfRise)
+		s.emit(models.FurnaceEvent{
+			EventID:     s.newID(),
+			OccurredAt:  now.UTC(),
+			Type:        "ERROR",
+			Description: "Rate of rise exceeded",
+			Metadata: withRunID(map[string]any{
+				"alarm":           AlarmRateOfRise,
+				"rate_c_per_min":  math.Round(rate*10) / 10,
+				"limit_c_per_min": limit,
+				"measured_temp_c": st.MeasuredTempC,
+				"mode":            st.Mode,
+			}, st.RunID),
+		})
+		if s.cfg.Safety.TripOnRateOfRise && st.IsRunning {
+			s.trip(ctx, st, AlarmRateOfRise, now)
+		}
+		return true
+	case rate <= limit && active:
+		st.ErrorCodes = removeString(st.ErrorCodes, AlarmRateOfRise)
+		s.emit(models.FurnaceEvent{
+			EventID:     s.newID(),
+			OccurredAt:  now.UTC(),
+			Type:        "ALARM_CLEARED",
+			Description: "Rate of rise back within limit",
+			Metadata: withRunID(map[string]any{
+				"alarm":          AlarmRateOfRise,
+				"rate_c_per_min": math.Round(rate*10) / 10,
+			}, st.RunID),
+		})
+		return true
+	}
+	return false
+}
+
+// trip performs an automatic safety shutdown: heating stops, the furnace
+// goes to STANDBY and the active run ends, as with an operator Stop.
+func (s *SimulatorService) trip(ctx context.Context, st *models.FurnaceState, reason string, now time.Time) {
+	runID := st.RunID
+	st.IsRunning = false
+	st.Mode = ModeStandby
+	st.TargetTempC = 0
+	st.RemainingSeconds = 0
+	st.RunID = ""
+	s.emit(models.FurnaceEvent{
+		EventID:     s.newID(),
+		OccurredAt:  now.UTC(),
+		Type:        "SAFETY_TRIP",
+		Description: "Automatic safety shutdown",
+		Metadata:    withRunID(map[string]any{"reason": reason}, runID),
+	})
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestCheckRateOfRise_RaisesAndClears(t *testing.T) {
+	events := &simEventRepoStub{}
+	svc := NewSimulatorService(&simStateRepoStub{}, events)
+	now := time.Now()
+
+	// 10 °C in 1 s is 600 °C/min, above the default 300 limit
+	st := models.FurnaceState{Mode: ModeHeat, IsRunning: true, RunID: "run-1", MeasuredTempC: 510}
+	if !svc.checkRateOfRise(context.Background(), &st, 500, 1, now) {
+		t.Fatalf("expected alarm to change state")
+	}
+	if !hasString(st.ErrorCodes, AlarmRateOfRise) || !st.IsRunning {
+		t.Fatalf("expected alarm without trip, got %+v", st)
+	}
+	// still rising fast: no duplicate event
+	st.MeasuredTempC = 520
+	_ = svc.checkRateOfRise(context.Background(), &st, 510, 1, now)
+	svc.flushEvents(context.Background())
+	if len(events.appends) != 1 || events.appends[0].Type != "ERROR" {
+		t.Fatalf("expected one ERROR event, got %+v", events.appends)
+	}
+
+	// nominal ramp clears it
+	st.MeasuredTempC = 523
+	_ = svc.checkRateOfRise(context.Background(), &st, 520, 1, now)
+	svc.flushEvents(context.Background())
+	if hasString(st.ErrorCodes, AlarmRateOfRise) {
+		t.Fatalf("expected alarm cleared, got %v", st.ErrorCodes)
+	}
+	if last := events.appends[len(events.appends)-1]; last.Type != "ALARM_CLEARED" {
+		t.Fatalf("expected ALARM_CLEARED, got %s", last.Type)
+	}
+}
+
+func TestCheckRateOfRise_TripsWhenConfigured(t *testing.T) {
+	events := &simEventRepoStub{}
+	cfg := DefaultSimConfig()
+	cfg.Safety = SafetyConfig{MaxRiseCPerMin: 120, TripOnRateOfRise: true}
+	svc := NewSimulatorServiceWithConfig(&simStateRepoStub{}, events, nil, nil, nil, cfg)
+
+	// 3 °C/s nominal ramp is 180 °C/min
+	st := models.FurnaceState{Mode: ModeHeat, IsRunning: true, RunID: "run-1", TargetTempC: 800, RemainingSeconds: 60, MeasuredTempC: 503}
+	_ = svc.checkRateOfRise(context.Background(), &st, 500, 1, time.Now())
+	svc.flushEvents(context.Background())
+
+	if st.IsRunning || st.Mode != ModeStandby || st.RunID != "" || st.TargetTempC != 0 {
+		t.Fatalf("expected safety shutdown, got %+v", st)
+	}
+	last := events.appends[len(events.appends)-1]
+	meta, _ := last.Metadata.(map[string]any)
+	if last.Type != "SAFETY_TRIP" || meta["run_id"] != "run-1" || meta["reason"] != AlarmRateOfRise {
+		t.Fatalf("unexpected trip event: %+v", last)
+	}
+}
+
+func TestCheckRateOfRise_Disabled(t *testing.T) {
+	cfg := DefaultSimConfig()
+	cfg.Safety.MaxRiseCPerMin = 0
+	svc := NewSimulatorServiceWithConfig(&simStateRepoStub{}, &simEventRepoStub{}, nil, nil, nil, cfg)
+
+	st := models.FurnaceState{MeasuredTempC: 1000}
+	if svc.checkRateOfRise(context.Background(), &st, 0, 1, time.Now()) {
+		t.Fatalf("did not expect alarm when disabled")
+	}
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"math"
+	"math/rand"
+)
+
+// sensorModel turns the simulated true temperature into a measured reading
+// by adding a slowly drifting offset and Gaussian noise.
+type sensorModel struct {
+	cfg    SensorConfig
+	rng    *rand.Rand
+	driftC float64 // accumulated offset, °C
+}
+
+func newSensorModel(cfg SensorConfig) *sensorModel {
+	return &sensorModel{cfg: cfg, rng: rand.New(rand.NewSource(cfg.Seed))}
+}
+
+// read advances the drift by elapsed seconds and returns a measurement of trueC.
+func (m *sensorModel) read(trueC, elapsed float64) float64 {
+	if m.cfg.DriftCPerHour != 0 && elapsed > 0 {
+		m.driftC += m.cfg.DriftCPerHour * elapsed / 3600
+		if limit := m.cfg.MaxDriftC; limit > 0 {
+			m.driftC = math.Max(-limit, math.Min(limit, m.driftC))
+		}
+	}
+	v := trueC + m.driftC
+	if m.cfg.NoiseStdDevC > 0 {
+		v += m.rng.NormFloat64() * m.cfg.NoiseStdDevC
+	}
+	// Real controllers report with finite resolution.
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,45 @@
+package service
+
+import "testing"
+
+func TestSensorModel_PerfectSensorReportsTrueTemp(t *testing.T) {
+	m := newSensorModel(SensorConfig{})
+	for _, v := range []float64{25, 512.34, 1000} {
+		if got := m.read(v, 1); got != v {
+			t.Fatalf("read(%v) = %v, want exact value", v, got)
+		}
+	}
+}
+
+func TestSensorModel_SeededNoiseIsReproducible(t *testing.T) {
+	cfg := SensorConfig{NoiseStdDevC: 2, Seed: 42}
+	a, b := newSensorModel(cfg), newSensorModel(cfg)
+
+	differs := false
+	for i := 0; i < 20; i++ {
+		ra, rb := a.read(500, 1), b.read(500, 1)
+		if ra != rb {
+			t.Fatalf("reading %d differs between identically seeded sensors: %v vs %v", i, ra, rb)
+		}
+		if ra != 500 {
+			differs = true
+		}
+		if ra < 480 || ra > 520 {
+			t.Fatalf("reading %v is implausibly far from truth for sigma=2", ra)
+		}
+	}
+	if !differs {
+		t.Fatalf("expected noisy readings to deviate from the true value")
+	}
+}
+
+func TestSensorModel_DriftAccumulatesAndClamps(t *testing.T) {
+	m := newSensorModel(SensorConfig{DriftCPerHour: 2, MaxDriftC: 3})
+
+	if got := m.read(100, 3600); got != 102 {
+		t.Fatalf("after 1h got %v, want 102", got)
+	}
+	if got := m.read(100, 3*3600); got != 103 {
+		t.Fatalf("expected drift clamped to +3, got %v", got)
+	}
+}
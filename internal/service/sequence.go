@@ -0,0 +1,452 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Limits for sequences.
+const (
+	MaxSequenceSteps = 50
+	// finished sequences kept in memory; older ones are read back from the
+	// repository, if there is one
+	sequenceHistory = 20
+	// how often the coordinator checks the running step between states,
+	// so holds end even while nothing in the state changes
+	sequencePoll = time.Second
+	// how often the progress of the running step is saved; a crash loses
+	// at most this much of a hold
+	sequenceSave = 10 * time.Second
+)
+
+var (
+	// ErrInvalidSequence is returned for steps that cannot be run.
+	ErrInvalidSequence = errors.New("invalid sequence")
+	// ErrSequenceRunning is returned when starting while a sequence runs.
+	ErrSequenceRunning = errors.New("a sequence is already running")
+	// ErrSequenceNotFound is returned for unknown or long finished sequences.
+	ErrSequenceNotFound = errors.New("sequence not found")
+)
+
+// SequenceConfig sets how a sequence left running by a previous process
+// is picked up.
+type SequenceConfig struct {
+	// ResumeWithin aborts, at start, a sequence whose process was down for
+	// longer than this, as the chamber will have cooled far from where the
+	// step left it; 0 resumes it however long the process was down.
+	ResumeWithin time.Duration `mapstructure:"resume_within"`
+}
+
+// Validate rejects a negative ResumeWithin.
+func (c SequenceConfig) Validate() error {
+	if c.ResumeWithin < 0 {
+		return errors.New("sequences.resume_within must be >= 0")
+	}
+	return nil
+}
+
+// sequenceRun is the running sequence with what the coordinator needs to
+// tell when its step is done.
+type sequenceRun struct {
+	seq     models.Sequence
+	since   time.Time // UpdatedAt of the state the step was entered in
+	runID   string    // run a HEAT step started
+	held    float64   // simulated seconds a COOL or STANDBY step has held
+	checked time.Time // when held was last advanced
+}
+
+// SequenceService runs one sequence of mode steps at a time, setting each
+// step with the same SetMode as an operator would.
+//
+// The running sequence is saved with its step when the step is entered,
+// every sequenceSave while it runs, when it ends and when Run stops. After
+// a restart it carries on with the step it was on; steps are never
+// skipped. Time the process was down does not count toward the step, just
+// as the simulator does not count it toward a soak: a hold continues from
+// where it was saved, and a COOL target the chamber drifted down to in the
+// meantime is met at the first check. See SequenceConfig.ResumeWithin for
+// outages too long to carry on after.
+type SequenceService struct {
+	furnace *FurnaceService
+	state   *StateManager
+	events  repository.EventRepo
+	repo    repository.SequenceRepo // optional; sequences are kept in memory only when nil
+	cfg     SequenceConfig
+	bus     *StateBroker // optional; steps are only checked every sequencePoll when nil
+	speed   SimClock     // optional; holds count real time when nil
+	now     func() time.Time
+	newID   func() string
+
+	mu      sync.Mutex
+	active  *sequenceRun
+	done    []models.Sequence // most recent last
+	resumed bool              // looked for a sequence left running by a previous process
+	saved   time.Time         // when the running sequence was last saved
+}
+
+func NewSequenceService(furnace *FurnaceService, events repository.EventRepo, bus *StateBroker) *SequenceService {
+	return &SequenceService{furnace: furnace, state: furnace.state, events: events, bus: bus, now: time.Now, newID: uuid.NewString}
+}
+
+// validateSteps checks every step before the first is set, so a sequence
+// does not fail halfway on a typo.
+func (s *SequenceService) validateSteps(steps []models.SequenceStep) error {
+	if len(steps) == 0 || len(steps) > MaxSequenceSteps {
+		return fmt.Errorf("%w: 1..%d steps required", ErrInvalidSequence, MaxSequenceSteps)
+	}
+	phys := s.furnace.physics()
+	for i, st := range steps {
+		var msg string
+		switch {
+		case st.DurationSec < 0 || st.TargetTempC < 0:
+			msg = "target_temp_c and duration_sec must not be negative"
+		case st.Mode == ModeHeat:
+			if b := heatParamsBlocker(ModeParams{Mode: ModeHeat, TargetTempC: st.TargetTempC, DurationSec: st.DurationSec}, phys); b != nil {
+				msg = b.Message
+			}
+		case st.Mode == ModeCool:
+			if st.TargetTempC == 0 && st.DurationSec == 0 {
+				msg = "COOL needs target_temp_c, duration_sec or both"
+			}
+		case st.Mode == ModeStandby:
+			if st.TargetTempC != 0 || st.DurationSec == 0 {
+				msg = "STANDBY needs duration_sec and takes no target_temp_c"
+			}
+		default:
+			msg = errInvalidMode.Error()
+		}
+		if msg != "" {
+			return fmt.Errorf("%w: step %d: %s", ErrInvalidSequence, i+1, msg)
+		}
+	}
+	return nil
+}
+
+// StartSequence sets the first step and hands the rest to Run. Errors of
+// SetMode, such as a stopped furnace, are returned as they are.
+func (s *SequenceService) StartSequence(ctx context.Context, steps []models.SequenceStep, userID int) (_ models.Sequence, err error) {
+	ctx, span := startSpan(ctx, "Sequences.StartSequence")
+	defer func() { endSpan(span, err) }()
+
+	if err := s.validateSteps(steps); err != nil {
+		return models.Sequence{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.resume(ctx); err != nil {
+		return models.Sequence{}, err
+	}
+	if s.active != nil {
+		return models.Sequence{}, ErrSequenceRunning
+	}
+	run := &sequenceRun{seq: models.Sequence{
+		ID:        s.newID(),
+		Steps:     append([]models.SequenceStep(nil), steps...),
+		Status:    models.SequenceRunning,
+		CreatedBy: userID,
+		CreatedAt: s.now().UTC(),
+	}}
+	if err := s.enterStep(ctx, run, 1); err != nil {
+		return models.Sequence{}, err
+	}
+	s.active = run
+	s.save(ctx)
+	s.log(ctx, models.FurnaceEvent{
+		EventID:     s.newID(),
+		OccurredAt:  run.seq.CreatedAt,
+		Type:        "SEQUENCE_STARTED",
+		Description: fmt.Sprintf("Sequence of %d steps started", len(steps)),
+		Metadata:    sequenceMeta(run.seq),
+	})
+	return cloneSequence(run.seq), nil
+}
+
+// GetSequence returns the running sequence or a finished one.
+func (s *SequenceService) GetSequence(ctx context.Context, id string) (models.Sequence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.resume(ctx); err != nil {
+		return models.Sequence{}, err
+	}
+	if s.active != nil && s.active.seq.ID == id {
+		return cloneSequence(s.active.seq), nil
+	}
+	for _, seq := range s.done {
+		if seq.ID == id {
+			return cloneSequence(seq), nil
+		}
+	}
+	if s.repo != nil {
+		rec, err := s.repo.Get(ctx, id)
+		if err != nil {
+			return models.Sequence{}, err
+		}
+		if rec.ID != "" {
+			return rec.Sequence, nil
+		}
+	}
+	return models.Sequence{}, ErrSequenceNotFound
+}
+
+// resume picks up the sequence a previous process left running, once. It
+// is aborted instead if the process was down for longer than
+// cfg.ResumeWithin. s.mu must be held.
+func (s *SequenceService) resume(ctx context.Context) error {
+	if s.resumed || s.repo == nil {
+		return nil
+	}
+	rec, err := s.repo.Running(ctx)
+	if err != nil {
+		return err
+	}
+	s.resumed = true
+	if rec.ID == "" {
+		s.clearProgress(ctx, "")
+		return nil
+	}
+	now := s.now()
+	s.active = &sequenceRun{seq: rec.Sequence, since: rec.StepSince, runID: rec.StepRunID, held: rec.HeldS, checked: now}
+	s.saved = rec.SavedAt
+	down := max(now.Sub(rec.SavedAt), 0)
+	if s.cfg.ResumeWithin > 0 && down > s.cfg.ResumeWithin {
+		s.finish(ctx, models.SequenceAborted, fmt.Sprintf("not resumed after %s down, longer than resume_within", down.Round(time.Second)))
+		return nil
+	}
+	// the state keeps no sequence across a restart
+	_, _ = s.state.Update(ctx, func(st *models.FurnaceState) error {
+		st.Sequence = &models.SequenceProgress{ID: rec.ID, Step: rec.Step, Steps: len(rec.Steps)}
+		st.UpdatedAt = now.UTC()
+		return nil
+	})
+	meta := sequenceMeta(rec.Sequence)
+	meta["downtime_s"] = down.Seconds()
+	s.log(ctx, models.FurnaceEvent{
+		EventID:     s.newID(),
+		OccurredAt:  now.UTC(),
+		Type:        "SEQUENCE_RESUMED",
+		Description: fmt.Sprintf("Sequence resumed at step %d of %d after a restart", rec.Step, len(rec.Steps)),
+		Metadata:    meta,
+	})
+	return nil
+}
+
+// save stores the running sequence with the progress of its step. A
+// failed save is retried with the next one. s.mu must be held.
+func (s *SequenceService) save(ctx context.Context) {
+	run := s.active
+	if s.repo == nil || run == nil {
+		return
+	}
+	now := s.now()
+	rec := repository.SequenceRecord{Sequence: run.seq, StepSince: run.since, StepRunID: run.runID, HeldS: run.held, SavedAt: now.UTC()}
+	if err := s.repo.Save(ctx, rec); err == nil {
+		s.saved = now
+	}
+}
+
+// Run resumes a sequence left running by a previous process, then checks
+// the running step against every state the simulator publishes and every
+// sequencePoll, moving on to the next step once it is done.
+func (s *SequenceService) Run(ctx context.Context) {
+	s.mu.Lock()
+	_ = s.resume(ctx) // tried again with the first check if it fails
+	s.mu.Unlock()
+
+	var updates <-chan models.FurnaceState
+	if s.bus != nil {
+		var cancel func()
+		updates, cancel = s.bus.Subscribe(16)
+		defer cancel()
+	}
+	ticker := time.NewTicker(sequencePoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// keep the progress of the running step for the next process
+			s.mu.Lock()
+			s.save(context.WithoutCancel(ctx))
+			s.mu.Unlock()
+			return
+		case st := <-updates:
+			s.check(ctx, st)
+		case <-ticker.C:
+			if st, err := s.state.Load(ctx); err == nil {
+				s.check(ctx, st)
+			}
+		}
+	}
+}
+
+// check moves the running sequence on if st completes its step, or aborts
+// it if the furnace stopped or another command took over.
+func (s *SequenceService) check(ctx context.Context, st models.FurnaceState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.resume(ctx) != nil {
+		return // tried again with the next state
+	}
+	run := s.active
+	if run == nil || st.UpdatedAt.Before(run.since) {
+		return // published before the step was entered
+	}
+	step := run.seq.Steps[run.seq.Step-1]
+	now := s.now()
+	run.held += now.Sub(run.checked).Seconds() * s.timeScale()
+	run.checked = now
+	defer func() {
+		if s.active == run && now.Sub(s.saved) >= sequenceSave {
+			s.save(ctx)
+		}
+	}()
+
+	switch {
+	case !st.IsRunning:
+		s.finish(ctx, models.SequenceAborted, "furnace stopped")
+	case step.Mode == ModeHeat:
+		switch {
+		case st.RunID != run.runID:
+			s.finish(ctx, models.SequenceAborted, "superseded by another command")
+		case st.Mode == ModeHeat:
+			// ramping or soaking
+		case st.Mode == ModeCool && st.RemainingSeconds == 0:
+			// the soak elapsed, or an operator cut it short with COOL
+			s.next(ctx)
+		default:
+			s.finish(ctx, models.SequenceAborted, "superseded by another command")
+		}
+	case st.Mode != step.Mode:
+		s.finish(ctx, models.SequenceAborted, "superseded by another command")
+	case step.Mode == ModeCool && step.TargetTempC > 0 && st.CurrentTempC <= step.TargetTempC,
+		step.DurationSec > 0 && run.held >= float64(step.DurationSec):
+		s.next(ctx)
+	}
+}
+
+// next enters the step after the current one, or completes the sequence
+// after its last. s.mu must be held.
+func (s *SequenceService) next(ctx context.Context) {
+	run := s.active
+	if run.seq.Step == len(run.seq.Steps) {
+		s.finish(ctx, models.SequenceCompleted, "")
+		return
+	}
+	if err := s.enterStep(ctx, run, run.seq.Step+1); err != nil {
+		s.finish(ctx, models.SequenceAborted, fmt.Sprintf("step %d: %v", run.seq.Step+1, err))
+		return
+	}
+	s.save(ctx)
+}
+
+// enterStep sets step i (1-based) of run and shows it in the state.
+func (s *SequenceService) enterStep(ctx context.Context, run *sequenceRun, i int) error {
+	step := run.seq.Steps[i-1]
+	p := ModeParams{Mode: step.Mode}
+	if step.Mode == ModeHeat {
+		p.TargetTempC, p.DurationSec = step.TargetTempC, step.DurationSec
+	}
+	if err := s.furnace.SetMode(ctx, p); err != nil {
+		return err
+	}
+	now := s.now()
+	st, err := s.state.Update(ctx, func(st *models.FurnaceState) error {
+		st.Sequence = &models.SequenceProgress{ID: run.seq.ID, Step: i, Steps: len(run.seq.Steps)}
+		st.UpdatedAt = now.UTC()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	run.seq.Step = i
+	run.since, run.runID, run.held, run.checked = st.UpdatedAt, st.RunID, 0, now
+	return nil
+}
+
+// finish ends the running sequence with status and drops it from the
+// state. s.mu must be held.
+func (s *SequenceService) finish(ctx context.Context, status, reason string) {
+	run := s.active
+	now := s.now().UTC()
+	run.seq.Status, run.seq.Reason, run.seq.FinishedAt = status, reason, &now
+	s.save(ctx)
+	s.clearProgress(ctx, run.seq.ID)
+	s.active = nil
+	s.done = append(s.done, run.seq)
+	if len(s.done) > sequenceHistory {
+		s.done = s.done[len(s.done)-sequenceHistory:]
+	}
+
+	ev := models.FurnaceEvent{
+		EventID:     s.newID(),
+		OccurredAt:  now,
+		Type:        "SEQUENCE_COMPLETED",
+		Description: "Sequence completed",
+		Metadata:    sequenceMeta(run.seq),
+	}
+	if status == models.SequenceAborted {
+		ev = models.FurnaceEvent{
+			EventID:     ev.EventID,
+			OccurredAt:  now,
+			Type:        "SEQUENCE_ABORTED",
+			Description: "Sequence aborted: " + reason,
+			Metadata:    sequenceMeta(run.seq),
+		}
+	}
+	s.log(ctx, ev)
+}
+
+// clearProgress drops the sequence with the given ID from the state; an
+// empty id drops any, such as one shown by a process from before
+// sequences were saved.
+func (s *SequenceService) clearProgress(ctx context.Context, id string) {
+	_, _ = s.state.Update(ctx, func(st *models.FurnaceState) error {
+		if st.Sequence == nil || (id != "" && st.Sequence.ID != id) {
+			return errStateUnchanged
+		}
+		st.Sequence = nil
+		st.UpdatedAt = s.now().UTC()
+		return nil
+	})
+}
+
+func (s *SequenceService) log(ctx context.Context, ev models.FurnaceEvent) {
+	if s.events != nil {
+		_ = s.events.Append(ctx, ev)
+	}
+}
+
+// timeScale is the simulator's time acceleration; holds count simulated
+// seconds.
+func (s *SequenceService) timeScale() float64 {
+	if s.speed != nil {
+		if sp := s.speed.Speed(); sp.TimeScale > 0 {
+			return sp.TimeScale
+		}
+	}
+	return 1
+}
+
+func sequenceMeta(seq models.Sequence) map[string]any {
+	meta := map[string]any{"sequence_id": seq.ID, "step": seq.Step, "steps": len(seq.Steps)}
+	if seq.Reason != "" {
+		meta["reason"] = seq.Reason
+	}
+	return meta
+}
+
+func cloneSequence(seq models.Sequence) models.Sequence {
+	seq.Steps = append([]models.SequenceStep(nil), seq.Steps...)
+	if seq.FinishedAt != nil {
+		at := *seq.FinishedAt
+		seq.FinishedAt = &at
+	}
+	return seq
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+// sequenceRig runs a service on the in-memory repositories with a clock
+// that advances together with the simulator.
+type sequenceRig struct {
+	t     *testing.T
+	ctx   context.Context
+	repos *repository.Repository
+	svc   *Service
+	seqs  *SequenceService
+	now   time.Time
+}
+
+func newSequenceRig(t *testing.T) *sequenceRig {
+	r := &sequenceRig{t: t, ctx: context.Background(), repos: repository.NewInMemory(), now: time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)}
+	r.boot(SequenceConfig{})
+	if err := r.svc.Furnace.Start(r.ctx); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// boot builds the services on the rig's repositories, as a process does
+// on startup.
+func (r *sequenceRig) boot(seqCfg SequenceConfig) {
+	cfg := DefaultConfig()
+	cfg.Clock = func() time.Time { return r.now }
+	cfg.Sequences = seqCfg
+	r.svc = NewServiceWithConfig(r.repos, cfg)
+	r.seqs = r.svc.Sequences.(*SequenceService)
+}
+
+// restart stops the sequence coordinator, leaves the process down for
+// down and boots a new one on the same repositories.
+func (r *sequenceRig) restart(down time.Duration, seqCfg SequenceConfig) {
+	ctx, cancel := context.WithCancel(r.ctx)
+	cancel()
+	r.seqs.Run(ctx)
+	r.now = r.now.Add(down)
+	r.boot(seqCfg)
+}
+
+// tick advances a second of simulated time and lets the coordinator see
+// the resulting state.
+func (r *sequenceRig) tick() models.FurnaceState {
+	r.t.Helper()
+	r.now = r.now.Add(time.Second)
+	if err := r.svc.Simulator.Step(r.ctx, time.Second); err != nil {
+		r.t.Fatal(err)
+	}
+	st, err := r.seqs.state.Load(r.ctx)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	r.seqs.check(r.ctx, st)
+	st, _ = r.seqs.state.Load(r.ctx)
+	return st
+}
+
+func (r *sequenceRig) eventTypes() []string {
+	evs, _ := r.repos.EventRepo.Query(r.ctx, repository.EventQuery{})
+	var types []string
+	for _, ev := range evs {
+		types = append(types, ev.Type)
+	}
+	return types
+}
+
+func TestSequence_RunsStepsBackToBack(t *testing.T) {
+	r := newSequenceRig(t)
+	seq, err := r.seqs.StartSequence(r.ctx, []models.SequenceStep{
+		{Mode: ModeHeat, TargetTempC: 300, DurationSec: 30},
+		{Mode: ModeCool, TargetTempC: 200},
+		{Mode: ModeStandby, DurationSec: 20},
+	}, 7)
+	if err != nil || seq.Status != models.SequenceRunning || seq.Step != 1 || seq.CreatedBy != 7 {
+		t.Fatalf("start: %+v, %v", seq, err)
+	}
+	if _, err := r.seqs.StartSequence(r.ctx, []models.SequenceStep{{Mode: ModeStandby, DurationSec: 5}}, 7); !errors.Is(err, ErrSequenceRunning) {
+		t.Fatalf("second sequence: %v", err)
+	}
+
+	var modes []string
+	var st models.FurnaceState
+	for i := 0; i < 1000; i++ {
+		st = r.tick()
+		if st.Sequence == nil {
+			break
+		}
+		if st.Sequence.ID != seq.ID || st.Sequence.Steps != 3 {
+			t.Fatalf("progress %+v", st.Sequence)
+		}
+		if len(modes) < st.Sequence.Step {
+			modes = append(modes, st.Mode)
+			if st.Sequence.Step == 3 && st.CurrentTempC > 200 {
+				t.Fatalf("left COOL at %.1f °C", st.CurrentTempC)
+			}
+		}
+	}
+	if len(modes) != 3 || modes[0] != ModeHeat || modes[1] != ModeCool || modes[2] != ModeStandby {
+		t.Fatalf("modes %v", modes)
+	}
+	got, err := r.seqs.GetSequence(r.ctx, seq.ID)
+	if err != nil || got.Status != models.SequenceCompleted || got.Step != 3 || got.FinishedAt == nil {
+		t.Fatalf("finished: %+v, %v", got, err)
+	}
+	if st.Mode != ModeStandby || !st.IsRunning {
+		t.Fatalf("left the furnace in %s, running %v", st.Mode, st.IsRunning)
+	}
+	types := r.eventTypes()
+	if types[len(types)-1] != "SEQUENCE_COMPLETED" {
+		t.Fatalf("events %v", types)
+	}
+}
+
+func TestSequence_AbortsWhenStoppedOrOverridden(t *testing.T) {
+	r := newSequenceRig(t)
+	seq, err := r.seqs.StartSequence(r.ctx, []models.SequenceStep{{Mode: ModeStandby, DurationSec: 600}, {Mode: ModeCool, DurationSec: 60}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.tick()
+	if err := r.svc.Furnace.SetMode(r.ctx, ModeParams{Mode: ModeCool}); err != nil {
+		t.Fatal(err)
+	}
+	if st := r.tick(); st.Sequence != nil {
+		t.Fatalf("overridden sequence still shown: %+v", st.Sequence)
+	}
+	if got, _ := r.seqs.GetSequence(r.ctx, seq.ID); got.Status != models.SequenceAborted || got.Reason != "superseded by another command" {
+		t.Fatalf("overridden: %+v", got)
+	}
+
+	seq, err = r.seqs.StartSequence(r.ctx, []models.SequenceStep{{Mode: ModeHeat, TargetTempC: 500, DurationSec: 60}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.svc.Furnace.Stop(r.ctx); err != nil {
+		t.Fatal(err)
+	}
+	r.tick()
+	if got, _ := r.seqs.GetSequence(r.ctx, seq.ID); got.Status != models.SequenceAborted || got.Reason != "furnace stopped" {
+		t.Fatalf("stopped: %+v", got)
+	}
+	types := r.eventTypes()
+	if types[len(types)-1] != "SEQUENCE_ABORTED" {
+		t.Fatalf("events %v", types)
+	}
+
+	// a stopped furnace takes no first step
+	if _, err := r.seqs.StartSequence(r.ctx, []models.SequenceStep{{Mode: ModeCool, DurationSec: 60}}, 1); err == nil {
+		t.Fatal("sequence started on a stopped furnace")
+	}
+	if _, err := r.seqs.GetSequence(r.ctx, "nope"); !errors.Is(err, ErrSequenceNotFound) {
+		t.Fatalf("unknown: %v", err)
+	}
+}
+
+func TestSequence_ResumesAfterARestartWithoutCountingTheDowntime(t *testing.T) {
+	r := newSequenceRig(t)
+	seq, err := r.seqs.StartSequence(r.ctx, []models.SequenceStep{{Mode: ModeStandby, DurationSec: 30}, {Mode: ModeCool, DurationSec: 20}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 12; i++ {
+		r.tick()
+	}
+	r.restart(time.Hour, SequenceConfig{})
+
+	st := r.tick()
+	if st.Sequence == nil || st.Sequence.ID != seq.ID || st.Sequence.Step != 1 {
+		t.Fatalf("not resumed: %+v", st.Sequence)
+	}
+	if held := r.seqs.active.held; held != 12 {
+		t.Fatalf("held %.1f s after the restart, want the 12 s from before it", held)
+	}
+	evs, _ := r.repos.EventRepo.Query(r.ctx, repository.EventQuery{Type: "SEQUENCE_RESUMED"})
+	if len(evs) != 1 {
+		t.Fatalf("resume events %+v", evs)
+	}
+	if meta, _ := evs[0].Metadata.(map[string]any); meta["downtime_s"] != float64(3601) {
+		t.Fatalf("resume metadata %+v", evs[0].Metadata)
+	}
+
+	ticks := 1
+	for ; ticks < 1000 && st.Sequence != nil; ticks++ {
+		st = r.tick()
+	}
+	if ticks < 18+20 {
+		t.Fatalf("finished %d ticks after the restart; the downtime counted towards a hold", ticks)
+	}
+	if got, err := r.seqs.GetSequence(r.ctx, seq.ID); err != nil || got.Status != models.SequenceCompleted || got.Step != 2 {
+		t.Fatalf("finished: %+v, %v", got, err)
+	}
+}
+
+func TestSequence_AbortsWhenDownLongerThanResumeWithin(t *testing.T) {
+	r := newSequenceRig(t)
+	seq, err := r.seqs.StartSequence(r.ctx, []models.SequenceStep{{Mode: ModeStandby, DurationSec: 600}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.tick()
+	r.restart(time.Hour, SequenceConfig{ResumeWithin: 10 * time.Minute})
+
+	if st := r.tick(); st.Sequence != nil {
+		t.Fatalf("sequence still shown: %+v", st.Sequence)
+	}
+	got, err := r.seqs.GetSequence(r.ctx, seq.ID)
+	if err != nil || got.Status != models.SequenceAborted || got.Reason != "not resumed after 1h0m1s down, longer than resume_within" {
+		t.Fatalf("aborted: %+v, %v", got, err)
+	}
+
+	// a later process finds it among the saved sequences
+	r.restart(time.Minute, SequenceConfig{})
+	if got, err := r.seqs.GetSequence(r.ctx, seq.ID); err != nil || got.Status != models.SequenceAborted {
+		t.Fatalf("after another restart: %+v, %v", got, err)
+	}
+	if _, err := r.seqs.StartSequence(r.ctx, []models.SequenceStep{{Mode: ModeCool, DurationSec: 5}}, 1); err != nil {
+		t.Fatalf("a new sequence after the aborted one: %v", err)
+	}
+}
+
+func TestSequence_ValidatesEveryStep(t *testing.T) {
+	r := newSequenceRig(t)
+	for _, steps := range [][]models.SequenceStep{
+		nil,
+		{{Mode: ModeHeat, TargetTempC: 300}},
+		{{Mode: ModeCool}},
+		{{Mode: ModeStandby, TargetTempC: 100, DurationSec: 10}},
+		{{Mode: ModeCool, DurationSec: -1}},
+		{{Mode: ModeCool, DurationSec: 60}, {Mode: "BAKE", DurationSec: 60}},
+	} {
+		if _, err := r.seqs.StartSequence(r.ctx, steps, 1); !errors.Is(err, ErrInvalidSequence) {
+			t.Fatalf("%+v: err %v", steps, err)
+		}
+	}
+	if st, _ := r.seqs.state.Load(r.ctx); st.Mode != ModeStandby || st.Sequence != nil {
+		t.Fatalf("an invalid sequence changed the state: %+v", st)
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"controlling_furnace/internal/models"
+	"io"
 	"time"
 
 	// uses your FurnaceState / FurnaceEvent structs
@@ -10,9 +11,19 @@ import (
 )
 
 type Authorization interface {
-	SignUp(username, password string) (int, error)
-	GenerateToken(username, password string) (string, error)
+	SignUp(ctx context.Context, username, password string) (int, error)
+	GenerateToken(ctx context.Context, username, password string) (string, error)
 	ParseToken(accessToken string) (int, error)
+	ParseClaims(accessToken string) (*Claims, error)
+	SetRole(ctx context.Context, userID int, role string) error
+}
+
+// Setup runs the first-run configuration of an empty installation.
+type Setup interface {
+	// Restore applies the settings stored by an earlier setup.
+	Restore(ctx context.Context) error
+	SetupStatus(ctx context.Context) (SetupStatus, error)
+	CompleteSetup(ctx context.Context, req SetupRequest) (token string, err error)
 }
 
 // Furnace exposes control operations: start/stop and mode changes.
@@ -20,9 +31,25 @@ type Furnace interface {
 	Start(ctx context.Context) error
 	Stop(ctx context.Context) error
 	SetMode(ctx context.Context, p ModeParams) error
+	SetAtmosphere(ctx context.Context, p AtmosphereParams) error
+	CheckReadiness(ctx context.Context, targetTempC float64, durationSec int) (Readiness, error)
 }
 
-// Monitoring exposes read-only state (temperature, mode, remaining, errors).
+// Sequences runs ad-hoc lists of mode steps back to back, one list at a
+// time. Run moves the running sequence on as the furnace completes each step.
+type Sequences interface {
+	// StartSequence sets the first step. It fails with ErrInvalidSequence
+	// for steps that cannot run and with ErrSequenceRunning while another
+	// sequence runs.
+	StartSequence(ctx context.Context, steps []models.SequenceStep, userID int) (models.Sequence, error)
+	// GetSequence returns the running or a finished sequence, or fails with
+	// ErrSequenceNotFound.
+	GetSequence(ctx context.Context, id string) (models.Sequence, error)
+	Run(ctx context.Context)
+}
+
+// Monitoring exposes read-only state (temperature, mode, remaining, errors)
+// with the derived heating rate, ETA and soak progress.
 type Monitoring interface {
 	GetState(ctx context.Context) (models.FurnaceState, error)
 }
@@ -32,10 +59,252 @@ type EventLog interface {
 	List(ctx context.Context, f LogFilter) ([]models.FurnaceEvent, error)
 }
 
+// EventPager lists the event log a page at a time.
+type EventPager interface {
+	// Page returns up to p.Limit events matching f that follow p.Cursor,
+	// with the cursor of the next page. It fails with ErrInvalidCursor for
+	// a cursor it did not issue for the same order.
+	Page(ctx context.Context, f LogFilter, p LogPageParams) (LogPage, error)
+}
+
+// EventStream walks large log queries one event at a time.
+type EventStream interface {
+	// Stream calls fn for every event matching f in the order of List,
+	// stopping at the first error fn returns.
+	Stream(ctx context.Context, f LogFilter, fn func(models.FurnaceEvent) error) error
+}
+
+// EventTail follows the event log as it is appended to.
+type EventTail interface {
+	// Tail returns the events matching f appended after p.AfterID (or
+	// that occurred after p.AfterAt), waiting up to p.Wait for one. It
+	// fails with ErrTailEventNotFound if p.AfterID is not in the log.
+	Tail(ctx context.Context, f LogFilter, p TailParams) (LogTail, error)
+}
+
+// EventComments lets operators annotate logged events. List, Page and
+// Tail return the comments with each event.
+type EventComments interface {
+	// AddComment fails with ErrEventNotFound if eventID is not in the log
+	// and with ErrInvalidComment for blank or overlong text.
+	AddComment(ctx context.Context, eventID string, userID int, text string) (models.EventComment, error)
+}
+
+// EventDeletions lets admins hide events holding mistaken data without
+// destroying the audit history.
+type EventDeletions interface {
+	// DeleteEvent soft-deletes the event. It fails with ErrEventNotFound
+	// if eventID is not in the log, with ErrEventDeleted if it was deleted
+	// before and with ErrInvalidDeletion for a blank or overlong reason.
+	DeleteEvent(ctx context.Context, eventID string, userID int, reason string) error
+}
+
+// EventAudit detects edits and deletions in the event log.
+type EventAudit interface {
+	VerifyChain(ctx context.Context) (models.ChainReport, error)
+}
+
+// AuditExport assembles the records of a period into a signed package for
+// quality and compliance reviews, e.g. after an incident.
+type AuditExport interface {
+	// ExportAudit writes the package to w as a ZIP. It fails, before writing
+	// anything, with ErrInvalidAuditExport for an invalid period and with
+	// ErrAuditKeyNotConfigured without a signing key.
+	ExportAudit(ctx context.Context, req AuditExportRequest, w io.Writer) error
+	// AuditPublicKey returns the key packages are verified with.
+	AuditPublicKey() (AuditKey, error)
+}
+
+// Retention removes expired events from the log. Run purges by the
+// configured limits on a schedule.
+type Retention interface {
+	PurgeEvents(ctx context.Context, req PurgeRequest) (models.PurgeReport, error)
+	Run(ctx context.Context)
+}
+
+// Backups takes consistent snapshots of the database while it is in use.
+type Backups interface {
+	// Backup stores a snapshot in the backup directory; ErrBackupNotConfigured
+	// without one.
+	Backup(ctx context.Context) (models.BackupReport, error)
+	// OpenBackup takes a snapshot for download; closing it removes the
+	// temporary file.
+	OpenBackup(ctx context.Context) (models.BackupReport, io.ReadCloser, error)
+}
+
+// Runs exposes per-run records such as soak stability.
+type Runs interface {
+	GetRun(ctx context.Context, runID string) (models.Run, error)
+}
+
+// Health reports heater wear for maintenance planning.
+type Health interface {
+	FurnaceHealth(ctx context.Context) (models.FurnaceHealth, error)
+}
+
+// TempHistory serves the temperature history for charts.
+type TempHistory interface {
+	History(ctx context.Context, f HistoryFilter) (models.TemperatureHistory, error)
+}
+
+// SampleRollups keeps the hourly and daily summaries of the temperature
+// history up to date and prunes the samples they cover.
+type SampleRollups interface {
+	Run(ctx context.Context)
+}
+
+// Telemetry exposes recorded sensor channels.
+type Telemetry interface {
+	Samples(ctx context.Context, f TelemetryFilter) ([]models.TelemetrySample, error)
+}
+
+// Importer migrates history exported by legacy furnace controllers.
+type Importer interface {
+	ImportTelemetry(ctx context.Context, mapping string, r io.Reader) (ImportReport, error)
+	ImportEvents(ctx context.Context, mapping string, r io.Reader) (ImportReport, error)
+}
+
+// StateBus streams furnace state snapshots as the simulator saves them.
+type StateBus interface {
+	// Subscribe returns a channel of snapshots holding up to buffer pending
+	// ones; older snapshots are dropped for slow readers. cancel closes it.
+	Subscribe(buffer int) (updates <-chan models.FurnaceState, cancel func())
+}
+
+// System reports runtime diagnostics of the running instance.
+type System interface {
+	Info() SystemInfo
+	Build() BuildInfo
+	// RecordPanic counts and logs a panic recovered in a handler.
+	RecordPanic(ctx context.Context, p PanicReport) error
+}
+
 // Simulator runs the background loop that updates temperature/remaining time.
 // Stop via context cancellation in main() for graceful shutdown.
 type Simulator interface {
 	Run(ctx context.Context, tick time.Duration)
+	// Step advances exactly dt of simulated time without a ticker, for
+	// tests and scenario tooling. Not to be mixed with a running loop.
+	Step(ctx context.Context, dt time.Duration) error
+}
+
+// SimClock adjusts simulator tick and time acceleration at runtime.
+type SimClock interface {
+	Speed() Speed
+	SetSpeed(sp Speed) error
+}
+
+// SimTuning reads and changes the simulator physics at runtime.
+type SimTuning interface {
+	SimSettings() models.SimSettings
+	UpdateSimSettings(ctx context.Context, set models.SimSettings) error
+	PreviewSimSettings(ctx context.Context, set models.SimSettings, apply bool) (models.SettingsPreview, error)
+}
+
+// SimAmbient reads and overrides the room temperature around the furnace.
+type SimAmbient interface {
+	Ambient() AmbientStatus
+	SetAmbient(tempC float64) error
+	ClearAmbient()
+}
+
+// Charges simulates loads placed in and taken out of the chamber.
+type Charges interface {
+	Charge() ChargeStatus
+	InsertCharge(req ChargeRequest) (ChargeStatus, error)
+	RemoveCharge() (ChargeStatus, error)
+}
+
+// Faults injects simulated hardware failures into the running simulator.
+type Faults interface {
+	InjectFault(kind string) error
+	ClearFault(kind string) error
+	ClearAllFaults()
+	ActiveFaults() []ActiveFault
+}
+
+// Alerts manages alert rules and their history. Run evaluates the rules
+// against the simulator's published states.
+type Alerts interface {
+	ListRules(ctx context.Context) ([]models.AlertRule, error)
+	GetRule(ctx context.Context, id int) (models.AlertRule, error)
+	CreateRule(ctx context.Context, rule models.AlertRule, userID int) (models.AlertRule, error)
+	UpdateRule(ctx context.Context, rule models.AlertRule) (models.AlertRule, error)
+	DeleteRule(ctx context.Context, id int) error
+	ListAlerts(ctx context.Context, f AlertFilter) ([]models.Alert, error)
+	Run(ctx context.Context)
+}
+
+// Incidents serves the records of alarm episodes. Run compiles them from
+// the simulator's published states.
+type Incidents interface {
+	ListIncidents(ctx context.Context, f IncidentFilter) ([]models.Incident, error)
+	GetIncident(ctx context.Context, id int64) (models.Incident, error)
+	AcknowledgeIncident(ctx context.Context, id int64, userID int) (models.Incident, error)
+	Run(ctx context.Context)
+}
+
+// Escalations pages the escalation chain while a critical incident stays
+// unacknowledged. Run checks the open incident periodically.
+type Escalations interface {
+	Run(ctx context.Context)
+}
+
+// Maintenance tracks recurring maintenance tasks and who completed them.
+// Run logs MAINTENANCE_OVERDUE for tasks that fall due.
+type Maintenance interface {
+	ListTasks(ctx context.Context) ([]models.MaintenanceTask, error)
+	GetTask(ctx context.Context, id int) (models.MaintenanceTask, error)
+	CreateTask(ctx context.Context, t models.MaintenanceTask, userID int) (models.MaintenanceTask, error)
+	UpdateTask(ctx context.Context, t models.MaintenanceTask) (models.MaintenanceTask, error)
+	DeleteTask(ctx context.Context, id int) error
+	CompleteTask(ctx context.Context, id, userID int, note string) (models.MaintenanceRecord, error)
+	ListRecords(ctx context.Context, taskID, limit int) ([]models.MaintenanceRecord, error)
+	Run(ctx context.Context)
+}
+
+// Webhooks manages the external endpoints that receive events. Run queues
+// new events for them and delivers with retries.
+type Webhooks interface {
+	ListWebhooks(ctx context.Context) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, id int) (models.Webhook, error)
+	CreateWebhook(ctx context.Context, w models.Webhook, userID int) (models.Webhook, error)
+	UpdateWebhook(ctx context.Context, w models.Webhook) (models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	ListDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]models.WebhookDelivery, error)
+	ReplayDelivery(ctx context.Context, webhookID int, deliveryID int64) (models.WebhookDelivery, error)
+	Run(ctx context.Context)
+}
+
+// Probes backs the orchestrator readiness probe.
+type Probes interface {
+	Ready(ctx context.Context) ReadinessReport
+}
+
+// Uptime records readiness over time for the public status endpoints.
+type Uptime interface {
+	UptimeSummary(ctx context.Context) (models.UptimeSummary, error)
+	Run(ctx context.Context)
+}
+
+// Loops supervises the background loops: the simulator and the alert and
+// incident evaluators.
+type Loops interface {
+	// Go runs run under name until ctx is canceled, restarting it after a
+	// panic or an early return.
+	Go(ctx context.Context, name string, run func(context.Context))
+	// Restart restarts the named loop; ErrUnknownLoop if none was started.
+	Restart(name string) error
+	LoopStatus() []models.LoopStatus
+	// Wait blocks until every loop has stopped.
+	Wait()
+}
+
+// Chaos controls repository fault injection. It is nil unless chaos mode
+// was enabled in config.
+type Chaos interface {
+	ChaosSettings() repository.ChaosSettings
+	UpdateChaos(s repository.ChaosSettings) error
 }
 
 //
@@ -44,21 +313,206 @@ type Simulator interface {
 
 type Service struct {
 	Furnace
+	Sequences
 	Monitoring
 	EventLog
+	EventStream
+	EventPager
+	EventTail
+	EventComments
+	EventDeletions
+	EventAudit
+	AuditExport
+	Retention
+	Backups
+	Runs
+	Health
+	TempHistory
+	SampleRollups
+	Telemetry
+	Importer
+	StateBus
+	System
 	Simulator
+	SimClock
+	SimTuning
+	SimAmbient
+	Charges
+	Faults
+	Alerts
+	Incidents
+	Escalations
+	Maintenance
+	Webhooks
 	Authorization
+	Setup
+	Probes
+	Uptime
+	Loops
+	Chaos
+}
+
+// Config carries tunables for the composed services.
+type Config struct {
+	Sim    SimConfig
+	Import ImportConfig
+	Probes ProbeConfig
+	Alerts AlertConfig
+	// Escalation pages the chain for unacknowledged critical incidents;
+	// the zero value escalates nothing.
+	Escalation EscalationConfig
+	// Retention limits the event log; the zero value keeps every event.
+	Retention   RetentionConfig
+	Backup      BackupConfig
+	Maintenance MaintenanceConfig
+	Webhooks    WebhookConfig
+	Uptime      UptimeConfig
+	History     HistoryConfig
+	Sequences   SequenceConfig
+	Audit       AuditConfig
+	Usernames   UsernamePolicy
+	// Site is the site this backend serves, which tokens are issued for
+	// and checked against; repository.DefaultSite when empty. It must be
+	// the site the repositories are scoped to.
+	Site string
+	// Clock timestamps furnace commands and drives the simulator; time.Now
+	// when nil. Scripted replays drive it alongside Simulator.Step, edge
+	// deployments may plug in a disciplined (e.g. PTP-backed) source.
+	Clock func() time.Time
+	// NewID generates event and run IDs; random UUIDs when nil. See NodeIDs.
+	NewID func() string
+	// LoopFailed, when set, is called each time a supervised loop panics
+	// or returns early, before it is restarted; stack is set for a panic.
+	LoopFailed func(name string, err error, stack []byte)
+	// Version is reported by System.Info; the version embedded by the Go
+	// toolchain when empty.
+	Version string
+	// Commit is reported by System.Build; the VCS revision embedded by the
+	// Go toolchain when empty.
+	Commit string
+}
+
+// DefaultConfig returns the configuration used by NewService.
+func DefaultConfig() Config {
+	return Config{Sim: DefaultSimConfig(), Probes: DefaultProbeConfig()}
 }
 
 // NewService wires repository layer into concrete services (same style as your Todo `NewService`).
 // You will implement NewFurnaceService/NewMonitoringService/NewEventLogService/NewSimulatorService
 // in their own files, taking the repo deps you define under internal/repository.
 func NewService(repos *repository.Repository) *Service {
-	return &Service{
-		Furnace:       NewFurnaceService(repos.StateRepo, repos.EventRepo),
-		Monitoring:    NewMonitoringService(repos.StateRepo),
-		EventLog:      NewEventLogService(repos.EventRepo),
-		Simulator:     NewSimulatorService(repos.StateRepo, repos.EventRepo),
-		Authorization: NewAuthService(repos.Auth),
+	return NewServiceWithConfig(repos, DefaultConfig())
+}
+
+// NewServiceWithConfig is NewService with explicit tunables.
+func NewServiceWithConfig(repos *repository.Repository, cfg Config) *Service {
+	// one in-memory state for the simulator, the furnace commands and reads
+	state := NewStateManager(repos.StateRepo)
+	// events logged while serving a request carry its request and user IDs
+	eventRepo := correlate(repos.EventRepo)
+	sim := NewSimulatorServiceWithConfig(state, eventRepo, repos.RunRepo, repos.Telemetry, repos.Settings, cfg.Sim)
+	furnace := NewFurnaceService(state, eventRepo)
+	furnace.uow = repos.UnitOfWork
+	furnace.limits = sim.physicsLimits
+	bus := NewStateBroker()
+	sim.bus = bus
+	sim.healthRepo = repos.Health
+	sim.sampleRepo = repos.Samples
+	sim.uow = repos.UnitOfWork
+	sim.alertRepo = repos.Alerts
+	history := NewHistoryService(repos.Samples)
+	history.rollups, history.cfg = repos.Rollups, cfg.History
+	events := NewEventLogService(repos.EventRepo)
+	events.chain = repos.Chain
+	events.stream = repos.Events
+	events.comments = repos.Comments
+	events.deletions = repos.Deletions
+	audit := NewAuditService(repos.Events, repos.RunRepo, repos.Alerts, repos.Incidents, sim.SimSettings, cfg.Audit)
+	audit.comments = repos.Comments
+	audit.chain = repos.Chain
+	audit.version = cfg.Version
+	system := NewSystemService(repos.Status, bus, cfg.Version)
+	system.events = eventRepo
+	if cfg.Commit != "" {
+		system.commit = cfg.Commit
+	}
+	retention := NewRetentionService(repos.Retention, eventRepo, cfg.Retention)
+	backups := NewBackupService(repos.Backup, eventRepo, cfg.Backup)
+	alerts := NewAlertService(repos.Alerts, eventRepo, bus)
+	if n := NewChannelNotifier(cfg.Alerts); n != nil {
+		alerts.notifier = n
+	}
+	incidents := NewIncidentService(repos.Incidents, eventRepo, repos.Samples, bus)
+	escalation := NewEscalationService(repos.Incidents, eventRepo, cfg.Escalation)
+	maintenance := NewMaintenanceService(repos.Maintenance, repos.Health, eventRepo, cfg.Maintenance)
+	maintenance.elements = sim
+	webhooks := NewWebhookService(repos.Webhooks, cfg.Webhooks)
+	sequences := NewSequenceService(furnace, eventRepo, bus)
+	sequences.speed = sim
+	sequences.repo, sequences.cfg = repos.Sequences, cfg.Sequences
+	monitoring := NewMonitoringService(state)
+	monitoring.stats = sim.stats
+	monitoring.speed = sim
+	if cfg.Clock != nil {
+		furnace.clock, sim.now, history.now, incidents.now, retention.now = cfg.Clock, cfg.Clock, cfg.Clock, cfg.Clock, cfg.Clock
+		maintenance.now, webhooks.now, events.now, audit.now = cfg.Clock, cfg.Clock, cfg.Clock, cfg.Clock
+		escalation.now, backups.now, sequences.now = cfg.Clock, cfg.Clock, cfg.Clock
+	}
+	if cfg.NewID != nil {
+		furnace.ids, sim.newID, alerts.newID, retention.newID = cfg.NewID, cfg.NewID, cfg.NewID, cfg.NewID
+		maintenance.newID, escalation.newID, backups.newID, sequences.newID = cfg.NewID, cfg.NewID, cfg.NewID, cfg.NewID
+	}
+	probes := NewProbeService(repos.Status, sim, cfg.Probes)
+	auth := NewAuthService(repos.Auth)
+	auth.policy = cfg.Usernames
+	if cfg.Site != "" {
+		auth.site = cfg.Site
+	}
+	setup := NewSetupService(repos.Auth, repos.Install, auth)
+	setup.sim = sim
+	s := &Service{
+		Furnace:        furnace,
+		Sequences:      sequences,
+		Monitoring:     monitoring,
+		EventLog:       events,
+		EventStream:    events,
+		EventPager:     events,
+		EventTail:      events,
+		EventComments:  events,
+		EventDeletions: events,
+		EventAudit:     events,
+		AuditExport:    audit,
+		Retention:      retention,
+		Backups:        backups,
+		Runs:           NewRunService(repos.RunRepo),
+		Health:         sim,
+		TempHistory:    history,
+		SampleRollups:  history,
+		Telemetry:      NewTelemetryService(repos.Telemetry),
+		Importer:       NewImportService(repos.Import, cfg.Import),
+		StateBus:       bus,
+		System:         system,
+		Simulator:      sim,
+		SimClock:       sim,
+		SimTuning:      sim,
+		SimAmbient:     sim,
+		Charges:        sim,
+		Faults:         sim,
+		Alerts:         alerts,
+		Incidents:      incidents,
+		Escalations:    escalation,
+		Maintenance:    maintenance,
+		Webhooks:       webhooks,
+		Authorization:  auth,
+		Setup:          setup,
+		Probes:         probes,
+		Uptime:         NewUptimeService(repos.Uptime, probes, cfg.Uptime),
+	}
+	loops := NewSupervisor()
+	loops.failed = cfg.LoopFailed
+	s.Loops = loops
+	if repos.Chaos != nil {
+		s.Chaos = NewChaosService(repos.Chaos)
 	}
+	return s
 }
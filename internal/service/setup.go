@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+// MinSigningKeyLen is the shortest signing key setup accepts, in bytes.
+const MinSigningKeyLen = 32
+
+// Setup errors.
+var (
+	ErrSetupDone    = errors.New("setup already completed")
+	ErrInvalidSetup = errors.New("invalid setup")
+)
+
+// SetupRequest is the first-run configuration.
+type SetupRequest struct {
+	Username string
+	Password string
+	// SigningKey signs API tokens; a random key is generated when empty.
+	SigningKey string
+	Units      string  // models.UnitsCelsius (default) or models.UnitsFahrenheit
+	MaxSafeC   float64 // OVERHEAT threshold; 0 keeps the configured value
+}
+
+// SetupStatus tells clients whether the installation still needs setup.
+type SetupStatus struct {
+	Required    bool       `json:"required"`
+	Units       string     `json:"units" example:"C"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+type SetupService struct {
+	mu      sync.Mutex
+	users   repository.Authorization
+	install repository.InstallRepo
+	auth    *AuthService
+	sim     SimTuning // optional; safety limits are left alone when nil
+	now     func() time.Time
+}
+
+func NewSetupService(users repository.Authorization, install repository.InstallRepo, auth *AuthService) *SetupService {
+	return &SetupService{users: users, install: install, auth: auth, now: time.Now}
+}
+
+// Restore applies the signing key stored by an earlier setup. Call it once
+// before serving requests.
+func (s *SetupService) Restore(ctx context.Context) error {
+	inst, err := s.install.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("load installation: %w", err)
+	}
+	if inst.SigningKey != "" {
+		s.auth.setSigningKey(inst.SigningKey)
+	}
+	return nil
+}
+
+// SetupStatus reports whether setup is still required: it is until the
+// first user of the site exists.
+func (s *SetupService) SetupStatus(ctx context.Context) (SetupStatus, error) {
+	n, err := s.users.Count(ctx)
+	if err != nil {
+		return SetupStatus{}, err
+	}
+	inst, err := s.install.Load(ctx)
+	if err != nil {
+		return SetupStatus{}, err
+	}
+	st := SetupStatus{Required: n == 0, Units: inst.Units, CompletedAt: inst.CompletedAt}
+	if st.Units == "" {
+		st.Units = models.UnitsCelsius
+	}
+	return st, nil
+}
+
+// CompleteSetup creates the first admin, stores the signing key and units,
+// applies the safety limit, and returns a token for the new admin. It fails
+// with ErrSetupDone once any user exists.
+func (s *SetupService) CompleteSetup(ctx context.Context, req SetupRequest) (token string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, err := s.users.Count(ctx)
+	if err != nil {
+		return "", err
+	}
+	if n > 0 {
+		return "", ErrSetupDone
+	}
+
+	// the installer may name the first admin "admin" even if reserved
+	if req.Username, err = s.auth.policy.normalize(req.Username, true); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidSetup, err)
+	}
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidSetup, err)
+	}
+	switch req.Units = strings.ToUpper(strings.TrimSpace(req.Units)); req.Units {
+	case "":
+		req.Units = models.UnitsCelsius
+	case models.UnitsCelsius, models.UnitsFahrenheit:
+	default:
+		return "", fmt.Errorf("%w: units must be C or F", ErrInvalidSetup)
+	}
+	if req.SigningKey == "" {
+		// every site gets a key of its own, so its tokens are worthless
+		// to the other sites sharing the database
+		if req.SigningKey, err = randomKey(); err != nil {
+			return "", err
+		}
+	} else if len(req.SigningKey) < MinSigningKeyLen {
+		return "", fmt.Errorf("%w: signing_key must be at least %d bytes", ErrInvalidSetup, MinSigningKeyLen)
+	}
+	if req.MaxSafeC < 0 {
+		return "", fmt.Errorf("%w: max_safe_c must be positive", ErrInvalidSetup)
+	}
+
+	// Settings first: setup stays open until the admin exists, so a failure
+	// below can be retried.
+	if req.MaxSafeC > 0 && s.sim != nil {
+		set := s.sim.SimSettings()
+		set.MaxSafeC = req.MaxSafeC
+		if err := s.sim.UpdateSimSettings(ctx, set); err != nil {
+			if errors.Is(err, ErrInvalidSimSettings) {
+				return "", fmt.Errorf("%w: %v", ErrInvalidSetup, err)
+			}
+			return "", err
+		}
+	}
+	done := s.now().UTC()
+	if err := s.install.Save(ctx, models.Installation{SigningKey: req.SigningKey, Units: req.Units, CompletedAt: &done}); err != nil {
+		return "", fmt.Errorf("save installation: %w", err)
+	}
+	id, err := s.users.Create(ctx, req.Username, hash)
+	if err != nil {
+		return "", err
+	}
+	if err := s.users.SetRole(ctx, id, models.RoleAdmin); err != nil {
+		return "", err
+	}
+	s.auth.setSigningKey(req.SigningKey)
+	return s.auth.issueToken(id, models.RoleAdmin)
+}
+
+// randomKey returns a 256-bit key, hex encoded.
+func randomKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate signing key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+type installRepoStub struct {
+	inst  models.Installation
+	saves int
+}
+
+func (r *installRepoStub) Save(_ context.Context, inst models.Installation) error {
+	r.inst = inst
+	r.saves++
+	return nil
+}
+
+func (r *installRepoStub) Load(context.Context) (models.Installation, error) { return r.inst, nil }
+
+type simTuningStub struct {
+	set models.SimSettings
+}
+
+func (s *simTuningStub) SimSettings() models.SimSettings { return s.set }
+
+func (s *simTuningStub) UpdateSimSettings(_ context.Context, set models.SimSettings) error {
+	if err := validateSimSettings(set); err != nil {
+		return err
+	}
+	s.set = set
+	return nil
+}
+
+func (s *simTuningStub) PreviewSimSettings(_ context.Context, set models.SimSettings, _ bool) (models.SettingsPreview, error) {
+	return models.SettingsPreview{Current: s.set, Proposed: set, Valid: true}, nil
+}
+
+func newSetupForTest() (*SetupService, *mockAuthRepo, *installRepoStub, *simTuningStub) {
+	users := &mockAuthRepo{CreateFn: func(string, string) (int, error) { return 1, nil }}
+	install := &installRepoStub{}
+	sim := &simTuningStub{set: settingsFromConfig(DefaultSimConfig(), time.Second)}
+	svc := NewSetupService(users, install, NewAuthService(users))
+	svc.sim = sim
+	svc.now = func() time.Time { return time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC) }
+	return svc, users, install, sim
+}
+
+func TestSetup_CreatesAdminAndStoresSettings(t *testing.T) {
+	svc, users, install, sim := newSetupForTest()
+	ctx := context.Background()
+
+	st, err := svc.SetupStatus(ctx)
+	if err != nil || !st.Required || st.Units != models.UnitsCelsius {
+		t.Fatalf("status before setup = %+v, %v", st, err)
+	}
+
+	token, err := svc.CompleteSetup(ctx, SetupRequest{Username: " root ", Password: "pw", Units: "f", MaxSafeC: 900})
+	if err != nil {
+		t.Fatalf("CompleteSetup: %v", err)
+	}
+	if len(users.createCalls) != 1 || users.createCalls[0].username != "root" || users.setRoles[1] != models.RoleAdmin {
+		t.Fatalf("admin not created: %+v %v", users.createCalls, users.setRoles)
+	}
+	if len(install.inst.SigningKey) != 64 || install.inst.Units != models.UnitsFahrenheit || install.inst.CompletedAt == nil {
+		t.Fatalf("installation = %+v", install.inst)
+	}
+	if sim.set.MaxSafeC != 900 {
+		t.Fatalf("max_safe_c = %v, want 900", sim.set.MaxSafeC)
+	}
+
+	// The token is signed with the new key, which replaced the default.
+	claims, err := svc.auth.ParseClaims(token)
+	if err != nil || claims.UserID != 1 || claims.EffectiveRole() != models.RoleAdmin {
+		t.Fatalf("token claims = %+v, %v", claims, err)
+	}
+	legacy, _ := NewAuthService(users).issueToken(1, models.RoleAdmin)
+	if _, err := svc.auth.ParseClaims(legacy); err == nil {
+		t.Fatal("token signed with the default key still verifies")
+	}
+
+	users.count = 1
+	if _, err := svc.CompleteSetup(ctx, SetupRequest{Username: "again", Password: "pw"}); !errors.Is(err, ErrSetupDone) {
+		t.Fatalf("second setup: err = %v, want ErrSetupDone", err)
+	}
+	if st, _ := svc.SetupStatus(ctx); st.Required || st.Units != models.UnitsFahrenheit {
+		t.Fatalf("status after setup = %+v", st)
+	}
+}
+
+func TestSetup_RejectsInvalidRequests(t *testing.T) {
+	for name, req := range map[string]SetupRequest{
+		"no username":   {Password: "pw"},
+		"no password":   {Username: "root"},
+		"units":         {Username: "root", Password: "pw", Units: "K"},
+		"short key":     {Username: "root", Password: "pw", SigningKey: "short"},
+		"limit":         {Username: "root", Password: "pw", MaxSafeC: -1},
+		"below ambient": {Username: "root", Password: "pw", MaxSafeC: 10},
+	} {
+		svc, users, install, _ := newSetupForTest()
+		if _, err := svc.CompleteSetup(context.Background(), req); !errors.Is(err, ErrInvalidSetup) {
+			t.Errorf("%s: err = %v, want ErrInvalidSetup", name, err)
+		}
+		if len(users.createCalls) != 0 || install.saves != 0 {
+			t.Errorf("%s: setup left changes behind", name)
+		}
+	}
+}
+
+func TestSetup_RestoreAppliesStoredKey(t *testing.T) {
+	users := &mockAuthRepo{}
+	key := "0123456789abcdef0123456789abcdef"
+	auth := NewAuthService(users)
+	svc := NewSetupService(users, &installRepoStub{inst: models.Installation{SigningKey: key}}, auth)
+	if err := svc.Restore(context.Background()); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if string(auth.signingKey()) != key {
+		t.Fatalf("signing key = %q, want the stored one", auth.signingKey())
+	}
+}
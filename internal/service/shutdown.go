@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// flushTimeout bounds the final save once the simulator has been stopped.
+const flushTimeout = 5 * time.Second
+
+// shutdown brings the stored state up to the stop moment and appends a
+// SHUTDOWN event. Run's context is already canceled by then, so it uses
+// its own.
+func (s *SimulatorService) shutdown(now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	st, ok := s.step(ctx, now, true)
+	if !ok {
+		return
+	}
+	_ = s.eventRepo.Append(ctx, models.FurnaceEvent{
+		EventID:     s.newID(),
+		OccurredAt:  now.UTC(),
+		Type:        "SHUTDOWN",
+		Description: "Simulator stopped; state flushed",
+		Metadata: withRunID(map[string]any{
+			"temp_c":            st.CurrentTempC,
+			"mode":              st.Mode,
+			"isRunning":         st.IsRunning,
+			"remaining_seconds": st.RemainingSeconds,
+		}, st.RunID),
+	})
+}
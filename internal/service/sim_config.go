@@ -0,0 +1,110 @@
+package service
+
+import "time"
+
+// SimConfig holds tunable simulator parameters. The zero value disables
+// every optional effect; DefaultSimConfig returns the shipped defaults.
+type SimConfig struct {
+	Tick      time.Duration // interval between simulator steps; 0 means 1s
+	TimeScale float64       // simulated seconds per wall-clock second; 0 means 1
+	// MaxElapsed is the longest wall-clock gap between ticks that is
+	// simulated as running time; the rest counts as downtime, during which
+	// the chamber cools and soak timers stand still. 0 disables the clamp.
+	MaxElapsed time.Duration
+	// JitterTolerance is how much earlier than the tick interval a tick may
+	// fire and still be simulated; 0 means a tenth of the interval.
+	JitterTolerance time.Duration
+	Physics         PhysicsConfig
+	Sensor          SensorConfig
+	Ambient         AmbientConfig
+	Soak            SoakConfig
+	Power           PowerConfig
+	Safety          SafetyConfig
+	Wear            WearConfig
+	Charge          ChargeConfig
+	Atmosphere      AtmosphereConfig
+	KeepWarm        KeepWarmConfig
+}
+
+// PhysicsConfig sets the chamber's thermal behaviour. Zero fields fall back
+// to the package constants of the same name.
+type PhysicsConfig struct {
+	AmbientC          float64 // °C, temperature the chamber cools to
+	MaxSafeC          float64 // °C, overheat threshold and highest allowed target
+	RampUpCPerSec     float64 // heating rate in HEAT
+	RampDownCPerSec   float64 // cooling rate in COOL
+	StandbyCoolPerSec float64 // passive cooling rate
+}
+
+func (p PhysicsConfig) withDefaults() PhysicsConfig {
+	if p.AmbientC == 0 {
+		p.AmbientC = AmbientC
+	}
+	if p.MaxSafeC == 0 {
+		p.MaxSafeC = MaxSafeC
+	}
+	if p.RampUpCPerSec == 0 {
+		p.RampUpCPerSec = RampUpCPerSec
+	}
+	if p.RampDownCPerSec == 0 {
+		p.RampDownCPerSec = RampDownCPerSec
+	}
+	if p.StandbyCoolPerSec == 0 {
+		p.StandbyCoolPerSec = StandbyCoolPerSec
+	}
+	return p
+}
+
+// SensorConfig models the thermocouple between the true furnace temperature
+// and the value reported as measured_temp_c.
+type SensorConfig struct {
+	NoiseStdDevC  float64 // standard deviation of Gaussian noise per reading, °C
+	DriftCPerHour float64 // rate at which the sensor offset drifts, °C/h
+	MaxDriftC     float64 // cap on the absolute accumulated drift, °C
+	Seed          int64   // RNG seed so noisy runs are reproducible
+}
+
+// SoakConfig sets when a soak is reported as unstable.
+type SoakConfig struct {
+	MinStability float64 // required fraction of soak time within ±SoakToleranceC; 0 disables alerts
+	MinSeconds   float64 // soak time to accumulate before the score is judged
+}
+
+// DefaultSimConfig returns a configuration with a perfect sensor.
+func DefaultSimConfig() SimConfig {
+	return SimConfig{
+		Tick:       time.Second,
+		TimeScale:  1,
+		MaxElapsed: time.Minute,
+		Physics:    PhysicsConfig{}.withDefaults(),
+		Sensor:     SensorConfig{MaxDriftC: 5},
+		Ambient:    AmbientConfig{WarmingC: 10, TimeConstantS: 900},
+		Soak:       SoakConfig{MinStability: 0.9, MinSeconds: 60},
+		Power:      PowerConfig{HeaterKW: 150, HoldFraction: 0.4, CoolingKW: 7.5, IdleKW: 1.5},
+		// nominal ramp is RampUpCPerSec = 180 °C/min
+		Safety: SafetyConfig{MaxRiseCPerMin: 300},
+		// 2000 heating hours cost a fifth of the ramp rate
+		Wear: WearConfig{
+			RampLossPerHour:   0.0001,
+			RampLossPerCycle:  0.00002,
+			MaxRampLoss:       0.35,
+			MaintenanceHours:  2000,
+			MaintenanceCycles: 5000,
+		},
+		// an 800 kg steel charge at room temperature drops a chamber at
+		// 850 °C by about 100 °C
+		Charge: ChargeConfig{ChamberKJPerK: 300, CouplingKWPerK: 2},
+		// 20 m³/h of nitrogen holds about 25 ppm O2 and takes roughly an
+		// hour to purge the chamber from air
+		Atmosphere: AtmosphereConfig{
+			ChamberVolumeM3:   2,
+			FlowTimeConstantS: 5,
+			GasO2PPM:          5,
+			LeakPerHour:       0.001,
+			IdleAirPerHour:    2,
+			DefaultMaxO2PPM:   50,
+			AlarmAboveC:       300,
+			DoorAirShare:      0.3,
+		},
+	}
+}
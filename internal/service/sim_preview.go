@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"controlling_furnace/internal/models"
+)
+
+// PreviewSimSettings reports what set would invalidate: an active HEAT
+// target outside the new range, a chamber already above the new max_safe_c
+// (it would trip OVERHEAT on the next tick) and a room override at or above
+// it block the change; enabled temp_above rules that could then only fire
+// in an overheat are warnings.
+//
+// With apply, valid settings without blocking conflicts are applied like
+// UpdateSimSettings. The check and the change run under the state lock, so
+// no command can set a target the new limits would reject in between.
+func (s *SimulatorService) PreviewSimSettings(ctx context.Context, set models.SimSettings, apply bool) (models.SettingsPreview, error) {
+	p := models.SettingsPreview{
+		Current:   s.SimSettings(),
+		Proposed:  set,
+		Valid:     true,
+		Conflicts: []models.SettingsConflict{},
+	}
+	if err := validateSimSettings(set); err != nil {
+		p.Valid, p.Error = false, err.Error()
+		return p, nil
+	}
+	rules, err := s.alertRules(ctx)
+	if err != nil {
+		return models.SettingsPreview{}, err
+	}
+
+	_, err = s.state.Update(ctx, func(st *models.FurnaceState) error {
+		p.Conflicts = append(p.Conflicts, s.settingsConflicts(*st, set)...)
+		p.Conflicts = append(p.Conflicts, ruleConflicts(rules, set)...)
+		if !apply || blocked(p.Conflicts) {
+			return errStateUnchanged
+		}
+		if s.settingsRepo != nil {
+			if err := s.settingsRepo.Save(ctx, set); err != nil {
+				return err
+			}
+		}
+		s.publishSettings(set)
+		p.Applied = true
+		return errStateUnchanged
+	})
+	if err != nil {
+		return models.SettingsPreview{}, err
+	}
+	return p, nil
+}
+
+func (s *SimulatorService) alertRules(ctx context.Context) ([]models.AlertRule, error) {
+	if s.alertRepo == nil {
+		return nil, nil
+	}
+	return s.alertRepo.ListRules(ctx)
+}
+
+// settingsConflicts checks st and the room override against set.
+func (s *SimulatorService) settingsConflicts(st models.FurnaceState, set models.SimSettings) []models.SettingsConflict {
+	var out []models.SettingsConflict
+	if st.IsRunning && st.Mode == ModeHeat {
+		switch t := st.TargetTempC; {
+		case t > set.MaxSafeC:
+			out = append(out, models.SettingsConflict{Subject: "state.target_temp_c", Value: t, Blocking: true,
+				Message: fmt.Sprintf("active HEAT target %.1f °C exceeds max_safe_c %.1f °C", t, set.MaxSafeC)})
+		case t < set.AmbientC:
+			out = append(out, models.SettingsConflict{Subject: "state.target_temp_c", Value: t, Blocking: true,
+				Message: fmt.Sprintf("active HEAT target %.1f °C is below ambient_c %.1f °C", t, set.AmbientC)})
+		}
+	}
+	if st.CurrentTempC > set.MaxSafeC {
+		out = append(out, models.SettingsConflict{Subject: "state.current_temp_c", Value: st.CurrentTempC, Blocking: true,
+			Message: fmt.Sprintf("chamber at %.1f °C is above max_safe_c %.1f °C and would raise OVERHEAT", st.CurrentTempC, set.MaxSafeC)})
+	}
+
+	s.settingsMu.RLock()
+	override := s.ambientOverride
+	s.settingsMu.RUnlock()
+	if override != nil && *override >= set.MaxSafeC {
+		out = append(out, models.SettingsConflict{Subject: "ambient.override", Value: *override, Blocking: true,
+			Message: fmt.Sprintf("room override %.1f °C is not below max_safe_c %.1f °C", *override, set.MaxSafeC)})
+	}
+	return out
+}
+
+// ruleConflicts warns about enabled temp_above rules set above max_safe_c.
+func ruleConflicts(rules []models.AlertRule, set models.SimSettings) []models.SettingsConflict {
+	var out []models.SettingsConflict
+	for _, r := range rules {
+		if r.Enabled && r.Kind == models.AlertTempAbove && r.Threshold > set.MaxSafeC {
+			out = append(out, models.SettingsConflict{Subject: "alert_rule", RuleID: r.ID, Value: r.Threshold,
+				Message: fmt.Sprintf("rule %q at %.1f °C can only fire in an overheat above max_safe_c %.1f °C", r.Name, r.Threshold, set.MaxSafeC)})
+		}
+	}
+	return out
+}
+
+func blocked(conflicts []models.SettingsConflict) bool {
+	for _, c := range conflicts {
+		if c.Blocking {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestPreviewSimSettings_ReportsConflictsWithoutApplying(t *testing.T) {
+	now := time.Now()
+	repo := &settingsRepoStub{}
+	svc := NewSimulatorServiceWithConfig(&simStateRepoStub{loadResp: heatingState(now)}, &simEventRepoStub{}, nil, nil, repo, DefaultSimConfig())
+	svc.alertRepo = &alertRepoStub{rules: []models.AlertRule{
+		{ID: 1, Name: "Too hot", Kind: models.AlertTempAbove, Threshold: 750, Enabled: true},
+		{ID: 2, Name: "Disabled", Kind: models.AlertTempAbove, Threshold: 950, Enabled: false},
+		{ID: 3, Name: "Short soak", Kind: models.AlertRemainingBelow, Threshold: 900, Enabled: true},
+	}}
+	if err := svc.SetAmbient(650); err != nil {
+		t.Fatalf("SetAmbient: %v", err)
+	}
+
+	set := svc.SimSettings()
+	set.MaxSafeC = 600 // below the 800 °C target, the room override and rule 1
+	for _, apply := range []bool{false, true} {
+		p, err := svc.PreviewSimSettings(context.Background(), set, apply)
+		if err != nil {
+			t.Fatalf("PreviewSimSettings: %v", err)
+		}
+		if !p.Valid || p.Applied || p.Current.MaxSafeC != MaxSafeC || p.Proposed.MaxSafeC != 600 {
+			t.Fatalf("apply=%v: unexpected preview %+v", apply, p)
+		}
+		var subjects []string
+		for _, c := range p.Conflicts {
+			subjects = append(subjects, c.Subject)
+		}
+		want := []string{"state.target_temp_c", "ambient.override", "alert_rule"}
+		if len(subjects) != len(want) || subjects[0] != want[0] || subjects[1] != want[1] || subjects[2] != want[2] {
+			t.Fatalf("conflicts = %v, want %v", subjects, want)
+		}
+		if p.Conflicts[2].RuleID != 1 || p.Conflicts[2].Blocking {
+			t.Fatalf("rule conflict = %+v, want a warning for rule 1", p.Conflicts[2])
+		}
+	}
+	if repo.saves != 0 || svc.SimSettings().MaxSafeC != MaxSafeC {
+		t.Fatal("blocked settings must not be applied")
+	}
+}
+
+func TestPreviewSimSettings_AppliesWhenNothingBlocks(t *testing.T) {
+	now := time.Now()
+	repo := &settingsRepoStub{}
+	svc := NewSimulatorServiceWithConfig(&simStateRepoStub{loadResp: heatingState(now)}, &simEventRepoStub{}, nil, nil, repo, DefaultSimConfig())
+
+	set := svc.SimSettings()
+	set.MaxSafeC = 900
+	p, err := svc.PreviewSimSettings(context.Background(), set, true)
+	if err != nil {
+		t.Fatalf("PreviewSimSettings: %v", err)
+	}
+	if !p.Applied || len(p.Conflicts) != 0 {
+		t.Fatalf("unexpected preview %+v", p)
+	}
+	if repo.saves != 1 || svc.SimSettings().MaxSafeC != 900 {
+		t.Fatalf("settings not applied: saved %+v", repo.saved)
+	}
+
+	set.MaxSafeC = set.AmbientC
+	if p, err = svc.PreviewSimSettings(context.Background(), set, true); err != nil || p.Valid || p.Error == "" || p.Applied {
+		t.Fatalf("invalid settings: preview %+v, err %v", p, err)
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// ErrInvalidSimSettings reports settings the simulator cannot run with.
+var ErrInvalidSimSettings = errors.New("invalid simulator settings")
+
+func validateSimSettings(set models.SimSettings) error {
+	switch {
+	case time.Duration(set.TickMs)*time.Millisecond < MinSimTick:
+		return fmt.Errorf("%w: tick_ms must be >= %d", ErrInvalidSimSettings, MinSimTick.Milliseconds())
+	case set.MaxSafeC <= set.AmbientC:
+		return fmt.Errorf("%w: max_safe_c must be above ambient_c", ErrInvalidSimSettings)
+	case set.RampUpCPerSec <= 0, set.RampDownCPerSec <= 0, set.StandbyCoolCPerSec <= 0:
+		return fmt.Errorf("%w: ramp rates must be positive", ErrInvalidSimSettings)
+	case set.NoiseStdDevC < 0, set.AmbientNoiseStdDevC < 0, set.MaxDriftC < 0:
+		return fmt.Errorf("%w: noise and max drift must not be negative", ErrInvalidSimSettings)
+	}
+	return nil
+}
+
+func settingsFromConfig(cfg SimConfig, tick time.Duration) models.SimSettings {
+	return models.SimSettings{
+		TickMs:              int(tick / time.Millisecond),
+		AmbientC:            cfg.Physics.AmbientC,
+		MaxSafeC:            cfg.Physics.MaxSafeC,
+		RampUpCPerSec:       cfg.Physics.RampUpCPerSec,
+		RampDownCPerSec:     cfg.Physics.RampDownCPerSec,
+		StandbyCoolCPerSec:  cfg.Physics.StandbyCoolPerSec,
+		NoiseStdDevC:        cfg.Sensor.NoiseStdDevC,
+		DriftCPerHour:       cfg.Sensor.DriftCPerHour,
+		MaxDriftC:           cfg.Sensor.MaxDriftC,
+		AmbientNoiseStdDevC: cfg.Ambient.NoiseStdDevC,
+	}
+}
+
+// withSettings returns cfg with the tunable fields replaced by set.
+func (cfg SimConfig) withSettings(set models.SimSettings) SimConfig {
+	cfg.Physics = PhysicsConfig{
+		AmbientC:          set.AmbientC,
+		MaxSafeC:          set.MaxSafeC,
+		RampUpCPerSec:     set.RampUpCPerSec,
+		RampDownCPerSec:   set.RampDownCPerSec,
+		StandbyCoolPerSec: set.StandbyCoolCPerSec,
+	}
+	cfg.Sensor.NoiseStdDevC = set.NoiseStdDevC
+	cfg.Sensor.DriftCPerHour = set.DriftCPerHour
+	cfg.Sensor.MaxDriftC = set.MaxDriftC
+	cfg.Ambient.NoiseStdDevC = set.AmbientNoiseStdDevC
+	return cfg
+}
+
+// SimSettings returns the current runtime settings, including changes not
+// yet picked up by the loop.
+func (s *SimulatorService) SimSettings() models.SimSettings {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
+	return settingsFromConfig(s.published, s.Speed().Tick)
+}
+
+// UpdateSimSettings validates and persists set. The tick interval changes
+// immediately; everything else applies from the next tick.
+func (s *SimulatorService) UpdateSimSettings(ctx context.Context, set models.SimSettings) error {
+	if err := validateSimSettings(set); err != nil {
+		return err
+	}
+	if s.settingsRepo != nil {
+		if err := s.settingsRepo.Save(ctx, set); err != nil {
+			return err
+		}
+	}
+	s.publishSettings(set)
+	return nil
+}
+
+// restoreSettings applies settings persisted by an earlier process. Invalid
+// or missing rows leave the configured values in place.
+func (s *SimulatorService) restoreSettings(ctx context.Context) {
+	if s.settingsRepo == nil {
+		return
+	}
+	set, err := s.settingsRepo.Load(ctx)
+	if err != nil || set == (models.SimSettings{}) || validateSimSettings(set) != nil {
+		return
+	}
+	s.publishSettings(set)
+}
+
+func (s *SimulatorService) publishSettings(set models.SimSettings) {
+	sp := s.Speed()
+	sp.Tick = time.Duration(set.TickMs) * time.Millisecond
+	_ = s.SetSpeed(sp) // tick already validated
+
+	s.settingsMu.Lock()
+	cfg := s.published.withSettings(set)
+	s.published = cfg
+	s.pending = &cfg
+	s.settingsMu.Unlock()
+}
+
+// applyPendingSettings swaps in settings published since the last tick.
+// Only the simulator loop calls it, so cfg needs no lock elsewhere.
+func (s *SimulatorService) applyPendingSettings() {
+	s.settingsMu.Lock()
+	next := s.pending
+	s.pending = nil
+	s.settingsMu.Unlock()
+	if next == nil {
+		return
+	}
+	s.cfg = *next
+	// keep accumulated drift and RNG streams
+	s.sensor.cfg = next.Sensor
+	s.ambient.cfg = next.Ambient
+}
+
+// physicsLimits returns the published physics, so commands are validated
+// against the values the next tick will use.
+func (s *SimulatorService) physicsLimits() PhysicsConfig {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
+	return s.published.Physics
+}
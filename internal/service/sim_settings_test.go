@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+type settingsRepoStub struct {
+	saved models.SimSettings
+	saves int
+}
+
+func (r *settingsRepoStub) Save(ctx context.Context, s models.SimSettings) error {
+	r.saved = s
+	r.saves++
+	return nil
+}
+
+func (r *settingsRepoStub) Load(ctx context.Context) (models.SimSettings, error) {
+	return r.saved, nil
+}
+
+func TestUpdateSimSettings_PersistsAndAppliesNextTick(t *testing.T) {
+	now := time.Now()
+	st := heatingState(now)
+	st.UpdatedAt = now.Add(-time.Second)
+	states := &simStateRepoStub{loadResp: st}
+	repo := &settingsRepoStub{}
+	svc := NewSimulatorServiceWithConfig(states, &simEventRepoStub{}, nil, nil, repo, DefaultSimConfig())
+
+	set := svc.SimSettings()
+	if set.TickMs != 1000 || set.RampUpCPerSec != RampUpCPerSec || set.MaxSafeC != MaxSafeC {
+		t.Fatalf("unexpected defaults: %+v", set)
+	}
+
+	set.RampUpCPerSec = 10
+	set.TickMs = 250
+	if err := svc.UpdateSimSettings(context.Background(), set); err != nil {
+		t.Fatalf("UpdateSimSettings: %v", err)
+	}
+	if repo.saves != 1 || repo.saved != set {
+		t.Fatalf("settings not persisted: %+v", repo.saved)
+	}
+	if svc.Speed().Tick != 250*time.Millisecond {
+		t.Fatalf("tick should change immediately, got %s", svc.Speed().Tick)
+	}
+	if svc.cfg.Physics.RampUpCPerSec != RampUpCPerSec {
+		t.Fatal("physics must not change before the next tick")
+	}
+
+	svc.tick(context.Background(), now)
+	if got := states.saves[0].CurrentTempC; got != st.CurrentTempC+10 {
+		t.Fatalf("expected a 10 °C/s ramp, got %.2f -> %.2f", st.CurrentTempC, got)
+	}
+}
+
+func TestUpdateSimSettings_RejectsInvalid(t *testing.T) {
+	repo := &settingsRepoStub{}
+	svc := NewSimulatorServiceWithConfig(&simStateRepoStub{}, &simEventRepoStub{}, nil, nil, repo, DefaultSimConfig())
+	base := svc.SimSettings()
+
+	for name, mutate := range map[string]func(*models.SimSettings){
+		"tick":     func(s *models.SimSettings) { s.TickMs = 1 },
+		"max safe": func(s *models.SimSettings) { s.MaxSafeC = s.AmbientC },
+		"ramp":     func(s *models.SimSettings) { s.RampDownCPerSec = 0 },
+		"noise":    func(s *models.SimSettings) { s.NoiseStdDevC = -1 },
+	} {
+		set := base
+		mutate(&set)
+		if err := svc.UpdateSimSettings(context.Background(), set); !errors.Is(err, ErrInvalidSimSettings) {
+			t.Fatalf("%s: expected ErrInvalidSimSettings, got %v", name, err)
+		}
+	}
+	if repo.saves != 0 {
+		t.Fatalf("invalid settings must not be persisted")
+	}
+}
+
+func TestRestoreSettings_OverridesConfig(t *testing.T) {
+	saved := NewSimulatorService(&simStateRepoStub{}, &simEventRepoStub{}).SimSettings()
+	saved.AmbientC = 18
+	saved.TickMs = 200
+	repo := &settingsRepoStub{saved: saved}
+	svc := NewSimulatorServiceWithConfig(&simStateRepoStub{}, &simEventRepoStub{}, nil, nil, repo, DefaultSimConfig())
+
+	svc.restoreSettings(context.Background())
+
+	if got := svc.SimSettings(); got != saved {
+		t.Fatalf("expected restored settings %+v, got %+v", saved, got)
+	}
+}
+
+func TestCheckReadiness_UsesLiveLimits(t *testing.T) {
+	st := models.FurnaceState{ID: 1, Mode: ModeStandby, IsRunning: true}
+	svc := NewFurnaceService(&fakeStateRepo{loadResp: st}, &localEventRepo{})
+	svc.limits = func() PhysicsConfig { return PhysicsConfig{AmbientC: 25, MaxSafeC: 600} }
+
+	r, err := svc.CheckReadiness(context.Background(), 800, 600)
+	if err != nil {
+		t.Fatalf("CheckReadiness: %v", err)
+	}
+	if r.Ready || len(r.Blockers) != 1 || r.Blockers[0].Code != BlockerAboveMaxSafe {
+		t.Fatalf("expected %s against the lowered limit, got %+v", BlockerAboveMaxSafe, r)
+	}
+}
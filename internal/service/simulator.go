@@ -3,6 +3,10 @@ package service
 import (
 	"context"
 	"controlling_furnace/internal/models"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"controlling_furnace/internal/repository"
@@ -11,6 +15,7 @@ import (
 )
 
 // ----------- Simulation constants -----------
+// Defaults for PhysicsConfig; SoakToleranceC is fixed.
 const (
 	AmbientC          = 25.0   // ambient temperature °C
 	MaxSafeC          = 1000.0 // overheat threshold °C
@@ -29,109 +34,401 @@ const (
 
 // SimulatorService updates furnace state over time.
 type SimulatorService struct {
-	stateRepo repository.StateRepo
+	state     *StateManager
 	eventRepo repository.EventRepo
+	runRepo   repository.RunRepo // optional; soak statistics stay in memory when nil
+
+	telemetryRepo repository.TelemetryRepo   // optional; samples are dropped when nil
+	settingsRepo  repository.SimSettingsRepo // optional; runtime settings are not persisted when nil
+	healthRepo    repository.HealthRepo      // optional; heater wear is not tracked when nil
+	sampleRepo    repository.SampleRepo      // optional; temperature history is not kept when nil
+	alertRepo     repository.AlertRepo       // optional; settings previews skip the alert rules when nil
+	bus           *StateBroker               // optional; saved states are not published when nil
+	uow           repository.UnitOfWork      // commits a step's state and events together; two writes when nil
+
+	cfg     SimConfig
+	sensor  *sensorModel
+	ambient *ambientModel
+	faults  *faultSet
+	charge  chargeSlot
+	run     *runTracker           // record of the active run
+	stats   *liveStats            // inputs of the derived state fields; see derive
+	wearMu  sync.Mutex            // guards health; ReplaceElements runs outside the loop
+	health  *models.FurnaceHealth // wear counters, loaded on first use
+
+	lastTick atomic.Int64 // Unix nanoseconds of the last tick of Run; see LastTick
+
+	speedMu sync.RWMutex
+	speed   Speed
+	retick  chan time.Duration
+
+	booting bool                  // set by Run until the first tick has seen the stored state
+	soak    soakCountdown         // fraction of a second counted toward RemainingSeconds
+	room    float64               // °C the chamber converges to on this step; see updateRoom
+	now     func() time.Time      // time source for Run's ticks; also starts the timeline when Step finds no state
+	newID   func() string         // event IDs
+	events  []models.FurnaceEvent // logged by the current step; see emit
+
+	settingsMu sync.RWMutex
+	published  SimConfig  // cfg as seen by API callers, including pending changes
+	pending    *SimConfig // applied by the loop at the start of the next tick
+
+	ambientOverride *float64 // room temperature set through SetAmbient
 }
 
 // NewSimulatorService returns a simulator with defaults.
 func NewSimulatorService(stateRepo repository.StateRepo, eventRepo repository.EventRepo) *SimulatorService {
+	return NewSimulatorServiceWithConfig(stateRepo, eventRepo, nil, nil, nil, DefaultSimConfig())
+}
+
+// NewSimulatorServiceWithConfig returns a simulator using cfg that records
+// run statistics in runRepo and channel samples in telemetryRepo, and keeps
+// runtime settings changes in settingsRepo.
+func NewSimulatorServiceWithConfig(
+	stateRepo repository.StateRepo,
+	eventRepo repository.EventRepo,
+	runRepo repository.RunRepo,
+	telemetryRepo repository.TelemetryRepo,
+	settingsRepo repository.SimSettingsRepo,
+	cfg SimConfig,
+) *SimulatorService {
+	cfg.Physics = cfg.Physics.withDefaults()
 	return &SimulatorService{
-		stateRepo: stateRepo,
-		eventRepo: eventRepo,
+		state:         managedState(stateRepo),
+		eventRepo:     eventRepo,
+		runRepo:       runRepo,
+		telemetryRepo: telemetryRepo,
+		settingsRepo:  settingsRepo,
+		cfg:           cfg,
+		published:     cfg,
+		sensor:        newSensorModel(cfg.Sensor),
+		ambient:       newAmbientModel(cfg.Ambient, cfg.Sensor.Seed+1),
+		faults:        newFaultSet(),
+		speed:         Speed{Tick: cfg.Tick, TimeScale: cfg.TimeScale}.withDefaults(),
+		retick:        make(chan time.Duration, 1),
+		now:           time.Now,
+		newID:         uuid.NewString,
+		room:          cfg.Physics.AmbientC,
+		stats:         &liveStats{},
 	}
 }
 
-// Run ticks at the given interval until ctx is canceled.
+// Run ticks until ctx is canceled. A positive tick overrides the configured
+// interval; SetSpeed may change it while running.
 func (s *SimulatorService) Run(ctx context.Context, tick time.Duration) {
+	s.speedMu.Lock()
+	if tick > 0 {
+		s.speed.Tick = tick
+	}
+	s.speedMu.Unlock()
+	s.restoreSettings(ctx)
+	tick = s.Speed().Tick
+	s.booting = true
+
 	t := time.NewTicker(tick)
 	defer t.Stop()
 	for {
 		select {
 		case <-ctx.Done():
+			s.shutdown(s.now())
 			return
-		case now := <-t.C:
-			st, err := s.stateRepo.Load(ctx)
-			if err != nil {
-				continue
-			}
-			// Initialize state if empty
-			if st.ID == 0 {
-				st = models.FurnaceState{
-					ID:           1,
-					Mode:         ModeStandby,
-					CurrentTempC: AmbientC,
-					IsRunning:    false,
-					UpdatedAt:    now.UTC(),
-				}
-				_ = s.stateRepo.Save(ctx, st)
-				continue
-			}
-			// time passed since last update
-			elapsed := now.Sub(st.UpdatedAt).Seconds()
-			if elapsed < 1 {
-				// less than 1s → skip until more time passes
-				continue
-			}
+		case d := <-s.retick:
+			t.Reset(d)
+		case <-t.C:
+			s.tick(ctx, s.now())
+			s.lastTick.Store(time.Now().UnixNano())
+		}
+	}
+}
 
-			changed := false
+// LastTick returns when Run last completed a tick, or the zero time if it
+// has not ticked.
+func (s *SimulatorService) LastTick() time.Time {
+	if ns := s.lastTick.Load(); ns != 0 {
+		return time.Unix(0, ns)
+	}
+	return time.Time{}
+}
 
-			// If not running → drift to ambient
-			if !st.IsRunning {
-				if s.driftToAmbient(&st, elapsed) {
-					changed = true
-				}
-				if changed {
-					st.UpdatedAt = now.UTC()
-					_ = s.stateRepo.Save(ctx, st)
-				}
-				continue
-			}
+// tick loads the state, advances the simulation to now and saves the
+// result if anything changed.
+func (s *SimulatorService) tick(ctx context.Context, now time.Time) {
+	s.step(ctx, now, false)
+}
 
-			// --------------------
-			// When running
-			// --------------------
-			switch st.Mode {
-			case ModeHeat:
-				if s.handleHeat(ctx, &st, elapsed, now) {
-					changed = true
-				}
-			case ModeCool:
-				if s.handleCooling(&st, elapsed, RampDownCPerSec) {
-					changed = true
-				}
-			case ModeStandby:
-				if s.handleCooling(&st, elapsed, StandbyCoolPerSec) {
-					changed = true
-				}
-			default:
-				// unknown mode → treat like standby
-				if s.handleCooling(&st, elapsed, StandbyCoolPerSec) {
+// step does the work of tick. With force set it also advances by less than
+// a simulated second and always saves. The whole step runs as one state
+// update, so furnace commands wait for it instead of being overwritten. It
+// returns the resulting state and false if the state could not be loaded
+// or saved.
+func (s *SimulatorService) step(ctx context.Context, now time.Time, force bool) (models.FurnaceState, bool) {
+	s.applyPendingSettings()
+	s.updateRoom(now)
+	phys := s.cfg.Physics
+
+	saved, advanced := false, false
+	st, err := s.state.updateWith(ctx, func(st *models.FurnaceState) error {
+		// Initialize state if empty
+		if st.ID == 0 {
+			*st = initialState(phys, now)
+			s.booting = false
+			saved = true
+			return nil
+		}
+
+		gap := now.Sub(st.UpdatedAt)
+		changed := false
+		if down := s.downtime(ctx, *st, gap, now); down > 0 {
+			// nothing ran while we were down: the chamber only cooled
+			changed = s.driftToAmbient(st, down.Seconds())
+			gap -= down
+			force = true
+		}
+		if !force && gap < s.minStep() {
+			// an early or duplicate tick; its time is simulated with the next one
+			return errStateUnchanged
+		}
+		// simulated time passed since last update
+		elapsed := gap.Seconds() * s.timeScale()
+
+		advanced = true
+		if s.advance(ctx, st, elapsed, now) {
+			changed = true
+		}
+		if !changed && !force {
+			return errStateUnchanged
+		}
+		st.UpdatedAt = now.UTC()
+		saved = true
+		return nil
+	}, s.saveStep)
+	if err != nil {
+		s.dropStep()
+		return st, false
+	}
+	s.finishStep(ctx, st, now, advanced)
+	if saved {
+		s.publish(st)
+	}
+	return st, true
+}
+
+// Step advances the simulation by exactly dt of simulated time, ignoring the
+// wall clock, the ticker and the time scale. The stored UpdatedAt moves
+// forward by dt, so repeated Steps replay the same timeline. It must not be
+// called while Run is active.
+func (s *SimulatorService) Step(ctx context.Context, dt time.Duration) error {
+	if dt <= 0 {
+		return fmt.Errorf("%w: step must be positive", ErrInvalidSpeed)
+	}
+	s.applyPendingSettings()
+
+	st, err := s.state.updateWith(ctx, func(st *models.FurnaceState) error {
+		if st.ID == 0 {
+			*st = initialState(s.cfg.Physics, s.now())
+		}
+		now := st.UpdatedAt.Add(dt)
+		s.updateRoom(now)
+		s.advance(ctx, st, dt.Seconds(), now)
+		st.UpdatedAt = now.UTC()
+		return nil
+	}, s.saveStep)
+	if err != nil {
+		s.dropStep()
+		return err
+	}
+	s.finishStep(ctx, st, st.UpdatedAt, true)
+	s.publish(st)
+	return nil
+}
+
+// publish hands a saved state to subscribers with its derived fields
+// filled in, so live feeds match GetState.
+func (s *SimulatorService) publish(st models.FurnaceState) {
+	if s.bus != nil {
+		derive(&st, s.stats, s)
+		s.bus.Publish(st)
+	}
+}
+
+func initialState(phys PhysicsConfig, now time.Time) models.FurnaceState {
+	return models.FurnaceState{
+		ID:            1,
+		Mode:          ModeStandby,
+		CurrentTempC:  phys.AmbientC,
+		MeasuredTempC: phys.AmbientC,
+		AmbientTempC:  phys.AmbientC,
+		O2PPM:         AirO2PPM,
+		IsRunning:     false,
+		UpdatedAt:     now.UTC(),
+	}
+}
+
+// advance runs the physics, sensor and bookkeeping for elapsed simulated
+// seconds ending at now. Returns true if st changed.
+func (s *SimulatorService) advance(ctx context.Context, st *models.FurnaceState, elapsed float64, now time.Time) bool {
+	phys := s.cfg.Physics
+	changed := s.reconcileFaults(ctx, st, now)
+	if s.reconcileKeepWarm(st, now) {
+		changed = true
+	}
+	prevTempC, prevMeasuredC := st.CurrentTempC, st.MeasuredTempC
+	if s.exchangeCharge(ctx, st, elapsed, now) {
+		changed = true
+	}
+
+	if !st.IsRunning || s.faults.has(FaultPowerLoss) {
+		// Not running (or no power) → drift to ambient
+		if s.driftToAmbient(st, elapsed) {
+			changed = true
+		}
+	} else {
+		switch st.Mode {
+		case ModeHeat:
+			if s.faults.has(FaultHeaterFailure) {
+				// elements are dead: the chamber loses heat as in standby
+				if s.handleCooling(st, elapsed, phys.StandbyCoolPerSec) {
 					changed = true
 				}
+			} else if s.handleHeat(ctx, st, elapsed, now) {
+				changed = true
 			}
-
-			// Overheat detection
-			if s.detectAndLogOverheat(ctx, &st, now) {
+		case ModeCool:
+			if s.handleCooling(st, elapsed, phys.RampDownCPerSec) {
 				changed = true
 			}
-
-			if changed {
-				st.UpdatedAt = now.UTC()
-				_ = s.stateRepo.Save(ctx, st)
+		case ModeStandby:
+			if s.handleStandby(ctx, st, elapsed) {
+				changed = true
+			}
+		default:
+			// unknown mode → treat like standby
+			if s.handleCooling(st, elapsed, phys.StandbyCoolPerSec) {
+				changed = true
 			}
 		}
+
+		s.trackWear(ctx, st, elapsed, now)
+	}
+
+	// Overheat detection; a stopped furnace cooling down clears the alarm too
+	if s.detectAndLogOverheat(ctx, st, now) {
+		changed = true
+	}
+
+	if s.measure(st, elapsed) {
+		changed = true
+	}
+	if s.checkRateOfRise(ctx, st, prevMeasuredC, elapsed, now) {
+		changed = true
+	}
+	if s.measureAmbient(st, elapsed) {
+		changed = true
+	}
+	if s.advanceAtmosphere(st, elapsed) {
+		changed = true
+	}
+	if s.checkO2(ctx, st, now) {
+		changed = true
+	}
+	if s.meterEnergy(st, prevTempC, elapsed) {
+		changed = true
+	}
+	if s.recordRun(ctx, st, elapsed, now) {
+		changed = true
+	}
+
+	return changed
+}
+
+// emit queues an event for the current step. A step can log several (a
+// mode change, an alarm, a fault); they are written with one AppendBatch
+// when the step's state is saved (see saveStep), which keeps per-event
+// inserts out of the tick. Events from downtime, logged just before
+// advance, join the same batch.
+func (s *SimulatorService) emit(e models.FurnaceEvent) {
+	s.events = append(s.events, e)
+}
+
+// saveStep stores the state of a step with the events it queued, in one
+// transaction when the repositories support it, so a failed save leaves
+// no events describing a state that was rolled back.
+func (s *SimulatorService) saveStep(ctx context.Context, st models.FurnaceState) error {
+	if s.uow == nil {
+		if err := s.state.repo.Save(ctx, st); err != nil {
+			return err
+		}
+		s.flushEvents(ctx)
+		return nil
 	}
+	err := s.uow.Do(ctx, func(tx repository.Tx) error {
+		if err := tx.StateRepo.Save(ctx, st); err != nil {
+			return err
+		}
+		if len(s.events) == 0 {
+			return nil
+		}
+		return correlate(tx.EventRepo).AppendBatch(ctx, s.events)
+	})
+	if err == nil {
+		s.events = s.events[:0]
+	}
+	return err
+}
+
+// finishStep records the telemetry, history sample and live stats of a
+// step that was saved or left the state as it was, and writes the events
+// of the latter.
+func (s *SimulatorService) finishStep(ctx context.Context, st models.FurnaceState, now time.Time, advanced bool) {
+	s.flushEvents(ctx)
+	if !advanced {
+		return
+	}
+	s.recordTelemetry(ctx, st, now)
+	s.recordSample(ctx, st, now)
+	soaked := 0.0
+	if s.run != nil && s.run.run.RunID == st.RunID {
+		soaked = s.run.run.SoakSeconds
+	}
+	s.stats.observe(st, soaked)
+}
+
+// dropStep forgets the events of a step whose state could not be saved;
+// the next step simulates the same time again.
+func (s *SimulatorService) dropStep() {
+	s.events = s.events[:0]
+}
+
+// flushEvents appends the queued events, dropping them if that fails.
+func (s *SimulatorService) flushEvents(ctx context.Context) {
+	if len(s.events) == 0 {
+		return
+	}
+	_ = s.eventRepo.AppendBatch(ctx, s.events)
+	s.events = s.events[:0]
 }
 
 // ... existing code ...
 
-// driftToAmbient cools toward ambient when not running. Returns true if temp changed.
-func (s *SimulatorService) driftToAmbient(st *models.FurnaceState, elapsed float64) bool {
-	if st.CurrentTempC > AmbientC {
-		st.CurrentTempC = maxFloat(st.CurrentTempC-StandbyCoolPerSec*elapsed, AmbientC)
-		return true
+// measure takes a sensor reading of the current true temperature, honoring
+// injected sensor faults. Returns true if the reported value changed.
+func (s *SimulatorService) measure(st *models.FurnaceState, elapsed float64) bool {
+	prev := st.MeasuredTempC
+	switch {
+	case s.faults.has(FaultThermocoupleBreak):
+		st.MeasuredTempC = openCircuitReadingC
+	case s.faults.has(FaultStuckSensor):
+		// reading frozen at its last value
+	default:
+		st.MeasuredTempC = s.sensor.read(st.CurrentTempC, elapsed)
 	}
-	return false
+	return st.MeasuredTempC != prev
+}
+
+// driftToAmbient moves the chamber toward the room temperature at the
+// passive rate when not running. Returns true if temp changed.
+func (s *SimulatorService) driftToAmbient(st *models.FurnaceState, elapsed float64) bool {
+	return s.handleCooling(st, elapsed, s.cfg.Physics.StandbyCoolPerSec)
 }
 
 // handleHeat advances temperature toward target and decrements soak timer.
@@ -146,11 +443,12 @@ func (s *SimulatorService) handleHeat(ctx context.Context, st *models.FurnaceSta
 	// Ramp up if below (target - tolerance)
 	if prevTemp < st.TargetTempC-SoakToleranceC {
 		// Compute new temperature and time to reach target based on previous temp.
-		timeToTarget := (st.TargetTempC - prevTemp) / RampUpCPerSec
+		rate := s.rampUpRate(ctx)
+		timeToTarget := (st.TargetTempC - prevTemp) / rate
 		if timeToTarget < 0 {
 			timeToTarget = 0
 		}
-		st.CurrentTempC = prevTemp + RampUpCPerSec*elapsed
+		st.CurrentTempC = prevTemp + rate*elapsed
 		if st.CurrentTempC > st.TargetTempC {
 			st.CurrentTempC = st.TargetTempC
 		}
@@ -172,22 +470,22 @@ func (s *SimulatorService) handleHeat(ctx context.Context, st *models.FurnaceSta
 
 	// Countdown during soak
 	if st.RemainingSeconds > 0 && soakElapsed > 0 {
-		dec := int(soakElapsed) // whole seconds at/near target
-		if dec >= 1 {
+		// the fraction is kept in memory, so the time must not be simulated twice
+		changed = true
+		if dec := s.soak.take(st.RemainingSeconds, soakElapsed); dec >= 1 {
 			if st.RemainingSeconds > dec {
 				st.RemainingSeconds -= dec
 			} else {
 				st.RemainingSeconds = 0
 				st.Mode = ModeCool
-				_ = s.eventRepo.Append(ctx, models.FurnaceEvent{
-					EventID:     uuid.NewString(),
+				s.emit(models.FurnaceEvent{
+					EventID:     s.newID(),
 					OccurredAt:  now.UTC(),
 					Type:        "MODE_CHANGE",
 					Description: "Duration elapsed; switched to COOL",
-					Metadata:    map[string]any{"from": ModeHeat, "to": ModeCool},
+					Metadata:    withRunID(map[string]any{"from": ModeHeat, "to": ModeCool}, st.RunID),
 				})
 			}
-			changed = true
 		}
 	}
 
@@ -198,38 +496,62 @@ func (s *SimulatorService) handleHeat(ctx context.Context, st *models.FurnaceSta
 	return changed
 }
 
-// handleCooling cools toward ambient by a given rate. Returns true if temp changed.
+// handleCooling cools toward the room temperature by a given rate. A chamber
+// colder than the room, e.g. after the room warmed up, warms passively.
+// Returns true if temp changed.
 func (s *SimulatorService) handleCooling(st *models.FurnaceState, elapsed float64, ratePerSec float64) bool {
-	if st.CurrentTempC > AmbientC {
-		st.CurrentTempC = maxFloat(st.CurrentTempC-ratePerSec*elapsed, AmbientC)
-		return true
+	switch room := s.room; {
+	case st.CurrentTempC > room:
+		st.CurrentTempC = maxFloat(st.CurrentTempC-ratePerSec*elapsed, room)
+	case st.CurrentTempC < room:
+		st.CurrentTempC = math.Min(st.CurrentTempC+s.cfg.Physics.StandbyCoolPerSec*elapsed, room)
+	default:
+		return false
 	}
-	return false
+	return true
 }
 
-// detectAndLogOverheat appends an error event and sets error code if needed.
-// Returns true if state changed (error code added).
+// detectAndLogOverheat raises OVERHEAT and logs an ERROR event on every
+// tick the chamber is above MaxSafeC, and clears the code once it is back at
+// or below, which closes the overheat incident. Returns true if the state
+// changed.
 func (s *SimulatorService) detectAndLogOverheat(ctx context.Context, st *models.FurnaceState, now time.Time) bool {
-	stateChanged := false
-	if st.CurrentTempC > MaxSafeC {
-		if !hasString(st.ErrorCodes, "OVERHEAT") {
-			st.ErrorCodes = append(st.ErrorCodes, "OVERHEAT")
-			stateChanged = true
-		}
-		_ = s.eventRepo.Append(ctx, models.FurnaceEvent{
-			EventID:     uuid.NewString(),
+	maxSafe := s.cfg.Physics.MaxSafeC
+	active := hasString(st.ErrorCodes, AlarmOverheat)
+	switch {
+	case st.CurrentTempC > maxSafe:
+		if !active {
+			st.ErrorCodes = append(st.ErrorCodes, AlarmOverheat)
+		}
+		s.emit(models.FurnaceEvent{
+			EventID:     s.newID(),
 			OccurredAt:  now.UTC(),
 			Type:        "ERROR",
 			Description: "Overheat detected",
-			Metadata: map[string]any{
+			Metadata: withRunID(map[string]any{
 				"temp_c":    st.CurrentTempC,
-				"max_safe":  MaxSafeC,
+				"max_safe":  maxSafe,
 				"mode":      st.Mode,
 				"isRunning": st.IsRunning,
-			},
+			}, st.RunID),
+		})
+		return !active
+	case active:
+		st.ErrorCodes = removeString(st.ErrorCodes, AlarmOverheat)
+		s.emit(models.FurnaceEvent{
+			EventID:     s.newID(),
+			OccurredAt:  now.UTC(),
+			Type:        "ALARM_CLEARED",
+			Description: "Temperature back within limit",
+			Metadata: withRunID(map[string]any{
+				"alarm":    AlarmOverheat,
+				"temp_c":   st.CurrentTempC,
+				"max_safe": maxSafe,
+			}, st.RunID),
 		})
+		return true
 	}
-	return stateChanged
+	return false
 }
 
 // helpers
@@ -248,3 +570,13 @@ func hasString(ss []string, want string) bool {
 	}
 	return false
 }
+
+func removeString(ss []string, drop string) []string {
+	out := ss[:0]
+	for _, s := range ss {
+		if s != drop {
+			out = append(out, s)
+		}
+	}
+	return out
+}
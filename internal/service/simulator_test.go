@@ -2,10 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
 )
 
 // ---- Test doubles ----
@@ -14,9 +16,13 @@ import (
 type simStateRepoStub struct {
 	loadResp models.FurnaceState
 	saves    []models.FurnaceState
+	saveErr  error
 }
 
 func (s *simStateRepoStub) Save(ctx context.Context, st models.FurnaceState) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
 	s.saves = append(s.saves, st)
 	return nil
 }
@@ -27,15 +33,24 @@ func (s *simStateRepoStub) Load(ctx context.Context) (models.FurnaceState, error
 // simEventRepoStub is a minimal stub for repository.EventRepo.
 type simEventRepoStub struct {
 	appends []models.FurnaceEvent
+	batches int
 }
 
 func (e *simEventRepoStub) Append(ctx context.Context, ev models.FurnaceEvent) error {
 	e.appends = append(e.appends, ev)
 	return nil
 }
+func (e *simEventRepoStub) AppendBatch(ctx context.Context, evs []models.FurnaceEvent) error {
+	e.appends = append(e.appends, evs...)
+	e.batches++
+	return nil
+}
 func (e *simEventRepoStub) List(ctx context.Context, from, to time.Time, typ string) ([]models.FurnaceEvent, error) {
 	return nil, nil
 }
+func (e *simEventRepoStub) Query(ctx context.Context, q repository.EventQuery) ([]models.FurnaceEvent, error) {
+	return nil, nil
+}
 
 // ---- Tests ----
 
@@ -127,6 +142,7 @@ func TestHandleHeat_RampTowardTargetAndSoakCountdown(t *testing.T) {
 		ev.appends = nil
 		st := models.FurnaceState{Mode: ModeHeat, CurrentTempC: 110, TargetTempC: 110, RemainingSeconds: 1}
 		_ = svc.handleHeat(ctx, &st, 1.2, time.Now())
+		svc.flushEvents(ctx)
 		if st.Mode != ModeCool {
 			t.Fatalf("expected COOL, got %q", st.Mode)
 		}
@@ -143,6 +159,7 @@ func TestDetectAndLogOverheat_SetsErrorOnceAndAlwaysLogs(t *testing.T) {
 
 	st := models.FurnaceState{Mode: ModeHeat, IsRunning: true, CurrentTempC: MaxSafeC + 10}
 	changed := svc.detectAndLogOverheat(ctx, &st, time.Now())
+	svc.flushEvents(ctx)
 	if !changed || !containsStr(st.ErrorCodes, "OVERHEAT") {
 		t.Fatalf("OVERHEAT not set properly")
 	}
@@ -152,6 +169,7 @@ func TestDetectAndLogOverheat_SetsErrorOnceAndAlwaysLogs(t *testing.T) {
 
 	// Second detection
 	changed = svc.detectAndLogOverheat(ctx, &st, time.Now().Add(time.Second))
+	svc.flushEvents(ctx)
 	if changed {
 		t.Fatalf("did not expect state change again")
 	}
@@ -160,6 +178,27 @@ func TestDetectAndLogOverheat_SetsErrorOnceAndAlwaysLogs(t *testing.T) {
 	}
 }
 
+func TestDetectAndLogOverheat_ClearsBelowLimit(t *testing.T) {
+	ctx := context.Background()
+	ev := &simEventRepoStub{}
+	svc := NewSimulatorService(&simStateRepoStub{}, ev)
+
+	st := models.FurnaceState{Mode: ModeStandby, CurrentTempC: MaxSafeC, ErrorCodes: []string{"OVERHEAT", "SENSOR_FAULT"}}
+	if !svc.detectAndLogOverheat(ctx, &st, time.Now()) {
+		t.Fatal("expected the alarm to clear at MaxSafeC")
+	}
+	svc.flushEvents(ctx)
+	if containsStr(st.ErrorCodes, "OVERHEAT") || !containsStr(st.ErrorCodes, "SENSOR_FAULT") {
+		t.Fatalf("expected only OVERHEAT cleared, got %v", st.ErrorCodes)
+	}
+	if len(ev.appends) != 1 || ev.appends[0].Type != "ALARM_CLEARED" || ev.appends[0].Metadata.(map[string]any)["alarm"] != "OVERHEAT" {
+		t.Fatalf("expected one ALARM_CLEARED event, got %+v", ev.appends)
+	}
+	if svc.detectAndLogOverheat(ctx, &st, time.Now()) || len(svc.events) != 0 {
+		t.Fatal("expected nothing more once cleared")
+	}
+}
+
 func containsStr(ss []string, w string) bool {
 	for _, s := range ss {
 		if s == w {
@@ -168,3 +207,181 @@ func containsStr(ss []string, w string) bool {
 	}
 	return false
 }
+
+func TestMeasure_ReportsSensorReading(t *testing.T) {
+	svc := NewSimulatorService(&simStateRepoStub{}, &simEventRepoStub{})
+	st := models.FurnaceState{CurrentTempC: 300, MeasuredTempC: 25}
+	if !svc.measure(&st, 1) || st.MeasuredTempC != 300 {
+		t.Fatalf("perfect sensor should report true temp, got %.2f", st.MeasuredTempC)
+	}
+
+	noisy := NewSimulatorServiceWithConfig(&simStateRepoStub{}, &simEventRepoStub{}, nil, nil, nil, SimConfig{
+		Sensor: SensorConfig{NoiseStdDevC: 1, Seed: 7},
+	})
+	st = models.FurnaceState{CurrentTempC: 300, MeasuredTempC: 300}
+	noisy.measure(&st, 1)
+	if st.CurrentTempC != 300 {
+		t.Fatalf("measurement must not alter true temperature, got %.2f", st.CurrentTempC)
+	}
+	if st.MeasuredTempC == 300 {
+		t.Fatalf("expected noisy reading to differ from true temperature")
+	}
+}
+
+func TestShutdown_FlushesPartialTick(t *testing.T) {
+	now := time.Now()
+	st := heatingState(now)
+	st.UpdatedAt = now.Add(-500 * time.Millisecond)
+	states := &simStateRepoStub{loadResp: st}
+	events := &simEventRepoStub{}
+	svc := NewSimulatorService(states, events)
+
+	svc.shutdown(now)
+
+	if len(states.saves) != 1 {
+		t.Fatalf("expected a final save, got %d", len(states.saves))
+	}
+	saved := states.saves[0]
+	if !saved.UpdatedAt.Equal(now.UTC()) || saved.CurrentTempC <= st.CurrentTempC {
+		t.Fatalf("expected the partial tick to be applied, got %+v", saved)
+	}
+	last := events.appends[len(events.appends)-1]
+	if last.Type != "SHUTDOWN" {
+		t.Fatalf("expected SHUTDOWN event, got %+v", events.appends)
+	}
+	if meta, _ := last.Metadata.(map[string]any); meta["run_id"] != "run-1" {
+		t.Fatalf("unexpected metadata: %v", last.Metadata)
+	}
+}
+
+func TestRun_CanceledStillFlushes(t *testing.T) {
+	states := &simStateRepoStub{loadResp: heatingState(time.Now())}
+	events := &simEventRepoStub{}
+	svc := NewSimulatorService(states, events)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	svc.Run(ctx, time.Hour)
+
+	if len(states.saves) != 1 || events.appends[len(events.appends)-1].Type != "SHUTDOWN" {
+		t.Fatalf("expected a final save and SHUTDOWN, got %d saves, events %+v", len(states.saves), events.appends)
+	}
+}
+
+func TestStep_AppendsTheStepsEventsInOneBatch(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	// soaking above the limit: the soak ends and the chamber overheats on
+	// the same step
+	st := models.FurnaceState{ID: 1, Mode: ModeHeat, IsRunning: true, CurrentTempC: MaxSafeC + 10, MeasuredTempC: MaxSafeC + 10,
+		TargetTempC: MaxSafeC + 10, RemainingSeconds: 1, UpdatedAt: start}
+	events := &simEventRepoStub{}
+	svc := NewSimulatorService(&simStateRepoStub{loadResp: st}, events)
+
+	if err := svc.Step(context.Background(), 2*time.Second); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	if events.batches != 1 || len(events.appends) != 2 || events.appends[0].Type != "MODE_CHANGE" || events.appends[1].Type != "ERROR" {
+		t.Fatalf("expected MODE_CHANGE and ERROR in one batch, got %d batches: %+v", events.batches, events.appends)
+	}
+	if len(svc.events) != 0 {
+		t.Fatalf("expected the queue drained, %d left", len(svc.events))
+	}
+}
+
+func TestStep_FailedSaveWritesNoEvents(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	st := models.FurnaceState{ID: 1, Mode: ModeHeat, IsRunning: true, CurrentTempC: MaxSafeC + 10, MeasuredTempC: MaxSafeC + 10,
+		TargetTempC: MaxSafeC + 10, RemainingSeconds: 1, UpdatedAt: start}
+	states := &simStateRepoStub{loadResp: st, saveErr: errors.New("disk full")}
+	events := &simEventRepoStub{}
+	svc := NewSimulatorService(states, events)
+
+	if err := svc.Step(context.Background(), 2*time.Second); err == nil {
+		t.Fatal("expected the failed save to fail Step")
+	}
+	if len(events.appends) != 0 || len(svc.events) != 0 {
+		t.Fatalf("events of an unsaved step: logged %+v, queued %+v", events.appends, svc.events)
+	}
+
+	states.saveErr = nil
+	if err := svc.Step(context.Background(), 2*time.Second); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	if len(states.saves) != 1 || len(events.appends) != 2 {
+		t.Fatalf("expected the retried step saved with its events, got %d saves, events %+v", len(states.saves), events.appends)
+	}
+}
+
+func TestStep_StateAndEventsCommitTogether(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewInMemory()
+	svc := NewServiceWithConfig(repos, DefaultConfig())
+	if err := svc.Furnace.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Furnace.SetMode(ctx, ModeParams{Mode: ModeHeat, TargetTempC: 60, DurationSec: 1}); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := repos.StateRepo.Load(ctx)
+	sim := svc.Simulator.(*SimulatorService)
+
+	// the soak ends within the step, but its MODE_CHANGE cannot be logged
+	sim.uow = failingEvents{repos.UnitOfWork}
+	if err := sim.Step(ctx, 10*time.Minute); err == nil {
+		t.Fatal("expected the failed append to fail Step")
+	}
+	if stored, _ := repos.StateRepo.Load(ctx); !stored.UpdatedAt.Equal(before.UpdatedAt) || stored.Mode != ModeHeat {
+		t.Fatalf("state saved without its events: %+v", stored)
+	}
+
+	sim.uow = repos.UnitOfWork
+	if err := sim.Step(ctx, 10*time.Minute); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	events, _ := repos.EventRepo.Query(ctx, repository.EventQuery{Type: "MODE_CHANGE"})
+	if stored, _ := repos.StateRepo.Load(ctx); stored.Mode == ModeHeat || len(events) != 2 {
+		t.Fatalf("expected the soak end saved and logged, got %+v, events %+v", stored, events)
+	}
+}
+
+func TestStep_AdvancesExactlyDt(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	newSim := func() (*SimulatorService, *simStateRepoStub) {
+		st := heatingState(start)
+		st.UpdatedAt = start
+		states := &simStateRepoStub{loadResp: st}
+		cfg := DefaultSimConfig()
+		cfg.TimeScale = 60 // must not apply to Step
+		cfg.Sensor = SensorConfig{NoiseStdDevC: 1, Seed: 7}
+		return NewSimulatorServiceWithConfig(states, &simEventRepoStub{}, nil, nil, nil, cfg), states
+	}
+
+	a, statesA := newSim()
+	b, statesB := newSim()
+	for i := 0; i < 3; i++ {
+		for _, p := range []struct {
+			svc    *SimulatorService
+			states *simStateRepoStub
+		}{{a, statesA}, {b, statesB}} {
+			if err := p.svc.Step(context.Background(), 2*time.Second); err != nil {
+				t.Fatalf("Step: %v", err)
+			}
+			p.states.loadResp = p.states.saves[len(p.states.saves)-1]
+		}
+	}
+
+	got := statesA.loadResp
+	if !got.UpdatedAt.Equal(start.Add(6 * time.Second)) {
+		t.Fatalf("expected UpdatedAt to advance by 3*dt, got %v", got.UpdatedAt)
+	}
+	if want := 500 + RampUpCPerSec*6; got.CurrentTempC != want {
+		t.Fatalf("expected %.1f°C after 6 simulated seconds, got %.2f", want, got.CurrentTempC)
+	}
+	if other := statesB.loadResp; other.MeasuredTempC != got.MeasuredTempC || other.CurrentTempC != got.CurrentTempC {
+		t.Fatalf("identical step sequences diverged: %+v vs %+v", got, other)
+	}
+
+	if err := a.Step(context.Background(), 0); !errors.Is(err, ErrInvalidSpeed) {
+		t.Fatalf("expected ErrInvalidSpeed for zero dt, got %v", err)
+	}
+}
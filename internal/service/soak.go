@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// soakCountdown converts soak time into whole seconds off RemainingSeconds,
+// carrying the fraction over so short or uneven ticks count down at the
+// true rate.
+type soakCountdown struct {
+	carry float64 // seconds soaked but not yet counted
+	next  int     // RemainingSeconds the carry belongs to
+}
+
+// take adds soaked seconds and returns how many whole seconds to count off
+// remaining. A remaining value other than the one left by the previous
+// call means the timer was set anew, so the old fraction is dropped.
+func (c *soakCountdown) take(remaining int, soaked float64) int {
+	if remaining != c.next {
+		c.carry = 0
+	}
+	total := c.carry + soaked
+	dec := int(total + 1e-9) // tolerate float error, e.g. ten ticks of 0.1 s
+	c.carry = math.Max(total-float64(dec), 0)
+	c.next = remaining - dec
+	return dec
+}
+
+// addSoak records a reading held for weight seconds in the run's
+// time-weighted soak statistics.
+func (t *runTracker) addSoak(readingC, weight float64, within bool) {
+	r := &t.run
+	total := r.SoakSeconds + weight
+	delta := readingC - r.SoakMeanC
+	r.SoakMeanC += delta * weight / total
+	t.m2 += weight * delta * (readingC - r.SoakMeanC)
+	r.SoakSeconds = total
+	if within {
+		r.SoakWithinSeconds += weight
+	}
+	r.SoakStdDevC = math.Sqrt(t.m2 / total)
+	r.StabilityScore = r.SoakWithinSeconds / total
+}
+
+// trackSoak updates the soak statistics of st's run and raises SOAK_UNSTABLE
+// once per run when the score drops below the configured threshold.
+// The soak starts when the furnace first reaches the target band and lasts
+// for the rest of the HEAT phase, so later excursions count against it.
+// Returns true if elapsed was counted.
+func (s *SimulatorService) trackSoak(ctx context.Context, t *runTracker, st *models.FurnaceState, elapsed float64, now time.Time) bool {
+	if !st.IsRunning || st.Mode != ModeHeat || elapsed <= 0 {
+		return false
+	}
+	inBand := func(c float64) bool { return math.Abs(c-st.TargetTempC) <= SoakToleranceC }
+	if t.run.SoakSeconds == 0 && !inBand(st.CurrentTempC) {
+		return false // still ramping
+	}
+	t.addSoak(st.MeasuredTempC, elapsed, inBand(st.MeasuredTempC))
+
+	cfg := s.cfg.Soak
+	if !t.run.SoakUnstable && cfg.MinStability > 0 &&
+		t.run.SoakSeconds >= cfg.MinSeconds && t.run.StabilityScore < cfg.MinStability {
+		t.run.SoakUnstable = true
+		s.emit(models.FurnaceEvent{
+			EventID:     s.newID(),
+			OccurredAt:  now.UTC(),
+			Type:        "SOAK_UNSTABLE",
+			Description: "Soak stability below threshold",
+			Metadata: withRunID(map[string]any{
+				"stability":     t.run.StabilityScore,
+				"min_stability": cfg.MinStability,
+				"stddev_c":      t.run.SoakStdDevC,
+				"soak_s":        t.run.SoakSeconds,
+				"target_temp_c": st.TargetTempC,
+			}, st.RunID),
+		})
+	}
+	return true
+}
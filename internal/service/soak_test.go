@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// runRepoStub keeps runs in a map.
+type runRepoStub struct {
+	runs  map[string]models.Run
+	saves int
+}
+
+func (r *runRepoStub) Save(ctx context.Context, run models.Run) error {
+	if r.runs == nil {
+		r.runs = make(map[string]models.Run)
+	}
+	r.runs[run.RunID] = run
+	r.saves++
+	return nil
+}
+func (r *runRepoStub) Get(ctx context.Context, runID string) (models.Run, error) {
+	return r.runs[runID], nil
+}
+
+func TestRunTracker_WeightedSoakStats(t *testing.T) {
+	tr := &runTracker{}
+	tr.addSoak(798, 10, false)
+	tr.addSoak(800, 30, true)
+
+	if got := tr.run.StabilityScore; got != 0.75 {
+		t.Fatalf("stability = %v, want 0.75", got)
+	}
+	if got := tr.run.SoakMeanC; got != 799.5 {
+		t.Fatalf("mean = %v, want 799.5", got)
+	}
+	// weighted variance: (10*1.5² + 30*0.5²) / 40 = 0.75
+	if got := tr.run.SoakStdDevC; math.Abs(got-math.Sqrt(0.75)) > 1e-9 {
+		t.Fatalf("stddev = %v, want %v", got, math.Sqrt(0.75))
+	}
+}
+
+func TestTrackSoak_StableRunIsRecorded(t *testing.T) {
+	runs := &runRepoStub{}
+	svc := NewSimulatorServiceWithConfig(&simStateRepoStub{}, &simEventRepoStub{}, runs, nil, nil, DefaultSimConfig())
+	now := time.Now()
+	st := models.FurnaceState{Mode: ModeHeat, IsRunning: true, RunID: "run-1", TargetTempC: 800, CurrentTempC: 500, MeasuredTempC: 500}
+
+	// ramping: the run record exists but no soak time accrues
+	if svc.recordRun(context.Background(), &st, 5, now) {
+		t.Fatalf("did not expect soak time while ramping")
+	}
+	if runs.runs["run-1"].StartedAt.IsZero() {
+		t.Fatalf("expected run record to be created on first tick")
+	}
+
+	st.CurrentTempC, st.MeasuredTempC = 800, 800.5
+	for i := 0; i < 10; i++ {
+		_ = svc.recordRun(context.Background(), &st, 10, now)
+	}
+	got := runs.runs["run-1"]
+	if got.SoakSeconds != 100 || got.StabilityScore != 1 || got.SoakUnstable {
+		t.Fatalf("unexpected run: %+v", got)
+	}
+}
+
+func TestTrackSoak_RaisesUnstableOnce(t *testing.T) {
+	events := &simEventRepoStub{}
+	runs := &runRepoStub{}
+	cfg := DefaultSimConfig()
+	cfg.Soak = SoakConfig{MinStability: 0.9, MinSeconds: 30}
+	svc := NewSimulatorServiceWithConfig(&simStateRepoStub{}, events, runs, nil, nil, cfg)
+	now := time.Now()
+	st := models.FurnaceState{Mode: ModeHeat, IsRunning: true, RunID: "run-1", TargetTempC: 800, CurrentTempC: 800, MeasuredTempC: 800}
+
+	_ = svc.recordRun(context.Background(), &st, 20, now)
+	// sensor swings out of band for the rest of the soak
+	st.MeasuredTempC = 790
+	for i := 0; i < 5; i++ {
+		_ = svc.recordRun(context.Background(), &st, 10, now)
+	}
+	svc.flushEvents(context.Background())
+
+	if len(events.appends) != 1 || events.appends[0].Type != "SOAK_UNSTABLE" {
+		t.Fatalf("expected a single SOAK_UNSTABLE event, got %+v", events.appends)
+	}
+	if !runs.runs["run-1"].SoakUnstable {
+		t.Fatalf("expected run to be flagged unstable")
+	}
+}
+
+func TestTrackSoak_ResumesPersistedRun(t *testing.T) {
+	runs := &runRepoStub{runs: map[string]models.Run{
+		"run-1": {RunID: "run-1", TargetTempC: 800, SoakSeconds: 40, SoakWithinSeconds: 40, SoakMeanC: 800},
+	}}
+	svc := NewSimulatorServiceWithConfig(&simStateRepoStub{}, &simEventRepoStub{}, runs, nil, nil, DefaultSimConfig())
+	st := models.FurnaceState{Mode: ModeHeat, IsRunning: true, RunID: "run-1", TargetTempC: 800, CurrentTempC: 800, MeasuredTempC: 800}
+
+	_ = svc.recordRun(context.Background(), &st, 10, time.Now())
+
+	if got := runs.runs["run-1"].SoakSeconds; got != 50 {
+		t.Fatalf("expected soak to continue from stored record, got %v", got)
+	}
+}
+
+func TestSoakCountdown_CarriesFractions(t *testing.T) {
+	var c soakCountdown
+	counted := 0
+	for i := 0; i < 10; i++ {
+		counted += c.take(600-counted, 0.1)
+	}
+	if counted != 1 {
+		t.Fatalf("ten 0.1 s steps should count one second, got %d", counted)
+	}
+
+	// a new timer drops the old fraction
+	c.take(600-counted, 0.5)
+	if got := c.take(300, 0.6); got != 0 {
+		t.Fatalf("expected the carry to reset for a new timer, got %d", got)
+	}
+}
+
+func TestSimulator_SoakCountsDownAtNonIntegerTicks(t *testing.T) {
+	now := time.Date(2025, 9, 20, 12, 0, 0, 0, time.UTC)
+	st := heatingState(now)
+	st.CurrentTempC, st.MeasuredTempC, st.RemainingSeconds = 800, 800, 10
+	st.UpdatedAt = now
+	states := &simStateRepoStub{loadResp: st}
+	cfg := DefaultSimConfig()
+	cfg.Tick = 1500 * time.Millisecond
+	svc := NewSimulatorServiceWithConfig(states, &simEventRepoStub{}, nil, nil, nil, cfg)
+
+	for i := 0; i < 4; i++ {
+		now = now.Add(1500 * time.Millisecond)
+		svc.tick(context.Background(), now)
+		states.loadResp = states.saves[len(states.saves)-1]
+	}
+	if got := states.loadResp.RemainingSeconds; got != 4 {
+		t.Fatalf("6 s of soak should leave 4 s, got %d", got)
+	}
+}
+
+func TestSimulator_JitterTolerance(t *testing.T) {
+	now := time.Date(2025, 9, 20, 12, 0, 0, 0, time.UTC)
+	st := heatingState(now)
+	st.UpdatedAt = now
+	states := &simStateRepoStub{loadResp: st}
+	svc := NewSimulatorService(states, &simEventRepoStub{})
+
+	svc.tick(context.Background(), now.Add(500*time.Millisecond))
+	if len(states.saves) != 0 {
+		t.Fatalf("a tick half an interval early should be skipped")
+	}
+	svc.tick(context.Background(), now.Add(950*time.Millisecond))
+	if len(states.saves) != 1 {
+		t.Fatalf("a tick within the default tolerance should be simulated")
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Limits accepted by SetSpeed.
+const (
+	MinSimTick   = 10 * time.Millisecond
+	MaxTimeScale = 3600.0
+)
+
+// ErrInvalidSpeed reports a tick or time scale outside the accepted range.
+var ErrInvalidSpeed = errors.New("invalid simulation speed")
+
+// Speed controls how often the simulator ticks and how fast simulated time
+// runs relative to the wall clock. With TimeScale 60, one wall-clock second
+// advances temperatures, soak timers and drift by one simulated minute.
+type Speed struct {
+	Tick      time.Duration
+	TimeScale float64
+}
+
+// Validate checks that the speed is within range.
+func (sp Speed) Validate() error {
+	if sp.Tick < MinSimTick {
+		return fmt.Errorf("%w: tick must be >= %s", ErrInvalidSpeed, MinSimTick)
+	}
+	if sp.TimeScale <= 0 || sp.TimeScale > MaxTimeScale {
+		return fmt.Errorf("%w: time scale must be within (0, %g]", ErrInvalidSpeed, MaxTimeScale)
+	}
+	return nil
+}
+
+// withDefaults fills unset fields from DefaultSimConfig.
+func (sp Speed) withDefaults() Speed {
+	def := DefaultSimConfig()
+	if sp.Tick <= 0 {
+		sp.Tick = def.Tick
+	}
+	if sp.TimeScale <= 0 {
+		sp.TimeScale = def.TimeScale
+	}
+	return sp
+}
+
+// Speed returns the current tick and time scale.
+func (s *SimulatorService) Speed() Speed {
+	s.speedMu.RLock()
+	defer s.speedMu.RUnlock()
+	return s.speed
+}
+
+// SetSpeed changes the tick and time scale of the running simulator. A new
+// tick takes effect immediately; the new scale applies from the next tick.
+func (s *SimulatorService) SetSpeed(sp Speed) error {
+	if err := sp.Validate(); err != nil {
+		return err
+	}
+	s.speedMu.Lock()
+	retick := sp.Tick != s.speed.Tick
+	s.speed = sp
+	s.speedMu.Unlock()
+
+	if retick {
+		// keep only the latest tick; Run picks it up on its next select
+		select {
+		case <-s.retick:
+		default:
+		}
+		s.retick <- sp.Tick
+	}
+	return nil
+}
+
+// minStep is the shortest wall-clock gap a tick simulates: the tick
+// interval less the configured jitter tolerance (a tenth of the interval by
+// default). Shorter gaps are early or duplicate ticks; since the step is
+// measured from the last saved state, their time is not lost but simulated
+// with the next tick, so ticker drift does not accumulate.
+func (s *SimulatorService) minStep() time.Duration {
+	tick := s.Speed().Tick
+	tol := s.cfg.JitterTolerance
+	if tol <= 0 {
+		tol = tick / 10
+	}
+	return max(tick-tol, 0)
+}
+
+// timeScale returns the current simulated-seconds per wall-clock second.
+func (s *SimulatorService) timeScale() float64 {
+	s.speedMu.RLock()
+	defer s.speedMu.RUnlock()
+	return s.speed.TimeScale
+}
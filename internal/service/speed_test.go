@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestSetSpeed_Validates(t *testing.T) {
+	svc := NewSimulatorService(&simStateRepoStub{}, &simEventRepoStub{})
+
+	for _, sp := range []Speed{
+		{Tick: time.Millisecond, TimeScale: 1},
+		{Tick: time.Second, TimeScale: 0},
+		{Tick: time.Second, TimeScale: MaxTimeScale + 1},
+	} {
+		if err := svc.SetSpeed(sp); !errors.Is(err, ErrInvalidSpeed) {
+			t.Fatalf("SetSpeed(%+v): expected ErrInvalidSpeed, got %v", sp, err)
+		}
+	}
+	want := Speed{Tick: 100 * time.Millisecond, TimeScale: 60}
+	if err := svc.SetSpeed(want); err != nil {
+		t.Fatalf("SetSpeed: %v", err)
+	}
+	if got := svc.Speed(); got != want {
+		t.Fatalf("Speed() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTick_TimeScaleAcceleratesSimulation(t *testing.T) {
+	now := time.Now()
+	states := &simStateRepoStub{loadResp: models.FurnaceState{
+		ID: 1, Mode: ModeCool, IsRunning: true, CurrentTempC: 800, MeasuredTempC: 800,
+		UpdatedAt: now.Add(-time.Second),
+	}}
+	svc := NewSimulatorService(states, &simEventRepoStub{})
+	_ = svc.SetSpeed(Speed{Tick: time.Second, TimeScale: 60})
+
+	svc.tick(context.Background(), now)
+
+	// one wall-clock second at 60x is a simulated minute of cooling
+	want := 800 - RampDownCPerSec*60
+	if got := states.saves[0].CurrentTempC; got != want {
+		t.Fatalf("got %.2f, want %.2f", got, want)
+	}
+}
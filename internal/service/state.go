@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+// errStateUnchanged, returned by an Update function, leaves the state as it
+// was without saving it. Update then returns nil.
+var errStateUnchanged = errors.New("state unchanged")
+
+// StateManager holds the authoritative furnace state in memory. The
+// furnace commands and the simulator change it through Update, which runs
+// the whole load-modify-save under the write lock, so neither can
+// overwrite a change the other made in between. The repository only
+// persists it; it is read once, on first use.
+//
+// StateManager implements repository.StateRepo, so services constructed
+// with one share it instead of wrapping the repository again.
+type StateManager struct {
+	repo repository.StateRepo
+
+	mu     sync.RWMutex
+	st     models.FurnaceState
+	loaded bool
+}
+
+func NewStateManager(repo repository.StateRepo) *StateManager {
+	return &StateManager{repo: repo}
+}
+
+// managedState returns repo if it is a StateManager already, so the
+// services built by NewServiceWithConfig share one, and a new StateManager
+// over repo otherwise.
+func managedState(repo repository.StateRepo) *StateManager {
+	if m, ok := repo.(*StateManager); ok {
+		return m
+	}
+	return NewStateManager(repo)
+}
+
+// Load returns a copy of the current state; a zero state (ID 0) until the
+// furnace is first started or simulated.
+func (m *StateManager) Load(ctx context.Context) (models.FurnaceState, error) {
+	m.mu.RLock()
+	if m.loaded {
+		st := cloneState(m.st)
+		m.mu.RUnlock()
+		return st, nil
+	}
+	m.mu.RUnlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.ensureLoaded(ctx); err != nil {
+		return models.FurnaceState{}, err
+	}
+	return cloneState(m.st), nil
+}
+
+// Save persists st and makes it the current state, replacing whatever
+// was there. Prefer Update for changes based on the current state.
+func (m *StateManager) Save(ctx context.Context, st models.FurnaceState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.repo.Save(ctx, st); err != nil {
+		return err
+	}
+	m.st, m.loaded = cloneState(st), true
+	return nil
+}
+
+// Update calls fn with a copy of the current state and persists the result
+// before anyone else can read or change the state. If fn or the save fails,
+// the current state is kept and the error returned; errStateUnchanged
+// keeps it without an error. Update returns the state as it is afterwards.
+// fn must not call back into the StateManager.
+func (m *StateManager) Update(ctx context.Context, fn func(st *models.FurnaceState) error) (models.FurnaceState, error) {
+	return m.updateWith(ctx, fn, m.repo.Save)
+}
+
+// updateWith is Update persisting the result with save, which may write
+// more than the state in the same transaction.
+func (m *StateManager) updateWith(ctx context.Context, fn func(st *models.FurnaceState) error, save func(ctx context.Context, st models.FurnaceState) error) (models.FurnaceState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.ensureLoaded(ctx); err != nil {
+		return models.FurnaceState{}, err
+	}
+	st := cloneState(m.st)
+	if err := fn(&st); err != nil {
+		if errors.Is(err, errStateUnchanged) {
+			return cloneState(m.st), nil
+		}
+		return cloneState(m.st), err
+	}
+	if err := save(ctx, st); err != nil {
+		return cloneState(m.st), err
+	}
+	m.st = st
+	return cloneState(st), nil
+}
+
+// ensureLoaded reads the stored state on first use. A failed read is
+// retried on the next call. m.mu must be held for writing.
+func (m *StateManager) ensureLoaded(ctx context.Context) error {
+	if m.loaded {
+		return nil
+	}
+	st, err := m.repo.Load(ctx)
+	if err != nil {
+		return err
+	}
+	m.st, m.loaded = st, true
+	return nil
+}
+
+// cloneState copies st so the copy's error codes can be changed in place.
+func cloneState(st models.FurnaceState) models.FurnaceState {
+	st.ErrorCodes = slices.Clone(st.ErrorCodes)
+	if st.Sequence != nil {
+		seq := *st.Sequence
+		st.Sequence = &seq
+	}
+	return st
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+func TestStateManager_ConcurrentUpdatesAreNotLost(t *testing.T) {
+	repo := &fakeStateRepo{loadResp: models.FurnaceState{ID: 1, Mode: ModeStandby}}
+	m := NewStateManager(repo)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _ = m.Update(ctx, func(st *models.FurnaceState) error {
+				st.EnergyKWh++
+				return nil
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = m.Load(ctx)
+		}()
+	}
+	wg.Wait()
+
+	st, err := m.Load(ctx)
+	if err != nil || st.EnergyKWh != 50 {
+		t.Fatalf("Load() = %v, %v; want all 50 increments", st.EnergyKWh, err)
+	}
+	if last := repo.savedCalls[len(repo.savedCalls)-1]; len(repo.savedCalls) != 50 || last.EnergyKWh != 50 {
+		t.Fatalf("expected 50 saves ending at 50, got %d ending at %v", len(repo.savedCalls), last.EnergyKWh)
+	}
+}
+
+func TestStateManager_FailedUpdateKeepsTheState(t *testing.T) {
+	repo := &fakeStateRepo{loadResp: models.FurnaceState{ID: 1, Mode: ModeStandby, ErrorCodes: []string{AlarmOverheat}}}
+	m := NewStateManager(repo)
+	ctx := context.Background()
+
+	repo.saveErr = errors.New("disk full")
+	if _, err := m.Update(ctx, func(st *models.FurnaceState) error {
+		st.Mode = ModeHeat
+		st.ErrorCodes = removeString(st.ErrorCodes, AlarmOverheat)
+		return nil
+	}); err == nil {
+		t.Fatalf("expected the save error")
+	}
+	repo.saveErr = nil
+	if _, err := m.Update(ctx, func(st *models.FurnaceState) error {
+		st.Mode = ModeCool
+		return errStateUnchanged
+	}); err != nil {
+		t.Fatalf("errStateUnchanged should not be returned, got %v", err)
+	}
+	st, _ := m.Load(ctx)
+	if st.Mode != ModeStandby || len(st.ErrorCodes) != 1 || st.ErrorCodes[0] != AlarmOverheat {
+		t.Fatalf("expected the state untouched, got %+v", st)
+	}
+	if len(repo.savedCalls) != 1 {
+		t.Fatalf("expected only the failed save, got %d", len(repo.savedCalls))
+	}
+
+	// the stored state is read once; later reads come from memory
+	repo.loadResp = models.FurnaceState{}
+	if st, _ := m.Load(ctx); st.ID != 1 {
+		t.Fatalf("expected the in-memory state, got %+v", st)
+	}
+}
+
+func TestStateManager_ReadsTheRepositoryOnce(t *testing.T) {
+	repo := &fakeStateRepo{loadErr: errors.New("db locked")}
+	m := NewStateManager(repo)
+	monitoring := NewMonitoringService(m)
+	ctx := context.Background()
+
+	if _, err := monitoring.GetState(ctx); err == nil {
+		t.Fatal("expected the failed read to be returned")
+	}
+	// the failed read is retried; after that every dashboard tick and API
+	// call is served from memory, and saves write through
+	repo.loadErr, repo.loadResp = nil, models.FurnaceState{ID: 1, Mode: ModeStandby, CurrentTempC: 25}
+	for i := 0; i < 100; i++ {
+		if _, err := monitoring.GetState(ctx); err != nil {
+			t.Fatalf("GetState: %v", err)
+		}
+	}
+	if _, err := m.Update(ctx, func(st *models.FurnaceState) error {
+		st.CurrentTempC = 30
+		return nil
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	st, err := monitoring.GetState(ctx)
+	if err != nil || st.CurrentTempC != 30 {
+		t.Fatalf("GetState after a save = %v, %v; want 30", st.CurrentTempC, err)
+	}
+	if repo.loadCalls != 2 || len(repo.savedCalls) != 1 {
+		t.Fatalf("repository loaded %d times and saved %d times, want 2 and 1", repo.loadCalls, len(repo.savedCalls))
+	}
+}
+
+// countingSamples and countingRuns count the history and run reads.
+type countingSamples struct {
+	repository.SampleRepo
+	buckets int
+}
+
+func (r *countingSamples) Buckets(ctx context.Context, q repository.HistoryQuery) ([]models.HistoryBucket, error) {
+	r.buckets++
+	return r.SampleRepo.Buckets(ctx, q)
+}
+
+type countingRuns struct {
+	repository.RunRepo
+	gets int
+}
+
+func (r *countingRuns) Get(ctx context.Context, runID string) (models.Run, error) {
+	r.gets++
+	return r.RunRepo.Get(ctx, runID)
+}
+
+func TestMonitoringService_DerivedFieldsReadNoRepository(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewInMemory()
+	samples := &countingSamples{SampleRepo: repos.Samples}
+	runs := &countingRuns{RunRepo: repos.RunRepo}
+	repos.Samples, repos.RunRepo = samples, runs
+	svc := NewServiceWithConfig(repos, DefaultConfig())
+	if err := svc.Furnace.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Furnace.SetMode(ctx, ModeParams{Mode: ModeHeat, TargetTempC: 800, DurationSec: 600}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := svc.Simulator.Step(ctx, time.Second); err != nil {
+			t.Fatalf("Step: %v", err)
+		}
+	}
+
+	// the rate and soak total are kept by the simulator as it ticks
+	samples.buckets, runs.gets = 0, 0
+	for i := 0; i < 100; i++ {
+		st, err := svc.Monitoring.GetState(ctx)
+		if err != nil {
+			t.Fatalf("GetState: %v", err)
+		}
+		if st.RateCPerSec == nil || st.ETASeconds == nil {
+			t.Fatalf("expected a rate and ETA, got %v, %v", st.RateCPerSec, st.ETASeconds)
+		}
+	}
+	if samples.buckets != 0 || runs.gets != 0 {
+		t.Fatalf("GetState read the history %d times and runs %d times, want none", samples.buckets, runs.gets)
+	}
+}
+
+func TestStateManager_SharedByFurnaceAndSimulator(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakeStateRepo{loadResp: models.FurnaceState{ID: 1, Mode: ModeStandby, CurrentTempC: 25, UpdatedAt: start}}
+	state := NewStateManager(repo)
+	furnace := NewFurnaceService(state, &localEventRepo{})
+	furnace.clock = func() time.Time { return start }
+	sim := NewSimulatorService(state, &simEventRepoStub{})
+	monitoring := NewMonitoringService(state)
+	if furnace.state != state || sim.state != state || monitoring.state != state {
+		t.Fatalf("expected the services to share the StateManager")
+	}
+
+	// the simulator steps from the state the command left
+	if err := furnace.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := sim.Step(context.Background(), time.Second); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	st, err := monitoring.GetState(context.Background())
+	if err != nil || !st.IsRunning || !st.UpdatedAt.Equal(start.Add(time.Second)) {
+		t.Fatalf("expected the started furnace stepped once, got %+v, %v", st, err)
+	}
+}
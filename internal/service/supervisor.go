@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// Restart delays for a failed loop. The delay doubles with each failure in
+// a row and resets once an attempt outlives maxLoopBackoff.
+const (
+	minLoopBackoff = time.Second
+	maxLoopBackoff = 30 * time.Second
+)
+
+// ErrUnknownLoop is returned by Restart for a name that was never started.
+var ErrUnknownLoop = errors.New("unknown loop")
+
+var errLoopExited = errors.New("loop exited before shutdown")
+
+// Supervisor runs the background loops, restarting any that panics or
+// returns while its context is still live.
+type Supervisor struct {
+	mu    sync.Mutex
+	loops map[string]*supervisedLoop
+	wg    sync.WaitGroup
+
+	// failed, when set, is told about each failure before the restart;
+	// stack is set for a panic.
+	failed  func(name string, err error, stack []byte)
+	backoff time.Duration
+	now     func() time.Time
+}
+
+type supervisedLoop struct {
+	status  models.LoopStatus
+	cancel  context.CancelFunc // cancels the current attempt
+	restart bool               // the current attempt was canceled by Restart
+}
+
+func NewSupervisor() *Supervisor {
+	return &Supervisor{loops: map[string]*supervisedLoop{}, backoff: minLoopBackoff, now: time.Now}
+}
+
+// Go starts run under name until ctx is canceled.
+func (s *Supervisor) Go(ctx context.Context, name string, run func(context.Context)) {
+	l := &supervisedLoop{status: models.LoopStatus{Name: name, State: models.LoopRunning}}
+	s.mu.Lock()
+	s.loops[name] = l
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.supervise(ctx, l, run)
+	}()
+}
+
+func (s *Supervisor) supervise(ctx context.Context, l *supervisedLoop, run func(context.Context)) {
+	delay := s.backoff
+	for {
+		attempt, cancel := context.WithCancel(ctx)
+		s.mu.Lock()
+		started := s.now()
+		l.status.State, l.status.StartedAt = models.LoopRunning, started
+		l.cancel, l.restart = cancel, false
+		s.mu.Unlock()
+
+		stack, err := runLoop(attempt, run)
+		cancel()
+		if ctx.Err() != nil {
+			s.setState(l, models.LoopStopped)
+			return
+		}
+
+		s.mu.Lock()
+		manual := l.restart
+		l.status.Restarts++
+		if manual {
+			s.mu.Unlock()
+			continue
+		}
+		if err == nil {
+			err = errLoopExited
+		}
+		now := s.now()
+		l.status.State = models.LoopBackoff
+		l.status.LastError = err.Error()
+		l.status.LastFailureAt = &now
+		name := l.status.Name
+		s.mu.Unlock()
+		if s.failed != nil {
+			s.failed(name, err, stack)
+		}
+
+		if now.Sub(started) > maxLoopBackoff {
+			delay = s.backoff
+		}
+		select {
+		case <-ctx.Done():
+			s.setState(l, models.LoopStopped)
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, maxLoopBackoff)
+	}
+}
+
+// runLoop calls run, turning a panic into an error and its stack.
+func runLoop(ctx context.Context, run func(context.Context)) (stack []byte, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			stack, err = debug.Stack(), fmt.Errorf("panic: %v", v)
+		}
+	}()
+	run(ctx)
+	return nil, nil
+}
+
+func (s *Supervisor) setState(l *supervisedLoop, state string) {
+	s.mu.Lock()
+	l.status.State = state
+	s.mu.Unlock()
+}
+
+// Restart stops the named loop and starts it again at once. The loop sees
+// its context canceled, as on shutdown. A loop waiting out its backoff
+// after a failure is left to restart on schedule.
+func (s *Supervisor) Restart(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.loops[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownLoop, name)
+	}
+	if l.status.State == models.LoopRunning {
+		l.restart = true
+		l.cancel()
+	}
+	return nil
+}
+
+// LoopStatus lists the loops by name.
+func (s *Supervisor) LoopStatus() []models.LoopStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]models.LoopStatus, 0, len(s.loops))
+	for _, l := range s.loops {
+		out = append(out, l.status)
+	}
+	slices.SortFunc(out, func(a, b models.LoopStatus) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+// Wait blocks until every loop has stopped after its context was canceled.
+func (s *Supervisor) Wait() {
+	s.wg.Wait()
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSupervisor_RestartsAfterPanic(t *testing.T) {
+	sup := NewSupervisor()
+	sup.backoff = time.Millisecond
+	var (
+		runs     atomic.Int32
+		failures atomic.Int32
+		gotStack atomic.Bool
+	)
+	sup.failed = func(name string, err error, stack []byte) {
+		failures.Add(1)
+		gotStack.Store(name == "sim" && strings.HasPrefix(err.Error(), "panic: boom") && len(stack) > 0)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sup.Go(ctx, "sim", func(ctx context.Context) {
+		if runs.Add(1) == 1 {
+			panic("boom")
+		}
+		<-ctx.Done()
+	})
+	waitFor(t, "second run", func() bool { return runs.Load() == 2 })
+
+	st := sup.LoopStatus()
+	if len(st) != 1 || st[0].State != models.LoopRunning || st[0].Restarts != 1 {
+		t.Fatalf("status after restart = %+v", st)
+	}
+	if st[0].LastError != "panic: boom" || st[0].LastFailureAt == nil {
+		t.Fatalf("failure not recorded: %+v", st[0])
+	}
+	if failures.Load() != 1 || !gotStack.Load() {
+		t.Fatalf("failure hook: calls=%d stack=%v", failures.Load(), gotStack.Load())
+	}
+
+	cancel()
+	sup.Wait()
+	if st := sup.LoopStatus(); st[0].State != models.LoopStopped {
+		t.Fatalf("state after cancel = %q, want stopped", st[0].State)
+	}
+}
+
+func TestSupervisor_EarlyReturnIsAFailure(t *testing.T) {
+	sup := NewSupervisor()
+	sup.backoff = time.Millisecond
+	var runs atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() { cancel(); sup.Wait() }()
+
+	sup.Go(ctx, "alerts", func(ctx context.Context) {
+		if runs.Add(1) == 1 {
+			return
+		}
+		<-ctx.Done()
+	})
+	waitFor(t, "second run", func() bool { return runs.Load() == 2 })
+	if st := sup.LoopStatus(); st[0].LastError != errLoopExited.Error() {
+		t.Fatalf("last error = %q", st[0].LastError)
+	}
+}
+
+func TestSupervisor_Restart(t *testing.T) {
+	sup := NewSupervisor()
+	sup.backoff = time.Hour // a manual restart must not wait
+	var runs atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() { cancel(); sup.Wait() }()
+
+	sup.Go(ctx, "incidents", func(ctx context.Context) {
+		runs.Add(1)
+		<-ctx.Done()
+	})
+	waitFor(t, "first run", func() bool { return runs.Load() == 1 })
+
+	if err := sup.Restart("incidents"); err != nil {
+		t.Fatalf("Restart: %v", err)
+	}
+	waitFor(t, "second run", func() bool { return runs.Load() == 2 })
+	if st := sup.LoopStatus(); st[0].Restarts != 1 || st[0].LastError != "" {
+		t.Fatalf("status after manual restart = %+v", st[0])
+	}
+	if err := sup.Restart("nope"); !errors.Is(err, ErrUnknownLoop) {
+		t.Fatalf("Restart unknown: err = %v, want ErrUnknownLoop", err)
+	}
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// SystemInfo describes the running process, for diagnosing leaks and
+// contention on a live instance.
+type SystemInfo struct {
+	Version        string    `json:"version" example:"v1.4.0"`
+	GoVersion      string    `json:"go_version" example:"go1.24.4"`
+	StartedAt      time.Time `json:"started_at"`
+	UptimeS        float64   `json:"uptime_s" example:"86400"`
+	Goroutines     int       `json:"goroutines" example:"42"`
+	HeapAllocBytes uint64    `json:"heap_alloc_bytes"`
+	NumGC          uint32    `json:"num_gc"`
+	// StateSubscribers counts live state consumers: one per WebSocket
+	// client plus the alert and incident evaluators.
+	StateSubscribers int `json:"state_subscribers" example:"3"`
+	// Panics counts the panics recovered while serving requests since the
+	// process started.
+	Panics uint64   `json:"panics" example:"0"`
+	DB     *DBStats `json:"db,omitempty"`
+}
+
+// DBStats is the database connection pool usage (see sql.DBStats).
+type DBStats struct {
+	MaxOpenConnections int     `json:"max_open_connections"`
+	OpenConnections    int     `json:"open_connections"`
+	InUse              int     `json:"in_use"`
+	Idle               int     `json:"idle"`
+	WaitCount          int64   `json:"wait_count"`
+	WaitMs             float64 `json:"wait_ms"` // total time spent waiting for a connection
+	MaxIdleClosed      int64   `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64   `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64   `json:"max_lifetime_closed"`
+}
+
+// BuildInfo identifies the deployed build, for fleet management to check
+// what runs on each controller.
+type BuildInfo struct {
+	Version string `json:"version" example:"v1.4.0"`
+	Commit  string `json:"commit" example:"3f2c9a1b7e4d"`
+	// SchemaVersion is the state and event payload version served;
+	// clients may pin any from MinSchemaVersion on.
+	SchemaVersion    int    `json:"schema_version" example:"9"`
+	MinSchemaVersion int    `json:"min_schema_version" example:"1"`
+	GoVersion        string `json:"go_version" example:"go1.24.4"`
+}
+
+// PanicReport describes a panic recovered while serving a request.
+type PanicReport struct {
+	Value  string // what was passed to panic
+	Method string
+	Route  string // the route pattern, or the path when none matched
+}
+
+type SystemService struct {
+	status  repository.StatusRepo // optional; no database stats when nil
+	bus     *StateBroker          // optional; no subscriber count when nil
+	events  repository.EventRepo  // optional; panics are only counted when nil
+	version string
+	commit  string
+	started time.Time
+	now     func() time.Time
+	newID   func() string
+	panics  atomic.Uint64
+}
+
+// NewSystemService reports on the process it is created in; version
+// defaults to the module version or VCS revision embedded by the Go
+// toolchain, the commit to that revision.
+func NewSystemService(status repository.StatusRepo, bus *StateBroker, version string) *SystemService {
+	if version == "" {
+		version = buildVersion()
+	}
+	return &SystemService{status: status, bus: bus, version: version, commit: buildCommit(), started: time.Now(), now: time.Now, newID: uuid.NewString}
+}
+
+// RecordPanic counts a panic recovered while serving a request and logs
+// an INTERNAL_ERROR event for it, so crashes show in the event log and its
+// alerts rather than only in the process output.
+func (s *SystemService) RecordPanic(ctx context.Context, p PanicReport) error {
+	s.panics.Add(1)
+	if s.events == nil {
+		return nil
+	}
+	return s.events.Append(ctx, models.FurnaceEvent{
+		EventID:     s.newID(),
+		OccurredAt:  s.now().UTC(),
+		Type:        "INTERNAL_ERROR",
+		Description: fmt.Sprintf("Panic serving %s %s: %s", p.Method, p.Route, p.Value),
+		Metadata:    map[string]any{"panic": p.Value, "method": p.Method, "route": p.Route},
+	})
+}
+
+// Build reports the version, commit and payload schema of the binary.
+func (s *SystemService) Build() BuildInfo {
+	return BuildInfo{
+		Version:          s.version,
+		Commit:           s.commit,
+		SchemaVersion:    models.SchemaVersion,
+		MinSchemaVersion: models.MinSchemaVersion,
+		GoVersion:        runtime.Version(),
+	}
+}
+
+// Info takes a snapshot of the runtime and connection pool statistics.
+func (s *SystemService) Info() SystemInfo {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	info := SystemInfo{
+		Version:        s.version,
+		GoVersion:      runtime.Version(),
+		StartedAt:      s.started.UTC(),
+		UptimeS:        s.now().Sub(s.started).Round(time.Second).Seconds(),
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		NumGC:          mem.NumGC,
+		Panics:         s.panics.Load(),
+	}
+	if s.bus != nil {
+		info.StateSubscribers = s.bus.Subscribers()
+	}
+	if s.status != nil {
+		st := s.status.Stats()
+		info.DB = &DBStats{
+			MaxOpenConnections: st.MaxOpenConnections,
+			OpenConnections:    st.OpenConnections,
+			InUse:              st.InUse,
+			Idle:               st.Idle,
+			WaitCount:          st.WaitCount,
+			WaitMs:             float64(st.WaitDuration.Microseconds()) / 1000,
+			MaxIdleClosed:      st.MaxIdleClosed,
+			MaxIdleTimeClosed:  st.MaxIdleTimeClosed,
+			MaxLifetimeClosed:  st.MaxLifetimeClosed,
+		}
+	}
+	return info
+}
+
+// buildVersion reads the version the toolchain stamped into the binary:
+// the module version for `go install`ed builds, else the VCS revision.
+func buildVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	if v := bi.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+	return vcsRevision(bi)
+}
+
+// buildCommit reads the VCS revision the toolchain stamped into the
+// binary; "unknown" for builds outside a checkout, such as Docker's.
+func buildCommit() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if rev := vcsRevision(bi); rev != "dev" {
+		return rev
+	}
+	return "unknown"
+}
+
+// vcsRevision abbreviates the recorded revision, marking uncommitted
+// changes; "dev" when none is recorded.
+func vcsRevision(bi *debug.BuildInfo) string {
+	var rev, dirty string
+	for _, kv := range bi.Settings {
+		switch kv.Key {
+		case "vcs.revision":
+			rev = kv.Value
+		case "vcs.modified":
+			if kv.Value == "true" {
+				dirty = "-dirty"
+			}
+		}
+	}
+	if rev == "" {
+		return "dev"
+	}
+	return rev[:min(len(rev), 12)] + dirty
+}
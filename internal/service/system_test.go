@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+func TestSystemService_Info(t *testing.T) {
+	bus := NewStateBroker()
+	_, cancel := bus.Subscribe(1)
+	defer cancel()
+
+	svc := NewSystemService(&statusRepoStub{}, bus, "v1.2.3")
+	svc.now = func() time.Time { return svc.started.Add(90 * time.Second) }
+
+	info := svc.Info()
+	if info.Version != "v1.2.3" || info.UptimeS != 90 || info.Goroutines < 1 || info.GoVersion == "" {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	if info.StateSubscribers != 1 {
+		t.Fatalf("expected 1 state subscriber, got %d", info.StateSubscribers)
+	}
+	if info.DB == nil || info.DB.OpenConnections != 1 || info.DB.InUse != 1 {
+		t.Fatalf("expected pool stats from the status repo, got %+v", info.DB)
+	}
+
+	if NewSystemService(nil, nil, "").Info().Version == "" {
+		t.Fatalf("expected a fallback version")
+	}
+}
+
+func TestSystemService_Build(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Version, cfg.Commit = "v1.2.3", "3f2c9a1b7e4d"
+	b := NewServiceWithConfig(repository.NewInMemory(), cfg).System.Build()
+	if b.Version != "v1.2.3" || b.Commit != "3f2c9a1b7e4d" || b.SchemaVersion != models.SchemaVersion || b.MinSchemaVersion != models.MinSchemaVersion {
+		t.Fatalf("unexpected build: %+v", b)
+	}
+	if NewSystemService(nil, nil, "").Build().Commit == "" {
+		t.Fatalf("expected a fallback commit")
+	}
+}
+
+func TestSystemService_RecordPanic(t *testing.T) {
+	repos := repository.NewInMemory()
+	svc := NewServiceWithConfig(repos, DefaultConfig()).System
+	ctx := WithRequestID(context.Background(), "req-42")
+	if err := svc.RecordPanic(ctx, PanicReport{Value: "boom", Method: "POST", Route: "/api/v1/furnace/start"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := svc.Info().Panics; n != 1 {
+		t.Fatalf("panics = %d", n)
+	}
+	events, err := repos.EventRepo.List(ctx, time.Time{}, time.Now().Add(time.Minute), "INTERNAL_ERROR")
+	if err != nil || len(events) != 1 {
+		t.Fatalf("events %+v, %v", events, err)
+	}
+	meta, _ := events[0].Metadata.(map[string]any)
+	if meta["panic"] != "boom" || meta["route"] != "/api/v1/furnace/start" || meta["request_id"] != "req-42" {
+		t.Fatalf("metadata %v", meta)
+	}
+
+	// without an event log the panic is still counted
+	bare := NewSystemService(nil, nil, "")
+	if err := bare.RecordPanic(ctx, PanicReport{Value: "boom"}); err != nil || bare.Info().Panics != 1 {
+		t.Fatalf("bare: %v, %d", err, bare.Info().Panics)
+	}
+}
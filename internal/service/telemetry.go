@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"controlling_furnace/internal/models"
+	"errors"
+	"strings"
+	"time"
+
+	"controlling_furnace/internal/repository"
+)
+
+// Sample count limits for telemetry queries.
+const (
+	DefaultTelemetryLimit = 1000
+	MaxTelemetryLimit     = 10000
+)
+
+// ErrUnknownChannel is returned for telemetry channels the simulator does not record.
+var ErrUnknownChannel = errors.New("unknown telemetry channel")
+
+// TelemetryFilter selects samples of one or all channels.
+type TelemetryFilter struct {
+	Channel string    // "" for all channels
+	From    time.Time // inclusive; zero means no lower bound
+	To      time.Time // inclusive; zero means no upper bound
+	Limit   int       // 0 means DefaultTelemetryLimit; capped at MaxTelemetryLimit
+}
+
+type TelemetryService struct {
+	repo repository.TelemetryRepo
+}
+
+func NewTelemetryService(repo repository.TelemetryRepo) *TelemetryService {
+	return &TelemetryService{repo: repo}
+}
+
+// Samples returns recorded telemetry, oldest first.
+func (s *TelemetryService) Samples(ctx context.Context, f TelemetryFilter) (_ []models.TelemetrySample, err error) {
+	ctx, span := startSpan(ctx, "Telemetry.Samples", rangeAttrs(f.From, f.To)...)
+	defer func() { endSpan(span, err) }()
+
+	channel := strings.ToLower(strings.TrimSpace(f.Channel))
+	if channel != "" && !knownChannel(channel) {
+		return nil, ErrUnknownChannel
+	}
+	from, to := normalizeToUTC(f.From), normalizeToUTC(f.To)
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return nil, errInvalidTimeRange
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultTelemetryLimit
+	}
+	if limit > MaxTelemetryLimit {
+		limit = MaxTelemetryLimit
+	}
+	return s.repo.Query(ctx, repository.TelemetryQuery{Channel: channel, From: from, To: to, Limit: limit})
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer starts the service spans that sit between the HTTP server span and
+// the SQL statement spans. It records nothing until tracing.Setup installs
+// a provider.
+var tracer = otel.Tracer("controlling_furnace/internal/service")
+
+// startSpan starts a span for the service operation op. End it with
+// endSpan so failures are marked on the trace.
+func startSpan(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, op, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// rangeAttrs describes a queried time range; unset bounds are omitted.
+func rangeAttrs(from, to time.Time) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if !from.IsZero() {
+		attrs = append(attrs, attribute.String("query.from", from.UTC().Format(time.RFC3339)))
+	}
+	if !to.IsZero() {
+		attrs = append(attrs, attribute.String("query.to", to.UTC().Format(time.RFC3339)))
+	}
+	return attrs
+}
+
+// logFilterAttrs describes an event log query.
+func logFilterAttrs(f LogFilter) []attribute.KeyValue {
+	attrs := rangeAttrs(f.From, f.To)
+	if f.Type != "" {
+		attrs = append(attrs, attribute.String("query.type", f.Type))
+	}
+	if f.RunID != "" {
+		attrs = append(attrs, attribute.String("run.id", f.RunID))
+	}
+	if len(f.Types) > 0 {
+		attrs = append(attrs, attribute.StringSlice("query.types", f.Types))
+	}
+	if len(f.ExcludeTypes) > 0 {
+		attrs = append(attrs, attribute.StringSlice("query.exclude_types", f.ExcludeTypes))
+	}
+	if len(f.Meta) > 0 {
+		attrs = append(attrs, attribute.Int("query.meta_filters", len(f.Meta)))
+	}
+	if f.Severity != "" {
+		attrs = append(attrs, attribute.String("query.severity", f.Severity))
+	}
+	return attrs
+}
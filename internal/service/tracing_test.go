@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans routes the package tracer into an in-memory recorder. The
+// global provider can only be delegated to once, so call it from one test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return rec
+}
+
+func endedSpan(t *testing.T, rec *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, s := range rec.Ended() {
+		if s.Name() == name {
+			return s
+		}
+	}
+	t.Fatalf("no ended span %q", name)
+	return nil
+}
+
+func spanAttr(s sdktrace.ReadOnlySpan, key string) (attribute.Value, bool) {
+	for _, kv := range s.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestEventLogSpans(t *testing.T) {
+	rec := recordSpans(t)
+	parentCtx, parent := otel.Tracer("test").Start(context.Background(), "GET /api/v1/logs")
+
+	now := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakeEventRepo{events: []models.FurnaceEvent{{EventID: "a"}, {EventID: "b"}}}
+	svc := NewEventLogService(repo)
+
+	if _, err := svc.List(parentCtx, LogFilter{From: now.Add(-time.Hour), To: now, Type: "START"}); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if err := svc.Stream(parentCtx, LogFilter{}, func(models.FurnaceEvent) error { return nil }); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if _, err := svc.List(parentCtx, LogFilter{From: now, To: now.Add(-time.Hour)}); err == nil {
+		t.Fatal("List with inverted range: want error")
+	}
+	parent.End()
+
+	spans := rec.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("ended spans = %d, want 4", len(spans))
+	}
+	list := spans[0]
+	if list.Name() != "EventLog.List" {
+		t.Fatalf("first span = %q, want EventLog.List", list.Name())
+	}
+	if list.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("EventLog.List is not a child of the request span")
+	}
+	if v, _ := spanAttr(list, "query.type"); v.AsString() != "START" {
+		t.Errorf("query.type = %q, want START", v.AsString())
+	}
+	if v, _ := spanAttr(list, "events.count"); v.AsInt64() != 2 {
+		t.Errorf("List events.count = %d, want 2", v.AsInt64())
+	}
+	if list.Status().Code == codes.Error {
+		t.Error("successful List marked as error")
+	}
+
+	stream := endedSpan(t, rec, "EventLog.Stream")
+	if v, _ := spanAttr(stream, "events.count"); v.AsInt64() != 2 {
+		t.Errorf("Stream events.count = %d, want 2", v.AsInt64())
+	}
+
+	failed := spans[2]
+	if failed.Status().Code != codes.Error {
+		t.Errorf("failed List status = %v, want Error", failed.Status().Code)
+	}
+	if len(failed.Events()) == 0 || failed.Events()[0].Name != "exception" {
+		t.Error("failed List did not record the error")
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+// DefaultUptimeCheck is how often Run records readiness when no interval
+// is configured.
+const DefaultUptimeCheck = time.Minute
+
+// uptimeWindows are the rolling windows reported by UptimeSummary; checks
+// are kept a day longer than the longest of them.
+var uptimeWindows = []struct {
+	name string
+	span time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+}
+
+// UptimeConfig configures the readiness record behind the status endpoints.
+type UptimeConfig struct {
+	CheckInterval time.Duration `mapstructure:"check_interval"` // DefaultUptimeCheck when 0
+}
+
+// UptimeService records the readiness report at a fixed interval and
+// derives the availability of the service from it.
+type UptimeService struct {
+	repo  repository.UptimeRepo
+	probe Probes
+	every time.Duration
+	now   func() time.Time
+}
+
+func NewUptimeService(repo repository.UptimeRepo, probe Probes, cfg UptimeConfig) *UptimeService {
+	every := cfg.CheckInterval
+	if every <= 0 {
+		every = DefaultUptimeCheck
+	}
+	return &UptimeService{repo: repo, probe: probe, every: every, now: time.Now}
+}
+
+// UptimeSummary reports the outcome of the last check and the
+// availability over the last 24 hours and 7 days. The status is unknown
+// when no check ran for three intervals, e.g. because the loop is stuck.
+func (s *UptimeService) UptimeSummary(ctx context.Context) (models.UptimeSummary, error) {
+	now := s.now().UTC()
+	sum := models.UptimeSummary{Status: models.UptimeUnknown, Windows: make([]models.UptimeWindow, 0, len(uptimeWindows))}
+	last, err := s.repo.LastCheck(ctx)
+	if err != nil {
+		return models.UptimeSummary{}, err
+	}
+	if !last.At.IsZero() {
+		sum.CheckedAt = &last.At
+		if now.Sub(last.At) <= 3*s.every {
+			sum.Status = models.UptimeDown
+			if last.Up {
+				sum.Status = models.UptimeUp
+			}
+		}
+	}
+	for _, w := range uptimeWindows {
+		n, err := s.repo.CountChecks(ctx, now.Add(-w.span))
+		if err != nil {
+			return models.UptimeSummary{}, err
+		}
+		sum.Windows = append(sum.Windows, s.availability(w.name, n, now))
+	}
+	return sum, nil
+}
+
+// availability counts the checks missing since the first one in the window
+// as down, so time the service was stopped lowers it. The window starts at
+// that first check, so a new installation is not penalised for the days
+// before it existed. A check may run up to half an interval late before it
+// counts as missed.
+func (s *UptimeService) availability(name string, n models.UptimeCounts, now time.Time) models.UptimeWindow {
+	w := models.UptimeWindow{Window: name, Checks: n.Checks}
+	if n.Checks == 0 {
+		return w
+	}
+	expected := int((now.Sub(n.First) + s.every/2) / s.every)
+	w.Missed = max(expected-n.Checks, 0)
+	pct := math.Round(10000*float64(n.Up)/float64(n.Checks+w.Missed)) / 100
+	w.AvailabilityPct = &pct
+	return w
+}
+
+// Run records a readiness check every interval until ctx is canceled.
+func (s *UptimeService) Run(ctx context.Context) {
+	t := time.NewTicker(s.every)
+	defer t.Stop()
+	for {
+		s.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// check records whether the service is ready now and drops checks that
+// have left every window.
+func (s *UptimeService) check(ctx context.Context) {
+	rep := s.probe.Ready(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	now := s.now().UTC()
+	_ = s.repo.RecordCheck(ctx, models.UptimeCheck{At: now, Up: rep.Ready})
+	keep := uptimeWindows[len(uptimeWindows)-1].span + 24*time.Hour
+	_, _ = s.repo.PurgeChecks(ctx, now.Add(-keep))
+}
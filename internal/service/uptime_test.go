@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// fakeUptimeRepo keeps checks in memory, oldest first.
+type fakeUptimeRepo struct {
+	checks []models.UptimeCheck
+}
+
+func (r *fakeUptimeRepo) RecordCheck(ctx context.Context, c models.UptimeCheck) error {
+	r.checks = append(r.checks, c)
+	return nil
+}
+func (r *fakeUptimeRepo) LastCheck(ctx context.Context) (models.UptimeCheck, error) {
+	if len(r.checks) == 0 {
+		return models.UptimeCheck{}, nil
+	}
+	return r.checks[len(r.checks)-1], nil
+}
+func (r *fakeUptimeRepo) CountChecks(ctx context.Context, since time.Time) (models.UptimeCounts, error) {
+	var n models.UptimeCounts
+	for _, c := range r.checks {
+		if c.At.Before(since) {
+			continue
+		}
+		if n.Checks == 0 {
+			n.First = c.At
+		}
+		n.Checks++
+		if c.Up {
+			n.Up++
+		}
+	}
+	return n, nil
+}
+func (r *fakeUptimeRepo) PurgeChecks(ctx context.Context, before time.Time) (int64, error) {
+	kept := r.checks[:0]
+	for _, c := range r.checks {
+		if !c.At.Before(before) {
+			kept = append(kept, c)
+		}
+	}
+	n := int64(len(r.checks) - len(kept))
+	r.checks = kept
+	return n, nil
+}
+
+type probeStub struct{ ready bool }
+
+func (p *probeStub) Ready(ctx context.Context) ReadinessReport {
+	return ReadinessReport{Ready: p.ready}
+}
+
+func TestUptime_CountsMissedChecksAsDown(t *testing.T) {
+	now := time.Date(2025, 9, 20, 12, 0, 0, 0, time.UTC)
+	repo := &fakeUptimeRepo{}
+	probe := &probeStub{ready: true}
+	svc := NewUptimeService(repo, probe, UptimeConfig{CheckInterval: time.Hour})
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	sum, err := svc.UptimeSummary(ctx)
+	if err != nil || sum.Status != models.UptimeUnknown || sum.CheckedAt != nil || len(sum.Windows) != 2 || sum.Windows[0].AvailabilityPct != nil {
+		t.Fatalf("expected an unknown status without checks, got %+v, %v", sum, err)
+	}
+
+	// checks every hour for two days, with the last day's 4 hours down and
+	// 2 hours not recorded at all
+	now = now.Add(-48 * time.Hour)
+	for i := 0; i < 48; i++ {
+		probe.ready = i < 40 || i > 43
+		if i != 30 && i != 31 {
+			svc.check(ctx)
+		}
+		now = now.Add(time.Hour)
+	}
+
+	sum, err = svc.UptimeSummary(ctx)
+	if err != nil || sum.Status != models.UptimeUp || !sum.CheckedAt.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("UptimeSummary() = %+v, %v", sum, err)
+	}
+	day, week := sum.Windows[0], sum.Windows[1]
+	// the last 24h hold checks 24..47 minus the two missed: 22 recorded, 18 up
+	if day.Window != "24h" || day.Checks != 22 || day.Missed != 2 || *day.AvailabilityPct != 75 {
+		t.Fatalf("unexpected 24h window: %+v (%v)", day, *day.AvailabilityPct)
+	}
+	// the week starts at the first check, two days ago
+	if week.Window != "7d" || week.Checks != 46 || week.Missed != 2 || *week.AvailabilityPct != 87.5 {
+		t.Fatalf("unexpected 7d window: %+v (%v)", week, *week.AvailabilityPct)
+	}
+
+	probe.ready = false
+	svc.check(ctx)
+	if sum, _ := svc.UptimeSummary(ctx); sum.Status != models.UptimeDown {
+		t.Fatalf("expected down after a failed check, got %s", sum.Status)
+	}
+	now = now.Add(4 * time.Hour)
+	if sum, _ := svc.UptimeSummary(ctx); sum.Status != models.UptimeUnknown {
+		t.Fatalf("expected unknown once the last check is stale, got %s", sum.Status)
+	}
+
+	// checks older than the longest window plus a day are dropped
+	now = now.Add(8 * 24 * time.Hour)
+	svc.check(ctx)
+	if len(repo.checks) != 1 {
+		t.Fatalf("expected old checks purged, %d left", len(repo.checks))
+	}
+}
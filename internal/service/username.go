@@ -0,0 +1,160 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"controlling_furnace/internal/repository"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Username errors. A name the policy rejects fails with a *UsernameError,
+// which matches ErrInvalidUsername.
+var (
+	ErrInvalidUsername = errors.New("invalid username")
+	ErrUsernameTaken   = repository.ErrUsernameTaken
+)
+
+// Reasons a *UsernameError reports.
+const (
+	UsernameEmpty            = "empty"
+	UsernameTooShort         = "too_short"
+	UsernameTooLong          = "too_long"
+	UsernameInvalidCharacter = "invalid_character"
+	UsernameReserved         = "reserved"
+)
+
+// UsernameError tells which rule of the UsernamePolicy a name broke, so
+// clients can point at it without parsing the message.
+type UsernameError struct {
+	Reason string // one of the Username* reasons
+	Detail string
+}
+
+func (e *UsernameError) Error() string { return "invalid username: " + e.Detail }
+
+func (e *UsernameError) Unwrap() error { return ErrInvalidUsername }
+
+// UsernamePolicy decides which names can be registered. Names are trimmed
+// and NFC-normalized first, so the same name typed on different keyboards
+// is one user, and are unique regardless of case.
+type UsernamePolicy struct {
+	// MinLength and MaxLength count characters, not bytes; 2 and 32 when 0.
+	MinLength int `mapstructure:"min_length"`
+	MaxLength int `mapstructure:"max_length"`
+	// ASCIIOnly limits letters and digits to ASCII; otherwise those of any
+	// script are accepted.
+	ASCIIOnly bool `mapstructure:"ascii_only"`
+	// Separators may join letters and digits but cannot start or end a
+	// name or follow one another; "._-" when empty.
+	Separators string `mapstructure:"separators"`
+	// Lowercase stores names lowercased instead of as typed.
+	Lowercase bool `mapstructure:"lowercase"`
+	// Reserved names, compared regardless of case, cannot be signed up.
+	// The first admin created by setup may still take one.
+	Reserved []string `mapstructure:"reserved"`
+}
+
+const (
+	defaultUsernameMin        = 2
+	defaultUsernameMax        = 32
+	defaultUsernameSeparators = "._-"
+)
+
+// Validate rejects lengths that cannot be satisfied and separators that
+// are letters, digits or spaces.
+func (p UsernamePolicy) Validate() error {
+	p = p.withDefaults()
+	if p.MinLength < 1 || p.MaxLength < p.MinLength {
+		return fmt.Errorf("username min_length must be positive and at most max_length, got %d and %d", p.MinLength, p.MaxLength)
+	}
+	for _, r := range p.Separators {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return fmt.Errorf("username separators must be punctuation, got %q", r)
+		}
+	}
+	return nil
+}
+
+func (p UsernamePolicy) withDefaults() UsernamePolicy {
+	if p.MinLength == 0 {
+		p.MinLength = defaultUsernameMin
+	}
+	if p.MaxLength == 0 {
+		p.MaxLength = defaultUsernameMax
+	}
+	if p.Separators == "" {
+		p.Separators = defaultUsernameSeparators
+	}
+	return p
+}
+
+// canonicalUsername is the form names are stored and looked up in, before
+// any policy applies.
+func canonicalUsername(name string) string {
+	return norm.NFC.String(strings.TrimSpace(name))
+}
+
+// normalize returns name as it is stored, or a *UsernameError. Reserved
+// names pass when allowReserved is set.
+func (p UsernamePolicy) normalize(name string, allowReserved bool) (string, error) {
+	p = p.withDefaults()
+	name = canonicalUsername(name)
+	if p.Lowercase {
+		name = strings.ToLower(name)
+	}
+	n := utf8.RuneCountInString(name)
+	switch {
+	case n == 0:
+		return "", &UsernameError{Reason: UsernameEmpty, Detail: "username is required"}
+	case n < p.MinLength:
+		return "", &UsernameError{Reason: UsernameTooShort, Detail: fmt.Sprintf("must be at least %d characters", p.MinLength)}
+	case n > p.MaxLength:
+		return "", &UsernameError{Reason: UsernameTooLong, Detail: fmt.Sprintf("must be at most %d characters", p.MaxLength)}
+	}
+	prevSep := true // a name cannot start with a separator
+	for i, r := range name {
+		switch {
+		case strings.ContainsRune(p.Separators, r):
+			if prevSep {
+				return "", p.invalidChar(r)
+			}
+			prevSep = true
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || (unicode.IsMark(r) && i > 0 && !prevSep):
+			// marks complete the letter before them in scripts NFC does
+			// not compose, e.g. Devanagari vowel signs
+			if p.ASCIIOnly && r >= utf8.RuneSelf {
+				return "", p.invalidChar(r)
+			}
+			prevSep = false
+		default:
+			return "", p.invalidChar(r)
+		}
+	}
+	if prevSep {
+		return "", &UsernameError{Reason: UsernameInvalidCharacter, Detail: "must not end with a separator"}
+	}
+	if !allowReserved {
+		for _, r := range p.Reserved {
+			if strings.EqualFold(name, canonicalUsername(r)) {
+				return "", &UsernameError{Reason: UsernameReserved, Detail: fmt.Sprintf("%q is reserved", name)}
+			}
+		}
+	}
+	return name, nil
+}
+
+func (p UsernamePolicy) invalidChar(r rune) *UsernameError {
+	allowed := "letters and digits"
+	if p.ASCIIOnly {
+		allowed = "ASCII letters and digits"
+	}
+	return &UsernameError{
+		Reason: UsernameInvalidCharacter,
+		Detail: fmt.Sprintf("%q is not allowed; use %s joined by any of %q", r, allowed, p.Separators),
+	}
+}
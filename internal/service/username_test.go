@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"controlling_furnace/internal/models"
+)
+
+func TestUsernamePolicy_Normalize(t *testing.T) {
+	p := UsernamePolicy{Reserved: []string{"admin"}}
+	ok := map[string]string{
+		"  alice ":                 "alice",
+		"Jean-Luc.Picard":          "Jean-Luc.Picard",
+		"Jose\u0301":               "Jos\u00e9", // NFC composes the accent
+		"Иван_2":                   "Иван_2",
+		"\u0928\u093f\u0915\u0940": "\u0928\u093f\u0915\u0940", // Devanagari vowel signs are marks
+	}
+	for in, want := range ok {
+		if got, err := p.normalize(in, false); err != nil || got != want {
+			t.Errorf("normalize(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	bad := map[string]string{
+		"   ":                   UsernameEmpty,
+		"a":                     UsernameTooShort,
+		strings.Repeat("x", 33): UsernameTooLong,
+		"bob smith":             UsernameInvalidCharacter,
+		"fire\U0001F525":        UsernameInvalidCharacter,
+		".bob":                  UsernameInvalidCharacter,
+		"bob.":                  UsernameInvalidCharacter,
+		"bob..smith":            UsernameInvalidCharacter,
+		"ADMIN":                 UsernameReserved,
+	}
+	for in, reason := range bad {
+		_, err := p.normalize(in, false)
+		var uerr *UsernameError
+		if !errors.As(err, &uerr) || uerr.Reason != reason || !errors.Is(err, ErrInvalidUsername) {
+			t.Errorf("normalize(%q) err = %v, want reason %s", in, err, reason)
+		}
+	}
+
+	if got, err := p.normalize("Admin", true); err != nil || got != "Admin" {
+		t.Errorf("setup may take a reserved name: %q, %v", got, err)
+	}
+	strict := UsernamePolicy{ASCIIOnly: true, Lowercase: true}
+	if got, err := strict.normalize("Alice", false); err != nil || got != "alice" {
+		t.Errorf("lowercase: %q, %v", got, err)
+	}
+	if _, err := strict.normalize("Иван", false); err == nil {
+		t.Error("ascii_only accepted Cyrillic")
+	}
+}
+
+func TestUsernamePolicy_Validate(t *testing.T) {
+	if err := (UsernamePolicy{}).Validate(); err != nil {
+		t.Fatalf("zero policy: %v", err)
+	}
+	for name, p := range map[string]UsernamePolicy{
+		"min above max":    {MinLength: 10, MaxLength: 5},
+		"letter separator": {Separators: "_x"},
+		"space separator":  {Separators: " "},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
+
+func TestAuthService_SignUp_AppliesUsernamePolicy(t *testing.T) {
+	mock := &mockAuthRepo{
+		CreateFn: func(username, hash string) (int, error) { return 7, nil },
+		GetByUsernameFn: func(username string) (*models.User, error) {
+			if strings.EqualFold(username, "alice") {
+				return &models.User{ID: 1, Username: "Alice"}, nil
+			}
+			return nil, nil
+		},
+		count: 1,
+	}
+	svc := NewAuthService(mock)
+	svc.policy = UsernamePolicy{Reserved: []string{"root"}}
+
+	if _, err := svc.SignUp(context.Background(), strings.Repeat("z", 10000), "pw"); !errors.Is(err, ErrInvalidUsername) {
+		t.Fatalf("long name: err = %v", err)
+	}
+	if _, err := svc.SignUp(context.Background(), "Root", "pw"); !errors.Is(err, ErrInvalidUsername) {
+		t.Fatalf("reserved name: err = %v", err)
+	}
+	if _, err := svc.SignUp(context.Background(), "ALICE", "pw"); !errors.Is(err, ErrUsernameTaken) {
+		t.Fatalf("taken name: err = %v", err)
+	}
+	if len(mock.createCalls) != 0 {
+		t.Fatalf("rejected names reached the repository: %+v", mock.createCalls)
+	}
+
+	if id, err := svc.SignUp(context.Background(), "  bob ", "pw"); err != nil || id != 7 {
+		t.Fatalf("SignUp = %d, %v", id, err)
+	}
+	if mock.createCalls[0].username != "bob" {
+		t.Fatalf("stored %q, want the trimmed name", mock.createCalls[0].username)
+	}
+}
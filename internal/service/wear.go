@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+// WearConfig sets how the heating elements age. Wear slows the HEAT ramp;
+// the zero value disables degradation and maintenance alerts.
+type WearConfig struct {
+	RampLossPerHour   float64 // share of the nominal ramp rate lost per heating hour
+	RampLossPerCycle  float64 // share lost per heat cycle (thermal stress)
+	MaxRampLoss       float64 // cap on the total loss, 0..1
+	MaintenanceHours  float64 // MAINTENANCE_DUE after the elements heated this many hours; 0 disables
+	MaintenanceCycles int     // MAINTENANCE_DUE after this many cycles of the elements; 0 disables
+}
+
+// elementWear returns the heating hours and cycles of the current elements.
+func elementWear(h models.FurnaceHealth) (hours float64, cycles int) {
+	return (h.HeatingSeconds - h.ReplacedHeatingSeconds) / 3600, h.Cycles - h.ReplacedCycles
+}
+
+// rampLoss returns the share of the nominal ramp rate h has lost.
+func (c WearConfig) rampLoss(h models.FurnaceHealth) float64 {
+	hours, cycles := elementWear(h)
+	loss := hours*c.RampLossPerHour + float64(cycles)*c.RampLossPerCycle
+	return math.Min(math.Max(loss, 0), math.Max(math.Min(c.MaxRampLoss, 1), 0))
+}
+
+// maintenanceDue reports whether h has crossed a maintenance threshold.
+func (c WearConfig) maintenanceDue(h models.FurnaceHealth) bool {
+	hours, cycles := elementWear(h)
+	return (c.MaintenanceHours > 0 && hours >= c.MaintenanceHours) ||
+		(c.MaintenanceCycles > 0 && cycles >= c.MaintenanceCycles)
+}
+
+// describe fills the derived fields of h for a furnace ramping at nominal
+// RampUpCPerSec when new.
+func (c WearConfig) describe(h models.FurnaceHealth, phys PhysicsConfig) models.FurnaceHealth {
+	h.HeatingHours = h.HeatingSeconds / 3600
+	h.ElementHours, _ = elementWear(h)
+	h.RampDegradation = c.rampLoss(h)
+	h.RampUpCPerSec = phys.RampUpCPerSec * (1 - h.RampDegradation)
+	h.MaintenanceHours = c.MaintenanceHours
+	h.MaintenanceCycles = c.MaintenanceCycles
+	return h
+}
+
+// rampUpRate returns the heating rate after wear. Without a health
+// repository wear is not tracked and the nominal rate applies.
+func (s *SimulatorService) rampUpRate(ctx context.Context) float64 {
+	s.wearMu.Lock()
+	defer s.wearMu.Unlock()
+	rate := s.cfg.Physics.RampUpCPerSec
+	if h := s.loadHealth(ctx); h != nil {
+		rate *= 1 - s.cfg.Wear.rampLoss(*h)
+	}
+	return rate
+}
+
+// loadHealth returns the cached wear counters, reading them on first use.
+// It returns nil when wear is not tracked or the counters cannot be read.
+// The caller holds wearMu.
+func (s *SimulatorService) loadHealth(ctx context.Context) *models.FurnaceHealth {
+	if s.healthRepo == nil {
+		return nil
+	}
+	if s.health == nil {
+		h, err := s.healthRepo.Load(ctx)
+		if err != nil {
+			return nil
+		}
+		s.health = &h
+	}
+	return s.health
+}
+
+// trackWear adds the tick's heating time and any newly started cycle to the
+// wear counters, raising MAINTENANCE_DUE once when a threshold is crossed.
+// Wear is kept outside the furnace state, so nothing here changes st.
+func (s *SimulatorService) trackWear(ctx context.Context, st *models.FurnaceState, elapsed float64, now time.Time) {
+	s.wearMu.Lock()
+	defer s.wearMu.Unlock()
+	h := s.loadHealth(ctx)
+	if h == nil || !st.IsRunning || st.Mode != ModeHeat ||
+		s.faults.has(FaultPowerLoss) || s.faults.has(FaultHeaterFailure) {
+		return
+	}
+	if st.RunID != "" && st.RunID != h.LastRunID {
+		h.Cycles++
+		h.LastRunID = st.RunID
+	}
+	h.HeatingSeconds += elapsed
+
+	cfg := s.cfg.Wear
+	if !h.MaintenanceDue && cfg.maintenanceDue(*h) {
+		h.MaintenanceDue = true
+		s.emit(models.FurnaceEvent{
+			EventID:     s.newID(),
+			OccurredAt:  now.UTC(),
+			Type:        "MAINTENANCE_DUE",
+			Description: "Heater maintenance due",
+			Metadata: withRunID(map[string]any{
+				"heating_hours":      math.Round(h.HeatingSeconds/36) / 100,
+				"cycles":             h.Cycles,
+				"ramp_degradation":   cfg.rampLoss(*h),
+				"maintenance_hours":  cfg.MaintenanceHours,
+				"maintenance_cycles": cfg.MaintenanceCycles,
+			}, st.RunID),
+		})
+	}
+	h.UpdatedAt = now.UTC()
+	_ = s.healthRepo.Save(ctx, *h)
+}
+
+// ReplaceElements restarts heater wear, as after fitting new heating
+// elements: the ramp rate is nominal again and MAINTENANCE_DUE can be
+// raised anew. Heating hours and cycles keep counting for the furnace.
+func (s *SimulatorService) ReplaceElements(ctx context.Context) error {
+	s.wearMu.Lock()
+	defer s.wearMu.Unlock()
+	if s.healthRepo == nil {
+		return nil
+	}
+	var h models.FurnaceHealth
+	if s.health != nil {
+		h = *s.health
+	} else {
+		var err error
+		if h, err = s.healthRepo.Load(ctx); err != nil {
+			return err
+		}
+	}
+	h.ReplacedHeatingSeconds, h.ReplacedCycles = h.HeatingSeconds, h.Cycles
+	h.MaintenanceDue = false
+	h.UpdatedAt = s.now().UTC()
+	if err := s.healthRepo.Save(ctx, h); err != nil {
+		return err
+	}
+	s.health = &h
+	return nil
+}
+
+// FurnaceHealth returns the stored wear counters with the resulting ramp
+// degradation. It reads the repository rather than the simulator's cache,
+// so it is safe to call while the simulator runs.
+func (s *SimulatorService) FurnaceHealth(ctx context.Context) (models.FurnaceHealth, error) {
+	var h models.FurnaceHealth
+	if s.healthRepo != nil {
+		var err error
+		if h, err = s.healthRepo.Load(ctx); err != nil {
+			return models.FurnaceHealth{}, err
+		}
+	}
+	s.settingsMu.RLock()
+	cfg := s.published
+	s.settingsMu.RUnlock()
+	return cfg.Wear.describe(h, cfg.Physics), nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+)
+
+type healthRepoStub struct {
+	saved models.FurnaceHealth
+	saves int
+}
+
+func (r *healthRepoStub) Save(ctx context.Context, h models.FurnaceHealth) error {
+	r.saved = h
+	r.saves++
+	return nil
+}
+func (r *healthRepoStub) Load(ctx context.Context) (models.FurnaceHealth, error) {
+	return r.saved, nil
+}
+
+func TestWear_DegradesRampAndRaisesMaintenanceOnce(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	st := heatingState(start)
+	states := &simStateRepoStub{loadResp: st}
+	events := &simEventRepoStub{}
+	cfg := DefaultSimConfig()
+	// 10 s short of the maintenance threshold
+	health := &healthRepoStub{saved: models.FurnaceHealth{HeatingSeconds: cfg.Wear.MaintenanceHours*3600 - 10, Cycles: 99, LastRunID: "run-0"}}
+	svc := NewSimulatorServiceWithConfig(states, events, nil, nil, nil, cfg)
+	svc.healthRepo = health
+
+	for i := 0; i < 2; i++ {
+		if err := svc.Step(context.Background(), 20*time.Second); err != nil {
+			t.Fatalf("Step: %v", err)
+		}
+		states.loadResp = states.saves[len(states.saves)-1]
+	}
+
+	h := health.saved
+	if h.Cycles != 100 || h.LastRunID != "run-1" {
+		t.Fatalf("expected the run to count as one cycle, got %d (%q)", h.Cycles, h.LastRunID)
+	}
+	if h.HeatingSeconds != cfg.Wear.MaintenanceHours*3600+30 || !h.MaintenanceDue {
+		t.Fatalf("unexpected counters %+v", h)
+	}
+	var due int
+	for _, ev := range events.appends {
+		if ev.Type == "MAINTENANCE_DUE" {
+			due++
+		}
+	}
+	if due != 1 {
+		t.Fatalf("expected one MAINTENANCE_DUE event, got %d", due)
+	}
+
+	// 2000 h and 100 cycles cost 20.2% of the nominal ramp
+	if got, want := states.loadResp.CurrentTempC, st.CurrentTempC+40*RampUpCPerSec*(1-0.202); got > want+0.01 || got < want-0.01 {
+		t.Fatalf("expected degraded ramp to reach %.2f°C, got %.2f", want, got)
+	}
+
+	rep, err := svc.FurnaceHealth(context.Background())
+	if err != nil || rep.RampUpCPerSec >= RampUpCPerSec || rep.MaintenanceHours != cfg.Wear.MaintenanceHours {
+		t.Fatalf("FurnaceHealth() = %+v, %v", rep, err)
+	}
+}
+
+func TestWear_NotTrackedWithoutHeating(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	st := heatingState(start)
+	st.Mode = ModeCool
+	health := &healthRepoStub{}
+	svc := NewSimulatorServiceWithConfig(&simStateRepoStub{loadResp: st}, &simEventRepoStub{}, nil, nil, nil, DefaultSimConfig())
+	svc.healthRepo = health
+
+	if err := svc.Step(context.Background(), 10*time.Second); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	if health.saves != 0 {
+		t.Fatalf("cooling must not add wear, got %+v", health.saved)
+	}
+}
+
+func TestWear_ReplaceElementsRestoresNominalRamp(t *testing.T) {
+	cfg := DefaultSimConfig()
+	health := &healthRepoStub{saved: models.FurnaceHealth{HeatingSeconds: cfg.Wear.MaintenanceHours * 3600, Cycles: 100, MaintenanceDue: true}}
+	svc := NewSimulatorServiceWithConfig(&simStateRepoStub{}, &simEventRepoStub{}, nil, nil, nil, cfg)
+	svc.healthRepo = health
+
+	if err := svc.ReplaceElements(context.Background()); err != nil {
+		t.Fatalf("ReplaceElements: %v", err)
+	}
+	h := health.saved
+	if h.HeatingSeconds != cfg.Wear.MaintenanceHours*3600 || h.MaintenanceDue {
+		t.Fatalf("expected the total counters to stay and MAINTENANCE_DUE to clear, got %+v", h)
+	}
+	rep, err := svc.FurnaceHealth(context.Background())
+	if err != nil || rep.RampUpCPerSec != RampUpCPerSec || rep.ElementHours != 0 || rep.HeatingHours != cfg.Wear.MaintenanceHours {
+		t.Fatalf("FurnaceHealth() = %+v, %v", rep, err)
+	}
+}
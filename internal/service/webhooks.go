@@ -0,0 +1,410 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+// Limits and defaults for webhooks and their delivery.
+const (
+	MaxWebhookURL        = 2048
+	MaxWebhookSecret     = 256
+	MaxWebhookEventTypes = 32
+	DefaultDeliveryList  = 100
+	MaxDeliveryList      = 1000
+	// MaxResponseSnippet bounds the bytes of a response body kept with a
+	// delivery.
+	MaxResponseSnippet = 512
+
+	DefaultWebhookPoll       = time.Second
+	DefaultWebhookAttempts   = 8
+	DefaultWebhookBackoff    = 5 * time.Second
+	DefaultWebhookMaxBackoff = 10 * time.Minute
+
+	// webhookBatch bounds the events queued and the deliveries attempted
+	// per pass of the dispatcher.
+	webhookBatch = 100
+)
+
+// Headers of a webhook delivery. The signature is the hex HMAC-SHA256 of
+// the body keyed with the webhook's secret, prefixed "sha256=".
+const (
+	WebhookEventHeader     = "X-Furnace-Event"
+	WebhookDeliveryHeader  = "X-Furnace-Delivery"
+	WebhookSignatureHeader = "X-Furnace-Signature"
+)
+
+var (
+	// ErrInvalidWebhook is returned for webhooks that cannot be delivered to.
+	ErrInvalidWebhook = errors.New("invalid webhook")
+	// ErrWebhookNotFound is returned when no webhook exists for an ID.
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrDeliveryNotFound is returned when a webhook has no delivery with
+	// an ID.
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrDeliveryNotFailed is returned when replaying a delivery that has
+	// not failed.
+	ErrDeliveryNotFailed = errors.New("only failed deliveries can be replayed")
+)
+
+// WebhookConfig configures the delivery of webhooks.
+type WebhookConfig struct {
+	PollInterval time.Duration `mapstructure:"poll_interval"` // between dispatcher passes; DefaultWebhookPoll when 0
+	Timeout      time.Duration `mapstructure:"timeout"`       // per attempt; DefaultNotifyTimeout when 0
+	MaxAttempts  int           `mapstructure:"max_attempts"`  // before a delivery fails; DefaultWebhookAttempts when 0
+	// Backoff is the delay after the first failed attempt; it doubles with
+	// each further failure up to MaxBackoff.
+	Backoff    time.Duration `mapstructure:"backoff"`
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+}
+
+// withDefaults fills the unset fields.
+func (c WebhookConfig) withDefaults() WebhookConfig {
+	if c.PollInterval <= 0 {
+		c.PollInterval = DefaultWebhookPoll
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultNotifyTimeout
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = DefaultWebhookAttempts
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = DefaultWebhookBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultWebhookMaxBackoff
+	}
+	return c
+}
+
+// backoff returns the delay before the attempt after the given number of
+// failed ones.
+func (c WebhookConfig) backoff(failed int) time.Duration {
+	d := c.Backoff
+	for i := 1; i < failed && d < c.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, c.MaxBackoff)
+}
+
+// WebhookService manages webhooks and delivers new events to them. Events
+// are queued from the log rather than intercepted as they are appended, so
+// every writer is covered and nothing is lost across restarts.
+type WebhookService struct {
+	repo   repository.WebhookRepo
+	client *http.Client
+	cfg    WebhookConfig
+	now    func() time.Time
+}
+
+func NewWebhookService(repo repository.WebhookRepo, cfg WebhookConfig) *WebhookService {
+	cfg = cfg.withDefaults()
+	return &WebhookService{repo: repo, client: &http.Client{Timeout: cfg.Timeout}, cfg: cfg, now: time.Now}
+}
+
+// normalizeWebhook checks that w can be delivered to and uppercases its
+// event types.
+func normalizeWebhook(w models.Webhook) (models.Webhook, error) {
+	w.URL = strings.TrimSpace(w.URL)
+	u, err := url.Parse(w.URL)
+	switch {
+	case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(w.URL) > MaxWebhookURL:
+		return w, fmt.Errorf("%w: url must be an absolute http(s) URL of at most %d characters", ErrInvalidWebhook, MaxWebhookURL)
+	case len(w.Secret) > MaxWebhookSecret:
+		return w, fmt.Errorf("%w: secret must be at most %d characters", ErrInvalidWebhook, MaxWebhookSecret)
+	}
+	w.EventTypes = normalizeEventTypes(w.EventTypes)
+	if len(w.EventTypes) == 0 || len(w.EventTypes) > MaxWebhookEventTypes {
+		return w, fmt.Errorf("%w: event_types must list 1..%d event types", ErrInvalidWebhook, MaxWebhookEventTypes)
+	}
+	return w, nil
+}
+
+func (s *WebhookService) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	return s.repo.ListWebhooks(ctx)
+}
+
+func (s *WebhookService) GetWebhook(ctx context.Context, id int) (models.Webhook, error) {
+	w, err := s.repo.GetWebhook(ctx, id)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	if w.ID == 0 {
+		return models.Webhook{}, ErrWebhookNotFound
+	}
+	return w, nil
+}
+
+// CreateWebhook validates and stores w on behalf of userID. Only events
+// logged from now on are delivered to it.
+func (s *WebhookService) CreateWebhook(ctx context.Context, w models.Webhook, userID int) (models.Webhook, error) {
+	w, err := normalizeWebhook(w)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	w.CreatedBy = userID
+	id, err := s.repo.CreateWebhook(ctx, w)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	return s.GetWebhook(ctx, id)
+}
+
+// UpdateWebhook replaces the webhook with w.ID. An empty secret keeps the
+// stored one.
+func (s *WebhookService) UpdateWebhook(ctx context.Context, w models.Webhook) (models.Webhook, error) {
+	w, err := normalizeWebhook(w)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	found, err := s.repo.UpdateWebhook(ctx, w)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	if !found {
+		return models.Webhook{}, ErrWebhookNotFound
+	}
+	return s.GetWebhook(ctx, w.ID)
+}
+
+// DeleteWebhook removes a webhook together with its deliveries.
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id int) error {
+	found, err := s.repo.DeleteWebhook(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// ListDeliveries returns the deliveries of a webhook, newest first,
+// optionally only those with the given status.
+func (s *WebhookService) ListDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]models.WebhookDelivery, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status != "" && status != models.DeliveryPending && status != models.DeliveryDelivered && status != models.DeliveryFailed {
+		return nil, fmt.Errorf("%w: status must be one of %s, %s, %s", ErrInvalidWebhook,
+			models.DeliveryPending, models.DeliveryDelivered, models.DeliveryFailed)
+	}
+	if _, err := s.GetWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultDeliveryList
+	}
+	if limit > MaxDeliveryList {
+		limit = MaxDeliveryList
+	}
+	return s.repo.ListDeliveries(ctx, repository.WebhookDeliveryQuery{WebhookID: webhookID, Status: status, Limit: limit})
+}
+
+// ReplayDelivery queues a failed delivery of a webhook again, with a fresh
+// set of attempts starting on the next dispatcher pass. The payload is the
+// one queued originally.
+func (s *WebhookService) ReplayDelivery(ctx context.Context, webhookID int, deliveryID int64) (models.WebhookDelivery, error) {
+	w, err := s.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	d, err := s.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	if d.ID == 0 || d.WebhookID != webhookID {
+		return models.WebhookDelivery{}, ErrDeliveryNotFound
+	}
+	if d.Status != models.DeliveryFailed {
+		return models.WebhookDelivery{}, fmt.Errorf("%w; delivery %d is %s", ErrDeliveryNotFailed, d.ID, d.Status)
+	}
+	if !w.Enabled {
+		return models.WebhookDelivery{}, fmt.Errorf("%w: enable the webhook before replaying its deliveries", ErrInvalidWebhook)
+	}
+	now := s.now().UTC()
+	d.Status, d.Attempts, d.NextAttemptAt = models.DeliveryPending, 0, &now
+	if err := s.repo.UpdateDelivery(ctx, d); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return d, nil
+}
+
+// Run queues and delivers new events until ctx is canceled.
+func (s *WebhookService) Run(ctx context.Context) {
+	t := time.NewTicker(s.cfg.PollInterval)
+	defer t.Stop()
+	for {
+		s.enqueue(ctx)
+		s.deliver(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// enqueue queues a delivery of each new event for every enabled webhook
+// that subscribes to its type. The cursor also moves past events no
+// webhook wants.
+func (s *WebhookService) enqueue(ctx context.Context) {
+	hooks, err := s.repo.ListWebhooks(ctx)
+	if err != nil {
+		return
+	}
+	for {
+		events, through, err := s.repo.NewEvents(ctx, webhookBatch)
+		if err != nil || len(events) == 0 {
+			return
+		}
+		now := s.now().UTC()
+		var deliveries []models.WebhookDelivery
+		for _, ev := range events {
+			var targets []int
+			for _, w := range hooks {
+				if w.Enabled && slices.Contains(w.EventTypes, ev.Type) {
+					targets = append(targets, w.ID)
+				}
+			}
+			if len(targets) == 0 {
+				continue
+			}
+			ev.SchemaVersion = models.SchemaVersion
+			payload, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			for _, id := range targets {
+				deliveries = append(deliveries, models.WebhookDelivery{
+					WebhookID:     id,
+					EventID:       ev.EventID,
+					EventType:     ev.Type,
+					Payload:       string(payload),
+					Status:        models.DeliveryPending,
+					NextAttemptAt: &now,
+					CreatedAt:     now,
+				})
+			}
+		}
+		if err := s.repo.Enqueue(ctx, deliveries, through); err != nil || len(events) < webhookBatch {
+			return
+		}
+	}
+}
+
+// deliver attempts every pending delivery that is due.
+func (s *WebhookService) deliver(ctx context.Context) {
+	due, err := s.repo.DueDeliveries(ctx, s.now().UTC(), webhookBatch)
+	if err != nil {
+		return
+	}
+	hooks := make(map[int]models.Webhook)
+	for _, d := range due {
+		w, ok := hooks[d.WebhookID]
+		if !ok {
+			if w, err = s.repo.GetWebhook(ctx, d.WebhookID); err != nil {
+				return
+			}
+			hooks[d.WebhookID] = w
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		s.attempt(ctx, w, d)
+	}
+}
+
+// attempt POSTs d to w and records the outcome. A failed attempt is
+// retried after an exponential backoff until MaxAttempts is reached.
+func (s *WebhookService) attempt(ctx context.Context, w models.Webhook, d models.WebhookDelivery) {
+	now := s.now().UTC()
+	if !w.Enabled {
+		// queued before the webhook was disabled
+		d.Status, d.NextAttemptAt, d.LastError = models.DeliveryFailed, nil, "webhook disabled"
+		_ = s.repo.UpdateDelivery(ctx, d)
+		return
+	}
+	resp, err := s.post(ctx, w, d)
+	if err != nil && ctx.Err() != nil {
+		return // shutting down; retried on the next start
+	}
+
+	now = s.now().UTC()
+	d.Attempts++
+	d.LastAttemptAt, d.ResponseStatus, d.LastError = &now, resp.status, ""
+	d.LatencyMs, d.ResponseSnippet = resp.latency.Milliseconds(), resp.snippet
+	switch {
+	case err == nil:
+		d.Status, d.NextAttemptAt, d.DeliveredAt = models.DeliveryDelivered, nil, &now
+	case d.Attempts >= s.cfg.MaxAttempts:
+		d.Status, d.NextAttemptAt, d.LastError = models.DeliveryFailed, nil, err.Error()
+	default:
+		next := now.Add(s.cfg.backoff(d.Attempts))
+		d.NextAttemptAt, d.LastError = &next, err.Error()
+	}
+	_ = s.repo.UpdateDelivery(ctx, d)
+}
+
+// webhookResponse is what an attempt learned about the endpoint.
+type webhookResponse struct {
+	status  int           // 0 without a response
+	latency time.Duration // until the body was read or the request failed
+	snippet string        // up to MaxResponseSnippet bytes of the body
+}
+
+// post sends one delivery and returns the response. It fails on transport
+// errors and non-2xx responses.
+func (s *WebhookService) post(ctx context.Context, w models.Webhook, d models.WebhookDelivery) (webhookResponse, error) {
+	body := []byte(d.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return webhookResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, d.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(d.ID, 10))
+	if w.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(w.Secret, body))
+	}
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return webhookResponse{latency: time.Since(start)}, err
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSnippet))
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+	out := webhookResponse{
+		status:  resp.StatusCode,
+		latency: time.Since(start),
+		snippet: strings.ToValidUTF8(string(snippet), ""),
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return out, fmt.Errorf("delivery rejected: %s", resp.Status)
+	}
+	return out, nil
+}
+
+// SignWebhook returns the signature header value for body, so receivers
+// written in Go can verify deliveries with hmac.Equal.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"controlling_furnace/internal/models"
+	"controlling_furnace/internal/repository"
+)
+
+// fakeWebhookRepo keeps webhooks, deliveries and the event log in memory;
+// the cursor indexes events.
+type fakeWebhookRepo struct {
+	hooks      map[int]models.Webhook
+	events     []models.FurnaceEvent
+	cursor     int64
+	deliveries []models.WebhookDelivery
+}
+
+func (r *fakeWebhookRepo) CreateWebhook(ctx context.Context, w models.Webhook) (int, error) {
+	w.ID = len(r.hooks) + 1
+	w.HasSecret = w.Secret != ""
+	r.hooks[w.ID] = w
+	return w.ID, nil
+}
+func (r *fakeWebhookRepo) UpdateWebhook(ctx context.Context, w models.Webhook) (bool, error) {
+	old, ok := r.hooks[w.ID]
+	if ok {
+		if w.Secret == "" {
+			w.Secret = old.Secret
+		}
+		r.hooks[w.ID] = w
+	}
+	return ok, nil
+}
+func (r *fakeWebhookRepo) DeleteWebhook(ctx context.Context, id int) (bool, error) {
+	_, ok := r.hooks[id]
+	delete(r.hooks, id)
+	return ok, nil
+}
+func (r *fakeWebhookRepo) GetWebhook(ctx context.Context, id int) (models.Webhook, error) {
+	return r.hooks[id], nil
+}
+func (r *fakeWebhookRepo) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	var out []models.Webhook
+	for id := 1; id <= len(r.hooks); id++ {
+		out = append(out, r.hooks[id])
+	}
+	return out, nil
+}
+func (r *fakeWebhookRepo) NewEvents(ctx context.Context, limit int) ([]models.FurnaceEvent, int64, error) {
+	end := min(int(r.cursor)+limit, len(r.events))
+	return r.events[r.cursor:end], int64(end), nil
+}
+func (r *fakeWebhookRepo) Enqueue(ctx context.Context, deliveries []models.WebhookDelivery, through int64) error {
+	for _, d := range deliveries {
+		d.ID = int64(len(r.deliveries) + 1)
+		r.deliveries = append(r.deliveries, d)
+	}
+	r.cursor = through
+	return nil
+}
+func (r *fakeWebhookRepo) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var out []models.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.Status == models.DeliveryPending && !d.NextAttemptAt.After(now) {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+func (r *fakeWebhookRepo) UpdateDelivery(ctx context.Context, d models.WebhookDelivery) error {
+	r.deliveries[d.ID-1] = d
+	return nil
+}
+func (r *fakeWebhookRepo) GetDelivery(ctx context.Context, id int64) (models.WebhookDelivery, error) {
+	if id < 1 || id > int64(len(r.deliveries)) {
+		return models.WebhookDelivery{}, nil
+	}
+	return r.deliveries[id-1], nil
+}
+func (r *fakeWebhookRepo) ListDeliveries(ctx context.Context, q repository.WebhookDeliveryQuery) ([]models.WebhookDelivery, error) {
+	return r.deliveries, nil
+}
+
+func newTestWebhooks(cfg WebhookConfig, now *time.Time) (*WebhookService, *fakeWebhookRepo) {
+	repo := &fakeWebhookRepo{hooks: map[int]models.Webhook{}}
+	svc := NewWebhookService(repo, cfg)
+	svc.now = func() time.Time { return *now }
+	return svc, repo
+}
+
+func TestWebhooks_RejectsUndeliverableWebhooks(t *testing.T) {
+	now := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	svc, _ := newTestWebhooks(WebhookConfig{}, &now)
+
+	for _, w := range []models.Webhook{
+		{URL: "ftp://mes.example.com", EventTypes: []string{"STOP"}},
+		{URL: "/relative", EventTypes: []string{"STOP"}},
+		{URL: "https://mes.example.com/hook", EventTypes: []string{" "}},
+	} {
+		if _, err := svc.CreateWebhook(context.Background(), w, 1); !errors.Is(err, ErrInvalidWebhook) {
+			t.Fatalf("CreateWebhook(%+v) = %v, want ErrInvalidWebhook", w, err)
+		}
+	}
+	w, err := svc.CreateWebhook(context.Background(), models.Webhook{URL: " https://mes.example.com/hook ", EventTypes: []string{"stop", "STOP", "error"}, Enabled: true}, 1)
+	if err != nil || w.URL != "https://mes.example.com/hook" || len(w.EventTypes) != 2 || w.EventTypes[1] != "ERROR" || w.CreatedBy != 1 {
+		t.Fatalf("CreateWebhook() = %+v, %v", w, err)
+	}
+	if _, err := svc.ListDeliveries(context.Background(), w.ID, "lost", 0); !errors.Is(err, ErrInvalidWebhook) {
+		t.Fatalf("ListDeliveries with an unknown status = %v, want ErrInvalidWebhook", err)
+	}
+	if _, err := svc.ListDeliveries(context.Background(), 9, "", 0); !errors.Is(err, ErrWebhookNotFound) {
+		t.Fatalf("ListDeliveries(9) = %v, want ErrWebhookNotFound", err)
+	}
+}
+
+func TestWebhooks_DeliversSignedEventsWithBackoff(t *testing.T) {
+	var calls atomic.Int32
+	var gotSig, gotEvent string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		gotSig, gotEvent = r.Header.Get(WebhookSignatureHeader), r.Header.Get(WebhookEventHeader)
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	now := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	svc, repo := newTestWebhooks(WebhookConfig{Backoff: 5 * time.Second}, &now)
+	ctx := context.Background()
+	mes, _ := svc.CreateWebhook(ctx, models.Webhook{URL: srv.URL, EventTypes: []string{"STOP"}, Secret: "s3cret", Enabled: true}, 1)
+	_, _ = svc.CreateWebhook(ctx, models.Webhook{URL: srv.URL, EventTypes: []string{"TELEMETRY"}, Enabled: false}, 1)
+	repo.events = []models.FurnaceEvent{
+		{EventID: "e1", Type: "TELEMETRY", OccurredAt: now},
+		{EventID: "e2", Type: "STOP", OccurredAt: now, Description: "Furnace stopped"},
+	}
+
+	svc.enqueue(ctx)
+	if len(repo.deliveries) != 1 || repo.deliveries[0].WebhookID != mes.ID || repo.deliveries[0].EventID != "e2" || repo.cursor != 2 {
+		t.Fatalf("expected only the STOP event queued for the enabled webhook, got %+v (cursor %d)", repo.deliveries, repo.cursor)
+	}
+
+	svc.deliver(ctx)
+	d := repo.deliveries[0]
+	if d.Status != models.DeliveryPending || d.Attempts != 1 || d.ResponseStatus != http.StatusServiceUnavailable ||
+		d.NextAttemptAt == nil || !d.NextAttemptAt.Equal(now.Add(5*time.Second)) || d.LastError == "" {
+		t.Fatalf("expected a retry in 5s after a 503, got %+v", d)
+	}
+	svc.deliver(ctx)
+	if calls.Load() != 1 {
+		t.Fatalf("retried before the backoff elapsed")
+	}
+
+	now = now.Add(5 * time.Second)
+	svc.deliver(ctx)
+	d = repo.deliveries[0]
+	if d.Status != models.DeliveryDelivered || d.Attempts != 2 || d.DeliveredAt == nil || d.LastError != "" || d.NextAttemptAt != nil {
+		t.Fatalf("expected the second attempt to deliver, got %+v", d)
+	}
+	if gotEvent != "STOP" || gotSig != SignWebhook("s3cret", gotBody) || string(gotBody) != d.Payload {
+		t.Fatalf("unexpected request: event=%q sig=%q body=%s", gotEvent, gotSig, gotBody)
+	}
+}
+
+func TestWebhooks_FailsAfterMaxAttempts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	now := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	svc, repo := newTestWebhooks(WebhookConfig{MaxAttempts: 2, Backoff: time.Second}, &now)
+	ctx := context.Background()
+	_, _ = svc.CreateWebhook(ctx, models.Webhook{URL: srv.URL, EventTypes: []string{"ERROR"}, Enabled: true}, 1)
+	repo.events = []models.FurnaceEvent{{EventID: "e1", Type: "ERROR", OccurredAt: now}}
+
+	svc.enqueue(ctx)
+	svc.deliver(ctx)
+	now = now.Add(time.Second)
+	svc.deliver(ctx)
+	if d := repo.deliveries[0]; d.Status != models.DeliveryFailed || d.Attempts != 2 || d.NextAttemptAt != nil || d.ResponseStatus != 500 {
+		t.Fatalf("expected the delivery to fail after 2 attempts, got %+v", d)
+	}
+}
+
+func TestWebhooks_ReplaysFailedDeliveries(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(strings.Repeat("x", MaxResponseSnippet+100)))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	now := time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+	svc, repo := newTestWebhooks(WebhookConfig{MaxAttempts: 1}, &now)
+	ctx := context.Background()
+	mes, _ := svc.CreateWebhook(ctx, models.Webhook{URL: srv.URL, EventTypes: []string{"STOP"}, Enabled: true}, 1)
+	other, _ := svc.CreateWebhook(ctx, models.Webhook{URL: srv.URL, EventTypes: []string{"ERROR"}, Enabled: true}, 1)
+	repo.events = []models.FurnaceEvent{{EventID: "e1", Type: "STOP", OccurredAt: now}}
+
+	svc.enqueue(ctx)
+	if _, err := svc.ReplayDelivery(ctx, mes.ID, 1); !errors.Is(err, ErrDeliveryNotFailed) {
+		t.Fatalf("ReplayDelivery of a pending delivery = %v, want ErrDeliveryNotFailed", err)
+	}
+	svc.deliver(ctx)
+	d := repo.deliveries[0]
+	if d.Status != models.DeliveryFailed || len(d.ResponseSnippet) != MaxResponseSnippet || d.LatencyMs < 0 {
+		t.Fatalf("expected a failed delivery with a %d byte snippet, got %+v", MaxResponseSnippet, d)
+	}
+
+	if _, err := svc.ReplayDelivery(ctx, other.ID, 1); !errors.Is(err, ErrDeliveryNotFound) {
+		t.Fatalf("ReplayDelivery under another webhook = %v, want ErrDeliveryNotFound", err)
+	}
+	if _, err := svc.ReplayDelivery(ctx, mes.ID, 9); !errors.Is(err, ErrDeliveryNotFound) {
+		t.Fatalf("ReplayDelivery(9) = %v, want ErrDeliveryNotFound", err)
+	}
+	if _, err := svc.ReplayDelivery(ctx, 9, 1); !errors.Is(err, ErrWebhookNotFound) {
+		t.Fatalf("ReplayDelivery under webhook 9 = %v, want ErrWebhookNotFound", err)
+	}
+	off := mes
+	off.Enabled = false
+	_, _ = repo.UpdateWebhook(ctx, off)
+	if _, err := svc.ReplayDelivery(ctx, mes.ID, 1); !errors.Is(err, ErrInvalidWebhook) {
+		t.Fatalf("ReplayDelivery on a disabled webhook = %v, want ErrInvalidWebhook", err)
+	}
+	_, _ = repo.UpdateWebhook(ctx, mes)
+
+	now = now.Add(time.Hour)
+	d, err := svc.ReplayDelivery(ctx, mes.ID, 1)
+	if err != nil || d.Status != models.DeliveryPending || d.Attempts != 0 || d.NextAttemptAt == nil || !d.NextAttemptAt.Equal(now) {
+		t.Fatalf("ReplayDelivery() = %+v, %v", d, err)
+	}
+	fail.Store(false)
+	svc.deliver(ctx)
+	if d := repo.deliveries[0]; d.Status != models.DeliveryDelivered || d.Attempts != 1 || d.ResponseSnippet != `{"ok":true}` {
+		t.Fatalf("expected the replay to deliver, got %+v", d)
+	}
+}
+
+func TestWebhookConfig_BackoffDoublesUpToTheCap(t *testing.T) {
+	cfg := WebhookConfig{Backoff: 5 * time.Second, MaxBackoff: time.Minute}.withDefaults()
+	for failed, want := range map[int]time.Duration{1: 5 * time.Second, 2: 10 * time.Second, 4: 40 * time.Second, 5: time.Minute, 30: time.Minute} {
+		if got := cfg.backoff(failed); got != want {
+			t.Fatalf("backoff(%d) = %v, want %v", failed, got, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package tracing exports OpenTelemetry spans over OTLP/HTTP so a slow
+// request can be followed from the Gin handler through the service layer
+// down to the individual SQLite statements.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// DefaultServiceName identifies this process in the tracing backend.
+const DefaultServiceName = "controlling_furnace"
+
+// ErrInvalidConfig is returned for tracing settings that cannot be applied.
+var ErrInvalidConfig = errors.New("invalid tracing config")
+
+// Config selects where spans are sent. The zero value disables tracing.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoint is the collector's host:port; empty uses the OTLP default
+	// (localhost:4318) or OTEL_EXPORTER_OTLP_ENDPOINT.
+	Endpoint    string `mapstructure:"endpoint"`
+	Insecure    bool   `mapstructure:"insecure"` // plain HTTP instead of TLS
+	ServiceName string `mapstructure:"service_name"`
+	// SampleRatio is the share of new traces recorded, 0..1; 0 records all.
+	// Requests that arrive with a sampled parent are always recorded.
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.ServiceName == "" {
+		c.ServiceName = DefaultServiceName
+	}
+	if c.SampleRatio == 0 {
+		c.SampleRatio = 1
+	}
+	return c
+}
+
+// Validate checks the sample ratio.
+func (c Config) Validate() error {
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("%w: sample_ratio must be within 0..1", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// Setup installs the global tracer provider and the W3C trace context
+// propagator. The returned shutdown flushes buffered spans and must be
+// called before exit. When cfg is disabled the global no-op provider is
+// left in place, so instrumented code records nothing.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	noop := func(context.Context) error { return nil }
+	if !cfg.Enabled {
+		return noop, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return noop, err
+	}
+	cfg = cfg.withDefaults()
+
+	var opts []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exp, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return noop, fmt.Errorf("create otlp exporter: %w", err)
+	}
+
+	tp := NewProvider(cfg, sdktrace.WithBatcher(exp))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+	return tp.Shutdown, nil
+}
+
+// NewProvider builds a tracer provider that tags spans with the service
+// name and samples by cfg.SampleRatio; opts add span processors.
+func NewProvider(cfg Config, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	cfg = cfg.withDefaults()
+	opts = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}, opts...)
+	return sdktrace.NewTracerProvider(opts...)
+}
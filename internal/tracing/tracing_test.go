@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestValidate(t *testing.T) {
+	for _, ratio := range []float64{0, 0.25, 1} {
+		if err := (Config{SampleRatio: ratio}).Validate(); err != nil {
+			t.Errorf("ratio %v: %v", ratio, err)
+		}
+	}
+	for _, ratio := range []float64{-0.1, 1.5} {
+		if err := (Config{SampleRatio: ratio}).Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("ratio %v: err = %v, want ErrInvalidConfig", ratio, err)
+		}
+	}
+}
+
+func TestSetupDisabledKeepsNoopProvider(t *testing.T) {
+	before := otel.GetTracerProvider()
+	shutdown, err := Setup(context.Background(), Config{SampleRatio: 5})
+	if err != nil {
+		t.Fatalf("disabled config is not validated, got %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if otel.GetTracerProvider() != before {
+		t.Fatal("disabled tracing replaced the global provider")
+	}
+}
+
+func TestNewProviderSamplesByRatio(t *testing.T) {
+	for _, tc := range []struct {
+		ratio float64
+		want  bool
+	}{
+		{ratio: 0, want: true}, // unset records everything
+		{ratio: 1, want: true},
+		{ratio: 1e-12, want: false},
+	} {
+		rec := tracetest.NewSpanRecorder()
+		tp := NewProvider(Config{SampleRatio: tc.ratio}, sdktrace.WithSpanProcessor(rec))
+		_, span := tp.Tracer("test").Start(context.Background(), "op")
+		span.End()
+		if got := len(rec.Ended()) == 1; got != tc.want {
+			t.Errorf("ratio %v: recorded = %v, want %v", tc.ratio, got, tc.want)
+		}
+		_ = tp.Shutdown(context.Background())
+	}
+}
+
+func TestNewProviderFollowsSampledParent(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := NewProvider(Config{SampleRatio: 1e-12}, sdktrace.WithSpanProcessor(rec))
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), parent)
+	_, span := tp.Tracer("test").Start(ctx, "op")
+	span.End()
+	if len(rec.Ended()) != 1 {
+		t.Fatal("span with a sampled parent was dropped")
+	}
+	if got := rec.Ended()[0].Resource().Attributes(); len(got) != 1 || got[0].Value.AsString() != DefaultServiceName {
+		t.Errorf("resource = %v, want service.name=%s", got, DefaultServiceName)
+	}
+}
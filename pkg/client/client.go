@@ -0,0 +1,114 @@
+// Package client is a typed Go client of the furnace API. The methods and
+// wire types in client_gen.go are generated from the handler annotations
+// and DTOs (see generate.go), so they change together with the server;
+// run `make gen` after changing either.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls one furnace instance.
+type Client struct {
+	BaseURL string       // e.g. http://localhost:8080
+	Token   string       // JWT sent as a bearer token when set
+	HTTP    *http.Client // http.DefaultClient when nil
+}
+
+// New returns a client of the instance at baseURL.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// Error is returned for a response with a non-2xx status.
+type Error struct {
+	StatusCode int
+	Message    string // the "detail" of the problem body, or the body itself
+	Code       string // the problem's machine-readable cause, e.g. event_not_found
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("furnace API: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// rawBody is a request body sent as is rather than as JSON.
+type rawBody struct {
+	contentType string
+	r           io.Reader
+}
+
+// do sends a request and decodes a JSON response into out, or copies the
+// body when out is a *[]byte. A nil out discards the body.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	u := strings.TrimRight(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var r io.Reader
+	contentType := ""
+	switch b := body.(type) {
+	case nil:
+	case rawBody:
+		r, contentType = b.r, b.contentType
+	default:
+		buf, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		r, contentType = bytes.NewReader(buf), "application/json"
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	hc := c.HTTP
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var p struct {
+			Detail string `json:"detail"`
+			Code   string `json:"code"`
+			Error  string `json:"error"` // servers before problem bodies
+		}
+		e := &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(b))}
+		if json.Unmarshal(b, &p) == nil {
+			e.Code = p.Code
+			if p.Detail != "" {
+				e.Message = p.Detail
+			} else if p.Error != "" {
+				e.Message = p.Error
+			}
+		}
+		return e
+	}
+	switch o := out.(type) {
+	case nil:
+		_, err = io.Copy(io.Discard, resp.Body)
+	case *[]byte:
+		*o, err = io.ReadAll(resp.Body)
+	default:
+		err = json.NewDecoder(resp.Body).Decode(out)
+	}
+	return err
+}
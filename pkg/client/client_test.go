@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_SendsTypedRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/sign-in":
+			var creds AuthCredentials
+			if err := json.NewDecoder(r.Body).Decode(&creds); err != nil || creds.Username != "qa" {
+				t.Errorf("sign-in body %+v, %v", creds, err)
+			}
+			_ = json.NewEncoder(w).Encode(TokenResponse{Token: "jwt"})
+		case "/api/v1/logs":
+			if got := r.Header.Get("Authorization"); got != "Bearer jwt" {
+				t.Errorf("authorization %q", got)
+			}
+			q := r.URL.Query()
+			if q.Get("limit") != "5" || q.Get("type") != "" || len(q["meta.temp_c>1000"]) != 1 {
+				t.Errorf("query %v", q)
+			}
+			_, _ = w.Write([]byte(`{"events": []}`))
+		default:
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"status": 403, "code": "insufficient_permissions", "detail": "insufficient permissions", "error": "insufficient permissions"}`))
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	c := New(srv.URL + "/")
+	tok, err := c.AuthSignIn(ctx, AuthCredentials{Username: "qa", Password: "secret"})
+	if err != nil || tok.Token != "jwt" {
+		t.Fatalf("sign in: %+v, %v", tok, err)
+	}
+	c.Token = tok.Token
+	if _, err := c.GetLogs(ctx, GetLogsParams{Limit: 5, Meta: []string{"temp_c>1000"}}); err != nil {
+		t.Fatalf("logs: %v", err)
+	}
+	var apiErr *Error
+	if _, err := c.GetSystemInfo(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden || apiErr.Message != "insufficient permissions" || apiErr.Code != "insufficient_permissions" {
+		t.Fatalf("error response: %v", err)
+	}
+}
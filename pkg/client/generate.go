@@ -0,0 +1,3 @@
+package client
+
+//go:generate go run ../../cmd/apigen -root ../.. -out client_gen.go